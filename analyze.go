@@ -0,0 +1,169 @@
+package ruleengine
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// Analysis is the result of statically analysing the loaded config
+type Analysis struct {
+	// UnusedRules are rules not referenced by any ruleset, directly or as the parent of a referenced rule
+	UnusedRules []string
+	// UnreachableRulesets are rulesets which can never pass
+	UnreachableRulesets []UnreachableRuleset
+	// UnusedGlobals are globals not referenced by any rule expression
+	UnusedGlobals []string
+	// UndefinedGlobals are globals referenced by a rule expression but not defined, e.g. removed by an environment
+	UndefinedGlobals []string
+	// DuplicateRules are groups of rules registered under different names requiring semantically identical
+	// conditions, e.g. `user.age >= 18` and `18 <= user.age`
+	DuplicateRules [][]string
+	// ConflictingRules are rules of AND rulesets requiring opposite conditions, the rulesets can never pass
+	ConflictingRules []ConflictingRules
+}
+
+// UnreachableRuleset is a ruleset which can never pass
+type UnreachableRuleset struct {
+	// Name is the name of the ruleset
+	Name string
+	// Rules are the rules which can never pass and cause the ruleset to never pass
+	Rules []string
+}
+
+// Empty reports whether the analysis found no issues
+func (a Analysis) Empty() bool {
+	return len(a.UnusedRules) == 0 && len(a.UnreachableRulesets) == 0 && len(a.UnusedGlobals) == 0 &&
+		len(a.UndefinedGlobals) == 0 && len(a.DuplicateRules) == 0 && len(a.ConflictingRules) == 0
+}
+
+// Analyze statically analyses the config for unused rules, unreachable rulesets, unused or undefined globals and
+// duplicate or conflicting rules
+//
+//	A rule can never pass when its expression, or the expression of a rule it extends, folds to a constant other
+//	than true, e.g. `1 > 2`. Expressions depending on the context are assumed to be able to pass.
+//	Errors are returned if an expression cannot be compiled
+func (re *RuleEngine) Analyze() (Analysis, error) {
+	if active := re.Active(); active != re {
+		return active.Analyze()
+	}
+	analysis := Analysis{
+		UnusedRules:         make([]string, 0),
+		UnreachableRulesets: make([]UnreachableRuleset, 0),
+		UnusedGlobals:       make([]string, 0),
+		UndefinedGlobals:    make([]string, 0),
+		DuplicateRules:      make([][]string, 0),
+		ConflictingRules:    make([]ConflictingRules, 0),
+	}
+
+	folder, err := cel.NewConstantFoldingOptimizer()
+	if err != nil {
+		return analysis, fmt.Errorf("failed to create constant folding optimizer: %w", err)
+	}
+	optimizer := cel.NewStaticOptimizer(folder)
+
+	constantFalse := make(map[string]bool, len(re.config.Rules))
+	usedGlobals := make(map[string]bool)
+	for _, name := range re.ruleNames() {
+		checked, issues := re.env.Compile(re.config.Rules[name].Expression)
+		if issues != nil && issues.Err() != nil {
+			return analysis, fmt.Errorf("failed to compile rule '%s': %w", name, issues.Err())
+		}
+		for _, path := range re.referencedVariables(checked.NativeRep().Expr()) {
+			if global, ok := strings.CutPrefix(path, "globals."); ok {
+				usedGlobals[strings.SplitN(global, ".", 2)[0]] = true
+			}
+		}
+		folded, issues := optimizer.Optimize(re.env, checked)
+		if issues != nil && issues.Err() != nil {
+			// Folding fails when a constant sub-expression errors, e.g. `1 / 0`, so the rule can never pass
+			constantFalse[name] = true
+			continue
+		}
+		expr := folded.NativeRep().Expr()
+		constantFalse[name] = expr.Kind() == ast.LiteralKind && expr.AsLiteral() != types.True
+	}
+
+	used := make(map[string]bool, len(re.config.Rules))
+	for _, rulesetName := range re.rulesetNames() {
+		ruleset := re.config.Rulesets[rulesetName]
+		neverPass := make([]string, 0)
+		for _, ruleName := range ruleset.Rules {
+			used[ruleName] = true
+			failing := constantFalse[ruleName]
+			for _, parent := range re.parents[ruleName] {
+				used[parent] = true
+				failing = failing || constantFalse[parent]
+			}
+			if failing {
+				neverPass = append(neverPass, ruleName)
+			}
+		}
+		unreachable := len(neverPass) > 0
+		if ruleset.Selector == selectorOr {
+			// A veto rule which can never pass fails the ruleset, otherwise one of the other rules must be able to
+			allows, allowsNeverPassing, vetoed := 0, 0, false
+			for _, ruleName := range ruleset.Rules {
+				veto := slices.Contains(ruleset.Vetoes, ruleName)
+				failing := slices.Contains(neverPass, ruleName)
+				vetoed = vetoed || (veto && failing)
+				if !veto {
+					allows++
+					if failing {
+						allowsNeverPassing++
+					}
+				}
+			}
+			unreachable = vetoed || allowsNeverPassing == allows
+		}
+		if unreachable {
+			analysis.UnreachableRulesets = append(analysis.UnreachableRulesets, UnreachableRuleset{
+				Name:  rulesetName,
+				Rules: neverPass,
+			})
+		}
+	}
+
+	for _, name := range re.ruleNames() {
+		if !used[name] {
+			analysis.UnusedRules = append(analysis.UnusedRules, name)
+		}
+	}
+	for name := range re.config.Globals {
+		if !usedGlobals[name] {
+			analysis.UnusedGlobals = append(analysis.UnusedGlobals, name)
+		}
+	}
+	sort.Strings(analysis.UnusedGlobals)
+	for name := range usedGlobals {
+		if _, ok := re.config.Globals[name]; !ok {
+			analysis.UndefinedGlobals = append(analysis.UndefinedGlobals, name)
+		}
+	}
+	sort.Strings(analysis.UndefinedGlobals)
+
+	conditions, err := re.ruleConditions()
+	if err != nil {
+		return analysis, err
+	}
+	if analysis.DuplicateRules, err = re.duplicateRules(conditions); err != nil {
+		return analysis, err
+	}
+	analysis.ConflictingRules = re.conflictingRules(conditions)
+	return analysis, nil
+}
+
+// ruleNames returns the names of all configured rules in sorted order
+func (re *RuleEngine) ruleNames() []string {
+	names := make([]string, 0, len(re.config.Rules))
+	for name := range re.config.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
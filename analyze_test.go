@@ -0,0 +1,72 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_Analyze(t *testing.T) {
+	tests := []struct {
+		name       string
+		configPath string
+		want       Analysis
+	}{
+		{
+			name:       "success - findings",
+			configPath: "./testdata/analyze_rules.yml",
+			want: Analysis{
+				UnusedRules: []string{"rate_limiting"},
+				UnreachableRulesets: []UnreachableRuleset{
+					{Name: "blocked", Rules: []string{"disabled_check"}},
+				},
+				UnusedGlobals:    []string{"legacy_limit"},
+				UndefinedGlobals: []string{},
+				DuplicateRules:   [][]string{},
+				ConflictingRules: []ConflictingRules{},
+			},
+		},
+		{
+			name:       "success - no unreachable rulesets",
+			configPath: "./testdata/rules.yml",
+			want: Analysis{
+				UnusedRules:         []string{"business_hours", "test_user"},
+				UnreachableRulesets: []UnreachableRuleset{},
+				UnusedGlobals:       []string{},
+				UndefinedGlobals:    []string{"business_hours_end", "business_hours_start"},
+				DuplicateRules:      [][]string{},
+				ConflictingRules:    []ConflictingRules{},
+			},
+		},
+		{
+			name:       "success - duplicate and conflicting rules",
+			configPath: "./testdata/conflict_rules.yml",
+			want: Analysis{
+				UnusedRules:         []string{"grown_up"},
+				UnreachableRulesets: []UnreachableRuleset{},
+				UnusedGlobals:       []string{},
+				UndefinedGlobals:    []string{},
+				DuplicateRules:      [][]string{{"adult", "grown_up"}},
+				ConflictingRules:    []ConflictingRules{{Ruleset: "contradiction", Rule: "adult", Negation: "minor"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewRuleEngine(tt.configPath, "", setupEnvironment()(t))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			got, err := re.Analyze()
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Analyze() (-got +want):\n%s", diff)
+			}
+			if got.Empty() {
+				t.Errorf("Empty() = true, want false")
+			}
+		})
+	}
+}
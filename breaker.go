@@ -0,0 +1,127 @@
+package ruleengine
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the evaluation error of a rule disabled by the circuit breaker
+var ErrCircuitOpen = errors.New("rule disabled by circuit breaker")
+
+// CircuitBreakerConfig configures the per-rule circuit breaker
+type CircuitBreakerConfig struct {
+	// Threshold is the error rate between 0 and 1 above which a rule is disabled
+	Threshold float64
+	// Window is the number of most recent evaluations the error rate is calculated over, defaults to 20
+	Window int
+	// MinEvaluations is the minimum number of evaluations in the window before the breaker can trip, defaults to Window
+	MinEvaluations int
+	// CoolDown is how long a rule stays disabled before it is re-enabled, defaults to 30s
+	CoolDown time.Duration
+}
+
+// WithCircuitBreaker disables rules whose evaluation error rate exceeds the threshold
+//
+//	A disabled rule is not evaluated, its result carries an EvaluationError wrapping ErrCircuitOpen so the
+//	ruleset fallback applies. CircuitOpened and CircuitClosed events are emitted on state changes.
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(re *RuleEngine) {
+		if config.Window <= 0 {
+			config.Window = 20
+		}
+		if config.MinEvaluations <= 0 || config.MinEvaluations > config.Window {
+			config.MinEvaluations = config.Window
+		}
+		if config.CoolDown <= 0 {
+			config.CoolDown = 30 * time.Second
+		}
+		re.breakerConfig = &config
+	}
+}
+
+// circuitBreaker tracks the recent evaluation errors of a single rule
+type circuitBreaker struct {
+	mu        sync.Mutex
+	outcomes  []bool
+	next      int
+	errors    int
+	openUntil time.Time
+}
+
+// allow reports whether the rule may be evaluated, closed reports the circuit was re-enabled by this call
+func (cb *circuitBreaker) allow(now time.Time) (allowed bool, closed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.openUntil.IsZero() {
+		return true, false
+	}
+	if now.Before(cb.openUntil) {
+		return false, false
+	}
+	// Cool-down elapsed, start over with an empty window
+	cb.openUntil = time.Time{}
+	cb.outcomes = cb.outcomes[:0]
+	cb.next = 0
+	cb.errors = 0
+	return true, true
+}
+
+// record adds an evaluation outcome, returning the error rate and whether the breaker tripped
+func (cb *circuitBreaker) record(errored bool, config *CircuitBreakerConfig, now time.Time) (float64, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !cb.openUntil.IsZero() {
+		return 0, false
+	}
+	if len(cb.outcomes) < config.Window {
+		cb.outcomes = append(cb.outcomes, errored)
+	} else {
+		if cb.outcomes[cb.next] {
+			cb.errors--
+		}
+		cb.outcomes[cb.next] = errored
+		cb.next = (cb.next + 1) % config.Window
+	}
+	if errored {
+		cb.errors++
+	}
+
+	if len(cb.outcomes) < config.MinEvaluations {
+		return 0, false
+	}
+	rate := float64(cb.errors) / float64(len(cb.outcomes))
+	if rate <= config.Threshold {
+		return rate, false
+	}
+	cb.openUntil = now.Add(config.CoolDown)
+	return rate, true
+}
+
+// breakerAllow checks the circuit breaker of the rule, emitting an event when it is re-enabled
+func (re *RuleEngine) breakerAllow(ruleName string) bool {
+	cb, ok := re.breakers[ruleName]
+	if !ok {
+		return true
+	}
+	allowed, closed := cb.allow(time.Now())
+	if closed {
+		re.emit(CircuitClosed{RuleName: ruleName})
+	}
+	return allowed
+}
+
+// breakerRecord records the evaluation outcome of the rule, emitting an event when the breaker trips
+func (re *RuleEngine) breakerRecord(ruleName string, errored bool) {
+	cb, ok := re.breakers[ruleName]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	rate, tripped := cb.record(errored, re.breakerConfig, now)
+	if tripped {
+		re.emit(CircuitOpened{RuleName: ruleName, ErrorRate: rate, Until: now.Add(re.breakerConfig.CoolDown)})
+	}
+}
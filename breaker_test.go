@@ -0,0 +1,97 @@
+package ruleengine
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRuleEngine_CircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	var events []Event
+	re, err := NewRuleEngine("./testdata/fallback_rules.yml", "", setupEnvironment()(t),
+		WithCircuitBreaker(CircuitBreakerConfig{
+			Threshold: 0.5,
+			Window:    4,
+			CoolDown:  50 * time.Millisecond,
+		}),
+		WithEventHandler(func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	erroring := WithEvalContext(map[string]interface{}{"request": map[string]interface{}{}})
+	healthy := WithEvalContext(map[string]interface{}{"request": map[string]interface{}{"risk_score": 10}})
+
+	// Trip the breaker with a window of errors
+	for i := 0; i < 4; i++ {
+		if _, err := re.EvaluateRule("risk_score", erroring); err != nil {
+			t.Fatalf("EvaluateRule() error = %v", err)
+		}
+	}
+	got, _ := re.EvaluateRule("risk_score", healthy)
+	if got.Passed || !errors.Is(got.Error, ErrCircuitOpen) {
+		t.Errorf("EvaluateRule() = %+v, want circuit open", got)
+	}
+
+	// The ruleset fallback applies while the rule is disabled
+	result, _ := re.EvaluateRuleset("fail_open", healthy)
+	if !result.Passed || !result.Degraded {
+		t.Errorf("EvaluateRuleset() = %+v, want degraded allow", result)
+	}
+
+	// Re-enabled after the cool-down
+	time.Sleep(60 * time.Millisecond)
+	got, _ = re.EvaluateRule("risk_score", healthy)
+	if !got.Passed {
+		t.Errorf("EvaluateRule() = %+v, want passed after cool-down", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("events = %v, want 2 events", events)
+	}
+	opened, ok := events[0].(CircuitOpened)
+	if !ok || opened.RuleName != "risk_score" || opened.ErrorRate != 1 {
+		t.Errorf("events[0] = %+v, want CircuitOpened", events[0])
+	}
+	if closed, ok := events[1].(CircuitClosed); !ok || closed.RuleName != "risk_score" {
+		t.Errorf("events[1] = %+v, want CircuitClosed", events[1])
+	}
+}
+
+func TestCircuitBreaker_Record(t *testing.T) {
+	config := &CircuitBreakerConfig{Threshold: 0.5, Window: 4, MinEvaluations: 4, CoolDown: time.Second}
+	tests := []struct {
+		name     string
+		outcomes []bool
+		want     bool
+	}{
+		{name: "below min evaluations", outcomes: []bool{true, true, true}, want: false},
+		{name: "at threshold", outcomes: []bool{true, true, false, false}, want: false},
+		{name: "above threshold", outcomes: []bool{true, true, true, false}, want: true},
+		{name: "window slides", outcomes: []bool{true, true, false, false, false, false, true, true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := &circuitBreaker{}
+			tripped := false
+			for _, errored := range tt.outcomes {
+				_, trip := cb.record(errored, config, time.Now())
+				tripped = tripped || trip
+				if trip {
+					break
+				}
+			}
+			if tripped != tt.want {
+				t.Errorf("record() tripped = %v, want %v", tripped, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Builder constructs a RuleEngine using a fluent API
+//
+//	engine, err := ruleengine.NewBuilder().
+//		WithConfigFile("rules.yml").
+//		WithEnvironment("production").
+//		WithVariables("user", "request").
+//		WithFunctions(isWeekendFunction).
+//		Build()
+//
+// Defaults: no environment overrides, a CEL env declaring `globals` plus any variables given to
+// WithVariables as dynamic types, and the engine options' own defaults.
+type Builder struct {
+	configPath  string
+	config      *RulesetConfig
+	overlays    []string
+	bundle      []byte
+	defaults    []byte
+	key         []byte
+	environment string
+	profile     string
+	env         *cel.Env
+	variables   []string
+	functions   []cel.EnvOption
+	options     []Option
+}
+
+// NewBuilder creates a new Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// WithConfigFile loads the configuration from the YAML file at path
+func (b *Builder) WithConfigFile(path string) *Builder {
+	b.configPath = path
+	return b
+}
+
+// WithConfig uses an already loaded configuration, the engine takes ownership and may modify it
+func (b *Builder) WithConfig(config *RulesetConfig) *Builder {
+	b.config = config
+	return b
+}
+
+// WithOverlayFiles patches the config file with the YAML overlay files, applied in order
+//
+//	See NewRulesetConfigWithOverlays for how overlays are merged, only valid with WithConfigFile
+func (b *Builder) WithOverlayFiles(paths ...string) *Builder {
+	b.overlays = append(b.overlays, paths...)
+	return b
+}
+
+// WithBundle loads the configuration and checked expressions from a bundle created by RuleEngine.MarshalBundle
+//
+//	The environment and profile applied when compiling the bundle are applied, WithEnvironment must not be set and
+//	WithProfile replaces the profile
+func (b *Builder) WithBundle(data []byte) *Builder {
+	b.bundle = data
+	return b
+}
+
+// WithDefaultConfig falls back to the YAML config data, e.g. a known-good config embedded with go:embed, when the
+// config file or bundle fails to load or build
+//
+//	The engine then runs in degraded mode: a DefaultConfigUsed event is emitted and DefaultConfigErr reports why the
+//	primary config was rejected. The default config is never decrypted, overlays are not applied to it
+func (b *Builder) WithDefaultConfig(data []byte) *Builder {
+	b.defaults = data
+	return b
+}
+
+// WithDecryptionKey decrypts the config file or bundle with key, which must have been encrypted by EncryptConfig
+//
+//	Unencrypted files are rejected once a key is set, so a plaintext file cannot be swapped in by mistake
+func (b *Builder) WithDecryptionKey(key []byte) *Builder {
+	b.key = key
+	return b
+}
+
+// WithEnvironment applies the named environment overrides from the configuration
+func (b *Builder) WithEnvironment(environment string) *Builder {
+	b.environment = environment
+	return b
+}
+
+// WithProfile applies the overrides of the named profile, layered on top of the environment overrides
+func (b *Builder) WithProfile(profile string) *Builder {
+	b.profile = profile
+	return b
+}
+
+// WithCELEnv uses env as the base CEL environment instead of the default one
+func (b *Builder) WithCELEnv(env *cel.Env) *Builder {
+	b.env = env
+	return b
+}
+
+// WithVariables declares dynamically typed context variables, e.g. "user" and "request"
+func (b *Builder) WithVariables(names ...string) *Builder {
+	b.variables = append(b.variables, names...)
+	return b
+}
+
+// WithFunctions adds CEL function declarations, or any other cel.EnvOption, to the CEL environment
+func (b *Builder) WithFunctions(functions ...cel.EnvOption) *Builder {
+	b.functions = append(b.functions, functions...)
+	return b
+}
+
+// WithOptions adds engine options, e.g. WithOptimise()
+func (b *Builder) WithOptions(opts ...Option) *Builder {
+	b.options = append(b.options, opts...)
+	return b
+}
+
+// Build validates the builder settings and creates the RuleEngine
+func (b *Builder) Build() (*RuleEngine, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	engine, err := b.build()
+	if err == nil || b.defaults == nil {
+		return engine, err
+	}
+	engine, defaultErr := b.buildDefault()
+	if defaultErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("failed to load default config: %w", defaultErr))
+	}
+	engine.defaultConfigErr = err
+	engine.emit(DefaultConfigUsed{Err: err})
+	return engine, nil
+}
+
+// build loads the config file, bundle or config and creates the RuleEngine
+func (b *Builder) build() (*RuleEngine, error) {
+	config := b.config
+	options := b.options
+	environment := b.environment
+	if b.bundle != nil {
+		data := b.bundle
+		var p *precompiled
+		var err error
+		if b.key != nil {
+			if data, err = decryptConfig(data, b.key); err != nil {
+				return nil, fmt.Errorf("failed to load bundle: %w", err)
+			}
+		}
+		config, p, err = loadBundle(data, bundleKeyOf(b.options))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bundle: %w", err)
+		}
+		options = append(options[:len(options):len(options)], withPrecompiled(p))
+		environment = p.environment
+		// WithProfile replaces the profile the bundle was compiled with
+		if p.profile != "" && b.profile == "" {
+			options = append([]Option{withProfile(p.profile)}, options...)
+		}
+	}
+	if config == nil {
+		var sources map[string]SourcePosition
+		var err error
+		config, sources, err = loadOverlaidRulesetConfig(b.configPath, b.overlays, b.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		options = append(options[:len(options):len(options)], withSources(sources))
+	}
+	return b.assemble(config, environment, options)
+}
+
+// buildDefault creates the RuleEngine from the WithDefaultConfig data
+func (b *Builder) buildDefault() (*RuleEngine, error) {
+	config, sources, err := parseRulesetConfig("default config", b.defaults)
+	if err != nil {
+		return nil, err
+	}
+	options := append(b.options[:len(b.options):len(b.options)], withSources(sources))
+	return b.assemble(config, b.environment, options)
+}
+
+// assemble applies the environment and profile to the loaded config and creates the RuleEngine
+func (b *Builder) assemble(config *RulesetConfig, environment string, options []Option) (*RuleEngine, error) {
+	if b.environment != "" {
+		if _, ok := config.Environments[b.environment]; !ok {
+			return nil, fmt.Errorf("environment '%s' not found in config", b.environment)
+		}
+	}
+
+	if b.profile != "" {
+		if _, ok := config.Profiles[b.profile]; !ok {
+			return nil, fmt.Errorf("profile '%s' not found in config", b.profile)
+		}
+		options = append([]Option{withProfile(b.profile)}, options...)
+	}
+
+	env, err := b.celEnv(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRuleEngine(config, environment, env, options...)
+}
+
+// validate checks the combination of builder settings
+func (b *Builder) validate() error {
+	var errs []error
+	sources := 0
+	for _, set := range []bool{b.configPath != "", b.config != nil, b.bundle != nil} {
+		if set {
+			sources++
+		}
+	}
+	switch {
+	case sources == 0:
+		errs = append(errs, errors.New("a config is required, use WithConfigFile, WithConfig or WithBundle"))
+	case sources > 1:
+		errs = append(errs, errors.New("WithConfigFile, WithConfig and WithBundle are mutually exclusive"))
+	case b.bundle != nil && b.environment != "":
+		errs = append(errs, errors.New("WithEnvironment cannot be used with WithBundle, the bundle's environment is applied"))
+	case b.config != nil && b.key != nil:
+		errs = append(errs, errors.New("WithDecryptionKey cannot be used with WithConfig, the config is already loaded"))
+	case b.configPath == "" && len(b.overlays) > 0:
+		errs = append(errs, errors.New("WithOverlayFiles can only be used with WithConfigFile"))
+	}
+	if b.key != nil {
+		if _, err := newConfigCipher(b.key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	seen := make(map[string]bool, len(b.variables))
+	for _, name := range b.variables {
+		if name == "" {
+			errs = append(errs, errors.New("variable names must not be empty"))
+			continue
+		}
+		if name == "globals" {
+			errs = append(errs, errors.New("variable 'globals' is reserved for the configured globals"))
+		}
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("variable '%s' declared more than once", name))
+		}
+		seen[name] = true
+	}
+	return errors.Join(errs...)
+}
+
+// celEnv creates the CEL environment from the base env, variables and functions
+//
+//	Variables typed by the config's types are left for the engine to declare
+func (b *Builder) celEnv(config *RulesetConfig) (*cel.Env, error) {
+	opts := make([]cel.EnvOption, 0, len(b.variables)+len(b.functions)+1)
+	for _, name := range b.variables {
+		if config.Types != nil {
+			if _, typed := config.Types.Variables[name]; typed {
+				continue
+			}
+		}
+		opts = append(opts, cel.Variable(name, cel.DynType))
+	}
+	opts = append(opts, b.functions...)
+
+	if b.env != nil {
+		env, err := b.env.Extend(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extend cel env: %w", err)
+		}
+		return env, nil
+	}
+
+	opts = append([]cel.EnvOption{cel.Variable("globals", cel.DynType)}, opts...)
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel env: %w", err)
+	}
+	return env, nil
+}
@@ -0,0 +1,101 @@
+package ruleengine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	isWeekendFunction := cel.Function("is_weekend",
+		cel.Overload("is_weekend_timestamp", []*cel.Type{cel.TimestampType}, cel.BoolType,
+			cel.UnaryBinding(func(val ref.Val) ref.Val {
+				day := val.Value().(time.Time).Weekday()
+				return types.Bool(day == time.Saturday || day == time.Sunday)
+			}),
+		),
+	)
+	tests := []struct {
+		name    string
+		builder func(*testing.T) *Builder
+		wantErr bool
+	}{
+		{
+			name: "success - file, environment, variables and functions",
+			builder: func(t *testing.T) *Builder {
+				return NewBuilder().
+					WithConfigFile("./testdata/rules.yml").
+					WithEnvironment("production").
+					WithVariables("user", "request").
+					WithFunctions(isWeekendFunction).
+					WithOptions(WithOptimise())
+			},
+		},
+		{
+			name: "success - loaded config and cel env",
+			builder: func(t *testing.T) *Builder {
+				config, err := NewRulesetConfig("./testdata/rules.yml")
+				if err != nil {
+					t.Fatalf("NewRulesetConfig() error = %v", err)
+				}
+				return NewBuilder().WithConfig(config).WithCELEnv(setupEnvironment()(t))
+			},
+		},
+		{
+			name: "fail - no config",
+			builder: func(t *testing.T) *Builder {
+				return NewBuilder().WithVariables("user", "request")
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail - config file and config",
+			builder: func(t *testing.T) *Builder {
+				return NewBuilder().WithConfigFile("./testdata/rules.yml").WithConfig(&RulesetConfig{})
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail - reserved variable",
+			builder: func(t *testing.T) *Builder {
+				return NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request", "globals")
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail - duplicate variable",
+			builder: func(t *testing.T) *Builder {
+				return NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "user")
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail - unknown environment",
+			builder: func(t *testing.T) *Builder {
+				return NewBuilder().
+					WithConfigFile("./testdata/rules.yml").
+					WithEnvironment("staging").
+					WithVariables("user", "request")
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail - undeclared variables",
+			builder: func(t *testing.T) *Builder {
+				return NewBuilder().WithConfigFile("./testdata/rules.yml")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder(t).Build()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
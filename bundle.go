@@ -0,0 +1,327 @@
+package ruleengine
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+)
+
+// bundleMagic prefixes every bundle, followed by the format version and the SHA-256 digest or HMAC of the payload
+const bundleMagic = "CELRULESBUNDLE"
+
+// bundleVersion is the current bundle format version
+const bundleVersion = 2
+
+// ErrBundleDigest is returned when a bundle payload does not match its digest, e.g. it was truncated or modified, or
+// was signed with a different bundle key
+var ErrBundleDigest = errors.New("bundle digest mismatch")
+
+func init() {
+	// Globals decoded from YAML hold these types behind interface{} values
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+}
+
+// BundleMetadata describes a compiled bundle, see MarshalBundle
+type BundleMetadata struct {
+	// Name is the name from the config metadata
+	Name string
+	// Environment is the environment whose overrides are applied when loading, as they were when compiling
+	Environment string
+	// Profile is the profile whose overrides are applied when loading, as they were when compiling, none when empty
+	Profile string
+	// CreatedAt is the time the bundle was compiled
+	CreatedAt time.Time
+	// Expressions is the number of checked expressions in the bundle
+	Expressions int
+	// Digest is the hex encoded SHA-256 digest of the bundle payload, or its HMAC-SHA256 if signed with a bundle key,
+	// verified when loading
+	Digest string
+}
+
+// bundlePayload is the gob encoded content of a bundle
+type bundlePayload struct {
+	Metadata BundleMetadata
+	Config   *RulesetConfig
+	// EnvHash identifies the CEL env the expressions were checked against
+	EnvHash string
+	// Checked is a map of expressions to their serialized checked ASTs
+	Checked map[string][]byte
+	// Outcomes is a map of the decision expressions, checked against the `rulesets` outcomes rather than the CEL
+	// env, to their serialized checked ASTs
+	Outcomes map[string][]byte
+}
+
+// precompiled holds the checked ASTs loaded from a bundle
+type precompiled struct {
+	// environment and profile are the overrides applied when compiling, applied once to the config as given
+	environment string
+	profile     string
+	envHash     string
+	asts        map[string]*cel.Ast
+	// outcomes are the checked ASTs of the decision expressions
+	outcomes map[string]*cel.Ast
+}
+
+// WithBundleKey signs bundles created by MarshalBundle with an HMAC-SHA256 of key and verifies it when loading bundles
+//
+//	Pass the same key to NewRuleEngineFromBundle, ReadBundleMetadata or the Builder options. A bundle signed with a
+//	different key, or not signed at all, is rejected with ErrBundleDigest, so only holders of the key can create
+//	bundles the engine accepts
+func WithBundleKey(key []byte) Option {
+	return func(re *RuleEngine) {
+		re.bundleKey = key
+	}
+}
+
+// bundleKeyOf returns the key set by WithBundleKey in opts, nil if none
+func bundleKeyOf(opts []Option) []byte {
+	var re RuleEngine
+	for _, opt := range opts {
+		opt(&re)
+	}
+	return re.bundleKey
+}
+
+// bundleDigest returns the HMAC-SHA256 of body with key, or its SHA-256 digest if key is nil
+func bundleDigest(body, key []byte) []byte {
+	if key == nil {
+		digest := sha256.Sum256(body)
+		return digest[:]
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// withPrecompiled uses the checked ASTs of a bundle instead of parsing and checking expressions
+func withPrecompiled(p *precompiled) Option {
+	return func(re *RuleEngine) {
+		re.precompiled = p
+	}
+}
+
+// MarshalBundle serializes the config as given, the names of its environment and profile overrides, and the checked
+// AST of every expression once they are applied, decision expressions included, into a single binary bundle
+//
+//	NewRuleEngineFromBundle loads the bundle without YAML parsing or CEL checking. The payload is protected by a
+//	SHA-256 digest which detects corrupted bundles, sign it with WithBundleKey to also detect bundles modified or
+//	created without the key
+func (re *RuleEngine) MarshalBundle() ([]byte, error) {
+	if active := re.Active(); active != re {
+		return active.MarshalBundle()
+	}
+	payload := bundlePayload{
+		Metadata: BundleMetadata{
+			Name:        re.config.Metadata.Name,
+			Environment: re.environment,
+			Profile:     re.profile,
+			CreatedAt:   time.Now().UTC(),
+		},
+		Config:   re.base,
+		EnvHash:  re.envHash(),
+		Checked:  make(map[string][]byte),
+		Outcomes: make(map[string][]byte),
+	}
+	for _, expression := range re.expressions() {
+		checked, err := re.checkExpression(expression)
+		if err != nil {
+			return nil, err
+		}
+		if payload.Checked[expression], err = marshalChecked(expression, checked); err != nil {
+			return nil, err
+		}
+	}
+	env, err := outcomeEnv()
+	if err != nil {
+		return nil, err
+	}
+	for _, expression := range re.outcomeExpressions() {
+		checked, err := re.checkOutcomeExpression(env, expression)
+		if err != nil {
+			return nil, err
+		}
+		if payload.Outcomes[expression], err = marshalChecked(expression, checked); err != nil {
+			return nil, err
+		}
+	}
+	payload.Metadata.Expressions = len(payload.Checked) + len(payload.Outcomes)
+
+	var body bytes.Buffer
+	if err := gob.NewEncoder(&body).Encode(payload); err != nil {
+		return nil, fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	digest := bundleDigest(body.Bytes(), re.bundleKey)
+
+	var out bytes.Buffer
+	out.Grow(len(bundleMagic) + 1 + len(digest) + body.Len())
+	out.WriteString(bundleMagic)
+	out.WriteByte(bundleVersion)
+	out.Write(digest)
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// ReadBundleMetadata verifies a bundle and returns its metadata, pass WithBundleKey to verify a signed bundle
+func ReadBundleMetadata(data []byte, opts ...Option) (BundleMetadata, error) {
+	payload, err := decodeBundle(data, bundleKeyOf(opts))
+	if err != nil {
+		return BundleMetadata{}, err
+	}
+	return payload.Metadata, nil
+}
+
+// NewRuleEngineFromBundle creates a rule engine from a bundle created by MarshalBundle
+//
+//	env must declare the same variables and functions as the env the bundle was compiled with,
+//	errors are returned if the bundle is invalid, does not match its digest or was compiled for a different env. A
+//	signed bundle is verified against the key given with WithBundleKey
+func NewRuleEngineFromBundle(data []byte, env *cel.Env, opts ...Option) (*RuleEngine, error) {
+	config, p, err := loadBundle(data, bundleKeyOf(opts))
+	if err != nil {
+		return nil, err
+	}
+	if p.profile != "" {
+		opts = append([]Option{withProfile(p.profile)}, opts...)
+	}
+	return newRuleEngine(config, p.environment, env, append(opts[:len(opts):len(opts)], withPrecompiled(p))...)
+}
+
+// loadBundle verifies a bundle with key, nil if unsigned, and decodes it into its config and checked ASTs
+func loadBundle(data, key []byte) (*RulesetConfig, *precompiled, error) {
+	payload, err := decodeBundle(data, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &precompiled{
+		environment: payload.Metadata.Environment,
+		profile:     payload.Metadata.Profile,
+		envHash:     payload.EnvHash,
+		asts:        make(map[string]*cel.Ast, len(payload.Checked)),
+		outcomes:    make(map[string]*cel.Ast, len(payload.Outcomes)),
+	}
+	for expression, data := range payload.Checked {
+		if p.asts[expression], err = unmarshalChecked(expression, data); err != nil {
+			return nil, nil, err
+		}
+	}
+	for expression, data := range payload.Outcomes {
+		if p.outcomes[expression], err = unmarshalChecked(expression, data); err != nil {
+			return nil, nil, err
+		}
+	}
+	// The overrides and references were validated when compiling
+	payload.Config.StrictEnvironments = false
+	payload.Config.StrictReferences = false
+	return payload.Config, p, nil
+}
+
+// decodeBundle verifies the header and digest of a bundle, the HMAC with key unless nil, and decodes its payload
+func decodeBundle(data, key []byte) (bundlePayload, error) {
+	header := len(bundleMagic) + 1 + sha256.Size
+	if len(data) < header || string(data[:len(bundleMagic)]) != bundleMagic {
+		return bundlePayload{}, errors.New("not a ruleengine bundle")
+	}
+	if version := data[len(bundleMagic)]; version != bundleVersion {
+		return bundlePayload{}, fmt.Errorf("unsupported bundle version %d, want %d", version, bundleVersion)
+	}
+	body := data[header:]
+	digest := bundleDigest(body, key)
+	if !hmac.Equal(digest, data[len(bundleMagic)+1:header]) {
+		return bundlePayload{}, ErrBundleDigest
+	}
+
+	var payload bundlePayload
+	if err := gob.NewDecoder(bytes.NewReader(body)).Decode(&payload); err != nil {
+		return bundlePayload{}, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	if payload.Config == nil {
+		return bundlePayload{}, errors.New("bundle has no config")
+	}
+	payload.Metadata.Digest = hex.EncodeToString(digest)
+	return payload, nil
+}
+
+// marshalChecked serializes the checked AST of an expression
+func marshalChecked(expression string, checked *cel.Ast) ([]byte, error) {
+	pb, err := cel.AstToCheckedExpr(checked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert checked expression '%s': %w", expression, err)
+	}
+	data, err := proto.Marshal(pb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal checked expression '%s': %w", expression, err)
+	}
+	return data, nil
+}
+
+// unmarshalChecked loads the checked AST of an expression serialized by marshalChecked
+func unmarshalChecked(expression string, data []byte) (*cel.Ast, error) {
+	pb := &exprpb.CheckedExpr{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checked expression '%s': %w", expression, err)
+	}
+	checked, err := cel.CheckedExprToAstWithSource(pb, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checked expression '%s': %w", expression, err)
+	}
+	return checked, nil
+}
+
+// expressions returns every rule expression, when clause, ruleset precondition and computed field in sorted order
+func (re *RuleEngine) expressions() []string {
+	seen := make(map[string]bool)
+	for _, name := range re.ruleNames() {
+		rule := re.config.Rules[name]
+		seen[rule.Expression] = true
+		if rule.When != "" {
+			seen[rule.When] = true
+		}
+	}
+	for _, ruleset := range re.config.Rulesets {
+		if ruleset.Precondition != "" {
+			seen[ruleset.Precondition] = true
+		}
+	}
+	for _, expression := range re.config.Computed {
+		seen[expression] = true
+	}
+	expressions := make([]string, 0, len(seen))
+	for expression := range seen {
+		expressions = append(expressions, expression)
+	}
+	sort.Strings(expressions)
+	return expressions
+}
+
+// outcomeExpressions returns the decision expression and the condition of every decision outcome in sorted order
+func (re *RuleEngine) outcomeExpressions() []string {
+	seen := make(map[string]bool)
+	if re.config.Decision != "" {
+		seen[re.config.Decision] = true
+	}
+	for _, decision := range re.config.Decisions {
+		for _, outcome := range decision.Outcomes {
+			if outcome.When != "" {
+				seen[outcome.When] = true
+			}
+		}
+	}
+	expressions := make([]string, 0, len(seen))
+	for expression := range seen {
+		expressions = append(expressions, expression)
+	}
+	sort.Strings(expressions)
+	return expressions
+}
@@ -0,0 +1,275 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newBundle compiles testdata/rules.yml with production overrides into a bundle
+func newBundle(t *testing.T, opts ...Option) []byte {
+	t.Helper()
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/rules.yml").
+		WithEnvironment("production").
+		WithVariables("user", "request").
+		WithOptions(opts...).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	data, err := re.MarshalBundle()
+	if err != nil {
+		t.Fatalf("MarshalBundle() error = %v", err)
+	}
+	return data
+}
+
+func TestRuleEngine_MarshalBundle(t *testing.T) {
+	data := newBundle(t)
+
+	metadata, err := ReadBundleMetadata(data)
+	if err != nil {
+		t.Fatalf("ReadBundleMetadata() error = %v", err)
+	}
+	if metadata.Environment != "production" || metadata.Expressions == 0 || len(metadata.Digest) != 64 {
+		t.Errorf("ReadBundleMetadata() = %+v, want production environment, expressions and digest", metadata)
+	}
+
+	original, err := NewBuilder().
+		WithConfigFile("./testdata/rules.yml").
+		WithEnvironment("production").
+		WithVariables("user", "request").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	bundled, err := NewBuilder().
+		WithBundle(data).
+		WithVariables("user", "request").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() from bundle error = %v", err)
+	}
+	if got := len(bundled.precompiled.asts) + len(bundled.precompiled.outcomes); got != metadata.Expressions {
+		t.Errorf("Build() from bundle loaded %d expressions, want %d", got, metadata.Expressions)
+	}
+
+	ctx := map[string]interface{}{
+		"user": map[string]interface{}{
+			"age": 16, "email": "test@company.com", "status": "active", "suspended": false,
+		},
+		"request": map[string]interface{}{"attempt": 2},
+	}
+	// The production policy times out EvaluateAllRulesets, so rulesets are evaluated one by one
+	passed := func(re *RuleEngine) map[string]bool {
+		got := make(map[string]bool)
+		for _, name := range re.rulesetNames() {
+			result, err := re.EvaluateRuleset(name, WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			got[name] = result.Passed
+		}
+		return got
+	}
+	if diff := cmp.Diff(passed(bundled), passed(original)); diff != "" {
+		t.Errorf("EvaluateRuleset() bundled (-got +want):\n%s", diff)
+	}
+	if got := bundled.config.Globals["min_age"]; got != 18 {
+		t.Errorf("Build() from bundle globals min_age = %v, want production override 18", got)
+	}
+}
+
+func TestRuleEngine_MarshalBundle_Decisions(t *testing.T) {
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/decision_rules.yml").
+		WithVariables("user", "request").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	data, err := re.MarshalBundle()
+	if err != nil {
+		t.Fatalf("MarshalBundle() error = %v", err)
+	}
+	metadata, err := ReadBundleMetadata(data)
+	if err != nil {
+		t.Fatalf("ReadBundleMetadata() error = %v", err)
+	}
+	// Two rule expressions and the two outcome conditions
+	if metadata.Expressions != 4 {
+		t.Errorf("ReadBundleMetadata() expressions = %d, want 4", metadata.Expressions)
+	}
+	bundled, err := NewBuilder().WithBundle(data).WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("Build() from bundle error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"!rulesets.sanctions", "!rulesets.velocity"}, sortedNames(bundled.precompiled.outcomes)); diff != "" {
+		t.Errorf("Build() from bundle outcome expressions mismatch (-want +got):\n%s", diff)
+	}
+	result, err := bundled.Decide("payment", WithEvalContext(map[string]interface{}{
+		"user":    map[string]interface{}{"sanctioned": false},
+		"request": map[string]interface{}{"attempts": 7},
+	}))
+	if err != nil {
+		t.Fatalf("Decide() error = %v", err)
+	}
+	if result.Outcome != "REVIEW" {
+		t.Errorf("Decide() outcome = %s, want REVIEW", result.Outcome)
+	}
+}
+
+func TestNewRuleEngineFromBundle(t *testing.T) {
+	data := newBundle(t)
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 0xff
+	key := []byte("bundle-signing-key")
+	signed := newBundle(t, WithBundleKey(key))
+
+	tests := []struct {
+		name      string
+		data      []byte
+		variables []string
+		opts      []Option
+		wantErr   error
+		wantMsg   string
+	}{
+		{
+			name:      "success - loads bundle",
+			data:      data,
+			variables: []string{"user", "request"},
+		},
+		{
+			name:      "fail - tampered bundle",
+			data:      tampered,
+			variables: []string{"user", "request"},
+			wantErr:   ErrBundleDigest,
+		},
+		{
+			name:      "success - signed bundle with key",
+			data:      signed,
+			variables: []string{"user", "request"},
+			opts:      []Option{WithBundleKey(key)},
+		},
+		{
+			name:      "fail - signed bundle with wrong key",
+			data:      signed,
+			variables: []string{"user", "request"},
+			opts:      []Option{WithBundleKey([]byte("other-key"))},
+			wantErr:   ErrBundleDigest,
+		},
+		{
+			name:      "fail - signed bundle without key",
+			data:      signed,
+			variables: []string{"user", "request"},
+			wantErr:   ErrBundleDigest,
+		},
+		{
+			name:      "fail - unsigned bundle with key",
+			data:      data,
+			variables: []string{"user", "request"},
+			opts:      []Option{WithBundleKey(key)},
+			wantErr:   ErrBundleDigest,
+		},
+		{
+			name:      "fail - not a bundle",
+			data:      []byte("rules:\n"),
+			variables: []string{"user", "request"},
+			wantMsg:   "not a ruleengine bundle",
+		},
+		{
+			name:      "fail - different env",
+			data:      data,
+			variables: []string{"user"},
+			wantMsg:   "bundle was compiled against a different cel env",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuilder().WithVariables(tt.variables...)
+			env, err := b.celEnv(&RulesetConfig{})
+			if err != nil {
+				t.Fatalf("failed to create cel env: %v", err)
+			}
+
+			_, err = NewRuleEngineFromBundle(tt.data, env, tt.opts...)
+			if (err != nil) != (tt.wantErr != nil || tt.wantMsg != "") {
+				t.Fatalf("NewRuleEngineFromBundle() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewRuleEngineFromBundle() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("NewRuleEngineFromBundle() error = %v, want %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestBuilder_WithBundle_Environment(t *testing.T) {
+	_, err := NewBuilder().
+		WithBundle(newBundle(t)).
+		WithEnvironment("production").
+		WithVariables("user", "request").
+		Build()
+	if err == nil || !strings.Contains(err.Error(), "WithEnvironment cannot be used with WithBundle") {
+		t.Errorf("Build() error = %v, want WithEnvironment conflict", err)
+	}
+}
+
+func TestNewRuleEngineFromBundle_AppliesOverridesOnce(t *testing.T) {
+	config := rolloutConfig("user.age >= 18")
+	config.GlobalsMerge = GlobalsMerge{Deep: true, Lists: ListAppend}
+	config.Globals = map[string]interface{}{"countries": []interface{}{"AU"}}
+	config.Environments = map[string]Environment{
+		"production": {Globals: map[string]interface{}{"countries": []interface{}{"NZ"}}},
+	}
+	config.Profiles = map[string]Profile{
+		"eu": {Globals: map[string]interface{}{"countries": []interface{}{"DE"}}},
+		"us": {Globals: map[string]interface{}{"countries": []interface{}{"US"}}},
+	}
+	re, err := NewBuilder().WithConfig(config).WithEnvironment("production").WithProfile("eu").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	// Each bundle is compiled from the engine loaded from the previous one
+	for i := 0; i < 2; i++ {
+		data, err := re.MarshalBundle()
+		if err != nil {
+			t.Fatalf("MarshalBundle() error = %v", err)
+		}
+		if re, err = NewBuilder().WithBundle(data).WithVariables("user").Build(); err != nil {
+			t.Fatalf("Build() from bundle error = %v", err)
+		}
+		if diff := cmp.Diff([]interface{}{"AU", "NZ", "DE"}, re.config.Globals["countries"]); diff != "" {
+			t.Errorf("globals of bundle %d mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+
+	data, err := re.MarshalBundle()
+	if err != nil {
+		t.Fatalf("MarshalBundle() error = %v", err)
+	}
+	replaced, err := NewBuilder().WithBundle(data).WithProfile("us").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() from bundle error = %v", err)
+	}
+	if diff := cmp.Diff([]interface{}{"AU", "NZ", "US"}, replaced.config.Globals["countries"]); diff != "" {
+		t.Errorf("globals with the profile replaced mismatch (-want +got):\n%s", diff)
+	}
+
+	// Drafts of an engine loaded from a bundle apply the overrides to the config as given
+	drafts := NewDrafts(re)
+	if err := drafts.PutRule("alice", "age_validation", Rule{Expression: "user.age >= 21"}); err != nil {
+		t.Fatalf("PutRule() error = %v", err)
+	}
+	if _, err := drafts.Commit("alice", "raise age"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if diff := cmp.Diff([]interface{}{"AU", "NZ", "DE"}, re.Active().config.Globals["countries"]); diff != "" {
+		t.Errorf("globals after Commit() mismatch (-want +got):\n%s", diff)
+	}
+}
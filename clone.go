@@ -0,0 +1,108 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrCloneRecompile is returned by Clone for options changing how expressions are compiled
+var ErrCloneRecompile = errors.New("option requires recompiling, build a new engine instead")
+
+// WithPolicy overrides the execution policy selected by the config's error_handling, e.g. a clone stopping at the
+// first failure of a ruleset
+func WithPolicy(policy Policy) Option {
+	return func(re *RuleEngine) {
+		re.policy = policy
+	}
+}
+
+// Clone returns an engine sharing the config and compiled programs of the active engine, with opts applied on top of
+// the options it was created with
+//
+//	Clones are cheap per-tenant or per-request specializations: a different policy with WithPolicy, event handler,
+//	interceptors, result transformers, context limits, circuit breakers or result cache, without compiling any
+//	expression again. The clone starts with its own empty SetContext context, statistics and circuit breakers, and
+//	nothing staged, it shares the result cache unless given WithResultCache. Options changing how expressions are
+//	compiled, WithOptimise, WithHTTPGet, WithStateStore, WithCostBudget, WithExplanations, WithProfiler,
+//	WithListIndex, WithCompileCache, WithQuarantine, WithSkipMissingRules, WithSandbox and WithMaxPrograms, fail
+//	with ErrCloneRecompile
+func (re *RuleEngine) Clone(opts ...Option) (*RuleEngine, error) {
+	if active := re.Active(); active != re {
+		return active.Clone(opts...)
+	}
+	c := *re
+	c.transformers = re.transformers[:len(re.transformers):len(re.transformers)]
+	c.interceptors = re.interceptors[:len(re.interceptors):len(re.interceptors)]
+	// Options are applied to a probe store, WithMaxPrograms must not change the shared programs
+	c.programs = &programStore{max: re.programs.max}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if re.recompiles(&c) {
+		return nil, ErrCloneRecompile
+	}
+	c.programs = re.programs
+	if c.queue != re.queue {
+		if err := c.queue.init(); err != nil {
+			return nil, fmt.Errorf("invalid evaluation queue: %w", err)
+		}
+	}
+	c.options = append(re.options[:len(re.options):len(re.options)], opts...)
+	c.context = make(map[string]interface{})
+	c.rollout = &rollout{}
+	c.counters = make(map[string]*ruleCounters, len(re.counters))
+	c.breakers = make(map[string]*circuitBreaker, len(re.counters))
+	for name := range re.counters {
+		c.counters[name] = &ruleCounters{}
+		if c.breakerConfig != nil {
+			c.breakers[name] = &circuitBreaker{}
+		}
+	}
+	if c.resultCache != re.resultCache {
+		c.prepareResultCache(re.resultCache)
+	}
+	return &c, nil
+}
+
+// recompiles reports whether the clone was given options changing how the expressions of the engine are compiled
+func (re *RuleEngine) recompiles(clone *RuleEngine) bool {
+	return clone.optimise != re.optimise ||
+		clone.httpGet != re.httpGet ||
+		clone.state != re.state ||
+		clone.costBudget != re.costBudget ||
+		!sameMap(clone.explainers, re.explainers) ||
+		clone.profiler != re.profiler ||
+		clone.listIndexSize != re.listIndexSize ||
+		clone.compileCache != re.compileCache ||
+		!sameMap(clone.quarantined, re.quarantined) ||
+		!sameMap(clone.missingRules, re.missingRules) ||
+		clone.sandbox != re.sandbox ||
+		clone.programs.max != re.programs.max
+}
+
+// sameMap reports whether a and b are the same map, not merely equal
+func sameMap(a, b interface{}) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// prepareResultCache records the paths read by the rules in the new result cache of a clone, reusing those of the
+// result cache it replaces if any
+func (re *RuleEngine) prepareResultCache(previous *resultCache) {
+	if re.resultCache == nil {
+		return
+	}
+	if previous != nil {
+		for name, paths := range previous.paths {
+			re.resultCache.paths[name] = paths
+		}
+		return
+	}
+	for name := range re.counters {
+		checked, err := re.checkExpression(re.config.Rules[name].Expression)
+		if err != nil {
+			continue
+		}
+		re.resultCache.prepare(re, name, checked)
+	}
+}
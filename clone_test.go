@@ -0,0 +1,103 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRuleEngine_Clone(t *testing.T) {
+	config := &RulesetConfig{
+		Rules: map[string]Rule{
+			"age_validation": {Expression: "user.age >= 18"},
+			"email_verified": {Expression: "user.verified"},
+		},
+		Rulesets: map[string]Ruleset{
+			"signup": {Selector: selectorAnd, Rules: []string{"age_validation", "email_verified"}},
+		},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+	}
+	re, err := NewBuilder().WithConfig(config).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	ctx := WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 16, "verified": true}})
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantResults int
+		wantErr     error
+	}{
+		{
+			name:        "success - same options",
+			wantResults: 2,
+		},
+		{
+			name:        "success - stop on failure policy",
+			opts:        []Option{WithPolicy(Policy{StopOnFailure: true})},
+			wantResults: 1,
+		},
+		{
+			name:        "success - result cache",
+			opts:        []Option{WithResultCache(10)},
+			wantResults: 2,
+		},
+		{
+			name:    "fail - optimise",
+			opts:    []Option{WithOptimise()},
+			wantErr: ErrCloneRecompile,
+		},
+		{
+			name:    "fail - max programs",
+			opts:    []Option{WithMaxPrograms(1)},
+			wantErr: ErrCloneRecompile,
+		},
+		{
+			name:    "fail - explanations",
+			opts:    []Option{WithExplanations()},
+			wantErr: ErrCloneRecompile,
+		},
+		{
+			name:    "fail - skip missing rules",
+			opts:    []Option{WithSkipMissingRules()},
+			wantErr: ErrCloneRecompile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clone, err := re.Clone(tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Clone() error = %v, want %v", err, tt.wantErr)
+			}
+			if re.programs.max != 0 {
+				t.Fatalf("Clone() changed the program cap of the engine to %d", re.programs.max)
+			}
+			if err != nil {
+				return
+			}
+			if clone.programs != re.programs {
+				t.Error("Clone() did not share the compiled programs")
+			}
+			for i := 0; i < 2; i++ {
+				result, err := clone.EvaluateRuleset("signup", ctx)
+				if err != nil {
+					t.Fatalf("EvaluateRuleset() error = %v", err)
+				}
+				if len(result.RuleResults) != tt.wantResults {
+					t.Errorf("EvaluateRuleset() evaluated %d rules, want %d", len(result.RuleResults), tt.wantResults)
+				}
+			}
+			if clone.resultCache != nil && clone.ResultCacheStats().Hits == 0 {
+				t.Errorf("ResultCacheStats() = %+v, want hits from the second evaluation", clone.ResultCacheStats())
+			}
+			if got := re.Stats().Rules["age_validation"].Failed; got != 0 {
+				t.Errorf("Stats() of the engine counted %d clone evaluations", got)
+			}
+			clone.SetContext(map[string]interface{}{"user": map[string]interface{}{"age": 30}})
+			if len(re.context) != 0 {
+				t.Errorf("SetContext() on the clone set the engine context to %v", re.context)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Command ruleengine-wasm exposes the rule engine to JavaScript when built for WebAssembly
+//
+//	GOOS=js GOARCH=wasm go build -o ruleengine.wasm ./cmd/ruleengine-wasm
+//
+// Loading the module with Go's wasm_exec.js defines a global `ruleengine` object:
+//
+//	ruleengine.load(yaml, {environment: "production", variables: ["user", "request"]})
+//	ruleengine.evaluate("user_registration", {user: {age: 21}})   // evaluates a ruleset
+//	ruleengine.evaluateRule("age_validation", {user: {age: 21}})
+//
+// Every function returns an object, failures are reported in its `error` field.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// loadOptions are the options accepted by load
+type loadOptions struct {
+	Environment string   `json:"environment"`
+	Variables   []string `json:"variables"`
+}
+
+// result is the JSON representation of an evaluation result
+type result struct {
+	Name        string            `json:"name"`
+	Passed      bool              `json:"passed"`
+	Error       string            `json:"error,omitempty"`
+	Skipped     bool              `json:"skipped,omitempty"`
+	ReasonCode  string            `json:"reason_code,omitempty"`
+	ReasonCodes []string          `json:"reason_codes,omitempty"`
+	Rules       map[string]result `json:"rules,omitempty"`
+}
+
+// session holds the engine loaded by load
+type session struct {
+	engine *ruleengine.RuleEngine
+}
+
+// load replaces the engine with one created from a YAML config and JSON encoded loadOptions
+func (s *session) load(config string, options string) error {
+	opts := loadOptions{Variables: []string{"user", "request"}}
+	if options != "" {
+		if err := json.Unmarshal([]byte(options), &opts); err != nil {
+			return fmt.Errorf("failed to parse options: %w", err)
+		}
+	}
+	rc, err := ruleengine.ParseRulesetConfig([]byte(config))
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	engine, err := ruleengine.NewBuilder().
+		WithConfig(rc).
+		WithEnvironment(opts.Environment).
+		WithVariables(opts.Variables...).
+		Build()
+	if err != nil {
+		return err
+	}
+	s.engine = engine
+	return nil
+}
+
+// evaluate evaluates a ruleset, or a rule when rule is true, against a JSON context and returns the JSON result
+func (s *session) evaluate(name string, context string, rule bool) (string, error) {
+	if s.engine == nil {
+		return "", errors.New("no config loaded, call ruleengine.load first")
+	}
+	ctx := make(map[string]interface{})
+	if context != "" {
+		if err := json.Unmarshal([]byte(context), &ctx); err != nil {
+			return "", fmt.Errorf("failed to parse context: %w", err)
+		}
+	}
+
+	var out result
+	if rule {
+		r, err := s.engine.EvaluateRule(name, ruleengine.WithEvalContext(ctx))
+		if err != nil {
+			return "", err
+		}
+		out = ruleResult(r)
+	} else {
+		r, err := s.engine.EvaluateRuleset(name, ruleengine.WithEvalContext(ctx))
+		if err != nil {
+			return "", err
+		}
+		out = rulesetResult(r)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(data), nil
+}
+
+// ruleResult converts a RuleResult into its JSON representation
+func ruleResult(r ruleengine.RuleResult) result {
+	out := result{Name: r.RuleName, Passed: r.Passed, Skipped: r.Skipped, ReasonCode: r.ReasonCode}
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	return out
+}
+
+// rulesetResult converts a RulesetResult into its JSON representation
+func rulesetResult(r ruleengine.RulesetResult) result {
+	out := result{
+		Name:        r.RulesetName,
+		Passed:      r.Passed,
+		Skipped:     r.Skipped,
+		ReasonCodes: r.ReasonCodes,
+		Rules:       make(map[string]result, len(r.RuleResults)),
+	}
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	for name, rr := range r.RuleResults {
+		out.Rules[name] = ruleResult(rr)
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSession(t *testing.T) {
+	config, err := os.ReadFile("../../testdata/reason_rules.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		config  string
+		options string
+		target  string
+		context string
+		rule    bool
+		want    result
+		wantErr bool
+	}{
+		{
+			name:    "fail - invalid config",
+			config:  "rules: [",
+			wantErr: true,
+		},
+		{
+			name:    "fail - invalid options",
+			config:  string(config),
+			options: "{",
+			wantErr: true,
+		},
+		{
+			name:    "fail - invalid context",
+			config:  string(config),
+			target:  "kyc",
+			context: "{",
+			wantErr: true,
+		},
+		{
+			name:    "fail - unknown ruleset",
+			config:  string(config),
+			target:  "unknown",
+			wantErr: true,
+		},
+		{
+			name:    "success - evaluate rule",
+			config:  string(config),
+			target:  "age_validation",
+			context: `{"user": {"age": 15}}`,
+			rule:    true,
+			want: result{
+				Name:       "age_validation",
+				Error:      "rule 'age_validation' did not pass evaluation",
+				ReasonCode: "KYC_001",
+			},
+		},
+		{
+			name:    "success - evaluate ruleset",
+			config:  string(config),
+			options: `{"variables": ["user"]}`,
+			target:  "any_kyc",
+			context: `{"user": {"age": 21, "verified": false}}`,
+			want: result{
+				Name:   "any_kyc",
+				Passed: true,
+				Rules: map[string]result{
+					"age_validation": {Name: "age_validation", Passed: true},
+					"identity_verified": {
+						Name:       "identity_verified",
+						Error:      "rule 'identity_verified' did not pass evaluation",
+						ReasonCode: "KYC_002",
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &session{}
+			err := s.load(tt.config, tt.options)
+			if err == nil {
+				var out string
+				out, err = s.evaluate(tt.target, tt.context, tt.rule)
+				if err == nil {
+					var got result
+					if err := json.Unmarshal([]byte(out), &got); err != nil {
+						t.Fatalf("evaluate() returned invalid JSON: %v", err)
+					}
+					if diff := cmp.Diff(got, tt.want); diff != "" {
+						t.Errorf("evaluate() (-got +want):\n%s", diff)
+					}
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("session error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSession_NotLoaded(t *testing.T) {
+	s := &session{}
+	if _, err := s.evaluate("kyc", "", false); err == nil {
+		t.Error("evaluate() expected an error before load")
+	}
+}
@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+)
+
+func main() {
+	s := &session{}
+	js.Global().Set("ruleengine", js.ValueOf(map[string]interface{}{
+		"load": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if len(args) < 1 {
+				return errorValue("usage: ruleengine.load(yaml, options)")
+			}
+			if err := s.load(args[0].String(), stringify(args, 1)); err != nil {
+				return errorValue(err.Error())
+			}
+			return js.ValueOf(map[string]interface{}{})
+		}),
+		"evaluate": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return evaluate(s, args, false)
+		}),
+		"evaluateRule": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return evaluate(s, args, true)
+		}),
+	}))
+	// Keep the exported functions alive
+	select {}
+}
+
+// evaluate evaluates the ruleset or rule named by args[0] against the context object args[1]
+func evaluate(s *session, args []js.Value, rule bool) interface{} {
+	if len(args) < 1 {
+		return errorValue("usage: ruleengine.evaluate(name, context)")
+	}
+	out, err := s.evaluate(args[0].String(), stringify(args, 1), rule)
+	if err != nil {
+		return errorValue(err.Error())
+	}
+	return js.Global().Get("JSON").Call("parse", out)
+}
+
+// stringify returns args[i] encoded as JSON, empty if it is missing or undefined
+func stringify(args []js.Value, i int) string {
+	if len(args) <= i || args[i].IsUndefined() || args[i].IsNull() {
+		return ""
+	}
+	if args[i].Type() == js.TypeString {
+		return args[i].String()
+	}
+	return js.Global().Get("JSON").Call("stringify", args[i]).String()
+}
+
+// errorValue returns an object reporting msg in its error field
+func errorValue(msg string) js.Value {
+	return js.ValueOf(map[string]interface{}{"error": msg})
+}
@@ -0,0 +1,13 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "ruleengine-wasm: build with GOOS=js GOARCH=wasm and load the module with wasm_exec.js")
+	os.Exit(1)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// runAnalyze reports unused rules, unreachable rulesets, unused or undefined globals and duplicate or conflicting
+// rules
+func runAnalyze(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	strict := fs.Bool("strict", false, "exit with an error when any issue is found")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	analysis, err := engine.Analyze()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range analysis.UnusedRules {
+		fmt.Fprintf(stdout, "unused rule: %s is not referenced by any ruleset\n", rule)
+	}
+	for _, ruleset := range analysis.UnreachableRulesets {
+		fmt.Fprintf(stdout, "unreachable ruleset: %s can never pass, rules %s can never pass\n",
+			ruleset.Name, strings.Join(ruleset.Rules, ", "))
+	}
+	for _, global := range analysis.UnusedGlobals {
+		fmt.Fprintf(stdout, "unused global: %s is not referenced by any rule\n", global)
+	}
+	for _, global := range analysis.UndefinedGlobals {
+		fmt.Fprintf(stdout, "undefined global: %s is referenced by a rule but not defined\n", global)
+	}
+	for _, rules := range analysis.DuplicateRules {
+		fmt.Fprintf(stdout, "duplicate rules: %s have identical conditions\n", strings.Join(rules, ", "))
+	}
+	for _, conflict := range analysis.ConflictingRules {
+		fmt.Fprintf(stdout, "conflicting rules: %s negates %s, AND ruleset %s can never pass\n",
+			conflict.Negation, conflict.Rule, conflict.Ruleset)
+	}
+	if analysis.Empty() {
+		fmt.Fprintf(stdout, "%s: no issues found\n", ef.source())
+		return nil
+	}
+	if *strict {
+		return fmt.Errorf("%s: issues found", ef.source())
+	}
+	return nil
+}
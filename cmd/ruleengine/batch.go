@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// batchProgressInterval is how often the batch command reports its progress
+const batchProgressInterval = time.Second
+
+// batchOutput is the JSON line written for each record of a batch
+type batchOutput struct {
+	// Record is the 1-based position of the context in the input
+	Record int        `json:"record"`
+	Result evalOutput `json:"result"`
+	// Error is set if the record could not be evaluated, e.g. it exceeds the context limits
+	Error string `json:"error,omitempty"`
+}
+
+// batchRecord is a context of the input travelling through the workers
+type batchRecord struct {
+	ctx    map[string]interface{}
+	output batchOutput
+}
+
+// batchJob evaluates a ruleset against every context of a dataset
+type batchJob struct {
+	engine  *ruleengine.RuleEngine
+	ruleset string
+	workers int
+	// skip is the number of records already written by an earlier run, see resumeOutput
+	skip int
+	// progress receives the progress display, nil disables it
+	progress io.Writer
+}
+
+// batchSummary counts the records written by a batch
+type batchSummary struct {
+	records, passed, failed, errors int
+}
+
+// runBatch evaluates a ruleset against a CSV or JSONL dataset of contexts, writing one JSON line per record
+//
+//	Records are evaluated by parallel workers and written in input order. An interrupted batch writes the records
+//	evaluated so far, -resume continues it from the last complete line of -output
+func runBatch(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	inputPath := fs.String("input", "", "path to the dataset of contexts, one JSON object per line or a CSV with a header of context paths")
+	format := fs.String("format", "", "dataset format, jsonl or csv, detected from the -input extension when empty")
+	ruleset := fs.String("ruleset", "", "name of the ruleset to evaluate")
+	outputPath := fs.String("output", "-", "path to the JSONL results, - writes stdout")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of records evaluated in parallel")
+	resume := fs.Bool("resume", false, "continue an interrupted batch after the records already in -output")
+	progress := fs.Bool("progress", true, "report progress on stderr")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case *inputPath == "":
+		return errors.New("-input is required")
+	case *ruleset == "":
+		return errors.New("-ruleset is required")
+	case *workers < 1:
+		return errors.New("-workers must be at least 1")
+	case *resume && *outputPath == "-":
+		return errors.New("-resume requires an -output file")
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	if _, ok := engine.GetRuleset(*ruleset); !ok {
+		return fmt.Errorf("ruleset '%s' not found", *ruleset)
+	}
+	dataset, closer, err := openDataset(*inputPath, *format)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	job := batchJob{engine: engine, ruleset: *ruleset, workers: *workers}
+	if *progress {
+		job.progress = os.Stderr
+	}
+	out := stdout
+	if *outputPath != "-" {
+		file, skip, err := openBatchOutput(*outputPath, *resume)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out, job.skip = file, skip
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	_, err = job.run(ctx, dataset, out)
+	return err
+}
+
+// openBatchOutput opens the results file of a batch, returning the number of records it already holds when resuming
+//
+//	A resumed file is truncated after its last complete record, dropping a line cut short by an interruption
+func openBatchOutput(path string, resume bool) (*os.File, int, error) {
+	if !resume {
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create output: %w", err)
+		}
+		return file, 0, nil
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open output: %w", err)
+	}
+	records, offset, err := resumeOutput(file)
+	if err == nil {
+		err = file.Truncate(offset)
+	}
+	if err == nil {
+		_, err = file.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to resume output: %w", err)
+	}
+	return file, records, nil
+}
+
+// resumeOutput counts the complete, consecutive records at the start of r and the offset of the byte following them
+func resumeOutput(r io.Reader) (records int, offset int64, err error) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without its newline was cut short
+			return records, offset, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		var out batchOutput
+		if json.Unmarshal(line, &out) != nil || out.Record != records+1 {
+			return records, offset, nil
+		}
+		records++
+		offset += int64(len(line))
+	}
+}
+
+// run evaluates the records of dataset after the skipped ones, writing them to w in input order
+//
+//	Once ctx is done no more records are read, the records being evaluated are written and an error naming the
+//	records written is returned. Errors are also returned if the dataset or w fail
+func (j *batchJob) run(ctx context.Context, dataset ruleengine.DatasetReader, w io.Writer) (batchSummary, error) {
+	for i := 0; i < j.skip; i++ {
+		if _, err := dataset.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("output holds %d records, more than the input", j.skip)
+			}
+			return batchSummary{}, fmt.Errorf("failed to skip written records: %w", err)
+		}
+	}
+
+	records := make(chan batchRecord, j.workers)
+	var readErr error
+	go func() {
+		defer close(records)
+		for record := j.skip + 1; ctx.Err() == nil; record++ {
+			evalCtx, err := dataset.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				readErr = fmt.Errorf("failed to read dataset: %w", err)
+				return
+			}
+			select {
+			case records <- batchRecord{ctx: evalCtx, output: batchOutput{Record: record}}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	evaluated := make(chan batchOutput, j.workers)
+	var wg sync.WaitGroup
+	for range j.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range records {
+				evaluated <- j.evaluate(record)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(evaluated)
+	}()
+
+	// Records finish out of order, pending holds them until the records before them are written
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	pending := make(map[int]batchOutput)
+	next := j.skip + 1
+	var summary batchSummary
+	var writeErr error
+	start, reported := time.Now(), time.Now()
+	for output := range evaluated {
+		pending[output.Record] = output
+		for ; writeErr == nil; next++ {
+			output, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			writeErr = enc.Encode(output)
+			summary.record(output)
+		}
+		if j.progress != nil && time.Since(reported) >= batchProgressInterval {
+			reported = time.Now()
+			fmt.Fprintf(j.progress, "\r%d records, %.0f/s", summary.records, float64(summary.records)/time.Since(start).Seconds())
+		}
+	}
+	if writeErr == nil {
+		writeErr = buf.Flush()
+	}
+	if j.progress != nil {
+		fmt.Fprintf(j.progress, "\r%d records, %d passed, %d failed, %d errors in %s\n",
+			summary.records, summary.passed, summary.failed, summary.errors, time.Since(start).Round(time.Millisecond))
+	}
+
+	switch {
+	case writeErr != nil:
+		return summary, fmt.Errorf("failed to write results: %w", writeErr)
+	case readErr != nil:
+		return summary, readErr
+	case ctx.Err() != nil:
+		return summary, fmt.Errorf("interrupted after record %d, run again with -resume to continue: %w", next-1, ctx.Err())
+	}
+	return summary, nil
+}
+
+// evaluate evaluates the ruleset against the context of a record
+func (j *batchJob) evaluate(record batchRecord) batchOutput {
+	result, err := j.engine.EvaluateRuleset(j.ruleset, ruleengine.WithEvalContext(record.ctx))
+	if err != nil {
+		record.output.Error = err.Error()
+		return record.output
+	}
+	record.output.Result = rulesetOutput(result)
+	return record.output
+}
+
+// record counts a written record
+func (s *batchSummary) record(output batchOutput) {
+	s.records++
+	switch {
+	case output.Error != "":
+		s.errors++
+	case output.Result.Passed:
+		s.passed++
+	default:
+		s.failed++
+	}
+}
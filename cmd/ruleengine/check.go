@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// runCheck verifies the config compiles and references only defined globals, optionally under every environment
+func runCheck(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	all := fs.Bool("all-environments", false, "check the config without overrides and with each environment applied")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *all && ef.bundle != "" {
+		return errors.New("-all-environments cannot be used with -bundle, a bundle has its environment applied")
+	}
+	if *all && ef.environment != "" {
+		return errors.New("-all-environments cannot be used with -env")
+	}
+
+	environments := []string{ef.environment}
+	if *all {
+		// The environments are listed by the config without overrides, which is checked first
+		ef.environment = ""
+		engine, err := ef.build()
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: FAIL %v\n", environmentLabel(""), err)
+			return fmt.Errorf("%s: check failed", ef.source())
+		}
+		environments = append(environments, engine.Environments()...)
+	}
+
+	failed := 0
+	for _, environment := range environments {
+		env := ef
+		env.environment = environment
+		if err := env.check(); err != nil {
+			failed++
+			fmt.Fprintf(stdout, "%s: FAIL %v\n", environmentLabel(environment), err)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: ok\n", environmentLabel(environment))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s: %d of %d environment(s) failed the check", ef.source(), failed, len(environments))
+	}
+	return nil
+}
+
+// check builds the engine described by the flags and verifies every global its rules reference is defined
+func (f *engineFlags) check() error {
+	engine, err := f.build()
+	if err != nil {
+		return err
+	}
+	analysis, err := engine.Analyze()
+	if err != nil {
+		return err
+	}
+	if len(analysis.UndefinedGlobals) > 0 {
+		return fmt.Errorf("undefined globals referenced by rules: %s", strings.Join(analysis.UndefinedGlobals, ", "))
+	}
+	return nil
+}
+
+// environmentLabel names the environment in check output, the config without overrides is the base
+func environmentLabel(environment string) string {
+	if environment == "" {
+		return "(base)"
+	}
+	return environment
+}
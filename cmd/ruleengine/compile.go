@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// runCompile compiles a config into a bundle which loads without YAML parsing or CEL checking
+func runCompile(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("compile", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	output := fs.String("o", "rules.bundle", "path to write the bundle to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	data, err := engine.MarshalBundle()
+	if err != nil {
+		return fmt.Errorf("failed to compile bundle: %w", err)
+	}
+	key, err := ef.bundleKey()
+	if err != nil {
+		return err
+	}
+	metadata, err := ruleengine.ReadBundleMetadata(data, ruleengine.WithBundleKey(key))
+	if err != nil {
+		return fmt.Errorf("failed to verify bundle: %w", err)
+	}
+	algorithm := "sha256"
+	if key != nil {
+		algorithm = "hmac-sha256"
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	fmt.Fprintf(stdout, "%s: %d expressions, %s:%s\n", *output, metadata.Expressions, algorithm, metadata.Digest)
+	return nil
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// docsView is the config flattened into sorted slices for rendering
+type docsView struct {
+	Name            string
+	Description     string
+	ExecutionPolicy string
+	Rulesets        []docsRuleset
+	Rules           []docsRule
+	Globals         []docsValue
+	Policies        []docsPolicy
+	Environments    []docsEnvironment
+}
+
+// docsRuleset describes a ruleset
+type docsRuleset struct {
+	Key          string
+	Name         string
+	Description  string
+	Selector     string
+	Owner        string
+	Fallback     string
+	ErrorMessage string
+	Rules        []string
+}
+
+// docsRule describes a rule
+type docsRule struct {
+	Key          string
+	Name         string
+	Description  string
+	Owner        string
+	Extends      string
+	Expression   string
+	ErrorMessage string
+	ReasonCode   string
+}
+
+// docsValue is a named value formatted as JSON
+type docsValue struct {
+	Key   string
+	Value string
+}
+
+// docsPolicy describes an execution policy
+type docsPolicy struct {
+	Key              string
+	Description      string
+	StopOnFailure    bool
+	MaxExecutionTime string
+}
+
+// docsEnvironment describes the overrides of an environment
+type docsEnvironment struct {
+	Name            string
+	ExecutionPolicy string
+	Globals         []docsValue
+	ErrorMessages   []docsValue
+}
+
+// markdownDocs renders the docs as Markdown
+var markdownDocs = template.Must(template.New("markdown").Funcs(template.FuncMap{
+	"cell": markdownCell,
+}).Parse(`# {{.Name}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+## Rulesets
+{{range .Rulesets}}
+### {{.Key}}
+
+{{if .Name}}**{{.Name}}**{{if .Description}} — {{end}}{{end}}{{.Description}}
+
+| Selector | Owner | Fallback |
+|---|---|---|
+| {{.Selector}} | {{cell .Owner}} | {{cell .Fallback}} |
+
+Rules: {{range $i, $rule := .Rules}}{{if $i}}, {{end}}[{{$rule}}](#{{$rule}}){{end}}
+{{if .ErrorMessage}}
+Error message: {{.ErrorMessage}}
+{{end}}{{end}}
+## Rules
+{{range .Rules}}
+### {{.Key}}
+
+{{if .Name}}**{{.Name}}**{{if .Description}} — {{end}}{{end}}{{.Description}}
+{{if or .Owner .Extends}}
+{{if .Owner}}Owner: {{.Owner}}{{end}}{{if and .Owner .Extends}}, {{end}}{{if .Extends}}Extends: [{{.Extends}}](#{{.Extends}}){{end}}
+{{end}}
+` + "```cel" + `
+{{.Expression}}
+` + "```" + `
+{{if .ErrorMessage}}
+Error message: {{.ErrorMessage}}
+{{end}}{{if .ReasonCode}}
+Reason code: ` + "`{{.ReasonCode}}`" + `
+{{end}}{{end}}
+## Globals
+
+| Name | Value |
+|---|---|
+{{range .Globals}}| {{.Key}} | {{cell .Value}} |
+{{end}}
+## Execution Policies
+
+Default policy: {{.ExecutionPolicy}}
+
+| Policy | Description | Stop on failure | Max execution time |
+|---|---|---|---|
+{{range .Policies}}| {{.Key}} | {{cell .Description}} | {{.StopOnFailure}} | {{cell .MaxExecutionTime}} |
+{{end}}
+## Environments
+{{range .Environments}}
+### {{.Name}}
+{{if .ExecutionPolicy}}
+Execution policy: {{.ExecutionPolicy}}
+{{end}}{{if .Globals}}
+| Global | Value |
+|---|---|
+{{range .Globals}}| {{.Key}} | {{cell .Value}} |
+{{end}}{{end}}{{if .ErrorMessages}}
+| Error message | Value |
+|---|---|
+{{range .ErrorMessages}}| {{.Key}} | {{cell .Value}} |
+{{end}}{{end}}{{end}}`))
+
+// htmlDocs renders the docs as a standalone HTML page
+var htmlDocs = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{if .Description}}<p>{{.Description}}</p>
+{{end}}<h2>Rulesets</h2>
+{{range .Rulesets}}<h3 id="ruleset-{{.Key}}">{{.Key}}</h3>
+<p>{{if .Name}}<strong>{{.Name}}</strong> {{end}}{{.Description}}</p>
+<dl>
+<dt>Selector</dt><dd>{{.Selector}}</dd>
+{{if .Owner}}<dt>Owner</dt><dd>{{.Owner}}</dd>
+{{end}}{{if .Fallback}}<dt>Fallback</dt><dd>{{.Fallback}}</dd>
+{{end}}{{if .ErrorMessage}}<dt>Error message</dt><dd>{{.ErrorMessage}}</dd>
+{{end}}<dt>Rules</dt><dd>{{range $i, $rule := .Rules}}{{if $i}}, {{end}}<a href="#{{$rule}}">{{$rule}}</a>{{end}}</dd>
+</dl>
+{{end}}<h2>Rules</h2>
+{{range .Rules}}<h3 id="{{.Key}}">{{.Key}}</h3>
+<p>{{if .Name}}<strong>{{.Name}}</strong> {{end}}{{.Description}}</p>
+<dl>
+{{if .Owner}}<dt>Owner</dt><dd>{{.Owner}}</dd>
+{{end}}{{if .Extends}}<dt>Extends</dt><dd><a href="#{{.Extends}}">{{.Extends}}</a></dd>
+{{end}}{{if .ErrorMessage}}<dt>Error message</dt><dd>{{.ErrorMessage}}</dd>
+{{end}}{{if .ReasonCode}}<dt>Reason code</dt><dd><code>{{.ReasonCode}}</code></dd>
+{{end}}</dl>
+<pre><code>{{.Expression}}</code></pre>
+{{end}}<h2>Globals</h2>
+<table>
+<tr><th>Name</th><th>Value</th></tr>
+{{range .Globals}}<tr><td>{{.Key}}</td><td><code>{{.Value}}</code></td></tr>
+{{end}}</table>
+<h2>Execution Policies</h2>
+<p>Default policy: {{.ExecutionPolicy}}</p>
+<table>
+<tr><th>Policy</th><th>Description</th><th>Stop on failure</th><th>Max execution time</th></tr>
+{{range .Policies}}<tr><td>{{.Key}}</td><td>{{.Description}}</td><td>{{.StopOnFailure}}</td><td>{{.MaxExecutionTime}}</td></tr>
+{{end}}</table>
+<h2>Environments</h2>
+{{range .Environments}}<h3>{{.Name}}</h3>
+{{if .ExecutionPolicy}}<p>Execution policy: {{.ExecutionPolicy}}</p>
+{{end}}{{if .Globals}}<table>
+<tr><th>Global</th><th>Value</th></tr>
+{{range .Globals}}<tr><td>{{.Key}}</td><td><code>{{.Value}}</code></td></tr>
+{{end}}</table>
+{{end}}{{if .ErrorMessages}}<table>
+<tr><th>Error message</th><th>Value</th></tr>
+{{range .ErrorMessages}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// runDocs renders the rules, rulesets, policies and environments of a config as Markdown or HTML
+func runDocs(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("docs", flag.ContinueOnError)
+	config := fs.String("config", "rules.yml", "path to the rules config file")
+	format := fs.String("format", "markdown", "output format, markdown or html")
+	showConfidential := fs.Bool("show-confidential", false, "include the expressions of confidential rules and the globals they read")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rc, err := ruleengine.NewRulesetConfig(*config)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !*showConfidential {
+		if rc, err = rc.Redact(); err != nil {
+			return fmt.Errorf("failed to redact config: %w", err)
+		}
+	}
+	view, err := newDocsView(rc)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "markdown", "md":
+		err = markdownDocs.Execute(stdout, view)
+	case "html":
+		err = htmlDocs.Execute(stdout, view)
+	default:
+		return fmt.Errorf("unknown format '%s', use markdown or html", *format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render docs: %w", err)
+	}
+	return nil
+}
+
+// newDocsView flattens the config into a docsView
+func newDocsView(rc *ruleengine.RulesetConfig) (docsView, error) {
+	messages := rc.ErrorHandling.CustomErrorMessages
+	view := docsView{
+		Name:            rc.Metadata.Name,
+		Description:     rc.Metadata.Description,
+		ExecutionPolicy: rc.ErrorHandling.ExecutionPolicy,
+	}
+
+	for _, key := range sortedKeys(rc.Rulesets) {
+		ruleset := rc.Rulesets[key]
+		selector := string(ruleset.Selector)
+		if selector == "" {
+			selector = "AND"
+		}
+		view.Rulesets = append(view.Rulesets, docsRuleset{
+			Key:          key,
+			Name:         ruleset.Name,
+			Description:  ruleset.Description,
+			Selector:     selector,
+			Owner:        ruleset.Owner,
+			Fallback:     ruleset.Fallback,
+			ErrorMessage: errorMessage(ruleset.ErrorMessage, messages[key]),
+			Rules:        ruleset.Rules,
+		})
+	}
+	for _, key := range sortedKeys(rc.Rules) {
+		rule := rc.Rules[key]
+		view.Rules = append(view.Rules, docsRule{
+			Key:          key,
+			Name:         rule.Name,
+			Description:  rule.Description,
+			Owner:        rule.Owner,
+			Extends:      rule.Extends,
+			Expression:   strings.TrimSpace(rule.Expression),
+			ErrorMessage: errorMessage(rule.ErrorMessage, messages[key]),
+			ReasonCode:   rule.ReasonCode,
+		})
+	}
+	globals, err := docsValues(rc.Globals)
+	if err != nil {
+		return docsView{}, err
+	}
+	view.Globals = globals
+	for _, key := range sortedKeys(rc.ExecutionPolicies) {
+		policy := rc.ExecutionPolicies[key]
+		view.Policies = append(view.Policies, docsPolicy{
+			Key:              key,
+			Description:      policy.Description,
+			StopOnFailure:    policy.StopOnFailure,
+			MaxExecutionTime: policy.MaxExecutionTime,
+		})
+	}
+	for _, name := range sortedKeys(rc.Environments) {
+		env := rc.Environments[name]
+		globals, err := docsValues(env.Globals)
+		if err != nil {
+			return docsView{}, err
+		}
+		errorMessages := make([]docsValue, 0, len(env.ErrorHandling.CustomErrorMessages))
+		for _, key := range sortedKeys(env.ErrorHandling.CustomErrorMessages) {
+			errorMessages = append(errorMessages, docsValue{Key: key, Value: env.ErrorHandling.CustomErrorMessages[key]})
+		}
+		view.Environments = append(view.Environments, docsEnvironment{
+			Name:            name,
+			ExecutionPolicy: env.ErrorHandling.ExecutionPolicy,
+			Globals:         globals,
+			ErrorMessages:   errorMessages,
+		})
+	}
+	return view, nil
+}
+
+// docsValues formats the values of m as JSON, sorted by key
+func docsValues(m map[string]interface{}) ([]docsValue, error) {
+	values := make([]docsValue, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		// The HTML template escapes values itself, escaping them here would show `\u003c` in Markdown
+		var buf strings.Builder
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(m[key]); err != nil {
+			return nil, fmt.Errorf("failed to format '%s': %w", key, err)
+		}
+		values = append(values, docsValue{Key: key, Value: strings.TrimSuffix(buf.String(), "\n")})
+	}
+	return values, nil
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// markdownCell escapes a value for use in a Markdown table cell, empty values are shown as a dash
+func markdownCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// errorMessage returns the inline error message of a rule or ruleset, falling back to the custom error messages
+func errorMessage(inline, custom string) string {
+	if inline != "" {
+		return inline
+	}
+	return custom
+}
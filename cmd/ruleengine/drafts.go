@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// draftChangeOutput is the JSON representation of a pending draft change
+type draftChangeOutput struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// auditOutput is the JSON representation of an audit trail entry
+type auditOutput struct {
+	Time    time.Time           `json:"time"`
+	Author  string              `json:"author"`
+	Action  string              `json:"action"`
+	Name    string              `json:"name,omitempty"`
+	Message string              `json:"message,omitempty"`
+	Changes []draftChangeOutput `json:"changes,omitempty"`
+}
+
+// registerDrafts adds the draft authoring endpoints to the mux
+//
+//	Rules and rulesets are drafted with PUT and DELETE on /v1/drafts/rules/{name} and /v1/drafts/rulesets/{name},
+//	the body of a PUT is the rule or ruleset as in the config, in YAML or JSON. Drafts are validated with
+//	POST /v1/drafts/validate, test-run with POST on /v1/drafts/rules/{name}/test and /v1/drafts/rulesets/{name}/test
+//	and committed with POST /v1/drafts/commit. The author of a change is taken from the X-Author header
+func (s *server) registerDrafts(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v1/drafts", s.admin(s.handleDraftsPending))
+	mux.HandleFunc("DELETE /v1/drafts", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		s.currentDrafts().Discard(author(r))
+		s.handleDraftsPending(w, r)
+	}))
+	mux.HandleFunc("PUT /v1/drafts/rules/{name}", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		var rule ruleengine.Rule
+		if err := decodeDraft(w, r, &rule); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.respondDraft(w, r, s.currentDrafts().PutRule(author(r), r.PathValue("name"), rule))
+	}))
+	mux.HandleFunc("DELETE /v1/drafts/rules/{name}", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		s.respondDraft(w, r, s.currentDrafts().DeleteRule(author(r), r.PathValue("name")))
+	}))
+	mux.HandleFunc("PUT /v1/drafts/rulesets/{name}", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		var ruleset ruleengine.Ruleset
+		if err := decodeDraft(w, r, &ruleset); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.respondDraft(w, r, s.currentDrafts().PutRuleset(author(r), r.PathValue("name"), ruleset))
+	}))
+	mux.HandleFunc("DELETE /v1/drafts/rulesets/{name}", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		s.respondDraft(w, r, s.currentDrafts().DeleteRuleset(author(r), r.PathValue("name")))
+	}))
+	mux.HandleFunc("POST /v1/drafts/validate", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.currentDrafts().Validate(); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"valid": true})
+	}))
+	mux.HandleFunc("POST /v1/drafts/rules/{name}/test", s.admin(s.handleDraftTest(true)))
+	mux.HandleFunc("POST /v1/drafts/rulesets/{name}/test", s.admin(s.handleDraftTest(false)))
+	mux.HandleFunc("POST /v1/drafts/commit", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse commit: %w", err))
+			return
+		}
+		entry, err := s.currentDrafts().Commit(author(r), body.Message)
+		switch {
+		case errors.Is(err, ruleengine.ErrNoDrafts):
+			writeError(w, http.StatusConflict, err)
+		case errors.Is(err, ruleengine.ErrChangeRejected):
+			writeError(w, http.StatusForbidden, err)
+		case err != nil:
+			writeError(w, http.StatusUnprocessableEntity, err)
+		default:
+			writeJSON(w, http.StatusOK, auditEntryOutput(entry))
+		}
+	}))
+	mux.HandleFunc("GET /v1/drafts/audit", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		entries := append(slices.Clone(s.audit), s.drafts.Audit()...)
+		s.mu.RUnlock()
+		out := make([]auditOutput, 0, len(entries))
+		for _, entry := range entries {
+			out = append(out, auditEntryOutput(entry))
+		}
+		writeJSON(w, http.StatusOK, out)
+	}))
+}
+
+// currentDrafts returns the drafts of the engine currently served
+func (s *server) currentDrafts() *ruleengine.Drafts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.drafts
+}
+
+// handleDraftsPending lists the pending draft changes
+func (s *server) handleDraftsPending(w http.ResponseWriter, r *http.Request) {
+	changes := s.currentDrafts().Pending()
+	out := make([]draftChangeOutput, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, draftChangeOutput{Kind: c.Kind, Name: c.Name, Deleted: c.Deleted})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// respondDraft reports a failed draft change or lists the pending changes
+func (s *server) respondDraft(w http.ResponseWriter, r *http.Request, err error) {
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.handleDraftsPending(w, r)
+}
+
+// handleDraftTest evaluates the drafted rule or ruleset named in the path against the JSON context in the body
+func (s *server) handleDraftTest(rule bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := make(map[string]interface{})
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&ctx); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse context: %w", err))
+			return
+		}
+		drafts := s.currentDrafts()
+		if err := drafts.Validate(); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		name := r.PathValue("name")
+		if rule {
+			result, err := drafts.TestRule(name, ruleengine.WithEvalContext(ctx))
+			if err != nil {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, ruleOutput(result))
+			return
+		}
+		result, err := drafts.TestRuleset(name, ruleengine.WithEvalContext(ctx))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rulesetOutput(result))
+	}
+}
+
+// decodeDraft decodes the YAML or JSON rule or ruleset in the request body into v
+func decodeDraft(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read draft: %w", err)
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse draft: %w", err)
+	}
+	return nil
+}
+
+// author returns the author of a change from the X-Author header
+func author(r *http.Request) string {
+	if a := r.Header.Get("X-Author"); a != "" {
+		return a
+	}
+	return "anonymous"
+}
+
+// auditEntryOutput converts an audit trail entry into its JSON representation
+func auditEntryOutput(entry ruleengine.AuditEntry) auditOutput {
+	out := auditOutput{
+		Time:    entry.Time,
+		Author:  entry.Author,
+		Action:  entry.Action,
+		Name:    entry.Name,
+		Message: entry.Message,
+	}
+	for _, c := range entry.Changes {
+		out.Changes = append(out.Changes, draftChangeOutput{Kind: c.Kind, Name: c.Name, Deleted: c.Deleted})
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// runEncrypt encrypts a config or bundle so it can be shipped without its thresholds and lists in plaintext
+func runEncrypt(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	input := fs.String("in", "rules.yml", "path to the config or bundle to encrypt")
+	keyFile := fs.String("key-file", "", "path to a file holding the hex encoded 16, 24 or 32 byte key")
+	output := fs.String("o", "", "path to write the encrypted file to, defaults to the input path with .enc appended")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyFile == "" {
+		return fmt.Errorf("-key-file is required")
+	}
+	if *output == "" {
+		*output = *input + ".enc"
+	}
+
+	key, err := readKey(*keyFile)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	encrypted, err := ruleengine.EncryptConfig(data, key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, encrypted, 0o600); err != nil {
+		return fmt.Errorf("failed to write encrypted file: %w", err)
+	}
+	fmt.Fprintf(stdout, "%s: encrypted %s\n", *output, *input)
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// engineFlags are the flags shared by every command loading an engine
+type engineFlags struct {
+	config      string
+	overlays    string
+	bundle      string
+	environment string
+	profile     string
+	variables   string
+	keyFile     string
+	signKeyFile string
+	defaults    string
+}
+
+// register adds the engine flags to fs
+func (f *engineFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.config, "config", "rules.yml", "path to the rules config file")
+	fs.StringVar(&f.overlays, "overlays", "", "comma separated overlay files patching the config, applied in order")
+	fs.StringVar(&f.bundle, "bundle", "", "path to a bundle created by the compile command, used instead of -config")
+	fs.StringVar(&f.environment, "env", "", "environment overrides to apply")
+	fs.StringVar(&f.profile, "profile", "", "profile overrides to apply on top of the environment")
+	fs.StringVar(&f.variables, "vars", "user,request", "comma separated context variables declared as dynamic types")
+	fs.StringVar(&f.keyFile, "key-file", "", "path to a file holding the hex encoded key of an encrypted config or bundle")
+	fs.StringVar(&f.signKeyFile, "bundle-key-file", "", "path to a file holding the hex encoded key bundles are signed and verified with")
+	fs.StringVar(&f.defaults, "default-config", "", "path to a known-good config used when -config or -bundle fails to load")
+}
+
+// build creates the engine described by the flags
+func (f *engineFlags) build(opts ...ruleengine.Option) (*ruleengine.RuleEngine, error) {
+	builder := ruleengine.NewBuilder()
+	if f.bundle != "" {
+		data, err := os.ReadFile(f.bundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		builder = builder.WithBundle(data)
+	} else {
+		builder = builder.WithConfigFile(f.config).
+			WithOverlayFiles(splitList(f.overlays)...).
+			WithEnvironment(f.environment)
+	}
+	if f.keyFile != "" {
+		key, err := readKey(f.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		builder = builder.WithDecryptionKey(key)
+	}
+	if f.defaults != "" {
+		data, err := os.ReadFile(f.defaults)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read default config: %w", err)
+		}
+		builder = builder.WithDefaultConfig(data)
+	}
+	key, err := f.bundleKey()
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		opts = append(opts[:len(opts):len(opts)], ruleengine.WithBundleKey(key))
+	}
+	return builder.
+		WithProfile(f.profile).
+		WithVariables(splitList(f.variables)...).
+		WithOptions(opts...).
+		Build()
+}
+
+// bundleKey reads the key bundles are signed with, nil if -bundle-key-file is not set
+func (f *engineFlags) bundleKey() ([]byte, error) {
+	if f.signKeyFile == "" {
+		return nil, nil
+	}
+	return readKey(f.signKeyFile)
+}
+
+// source returns the path the engine is loaded from
+func (f *engineFlags) source() string {
+	if f.bundle != "" {
+		return f.bundle
+	}
+	return f.config
+}
+
+// readKey reads a hex encoded encryption key from path
+func readKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key, want hex: %w", err)
+	}
+	return key, nil
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readContext reads a JSON object from path, "-" reads from stdin
+func readContext(path string) (map[string]interface{}, error) {
+	if path == "" {
+		return map[string]interface{}{}, nil
+	}
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context: %w", err)
+	}
+	ctx := make(map[string]interface{})
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		return nil, fmt.Errorf("failed to parse context: %w", err)
+	}
+	return ctx, nil
+}
+
+// openDataset opens the dataset of contexts at path, format is jsonl or csv and detected from the extension when empty
+func openDataset(path, format string) (ruleengine.DatasetReader, io.Closer, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	if format != "jsonl" && format != "ndjson" && format != "csv" {
+		return nil, nil, fmt.Errorf("unknown dataset format '%s', want jsonl or csv", format)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	if format == "csv" {
+		dataset, err := ruleengine.NewCSVReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return dataset, file, nil
+	}
+	return ruleengine.NewJSONLReader(file), file, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runEnvDescriptor prints the variables and functions the host CEL env of a config declares, verified by hosts with
+// ruleengine.WithEnvDescriptor
+func runEnvDescriptor(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("env-descriptor", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	format := fs.String("format", "json", "output format, json or yaml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	descriptor := engine.EnvDescriptor()
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(descriptor)
+	case "yaml":
+		err = yaml.NewEncoder(stdout).Encode(descriptor)
+	default:
+		return fmt.Errorf("unknown format '%s', want json or yaml", *format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write descriptor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// evalOutput is the JSON representation of an evaluation result
+type evalOutput struct {
+	Name        string                `json:"name"`
+	Passed      bool                  `json:"passed"`
+	Error       string                `json:"error,omitempty"`
+	Degraded    bool                  `json:"degraded,omitempty"`
+	Fallback    string                `json:"fallback,omitempty"`
+	Skipped     bool                  `json:"skipped,omitempty"`
+	ReasonCode  string                `json:"reason_code,omitempty"`
+	ReasonCodes []string              `json:"reason_codes,omitempty"`
+	VetoedBy    string                `json:"vetoed_by,omitempty"`
+	Warnings    []string              `json:"warnings,omitempty"`
+	Rules       map[string]evalOutput `json:"rules,omitempty"`
+}
+
+// runEval evaluates a rule or ruleset against a JSON context and prints the result as JSON
+func runEval(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	rule := fs.String("rule", "", "name of the rule to evaluate")
+	ruleset := fs.String("ruleset", "", "name of the ruleset to evaluate")
+	contextPath := fs.String("context", "", "path to a JSON context file, - reads stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*rule == "") == (*ruleset == "") {
+		return errors.New("exactly one of -rule or -ruleset is required")
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	ctx, err := readContext(*contextPath)
+	if err != nil {
+		return err
+	}
+
+	var out evalOutput
+	if *rule != "" {
+		result, err := engine.EvaluateRule(*rule, ruleengine.WithEvalContext(ctx))
+		if err != nil {
+			return err
+		}
+		out = ruleOutput(result)
+	} else {
+		result, err := engine.EvaluateRuleset(*ruleset, ruleengine.WithEvalContext(ctx))
+		if err != nil {
+			return err
+		}
+		out = rulesetOutput(result)
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
+
+// ruleOutput converts a RuleResult into its JSON representation
+func ruleOutput(result ruleengine.RuleResult) evalOutput {
+	out := evalOutput{
+		Name:       result.RuleName,
+		Passed:     result.Passed,
+		Skipped:    result.Skipped,
+		ReasonCode: result.ReasonCode,
+	}
+	if result.Error != nil {
+		out.Error = result.Error.Error()
+	}
+	return out
+}
+
+// rulesetOutput converts a RulesetResult into its JSON representation
+func rulesetOutput(result ruleengine.RulesetResult) evalOutput {
+	out := evalOutput{
+		Name:        result.RulesetName,
+		Passed:      result.Passed,
+		Degraded:    result.Degraded,
+		Fallback:    result.Fallback,
+		Skipped:     result.Skipped,
+		ReasonCodes: result.ReasonCodes,
+		VetoedBy:    result.VetoedBy,
+		Warnings:    result.Warnings,
+		Rules:       make(map[string]evalOutput, len(result.RuleResults)),
+	}
+	if result.Error != nil {
+		out.Error = result.Error.Error()
+	}
+	for name, ruleResult := range result.RuleResults {
+		out.Rules[name] = ruleOutput(ruleResult)
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// runExplain prints the AST, referenced variables, inheritance chain and cost estimate of a rule
+func runExplain(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	rule := fs.String("rule", "", "name of the rule to explain")
+	showConfidential := fs.Bool("show-confidential", false, "explain confidential rules in full")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rule == "" {
+		return errors.New("-rule is required")
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	explanation, err := engine.Explain(*rule)
+	if err != nil {
+		return err
+	}
+	if engine.Confidential(*rule) && !*showConfidential {
+		explanation = explanation.Redact()
+	}
+
+	fmt.Fprintf(stdout, "Rule: %s\n", explanation.RuleName)
+	fmt.Fprintf(stdout, "Expression: %s\n", strings.TrimSpace(explanation.Expression))
+	if len(explanation.Parents) > 0 {
+		fmt.Fprintf(stdout, "Extends: %s\n", strings.Join(explanation.Parents, " -> "))
+	}
+	fmt.Fprintf(stdout, "Variables: %s\n", strings.Join(explanation.Variables, ", "))
+	fmt.Fprintf(stdout, "Cost: %s\n", formatCost(explanation.Cost.Min, explanation.Cost.Max))
+	if explanation.AST == "" {
+		// Redacted explanations have no AST
+		return nil
+	}
+	fmt.Fprintln(stdout, "AST:")
+	for _, line := range strings.Split(strings.TrimRight(explanation.AST, "\n"), "\n") {
+		fmt.Fprintf(stdout, "  %s\n", line)
+	}
+	return nil
+}
+
+// formatCost formats a cost range, costs too large to be meaningful are shown as unbounded
+func formatCost(min, max uint64) string {
+	if max >= math.MaxUint64/10 {
+		return fmt.Sprintf("%d - unbounded", min)
+	}
+	if min == max {
+		return fmt.Sprintf("%d", min)
+	}
+	return fmt.Sprintf("%d - %d", min, max)
+}
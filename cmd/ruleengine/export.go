@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// runExport writes the canonical expression and checked AST of every rule to <rule>.cel and <rule>.textproto files
+func runExport(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	output := fs.String("o", "cel", "directory to write the expression files to")
+	showConfidential := fs.Bool("show-confidential", false, "export confidential rules too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	rules, err := engine.ExportRules()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*output, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	exported := 0
+	for _, rule := range rules {
+		if engine.Confidential(rule.RuleName) && !*showConfidential {
+			continue
+		}
+		exported++
+		files := map[string]string{
+			rule.RuleName + ".cel":       rule.Expression + "\n",
+			rule.RuleName + ".textproto": rule.CheckedExpr,
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(*output, name), []byte(content), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+	}
+	fmt.Fprintf(stdout, "%s: %d rules\n", *output, exported)
+	return nil
+}
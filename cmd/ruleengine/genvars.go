@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// variableDirective annotates a struct as the type of a context variable, e.g. `//ruleengine:variable user`
+const variableDirective = "ruleengine:variable"
+
+// genVarsFile is the default name of the generated file, written to the scanned directory
+const genVarsFile = "ruleengine_vars.go"
+
+// genVarsStruct is a struct type declared in the scanned package
+type genVarsStruct struct {
+	name   string
+	fields []*ast.Field
+}
+
+// runGenVars scans the Go files of a package for structs annotated with `//ruleengine:variable <name>` and writes
+// the cel.Variable declarations of those variables and the conversion of the structs into an evaluation context
+//
+//	Fields are named like ContextBuilder names them: `cel` tag, then `json` tag, then field name, `-` skips the
+//	field and nil pointers, slices and maps are omitted. Structs of the package used by fields are converted too,
+//	other values are passed as is for CEL to convert. Run it with `//go:generate ruleengine gen-vars .`
+func runGenVars(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gen-vars", flag.ContinueOnError)
+	output := fs.String("o", "", "path of the generated file, defaults to "+genVarsFile+" in the package directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("exactly one package directory is required")
+	}
+	dir := fs.Arg(0)
+	if *output == "" {
+		*output = filepath.Join(dir, genVarsFile)
+	}
+
+	pkg, structs, variables, err := scanGenVars(dir, filepath.Base(*output))
+	if err != nil {
+		return err
+	}
+	if len(variables) == 0 {
+		return fmt.Errorf("no structs annotated with //%s <name> found in %s", variableDirective, dir)
+	}
+	src, err := generateVars(pkg, structs, variables)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Fprintf(stdout, "%s: %d variables\n", *output, len(variables))
+	return nil
+}
+
+// scanGenVars parses the non-test Go files of dir, except the generated file, returning the package name, its
+// struct types and the annotated variables keyed by variable name
+func scanGenVars(dir, generated string) (string, map[string]*genVarsStruct, map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to read package: %w", err)
+	}
+	fset := token.NewFileSet()
+	pkg := ""
+	structs := make(map[string]*genVarsStruct)
+	variables := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == generated {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		pkg = file.Name.Name
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				structs[ts.Name.Name] = &genVarsStruct{name: ts.Name.Name, fields: st.Fields.List}
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				variable := directiveVariable(doc)
+				if variable == "" {
+					continue
+				}
+				if other, ok := variables[variable]; ok {
+					return "", nil, nil, fmt.Errorf("variable '%s' is declared by both %s and %s", variable, other, ts.Name.Name)
+				}
+				variables[variable] = ts.Name.Name
+			}
+		}
+	}
+	if pkg == "" {
+		return "", nil, nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+	return pkg, structs, variables, nil
+}
+
+// directiveVariable returns the variable name of a `//ruleengine:variable <name>` comment, empty if there is none
+func directiveVariable(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if name, ok := strings.CutPrefix(text, variableDirective+" "); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
+
+// generateVars renders the generated file for the annotated variables and the structs they use
+func generateVars(pkg string, structs map[string]*genVarsStruct, variables map[string]string) ([]byte, error) {
+	names := sortedKeys(variables)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by ruleengine gen-vars. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import \"github.com/google/cel-go/cel\"\n\n")
+
+	fmt.Fprintf(&buf, "// RuleVariables declares the context variables of the annotated structs\n")
+	fmt.Fprintf(&buf, "func RuleVariables() []cel.EnvOption {\n\treturn []cel.EnvOption{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t\tcel.Variable(%q, cel.MapType(cel.StringType, cel.DynType)),\n", name)
+	}
+	fmt.Fprintf(&buf, "\t}\n}\n\n")
+
+	params := make([]string, 0, len(names))
+	for _, name := range names {
+		params = append(params, fmt.Sprintf("%s %s", goIdent(name), variables[name]))
+	}
+	fmt.Fprintf(&buf, "// RuleContext converts the annotated structs into an evaluation context\n")
+	fmt.Fprintf(&buf, "func RuleContext(%s) map[string]interface{} {\n\treturn map[string]interface{}{\n", strings.Join(params, ", "))
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t\t%q: %s.ruleContext(),\n", name, goIdent(name))
+	}
+	fmt.Fprintf(&buf, "\t}\n}\n")
+
+	// Convert every struct reachable from the variables, in name order so the output is stable
+	pending := make([]string, 0, len(names))
+	for _, name := range names {
+		pending = append(pending, variables[name])
+	}
+	seen := make(map[string]bool)
+	var converters []string
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		converters = append(converters, name)
+		for _, field := range structs[name].fields {
+			if local := localStruct(field.Type, structs); local != "" {
+				pending = append(pending, local)
+			}
+		}
+	}
+	sort.Strings(converters)
+	for _, name := range converters {
+		if err := generateConverter(&buf, structs[name], structs); err != nil {
+			return nil, err
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// generateConverter renders the ruleContext method converting a struct into a map
+func generateConverter(buf *bytes.Buffer, s *genVarsStruct, structs map[string]*genVarsStruct) error {
+	fmt.Fprintf(buf, "\n// ruleContext converts %s into its evaluation context value\n", s.name)
+	fmt.Fprintf(buf, "func (v %s) ruleContext() map[string]interface{} {\n\tm := make(map[string]interface{})\n", s.name)
+	for _, field := range s.fields {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			unquoted, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return fmt.Errorf("%s: invalid struct tag %s", s.name, field.Tag.Value)
+			}
+			tag = reflect.StructTag(unquoted)
+		}
+		if len(field.Names) == 0 {
+			// Untagged embedded structs of the package are flattened like ContextBuilder does
+			embedded := embeddedName(field.Type)
+			if _, ok := structs[embedded]; ok && !hasNameTag(tag) {
+				ptr := isPointer(field.Type)
+				if ptr {
+					fmt.Fprintf(buf, "\tif v.%s != nil {\n", embedded)
+				}
+				fmt.Fprintf(buf, "\tfor k, value := range v.%s.ruleContext() {\n\t\tm[k] = value\n\t}\n", embedded)
+				if ptr {
+					fmt.Fprintf(buf, "\t}\n")
+				}
+				continue
+			}
+			field = &ast.Field{Names: []*ast.Ident{ast.NewIdent(embedded)}, Type: field.Type, Tag: field.Tag}
+		}
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			key := contextName(ident.Name, tag)
+			if key == "-" {
+				continue
+			}
+			if strings.Contains(key, ".") {
+				return fmt.Errorf("%s.%s: dotted cel tag paths are not supported, use ContextBuilder", s.name, ident.Name)
+			}
+			generateField(buf, "v."+ident.Name, key, field.Type, structs)
+		}
+	}
+	fmt.Fprintf(buf, "\treturn m\n}\n")
+	return nil
+}
+
+// generateField renders the assignment of a field to its context key, omitting nil values
+func generateField(buf *bytes.Buffer, value, key string, typ ast.Expr, structs map[string]*genVarsStruct) {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		fmt.Fprintf(buf, "\tif %s != nil {\n", value)
+		if _, ok := structs[identName(t.X)]; ok {
+			fmt.Fprintf(buf, "\t\tm[%q] = %s.ruleContext()\n", key, value)
+		} else {
+			fmt.Fprintf(buf, "\t\tm[%q] = *%s\n", key, value)
+		}
+		fmt.Fprintf(buf, "\t}\n")
+	case *ast.ArrayType:
+		elem := t.Elt
+		ptr := false
+		if star, ok := elem.(*ast.StarExpr); ok {
+			elem, ptr = star.X, true
+		}
+		if _, ok := structs[identName(elem)]; !ok {
+			if t.Len == nil {
+				fmt.Fprintf(buf, "\tif %s != nil {\n\t\tm[%q] = %s\n\t}\n", value, key, value)
+			} else {
+				fmt.Fprintf(buf, "\tm[%q] = %s\n", key, value)
+			}
+			return
+		}
+		fmt.Fprintf(buf, "\tif len(%s) > 0 {\n", value)
+		fmt.Fprintf(buf, "\t\titems := make([]interface{}, 0, len(%s))\n\t\tfor _, item := range %s {\n", value, value)
+		if ptr {
+			fmt.Fprintf(buf, "\t\t\tif item != nil {\n\t\t\t\titems = append(items, item.ruleContext())\n\t\t\t}\n")
+		} else {
+			fmt.Fprintf(buf, "\t\t\titems = append(items, item.ruleContext())\n")
+		}
+		fmt.Fprintf(buf, "\t\t}\n\t\tm[%q] = items\n\t}\n", key)
+	case *ast.MapType, *ast.InterfaceType:
+		fmt.Fprintf(buf, "\tif %s != nil {\n\t\tm[%q] = %s\n\t}\n", value, key, value)
+	default:
+		if _, ok := structs[identName(typ)]; ok {
+			fmt.Fprintf(buf, "\tm[%q] = %s.ruleContext()\n", key, value)
+			return
+		}
+		fmt.Fprintf(buf, "\tm[%q] = %s\n", key, value)
+	}
+}
+
+// localStruct returns the struct of the package a field type refers to, directly, by pointer or as slice element
+func localStruct(typ ast.Expr, structs map[string]*genVarsStruct) string {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		return localStruct(t.X, structs)
+	case *ast.ArrayType:
+		return localStruct(t.Elt, structs)
+	}
+	if _, ok := structs[identName(typ)]; ok {
+		return identName(typ)
+	}
+	return ""
+}
+
+// contextName returns the context key of a field: `cel` tag, then `json` tag, then the field name
+func contextName(field string, tag reflect.StructTag) string {
+	for _, key := range []string{"cel", "json"} {
+		if value, ok := tag.Lookup(key); ok {
+			if name, _, _ := strings.Cut(value, ","); name != "" {
+				return name
+			}
+		}
+	}
+	return field
+}
+
+// hasNameTag reports whether the tag names the field, so an embedded struct is nested rather than flattened
+func hasNameTag(tag reflect.StructTag) bool {
+	return contextName("", tag) != ""
+}
+
+// embeddedName returns the type name of an embedded field, e.g. Base for `*Base`
+func embeddedName(typ ast.Expr) string {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if sel, ok := typ.(*ast.SelectorExpr); ok {
+		return sel.Sel.Name
+	}
+	return identName(typ)
+}
+
+// identName returns the name of an identifier type, empty for any other type
+func identName(typ ast.Expr) string {
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// isPointer reports whether typ is a pointer type
+func isPointer(typ ast.Expr) bool {
+	_, ok := typ.(*ast.StarExpr)
+	return ok
+}
+
+// goIdent converts a variable name into a Go parameter name
+func goIdent(name string) string {
+	ident := strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	if token.IsKeyword(ident) || (ident != "" && '0' <= ident[0] && ident[0] <= '9') {
+		ident = "_" + ident
+	}
+	return ident
+}
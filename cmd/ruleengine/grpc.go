@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mobanhawi/ruleengine"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// decisionServiceName is the full name of the gRPC decision service
+const decisionServiceName = "ruleengine.v1.DecisionService"
+
+// decisionService evaluates rules and rulesets over gRPC
+//
+//	Requests and responses are google.protobuf.Struct messages, so clients need no generated code: a request holds
+//	the rule or ruleset name, the evaluation context and an optional subject recorded in the decision log, e.g.
+//	{"name": "kyc", "context": {"user": {"age": 21}}}, and the response is the same JSON object as
+//	POST /v1/rulesets/{name}
+type decisionService interface {
+	EvaluateRuleset(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	EvaluateRule(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// decisionServiceDesc describes decisionService to grpc.Server.RegisterService
+var decisionServiceDesc = grpc.ServiceDesc{
+	ServiceName: decisionServiceName,
+	HandlerType: (*decisionService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "EvaluateRuleset",
+			Handler:    decisionHandler("EvaluateRuleset", decisionService.EvaluateRuleset),
+		},
+		{
+			MethodName: "EvaluateRule",
+			Handler:    decisionHandler("EvaluateRule", decisionService.EvaluateRule),
+		},
+	},
+	Metadata: "ruleengine/v1/decision.proto",
+}
+
+// decisionMethod is a method expression of decisionService
+type decisionMethod func(decisionService, context.Context, *structpb.Struct) (*structpb.Struct, error)
+
+// decisionHandler adapts a decisionService method to a grpc.MethodDesc handler running the server interceptors
+func decisionHandler(method string, call decisionMethod) grpc.MethodHandler {
+	return func(
+		srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+	) (interface{}, error) {
+		req := &structpb.Struct{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return call(srv.(decisionService), ctx, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + decisionServiceName + "/" + method}
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return call(srv.(decisionService), ctx, req.(*structpb.Struct))
+		})
+	}
+}
+
+// grpcDecisions implements decisionService for the engine served
+type grpcDecisions struct {
+	s *server
+}
+
+// EvaluateRuleset evaluates the ruleset named in the request
+func (g grpcDecisions) EvaluateRuleset(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	return g.evaluate(false, req)
+}
+
+// EvaluateRule evaluates the rule named in the request
+func (g grpcDecisions) EvaluateRule(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	return g.evaluate(true, req)
+}
+
+// evaluate evaluates the rule or ruleset named in the request and converts the result to a Struct
+//
+//	Results which did not pass are returned like any other, the problem+json mappings only apply to HTTP
+func (g grpcDecisions) evaluate(rule bool, req *structpb.Struct) (*structpb.Struct, error) {
+	name := req.GetFields()["name"].GetStringValue()
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	ctx := req.GetFields()["context"].GetStructValue().AsMap()
+	subject := req.GetFields()["subject"].GetStringValue()
+	out, _, err := g.s.evaluate(rule, name, subject, ctx)
+	if err != nil {
+		return nil, status.Error(evalCode(err), err.Error())
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to encode result: %v", err))
+	}
+	res := &structpb.Struct{}
+	if err := res.UnmarshalJSON(data); err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to encode result: %v", err))
+	}
+	return res, nil
+}
+
+// evalCode returns the gRPC status code of an evaluation error, the counterpart of evalStatus
+func evalCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, errNotFound):
+		return codes.NotFound
+	case errors.Is(err, ruleengine.ErrContextTooLarge):
+		return codes.InvalidArgument
+	case errors.Is(err, ruleengine.ErrQueueFull):
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// grpcServer returns a gRPC server with the decision service and the standard health service, always serving since
+// a failed reload keeps the previous engine
+func (s *server) grpcServer() *grpc.Server {
+	srv := grpc.NewServer()
+	srv.RegisterService(&decisionServiceDesc, grpcDecisions{s: s})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestServer_GRPC(t *testing.T) {
+	s := &server{flags: engineFlags{config: "../../testdata/reason_rules.yml", variables: "user"}}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	srv := s.grpcServer()
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	tests := []struct {
+		name       string
+		method     string
+		req        map[string]interface{}
+		wantPassed bool
+		wantCode   codes.Code
+	}{
+		{
+			name:       "success - evaluate ruleset",
+			method:     "EvaluateRuleset",
+			req:        map[string]interface{}{"name": "any_kyc", "context": map[string]interface{}{"user": map[string]interface{}{"age": 21, "verified": false}}},
+			wantPassed: true,
+		},
+		{
+			name:   "success - evaluate rule",
+			method: "EvaluateRule",
+			req:    map[string]interface{}{"name": "age_validation", "context": map[string]interface{}{"user": map[string]interface{}{"age": 15}}},
+		},
+		{
+			name:     "fail - unknown ruleset",
+			method:   "EvaluateRuleset",
+			req:      map[string]interface{}{"name": "unknown"},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "fail - missing name",
+			method:   "EvaluateRule",
+			req:      map[string]interface{}{},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := structpb.NewStruct(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := &structpb.Struct{}
+			err = conn.Invoke(context.Background(), "/"+decisionServiceName+"/"+tt.method, req, res)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("Invoke() code = %v, want %v, error %v", got, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if got := res.GetFields()["passed"].GetBoolValue(); got != tt.wantPassed {
+				t.Errorf("passed = %v, want %v, response %v", got, tt.wantPassed, res)
+			}
+		})
+	}
+
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if health.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Check() = %v, want SERVING", health.GetStatus())
+	}
+}
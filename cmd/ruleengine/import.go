@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// runImportOPA converts an OPA policy and data document into a rules config printed as YAML
+func runImportOPA(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("import-opa", flag.ContinueOnError)
+	policyPath := fs.String("policy", "", "path to the Rego module to convert")
+	dataPath := fs.String("data", "", "path to a JSON data document whose values become globals")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *policyPath == "" {
+		return errors.New("-policy is required")
+	}
+
+	policy, err := os.ReadFile(*policyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read policy: %w", err)
+	}
+	var data []byte
+	if *dataPath != "" {
+		if data, err = os.ReadFile(*dataPath); err != nil {
+			return fmt.Errorf("failed to read data document: %w", err)
+		}
+	}
+	rc, err := ruleengine.ImportOPA(policy, data)
+	if err != nil {
+		return fmt.Errorf("failed to import policy: %w", err)
+	}
+
+	enc := yaml.NewEncoder(stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(rc); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return enc.Close()
+}
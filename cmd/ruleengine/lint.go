@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// runLint reports rule expressions failing the lint checks of the config, or the default checks if it has none
+func runLint(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	issues, err := engine.Lint(engine.LintRules())
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%s: %d lint issue(s) found", ef.source(), len(issues))
+	}
+	fmt.Fprintf(stdout, "%s: no issues found\n", ef.source())
+	return nil
+}
@@ -0,0 +1,129 @@
+// Command ruleengine is a command line interface for authoring and evaluating ruleengine configurations
+//
+//	ruleengine <command> [flags]
+//
+// Run `ruleengine help` for the list of commands.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a ruleengine subcommand
+type command struct {
+	// summary is the one line description shown by help
+	summary string
+	// run executes the command with its arguments, writing output to stdout
+	run func(args []string, stdout io.Writer) error
+}
+
+// commands is the registry of subcommands keyed by name
+var commands = map[string]command{
+	"analyze": {
+		summary: "report unused rules, unreachable rulesets, unused globals and duplicate or conflicting rules",
+		run:     runAnalyze,
+	},
+	"batch": {
+		summary: "evaluate a ruleset against a CSV or JSONL dataset with parallel workers, writing JSONL results",
+		run:     runBatch,
+	},
+	"check": {
+		summary: "verify a config compiles and defines the globals its rules use, -all-environments checks each one",
+		run:     runCheck,
+	},
+	"compile": {
+		summary: "compile a config and its environment overrides into a bundle loaded with -bundle",
+		run:     runCompile,
+	},
+	"docs": {
+		summary: "render the rules, rulesets and environments of a config as Markdown or HTML",
+		run:     runDocs,
+	},
+	"encrypt": {
+		summary: "encrypt a config or bundle with AES-GCM, loaded with -key-file",
+		run:     runEncrypt,
+	},
+	"env-descriptor": {
+		summary: "print the variables and functions the host CEL env must declare, verified with WithEnvDescriptor",
+		run:     runEnvDescriptor,
+	},
+	"eval": {
+		summary: "evaluate a rule or ruleset against a JSON context",
+		run:     runEval,
+	},
+	"explain": {
+		summary: "print the AST, variables, inheritance chain and cost estimate of a rule",
+		run:     runExplain,
+	},
+	"export": {
+		summary: "write the canonical expression and checked AST of every rule to .cel and .textproto files",
+		run:     runExport,
+	},
+	"gen-vars": {
+		summary: "generate cel.Variable declarations and context conversion from annotated Go structs",
+		run:     runGenVars,
+	},
+	"import-opa": {
+		summary: "convert a simple OPA Rego policy and data document into a rules config",
+		run:     runImportOPA,
+	},
+	"lint": {
+		summary: "report rule expressions failing the complexity checks configured in the lint section",
+		run:     runLint,
+	},
+	"repl": {
+		summary: "interactively evaluate expressions, rules and rulesets against an editable context",
+		run:     runRepl,
+	},
+	"serve": {
+		summary: "serve rule and ruleset decisions over HTTP and gRPC with /reload, /rules, /stats and /healthz endpoints",
+		run:     runServe,
+	},
+	"simulate": {
+		summary: "replay a CSV or JSONL dataset through a config and a candidate, reporting pass rates and flips",
+		run:     runSimulate,
+	},
+	"validate": {
+		summary: "load and compile a config, reporting any errors and lint issues",
+		run:     runValidate,
+	},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "ruleengine: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches args to the matching subcommand
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stdout)
+		return fmt.Errorf("unknown command '%s'", args[0])
+	}
+	return cmd.run(args[1:], stdout)
+}
+
+// usage prints the list of commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ruleengine <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
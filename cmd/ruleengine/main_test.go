@@ -0,0 +1,591 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "success - help",
+			args:       []string{"help"},
+			wantOutput: "Commands:",
+		},
+		{
+			name:       "success - validate",
+			args:       []string{"validate", "-config", "../../testdata/rules.yml", "-env", "production"},
+			wantOutput: "../../testdata/rules.yml: ok",
+		},
+		{
+			name:       "success - check",
+			args:       []string{"check", "-config", "../../testdata/check_rules.yml", "-env", "development"},
+			wantOutput: "development: ok",
+		},
+		{
+			name: "fail - check all environments",
+			args: []string{"check", "-config", "../../testdata/check_rules.yml", "--all-environments"},
+			wantOutput: "(base): FAIL undefined globals referenced by rules: beta_cohort\n" +
+				"development: ok\n" +
+				"production: ok\n" +
+				"staging: FAIL failed to get execution policy",
+			wantErr: true,
+		},
+		{
+			name:    "fail - check all environments with env",
+			args:    []string{"check", "-config", "../../testdata/check_rules.yml", "-all-environments", "-env", "production"},
+			wantErr: true,
+		},
+		{
+			name:    "fail - validate bad rules",
+			args:    []string{"validate", "-config", "../../testdata/bad_rules.yml"},
+			wantErr: true,
+		},
+		{
+			name:       "success - env descriptor",
+			args:       []string{"env-descriptor", "-config", "../../testdata/rules.yml"},
+			wantOutput: "\"variables\": [\n    {\n      \"name\": \"globals\",",
+		},
+		{
+			name:    "fail - env descriptor format",
+			args:    []string{"env-descriptor", "-config", "../../testdata/rules.yml", "-format", "xml"},
+			wantErr: true,
+		},
+		{
+			name: "success - eval ruleset",
+			args: []string{"eval", "-config", "../../testdata/rules.yml", "-env", "production",
+				"-ruleset", "user_registration", "-context", "testdata/context.json"},
+			wantOutput: `"passed": true`,
+		},
+		{
+			name: "success - eval rule",
+			args: []string{"eval", "-config", "../../testdata/rules.yml", "-env", "production",
+				"-rule", "rate_limiting", "-context", "testdata/context.json"},
+			wantOutput: `"name": "rate_limiting"`,
+		},
+		{
+			name: "success - eval ruleset reason codes",
+			args: []string{"eval", "-config", "../../testdata/reason_rules.yml",
+				"-ruleset", "kyc", "-context", "testdata/context.json"},
+			wantOutput: `"reason_codes": [
+    "KYC_002"
+  ]`,
+		},
+		{
+			name: "success - eval rule with overlays",
+			args: []string{"eval", "-config", "../../testdata/rules.yml",
+				"-overlays", "../../testdata/overlay_region.yml,../../testdata/overlay_dev.yml",
+				"-rule", "age_validation", "-context", "testdata/context.json"},
+			wantOutput: `"name": "age_validation"`,
+		},
+		{
+			name:    "fail - eval missing overlay",
+			args:    []string{"eval", "-config", "../../testdata/rules.yml", "-overlays", "missing.yml", "-rule", "age_validation"},
+			wantErr: true,
+		},
+		{
+			name: "success - eval rule with profile",
+			args: []string{"eval", "-config", "../../testdata/profile_rules.yml", "-vars", "user",
+				"-profile", "us", "-rule", "alcohol_purchase", "-context", "testdata/context.json"},
+			wantOutput: `"passed": true`,
+		},
+		{
+			name:    "fail - eval unknown profile",
+			args:    []string{"eval", "-config", "../../testdata/profile_rules.yml", "-profile", "fr", "-rule", "age_validation"},
+			wantErr: true,
+		},
+		{
+			name:    "fail - eval without target",
+			args:    []string{"eval", "-config", "../../testdata/rules.yml"},
+			wantErr: true,
+		},
+		{
+			name: "success - explain",
+			args: []string{"explain", "-config", "../../testdata/rules.yml", "-rule", "test_user"},
+			wantOutput: "Extends: email_whitelist -> email_format\n" +
+				"Variables: user.email\n" +
+				"Cost: 2\n",
+		},
+		{
+			name:    "fail - explain unknown rule",
+			args:    []string{"explain", "-config", "../../testdata/rules.yml", "-rule", "missing"},
+			wantErr: true,
+		},
+		{
+			name:       "success - docs markdown",
+			args:       []string{"docs", "-config", "../../testdata/rules.yml"},
+			wantOutput: "### age_validation\n\n**Age Validation** — Validates user age requirements\n\nOwner: identity-team\n",
+		},
+		{
+			name:       "success - docs html",
+			args:       []string{"docs", "-config", "../../testdata/rules.yml", "-format", "html"},
+			wantOutput: `<dt>Extends</dt><dd><a href="#email_format">email_format</a></dd>`,
+		},
+		{
+			name:       "success - docs confidential redacted",
+			args:       []string{"docs", "-config", "../../testdata/confidential_rules.yml"},
+			wantOutput: "| home_country | \"<confidential>\" |",
+		},
+		{
+			name:       "success - explain confidential redacted",
+			args:       []string{"explain", "-config", "../../testdata/confidential_rules.yml", "-rule", "fraud_screen", "-vars", "user"},
+			wantOutput: "Expression: <confidential>\nVariables: user.email, user.risk_score\n",
+		},
+		{
+			name:       "success - explain confidential shown",
+			args:       []string{"explain", "-config", "../../testdata/confidential_rules.yml", "-rule", "fraud_screen", "-vars", "user", "-show-confidential"},
+			wantOutput: "Variables: globals.fraud.blocked_emails, globals.fraud.max_risk, user.email, user.risk_score\n",
+		},
+		{
+			name:    "fail - docs unknown format",
+			args:    []string{"docs", "-config", "../../testdata/rules.yml", "-format", "pdf"},
+			wantErr: true,
+		},
+		{
+			name: "success - analyze",
+			args: []string{"analyze", "-config", "../../testdata/analyze_rules.yml"},
+			wantOutput: "unused rule: rate_limiting is not referenced by any ruleset\n" +
+				"unreachable ruleset: blocked can never pass, rules disabled_check can never pass\n" +
+				"unused global: legacy_limit is not referenced by any rule\n",
+		},
+		{
+			name:    "fail - analyze strict",
+			args:    []string{"analyze", "-config", "../../testdata/analyze_rules.yml", "-strict"},
+			wantErr: true,
+		},
+		{
+			name:       "success - lint",
+			args:       []string{"lint", "-config", "../../testdata/reason_rules.yml", "-vars", "user"},
+			wantOutput: "../../testdata/reason_rules.yml: no issues found",
+		},
+		{
+			name:       "fail - lint issues",
+			args:       []string{"lint", "-config", "../../testdata/lint_rules.yml", "-vars", "user"},
+			wantOutput: "rule 'unbounded_matches': bounded_matches: matches() on unbounded input user.bio",
+			wantErr:    true,
+		},
+		{
+			name:    "fail - validate lint issues",
+			args:    []string{"validate", "-config", "../../testdata/lint_rules.yml", "-vars", "user"},
+			wantErr: true,
+		},
+		{
+			name:    "fail - unknown command",
+			args:    []string{"unknown"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("run() output = %q, want it to contain %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRun_EvalOutput(t *testing.T) {
+	var stdout bytes.Buffer
+	err := run([]string{"eval", "-config", "../../testdata/rules.yml", "-env", "production",
+		"-ruleset", "user_registration", "-context", "testdata/context.json"}, &stdout)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var got evalOutput
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("run() returned invalid JSON: %v", err)
+	}
+	if !got.Passed || len(got.Rules) != 3 {
+		t.Errorf("run() = %+v, want passed with 3 rules", got)
+	}
+}
+
+func TestRun_Repl(t *testing.T) {
+	input := strings.Join([]string{
+		"user.age >= globals.min_age",
+		":set user.age 10",
+		"user.age >= globals.min_age",
+		":rule age_validation",
+		":context {\"user\": {\"email\": \"a@b.com\"}}",
+		"[user.email, 'x']",
+		"user.missing",
+		":unknown",
+		":quit",
+		"user.age",
+	}, "\n")
+	stdin = strings.NewReader(input)
+	t.Cleanup(func() { stdin = os.Stdin })
+
+	var stdout bytes.Buffer
+	err := run([]string{"repl", "-config", "../../testdata/rules.yml", "-context", "testdata/context.json"}, &stdout)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	for _, want := range []string{
+		"> true (bool)\n> > false (bool)\n",
+		`"name": "age_validation"`,
+		`["a@b.com","x"] (list)`,
+		"error: no such key: missing",
+		"error: unknown command ':unknown'",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("run() output = %q, want it to contain %q", stdout.String(), want)
+		}
+	}
+	if strings.Count(stdout.String(), "> ") != 9 {
+		t.Errorf("run() output = %q, want the session to stop at :quit", stdout.String())
+	}
+}
+
+func TestRun_CompileBundle(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "rules.bundle")
+
+	var stdout bytes.Buffer
+	err := run([]string{"compile", "-config", "../../testdata/rules.yml", "-env", "development", "-o", bundle}, &stdout)
+	if err != nil {
+		t.Fatalf("run() compile error = %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), bundle+": ") || !strings.Contains(stdout.String(), "sha256:") {
+		t.Errorf("run() compile output = %q, want the bundle path and digest", stdout.String())
+	}
+
+	stdout.Reset()
+	err = run([]string{"eval", "-bundle", bundle, "-ruleset", "user_registration", "-context", "testdata/context.json"}, &stdout)
+	if err != nil {
+		t.Fatalf("run() eval error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), `"passed": true`) {
+		t.Errorf("run() eval output = %q, want the ruleset to pass", stdout.String())
+	}
+
+	err = run([]string{"validate", "-bundle", filepath.Join(t.TempDir(), "missing.bundle")}, &stdout)
+	if err == nil {
+		t.Errorf("run() validate missing bundle error = nil, want error")
+	}
+}
+
+func TestRun_Encrypt(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "rules.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	encrypted := filepath.Join(dir, "rules.yml.enc")
+
+	var stdout bytes.Buffer
+	err := run([]string{"encrypt", "-in", "../../testdata/rules.yml", "-key-file", keyFile, "-o", encrypted}, &stdout)
+	if err != nil {
+		t.Fatalf("run() encrypt error = %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), encrypted+": ") {
+		t.Errorf("run() encrypt output = %q, want the encrypted path", stdout.String())
+	}
+
+	stdout.Reset()
+	err = run([]string{"eval", "-config", encrypted, "-key-file", keyFile, "-ruleset", "user_registration", "-context", "testdata/context.json"}, &stdout)
+	if err != nil {
+		t.Fatalf("run() eval error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), `"passed": true`) {
+		t.Errorf("run() eval output = %q, want the ruleset to pass", stdout.String())
+	}
+
+	if err := run([]string{"validate", "-config", encrypted}, &stdout); err == nil {
+		t.Errorf("run() validate without key error = nil, want error")
+	}
+	if err := run([]string{"encrypt", "-in", "../../testdata/rules.yml"}, &stdout); err == nil {
+		t.Errorf("run() encrypt without key error = nil, want error")
+	}
+}
+
+func TestRun_ImportOPA(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "rules.yml")
+
+	var stdout bytes.Buffer
+	err := run([]string{"import-opa", "-policy", "../../testdata/opa_policy.rego", "-data", "../../testdata/opa_data.json"}, &stdout)
+	if err != nil {
+		t.Fatalf("run() import-opa error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), `is_admin: '"admin" in user.roles'`) {
+		t.Errorf("run() import-opa output = %q, want the is_admin function", stdout.String())
+	}
+	if err := os.WriteFile(config, stdout.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout.Reset()
+	if err := run([]string{"validate", "-config", config}, &stdout); err != nil {
+		t.Errorf("run() validate imported config error = %v", err)
+	}
+
+	err = run([]string{"import-opa", "-policy", "../../testdata/rules.yml"}, &stdout)
+	if err == nil {
+		t.Errorf("run() import-opa invalid policy error = nil, want error")
+	}
+}
+
+func TestRun_Export(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cel")
+
+	var stdout bytes.Buffer
+	err := run([]string{"export", "-config", "../../testdata/rules.yml", "-o", dir}, &stdout)
+	if err != nil {
+		t.Fatalf("run() export error = %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), dir+": ") {
+		t.Errorf("run() export output = %q, want the output directory", stdout.String())
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "age_validation.cel"))
+	if err != nil {
+		t.Fatalf("failed to read exported expression: %v", err)
+	}
+	if string(got) != "user.age >= globals.min_age\n" {
+		t.Errorf("age_validation.cel = %q, want the canonical expression", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "age_validation.textproto")); err != nil {
+		t.Errorf("age_validation.textproto not written: %v", err)
+	}
+}
+
+func TestRun_GenVars(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "vars.go")
+
+	var stdout bytes.Buffer
+	if err := run([]string{"gen-vars", "-o", output, "testdata/genvars"}, &stdout); err != nil {
+		t.Fatalf("run() gen-vars error = %v", err)
+	}
+	if want := output + ": 2 variables\n"; stdout.String() != want {
+		t.Errorf("run() gen-vars output = %q, want %q", stdout.String(), want)
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("testdata/genvars/ruleengine_vars.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("run() gen-vars generated:\n%s\nwant:\n%s", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{
+			name:   "fail - no annotated structs",
+			source: "package types\n\ntype User struct {\n\tAge int\n}\n",
+		},
+		{
+			name:   "fail - variable declared twice",
+			source: "package types\n\n//ruleengine:variable user\ntype A struct{}\n\n//ruleengine:variable user\ntype B struct{}\n",
+		},
+		{
+			name:   "fail - dotted cel tag",
+			source: "package types\n\n//ruleengine:variable user\ntype User struct {\n\tAge int `cel:\"user.age\"`\n}\n",
+		},
+		{
+			name:   "fail - invalid go",
+			source: "package types\n\ntype User struct {\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := t.TempDir()
+			if err := os.WriteFile(filepath.Join(pkg, "types.go"), []byte(tt.source), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := run([]string{"gen-vars", pkg}, &stdout); err == nil {
+				t.Errorf("run() gen-vars error = nil, want error")
+			}
+		})
+	}
+	if err := run([]string{"gen-vars"}, &stdout); err == nil {
+		t.Errorf("run() gen-vars without package error = nil, want error")
+	}
+}
+
+func TestRun_Simulate(t *testing.T) {
+	config, err := os.ReadFile("../../testdata/rules.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidate := filepath.Join(t.TempDir(), "candidate.yml")
+	stricter := strings.Replace(string(config), "min_age: 13", "min_age: 18", 1)
+	if err := os.WriteFile(candidate, []byte(stricter), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name: "success - candidate from csv",
+			args: []string{"simulate", "-config", "../../testdata/rules.yml", "-candidate", candidate,
+				"-data", "../../testdata/simulation.csv", "-rulesets", "user_registration"},
+			wantOutput: "records: 4\n\nuser_registration\n" +
+				"  baseline:  50.0% passed (2 passed, 2 failed)\n" +
+				"  candidate: 25.0% passed (1 passed, 3 failed)\n" +
+				"  flips:     1 (pass to fail 1, fail to pass 0) e.g. records 2\n" +
+				"  rule age_validation: failed 1, decisive 1 -> failed 2, decisive 2\n" +
+				"  rule user_status: failed 1, decisive 1 -> failed 1, decisive 1\n",
+		},
+		{
+			name: "success - baseline from jsonl",
+			args: []string{"simulate", "-config", "../../testdata/rules.yml",
+				"-data", "../../testdata/simulation.jsonl", "-rulesets", "user_registration"},
+			wantOutput: "  baseline:  50.0% passed (2 passed, 2 failed)\n  rule age_validation: failed 1, decisive 1\n",
+		},
+		{
+			name:    "fail - missing data",
+			args:    []string{"simulate", "-config", "../../testdata/rules.yml"},
+			wantErr: true,
+		},
+		{
+			name:    "fail - unknown format",
+			args:    []string{"simulate", "-config", "../../testdata/rules.yml", "-data", "../../testdata/rules.yml"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("run() output = %q, want it to contain %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRun_Batch(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name        string
+		args        []string
+		existing    string
+		wantRecords []int
+		wantPassed  []bool
+		wantErr     bool
+	}{
+		{
+			name:        "success - jsonl",
+			args:        []string{"-input", "../../testdata/simulation.jsonl"},
+			wantRecords: []int{1, 2, 3, 4},
+			wantPassed:  []bool{true, true, false, false},
+		},
+		{
+			name:        "success - csv with one worker",
+			args:        []string{"-input", "../../testdata/simulation.csv", "-workers", "1"},
+			wantRecords: []int{1, 2, 3, 4},
+			wantPassed:  []bool{true, true, false, false},
+		},
+		{
+			name:        "success - resume after a cut short line",
+			args:        []string{"-input", "../../testdata/simulation.jsonl", "-resume"},
+			existing:    `{"record":1,"result":{"name":"user_registration","passed":true}}` + "\n" + `{"record":2,"res`,
+			wantRecords: []int{1, 2, 3, 4},
+			wantPassed:  []bool{true, true, false, false},
+		},
+		{
+			name:    "fail - unknown ruleset",
+			args:    []string{"-input", "../../testdata/simulation.jsonl", "-ruleset", "unknown"},
+			wantErr: true,
+		},
+		{
+			name: "fail - resume longer output",
+			args: []string{"-input", "../../testdata/simulation.jsonl", "-resume"},
+			existing: `{"record":1}` + "\n" + `{"record":2}` + "\n" + `{"record":3}` + "\n" + `{"record":4}` + "\n" +
+				`{"record":5}` + "\n",
+			wantErr: true,
+		},
+		{
+			name:    "fail - missing input",
+			args:    []string{},
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(dir, fmt.Sprintf("results%d.jsonl", i))
+			if tt.existing != "" {
+				if err := os.WriteFile(output, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			args := append([]string{"batch", "-config", "../../testdata/rules.yml", "-ruleset", "user_registration",
+				"-output", output, "-progress=false"}, tt.args...)
+			var stdout bytes.Buffer
+			err := run(args, &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var records []int
+			var passed []bool
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				var out batchOutput
+				if err := json.Unmarshal([]byte(line), &out); err != nil {
+					t.Fatalf("json.Unmarshal(%q) error = %v", line, err)
+				}
+				records = append(records, out.Record)
+				passed = append(passed, out.Result.Passed)
+			}
+			if !slices.Equal(records, tt.wantRecords) || !slices.Equal(passed, tt.wantPassed) {
+				t.Errorf("run() records = %v passed %v, want %v passed %v", records, passed, tt.wantRecords, tt.wantPassed)
+			}
+		})
+	}
+}
+
+func TestBatchJob_Cancelled(t *testing.T) {
+	engine, err := (&engineFlags{config: "../../testdata/rules.yml", variables: "user,request"}).build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job := batchJob{engine: engine, ruleset: "user_registration", workers: 2}
+	var out bytes.Buffer
+	summary, err := job.run(ctx, ruleengine.NewJSONLReader(strings.NewReader(`{"user": {"age": 21}}`)), &out)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("run() error = %v, want %v", err, context.Canceled)
+	}
+	if summary.records != 0 || out.Len() != 0 {
+		t.Errorf("run() wrote %d records: %q", summary.records, out.String())
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/common/types/ref"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// stdin is the input read by interactive commands, replaced in tests
+var stdin io.Reader = os.Stdin
+
+// replHelp lists the REPL commands
+const replHelp = `Enter a CEL expression to evaluate it against the context, or one of:
+  :context <json>      replace the context with a JSON object
+  :load <file>         replace the context with a JSON file
+  :set <path> <value>  set a dotted context path to a JSON value, e.g. :set user.age 21
+  :show                print the context
+  :rule <name>         evaluate a rule
+  :ruleset <name>      evaluate a ruleset
+  :help                print this help
+  :quit                exit`
+
+// repl is the state of an interactive session
+type repl struct {
+	engine *ruleengine.RuleEngine
+	ctx    map[string]interface{}
+	out    io.Writer
+}
+
+// runRepl starts an interactive session evaluating expressions, rules and rulesets against an editable context
+func runRepl(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	contextPath := fs.String("context", "", "path to a JSON file with the initial context")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	ctx, err := readContext(*contextPath)
+	if err != nil {
+		return err
+	}
+
+	r := &repl{engine: engine, ctx: ctx, out: stdout}
+	fmt.Fprintf(stdout, "Loaded %s, type :help for commands\n", ef.source())
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for {
+		fmt.Fprint(stdout, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(stdout)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == ":quit" || line == ":q" {
+			return nil
+		}
+		if err := r.handle(line); err != nil {
+			fmt.Fprintf(stdout, "error: %v\n", err)
+		}
+	}
+}
+
+// handle executes a single line of input
+func (r *repl) handle(line string) error {
+	if !strings.HasPrefix(line, ":") {
+		out, err := r.engine.EvaluateExpression(line, r.ctx)
+		if err != nil {
+			return err
+		}
+		return r.print(out)
+	}
+
+	cmd, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch cmd {
+	case ":help":
+		fmt.Fprintln(r.out, replHelp)
+	case ":context":
+		ctx := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(arg), &ctx); err != nil {
+			return fmt.Errorf("failed to parse context: %w", err)
+		}
+		r.ctx = ctx
+	case ":load":
+		ctx, err := readContext(arg)
+		if err != nil {
+			return err
+		}
+		r.ctx = ctx
+	case ":set":
+		path, raw, ok := strings.Cut(arg, " ")
+		if !ok || path == "" {
+			return fmt.Errorf("usage: :set <path> <value>")
+		}
+		setPath(r.ctx, path, parseValue(strings.TrimSpace(raw)))
+	case ":show":
+		return r.printJSON(r.ctx)
+	case ":rule":
+		result, err := r.engine.EvaluateRule(arg, ruleengine.WithEvalContext(r.ctx))
+		if err != nil {
+			return err
+		}
+		return r.printJSON(ruleOutput(result))
+	case ":ruleset":
+		result, err := r.engine.EvaluateRuleset(arg, ruleengine.WithEvalContext(r.ctx))
+		if err != nil {
+			return err
+		}
+		return r.printJSON(rulesetOutput(result))
+	default:
+		return fmt.Errorf("unknown command '%s', type :help for commands", cmd)
+	}
+	return nil
+}
+
+// print writes a CEL value as JSON, falling back to its Go representation for values JSON cannot hold
+func (r *repl) print(val ref.Val) error {
+	pb, err := val.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		fmt.Fprintf(r.out, "%v (%s)\n", val.Value(), val.Type().TypeName())
+		return nil
+	}
+	data, err := json.Marshal(pb.(*structpb.Value).AsInterface())
+	if err != nil {
+		return fmt.Errorf("failed to write value: %w", err)
+	}
+	fmt.Fprintf(r.out, "%s (%s)\n", data, val.Type().TypeName())
+	return nil
+}
+
+// printJSON writes v as indented JSON
+func (r *repl) printJSON(v interface{}) error {
+	enc := json.NewEncoder(r.out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
+
+// parseValue parses raw as JSON, treating anything else as a string
+func parseValue(raw string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return raw
+	}
+	return v
+}
+
+// setPath sets value at the dotted path in ctx, creating intermediate maps as needed
+func setPath(ctx map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	current := ctx
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
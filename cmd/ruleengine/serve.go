@@ -0,0 +1,442 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// maxRequestBytes bounds the size of an evaluation context accepted by the server
+const maxRequestBytes = 1 << 20
+
+// errNotFound is wrapped by the errors of rules and rulesets the engine served does not define
+var errNotFound = errors.New("not found")
+
+// server is a decision service evaluating rules and rulesets of an engine reloaded on demand
+type server struct {
+	flags engineFlags
+	// adminToken is the bearer token of the admin endpoints and confidential rules, both are refused when empty
+	adminToken string
+	// decisions keeps the recent ruleset decisions of subjects, nil unless enabled with -decision-log
+	decisions *ruleengine.DecisionLog
+	// drafts are the rule changes authored at runtime for the engine currently served, dropped on reload
+	drafts *ruleengine.Drafts
+	// audit is the draft audit trail of the engines replaced by reloads, each followed by a reload entry
+	audit []ruleengine.AuditEntry
+
+	mu       sync.RWMutex
+	engine   *ruleengine.RuleEngine
+	loadedAt time.Time
+}
+
+// rulesOutput is the JSON representation of the rules and rulesets served
+type rulesOutput struct {
+	Source   string    `json:"source"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Rules    []string  `json:"rules"`
+	Rulesets []string  `json:"rulesets"`
+}
+
+// ruleDetailOutput is the JSON representation of a single rule served, confidential rules are redacted
+type ruleDetailOutput struct {
+	Rule         string   `json:"rule"`
+	Expression   string   `json:"expression"`
+	Variables    []string `json:"variables"`
+	Extends      []string `json:"extends,omitempty"`
+	Confidential bool     `json:"confidential,omitempty"`
+}
+
+// decisionOutput is the JSON representation of a decision recorded for a subject
+type decisionOutput struct {
+	Time   time.Time  `json:"time"`
+	Result evalOutput `json:"result"`
+}
+
+// problemOutput is the problem+json body of a result which did not pass, carrying the result as an extension member
+type problemOutput struct {
+	ruleengine.Problem
+	Result evalOutput `json:"result"`
+}
+
+// runServe serves decisions and admin endpoints over HTTP, and decisions over gRPC with -grpc-addr, until interrupted
+//
+//	POST /v1/rulesets/{name} and POST /v1/rules/{name} evaluate a JSON context, POST /v1/decisions/{name} returns the
+//	outcome of a named decision, GET /rules lists the loaded rules,
+//	GET /rules/{name} shows a rule, redacted if confidential unless the request carries the -admin-token,
+//	POST /reload loads the config again and GET /stats serves the engine statistics, both to the -admin-token, and
+//	GET /healthz reports liveness, degraded when running on the -default-config
+//	With -decision-log, ruleset evaluations with a subject query parameter are recorded, for up to
+//	-decision-log-subjects subjects, and
+//	GET /v1/subjects/{subject}/decisions serves the last of them as a JSON timeline or, with format=dot, a DOT graph
+//	Results which did not pass are served as problem+json when the config maps them in error_handling.http_problems
+//	With -grpc-addr, the DecisionService and the standard gRPC health service are served on a second listener
+func runServe(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	adminToken := fs.String("admin-token", "", "bearer token required by the admin endpoints, disabled when empty, and allowed to see confidential rules on GET /rules/{name}")
+	grpcAddr := fs.String("grpc-addr", "", "address to serve the gRPC decision service on, disabled if empty")
+	decisionLog := fs.Int("decision-log", 0, "number of recent ruleset decisions kept per subject, 0 disables the decision log")
+	decisionSubjects := fs.Int("decision-log-subjects", ruleengine.DefaultDecisionLogSubjects, "number of subjects the decision log keeps, the least recently recorded are dropped beyond it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s := &server{flags: ef, adminToken: *adminToken}
+	if *decisionLog > 0 {
+		s.decisions = ruleengine.NewDecisionLog(*decisionLog)
+		s.decisions.SetMaxSubjects(*decisionSubjects)
+	}
+	if err := s.reload(""); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errs := make(chan error, 2)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	fmt.Fprintf(stdout, "serving %s on %s\n", ef.source(), *addr)
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen for gRPC: %w", err)
+		}
+		grpcSrv := s.grpcServer()
+		defer grpcSrv.GracefulStop()
+		go func() {
+			errs <- grpcSrv.Serve(lis)
+		}()
+		fmt.Fprintf(stdout, "serving gRPC on %s\n", *grpcAddr)
+	}
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// reload builds a new engine from the flags, the current engine keeps serving if it fails
+//
+//	The reloaded config replaces the pending and committed drafts, their audit trail is kept with a reload entry of
+//	author
+func (s *server) reload(author string) error {
+	engine, err := s.flags.build()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := engine.DefaultConfigErr(); err != nil && s.engine != nil {
+		// The default config only replaces a broken config at startup, never one already serving
+		return err
+	}
+	if s.drafts != nil {
+		s.audit = append(s.audit, s.drafts.Audit()...)
+		s.audit = append(s.audit, ruleengine.AuditEntry{
+			Time:    time.Now().UTC(),
+			Author:  author,
+			Action:  "reload",
+			Message: "reloaded " + s.flags.source() + ", drafts discarded",
+		})
+	}
+	s.engine = engine
+	s.drafts = ruleengine.NewDrafts(engine)
+	s.loadedAt = time.Now().UTC()
+	return nil
+}
+
+// current returns the engine currently served, including the committed drafts
+func (s *server) current() *ruleengine.RuleEngine {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.engine.Active()
+}
+
+// handler returns the routes of the decision service and its admin endpoints
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/rulesets/{name}", s.handleEvaluate(false))
+	mux.HandleFunc("POST /v1/rules/{name}", s.handleEvaluate(true))
+	mux.HandleFunc("GET /v1/rulesets/{name}/schema", s.handleSchema)
+	mux.HandleFunc("POST /v1/decisions/{name}", s.handleDecide)
+	mux.HandleFunc("GET /v1/subjects/{subject}/decisions", s.admin(s.handleDecisions))
+	s.registerDrafts(mux)
+	mux.HandleFunc("GET /rules", s.handleRules)
+	mux.HandleFunc("GET /rules/{name}", s.handleRule)
+	mux.HandleFunc("POST /reload", s.admin(s.handleReload))
+	mux.HandleFunc("GET /stats", s.admin(func(w http.ResponseWriter, r *http.Request) {
+		s.current().StatsHandler().ServeHTTP(w, r)
+	}))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		engine := s.engine
+		s.mu.RUnlock()
+		if err := engine.DefaultConfigErr(); err != nil {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "degraded", "error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+// handleEvaluate evaluates the rule or ruleset named in the path against the JSON context in the request body
+func (s *server) handleEvaluate(rule bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := make(map[string]interface{})
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&ctx); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse context: %w", err))
+			return
+		}
+
+		out, problem, err := s.evaluate(rule, r.PathValue("name"), r.URL.Query().Get("subject"), ctx)
+		if err != nil {
+			writeError(w, evalStatus(err), err)
+			return
+		}
+		if problem != nil {
+			w.Header().Set("Content-Type", ruleengine.ProblemContentType)
+			w.WriteHeader(problem.Status)
+			_ = json.NewEncoder(w).Encode(problemOutput{Problem: *problem, Result: out})
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// evaluate evaluates the rule or ruleset name against ctx and records ruleset decisions of subject in the decision log
+//
+//	The problem is nil unless the config maps the result in error_handling.http_problems, errors wrap errNotFound
+//	if the engine served has no such rule or ruleset
+func (s *server) evaluate(rule bool, name, subject string, ctx map[string]interface{}) (evalOutput, *ruleengine.Problem, error) {
+	engine := s.current()
+	if rule {
+		if !slices.Contains(engine.RuleNames(), name) {
+			return evalOutput{}, nil, fmt.Errorf("rule '%s' %w", name, errNotFound)
+		}
+		result, err := engine.EvaluateRule(name, ruleengine.WithEvalContext(ctx))
+		if err != nil {
+			return evalOutput{}, nil, err
+		}
+		return ruleOutput(result), engine.RuleProblem(result), nil
+	}
+	if !slices.Contains(engine.RulesetNames(), name) {
+		return evalOutput{}, nil, fmt.Errorf("ruleset '%s' %w", name, errNotFound)
+	}
+	result, err := engine.EvaluateRuleset(name, ruleengine.WithEvalContext(ctx))
+	if err != nil {
+		return evalOutput{}, nil, err
+	}
+	if subject != "" && s.decisions != nil {
+		s.decisions.Record(subject, result)
+	}
+	return rulesetOutput(result), engine.RulesetProblem(result), nil
+}
+
+// evalStatus returns the HTTP status of an evaluation error
+func evalStatus(err error) int {
+	switch {
+	case errors.Is(err, errNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ruleengine.ErrContextTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ruleengine.ErrQueueFull):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// decideOutput is the outcome of a named decision with the ruleset results it was chosen from
+type decideOutput struct {
+	Decision string                `json:"decision"`
+	Outcome  string                `json:"outcome"`
+	When     string                `json:"when,omitempty"`
+	Rulesets map[string]evalOutput `json:"rulesets"`
+}
+
+// handleDecide evaluates the decision named in the path against the JSON context in the request body
+func (s *server) handleDecide(w http.ResponseWriter, r *http.Request) {
+	ctx := make(map[string]interface{})
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&ctx); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse context: %w", err))
+		return
+	}
+	engine := s.current()
+	name := r.PathValue("name")
+	if !slices.Contains(engine.DecisionNames(), name) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("decision '%s' not found", name))
+		return
+	}
+	result, err := engine.Decide(name, ruleengine.WithEvalContext(ctx))
+	if err != nil {
+		writeError(w, evalStatus(err), err)
+		return
+	}
+	out := decideOutput{
+		Decision: result.DecisionName,
+		Outcome:  result.Outcome,
+		When:     result.When,
+		Rulesets: make(map[string]evalOutput, len(result.Rulesets)),
+	}
+	for rulesetName, rulesetResult := range result.Rulesets {
+		out.Rulesets[rulesetName] = rulesetOutput(rulesetResult)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleRules lists the rules and rulesets of the engine currently served
+func (s *server) handleRules(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	engine, loadedAt := s.engine.Active(), s.loadedAt
+	s.mu.RUnlock()
+
+	usage, err := engine.VariableUsage()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rulesOutput{
+		Source:   s.flags.source(),
+		LoadedAt: loadedAt,
+		Rules:    sortedKeys(usage.Rules),
+		Rulesets: sortedKeys(usage.Rulesets),
+	})
+}
+
+// handleRule shows the expression and variables of the rule named in the path
+func (s *server) handleRule(w http.ResponseWriter, r *http.Request) {
+	engine := s.current()
+	name := r.PathValue("name")
+	explanation, err := engine.Explain(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	confidential := engine.Confidential(name)
+	if confidential && !s.authorized(r) {
+		explanation = explanation.Redact()
+	}
+	writeJSON(w, http.StatusOK, ruleDetailOutput{
+		Rule:         explanation.RuleName,
+		Expression:   strings.TrimSpace(explanation.Expression),
+		Variables:    explanation.Variables,
+		Extends:      explanation.Parents,
+		Confidential: confidential,
+	})
+}
+
+// handleSchema returns the JSON Schema of the context the ruleset named in the path needs
+func (s *server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	schema, err := s.current().ContextSchema(r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema)
+}
+
+// handleDecisions serves the last decisions of the subject named in the path, the n query parameter bounds their
+// number and format=dot renders them as a DOT graph
+//
+//	The decisions are only served with the admin token, they may reveal confidential outcomes
+func (s *server) handleDecisions(w http.ResponseWriter, r *http.Request) {
+	if s.decisions == nil {
+		writeError(w, http.StatusNotFound, errors.New("decision log is disabled, start the server with -decision-log"))
+		return
+	}
+	n := 0
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid n '%s', want a positive number", v))
+			return
+		}
+	}
+	decisions := s.decisions.Last(r.PathValue("subject"), n)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		out := make([]decisionOutput, 0, len(decisions))
+		for _, d := range decisions {
+			out = append(out, decisionOutput{Time: d.Time, Result: rulesetOutput(d.Result)})
+		}
+		writeJSON(w, http.StatusOK, out)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		_ = ruleengine.WriteDecisionGraph(w, decisions)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown format '%s', want json or dot", format))
+	}
+}
+
+// admin wraps an admin handler, requiring the admin token and refusing every request when none is set
+func (s *server) admin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			writeError(w, http.StatusForbidden, errors.New("admin endpoints are disabled, start the server with -admin-token"))
+			return
+		}
+		if !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, errors.New("admin token required"))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// authorized reports whether the request carries the admin token
+func (s *server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.adminToken != "" && ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+// handleReload loads the config again, reporting why it failed while the previous engine keeps serving
+func (s *server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := s.reload(author(r)); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.handleRules(w, r)
+}
+
+// writeJSON writes v as the JSON response body with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON object with an error field
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,692 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+func TestServer_Handler(t *testing.T) {
+	config, err := os.ReadFile("../../testdata/reason_rules.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	if err := os.WriteFile(path, config, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &server{flags: engineFlags{config: path, variables: "user"}, adminToken: "secret"}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	handler := s.handler()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		config     string
+		admin      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "success - healthz",
+			method:     http.MethodGet,
+			target:     "/healthz",
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"ok"`,
+		},
+		{
+			name:       "success - evaluate ruleset",
+			method:     http.MethodPost,
+			target:     "/v1/rulesets/any_kyc",
+			body:       `{"user": {"age": 21, "verified": false}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"any_kyc","passed":true`,
+		},
+		{
+			name:       "success - evaluate rule",
+			method:     http.MethodPost,
+			target:     "/v1/rules/age_validation",
+			body:       `{"user": {"age": 15}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"reason_code":"KYC_001"`,
+		},
+		{
+			name:       "fail - unknown ruleset",
+			method:     http.MethodPost,
+			target:     "/v1/rulesets/unknown",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"error"`,
+		},
+		{
+			name:       "fail - invalid context",
+			method:     http.MethodPost,
+			target:     "/v1/rulesets/kyc",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "failed to parse context",
+		},
+		{
+			name:       "success - ruleset schema",
+			method:     http.MethodGet,
+			target:     "/v1/rulesets/any_kyc/schema",
+			wantStatus: http.StatusOK,
+			wantBody:   `"age":{"type":"integer"}`,
+		},
+		{
+			name:       "fail - unknown ruleset schema",
+			method:     http.MethodGet,
+			target:     "/v1/rulesets/unknown/schema",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"error"`,
+		},
+		{
+			name:       "success - rules",
+			method:     http.MethodGet,
+			target:     "/rules",
+			wantStatus: http.StatusOK,
+			wantBody:   `"rulesets":["any_kyc","kyc"]`,
+		},
+		{
+			name:       "success - stats",
+			method:     http.MethodGet,
+			target:     "/stats",
+			admin:      true,
+			wantStatus: http.StatusOK,
+			wantBody:   `"age_validation"`,
+		},
+		{
+			name:       "success - reload",
+			method:     http.MethodPost,
+			target:     "/reload",
+			config:     strings.Replace(string(config), "  any_kyc:", "  any_check:", 1),
+			admin:      true,
+			wantStatus: http.StatusOK,
+			wantBody:   `"rulesets":["any_check","kyc"]`,
+		},
+		{
+			name:       "fail - reload invalid config keeps serving",
+			method:     http.MethodPost,
+			target:     "/reload",
+			config:     "rules: [",
+			admin:      true,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `"error"`,
+		},
+		{
+			name:       "success - previous engine served after failed reload",
+			method:     http.MethodPost,
+			target:     "/v1/rulesets/any_check",
+			body:       `{"user": {"age": 21}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"passed":true`,
+		},
+		{
+			name:       "fail - method not allowed",
+			method:     http.MethodGet,
+			target:     "/reload",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.config != "" {
+				if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.admin {
+				req.Header.Set("Authorization", "Bearer secret")
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_AdminWithoutToken(t *testing.T) {
+	s := &server{flags: engineFlags{config: "../../testdata/reason_rules.yml", variables: "user"}}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	handler := s.handler()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{name: "stats", method: http.MethodGet, target: "/stats"},
+		{name: "reload", method: http.MethodPost, target: "/reload"},
+		{name: "decisions", method: http.MethodGet, target: "/v1/subjects/alice/decisions"},
+		{name: "draft rule", method: http.MethodPut, target: "/v1/drafts/rules/senior", body: `{"expression": "user.age >= 65"}`},
+		{name: "delete rule", method: http.MethodDelete, target: "/v1/drafts/rules/age_validation"},
+		{name: "commit", method: http.MethodPost, target: "/v1/drafts/commit", body: `{"message": "add seniors"}`},
+		{name: "audit", method: http.MethodGet, target: "/v1/drafts/audit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer ")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, http.StatusForbidden, rec.Body.String())
+			}
+		})
+	}
+	if pending := s.drafts.Pending(); len(pending) != 0 {
+		t.Errorf("Pending() = %v, want no drafts", pending)
+	}
+	if got := s.engine.RuleNames(); !slices.Contains(got, "age_validation") {
+		t.Errorf("RuleNames() = %v, want age_validation still served", got)
+	}
+}
+
+func TestServer_HandleRule(t *testing.T) {
+	s := &server{
+		flags:      engineFlags{config: "../../testdata/confidential_rules.yml", variables: "user"},
+		adminToken: "secret",
+	}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	handler := s.handler()
+
+	tests := []struct {
+		name          string
+		target        string
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		{
+			name:       "success - public rule",
+			target:     "/rules/age_validation",
+			wantStatus: http.StatusOK,
+			wantBody:   `"expression":"user.age \u003e= globals.min_age","variables":["globals.min_age","user.age"]`,
+		},
+		{
+			name:       "success - confidential rule redacted",
+			target:     "/rules/fraud_screen",
+			wantStatus: http.StatusOK,
+			wantBody:   `"expression":"\u003cconfidential\u003e","variables":["user.email","user.risk_score"],"confidential":true`,
+		},
+		{
+			name:          "success - confidential rule redacted with wrong token",
+			target:        "/rules/fraud_screen",
+			authorization: "Bearer wrong",
+			wantStatus:    http.StatusOK,
+			wantBody:      `"expression":"\u003cconfidential\u003e"`,
+		},
+		{
+			name:          "success - confidential rule shown to admin",
+			target:        "/rules/fraud_screen",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusOK,
+			wantBody:      `"globals.fraud.blocked_emails"`,
+		},
+		{
+			name:       "fail - unknown rule",
+			target:     "/rules/unknown",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"error"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_HandleEvaluateProblem(t *testing.T) {
+	s := &server{flags: engineFlags{config: "../../testdata/problem_rules.yml", variables: "user"}}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	handler := s.handler()
+
+	tests := []struct {
+		name            string
+		target          string
+		body            string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "success - passed",
+			target:          "/v1/rulesets/onboarding",
+			body:            `{"user": {"verified": true, "sanctioned": false, "email": "a@example.com"}}`,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `"passed":true`,
+		},
+		{
+			name:            "fail - reason code",
+			target:          "/v1/rulesets/onboarding",
+			body:            `{"user": {"verified": false, "sanctioned": true, "email": "a@example.com"}}`,
+			wantStatus:      http.StatusForbidden,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"https://errors.example.com/kyc","title":"Identity verification required","status":403,"detail":"ruleset 'onboarding' did not pass evaluation","instance":"onboarding","reason_codes":["KYC_001"],"result":`,
+		},
+		{
+			name:            "fail - rule",
+			target:          "/v1/rules/sanctions_check",
+			body:            `{"user": {"sanctioned": true}}`,
+			wantStatus:      http.StatusUnavailableForLegalReasons,
+			wantContentType: "application/problem+json",
+			wantBody:        `"status":451`,
+		},
+		{
+			name:            "fail - default",
+			target:          "/v1/rulesets/contact",
+			body:            `{"user": {"email": "invalid"}}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"about:blank","title":"Bad Request","status":400`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %s, want %s", got, tt.wantContentType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_HandleDecisions(t *testing.T) {
+	s := &server{
+		flags:      engineFlags{config: "../../testdata/reason_rules.yml", variables: "user"},
+		adminToken: "secret",
+		decisions:  ruleengine.NewDecisionLog(2),
+	}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	handler := s.handler()
+	for _, body := range []string{
+		`{"user": {"age": 15, "verified": false}}`,
+		`{"user": {"age": 21, "verified": false}}`,
+		`{"user": {"age": 21, "verified": true}}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/rulesets/any_kyc?subject=u1", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d, body %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name          string
+		target        string
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		{
+			name:          "success - timeline",
+			target:        "/v1/subjects/u1/decisions",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusOK,
+			wantBody:      `"result":{"name":"any_kyc","passed":true,"rules":{"age_validation":{"name":"age_validation","passed":true},"identity_verified":{"name":"identity_verified","passed":false,"error":"rule 'identity_verified' did not pass evaluation","reason_code":"KYC_002"}}}}`,
+		},
+		{
+			name:          "success - last decision",
+			target:        "/v1/subjects/u1/decisions?n=1&format=dot",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusOK,
+			wantBody:      `d0_r1 [label="identity_verified\npassed", shape=ellipse, fillcolor=palegreen];`,
+		},
+		{
+			name:          "success - unknown subject",
+			target:        "/v1/subjects/u2/decisions",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusOK,
+			wantBody:      `[]`,
+		},
+		{
+			name:       "fail - admin token required",
+			target:     "/v1/subjects/u1/decisions",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "admin token required",
+		},
+		{
+			name:          "success - stats with admin token",
+			target:        "/stats",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusOK,
+			wantBody:      `"age_validation"`,
+		},
+		{
+			name:       "fail - stats admin token required",
+			target:     "/stats",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "admin token required",
+		},
+		{
+			name:          "fail - invalid n",
+			target:        "/v1/subjects/u1/decisions?n=0",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusBadRequest,
+			wantBody:      "invalid n",
+		},
+		{
+			name:          "fail - unknown format",
+			target:        "/v1/subjects/u1/decisions?format=svg",
+			authorization: "Bearer secret",
+			wantStatus:    http.StatusBadRequest,
+			wantBody:      "unknown format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestEvalStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: fmt.Errorf("ruleset 'x' %w", errNotFound), want: http.StatusNotFound},
+		{name: "context too large", err: &ruleengine.ContextLimitError{Limit: "max_depth"}, want: http.StatusRequestEntityTooLarge},
+		{name: "queue full", err: fmt.Errorf("%w: 1 running", ruleengine.ErrQueueFull), want: http.StatusServiceUnavailable},
+		{name: "evaluation error", err: errors.New("no such key: age"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evalStatus(tt.err); got != tt.want {
+				t.Errorf("evalStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Drafts(t *testing.T) {
+	s := &server{flags: engineFlags{config: "../../testdata/reason_rules.yml", variables: "user"}, adminToken: "secret"}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	handler := s.handler()
+
+	// Steps run in order, each building on the drafts of the previous ones
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		noToken    bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "fail - admin token required",
+			method:     http.MethodGet,
+			target:     "/v1/drafts",
+			noToken:    true,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "admin token required",
+		},
+		{
+			name:       "success - draft rule",
+			method:     http.MethodPut,
+			target:     "/v1/drafts/rules/senior",
+			body:       `{"expression": "user.age >= 65", "reason_code": "AGE_065"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"kind":"rule","name":"senior"}]`,
+		},
+		{
+			name:       "success - draft ruleset",
+			method:     http.MethodPut,
+			target:     "/v1/drafts/rulesets/seniors",
+			body:       "selector: AND\nrules: [senior]\n",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"kind":"ruleset","name":"seniors"}`,
+		},
+		{
+			name:       "fail - draft ruleset without rules",
+			method:     http.MethodPut,
+			target:     "/v1/drafts/rulesets/empty",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "has no rules",
+		},
+		{
+			name:       "success - validate",
+			method:     http.MethodPost,
+			target:     "/v1/drafts/validate",
+			wantStatus: http.StatusOK,
+			wantBody:   `"valid":true`,
+		},
+		{
+			name:       "success - test drafted rule",
+			method:     http.MethodPost,
+			target:     "/v1/drafts/rules/senior/test",
+			body:       `{"user": {"age": 30}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"passed":false,"error":"rule 'senior' did not pass evaluation","reason_code":"AGE_065"`,
+		},
+		{
+			name:       "fail - drafted ruleset not live",
+			method:     http.MethodPost,
+			target:     "/v1/rulesets/seniors",
+			body:       `{"user": {"age": 70}}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"error"`,
+		},
+		{
+			name:       "success - test drafted ruleset",
+			method:     http.MethodPost,
+			target:     "/v1/drafts/rulesets/seniors/test",
+			body:       `{"user": {"age": 70}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"seniors","passed":true`,
+		},
+		{
+			name:       "success - commit",
+			method:     http.MethodPost,
+			target:     "/v1/drafts/commit",
+			body:       `{"message": "add seniors"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"author":"alice","action":"commit","message":"add seniors","changes":[{"kind":"rule","name":"senior"},{"kind":"ruleset","name":"seniors"}]`,
+		},
+		{
+			name:       "success - committed ruleset live",
+			method:     http.MethodPost,
+			target:     "/v1/rulesets/seniors",
+			body:       `{"user": {"age": 70}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"seniors","passed":true`,
+		},
+		{
+			name:       "fail - commit without drafts",
+			method:     http.MethodPost,
+			target:     "/v1/drafts/commit",
+			wantStatus: http.StatusConflict,
+			wantBody:   "no pending drafts",
+		},
+		{
+			name:       "success - draft invalid rule",
+			method:     http.MethodPut,
+			target:     "/v1/drafts/rules/broken",
+			body:       `{"expression": "user.age >="}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"broken"`,
+		},
+		{
+			name:       "fail - validate invalid draft",
+			method:     http.MethodPost,
+			target:     "/v1/drafts/validate",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "invalid drafts",
+		},
+		{
+			name:       "success - discard",
+			method:     http.MethodDelete,
+			target:     "/v1/drafts",
+			wantStatus: http.StatusOK,
+			wantBody:   `[]`,
+		},
+		{
+			name:       "success - audit",
+			method:     http.MethodGet,
+			target:     "/v1/drafts/audit",
+			wantStatus: http.StatusOK,
+			wantBody:   `"action":"discard"`,
+		},
+		{
+			name:       "fail - reload admin token required",
+			method:     http.MethodPost,
+			target:     "/reload",
+			noToken:    true,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "admin token required",
+		},
+		{
+			name:       "success - reload",
+			method:     http.MethodPost,
+			target:     "/reload",
+			wantStatus: http.StatusOK,
+			wantBody:   `"rulesets":["any_kyc","kyc"]`,
+		},
+		{
+			name:       "success - audit kept across reload",
+			method:     http.MethodGet,
+			target:     "/v1/drafts/audit",
+			wantStatus: http.StatusOK,
+			wantBody:   `"author":"alice","action":"reload","message":"reloaded ../../testdata/reason_rules.yml, drafts discarded"`,
+		},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if !tt.noToken {
+				req.Header.Set("Authorization", "Bearer secret")
+			}
+			req.Header.Set("X-Author", "alice")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_DefaultConfig(t *testing.T) {
+	s := &server{flags: engineFlags{
+		config:    filepath.Join(t.TempDir(), "missing.yml"),
+		defaults:  "../../testdata/default_rules.yml",
+		variables: "user",
+	}}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"degraded"`) {
+		t.Errorf("GET /healthz = %d %s, want degraded", rec.Code, rec.Body.String())
+	}
+	if err := s.reload(""); err == nil {
+		t.Error("reload() error = nil, want the broken config reported")
+	}
+}
+
+func TestServer_HandleDecide(t *testing.T) {
+	s := &server{flags: engineFlags{config: "../../testdata/decision_rules.yml", variables: "user,request"}}
+	if err := s.reload(""); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	handler := s.handler()
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "success - review",
+			target:     "/v1/decisions/payment",
+			body:       `{"user": {"sanctioned": false}, "request": {"attempts": 10}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"decision":"payment","outcome":"REVIEW","when":"!rulesets.velocity"`,
+		},
+		{
+			name:       "fail - decision not found",
+			target:     "/v1/decisions/refund",
+			body:       `{}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// runSimulate replays a CSV or JSONL dataset of contexts through a config and an optional candidate config
+func runSimulate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	candidatePath := fs.String("candidate", "", "path to the proposed config, loaded with the same flags as -config")
+	dataPath := fs.String("data", "", "path to the dataset of contexts, one JSON object per line or a CSV with a header of context paths")
+	format := fs.String("format", "", "dataset format, jsonl or csv, detected from the -data extension when empty")
+	rulesets := fs.String("rulesets", "", "comma separated rulesets to simulate, every ruleset when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dataPath == "" {
+		return errors.New("-data is required")
+	}
+
+	sim := ruleengine.Simulator{Rulesets: splitList(*rulesets)}
+	var err error
+	if sim.Baseline, err = ef.build(); err != nil {
+		return err
+	}
+	if *candidatePath != "" {
+		candidate := ef
+		candidate.config, candidate.bundle, candidate.overlays = *candidatePath, "", ""
+		if sim.Candidate, err = candidate.build(); err != nil {
+			return fmt.Errorf("candidate: %w", err)
+		}
+	}
+
+	dataset, closer, err := openDataset(*dataPath, *format)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	report, err := sim.Run(dataset)
+	if err != nil {
+		return err
+	}
+	writeSimulation(stdout, report)
+	return nil
+}
+
+// writeSimulation prints the pass rates, decision flips and rule contributions of each ruleset
+func writeSimulation(w io.Writer, report ruleengine.SimulationReport) {
+	fmt.Fprintf(w, "records: %d\n", report.Records)
+	for _, name := range sortedKeys(report.Rulesets) {
+		sim := report.Rulesets[name]
+		fmt.Fprintf(w, "\n%s\n", name)
+		fmt.Fprintf(w, "  baseline:  %s\n", simulationStats(sim.Baseline))
+		if sim.Candidate == nil && sim.Baseline != nil {
+			writeContributions(w, sim.Baseline, nil)
+			continue
+		}
+		fmt.Fprintf(w, "  candidate: %s\n", simulationStats(sim.Candidate))
+		fmt.Fprintf(w, "  flips:     %d (pass to fail %d, fail to pass %d)", sim.Flips(), sim.PassToFail, sim.FailToPass)
+		if len(sim.FlipExamples) > 0 {
+			examples := make([]string, 0, len(sim.FlipExamples))
+			for _, record := range sim.FlipExamples {
+				examples = append(examples, fmt.Sprint(record))
+			}
+			fmt.Fprintf(w, " e.g. records %s", strings.Join(examples, ", "))
+		}
+		fmt.Fprintln(w)
+		writeContributions(w, sim.Baseline, sim.Candidate)
+	}
+}
+
+// simulationStats formats the outcome counts of a ruleset, a dash if the config does not have it
+func simulationStats(s *ruleengine.SimulationStats) string {
+	if s == nil {
+		return "-"
+	}
+	out := fmt.Sprintf("%.1f%% passed (%d passed, %d failed", s.PassRate()*100, s.Passed, s.Failed)
+	if s.Skipped > 0 {
+		out += fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+	if s.Degraded > 0 {
+		out += fmt.Sprintf(", %d degraded", s.Degraded)
+	}
+	return out + ")"
+}
+
+// writeContributions prints how often each rule failed and was the only failing rule, for both configs if set
+func writeContributions(w io.Writer, baseline, candidate *ruleengine.SimulationStats) {
+	rules := make(map[string]bool)
+	for _, s := range []*ruleengine.SimulationStats{baseline, candidate} {
+		if s == nil {
+			continue
+		}
+		for name := range s.Rules {
+			rules[name] = true
+		}
+	}
+	contribution := func(s *ruleengine.SimulationStats, name string) string {
+		if s == nil {
+			return "-"
+		}
+		c := s.Rules[name]
+		out := fmt.Sprintf("failed %d, decisive %d", c.Failed, c.Decisive)
+		if c.Errors > 0 {
+			out += fmt.Sprintf(", errors %d", c.Errors)
+		}
+		return out
+	}
+	for _, name := range sortedKeys(rules) {
+		if candidate == nil {
+			fmt.Fprintf(w, "  rule %s: %s\n", name, contribution(baseline, name))
+			continue
+		}
+		fmt.Fprintf(w, "  rule %s: %s -> %s\n", name, contribution(baseline, name), contribution(candidate, name))
+	}
+}
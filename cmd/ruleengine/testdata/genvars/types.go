@@ -0,0 +1,39 @@
+package types
+
+import "time"
+
+// Base holds fields shared by every request
+type Base struct {
+	ID string `json:"id"`
+}
+
+// Address is a postal address
+type Address struct {
+	Country string `cel:"country"`
+	City    string
+}
+
+// User is the user variable of the rules
+//
+//ruleengine:variable user
+type User struct {
+	Age       int               `cel:"age"`
+	Email     string            `json:"email,omitempty"`
+	Status    *string           `json:"status"`
+	Address   *Address          `json:"address"`
+	Previous  []Address         `json:"previous_addresses"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"created_at"`
+	Password  string            `json:"-"`
+	internal  string
+}
+
+// Request is the request variable of the rules
+//
+//ruleengine:variable request
+type Request struct {
+	Base
+	Attempt int           `json:"attempt"`
+	Timeout time.Duration `json:"timeout"`
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// runValidate loads and compiles a config, reporting any errors and the issues of the lint checks it configures
+func runValidate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	var ef engineFlags
+	ef.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := ef.build()
+	if err != nil {
+		return err
+	}
+	if err := engine.Validate(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s: ok\n", ef.source())
+	return nil
+}
@@ -0,0 +1,88 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Combine merges the results of several rulesets, e.g. from a tenant engine and a global engine, into one decision
+//
+//	selector is "AND", the default when empty, or "OR" and applies to the results like it applies to the rules of a
+//	ruleset: skipped results are ignored by AND, and the combined result is skipped when every result is. Rule
+//	results are keyed `<ruleset>/<rule>` so rules of the same name from different engines are kept apart. The
+//	combined RulesetName joins the names with "+", Degraded is set if any result is, reason codes and errors are
+//	gathered from the results which did not pass and Duration is the total
+func Combine(selector string, results ...RulesetResult) (RulesetResult, error) {
+	switch selectorType(strings.ToUpper(selector)) {
+	case "", selectorAnd:
+		selector = string(selectorAnd)
+	case selectorOr:
+		selector = string(selectorOr)
+	default:
+		return RulesetResult{}, fmt.Errorf("unknown selector '%s', use AND or OR", selector)
+	}
+	if len(results) == 0 {
+		return RulesetResult{}, errors.New("no results to combine")
+	}
+
+	names := make([]string, 0, len(results))
+	combined := RulesetResult{
+		RuleResults: make(map[string]RuleResult),
+		Order:       make([]string, 0),
+		Skipped:     true,
+		Passed:      selector == string(selectorAnd),
+	}
+	var errs []error
+	codes := make(map[string]bool)
+	for _, result := range results {
+		names = append(names, result.RulesetName)
+		for _, rule := range result.Ordered() {
+			key := result.RulesetName + "/" + rule.RuleName
+			combined.RuleResults[key] = rule
+			combined.Order = append(combined.Order, key)
+		}
+		for key, value := range result.Metadata {
+			if combined.Metadata == nil {
+				combined.Metadata = make(map[string]interface{})
+			}
+			combined.Metadata[key] = value
+		}
+		combined.Duration += result.Duration
+		combined.Degraded = combined.Degraded || result.Degraded
+		if result.Skipped {
+			continue
+		}
+		combined.Skipped = false
+		if selector == string(selectorAnd) {
+			combined.Passed = combined.Passed && result.Passed
+		} else {
+			combined.Passed = combined.Passed || result.Passed
+		}
+		if !result.Passed {
+			if result.Error != nil {
+				errs = append(errs, result.Error)
+			}
+			for _, code := range result.ReasonCodes {
+				codes[code] = true
+			}
+		}
+	}
+	combined.RulesetName = strings.Join(names, "+")
+	if combined.Skipped {
+		combined.Passed = false
+		return combined, nil
+	}
+	if !combined.Passed {
+		combined.Error = errors.Join(errs...)
+		if combined.Error == nil {
+			combined.Error = fmt.Errorf("combined result '%s' did not pass", combined.RulesetName)
+		}
+		for code := range codes {
+			combined.ReasonCodes = append(combined.ReasonCodes, code)
+		}
+		sort.Strings(combined.ReasonCodes)
+	}
+	return combined, nil
+}
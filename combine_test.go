@@ -0,0 +1,129 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCombine(t *testing.T) {
+	tenant := RulesetResult{
+		RulesetName: "tenant",
+		Passed:      true,
+		RuleResults: map[string]RuleResult{"age": {RuleName: "age", Passed: true}},
+		Order:       []string{"age"},
+		Duration:    time.Millisecond,
+		Metadata:    map[string]interface{}{"tenant": "acme"},
+	}
+	global := RulesetResult{
+		RulesetName: "global",
+		Passed:      false,
+		Error:       errors.New("ruleset 'global' did not pass evaluation"),
+		RuleResults: map[string]RuleResult{
+			"age":     {RuleName: "age", Passed: true},
+			"blocked": {RuleName: "blocked", Passed: false, ReasonCode: "BLK_001"},
+		},
+		Order:       []string{"blocked", "age"},
+		ReasonCodes: []string{"BLK_001"},
+		Duration:    2 * time.Millisecond,
+	}
+	skipped := RulesetResult{RulesetName: "skipped", Skipped: true, RuleResults: map[string]RuleResult{}}
+
+	tests := []struct {
+		name     string
+		selector string
+		results  []RulesetResult
+		want     RulesetResult
+		wantErr  bool
+	}{
+		{
+			name:     "success - and",
+			selector: "AND",
+			results:  []RulesetResult{tenant, global},
+			want: RulesetResult{
+				RulesetName: "tenant+global",
+				Passed:      false,
+				Error:       errors.New("ruleset 'global' did not pass evaluation"),
+				RuleResults: map[string]RuleResult{
+					"tenant/age":     {RuleName: "age", Passed: true},
+					"global/blocked": {RuleName: "blocked", Passed: false, ReasonCode: "BLK_001"},
+					"global/age":     {RuleName: "age", Passed: true},
+				},
+				Order:       []string{"tenant/age", "global/blocked", "global/age"},
+				ReasonCodes: []string{"BLK_001"},
+				Duration:    3 * time.Millisecond,
+				Metadata:    map[string]interface{}{"tenant": "acme"},
+			},
+		},
+		{
+			name:     "success - or",
+			selector: "or",
+			results:  []RulesetResult{tenant, global},
+			want: RulesetResult{
+				RulesetName: "tenant+global",
+				Passed:      true,
+				RuleResults: map[string]RuleResult{
+					"tenant/age":     {RuleName: "age", Passed: true},
+					"global/blocked": {RuleName: "blocked", Passed: false, ReasonCode: "BLK_001"},
+					"global/age":     {RuleName: "age", Passed: true},
+				},
+				Order:    []string{"tenant/age", "global/blocked", "global/age"},
+				Duration: 3 * time.Millisecond,
+				Metadata: map[string]interface{}{"tenant": "acme"},
+			},
+		},
+		{
+			name:    "success - default and ignores skipped",
+			results: []RulesetResult{tenant, skipped},
+			want: RulesetResult{
+				RulesetName: "tenant+skipped",
+				Passed:      true,
+				RuleResults: map[string]RuleResult{"tenant/age": {RuleName: "age", Passed: true}},
+				Order:       []string{"tenant/age"},
+				Duration:    time.Millisecond,
+				Metadata:    map[string]interface{}{"tenant": "acme"},
+			},
+		},
+		{
+			name:    "success - all skipped",
+			results: []RulesetResult{skipped},
+			want: RulesetResult{
+				RulesetName: "skipped",
+				Skipped:     true,
+				RuleResults: map[string]RuleResult{},
+				Order:       []string{},
+			},
+		},
+		{
+			name:     "fail - unknown selector",
+			selector: "XOR",
+			results:  []RulesetResult{tenant},
+			wantErr:  true,
+		},
+		{
+			name:    "fail - no results",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Combine(tt.selector, tt.results...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Combine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			diff := cmp.Diff(tt.want, got,
+				cmp.Comparer(func(x, y error) bool {
+					return (x == nil && y == nil) || (x != nil && y != nil && x.Error() == y.Error())
+				}),
+			)
+			if diff != "" {
+				t.Errorf("Combine() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package ruleengine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+)
+
+// compileCacheVersion is part of every cache key, bump it when the cached format changes
+const compileCacheVersion = "v1"
+
+// WithCompileCache stores the checked ASTs of compiled expressions in dir, so later starts skip parsing and checking
+//
+//	Entries are keyed by a hash of the expression, the CEL env declarations and the config functions, a change to
+//	any of these misses the cache. The cache is best effort, unreadable entries are recompiled and write failures
+//	are ignored. The directory is created if it does not exist
+func WithCompileCache(dir string) Option {
+	return func(re *RuleEngine) {
+		re.compileCache = &compileCache{dir: dir}
+	}
+}
+
+// compileCache is a directory of serialized checked ASTs
+type compileCache struct {
+	dir string
+	// envHash identifies the env expressions are checked against, set once the env is complete
+	envHash string
+	// hits and misses count cache lookups
+	hits, misses atomic.Uint64
+}
+
+// load returns the cached checked AST of expression, if any
+func (c *compileCache) load(expression string) (*cel.Ast, bool) {
+	data, err := os.ReadFile(c.path(expression))
+	if err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	checked := &exprpb.CheckedExpr{}
+	if err := proto.Unmarshal(data, checked); err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	ast, err := cel.CheckedExprToAstWithSource(checked, nil)
+	if err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return ast, true
+}
+
+// store writes the checked AST of expression to the cache, replacing the entry atomically
+func (c *compileCache) store(expression string, ast *cel.Ast) error {
+	checked, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return fmt.Errorf("failed to convert checked expression: %w", err)
+	}
+	data, err := proto.Marshal(checked)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checked expression: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create compile cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create compile cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write compile cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write compile cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(expression)); err != nil {
+		return fmt.Errorf("failed to write compile cache entry: %w", err)
+	}
+	return nil
+}
+
+// path returns the file caching expression
+func (c *compileCache) path(expression string) string {
+	sum := sha256.Sum256([]byte(c.envHash + "\x00" + expression))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".pb")
+}
+
+// envHash hashes everything an expression is checked against: variables, function overloads, macros and the
+// config functions expanded by them
+func (re *RuleEngine) envHash() string {
+	lines := []string{compileCacheVersion}
+	for _, v := range re.env.Variables() {
+		lines = append(lines, "var "+v.Name()+" "+v.Type().String())
+	}
+	for name, fn := range re.env.Functions() {
+		for _, overload := range fn.OverloadDecls() {
+			args := make([]string, 0, len(overload.ArgTypes()))
+			for _, arg := range overload.ArgTypes() {
+				args = append(args, arg.String())
+			}
+			lines = append(lines, fmt.Sprintf("func %s %s(%s) %s",
+				name, overload.ID(), strings.Join(args, ","), overload.ResultType()))
+		}
+	}
+	for _, macro := range re.env.Macros() {
+		lines = append(lines, "macro "+macro.MacroKey())
+	}
+	for name, expression := range re.config.Functions {
+		lines = append(lines, "config "+name+" "+expression)
+	}
+	if re.sandbox != nil {
+		lines = append(lines, fmt.Sprintf("sandbox %+v", *re.sandbox))
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
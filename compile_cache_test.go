@@ -0,0 +1,99 @@
+package ruleengine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithCompileCache(t *testing.T) {
+	build := func(t *testing.T, dir string, variables ...string) *RuleEngine {
+		t.Helper()
+		re, err := NewBuilder().
+			WithConfigFile("./testdata/explanation_rules.yml").
+			WithVariables(variables...).
+			WithOptions(WithCompileCache(dir)).
+			Build()
+		if err != nil {
+			t.Fatalf("failed to create rules engine: %v", err)
+		}
+		return re
+	}
+	entries := func(t *testing.T, dir string) []string {
+		t.Helper()
+		files, err := filepath.Glob(filepath.Join(dir, "*.pb"))
+		if err != nil {
+			t.Fatalf("failed to list cache entries: %v", err)
+		}
+		return files
+	}
+
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, dir string)
+		variables  []string
+		wantHits   uint64
+		wantMisses uint64
+	}{
+		{
+			name:       "success - cold cache",
+			variables:  []string{"user"},
+			wantMisses: 4,
+		},
+		{
+			name: "success - warm cache",
+			setup: func(t *testing.T, dir string) {
+				build(t, dir, "user")
+			},
+			variables: []string{"user"},
+			wantHits:  4,
+		},
+		{
+			name: "success - env changed",
+			setup: func(t *testing.T, dir string) {
+				build(t, dir, "user", "request")
+			},
+			variables:  []string{"user"},
+			wantMisses: 4,
+		},
+		{
+			name: "success - corrupt entries recompiled",
+			setup: func(t *testing.T, dir string) {
+				build(t, dir, "user")
+				for _, file := range entries(t, dir) {
+					if err := os.WriteFile(file, []byte("corrupt"), 0o644); err != nil {
+						t.Fatalf("failed to corrupt cache entry: %v", err)
+					}
+				}
+			},
+			variables:  []string{"user"},
+			wantMisses: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "cache")
+			if tt.setup != nil {
+				tt.setup(t, dir)
+			}
+
+			re := build(t, dir, tt.variables...)
+			if hits := re.compileCache.hits.Load(); hits != tt.wantHits {
+				t.Errorf("WithCompileCache() hits = %d, want %d", hits, tt.wantHits)
+			}
+			if misses := re.compileCache.misses.Load(); misses != tt.wantMisses {
+				t.Errorf("WithCompileCache() misses = %d, want %d", misses, tt.wantMisses)
+			}
+
+			got, err := re.EvaluateRule("age_validation", WithEvalContext(map[string]interface{}{
+				"user": map[string]interface{}{"age": 21},
+			}))
+			if err != nil || !got.Passed {
+				t.Errorf("EvaluateRule() = %+v, %v, want passed", got, err)
+			}
+			if len(entries(t, dir)) < 4 {
+				t.Errorf("WithCompileCache() entries = %d, want at least 4", len(entries(t, dir)))
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ruleengine
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// computedVariable is the context variable holding the computed fields of the config
+const computedVariable = "computed"
+
+// declareComputed declares the `computed` variable rules read the computed fields of the config from, if any
+func (re *RuleEngine) declareComputed() error {
+	if len(re.config.Computed) == 0 {
+		return nil
+	}
+	env, err := re.env.Extend(cel.Variable(computedVariable, cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return fmt.Errorf("failed to declare computed fields: %w", err)
+	}
+	re.env = env
+	return nil
+}
+
+// compileComputed compiles the computed fields of the config
+func (re *RuleEngine) compileComputed() error {
+	if len(re.config.Computed) == 0 {
+		return nil
+	}
+	re.computed = make(map[string]cel.Program, len(re.config.Computed))
+	for _, name := range sortedNames(re.config.Computed) {
+		program, err := re.compileExpression(re.config.Computed[name])
+		if err != nil {
+			return fmt.Errorf("computed field '%s': %w", name, err)
+		}
+		re.computed[name] = program
+	}
+	return nil
+}
+
+// withComputed evaluates the computed fields against ctx and adds them to it as the `computed` variable
+//
+//	Fields failing to evaluate, e.g. reading a missing context field, are left out, so only the rules reading them fail.
+//	Computed fields are evaluated before the variable is set, so they cannot read each other
+func (re *RuleEngine) withComputed(ctx map[string]interface{}) map[string]interface{} {
+	if len(re.computed) == 0 {
+		return ctx
+	}
+	computed := make(map[string]interface{}, len(re.computed))
+	for name, program := range re.computed {
+		out, _, err := program.Eval(ctx)
+		if err != nil {
+			continue
+		}
+		computed[name] = out
+	}
+	ctx[computedVariable] = computed
+	return ctx
+}
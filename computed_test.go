@@ -0,0 +1,78 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/cel-go/ext"
+)
+
+func TestRuleEngine_Computed(t *testing.T) {
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/computed_rules.yml").
+		WithVariables("user").
+		WithFunctions(ext.Strings()).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		user        map[string]interface{}
+		setContext  bool
+		wantPassed  bool
+		wantErrored []string
+	}{
+		{
+			name:       "success - computed fields pass",
+			user:       map[string]interface{}{"email": "ada@example.com", "age": 36},
+			wantPassed: true,
+		},
+		{
+			name:       "success - computed from SetContext",
+			user:       map[string]interface{}{"email": "ada@example.com", "age": 36},
+			setContext: true,
+			wantPassed: true,
+		},
+		{
+			name: "fail - computed field does not pass",
+			user: map[string]interface{}{"email": "ada@example.org", "age": 36},
+		},
+		{
+			name:        "fail - computed field fails to evaluate",
+			user:        map[string]interface{}{"age": 36},
+			wantErrored: []string{"company_email"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := map[string]interface{}{"user": tt.user}
+			var opts []EvalOption
+			if tt.setContext {
+				re.SetContext(ctx)
+			} else {
+				opts = append(opts, WithEvalContext(ctx))
+			}
+			got, err := re.EvaluateRuleset("staff", opts...)
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", got.Passed, tt.wantPassed)
+			}
+			errored := make([]string, 0)
+			for _, result := range got.Ordered() {
+				var evalErr *EvaluationError
+				if errors.As(result.Error, &evalErr) {
+					errored = append(errored, result.RuleName)
+				}
+			}
+			if len(errored) != len(tt.wantErrored) || (len(errored) > 0 && errored[0] != tt.wantErrored[0]) {
+				t.Errorf("EvaluateRuleset() errored rules = %v, want %v", errored, tt.wantErrored)
+			}
+			if _, ok := ctx["computed"]; ok && !tt.setContext {
+				t.Error("EvaluateRuleset() modified the caller's context")
+			}
+		})
+	}
+}
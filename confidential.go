@@ -0,0 +1,141 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/parser"
+)
+
+// RedactedValue replaces the expressions of confidential rules, and the globals they read, in introspection output
+const RedactedValue = "<confidential>"
+
+// Confidential reports whether the rule is marked `confidential: true`
+//
+//	Confidential rules are evaluated as usual, surfaces exposing rules to other viewers, e.g. docs or admin
+//	endpoints, should show them redacted with RulesetConfig.Redact or RuleExplanation.Redact
+func (re *RuleEngine) Confidential(ruleName string) bool {
+	if active := re.Active(); active != re {
+		return active.Confidential(ruleName)
+	}
+	return re.config.Rules[ruleName].Confidential
+}
+
+// Redact returns the explanation without the expression, AST and globals of the rule
+func (e RuleExplanation) Redact() RuleExplanation {
+	e.Expression = RedactedValue
+	e.AST = ""
+	variables := make([]string, 0, len(e.Variables))
+	for _, v := range e.Variables {
+		if !strings.HasPrefix(v, "globals.") {
+			variables = append(variables, v)
+		}
+	}
+	e.Variables = variables
+	return e
+}
+
+// Redact returns a copy of the config safe to show viewers not allowed to see confidential rules
+//
+//	The expressions and when clauses of confidential rules, the config functions they call and the globals any of
+//	them read, including environment and profile overrides, are replaced by RedactedValue. Names, descriptions and
+//	owners are kept
+func (rc *RulesetConfig) Redact() (*RulesetConfig, error) {
+	redacted := *rc
+	redacted.Rules = make(map[string]Rule, len(rc.Rules))
+	p, err := parser.NewParser(parser.Macros(parser.AllMacros...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser: %w", err)
+	}
+
+	globals := make(map[string]bool)
+	functions := make(map[string]bool)
+	var read func(expression string) error
+	read = func(expression string) error {
+		if expression == "" {
+			return nil
+		}
+		parsed, issues := p.Parse(common.NewTextSource(expression))
+		if len(issues.GetErrors()) > 0 {
+			return fmt.Errorf("failed to parse expression '%s': %s", expression, issues.ToDisplayString())
+		}
+		expr := parsed.Expr()
+		for _, nav := range ast.MatchDescendants(ast.NavigateExpr(nil, expr), ast.KindMatcher(ast.SelectKind)) {
+			if parent, ok := nav.Parent(); ok && parent.Kind() == ast.SelectKind && !parent.AsSelect().IsTestOnly() {
+				// Only the longest path is redacted, e.g. `globals.limits.max` but not the rest of `globals.limits`
+				continue
+			}
+			if path, ok := selectPath(nav); ok && strings.HasPrefix(path, "globals.") {
+				globals[path] = true
+			}
+		}
+		for _, name := range calledFunctions(expr, rc.Functions) {
+			if !functions[name] {
+				functions[name] = true
+				if err := read(rc.Functions[name]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for name, rule := range rc.Rules {
+		if rule.Confidential {
+			if err := read(rule.Expression); err != nil {
+				return nil, fmt.Errorf("rule '%s': %w", name, err)
+			}
+			if err := read(rule.When); err != nil {
+				return nil, fmt.Errorf("rule '%s': %w", name, err)
+			}
+			rule.Expression = RedactedValue
+			if rule.When != "" {
+				rule.When = RedactedValue
+			}
+		}
+		redacted.Rules[name] = rule
+	}
+	if len(functions) > 0 {
+		redacted.Functions = make(map[string]string, len(rc.Functions))
+		for name, body := range rc.Functions {
+			if functions[name] {
+				body = RedactedValue
+			}
+			redacted.Functions[name] = body
+		}
+	}
+	if len(globals) > 0 {
+		redacted.Globals = redactGlobals(rc.Globals, "globals", globals)
+		redacted.Environments = make(map[string]Environment, len(rc.Environments))
+		for name, env := range rc.Environments {
+			env.Globals = redactGlobals(env.Globals, "globals", globals)
+			redacted.Environments[name] = env
+		}
+		redacted.Profiles = make(map[string]Profile, len(rc.Profiles))
+		for name, profile := range rc.Profiles {
+			profile.Globals = redactGlobals(profile.Globals, "globals", globals)
+			redacted.Profiles[name] = profile
+		}
+	}
+	return &redacted, nil
+}
+
+// redactGlobals returns a copy of m with the values at the given `globals.` paths replaced by RedactedValue
+func redactGlobals(m map[string]interface{}, prefix string, paths map[string]bool) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		path := prefix + "." + key
+		if paths[path] {
+			value = RedactedValue
+		} else if v, ok := value.(map[string]interface{}); ok {
+			value = redactGlobals(v, path, paths)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
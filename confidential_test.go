@@ -0,0 +1,90 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRulesetConfig_Redact(t *testing.T) {
+	config, err := NewRulesetConfig("./testdata/confidential_rules.yml")
+	if err != nil {
+		t.Fatalf("NewRulesetConfig() error = %v", err)
+	}
+	redacted, err := config.Redact()
+	if err != nil {
+		t.Fatalf("Redact() error = %v", err)
+	}
+
+	fraud := redacted.Rules["fraud_screen"]
+	if fraud.Expression != RedactedValue || fraud.When != RedactedValue {
+		t.Errorf("Redact() fraud_screen = %q when %q, want both redacted", fraud.Expression, fraud.When)
+	}
+	if fraud.Owner != "fraud-team" || fraud.Name != "Fraud Screen" {
+		t.Errorf("Redact() fraud_screen lost its name or owner: %+v", fraud)
+	}
+	if got := redacted.Rules["age_validation"].Expression; got != "user.age >= globals.min_age" {
+		t.Errorf("Redact() age_validation = %q, want it unchanged", got)
+	}
+	if diff := cmp.Diff(map[string]string{"is_high_risk": RedactedValue}, redacted.Functions); diff != "" {
+		t.Errorf("Redact() functions mismatch (-want +got):\n%s", diff)
+	}
+	wantGlobals := map[string]interface{}{
+		"min_age":      18,
+		"home_country": RedactedValue,
+		"fraud": map[string]interface{}{
+			"max_risk":       RedactedValue,
+			"blocked_emails": RedactedValue,
+			"review_queue":   "fraud-review",
+		},
+	}
+	if diff := cmp.Diff(wantGlobals, redacted.Globals); diff != "" {
+		t.Errorf("Redact() globals mismatch (-want +got):\n%s", diff)
+	}
+	wantProduction := map[string]interface{}{"fraud": map[string]interface{}{"max_risk": RedactedValue}}
+	if diff := cmp.Diff(wantProduction, redacted.Environments["production"].Globals); diff != "" {
+		t.Errorf("Redact() production globals mismatch (-want +got):\n%s", diff)
+	}
+
+	// The original config is left untouched
+	if config.Rules["fraud_screen"].Expression == RedactedValue || config.Globals["home_country"] != "AU" {
+		t.Errorf("Redact() modified the original config")
+	}
+}
+
+func TestRuleEngine_Confidential(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/confidential_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	if !re.Confidential("fraud_screen") || re.Confidential("age_validation") || re.Confidential("missing") {
+		t.Errorf("Confidential() did not report fraud_screen only")
+	}
+
+	// Confidential rules are evaluated as usual
+	got, err := re.EvaluateRule("fraud_screen", WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"country": "NZ", "risk_score": 10, "email": "fraud@example.com"},
+	}))
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	if got.Passed {
+		t.Errorf("EvaluateRule() passed = true, want the blocked email to fail")
+	}
+
+	explanation, err := re.Explain("fraud_screen")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	redacted := explanation.Redact()
+	want := RuleExplanation{
+		RuleName:   "fraud_screen",
+		Expression: RedactedValue,
+		Variables:  []string{"user.email", "user.risk_score"},
+		Parents:    []string{},
+		Cost:       explanation.Cost,
+	}
+	if diff := cmp.Diff(want, redacted); diff != "" {
+		t.Errorf("Redact() mismatch (-want +got):\n%s", diff)
+	}
+}
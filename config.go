@@ -1,8 +1,10 @@
 package ruleengine
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -10,23 +12,65 @@ import (
 
 // RulesetConfig is the top-level configuration structure
 type RulesetConfig struct {
-	APIVersion        string                     `yaml:"apiVersion"`
-	Kind              string                     `yaml:"kind"`
-	Metadata          Metadata                   `yaml:"metadata"`
-	Globals           map[string]interface{}     `yaml:"globals"`
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   Metadata               `yaml:"metadata"`
+	Globals    map[string]interface{} `yaml:"globals"`
+	// GlobalsMerge configures how environment and profile globals are merged onto Globals, see GlobalsMerge
+	GlobalsMerge      GlobalsMerge               `yaml:"globals_merge"`
+	Functions         map[string]string          `yaml:"functions"`
 	Rules             map[string]Rule            `yaml:"rules"`
 	Rulesets          map[string]Ruleset         `yaml:"rulesets"`
 	ExecutionPolicies map[string]ExecutionPolicy `yaml:"execution_policies"`
 	ErrorHandling     ErrorHandling              `yaml:"error_handling"`
 	Environments      map[string]Environment     `yaml:"environments"`
+	// StrictEnvironments fails building an engine for an unknown environment or with environment overrides
+	// referencing rules, rulesets or execution policies not in the config, see ValidateEnvironments
+	StrictEnvironments bool `yaml:"strict_environments"`
+	// StrictReferences fails building an engine when a name the config references does not resolve, e.g. a
+	// misspelt custom error message, see ValidateReferences
+	StrictReferences bool               `yaml:"strict_references"`
+	Profiles         map[string]Profile `yaml:"profiles"`
+	Types            *Types             `yaml:"types"`
+	// Lint configures the expression checks of RuleEngine.Validate, see LintRules
+	Lint *LintRules `yaml:"lint"`
+	// Decision combines the ruleset outcomes into a single verdict, see RuleEngine.EvaluateDecision
+	Decision string `yaml:"decision"`
+	// Decisions map ruleset outcomes to business outcomes, e.g. APPROVE, keyed by decision name, see RuleEngine.Decide
+	Decisions map[string]DecisionConfig `yaml:"decisions"`
+	// Computed maps field names to expressions evaluated once per evaluation, rules read them as `computed.<name>`
+	Computed map[string]string `yaml:"computed"`
+	// SandboxProfiles restrict the CEL features expressions may use, keyed by profile name, see SandboxProfile
+	SandboxProfiles map[string]SandboxProfile `yaml:"sandbox_profiles"`
+	// Sandbox is the name of the sandbox profile enforced on every expression, none when empty, see WithSandbox
+	Sandbox string `yaml:"sandbox"`
 }
 
 // Rule represents an individual rule with its properties
 type Rule struct {
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
-	Expression  string `yaml:"expression"`
-	Extends     string `yaml:"extends"`
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description"`
+	Expression  string       `yaml:"expression"`
+	Extends     string       `yaml:"extends"`
+	Owner       string       `yaml:"owner"`
+	Retry       *RetryPolicy `yaml:"retry"`
+	Priority    int          `yaml:"priority"`
+	// When is an optional expression guarding the rule, the rule is skipped unless it is true
+	When string `yaml:"when"`
+	// Severity classifies the rule, e.g. "warning" or "critical", and selects its error_handling.http_problems mapping
+	Severity string `yaml:"severity"`
+	// ErrorMessage is reported when the rule does not pass, overriding error_handling.custom_error_messages
+	ErrorMessage string `yaml:"error_message"`
+	// ReasonCode is a stable machine-readable code reported when the rule does not pass, e.g. "KYC_001"
+	ReasonCode string `yaml:"reason_code"`
+	// Confidential hides the expression and the globals it reads from introspection surfaces, see RulesetConfig.Redact
+	Confidential bool `yaml:"confidential"`
+	// ActiveFrom is an optional RFC3339 time before which the rule is skipped
+	ActiveFrom string `yaml:"active_from"`
+	// ActiveUntil is an optional RFC3339 time from which the rule is skipped
+	ActiveUntil string `yaml:"active_until"`
+	// Schedule is an optional cron-style window, e.g. "* 9-16 * * 1-5", the rule is skipped in minutes not matching it
+	Schedule string `yaml:"schedule"`
 }
 
 // Ruleset represents a collection of rules and their evaluation logic
@@ -35,6 +79,85 @@ type Ruleset struct {
 	Description string       `yaml:"description"`
 	Selector    selectorType `yaml:"selector"`
 	Rules       []string     `yaml:"rules"`
+	Fallback    string       `yaml:"fallback"`
+	Owner       string       `yaml:"owner"`
+	// ErrorMessage is reported when the ruleset does not pass, overriding error_handling.custom_error_messages
+	ErrorMessage string `yaml:"error_message"`
+	// Precondition is an optional expression evaluated before the rules, the ruleset is skipped unless it is true
+	Precondition string `yaml:"precondition"`
+	// ActiveFrom, ActiveUntil and Schedule skip the ruleset outside its time window, see Rule
+	ActiveFrom  string `yaml:"active_from"`
+	ActiveUntil string `yaml:"active_until"`
+	Schedule    string `yaml:"schedule"`
+	// Vetoes are rules of Rules which fail the ruleset when they do not pass, even if another rule passes an OR
+	// ruleset, passing a veto rule does not pass an OR ruleset. They are set with `veto: true` on a rule reference,
+	// other names are ignored
+	Vetoes []string `yaml:"vetoes"`
+	// Optional are rules of Rules whose failure an AND ruleset tolerates, reporting them in RulesetResult.Warnings.
+	// They are set with `optional: true` on a rule reference, other names are ignored
+	Optional []string `yaml:"optional"`
+}
+
+// ruleReference is an entry of the rules of a ruleset, a rule name or a mapping flagging the rule as a veto or optional
+//
+//	rules:
+//	  - allowlisted
+//	  - rule: sanctions_check
+//	    veto: true
+//	  - rule: phone_verified
+//	    optional: true
+type ruleReference struct {
+	Rule     string `yaml:"rule"`
+	Veto     bool   `yaml:"veto"`
+	Optional bool   `yaml:"optional"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, accepting rule names and veto or optional references in the rules of a
+// ruleset
+func (rs *Ruleset) UnmarshalYAML(node *yaml.Node) error {
+	type plain Ruleset
+	// Decode the rules separately, the remaining keys decode as usual
+	fields := *node
+	fields.Content = make([]*yaml.Node, 0, len(node.Content))
+	var refs []ruleReference
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "rules" {
+			fields.Content = append(fields.Content, node.Content[i], node.Content[i+1])
+			continue
+		}
+		if err := node.Content[i+1].Decode(&refs); err != nil {
+			return err
+		}
+	}
+	if err := fields.Decode((*plain)(rs)); err != nil {
+		return err
+	}
+	if refs == nil {
+		return nil
+	}
+	rs.Rules = make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Rule == "" {
+			return errors.New("ruleset rule reference has no rule name")
+		}
+		rs.Rules = append(rs.Rules, ref.Rule)
+		if ref.Veto && !slices.Contains(rs.Vetoes, ref.Rule) {
+			rs.Vetoes = append(rs.Vetoes, ref.Rule)
+		}
+		if ref.Optional && !slices.Contains(rs.Optional, ref.Rule) {
+			rs.Optional = append(rs.Optional, ref.Rule)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, accepting a rule name or a mapping
+func (r *ruleReference) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&r.Rule)
+	}
+	type plain ruleReference
+	return node.Decode((*plain)(r))
 }
 
 type selectorType string
@@ -50,6 +173,7 @@ type ExecutionPolicy struct {
 	Name             string `yaml:"name"`
 	Description      string `yaml:"description"`
 	StopOnFailure    bool   `yaml:"stop_on_failure"`
+	StopOnFirstPass  bool   `yaml:"stop_on_first_pass"`
 	MaxExecutionTime string `yaml:"max_execution_time"`
 }
 
@@ -57,40 +181,147 @@ type ExecutionPolicy struct {
 type ErrorHandling struct {
 	ExecutionPolicy     string            `yaml:"execution_policy"`
 	CustomErrorMessages map[string]string `yaml:"custom_error_messages"`
+	// HTTPProblems maps results which did not pass to HTTP statuses and problem+json bodies, see RuleEngine.RulesetProblem
+	HTTPProblems *HTTPProblems `yaml:"http_problems"`
 }
 
 // Environment defines settings for different execution environments
 type Environment struct {
 	Globals       map[string]interface{} `yaml:"globals"`
 	ErrorHandling ErrorHandling          `yaml:"error_handling"`
+	// EnabledRulesets restricts the rulesets to the listed ones in this environment, all are enabled when empty
+	EnabledRulesets []string `yaml:"enabled_rulesets"`
+	// DisabledRules removes the listed rules, and the rules extending them, from the rules and rulesets
+	DisabledRules []string `yaml:"disabled_rules"`
 }
 
 // NewRulesetConfig reads and parses the YAML configuration file
 // and returns a RulesetConfig instance
 func NewRulesetConfig(configPath string) (*RulesetConfig, error) {
+	config, _, err := loadRulesetConfig(configPath)
+	return config, err
+}
+
+// SourcePosition is a location within a YAML configuration file
+type SourcePosition struct {
+	// File is the path of the configuration file
+	File string
+	// Line is the 1-based line within the file
+	Line int
+	// Column is the 1-based column within the file
+	Column int
+}
+
+// String returns the position in `file:line:column` form
+func (p SourcePosition) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// loadRulesetConfig reads and parses the YAML configuration file
+// and returns it with the source positions of each rule expression keyed by rule name
+func loadRulesetConfig(configPath string) (*RulesetConfig, map[string]SourcePosition, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	return parseRulesetConfig(configPath, data)
+}
+
+// ParseRulesetConfig parses a YAML configuration already in memory, e.g. in environments without a filesystem
+// such as WebAssembly in the browser
+func ParseRulesetConfig(data []byte) (*RulesetConfig, error) {
+	config, _, err := parseRulesetConfig("", data)
+	return config, err
+}
+
+// parseRulesetConfig parses a YAML configuration, source positions are reported relative to the file name
+func parseRulesetConfig(name string, data []byte) (*RulesetConfig, map[string]SourcePosition, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var config RulesetConfig
-	err = yaml.Unmarshal(data, &config)
+	err = doc.Decode(&config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return &config, nil
+	config.resolveTypes(name)
+	return &config, expressionPositions(name, &doc), nil
+}
+
+// expressionPositions walks the parsed YAML document and records where each `rules.<name>.expression` value starts
+func expressionPositions(configPath string, doc *yaml.Node) map[string]SourcePosition {
+	positions := make(map[string]SourcePosition)
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return positions
+	}
+	rules := mappingValue(doc.Content[0], "rules")
+	if rules == nil || rules.Kind != yaml.MappingNode {
+		return positions
+	}
+	for i := 0; i+1 < len(rules.Content); i += 2 {
+		expression := mappingValue(rules.Content[i+1], "expression")
+		if expression == nil {
+			continue
+		}
+		positions[rules.Content[i].Value] = SourcePosition{
+			File:   configPath,
+			Line:   expression.Line,
+			Column: expression.Column,
+		}
+	}
+	return positions
+}
+
+// mappingValue returns the value node for key in a YAML mapping node, or nil if absent
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mergeErrorMessages copies the inline error messages of rules and rulesets into the custom error messages
+//
+//	Inline messages override the base error_handling map, apply it before ApplyEnvironment so environment
+//	messages still take precedence
+func (rc *RulesetConfig) mergeErrorMessages() {
+	if rc.ErrorHandling.CustomErrorMessages == nil {
+		rc.ErrorHandling.CustomErrorMessages = make(map[string]string)
+	}
+	for name, rule := range rc.Rules {
+		if rule.ErrorMessage != "" {
+			rc.ErrorHandling.CustomErrorMessages[name] = rule.ErrorMessage
+		}
+	}
+	for name, ruleset := range rc.Rulesets {
+		if ruleset.ErrorMessage != "" {
+			rc.ErrorHandling.CustomErrorMessages[name] = ruleset.ErrorMessage
+		}
+	}
 }
 
 // ApplyEnvironment applies environment-specific overrides to the configuration
+//
+//	Globals are merged onto the base globals as configured by GlobalsMerge. Apply it once to the config as given,
+//	applying overrides again merges them again, e.g. appends lists merged with ListAppend twice
 func (rc *RulesetConfig) ApplyEnvironment(environment string) {
 	// Apply environment-specific overrides
 	if envConfig, exists := rc.Environments[environment]; exists {
 		// Apply environment-specific globals
 		if envConfig.Globals != nil {
-			for k, v := range envConfig.Globals {
-				rc.Globals[k] = v
+			if rc.Globals == nil {
+				rc.Globals = make(map[string]interface{}, len(envConfig.Globals))
 			}
+			rc.GlobalsMerge.mergeInto(rc.Globals, envConfig.Globals)
 		}
 		// Apply environment-specific error handling execution policy
 		if envConfig.ErrorHandling.ExecutionPolicy != "" {
@@ -98,11 +329,104 @@ func (rc *RulesetConfig) ApplyEnvironment(environment string) {
 		}
 		// Apply environment-specific custom error messages
 		if envConfig.ErrorHandling.CustomErrorMessages != nil {
+			if rc.ErrorHandling.CustomErrorMessages == nil {
+				rc.ErrorHandling.CustomErrorMessages = make(map[string]string, len(envConfig.ErrorHandling.CustomErrorMessages))
+			}
 			for k, v := range envConfig.ErrorHandling.CustomErrorMessages {
 				rc.ErrorHandling.CustomErrorMessages[k] = v
 			}
 		}
+		// Apply environment-specific ruleset and rule selection
+		if len(envConfig.EnabledRulesets) > 0 {
+			rc.enableRulesets(envConfig.EnabledRulesets)
+		}
+		if len(envConfig.DisabledRules) > 0 {
+			rc.disableRules(envConfig.DisabledRules)
+		}
+	}
+}
+
+// enableRulesets removes every ruleset not listed in enabled, unknown names are ignored
+func (rc *RulesetConfig) enableRulesets(enabled []string) {
+	keep := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		keep[name] = true
+	}
+	rulesets := make(map[string]Ruleset, len(enabled))
+	for name, ruleset := range rc.Rulesets {
+		if keep[name] {
+			rulesets[name] = ruleset
+		}
+	}
+	rc.Rulesets = rulesets
+}
+
+// disableRules removes the disabled rules and the rules extending them, and drops them from every ruleset
+//
+//	A ruleset left without rules passes with the AND selector and fails with OR, unknown names are ignored
+func (rc *RulesetConfig) disableRules(disabled []string) {
+	removed := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		removed[name] = true
+	}
+	// Rules extending a disabled rule are disabled too, repeat until no more rules are found
+	for changed := true; changed; {
+		changed = false
+		for name, rule := range rc.Rules {
+			if !removed[name] && rule.Extends != "" && removed[rule.Extends] {
+				removed[name] = true
+				changed = true
+			}
+		}
+	}
+
+	rules := make(map[string]Rule, len(rc.Rules))
+	for name, rule := range rc.Rules {
+		if !removed[name] {
+			rules[name] = rule
+		}
+	}
+	rc.Rules = rules
+	rulesets := make(map[string]Ruleset, len(rc.Rulesets))
+	for name, ruleset := range rc.Rulesets {
+		kept := make([]string, 0, len(ruleset.Rules))
+		for _, rule := range ruleset.Rules {
+			if !removed[rule] {
+				kept = append(kept, rule)
+			}
+		}
+		ruleset.Rules = kept
+		rulesets[name] = ruleset
+	}
+	rc.Rulesets = rulesets
+}
+
+// clone returns a copy of the config whose maps can be modified without affecting rc, values are shared
+func (rc *RulesetConfig) clone() *RulesetConfig {
+	c := *rc
+	c.Globals = copyMap(rc.Globals)
+	c.Functions = copyMap(rc.Functions)
+	c.Rules = copyMap(rc.Rules)
+	c.Rulesets = copyMap(rc.Rulesets)
+	c.ExecutionPolicies = copyMap(rc.ExecutionPolicies)
+	c.Environments = copyMap(rc.Environments)
+	c.Profiles = copyMap(rc.Profiles)
+	c.Decisions = copyMap(rc.Decisions)
+	c.Computed = copyMap(rc.Computed)
+	c.ErrorHandling.CustomErrorMessages = copyMap(rc.ErrorHandling.CustomErrorMessages)
+	return &c
+}
+
+// copyMap returns a shallow copy of m, nil if m is nil
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		out[k] = v
 	}
+	return out
 }
 
 // ToExecutionPolicy maps the execution policy from on the current configuration
@@ -122,6 +446,7 @@ func (rc *RulesetConfig) ToExecutionPolicy() (Policy, error) {
 			policy.MaxExecutionTime = dur
 		}
 		policy.StopOnFailure = configPolicy.StopOnFailure
+		policy.StopOnFirstPass = configPolicy.StopOnFirstPass
 	} else {
 		return policy, fmt.Errorf("execution policy '%s' not found in config", rc.ErrorHandling.ExecutionPolicy)
 	}
@@ -2,6 +2,7 @@ package ruleengine
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -56,6 +57,7 @@ func TestNewRulesetConfig(t *testing.T) {
 						Name:        "Age Validation",
 						Description: "Validates user age requirements",
 						Expression:  "user.age >= globals.min_age",
+						Owner:       "identity-team",
 					},
 					"email_format": {
 						Name:        "Email Format Check",
@@ -101,6 +103,7 @@ func TestNewRulesetConfig(t *testing.T) {
 						Name:        "User Registration Validation",
 						Description: "All rules must pass for successful registration",
 						Selector:    "AND",
+						Owner:       "identity-team",
 						Rules: []string{
 							"age_validation",
 							"email_format",
@@ -185,6 +188,42 @@ func TestNewRulesetConfig(t *testing.T) {
 	}
 }
 
+func TestParseRulesetConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *RulesetConfig
+		wantErr bool
+	}{
+		{
+			name:    "fail - invalid yaml",
+			data:    "rules: [",
+			wantErr: true,
+		},
+		{
+			name: "success - rule",
+			data: "rules:\n  adult:\n    expression: user.age >= 18\n",
+			want: &RulesetConfig{
+				Rules: map[string]Rule{
+					"adult": {Expression: "user.age >= 18"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRulesetConfig([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRulesetConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("ParseRulesetConfig() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestRulesetConfig_ApplyEnvironment(t *testing.T) {
 	type fields struct {
 		APIVersion        string
@@ -462,6 +501,83 @@ func TestRulesetConfig_ApplyEnvironment(t *testing.T) {
 	}
 }
 
+func TestRulesetConfig_ApplyEnvironment_RuleSelection(t *testing.T) {
+	newConfig := func(env Environment) *RulesetConfig {
+		return &RulesetConfig{
+			Rules: map[string]Rule{
+				"age_validation": {Expression: "user.age >= 18"},
+				"business_hours": {Expression: "request.hour >= 9"},
+				"weekday_hours":  {Expression: "request.day < 5", Extends: "business_hours"},
+			},
+			Rulesets: map[string]Ruleset{
+				"user_registration": {Rules: []string{"age_validation", "business_hours"}},
+				"support":           {Rules: []string{"weekday_hours"}},
+			},
+			Environments: map[string]Environment{"development": env},
+		}
+	}
+	tests := []struct {
+		name         string
+		env          Environment
+		wantRules    []string
+		wantRulesets map[string][]string
+	}{
+		{
+			name:      "success - no selection",
+			wantRules: []string{"age_validation", "business_hours", "weekday_hours"},
+			wantRulesets: map[string][]string{
+				"user_registration": {"age_validation", "business_hours"},
+				"support":           {"weekday_hours"},
+			},
+		},
+		{
+			name:         "success - enabled rulesets",
+			env:          Environment{EnabledRulesets: []string{"user_registration", "unknown"}},
+			wantRules:    []string{"age_validation", "business_hours", "weekday_hours"},
+			wantRulesets: map[string][]string{"user_registration": {"age_validation", "business_hours"}},
+		},
+		{
+			name:      "success - disabled rules and the rules extending them",
+			env:       Environment{DisabledRules: []string{"business_hours", "unknown"}},
+			wantRules: []string{"age_validation"},
+			wantRulesets: map[string][]string{
+				"user_registration": {"age_validation"},
+				"support":           {},
+			},
+		},
+		{
+			name: "success - enabled rulesets and disabled rules",
+			env: Environment{
+				EnabledRulesets: []string{"support"},
+				DisabledRules:   []string{"weekday_hours"},
+			},
+			wantRules:    []string{"age_validation", "business_hours"},
+			wantRulesets: map[string][]string{"support": {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := newConfig(tt.env)
+			rc.ApplyEnvironment("development")
+			gotRules := make([]string, 0, len(rc.Rules))
+			for name := range rc.Rules {
+				gotRules = append(gotRules, name)
+			}
+			sort.Strings(gotRules)
+			gotRulesets := make(map[string][]string, len(rc.Rulesets))
+			for name, ruleset := range rc.Rulesets {
+				gotRulesets[name] = ruleset.Rules
+			}
+			if diff := cmp.Diff(tt.wantRules, gotRules); diff != "" {
+				t.Errorf("ApplyEnvironment() rules (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRulesets, gotRulesets); diff != "" {
+				t.Errorf("ApplyEnvironment() rulesets (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestRulesetConfig_GetExecutionPolicy(t *testing.T) {
 	type fields struct {
 		ExecutionPolicies map[string]ExecutionPolicy
@@ -610,3 +726,86 @@ func TestRulesetConfig_GetExecutionPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestRulesetConfig_InlineErrorMessages(t *testing.T) {
+	newConfig := func() *RulesetConfig {
+		return &RulesetConfig{
+			Rules: map[string]Rule{
+				"age_validation": {Expression: "user.age >= 18", ErrorMessage: "inline age message"},
+				"email_format":   {Expression: "user.email != ''"},
+				"user_status":    {Expression: "user.status == 'active'", ErrorMessage: "inline status message"},
+			},
+			Rulesets: map[string]Ruleset{
+				"user_registration": {
+					Rules:        []string{"age_validation", "email_format", "user_status"},
+					ErrorMessage: "inline ruleset message",
+				},
+			},
+			ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+			ErrorHandling: ErrorHandling{
+				ExecutionPolicy: "collect_all",
+				CustomErrorMessages: map[string]string{
+					"age_validation": "global age message",
+					"email_format":   "global email message",
+				},
+			},
+			Environments: map[string]Environment{
+				"production": {
+					ErrorHandling: ErrorHandling{
+						CustomErrorMessages: map[string]string{"user_status": "production status message"},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name         string
+		environment  string
+		wantMessages map[string]string
+		wantRuleset  string
+	}{
+		{
+			name: "success - inline overrides the global map",
+			wantMessages: map[string]string{
+				"age_validation": "inline age message",
+				"email_format":   "global email message",
+				"user_status":    "inline status message",
+			},
+			wantRuleset: "inline ruleset message",
+		},
+		{
+			name:        "success - environment overrides inline",
+			environment: "production",
+			wantMessages: map[string]string{
+				"age_validation": "inline age message",
+				"email_format":   "global email message",
+				"user_status":    "production status message",
+			},
+			wantRuleset: "inline ruleset message",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(newConfig()).WithEnvironment(tt.environment).WithVariables("user").Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			got, err := re.EvaluateRuleset("user_registration", WithEvalContext(map[string]interface{}{
+				"user": map[string]interface{}{"age": 10, "email": "", "status": "suspended"},
+			}))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			gotMessages := make(map[string]string, len(got.RuleResults))
+			for name, result := range got.RuleResults {
+				gotMessages[name] = result.Error.Error()
+			}
+			if diff := cmp.Diff(tt.wantMessages, gotMessages); diff != "" {
+				t.Errorf("EvaluateRuleset() rule messages mismatch (-want +got):\n%s", diff)
+			}
+			if got.Error == nil || got.Error.Error() != tt.wantRuleset {
+				t.Errorf("EvaluateRuleset() error = %v, want %s", got.Error, tt.wantRuleset)
+			}
+		})
+	}
+}
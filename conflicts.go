@@ -0,0 +1,265 @@
+package ruleengine
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+)
+
+// ConflictingRules are two rules of an AND ruleset requiring opposite conditions, so the ruleset can never pass
+type ConflictingRules struct {
+	// Ruleset is the name of the AND ruleset
+	Ruleset string
+	// Rule and Negation are the rules, Negation requires the negation of a condition of Rule, e.g. `user.age < 18`
+	// for `user.age >= 18`
+	Rule     string
+	Negation string
+}
+
+// condition is a canonical condition a rule requires to pass
+type condition struct {
+	// expr is the canonical form of the condition, identical for semantically identical conditions, e.g. `a == b` and
+	// `b == a`
+	expr string
+	// negation is the canonical form of the negated condition
+	negation string
+}
+
+// ruleConditions returns the canonical conditions each rule requires to pass, the conjuncts of its expression and of
+// the expressions of the rules it extends, sorted
+//
+//	Errors are returned if an expression cannot be compiled
+func (re *RuleEngine) ruleConditions() (map[string][]condition, error) {
+	own := make(map[string][]condition, len(re.config.Rules))
+	for _, name := range re.ruleNames() {
+		checked, issues := re.env.Compile(re.config.Rules[name].Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("failed to compile rule '%s': %w", name, issues.Err())
+		}
+		for _, conjunct := range flatten(operators.LogicalAnd, checked.NativeRep().Expr()) {
+			own[name] = append(own[name], condition{expr: canonicalExpr(conjunct), negation: negatedExpr(conjunct)})
+		}
+	}
+	conditions := make(map[string][]condition, len(own))
+	for name := range own {
+		all := slices.Clone(own[name])
+		for _, parent := range re.parents[name] {
+			all = append(all, own[parent]...)
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].expr < all[j].expr })
+		conditions[name] = slices.CompactFunc(all, func(a, b condition) bool { return a.expr == b.expr })
+	}
+	return conditions, nil
+}
+
+// duplicateRules groups the rules requiring identical conditions under identical when clauses and time windows,
+// each group and the groups sorted by name
+func (re *RuleEngine) duplicateRules(conditions map[string][]condition) ([][]string, error) {
+	groups := make(map[string][]string)
+	for _, name := range re.ruleNames() {
+		rule := re.config.Rules[name]
+		exprs := make([]string, 0, len(conditions[name]))
+		for _, c := range conditions[name] {
+			exprs = append(exprs, c.expr)
+		}
+		when := ""
+		if rule.When != "" {
+			checked, issues := re.env.Compile(rule.When)
+			if issues != nil && issues.Err() != nil {
+				return nil, fmt.Errorf("failed to compile when of rule '%s': %w", name, issues.Err())
+			}
+			when = canonicalExpr(checked.NativeRep().Expr())
+		}
+		key := strings.Join([]string{strings.Join(exprs, " && "), when, rule.ActiveFrom, rule.ActiveUntil, rule.Schedule}, "\n")
+		groups[key] = append(groups[key], name)
+	}
+	duplicates := make([][]string, 0)
+	for _, names := range groups {
+		if len(names) > 1 {
+			duplicates = append(duplicates, names)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i][0] < duplicates[j][0] })
+	return duplicates, nil
+}
+
+// conflictingRules finds the required rules of AND rulesets requiring the negation of a condition of another, sorted
+// by ruleset
+//
+//	Optional rules and rules which may be skipped, by a when clause or time window, never fail the ruleset and are
+//	left out
+func (re *RuleEngine) conflictingRules(conditions map[string][]condition) []ConflictingRules {
+	conflicts := make([]ConflictingRules, 0)
+	for _, rulesetName := range re.rulesetNames() {
+		ruleset := re.config.Rulesets[rulesetName]
+		if ruleset.Selector == selectorOr {
+			continue
+		}
+		required := make([]string, 0, len(ruleset.Rules))
+		for _, name := range ruleset.Rules {
+			rule, ok := re.config.Rules[name]
+			if !ok || slices.Contains(ruleset.Optional, name) || slices.Contains(required, name) ||
+				rule.When != "" || rule.ActiveFrom != "" || rule.ActiveUntil != "" || rule.Schedule != "" {
+				continue
+			}
+			required = append(required, name)
+		}
+		for i, rule := range required {
+			for _, other := range required[i+1:] {
+				if negates(conditions[other], conditions[rule]) {
+					conflicts = append(conflicts, ConflictingRules{Ruleset: rulesetName, Rule: rule, Negation: other})
+				} else if negates(conditions[rule], conditions[other]) {
+					conflicts = append(conflicts, ConflictingRules{Ruleset: rulesetName, Rule: other, Negation: rule})
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// negates reports whether one of the conditions of negation is the negation of one of conditions
+func negates(negation, conditions []condition) bool {
+	for _, c := range conditions {
+		for _, n := range negation {
+			if n.expr == c.negation {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flatten returns the operands of nested calls of the commutative operator op, e.g. `a`, `b` and `c` for
+// `a && (b && c)`, or e itself
+func flatten(op string, e ast.Expr) []ast.Expr {
+	if e.Kind() != ast.CallKind || e.AsCall().FunctionName() != op {
+		return []ast.Expr{e}
+	}
+	operands := make([]ast.Expr, 0, 2)
+	for _, arg := range e.AsCall().Args() {
+		operands = append(operands, flatten(op, arg)...)
+	}
+	return operands
+}
+
+// negatedOperators maps comparison operators to the operator of their negation
+var negatedOperators = map[string]string{
+	operators.Equals:        operators.NotEquals,
+	operators.NotEquals:     operators.Equals,
+	operators.Less:          operators.GreaterEquals,
+	operators.LessEquals:    operators.Greater,
+	operators.Greater:       operators.LessEquals,
+	operators.GreaterEquals: operators.Less,
+}
+
+// negatedExpr returns the canonical form of the negation of e, negated comparisons are the opposite comparison
+func negatedExpr(e ast.Expr) string {
+	if e.Kind() == ast.CallKind {
+		call := e.AsCall()
+		if call.FunctionName() == operators.LogicalNot && len(call.Args()) == 1 {
+			return canonicalExpr(call.Args()[0])
+		}
+		if op, ok := negatedOperators[call.FunctionName()]; ok && len(call.Args()) == 2 {
+			return canonicalBinary(op, call.Args()[0], call.Args()[1])
+		}
+	}
+	return "!" + canonicalExpr(e)
+}
+
+// canonicalBinary returns the canonical form of a binary operator call, the operands of commutative comparisons are
+// sorted and `a > b` is written `b < a`
+func canonicalBinary(op string, lhs, rhs ast.Expr) string {
+	left, right := canonicalExpr(lhs), canonicalExpr(rhs)
+	switch op {
+	case operators.Equals, operators.NotEquals:
+		if right < left {
+			left, right = right, left
+		}
+	case operators.Greater:
+		op, left, right = operators.Less, right, left
+	case operators.GreaterEquals:
+		op, left, right = operators.LessEquals, right, left
+	}
+	symbol, _ := operators.FindReverseBinaryOperator(op)
+	return "(" + left + " " + symbol + " " + right + ")"
+}
+
+// canonicalExpr formats e so that semantically identical expressions are formatted identically, the operands of &&
+// and || are sorted and negations are pushed into comparisons
+func canonicalExpr(e ast.Expr) string {
+	switch e.Kind() {
+	case ast.LiteralKind:
+		lit := e.AsLiteral()
+		if s, ok := lit.Value().(string); ok {
+			return strconv.Quote(s)
+		}
+		return fmt.Sprintf("%s(%v)", lit.Type().TypeName(), lit.Value())
+	case ast.IdentKind:
+		return e.AsIdent()
+	case ast.SelectKind:
+		sel := e.AsSelect()
+		if sel.IsTestOnly() {
+			return "has(" + canonicalExpr(sel.Operand()) + "." + sel.FieldName() + ")"
+		}
+		return canonicalExpr(sel.Operand()) + "." + sel.FieldName()
+	case ast.CallKind:
+		call := e.AsCall()
+		args := call.Args()
+		switch fn := call.FunctionName(); {
+		case fn == operators.LogicalNot && len(args) == 1:
+			return negatedExpr(args[0])
+		case fn == operators.LogicalAnd || fn == operators.LogicalOr:
+			operands := make([]string, 0, len(args))
+			for _, operand := range flatten(fn, e) {
+				operands = append(operands, canonicalExpr(operand))
+			}
+			sort.Strings(operands)
+			symbol, _ := operators.FindReverseBinaryOperator(fn)
+			return "(" + strings.Join(slices.Compact(operands), " "+symbol+" ") + ")"
+		case len(args) == 2:
+			if _, ok := operators.FindReverseBinaryOperator(fn); ok {
+				return canonicalBinary(fn, args[0], args[1])
+			}
+		}
+		formatted := make([]string, 0, len(args))
+		for _, arg := range args {
+			formatted = append(formatted, canonicalExpr(arg))
+		}
+		target := ""
+		if call.IsMemberFunction() {
+			target = canonicalExpr(call.Target()) + "."
+		}
+		return target + call.FunctionName() + "(" + strings.Join(formatted, ", ") + ")"
+	case ast.ListKind:
+		elems := make([]string, 0)
+		for _, elem := range e.AsList().Elements() {
+			elems = append(elems, canonicalExpr(elem))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case ast.MapKind:
+		entries := make([]string, 0)
+		for _, entry := range e.AsMap().Entries() {
+			entries = append(entries, canonicalExpr(entry.AsMapEntry().Key())+": "+canonicalExpr(entry.AsMapEntry().Value()))
+		}
+		sort.Strings(entries)
+		return "{" + strings.Join(entries, ", ") + "}"
+	case ast.StructKind:
+		fields := make([]string, 0)
+		for _, field := range e.AsStruct().Fields() {
+			fields = append(fields, field.AsStructField().Name()+": "+canonicalExpr(field.AsStructField().Value()))
+		}
+		sort.Strings(fields)
+		return e.AsStruct().TypeName() + "{" + strings.Join(fields, ", ") + "}"
+	case ast.ComprehensionKind:
+		comp := e.AsComprehension()
+		return fmt.Sprintf("comprehension(%s, %s, %s, %s, %s, %s, %s, %s)", comp.IterVar(), comp.IterVar2(),
+			canonicalExpr(comp.IterRange()), comp.AccuVar(), canonicalExpr(comp.AccuInit()),
+			canonicalExpr(comp.LoopCondition()), canonicalExpr(comp.LoopStep()), canonicalExpr(comp.Result()))
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestCanonicalExpr(t *testing.T) {
+	env, err := cel.NewEnv(cel.Variable("user", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		t.Fatalf("cel.NewEnv() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		lhs, rhs  string
+		wantEqual bool
+	}{
+		{name: "success - swapped equality", lhs: "user.a == 'x'", rhs: "'x' == user.a", wantEqual: true},
+		{name: "success - mirrored comparison", lhs: "user.age > 18", rhs: "18 < user.age", wantEqual: true},
+		{name: "success - reordered and", lhs: "user.a && (user.b && user.c)", rhs: "user.c && user.b && user.a", wantEqual: true},
+		{name: "success - negated comparison", lhs: "!(user.age >= 18)", rhs: "user.age < 18", wantEqual: true},
+		{name: "success - double negation", lhs: "!!user.verified", rhs: "user.verified", wantEqual: true},
+		{name: "success - negated equality", lhs: "!(user.a == 'x')", rhs: "'x' != user.a", wantEqual: true},
+		{name: "success - reordered map", lhs: "{'a': 1, 'b': 2} == user.m", rhs: "user.m == {'b': 2, 'a': 1}", wantEqual: true},
+		{name: "fail - different literal type", lhs: "user.age == 18", rhs: "user.age == 18u"},
+		{name: "fail - swapped subtraction", lhs: "user.a - user.b == 0", rhs: "user.b - user.a == 0"},
+		{name: "fail - different field", lhs: "user.a == 'x'", rhs: "user.b == 'x'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lhs, iss := env.Compile(tt.lhs)
+			if iss.Err() != nil {
+				t.Fatalf("Compile(%s) error = %v", tt.lhs, iss.Err())
+			}
+			rhs, iss := env.Compile(tt.rhs)
+			if iss.Err() != nil {
+				t.Fatalf("Compile(%s) error = %v", tt.rhs, iss.Err())
+			}
+			l, r := canonicalExpr(lhs.NativeRep().Expr()), canonicalExpr(rhs.NativeRep().Expr())
+			if (l == r) != tt.wantEqual {
+				t.Errorf("canonicalExpr() = %s and %s, want equal %v", l, r, tt.wantEqual)
+			}
+		})
+	}
+}
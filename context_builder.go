@@ -0,0 +1,252 @@
+package ruleengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	protoType    = reflect.TypeOf((*proto.Message)(nil)).Elem()
+)
+
+// ContextBuilder builds an evaluation context from Go values
+//
+//	Struct fields are placed at the path given by their `cel` tag, e.g. `cel:"user.age"`, falling back to
+//	the `json` tag name and then the field name. Paths are relative to the prefix passed to Add.
+//	time.Time and time.Duration are kept as is for CEL to convert, protobuf timestamps and durations are
+//	converted to their Go equivalents and other protobuf messages are converted to maps.
+type ContextBuilder struct {
+	ctx map[string]interface{}
+	err error
+}
+
+// NewContextBuilder creates an empty ContextBuilder
+func NewContextBuilder() *ContextBuilder {
+	return &ContextBuilder{
+		ctx: make(map[string]interface{}),
+	}
+}
+
+// Set places value at the dotted path, e.g. `request.attempt`
+func (b *ContextBuilder) Set(path string, value interface{}) *ContextBuilder {
+	if b.err != nil {
+		return b
+	}
+	converted, ok, err := convertValue(reflect.ValueOf(value))
+	if err != nil {
+		b.err = fmt.Errorf("failed to convert '%s': %w", path, err)
+		return b
+	}
+	if ok {
+		b.set(path, converted)
+	}
+	return b
+}
+
+// Add converts the struct v and merges its fields into the context under prefix
+//
+//	An empty prefix places the fields at the root of the context, which is useful with absolute `cel` tags
+func (b *ContextBuilder) Add(prefix string, v interface{}) *ContextBuilder {
+	if b.err != nil {
+		return b
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct || rv.Type() == timeType || rv.Type().Implements(protoType) ||
+		reflect.PointerTo(rv.Type()).Implements(protoType) {
+		return b.Set(prefix, v)
+	}
+	b.err = b.addStruct(prefix, rv)
+	return b
+}
+
+// Build returns the context, or the first error encountered while adding values
+func (b *ContextBuilder) Build() (map[string]interface{}, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.ctx, nil
+}
+
+// addStruct walks the fields of the struct rv, placing each at its tagged path under prefix
+func (b *ContextBuilder) addStruct(prefix string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+
+		name, omitEmpty, tagged := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		// Untagged embedded structs are flattened like encoding/json does
+		if field.Anonymous && !tagged {
+			embedded := reflect.Indirect(fv)
+			if embedded.Kind() == reflect.Struct {
+				if err := b.addStruct(prefix, embedded); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		path := joinPath(prefix, name)
+		converted, ok, err := convertValue(fv)
+		if err != nil {
+			return fmt.Errorf("failed to convert '%s': %w", path, err)
+		}
+		if ok {
+			b.set(path, converted)
+		}
+	}
+	return nil
+}
+
+// set places value at the dotted path, creating intermediate maps as needed
+func (b *ContextBuilder) set(path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	current := b.ctx
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	last := parts[len(parts)-1]
+	// Merge maps placed at the same path rather than overwriting fields set by tags
+	if existing, ok := current[last].(map[string]interface{}); ok {
+		if incoming, ok := value.(map[string]interface{}); ok {
+			for k, v := range incoming {
+				existing[k] = v
+			}
+			return
+		}
+	}
+	current[last] = value
+}
+
+// fieldName returns the context name of the struct field, whether it is omitted when empty and whether it is tagged
+func fieldName(field reflect.StructField) (string, bool, bool) {
+	if tag, ok := field.Tag.Lookup("cel"); ok {
+		name, opts, _ := strings.Cut(tag, ",")
+		return name, opts == "omitempty", true
+	}
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name, opts, _ := strings.Cut(tag, ",")
+		omitEmpty := strings.Contains(opts, "omitempty")
+		if name == "" {
+			return field.Name, omitEmpty, false
+		}
+		return name, omitEmpty, true
+	}
+	return field.Name, false, false
+}
+
+// joinPath joins a prefix and a name into a dotted path
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// convertValue converts v into a value CEL can evaluate, ok is false for nil values which are omitted
+func convertValue(v reflect.Value) (interface{}, bool, error) {
+	if !v.IsValid() {
+		return nil, false, nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || v.Kind() == reflect.Map ||
+		v.Kind() == reflect.Slice) && v.IsNil() {
+		return nil, false, nil
+	}
+
+	if v.CanInterface() {
+		switch msg := v.Interface().(type) {
+		case *timestamppb.Timestamp:
+			return msg.AsTime(), true, nil
+		case *durationpb.Duration:
+			return msg.AsDuration(), true, nil
+		case proto.Message:
+			return protoToMap(msg)
+		}
+	}
+	if v.Type() == timeType || v.Type() == durationType {
+		return v.Interface(), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return convertValue(v.Elem())
+	case reflect.Struct:
+		nested := &ContextBuilder{ctx: make(map[string]interface{})}
+		if err := nested.addStruct("", v); err != nil {
+			return nil, false, err
+		}
+		return nested.ctx, true, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), true, nil
+		}
+		list := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, ok, err := convertValue(v.Index(i))
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				list = append(list, item)
+			}
+		}
+		return list, true, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false, fmt.Errorf("map keys must be strings, got %s", v.Type().Key())
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, ok, err := convertValue(iter.Value())
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				m[iter.Key().String()] = item
+			}
+		}
+		return m, true, nil
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return nil, false, fmt.Errorf("unsupported type %s", v.Type())
+	default:
+		return v.Interface(), true, nil
+	}
+}
+
+// protoToMap converts a protobuf message into a map using its proto field names
+func protoToMap(msg proto.Message) (interface{}, bool, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, false, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false, err
+	}
+	return m, true, nil
+}
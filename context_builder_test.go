@@ -0,0 +1,176 @@
+package ruleengine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AuditFields struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type testUser struct {
+	AuditFields
+	Age       int               `json:"age"`
+	Email     string            `json:"email"`
+	Status    string            `cel:"status"`
+	Suspended bool              `json:"suspended"`
+	Nickname  string            `json:"nickname,omitempty"`
+	Secret    string            `json:"-"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+	Manager   *testUser         `json:"manager"`
+	internal  string
+}
+
+type testRequest struct {
+	Attempt   int                    `cel:"request.attempt"`
+	Time      time.Time              `cel:"request.time"`
+	Timeout   *durationpb.Duration   `cel:"request.timeout"`
+	Created   *timestamppb.Timestamp `cel:"request.created"`
+	Method    *apipb.Method          `cel:"request.method"`
+	UserAge   int                    `cel:"user.age"`
+	UserEmail string                 `cel:"user.email"`
+}
+
+func TestContextBuilder_Build(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		builder func() *ContextBuilder
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "success - json tags under prefix",
+			builder: func() *ContextBuilder {
+				return NewContextBuilder().Add("user", testUser{
+					AuditFields: AuditFields{CreatedBy: "admin"},
+					Age:         21,
+					Email:       "test@example.com",
+					Status:      "active",
+					Secret:      "hidden",
+					Tags:        []string{"beta"},
+					Labels:      map[string]string{"team": "risk"},
+					internal:    "hidden",
+				})
+			},
+			want: map[string]interface{}{
+				"user": map[string]interface{}{
+					"created_by": "admin",
+					"age":        21,
+					"email":      "test@example.com",
+					"status":     "active",
+					"suspended":  false,
+					"tags":       []interface{}{"beta"},
+					"labels":     map[string]interface{}{"team": "risk"},
+				},
+			},
+		},
+		{
+			name: "success - absolute cel tags, time and protobuf",
+			builder: func() *ContextBuilder {
+				return NewContextBuilder().Add("", testRequest{
+					Attempt:   2,
+					Time:      now,
+					Timeout:   durationpb.New(time.Second),
+					Created:   timestamppb.New(now),
+					Method:    &apipb.Method{Name: "Get", RequestStreaming: true},
+					UserAge:   21,
+					UserEmail: "test@example.com",
+				}).Set("request.ip", "127.0.0.1")
+			},
+			want: map[string]interface{}{
+				"request": map[string]interface{}{
+					"attempt": 2,
+					"time":    now,
+					"timeout": time.Second,
+					"created": now,
+					"method":  map[string]interface{}{"name": "Get", "request_streaming": true},
+					"ip":      "127.0.0.1",
+				},
+				"user": map[string]interface{}{
+					"age":   21,
+					"email": "test@example.com",
+				},
+			},
+		},
+		{
+			name: "success - nested struct pointer",
+			builder: func() *ContextBuilder {
+				return NewContextBuilder().Add("user", &testUser{
+					Age:     30,
+					Manager: &testUser{Age: 40},
+				})
+			},
+			want: map[string]interface{}{
+				"user": map[string]interface{}{
+					"created_by": "",
+					"age":        30,
+					"email":      "",
+					"status":     "",
+					"suspended":  false,
+					"manager": map[string]interface{}{
+						"created_by": "",
+						"age":        40,
+						"email":      "",
+						"status":     "",
+						"suspended":  false,
+					},
+				},
+			},
+		},
+		{
+			name: "fail - unsupported type",
+			builder: func() *ContextBuilder {
+				return NewContextBuilder().Set("user.callback", func() {})
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail - non string map keys",
+			builder: func() *ContextBuilder {
+				return NewContextBuilder().Set("user.scores", map[int]int{1: 2})
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder().Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Build() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestContextBuilder_Evaluate(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "production", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx, err := NewContextBuilder().Add("user", testUser{
+		Age:    21,
+		Email:  "test@example.com",
+		Status: "active",
+	}).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	got, err := re.EvaluateRuleset("user_registration", WithEvalContext(ctx))
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if !got.Passed {
+		t.Errorf("EvaluateRuleset() = %+v, want passed", got)
+	}
+}
@@ -0,0 +1,64 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+)
+
+// ErrCostBudgetExceeded is returned when loading a rule whose estimated cost exceeds the configured budget
+var ErrCostBudgetExceeded = errors.New("cost budget exceeded")
+
+// CostEstimate is the static cost range of an expression as estimated by CEL
+//
+//	Expressions iterating over dynamic lists or strings of unknown size have a very large Max
+type CostEstimate struct {
+	Min uint64
+	Max uint64
+}
+
+// WithCostBudget fails loading when the estimated maximum cost of any rule exceeds budget
+//
+//	CEL cannot bound the size of dyn values, so rules iterating over or comparing dynamic context values have a
+//	very large estimate. Declare typed variables for a budget to be meaningful.
+func WithCostBudget(budget uint64) Option {
+	return func(re *RuleEngine) {
+		re.costBudget = budget
+	}
+}
+
+// Costs returns a map of rule names to the static cost estimates computed when the rules were compiled
+func (re *RuleEngine) Costs() map[string]CostEstimate {
+	if active := re.Active(); active != re {
+		return active.Costs()
+	}
+	costs := make(map[string]CostEstimate, len(re.costs))
+	for name, cost := range re.costs {
+		costs[name] = cost
+	}
+	return costs
+}
+
+// estimateCost returns the static cost estimate of a checked expression
+func (re *RuleEngine) estimateCost(checked *cel.Ast) (CostEstimate, error) {
+	estimate, err := re.env.EstimateCost(checked, defaultCostEstimator{})
+	if err != nil {
+		return CostEstimate{}, fmt.Errorf("failed to estimate cost: %w", err)
+	}
+	return CostEstimate{Min: estimate.Min, Max: estimate.Max}, nil
+}
+
+// defaultCostEstimator is a checker.CostEstimator relying on CEL's default size and call estimates
+type defaultCostEstimator struct{}
+
+// EstimateSize implements checker.CostEstimator
+func (defaultCostEstimator) EstimateSize(checker.AstNode) *checker.SizeEstimate {
+	return nil
+}
+
+// EstimateCallCost implements checker.CostEstimator
+func (defaultCostEstimator) EstimateCallCost(string, string, *checker.AstNode, []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
@@ -0,0 +1,72 @@
+package ruleengine
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_Costs(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/cost_rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	want := map[string]CostEstimate{
+		"cheap":        {Min: 1, Max: 1},
+		"nested_loops": {Min: 26, Max: 256},
+		"dynamic":      {Min: 2, Max: math.MaxUint64},
+	}
+	if diff := cmp.Diff(re.Costs(), want); diff != "" {
+		t.Errorf("Costs() (-got +want):\n%s", diff)
+	}
+}
+
+func TestNewRuleEngine_CostBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		budget     uint64
+		wantFailed []string
+	}{
+		{
+			name:   "success - no budget",
+			budget: 0,
+		},
+		{
+			name:       "fail - unbounded rule exceeds budget",
+			budget:     1000,
+			wantFailed: []string{"dynamic"},
+		},
+		{
+			name:       "fail - bounded rule exceeds budget",
+			budget:     100,
+			wantFailed: []string{"dynamic", "nested_loops"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRuleEngine("./testdata/cost_rules.yml", "", setupEnvironment()(t), WithCostBudget(tt.budget))
+			if (err != nil) != (len(tt.wantFailed) > 0) {
+				t.Fatalf("NewRuleEngine() error = %v, want failures %v", err, tt.wantFailed)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, ErrCostBudgetExceeded) {
+				t.Errorf("NewRuleEngine() error = %v, want ErrCostBudgetExceeded", err)
+			}
+			var compileErrs CompileErrors
+			if !errors.As(err, &compileErrs) {
+				t.Fatalf("NewRuleEngine() error = %T, want CompileErrors", err)
+			}
+			failed := make([]string, 0, len(compileErrs))
+			for _, compileErr := range compileErrs {
+				failed = append(failed, compileErr.RuleName)
+			}
+			if diff := cmp.Diff(failed, tt.wantFailed); diff != "" {
+				t.Errorf("failed rules (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
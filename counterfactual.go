@@ -0,0 +1,175 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// Suggestion is a counterfactual for a failed rule, the minimal change to a context field for a comparison to pass
+//
+//	Suggestions are derived from comparisons of a context field with a value, e.g. `user.age >= 18`, and from boolean
+//	fields, e.g. `!user.suspended`. Every suggestion of the operands of `&&` is needed, the suggestions of the
+//	operands of `||` are alternatives
+type Suggestion struct {
+	// Path is the context path to change, e.g. `user.age`
+	Path string
+	// Current is the value the rule evaluated against
+	Current interface{}
+	// Target is the closest value passing the comparison, or the value to avoid for `!=`
+	Target interface{}
+	// Message is a human-readable remediation hint, e.g. "user.age must increase by 3"
+	Message string
+}
+
+// reversedComparisons maps comparison operators to the operator with their operands swapped
+var reversedComparisons = map[string]string{
+	operators.Less:          operators.Greater,
+	operators.LessEquals:    operators.GreaterEquals,
+	operators.Greater:       operators.Less,
+	operators.GreaterEquals: operators.LessEquals,
+	operators.Equals:        operators.Equals,
+	operators.NotEquals:     operators.NotEquals,
+}
+
+// suggester derives the suggestions of a failed expression from the values recorded during its evaluation
+type suggester struct {
+	state    interpreter.EvalState
+	declared map[string]bool
+}
+
+// suggestions returns the suggestions making e true, none if e is not false or no simple change is found
+func (s suggester) suggestions(e ast.Expr) []Suggestion {
+	if val, ok := s.state.Value(e.ID()); !ok || val != types.False {
+		return nil
+	}
+	if path, ok := s.field(e); ok {
+		return []Suggestion{{Path: path, Current: false, Target: true, Message: path + " must be true"}}
+	}
+	if e.Kind() != ast.CallKind {
+		return nil
+	}
+	call := e.AsCall()
+	args := call.Args()
+	fn := call.FunctionName()
+	switch {
+	case fn == operators.LogicalAnd || fn == operators.LogicalOr:
+		out := make([]Suggestion, 0)
+		for _, arg := range args {
+			out = append(out, s.suggestions(arg)...)
+		}
+		return out
+	case fn == operators.LogicalNot && len(args) == 1:
+		if path, ok := s.field(args[0]); ok {
+			return []Suggestion{{Path: path, Current: true, Target: false, Message: path + " must be false"}}
+		}
+	case reversedComparisons[fn] != "" && len(args) == 2:
+		if path, ok := s.field(args[0]); ok {
+			return s.compare(path, fn, args[0], args[1])
+		}
+		if path, ok := s.field(args[1]); ok {
+			return s.compare(path, reversedComparisons[fn], args[1], args[0])
+		}
+	}
+	return nil
+}
+
+// field returns the context path of e if it selects a field of a declared variable other than globals
+func (s suggester) field(e ast.Expr) (string, bool) {
+	path, ok := selectPath(e)
+	if !ok {
+		return "", false
+	}
+	variable := strings.SplitN(path, ".", 2)[0]
+	return path, s.declared[variable] && variable != "globals"
+}
+
+// compare returns the suggestion making `field op other` true
+func (s suggester) compare(path, op string, field, other ast.Expr) []Suggestion {
+	current, ok := s.state.Value(field.ID())
+	if !ok {
+		return nil
+	}
+	target, ok := s.state.Value(other.ID())
+	if !ok {
+		return nil
+	}
+	suggestion := Suggestion{Path: path, Current: current.Value(), Target: target.Value()}
+	switch op {
+	case operators.Equals:
+		suggestion.Message = fmt.Sprintf("%s must be %s", path, formatValue(target))
+		return []Suggestion{suggestion}
+	case operators.NotEquals:
+		suggestion.Message = fmt.Sprintf("%s must not be %s", path, formatValue(target))
+		return []Suggestion{suggestion}
+	}
+
+	x, xOk := numeric(current)
+	k, kOk := numeric(target)
+	if !xOk || !kOk {
+		return nil
+	}
+	// Integer fields step by one past a strict bound, any other field only has a bound to cross
+	_, integral := current.(types.Int)
+	if _, ok := current.(types.Uint); ok {
+		integral = true
+	}
+	var delta float64
+	direction := "increase"
+	switch op {
+	case operators.GreaterEquals:
+		delta = k - x
+	case operators.Greater:
+		delta = k - x + 1
+		if !integral {
+			suggestion.Message = fmt.Sprintf("%s must be greater than %s", path, formatValue(target))
+			return []Suggestion{suggestion}
+		}
+	case operators.LessEquals:
+		delta, direction = x-k, "decrease"
+	case operators.Less:
+		delta, direction = x-k+1, "decrease"
+		if !integral {
+			suggestion.Message = fmt.Sprintf("%s must be less than %s", path, formatValue(target))
+			return []Suggestion{suggestion}
+		}
+	}
+	if direction == "increase" {
+		suggestion.Target = addNumeric(current, delta)
+	} else {
+		suggestion.Target = addNumeric(current, -delta)
+	}
+	suggestion.Message = fmt.Sprintf("%s must %s by %v", path, direction, delta)
+	return []Suggestion{suggestion}
+}
+
+// numeric returns the value of a CEL number as a float64
+func numeric(val ref.Val) (float64, bool) {
+	switch v := val.(type) {
+	case types.Int:
+		return float64(v), true
+	case types.Uint:
+		return float64(v), true
+	case types.Double:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// addNumeric adds delta to the CEL number val, keeping its Go type
+func addNumeric(val ref.Val, delta float64) interface{} {
+	switch v := val.(type) {
+	case types.Int:
+		return int64(v) + int64(delta)
+	case types.Uint:
+		return uint64(float64(v) + delta)
+	default:
+		return float64(val.(types.Double)) + delta
+	}
+}
@@ -0,0 +1,107 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRule_Suggestions(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		ctx  map[string]interface{}
+		opts []Option
+		want []Suggestion
+	}{
+		{
+			name: "success - greater or equal to a global",
+			rule: "age_validation",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"age": 15}},
+			opts: []Option{WithExplanations()},
+			want: []Suggestion{{Path: "user.age", Current: int64(15), Target: int64(18), Message: "user.age must increase by 3"}},
+		},
+		{
+			name: "success - strictly greater integer",
+			rule: "credit_score",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"score": 650}},
+			opts: []Option{WithExplanations()},
+			want: []Suggestion{{Path: "user.score", Current: int64(650), Target: int64(701), Message: "user.score must increase by 51"}},
+		},
+		{
+			name: "success - less or equal",
+			rule: "login_attempts",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"attempts": 5}},
+			opts: []Option{WithExplanations()},
+			want: []Suggestion{{Path: "user.attempts", Current: int64(5), Target: int64(3), Message: "user.attempts must decrease by 2"}},
+		},
+		{
+			name: "success - strictly less double",
+			rule: "amount_limit",
+			ctx:  map[string]interface{}{"request": map[string]interface{}{"amount": 1500.5}},
+			opts: []Option{WithExplanations()},
+			want: []Suggestion{{Path: "request.amount", Current: 1500.5, Target: int64(1000), Message: "request.amount must be less than 1000"}},
+		},
+		{
+			name: "success - field on the right",
+			rule: "active_status",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"status": "suspended"}},
+			opts: []Option{WithExplanations()},
+			want: []Suggestion{{Path: "user.status", Current: "suspended", Target: "active", Message: `user.status must be "active"`}},
+		},
+		{
+			name: "success - negated boolean",
+			rule: "not_suspended",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"suspended": true}},
+			opts: []Option{WithExplanations()},
+			want: []Suggestion{{Path: "user.suspended", Current: true, Target: false, Message: "user.suspended must be false"}},
+		},
+		{
+			name: "success - alternatives of a failed operand",
+			rule: "active_adult",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"age": 21, "status": "suspended", "verified": false}},
+			opts: []Option{WithExplanations()},
+			want: []Suggestion{
+				{Path: "user.status", Current: "suspended", Target: "active", Message: `user.status must be "active"`},
+				{Path: "user.verified", Current: false, Target: true, Message: "user.verified must be true"},
+			},
+		},
+		{
+			name: "success - function call has no suggestion",
+			rule: "email_domain",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"email": "jane@example.com"}},
+			opts: []Option{WithExplanations()},
+		},
+		{
+			name: "success - passed rule has no suggestion",
+			rule: "age_validation",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"age": 21}},
+			opts: []Option{WithExplanations()},
+		},
+		{
+			name: "success - explanations disabled",
+			rule: "age_validation",
+			ctx:  map[string]interface{}{"user": map[string]interface{}{"age": 15}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/counterfactual_rules.yml").
+				WithVariables("user", "request").
+				WithOptions(tt.opts...).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+
+			got, err := re.EvaluateRule(tt.rule, WithEvalContext(tt.ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Suggestions); diff != "" {
+				t.Errorf("EvaluateRule() suggestions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
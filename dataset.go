@@ -0,0 +1,87 @@
+package ruleengine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DatasetReader streams evaluation contexts, e.g. historical requests replayed by a Simulator
+type DatasetReader interface {
+	// Next returns the next context, io.EOF is returned after the last one
+	Next() (map[string]interface{}, error)
+}
+
+// jsonlReader reads one JSON object context per line
+type jsonlReader struct {
+	dec    *json.Decoder
+	record int
+}
+
+// NewJSONLReader reads a JSON Lines dataset, each line is a JSON object holding one context
+func NewJSONLReader(r io.Reader) DatasetReader {
+	return &jsonlReader{dec: json.NewDecoder(r)}
+}
+
+// Next implements DatasetReader
+func (r *jsonlReader) Next() (map[string]interface{}, error) {
+	ctx := make(map[string]interface{})
+	if err := r.dec.Decode(&ctx); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("record %d: %w", r.record+1, err)
+	}
+	r.record++
+	return ctx, nil
+}
+
+// csvReader reads one context per CSV row, placing each column at the dotted path in its header
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+	record int
+}
+
+// NewCSVReader reads a CSV dataset whose header row holds the dotted context path of each column, e.g. `user.age`
+//
+//	Cells holding a JSON number, boolean, array or object are decoded as such, any other cell is a string and empty
+//	cells are left out of the context. Errors are returned if the header cannot be read
+func NewCSVReader(r io.Reader) (DatasetReader, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	return &csvReader{r: reader, header: header}, nil
+}
+
+// Next implements DatasetReader
+func (r *csvReader) Next() (map[string]interface{}, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("record %d: %w", r.record+1, err)
+	}
+	r.record++
+	b := NewContextBuilder()
+	for i, cell := range row {
+		if cell == "" {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(cell), &value); err != nil || value == nil {
+			value = cell
+		}
+		b.Set(r.header[i], value)
+	}
+	ctx, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("record %d: %w", r.record, err)
+	}
+	return ctx, nil
+}
@@ -0,0 +1,123 @@
+package ruleengine
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// readAll returns every context of the dataset
+func readAll(dataset DatasetReader) ([]map[string]interface{}, error) {
+	records := make([]map[string]interface{}, 0)
+	for {
+		ctx, err := dataset.Next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, ctx)
+	}
+}
+
+func TestDatasetReaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		reader  func(data string) (DatasetReader, error)
+		data    string
+		want    []map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "success - jsonl",
+			reader: func(data string) (DatasetReader, error) { return NewJSONLReader(strings.NewReader(data)), nil },
+			data:   "{\"user\": {\"age\": 21}}\n\n{\"user\": {\"age\": 15, \"tags\": [\"a\"]}}\n",
+			want: []map[string]interface{}{
+				{"user": map[string]interface{}{"age": 21.0}},
+				{"user": map[string]interface{}{"age": 15.0, "tags": []interface{}{"a"}}},
+			},
+		},
+		{
+			name:   "success - csv with typed cells",
+			reader: func(data string) (DatasetReader, error) { return NewCSVReader(strings.NewReader(data)) },
+			data:   "user.age,user.email,user.verified,request.tags,user.note\n21,ada@example.com,true,\"[\"\"a\"\"]\",\n007,null,false,,\"\"\"quoted\"\"\"\n",
+			want: []map[string]interface{}{
+				{
+					"user":    map[string]interface{}{"age": 21.0, "email": "ada@example.com", "verified": true},
+					"request": map[string]interface{}{"tags": []interface{}{"a"}},
+				},
+				{
+					"user": map[string]interface{}{"age": "007", "email": "null", "verified": false, "note": "quoted"},
+				},
+			},
+		},
+		{
+			name:    "fail - invalid jsonl",
+			reader:  func(data string) (DatasetReader, error) { return NewJSONLReader(strings.NewReader(data)), nil },
+			data:    "{\"user\": {}}\n{\n",
+			wantErr: true,
+		},
+		{
+			name:    "fail - csv row with too many cells",
+			reader:  func(data string) (DatasetReader, error) { return NewCSVReader(strings.NewReader(data)) },
+			data:    "user.age\n21,22\n",
+			wantErr: true,
+		},
+		{
+			name:    "fail - empty csv",
+			reader:  func(data string) (DatasetReader, error) { return NewCSVReader(strings.NewReader(data)) },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataset, err := tt.reader(tt.data)
+			if err == nil {
+				var got []map[string]interface{}
+				got, err = readAll(dataset)
+				if !tt.wantErr {
+					if diff := cmp.Diff(got, tt.want); diff != "" {
+						t.Errorf("Next() (-got +want):\n%s", diff)
+					}
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDatasetReaders_SameContexts(t *testing.T) {
+	jsonl, err := os.Open("./testdata/simulation.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonl.Close()
+	csvFile, err := os.Open("./testdata/simulation.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer csvFile.Close()
+	csvReader, err := NewCSVReader(csvFile)
+	if err != nil {
+		t.Fatalf("NewCSVReader() error = %v", err)
+	}
+
+	fromJSONL, err := readAll(NewJSONLReader(jsonl))
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	fromCSV, err := readAll(csvReader)
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if diff := cmp.Diff(fromCSV, fromJSONL); diff != "" {
+		t.Errorf("CSV and JSONL contexts differ (-csv +jsonl):\n%s", diff)
+	}
+}
@@ -0,0 +1,191 @@
+package ruleengine
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Decision is a ruleset result recorded for a subject, e.g. a user or an account under investigation
+type Decision struct {
+	// Subject identifies who or what the decision was made about
+	Subject string
+	// Time is when the decision was recorded
+	Time time.Time
+	// Result is the result of the ruleset evaluation
+	Result RulesetResult
+}
+
+// DefaultDecisionLogSubjects is the number of subjects a DecisionLog keeps, see SetMaxSubjects
+const DefaultDecisionLogSubjects = 10000
+
+// DecisionLog keeps the most recent decisions of each subject in memory, for case investigation tooling
+//
+//	It is safe for concurrent use. Only the last decisions of a subject are kept, older ones are dropped as new ones
+//	are recorded. Up to DefaultDecisionLogSubjects subjects are kept, recording a decision for a new subject beyond
+//	them drops every decision of the subject recorded least recently, see SetMaxSubjects
+type DecisionLog struct {
+	limit int
+
+	mu          sync.Mutex
+	maxSubjects int
+	lru         *list.List
+	subjects    map[string]*list.Element
+	now         func() time.Time
+}
+
+// subjectDecisions are the decisions kept for a subject, the value of the DecisionLog lru elements
+type subjectDecisions struct {
+	subject   string
+	decisions []Decision
+}
+
+// NewDecisionLog creates a decision log keeping the last limit decisions of each subject
+func NewDecisionLog(limit int) *DecisionLog {
+	if limit < 1 {
+		limit = 1
+	}
+	return &DecisionLog{
+		limit:       limit,
+		maxSubjects: DefaultDecisionLogSubjects,
+		lru:         list.New(),
+		subjects:    make(map[string]*list.Element),
+		now:         time.Now,
+	}
+}
+
+// SetMaxSubjects bounds the number of subjects decisions are kept of, dropping those recorded least recently beyond
+// n, at least one subject is kept
+func (l *DecisionLog) SetMaxSubjects(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxSubjects = max(n, 1)
+	l.evict()
+}
+
+// Record adds the result to the decisions of the subject, dropping the oldest decision once the limit is reached and
+// the subject recorded least recently once the number of subjects is
+func (l *DecisionLog) Record(subject string, result RulesetResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.subjects[subject]
+	if ok {
+		l.lru.MoveToFront(el)
+	} else {
+		el = l.lru.PushFront(&subjectDecisions{subject: subject})
+		l.subjects[subject] = el
+	}
+	entry := el.Value.(*subjectDecisions)
+	decisions := append(entry.decisions, Decision{Subject: subject, Time: l.now().UTC(), Result: result})
+	if len(decisions) > l.limit {
+		decisions = append(decisions[:0:0], decisions[len(decisions)-l.limit:]...)
+	}
+	entry.decisions = decisions
+	l.evict()
+}
+
+// evict drops the subjects recorded least recently beyond the maximum number of subjects
+func (l *DecisionLog) evict() {
+	for l.lru.Len() > l.maxSubjects {
+		el := l.lru.Back()
+		l.lru.Remove(el)
+		delete(l.subjects, el.Value.(*subjectDecisions).subject)
+	}
+}
+
+// Last returns up to the last n decisions of the subject, oldest first, every decision kept if n is not positive
+func (l *DecisionLog) Last(subject string, n int) []Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var decisions []Decision
+	if el, ok := l.subjects[subject]; ok {
+		decisions = el.Value.(*subjectDecisions).decisions
+	}
+	if n > 0 && n < len(decisions) {
+		decisions = decisions[len(decisions)-n:]
+	}
+	return append(make([]Decision, 0, len(decisions)), decisions...)
+}
+
+// WriteDecisionGraph renders decisions as a Graphviz DOT graph, a timeline of ruleset nodes linked to their rules
+//
+//	Ruleset and rule nodes are labelled and coloured by outcome, consecutive decisions are linked by dashed edges.
+//	Render with e.g. `dot -Tsvg`
+func WriteDecisionGraph(w io.Writer, decisions []Decision) error {
+	if _, err := fmt.Fprintln(w, "digraph decisions {\n  rankdir=LR;\n  node [shape=box, style=filled];"); err != nil {
+		return err
+	}
+	for i, d := range decisions {
+		node := fmt.Sprintf("d%d", i)
+		label := fmt.Sprintf("%s\n%s\n%s", d.Result.RulesetName, d.Time.Format(time.RFC3339), rulesetOutcome(d.Result))
+		if _, err := fmt.Fprintf(w, "  %s [label=%s, fillcolor=%s];\n", node, strconv.Quote(label), outcomeColor(d.Result.Passed, d.Result.Skipped)); err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintf(w, "  d%d -> %s [style=dashed];\n", i-1, node); err != nil {
+				return err
+			}
+		}
+		names := make([]string, 0, len(d.Result.RuleResults))
+		for name := range d.Result.RuleResults {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for j, name := range names {
+			rule := d.Result.RuleResults[name]
+			ruleNode := fmt.Sprintf("%s_r%d", node, j)
+			label := name + "\n" + ruleOutcome(rule)
+			if _, err := fmt.Fprintf(w, "  %s [label=%s, shape=ellipse, fillcolor=%s];\n  %s -> %s;\n", ruleNode, strconv.Quote(label), outcomeColor(rule.Passed, rule.Skipped), node, ruleNode); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// rulesetOutcome describes the outcome of a ruleset result, e.g. "failed (degraded)"
+func rulesetOutcome(result RulesetResult) string {
+	outcome := "failed"
+	switch {
+	case result.Skipped:
+		outcome = "skipped"
+	case result.Passed:
+		outcome = "passed"
+	}
+	if result.Degraded {
+		outcome += " (degraded)"
+	}
+	return outcome
+}
+
+// ruleOutcome describes the outcome of a rule result, with its reason code if any
+func ruleOutcome(result RuleResult) string {
+	outcome := "failed"
+	switch {
+	case result.Skipped:
+		outcome = "skipped"
+	case result.Passed:
+		outcome = "passed"
+	}
+	if result.ReasonCode != "" {
+		outcome += " " + result.ReasonCode
+	}
+	return outcome
+}
+
+// outcomeColor returns the fill colour of a node by outcome
+func outcomeColor(passed, skipped bool) string {
+	switch {
+	case skipped:
+		return "lightgrey"
+	case passed:
+		return "palegreen"
+	default:
+		return "lightpink"
+	}
+}
@@ -0,0 +1,176 @@
+package ruleengine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecisionLog_Last(t *testing.T) {
+	log := NewDecisionLog(2)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	log.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	log.Record("u1", RulesetResult{RulesetName: "kyc"})
+	log.Record("u1", RulesetResult{RulesetName: "kyc", Passed: true})
+	log.Record("u2", RulesetResult{RulesetName: "kyc"})
+	log.Record("u1", RulesetResult{RulesetName: "payments"})
+
+	tests := []struct {
+		name    string
+		subject string
+		n       int
+		want    []Decision
+	}{
+		{
+			name:    "success - oldest decision dropped",
+			subject: "u1",
+			want: []Decision{
+				{Subject: "u1", Time: time.Date(2026, 1, 2, 3, 6, 5, 0, time.UTC), Result: RulesetResult{RulesetName: "kyc", Passed: true}},
+				{Subject: "u1", Time: time.Date(2026, 1, 2, 3, 8, 5, 0, time.UTC), Result: RulesetResult{RulesetName: "payments"}},
+			},
+		},
+		{
+			name:    "success - last decision",
+			subject: "u1",
+			n:       1,
+			want: []Decision{
+				{Subject: "u1", Time: time.Date(2026, 1, 2, 3, 8, 5, 0, time.UTC), Result: RulesetResult{RulesetName: "payments"}},
+			},
+		},
+		{
+			name:    "success - other subject",
+			subject: "u2",
+			n:       5,
+			want: []Decision{
+				{Subject: "u2", Time: time.Date(2026, 1, 2, 3, 7, 5, 0, time.UTC), Result: RulesetResult{RulesetName: "kyc"}},
+			},
+		},
+		{
+			name:    "success - unknown subject",
+			subject: "u3",
+			want:    []Decision{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := log.Last(tt.subject, tt.n)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Last() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecisionLog_SetMaxSubjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxSubjects int
+		subjects    []string
+		want        []string
+	}{
+		{
+			name:        "success - least recently recorded subject dropped",
+			maxSubjects: 2,
+			subjects:    []string{"u1", "u2", "u1", "u3"},
+			want:        []string{"u1", "u3"},
+		},
+		{
+			name:        "success - every subject kept within the bound",
+			maxSubjects: 3,
+			subjects:    []string{"u1", "u2", "u3"},
+			want:        []string{"u1", "u2", "u3"},
+		},
+		{
+			name:        "success - at least one subject kept",
+			maxSubjects: 0,
+			subjects:    []string{"u1", "u2"},
+			want:        []string{"u2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := NewDecisionLog(2)
+			log.SetMaxSubjects(tt.maxSubjects)
+			for _, subject := range tt.subjects {
+				log.Record(subject, RulesetResult{RulesetName: "kyc"})
+			}
+			var got []string
+			for _, subject := range []string{"u1", "u2", "u3"} {
+				if len(log.Last(subject, 0)) > 0 {
+					got = append(got, subject)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("subjects kept mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecisionLog_SetMaxSubjectsEvictsKept(t *testing.T) {
+	log := NewDecisionLog(1)
+	for _, subject := range []string{"u1", "u2", "u3"} {
+		log.Record(subject, RulesetResult{RulesetName: "kyc"})
+	}
+	log.SetMaxSubjects(1)
+	if got := len(log.Last("u2", 0)); got != 0 {
+		t.Errorf("Last(u2) = %d decisions, want none after lowering the bound", got)
+	}
+	if got := len(log.Last("u3", 0)); got != 1 {
+		t.Errorf("Last(u3) = %d decisions, want 1", got)
+	}
+}
+
+func TestWriteDecisionGraph(t *testing.T) {
+	decisions := []Decision{
+		{
+			Subject: "u1",
+			Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Result: RulesetResult{
+				RulesetName: "kyc",
+				Degraded:    true,
+				RuleResults: map[string]RuleResult{
+					"identity_verified": {RuleName: "identity_verified", ReasonCode: "KYC_002"},
+					"age_validation":    {RuleName: "age_validation", Passed: true},
+				},
+			},
+		},
+		{
+			Subject: "u1",
+			Time:    time.Date(2026, 1, 2, 4, 4, 5, 0, time.UTC),
+			Result: RulesetResult{
+				RulesetName: "kyc",
+				Passed:      true,
+				RuleResults: map[string]RuleResult{
+					"age_validation": {RuleName: "age_validation", Skipped: true},
+				},
+			},
+		},
+	}
+	want := `digraph decisions {
+  rankdir=LR;
+  node [shape=box, style=filled];
+  d0 [label="kyc\n2026-01-02T03:04:05Z\nfailed (degraded)", fillcolor=lightpink];
+  d0_r0 [label="age_validation\npassed", shape=ellipse, fillcolor=palegreen];
+  d0 -> d0_r0;
+  d0_r1 [label="identity_verified\nfailed KYC_002", shape=ellipse, fillcolor=lightpink];
+  d0 -> d0_r1;
+  d1 [label="kyc\n2026-01-02T04:04:05Z\npassed", fillcolor=palegreen];
+  d0 -> d1 [style=dashed];
+  d1_r0 [label="age_validation\nskipped", shape=ellipse, fillcolor=lightgrey];
+  d1 -> d1_r0;
+}
+`
+	var got strings.Builder
+	if err := WriteDecisionGraph(&got, decisions); err != nil {
+		t.Fatalf("WriteDecisionGraph() error = %v", err)
+	}
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("WriteDecisionGraph() mismatch (-want +got):\n%s", diff)
+	}
+}
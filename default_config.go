@@ -0,0 +1,22 @@
+package ruleengine
+
+// DefaultConfigUsed is emitted when an engine is built from the WithDefaultConfig config, e.g. because the config file
+// is missing or fails to compile
+type DefaultConfigUsed struct {
+	// Err is why the primary config was rejected
+	Err error
+}
+
+// EventName implements Event
+func (DefaultConfigUsed) EventName() string {
+	return "default_config_used"
+}
+
+// DefaultConfigErr returns why the primary config was rejected when the engine runs in degraded mode on the
+// WithDefaultConfig config, nil when the primary config is used
+func (re *RuleEngine) DefaultConfigErr() error {
+	if active := re.Active(); active != re {
+		return active.DefaultConfigErr()
+	}
+	return re.defaultConfigErr
+}
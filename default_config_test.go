@@ -0,0 +1,77 @@
+package ruleengine
+
+import (
+	_ "embed"
+	"testing"
+)
+
+//go:embed testdata/default_rules.yml
+var defaultRules []byte
+
+func TestBuilder_WithDefaultConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		configPath  string
+		defaults    []byte
+		wantDefault bool
+		wantErr     bool
+	}{
+		{
+			name:       "success - primary config",
+			configPath: "./testdata/default_rules.yml",
+			defaults:   []byte("not: [valid"),
+		},
+		{
+			name:        "success - missing config falls back",
+			configPath:  "./testdata/missing.yml",
+			defaults:    defaultRules,
+			wantDefault: true,
+		},
+		{
+			name:        "success - invalid config falls back",
+			configPath:  "./testdata/bad_rules.yml",
+			defaults:    defaultRules,
+			wantDefault: true,
+		},
+		{
+			name:       "fail - invalid default config",
+			configPath: "./testdata/missing.yml",
+			defaults:   []byte("not: [valid"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make([]Event, 0)
+			re, err := NewBuilder().
+				WithConfigFile(tt.configPath).
+				WithDefaultConfig(tt.defaults).
+				WithVariables("user").
+				WithOptions(WithEventHandler(func(event Event) { events = append(events, event) })).
+				Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := re.DefaultConfigErr() != nil; got != tt.wantDefault {
+				t.Errorf("DefaultConfigErr() = %v, want default %v", re.DefaultConfigErr(), tt.wantDefault)
+			}
+			used, ok := DefaultConfigUsed{}, len(events) == 1
+			if ok {
+				used, ok = events[0].(DefaultConfigUsed)
+			}
+			if got := ok && used.Err == re.DefaultConfigErr(); got != tt.wantDefault {
+				t.Errorf("events = %v, want DefaultConfigUsed %v", events, tt.wantDefault)
+			}
+			result, err := re.EvaluateRuleset("user_registration", WithEvalContext(map[string]interface{}{"user": map[string]interface{}{}}))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if result.Passed {
+				t.Errorf("EvaluateRuleset() passed, want the default config denying")
+			}
+		})
+	}
+}
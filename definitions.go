@@ -0,0 +1,74 @@
+package ruleengine
+
+import "slices"
+
+// GetRule returns the effective definition of the named rule, false if the engine has no such rule
+//
+//	The definition is the one the engine runs: environment overrides are applied, so a disabled rule is not found, and
+//	ErrorMessage is the message reported when the rule does not pass, inline or from
+//	error_handling.custom_error_messages. The returned rule is a copy, modifying it does not affect the engine
+func (re *RuleEngine) GetRule(name string) (Rule, bool) {
+	if active := re.Active(); active != re {
+		return active.GetRule(name)
+	}
+	rule, ok := re.config.Rules[name]
+	if !ok {
+		return Rule{}, false
+	}
+	rule.ErrorMessage = re.config.ErrorHandling.CustomErrorMessages[name]
+	if rule.Retry != nil {
+		retry := *rule.Retry
+		rule.Retry = &retry
+	}
+	return rule, true
+}
+
+// GetRuleset returns the effective definition of the named ruleset, false if the engine has no such ruleset
+//
+//	As GetRule, environment overrides are applied, so Rules lists only the rules left after disabled_rules and a
+//	ruleset not in enabled_rulesets is not found. Vetoes and Optional hold only rules of Rules. The returned ruleset
+//	is a copy
+func (re *RuleEngine) GetRuleset(name string) (Ruleset, bool) {
+	if active := re.Active(); active != re {
+		return active.GetRuleset(name)
+	}
+	ruleset, ok := re.config.Rulesets[name]
+	if !ok {
+		return Ruleset{}, false
+	}
+	ruleset.ErrorMessage = re.config.ErrorHandling.CustomErrorMessages[name]
+	ruleset.Rules = slices.Clone(ruleset.Rules)
+	ruleset.Vetoes = memberRules(ruleset.Vetoes, ruleset.Rules)
+	ruleset.Optional = memberRules(ruleset.Optional, ruleset.Rules)
+	return ruleset, true
+}
+
+// memberRules returns the names which are also in rules, e.g. the vetoes of a ruleset left after disabled_rules
+func memberRules(names, rules []string) []string {
+	if names == nil {
+		return nil
+	}
+	members := make([]string, 0, len(names))
+	for _, name := range names {
+		if slices.Contains(rules, name) {
+			members = append(members, name)
+		}
+	}
+	return members
+}
+
+// RuleNames returns the names of the rules the engine runs in sorted order
+func (re *RuleEngine) RuleNames() []string {
+	if active := re.Active(); active != re {
+		return active.RuleNames()
+	}
+	return re.ruleNames()
+}
+
+// RulesetNames returns the names of the rulesets the engine runs in sorted order
+func (re *RuleEngine) RulesetNames() []string {
+	if active := re.Active(); active != re {
+		return active.RulesetNames()
+	}
+	return re.rulesetNames()
+}
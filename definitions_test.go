@@ -0,0 +1,146 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// definitionsEngine builds an engine whose production environment disables a rule and overrides a message
+func definitionsEngine(t *testing.T) *RuleEngine {
+	t.Helper()
+	re, err := NewBuilder().WithConfig(&RulesetConfig{
+		Rules: map[string]Rule{
+			"age_validation": {Name: "Age Validation", Expression: "user.age >= 18", ErrorMessage: "too young"},
+			"sanctions":      {Expression: "!user.sanctioned", Retry: &RetryPolicy{MaxAttempts: 2, Backoff: "10ms"}},
+			"beta_access":    {Expression: "user.beta"},
+		},
+		Rulesets: map[string]Ruleset{
+			"signup": {
+				Selector: selectorOr,
+				Rules:    []string{"age_validation", "sanctions", "beta_access"},
+				Vetoes:   []string{"sanctions"},
+				Optional: []string{"beta_access"},
+			},
+		},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling: ErrorHandling{
+			ExecutionPolicy:     "collect_all",
+			CustomErrorMessages: map[string]string{"signup": "signup not allowed"},
+		},
+		Environments: map[string]Environment{
+			"production": {
+				ErrorHandling: ErrorHandling{
+					CustomErrorMessages: map[string]string{"age_validation": "you must be 18 or older"},
+				},
+				DisabledRules: []string{"beta_access"},
+			},
+		},
+	}).WithEnvironment("production").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	return re
+}
+
+func TestRuleEngine_GetRule(t *testing.T) {
+	re := definitionsEngine(t)
+	tests := []struct {
+		name     string
+		ruleName string
+		want     Rule
+		wantOK   bool
+	}{
+		{
+			name:     "success - environment error message",
+			ruleName: "age_validation",
+			want:     Rule{Name: "Age Validation", Expression: "user.age >= 18", ErrorMessage: "you must be 18 or older"},
+			wantOK:   true,
+		},
+		{
+			name:     "success - retry policy",
+			ruleName: "sanctions",
+			want:     Rule{Expression: "!user.sanctioned", Retry: &RetryPolicy{MaxAttempts: 2, Backoff: "10ms"}},
+			wantOK:   true,
+		},
+		{
+			name:     "fail - disabled rule",
+			ruleName: "beta_access",
+		},
+		{
+			name:     "fail - unknown rule",
+			ruleName: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := re.GetRule(tt.ruleName)
+			if ok != tt.wantOK {
+				t.Fatalf("GetRule() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GetRule() (-want +got):\n%s", diff)
+			}
+			if got.Retry != nil {
+				got.Retry.MaxAttempts = 10
+				if again, _ := re.GetRule(tt.ruleName); again.Retry.MaxAttempts != 2 {
+					t.Errorf("GetRule() returned the engine's retry policy")
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEngine_GetRuleset(t *testing.T) {
+	re := definitionsEngine(t)
+	tests := []struct {
+		name        string
+		rulesetName string
+		want        Ruleset
+		wantOK      bool
+	}{
+		{
+			name:        "success - disabled rule removed",
+			rulesetName: "signup",
+			want: Ruleset{
+				Selector:     selectorOr,
+				Rules:        []string{"age_validation", "sanctions"},
+				Vetoes:       []string{"sanctions"},
+				Optional:     []string{},
+				ErrorMessage: "signup not allowed",
+			},
+			wantOK: true,
+		},
+		{
+			name:        "fail - unknown ruleset",
+			rulesetName: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := re.GetRuleset(tt.rulesetName)
+			if ok != tt.wantOK {
+				t.Fatalf("GetRuleset() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GetRuleset() (-want +got):\n%s", diff)
+			}
+			if len(got.Rules) > 0 {
+				got.Rules[0] = "modified"
+				if again, _ := re.GetRuleset(tt.rulesetName); again.Rules[0] == "modified" {
+					t.Errorf("GetRuleset() returned the engine's rules")
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEngine_RuleNames(t *testing.T) {
+	re := definitionsEngine(t)
+	if diff := cmp.Diff([]string{"age_validation", "sanctions"}, re.RuleNames()); diff != "" {
+		t.Errorf("RuleNames() (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"signup"}, re.RulesetNames()); diff != "" {
+		t.Errorf("RulesetNames() (-want +got):\n%s", diff)
+	}
+}
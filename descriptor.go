@@ -0,0 +1,94 @@
+package ruleengine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Types registers protobuf message types with CEL from a compiled FileDescriptorSet, so rules can use typed
+// variables without building the CEL env in Go
+//
+//	types:
+//	  descriptor_file: api.pb # protoc --include_imports --descriptor_set_out=api.pb api.proto
+//	  messages: [acme.User]
+//	  variables:
+//	    user: acme.User
+//
+// Messages built from the descriptors are *dynamicpb.Message values, contexts may hold those or the generated Go
+// messages of the same types. Variables declared here take precedence over Builder.WithVariables
+type Types struct {
+	// DescriptorFile is the path of a binary FileDescriptorSet, relative to the config file it is loaded from
+	DescriptorFile string `yaml:"descriptor_file"`
+	// Messages are the fully qualified message types the rules use, each must be in the descriptor set
+	Messages []string `yaml:"messages"`
+	// Variables maps context variables to the fully qualified message type they are declared as
+	Variables map[string]string `yaml:"variables"`
+}
+
+// resolveTypes makes the descriptor file of the config relative to the directory of the config file at path
+func (rc *RulesetConfig) resolveTypes(path string) {
+	if rc.Types == nil || rc.Types.DescriptorFile == "" || path == "" || filepath.IsAbs(rc.Types.DescriptorFile) {
+		return
+	}
+	rc.Types.DescriptorFile = filepath.Join(filepath.Dir(path), rc.Types.DescriptorFile)
+}
+
+// envOptions loads the descriptor set and returns the options registering its types and declaring the variables
+func (t *Types) envOptions() ([]cel.EnvOption, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if t.DescriptorFile == "" {
+		return nil, fmt.Errorf("types require a descriptor_file")
+	}
+	data, err := os.ReadFile(t.DescriptorFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor file: %w", err)
+	}
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &fds); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor file '%s': %w", t.DescriptorFile, err)
+	}
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor file '%s': %w", t.DescriptorFile, err)
+	}
+	findMessage := func(name string) error {
+		desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return fmt.Errorf("message '%s' not found in descriptor file '%s'", name, t.DescriptorFile)
+		}
+		if _, ok := desc.(protoreflect.MessageDescriptor); !ok {
+			return fmt.Errorf("'%s' in descriptor file '%s' is not a message", name, t.DescriptorFile)
+		}
+		return nil
+	}
+	for _, name := range t.Messages {
+		if err := findMessage(name); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []cel.EnvOption{cel.TypeDescs(files)}
+	variables := make([]string, 0, len(t.Variables))
+	for name := range t.Variables {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+	for _, name := range variables {
+		message := t.Variables[name]
+		if err := findMessage(message); err != nil {
+			return nil, fmt.Errorf("variable '%s': %w", name, err)
+		}
+		opts = append(opts, cel.Variable(name, cel.ObjectType(message)))
+	}
+	return opts, nil
+}
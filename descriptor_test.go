@@ -0,0 +1,172 @@
+package ruleengine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newUserDescriptorSet returns a FileDescriptorSet declaring the acme.User message
+func newUserDescriptorSet() *descriptorpb.FileDescriptorSet {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+	}
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("acme/user.proto"),
+		Package: proto.String("acme"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("email", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("age", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+			},
+		}},
+	}}}
+}
+
+func TestTypes_DescriptorFile(t *testing.T) {
+	fds := newUserDescriptorSet()
+	data, err := proto.Marshal(fds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "api.pb"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "invalid.pb"), []byte("not a descriptor"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	const config = `
+rules:
+  age_validation:
+    expression: %s
+rulesets:
+  registration:
+    rules: [age_validation]
+execution_policies:
+  collect_all: {}
+error_handling:
+  execution_policy: collect_all
+types:
+  descriptor_file: %s
+  messages: [%s]
+  variables:
+    user: %s
+`
+	tests := []struct {
+		name       string
+		expression string
+		descriptor string
+		message    string
+		variable   string
+		wantErr    string
+	}{
+		{
+			name:       "success - typed variable",
+			expression: "user.age >= 18 && user.email.endsWith('@example.com')",
+			descriptor: "api.pb",
+			message:    "acme.User",
+			variable:   "acme.User",
+		},
+		{
+			name:       "fail - unknown field",
+			expression: "user.name == 'x'",
+			descriptor: "api.pb",
+			message:    "acme.User",
+			variable:   "acme.User",
+			wantErr:    "undefined field 'name'",
+		},
+		{
+			name:       "fail - unknown message",
+			expression: "true",
+			descriptor: "api.pb",
+			message:    "acme.Account",
+			variable:   "acme.User",
+			wantErr:    "message 'acme.Account' not found",
+		},
+		{
+			name:       "fail - variable is not a message",
+			expression: "true",
+			descriptor: "api.pb",
+			message:    "acme.User",
+			variable:   "acme.User.age",
+			wantErr:    "variable 'user': 'acme.User.age'",
+		},
+		{
+			name:       "fail - missing descriptor file",
+			expression: "true",
+			descriptor: "missing.pb",
+			message:    "acme.User",
+			variable:   "acme.User",
+			wantErr:    "failed to read descriptor file",
+		},
+		{
+			name:       "fail - invalid descriptor file",
+			expression: "true",
+			descriptor: "invalid.pb",
+			message:    "acme.User",
+			variable:   "acme.User",
+			wantErr:    "failed to parse descriptor file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "rules.yml")
+			content := fmt.Sprintf(config, strconv.Quote(tt.expression), tt.descriptor, tt.message, tt.variable)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			// user is declared by the config types, the dynamic declaration is ignored
+			re, err := NewBuilder().WithConfigFile(path).WithVariables("user").Build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Build() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+
+			files, err := protodesc.NewFiles(fds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			desc, err := files.FindDescriptorByName("acme.User")
+			if err != nil {
+				t.Fatal(err)
+			}
+			md := desc.(protoreflect.MessageDescriptor)
+			for age, want := range map[int64]bool{20: true, 15: false} {
+				user := dynamicpb.NewMessage(md)
+				user.Set(md.Fields().ByName("age"), protoreflect.ValueOfInt64(age))
+				user.Set(md.Fields().ByName("email"), protoreflect.ValueOfString("a@example.com"))
+				got, err := re.EvaluateRuleset("registration", WithEvalContext(map[string]interface{}{"user": user}))
+				if err != nil {
+					t.Fatalf("EvaluateRuleset() error = %v", err)
+				}
+				if got.Passed != want {
+					t.Errorf("EvaluateRuleset() age %d passed = %v, want %v", age, got.Passed, want)
+				}
+			}
+		})
+	}
+}
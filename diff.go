@@ -0,0 +1,136 @@
+package ruleengine
+
+import (
+	"sort"
+	"time"
+)
+
+// ResultDiff is the structured difference between two evaluations of a ruleset, e.g. shadow vs enforced
+type ResultDiff struct {
+	// RulesetName is the name of the compared ruleset
+	RulesetName string
+	// PassedA is the outcome of the first result
+	PassedA bool
+	// PassedB is the outcome of the second result
+	PassedB bool
+	// ErrorA is the error message of the first result, empty if none
+	ErrorA string
+	// ErrorB is the error message of the second result, empty if none
+	ErrorB string
+	// DurationDelta is the duration of the second result minus the duration of the first
+	DurationDelta time.Duration
+	// Rules contains the per-rule differences of every rule present in either result, sorted by rule name
+	Rules []RuleDiff
+}
+
+// RuleDiff is the difference between two evaluations of a single rule
+type RuleDiff struct {
+	// RuleName is the name of the compared rule
+	RuleName string
+	// InA indicates the rule was evaluated in the first result
+	InA bool
+	// InB indicates the rule was evaluated in the second result
+	InB bool
+	// PassedA is the outcome of the rule in the first result
+	PassedA bool
+	// PassedB is the outcome of the rule in the second result
+	PassedB bool
+	// ErrorA is the error message of the rule in the first result, empty if none
+	ErrorA string
+	// ErrorB is the error message of the rule in the second result, empty if none
+	ErrorB string
+	// DurationDelta is the duration of the rule in the second result minus the duration in the first
+	DurationDelta time.Duration
+}
+
+// OutcomeChanged reports whether the ruleset outcome differs
+func (d ResultDiff) OutcomeChanged() bool {
+	return d.PassedA != d.PassedB
+}
+
+// ErrorChanged reports whether the ruleset error message differs
+func (d ResultDiff) ErrorChanged() bool {
+	return d.ErrorA != d.ErrorB
+}
+
+// Changed reports whether the ruleset or any of its rules changed outcome or error
+func (d ResultDiff) Changed() bool {
+	return d.OutcomeChanged() || d.ErrorChanged() || len(d.ChangedRules()) > 0
+}
+
+// ChangedRules returns the rules which changed outcome or error, or were only evaluated in one result
+func (d ResultDiff) ChangedRules() []RuleDiff {
+	changed := make([]RuleDiff, 0)
+	for _, rd := range d.Rules {
+		if rd.Changed() {
+			changed = append(changed, rd)
+		}
+	}
+	return changed
+}
+
+// OutcomeChanged reports whether the rule outcome differs
+func (d RuleDiff) OutcomeChanged() bool {
+	return d.PassedA != d.PassedB
+}
+
+// ErrorChanged reports whether the rule error message differs
+func (d RuleDiff) ErrorChanged() bool {
+	return d.ErrorA != d.ErrorB
+}
+
+// Changed reports whether the rule changed outcome or error, or was only evaluated in one result
+func (d RuleDiff) Changed() bool {
+	return d.InA != d.InB || d.OutcomeChanged() || d.ErrorChanged()
+}
+
+// DiffResults compares two results of the same ruleset, e.g. from a shadow and an enforced config or a replay
+func DiffResults(a, b RulesetResult) ResultDiff {
+	diff := ResultDiff{
+		RulesetName:   a.RulesetName,
+		PassedA:       a.Passed,
+		PassedB:       b.Passed,
+		ErrorA:        errorString(a.Error),
+		ErrorB:        errorString(b.Error),
+		DurationDelta: b.Duration - a.Duration,
+	}
+	if diff.RulesetName == "" {
+		diff.RulesetName = b.RulesetName
+	}
+
+	names := make(map[string]bool, len(a.RuleResults)+len(b.RuleResults))
+	for name := range a.RuleResults {
+		names[name] = true
+	}
+	for name := range b.RuleResults {
+		names[name] = true
+	}
+
+	diff.Rules = make([]RuleDiff, 0, len(names))
+	for name := range names {
+		ra, inA := a.RuleResults[name]
+		rb, inB := b.RuleResults[name]
+		diff.Rules = append(diff.Rules, RuleDiff{
+			RuleName:      name,
+			InA:           inA,
+			InB:           inB,
+			PassedA:       ra.Passed,
+			PassedB:       rb.Passed,
+			ErrorA:        errorString(ra.Error),
+			ErrorB:        errorString(rb.Error),
+			DurationDelta: rb.Duration - ra.Duration,
+		})
+	}
+	sort.Slice(diff.Rules, func(i, j int) bool {
+		return diff.Rules[i].RuleName < diff.Rules[j].RuleName
+	})
+	return diff
+}
+
+// errorString returns the message of err, or an empty string if err is nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,94 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffResults(t *testing.T) {
+	enforced := RulesetResult{
+		RulesetName: "user_registration",
+		Passed:      true,
+		Duration:    10 * time.Millisecond,
+		RuleResults: map[string]RuleResult{
+			"age_validation": {RuleName: "age_validation", Passed: true, Duration: 2 * time.Millisecond},
+			"email_format":   {RuleName: "email_format", Passed: true, Duration: 3 * time.Millisecond},
+			"user_status":    {RuleName: "user_status", Passed: true, Duration: 1 * time.Millisecond},
+		},
+	}
+	shadow := RulesetResult{
+		RulesetName: "user_registration",
+		Passed:      false,
+		Error:       errors.New("ruleset 'user_registration' did not pass evaluation"),
+		Duration:    15 * time.Millisecond,
+		RuleResults: map[string]RuleResult{
+			"age_validation": {
+				RuleName: "age_validation",
+				Passed:   false,
+				Error:    errors.New("user must be at least 18 years old"),
+				Duration: 2 * time.Millisecond,
+			},
+			"email_format": {RuleName: "email_format", Passed: true, Duration: 5 * time.Millisecond},
+			"user_tier":    {RuleName: "user_tier", Passed: true, Duration: 1 * time.Millisecond},
+		},
+	}
+
+	got := DiffResults(enforced, shadow)
+	want := ResultDiff{
+		RulesetName:   "user_registration",
+		PassedA:       true,
+		PassedB:       false,
+		ErrorB:        "ruleset 'user_registration' did not pass evaluation",
+		DurationDelta: 5 * time.Millisecond,
+		Rules: []RuleDiff{
+			{
+				RuleName: "age_validation",
+				InA:      true,
+				InB:      true,
+				PassedA:  true,
+				ErrorB:   "user must be at least 18 years old",
+			},
+			{
+				RuleName:      "email_format",
+				InA:           true,
+				InB:           true,
+				PassedA:       true,
+				PassedB:       true,
+				DurationDelta: 2 * time.Millisecond,
+			},
+			{
+				RuleName:      "user_status",
+				InA:           true,
+				PassedA:       true,
+				DurationDelta: -1 * time.Millisecond,
+			},
+			{
+				RuleName:      "user_tier",
+				InB:           true,
+				PassedB:       true,
+				DurationDelta: 1 * time.Millisecond,
+			},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("DiffResults() (-got +want):\n%s", diff)
+	}
+	if !got.Changed() || !got.OutcomeChanged() || !got.ErrorChanged() {
+		t.Errorf("DiffResults() expected ruleset change")
+	}
+
+	changed := make([]string, 0)
+	for _, rd := range got.ChangedRules() {
+		changed = append(changed, rd.RuleName)
+	}
+	if diff := cmp.Diff(changed, []string{"age_validation", "user_status", "user_tier"}); diff != "" {
+		t.Errorf("ChangedRules() (-got +want):\n%s", diff)
+	}
+
+	if same := DiffResults(enforced, enforced); same.Changed() {
+		t.Errorf("DiffResults() of identical results reported a change: %+v", same)
+	}
+}
@@ -0,0 +1,287 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoDrafts is returned when committing drafts without any pending change
+var ErrNoDrafts = errors.New("no pending drafts")
+
+// DraftChange is a pending change of Drafts
+type DraftChange struct {
+	// Kind is "rule" or "ruleset"
+	Kind string
+	// Name is the name of the changed rule or ruleset
+	Name string
+	// Deleted indicates the rule or ruleset is removed, otherwise it is created or replaced
+	Deleted bool
+}
+
+// AuditEntry records a change made through Drafts
+type AuditEntry struct {
+	// Time is when the change was made
+	Time time.Time
+	// Author is who made the change, as given by the caller
+	Author string
+	// Action is one of "put_rule", "delete_rule", "put_ruleset", "delete_ruleset", "discard", "commit" or "reject"
+	Action string
+	// Name is the name of the rule or ruleset changed, empty for discard, commit and reject
+	Name string
+	// Message describes a commit, or why it was rejected
+	Message string
+	// Changes are the changes committed or rejected, empty for other actions
+	Changes []DraftChange
+}
+
+// Drafts stages rule and ruleset changes made at runtime, e.g. from a rule management UI, until they are committed
+// to the active config of an engine
+//
+//	Drafts are layered over the active config: they can be validated and test-run in isolation without affecting
+//	live evaluations, and Commit atomically activates them, see RuleEngine.Promote. Every change is recorded in the
+//	audit trail. Drafts is safe for concurrent use
+type Drafts struct {
+	engine *RuleEngine
+
+	mu       sync.Mutex
+	rules    map[string]*Rule
+	rulesets map[string]*Ruleset
+	audit    []AuditEntry
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// NewDrafts creates an empty set of drafts committed to the engine
+func NewDrafts(engine *RuleEngine) *Drafts {
+	return &Drafts{
+		engine:   engine,
+		rules:    make(map[string]*Rule),
+		rulesets: make(map[string]*Ruleset),
+		now:      time.Now,
+	}
+}
+
+// PutRule drafts the creation or replacement of a rule
+//
+//	Errors are returned if the name or expression is empty, see Validate for the checks of the complete config
+func (d *Drafts) PutRule(author, name string, rule Rule) error {
+	if name == "" {
+		return errors.New("rule name must not be empty")
+	}
+	if rule.Expression == "" {
+		return fmt.Errorf("rule '%s' has no expression", name)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rules[name] = &rule
+	d.record(AuditEntry{Author: author, Action: "put_rule", Name: name})
+	return nil
+}
+
+// DeleteRule drafts the removal of a rule, a draft of a rule not in the active config as given is dropped
+//
+//	Errors are returned if the rule is neither drafted nor in the active config as given, rules the environment
+//	disables are in it
+func (d *Drafts) DeleteRule(author, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, active := d.engine.Active().base.Rules[name]
+	draft, drafted := d.rules[name]
+	if !active && (!drafted || draft == nil) {
+		return fmt.Errorf("rule '%s' not found", name)
+	}
+	if active {
+		d.rules[name] = nil
+	} else {
+		delete(d.rules, name)
+	}
+	d.record(AuditEntry{Author: author, Action: "delete_rule", Name: name})
+	return nil
+}
+
+// PutRuleset drafts the creation or replacement of a ruleset
+//
+//	Errors are returned if the name is empty or the ruleset has no rules
+func (d *Drafts) PutRuleset(author, name string, ruleset Ruleset) error {
+	if name == "" {
+		return errors.New("ruleset name must not be empty")
+	}
+	if len(ruleset.Rules) == 0 {
+		return fmt.Errorf("ruleset '%s' has no rules", name)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rulesets[name] = &ruleset
+	d.record(AuditEntry{Author: author, Action: "put_ruleset", Name: name})
+	return nil
+}
+
+// DeleteRuleset drafts the removal of a ruleset, a draft of a ruleset not in the active config as given is dropped
+//
+//	Errors are returned if the ruleset is neither drafted nor in the active config as given, rulesets the environment
+//	does not enable are in it
+func (d *Drafts) DeleteRuleset(author, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, active := d.engine.Active().base.Rulesets[name]
+	draft, drafted := d.rulesets[name]
+	if !active && (!drafted || draft == nil) {
+		return fmt.Errorf("ruleset '%s' not found", name)
+	}
+	if active {
+		d.rulesets[name] = nil
+	} else {
+		delete(d.rulesets, name)
+	}
+	d.record(AuditEntry{Author: author, Action: "delete_ruleset", Name: name})
+	return nil
+}
+
+// Pending returns the pending changes, rules first, each sorted by name
+func (d *Drafts) Pending() []DraftChange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pending()
+}
+
+// pending returns the pending changes, the lock must be held
+func (d *Drafts) pending() []DraftChange {
+	changes := make([]DraftChange, 0, len(d.rules)+len(d.rulesets))
+	for _, name := range sortedNames(d.rules) {
+		changes = append(changes, DraftChange{Kind: "rule", Name: name, Deleted: d.rules[name] == nil})
+	}
+	for _, name := range sortedNames(d.rulesets) {
+		changes = append(changes, DraftChange{Kind: "ruleset", Name: name, Deleted: d.rulesets[name] == nil})
+	}
+	return changes
+}
+
+// Discard drops every pending change
+func (d *Drafts) Discard(author string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rules = make(map[string]*Rule)
+	d.rulesets = make(map[string]*Ruleset)
+	d.record(AuditEntry{Author: author, Action: "discard"})
+}
+
+// Validate builds the active config with the drafts applied, returning why it fails to build, e.g. CompileErrors
+func (d *Drafts) Validate() error {
+	_, err := d.build()
+	return err
+}
+
+// TestRule evaluates a rule of the active config with the drafts applied, without affecting live evaluations
+//
+//	Errors are returned if the config fails to build or the rule is not found
+func (d *Drafts) TestRule(name string, opts ...EvalOption) (RuleResult, error) {
+	engine, err := d.build()
+	if err != nil {
+		return RuleResult{}, err
+	}
+	return engine.EvaluateRule(name, opts...)
+}
+
+// TestRuleset evaluates a ruleset of the active config with the drafts applied, without affecting live evaluations
+//
+//	Errors are returned if the config fails to build or the ruleset is not found
+func (d *Drafts) TestRuleset(name string, opts ...EvalOption) (RulesetResult, error) {
+	engine, err := d.build()
+	if err != nil {
+		return RulesetResult{}, err
+	}
+	return engine.EvaluateRuleset(name, opts...)
+}
+
+// Commit atomically activates the active config with the drafts applied and clears the drafts, emitting a
+// ConfigReloaded event
+//
+//	Errors are returned if there is no pending change, the config fails to build or the WithChangeGate gate rejects
+//	it, the drafts are then kept and the active config keeps serving. Rejections are recorded in the audit trail
+func (d *Drafts) Commit(author, message string) (AuditEntry, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	changes := d.pending()
+	if len(changes) == 0 {
+		return AuditEntry{}, ErrNoDrafts
+	}
+	engine, err := d.candidate()
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	req := ChangeRequest{Action: "commit", Author: author, Message: message, Changes: changes, Config: engine.config}
+	if err := d.engine.approve(req); err != nil {
+		d.record(AuditEntry{Author: author, Action: "reject", Message: err.Error(), Changes: changes})
+		return AuditEntry{}, err
+	}
+	d.engine.reloaded("commit", d.engine.swap(engine), engine)
+	d.rules = make(map[string]*Rule)
+	d.rulesets = make(map[string]*Ruleset)
+	return d.record(AuditEntry{Author: author, Action: "commit", Message: message, Changes: changes}), nil
+}
+
+// Audit returns the audit trail of the changes made, oldest first
+func (d *Drafts) Audit() []AuditEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append(make([]AuditEntry, 0, len(d.audit)), d.audit...)
+}
+
+// build builds the candidate engine
+func (d *Drafts) build() (*RuleEngine, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.candidate()
+}
+
+// candidate builds an engine from the active config with the drafts applied, the lock must be held
+//
+//	The drafts are applied to the config as given, the engine applies its overrides again when building
+func (d *Drafts) candidate() (*RuleEngine, error) {
+	config := d.engine.Active().base.clone()
+	for name, rule := range d.rules {
+		if rule == nil {
+			delete(config.Rules, name)
+			continue
+		}
+		if config.Rules == nil {
+			config.Rules = make(map[string]Rule)
+		}
+		config.Rules[name] = *rule
+	}
+	for name, ruleset := range d.rulesets {
+		if ruleset == nil {
+			delete(config.Rulesets, name)
+			continue
+		}
+		if config.Rulesets == nil {
+			config.Rulesets = make(map[string]Ruleset)
+		}
+		config.Rulesets[name] = *ruleset
+	}
+	engine, err := d.engine.candidate(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid drafts: %w", err)
+	}
+	return engine, nil
+}
+
+// record appends the entry to the audit trail, the lock must be held
+func (d *Drafts) record(entry AuditEntry) AuditEntry {
+	entry.Time = d.now().UTC()
+	d.audit = append(d.audit, entry)
+	return entry
+}
+
+// sortedNames returns the keys of m in sorted order
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
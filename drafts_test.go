@@ -0,0 +1,173 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDrafts(t *testing.T) {
+	re, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	drafts := NewDrafts(re)
+	drafts.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	ctx := WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 19, "verified": false}})
+
+	if _, err := drafts.Commit("alice", "nothing"); !errors.Is(err, ErrNoDrafts) {
+		t.Errorf("Commit() error = %v, want %v", err, ErrNoDrafts)
+	}
+	if err := drafts.PutRule("alice", "verified", Rule{}); err == nil {
+		t.Error("PutRule() error = nil, want an error without an expression")
+	}
+	if err := drafts.DeleteRule("alice", "unknown"); err == nil {
+		t.Error("DeleteRule() error = nil, want an error for an unknown rule")
+	}
+	if err := drafts.PutRuleset("alice", "empty", Ruleset{}); err == nil {
+		t.Error("PutRuleset() error = nil, want an error without rules")
+	}
+
+	if err := drafts.PutRule("alice", "verified", Rule{Expression: "user.verified"}); err != nil {
+		t.Fatalf("PutRule() error = %v", err)
+	}
+	if err := drafts.PutRuleset("alice", "adult", Ruleset{Selector: "AND", Rules: []string{"age_validation", "verified"}}); err != nil {
+		t.Fatalf("PutRuleset() error = %v", err)
+	}
+	if err := drafts.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	draft, err := drafts.TestRuleset("adult", ctx)
+	if err != nil {
+		t.Fatalf("TestRuleset() error = %v", err)
+	}
+	if draft.Passed {
+		t.Error("TestRuleset() passed = true, want the drafted ruleset to fail")
+	}
+	live, err := re.EvaluateRuleset("adult", ctx)
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if !live.Passed {
+		t.Error("EvaluateRuleset() passed = false, drafts must not affect live evaluations")
+	}
+
+	if err := drafts.PutRule("bob", "broken", Rule{Expression: "user.age >="}); err != nil {
+		t.Fatalf("PutRule() error = %v", err)
+	}
+	if err := drafts.Validate(); err == nil {
+		t.Error("Validate() error = nil, want a compile error")
+	}
+	if _, err := drafts.Commit("bob", "broken"); err == nil {
+		t.Error("Commit() error = nil, want a compile error")
+	}
+	if err := drafts.DeleteRule("bob", "broken"); err != nil {
+		t.Fatalf("DeleteRule() error = %v", err)
+	}
+
+	wantChanges := []DraftChange{
+		{Kind: "rule", Name: "verified"},
+		{Kind: "ruleset", Name: "adult"},
+	}
+	if diff := cmp.Diff(wantChanges, drafts.Pending()); diff != "" {
+		t.Errorf("Pending() mismatch (-want +got):\n%s", diff)
+	}
+	entry, err := drafts.Commit("alice", "require verification")
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if diff := cmp.Diff(wantChanges, entry.Changes); diff != "" {
+		t.Errorf("Commit() changes mismatch (-want +got):\n%s", diff)
+	}
+	if len(drafts.Pending()) != 0 {
+		t.Errorf("Pending() = %v, want none after Commit()", drafts.Pending())
+	}
+	live, err = re.EvaluateRuleset("adult", ctx)
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if live.Passed {
+		t.Error("EvaluateRuleset() passed = true, want the committed ruleset to fail")
+	}
+
+	// Drafts are layered over the committed config
+	if err := drafts.DeleteRule("carol", "verified"); err != nil {
+		t.Fatalf("DeleteRule() error = %v", err)
+	}
+	if diff := cmp.Diff([]DraftChange{{Kind: "rule", Name: "verified", Deleted: true}}, drafts.Pending()); diff != "" {
+		t.Errorf("Pending() mismatch (-want +got):\n%s", diff)
+	}
+	drafts.Discard("carol")
+
+	wantAudit := []AuditEntry{
+		{Author: "alice", Action: "put_rule", Name: "verified"},
+		{Author: "alice", Action: "put_ruleset", Name: "adult"},
+		{Author: "bob", Action: "put_rule", Name: "broken"},
+		{Author: "bob", Action: "delete_rule", Name: "broken"},
+		{Author: "alice", Action: "commit", Message: "require verification", Changes: wantChanges},
+		{Author: "carol", Action: "delete_rule", Name: "verified"},
+		{Author: "carol", Action: "discard"},
+	}
+	if diff := cmp.Diff(wantAudit, drafts.Audit(), cmpopts.IgnoreFields(AuditEntry{}, "Time")); diff != "" {
+		t.Errorf("Audit() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDrafts_CommitAppliesOverridesOnce(t *testing.T) {
+	config := rolloutConfig("user.age >= 18")
+	config.GlobalsMerge = GlobalsMerge{Deep: true, Lists: ListAppend}
+	config.Globals = map[string]interface{}{"countries": []interface{}{"AU"}}
+	config.Environments = map[string]Environment{
+		"production": {Globals: map[string]interface{}{"countries": []interface{}{"NZ"}}},
+	}
+	re, err := NewBuilder().WithConfig(config).WithEnvironment("production").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	drafts := NewDrafts(re)
+	for _, expression := range []string{"user.age >= 21", "user.age >= 18"} {
+		if err := drafts.PutRule("alice", "age_validation", Rule{Expression: expression}); err != nil {
+			t.Fatalf("PutRule() error = %v", err)
+		}
+		if _, err := drafts.Commit("alice", "change age"); err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+	}
+	want := []interface{}{"AU", "NZ"}
+	if diff := cmp.Diff(want, re.Active().config.Globals["countries"]); diff != "" {
+		t.Errorf("globals after Commit() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDrafts_DeleteChecksConfigAsGiven(t *testing.T) {
+	config := rolloutConfig("user.age >= 18")
+	config.Rules["verified"] = Rule{Expression: "user.verified"}
+	config.Rulesets["verified"] = Ruleset{Selector: "AND", Rules: []string{"verified"}}
+	config.Environments = map[string]Environment{
+		"production": {EnabledRulesets: []string{"adult"}, DisabledRules: []string{"verified"}},
+	}
+	re, err := NewBuilder().WithConfig(config).WithEnvironment("production").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	drafts := NewDrafts(re)
+	if err := drafts.DeleteRuleset("alice", "verified"); err != nil {
+		t.Fatalf("DeleteRuleset() error = %v for a ruleset the environment does not enable", err)
+	}
+	if err := drafts.DeleteRule("alice", "verified"); err != nil {
+		t.Fatalf("DeleteRule() error = %v for a rule the environment disables", err)
+	}
+	if _, err := drafts.Commit("alice", "drop verified"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	base := re.Active().base
+	if _, ok := base.Rules["verified"]; ok {
+		t.Error("rule 'verified' still in the config after Commit()")
+	}
+	if _, ok := base.Rulesets["verified"]; ok {
+		t.Error("ruleset 'verified' still in the config after Commit()")
+	}
+}
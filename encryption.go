@@ -0,0 +1,75 @@
+package ruleengine
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// encryptedMagic prefixes every encrypted config or bundle, followed by the format version and the nonce
+const encryptedMagic = "CELRULESENC"
+
+// encryptedVersion is the current encryption format version
+const encryptedVersion = 1
+
+// ErrDecryption is returned when an encrypted config cannot be decrypted, e.g. the key is wrong or the data was modified
+var ErrDecryption = errors.New("failed to decrypt config")
+
+// EncryptConfig encrypts a YAML config or a bundle with AES-GCM, the result is loaded with Builder.WithDecryptionKey
+//
+//	key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256. A random nonce is generated for every
+//	call, so encrypting the same data twice gives different results
+func EncryptConfig(data, key []byte) ([]byte, error) {
+	aead, err := newConfigCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(encryptedMagic)+1+aead.NonceSize())
+	copy(header, encryptedMagic)
+	header[len(encryptedMagic)] = encryptedVersion
+	nonce := header[len(encryptedMagic)+1:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The header is authenticated so the version and nonce cannot be altered either
+	return aead.Seal(header, nonce, data, header), nil
+}
+
+// IsEncryptedConfig reports whether data was created by EncryptConfig
+func IsEncryptedConfig(data []byte) bool {
+	return len(data) > len(encryptedMagic) && string(data[:len(encryptedMagic)]) == encryptedMagic
+}
+
+// decryptConfig decrypts data created by EncryptConfig
+func decryptConfig(data, key []byte) ([]byte, error) {
+	aead, err := newConfigCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncryptedConfig(data) {
+		return nil, errors.New("config is not encrypted")
+	}
+	if version := data[len(encryptedMagic)]; version != encryptedVersion {
+		return nil, fmt.Errorf("unsupported encryption version %d, want %d", version, encryptedVersion)
+	}
+	header := len(encryptedMagic) + 1 + aead.NonceSize()
+	if len(data) < header+aead.Overhead() {
+		return nil, fmt.Errorf("%w: data is truncated", ErrDecryption)
+	}
+	plaintext, err := aead.Open(nil, data[len(encryptedMagic)+1:header], data[header:], data[:header])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+	return plaintext, nil
+}
+
+// newConfigCipher creates the AES-GCM cipher for key
+func newConfigCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,136 @@
+package ruleengine
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuilder_WithDecryptionKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	plain, err := os.ReadFile("./testdata/rules.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptConfig(plain, key)
+	if err != nil {
+		t.Fatalf("EncryptConfig() error = %v", err)
+	}
+	if bytes.Contains(encrypted, []byte("user_registration")) {
+		t.Fatalf("EncryptConfig() output contains plaintext")
+	}
+	dir := t.TempDir()
+	encryptedPath := filepath.Join(dir, "rules.yml.enc")
+	if err := os.WriteFile(encryptedPath, encrypted, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	bundle, err := engine.MarshalBundle()
+	if err != nil {
+		t.Fatalf("MarshalBundle() error = %v", err)
+	}
+	encryptedBundle, err := EncryptConfig(bundle, key)
+	if err != nil {
+		t.Fatalf("EncryptConfig() error = %v", err)
+	}
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name      string
+		builder   *Builder
+		wantErr   bool
+		wantErrIs error
+	}{
+		{
+			name:    "success - encrypted config file",
+			builder: NewBuilder().WithConfigFile(encryptedPath).WithDecryptionKey(key),
+		},
+		{
+			name:    "success - encrypted bundle",
+			builder: NewBuilder().WithBundle(encryptedBundle).WithDecryptionKey(key),
+		},
+		{
+			name:      "fail - wrong key",
+			builder:   NewBuilder().WithConfigFile(encryptedPath).WithDecryptionKey(bytes.Repeat([]byte{8}, 32)),
+			wantErr:   true,
+			wantErrIs: ErrDecryption,
+		},
+		{
+			name:      "fail - tampered bundle",
+			builder:   NewBuilder().WithBundle(tampered).WithDecryptionKey(key),
+			wantErr:   true,
+			wantErrIs: ErrDecryption,
+		},
+		{
+			name:    "fail - plaintext config file",
+			builder: NewBuilder().WithConfigFile("./testdata/rules.yml").WithDecryptionKey(key),
+			wantErr: true,
+		},
+		{
+			name:    "fail - invalid key length",
+			builder: NewBuilder().WithConfigFile(encryptedPath).WithDecryptionKey([]byte("short")),
+			wantErr: true,
+		},
+		{
+			name:    "fail - key with loaded config",
+			builder: NewBuilder().WithConfig(&RulesetConfig{}).WithDecryptionKey(key),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := tt.builder.WithVariables("user", "request").Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("Build() error = %v, want %v", err, tt.wantErrIs)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := re.EvaluateRuleset("user_registration", WithEvalContext(map[string]interface{}{
+				"user": map[string]interface{}{"age": 20, "email": "a@example.com"},
+			})); err != nil {
+				t.Errorf("EvaluateRuleset() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestEncryptConfig(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	first, err := EncryptConfig([]byte("rules: {}"), key)
+	if err != nil {
+		t.Fatalf("EncryptConfig() error = %v", err)
+	}
+	second, err := EncryptConfig([]byte("rules: {}"), key)
+	if err != nil {
+		t.Fatalf("EncryptConfig() error = %v", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Errorf("EncryptConfig() twice gave the same output, want a fresh nonce")
+	}
+	if !IsEncryptedConfig(first) || IsEncryptedConfig([]byte("rules: {}")) {
+		t.Errorf("IsEncryptedConfig() did not tell encrypted and plaintext configs apart")
+	}
+	got, err := decryptConfig(first, key)
+	if err != nil {
+		t.Fatalf("decryptConfig() error = %v", err)
+	}
+	if string(got) != "rules: {}" {
+		t.Errorf("decryptConfig() = %q, want %q", got, "rules: {}")
+	}
+	if _, err := decryptConfig(first[:20], key); !errors.Is(err, ErrDecryption) {
+		t.Errorf("decryptConfig() truncated error = %v, want %v", err, ErrDecryption)
+	}
+	if _, err := EncryptConfig([]byte("rules: {}"), []byte("short")); err == nil {
+		t.Errorf("EncryptConfig() invalid key error = nil, want error")
+	}
+}
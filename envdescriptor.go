@@ -0,0 +1,187 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/decls"
+)
+
+// ErrEnvMismatch is returned when a CEL env lacks declarations an EnvDescriptor requires
+var ErrEnvMismatch = errors.New("cel env does not satisfy the descriptor")
+
+// EnvDescriptor is a machine-readable description of the variables and functions a host CEL env declares, see
+// RuleEngine.EnvDescriptor
+//
+//	It serializes to JSON or YAML, so a config can ship with the env it was written against and hosts fail fast with
+//	WithEnvDescriptor instead of on the first rule using a missing declaration
+type EnvDescriptor struct {
+	// Variables are the variables declared on top of the CEL standard library, sorted by name
+	Variables []VariableDescriptor `json:"variables,omitempty" yaml:"variables,omitempty"`
+	// Functions are the functions declared on top of the CEL standard library, sorted by name
+	Functions []FunctionDescriptor `json:"functions,omitempty" yaml:"functions,omitempty"`
+}
+
+// VariableDescriptor describes a declared variable
+type VariableDescriptor struct {
+	Name string `json:"name" yaml:"name"`
+	// Type is the CEL type of the variable, e.g. `map(string, dyn)`
+	Type string `json:"type" yaml:"type"`
+}
+
+// FunctionDescriptor describes a declared function and its overloads
+type FunctionDescriptor struct {
+	Name string `json:"name" yaml:"name"`
+	// Overloads are the signatures of the function, sorted by ID
+	Overloads []OverloadDescriptor `json:"overloads" yaml:"overloads"`
+}
+
+// OverloadDescriptor describes the signature of a function overload
+type OverloadDescriptor struct {
+	ID string `json:"id" yaml:"id"`
+	// Member is whether the overload is called on its first argument, e.g. `ts.is_weekend()`
+	Member bool `json:"member,omitempty" yaml:"member,omitempty"`
+	// Args are the CEL types of the arguments, including the receiver of member overloads
+	Args   []string `json:"args" yaml:"args"`
+	Result string   `json:"result" yaml:"result"`
+}
+
+// WithEnvDescriptor verifies the host CEL env satisfies descriptor before any rule is compiled
+//
+//	The engine fails to build with ErrEnvMismatch listing every missing or differently typed declaration
+func WithEnvDescriptor(descriptor EnvDescriptor) Option {
+	return func(re *RuleEngine) {
+		re.envDescriptor = &descriptor
+	}
+}
+
+// EnvDescriptor describes the host CEL env the engine was created with, the variables and functions it expects
+// every host to declare
+//
+//	Declarations added by the engine itself, e.g. the geo functions, config functions and typed variables, and the
+//	CEL standard library are left out
+func (re *RuleEngine) EnvDescriptor() EnvDescriptor {
+	return DescribeEnv(re.baseEnv)
+}
+
+// DescribeEnv describes the variables and functions env declares on top of the CEL standard library
+func DescribeEnv(env *cel.Env) EnvDescriptor {
+	standardVariables, standardOverloads := standardLibrary()
+	var descriptor EnvDescriptor
+	for _, v := range env.Variables() {
+		if standardVariables[v.Name()] {
+			continue
+		}
+		descriptor.Variables = append(descriptor.Variables, VariableDescriptor{Name: v.Name(), Type: v.Type().String()})
+	}
+	for name, fn := range env.Functions() {
+		function := FunctionDescriptor{Name: name}
+		for _, overload := range fn.OverloadDecls() {
+			if _, ok := standardOverloads[name+"/"+overload.ID()]; !ok {
+				function.Overloads = append(function.Overloads, describeOverload(overload))
+			}
+		}
+		if len(function.Overloads) > 0 {
+			sort.Slice(function.Overloads, func(i, j int) bool { return function.Overloads[i].ID < function.Overloads[j].ID })
+			descriptor.Functions = append(descriptor.Functions, function)
+		}
+	}
+	sort.Slice(descriptor.Variables, func(i, j int) bool { return descriptor.Variables[i].Name < descriptor.Variables[j].Name })
+	sort.Slice(descriptor.Functions, func(i, j int) bool { return descriptor.Functions[i].Name < descriptor.Functions[j].Name })
+	return descriptor
+}
+
+// Verify checks env declares every variable and function overload of the descriptor with the same types
+//
+//	env may declare more. Errors wrap ErrEnvMismatch and join one error per missing or differently typed declaration
+func (d EnvDescriptor) Verify(env *cel.Env) error {
+	if env == nil {
+		return fmt.Errorf("%w: cel env is nil", ErrEnvMismatch)
+	}
+	actual := DescribeEnv(env)
+	variables := make(map[string]string, len(actual.Variables))
+	for _, v := range actual.Variables {
+		variables[v.Name] = v.Type
+	}
+	// Standard overloads count as declared, a descriptor may list them
+	_, standardOverloads := standardLibrary()
+	overloads := maps.Clone(standardOverloads)
+	for _, fn := range actual.Functions {
+		for _, overload := range fn.Overloads {
+			overloads[fn.Name+"/"+overload.ID] = overload
+		}
+	}
+
+	var errs []error
+	for _, v := range d.Variables {
+		typ, ok := variables[v.Name]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("variable '%s' of type %s is not declared", v.Name, v.Type))
+		case typ != v.Type:
+			errs = append(errs, fmt.Errorf("variable '%s' is declared as %s, want %s", v.Name, typ, v.Type))
+		}
+	}
+	for _, fn := range d.Functions {
+		for _, want := range fn.Overloads {
+			got, ok := overloads[fn.Name+"/"+want.ID]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("function '%s' overload %s is not declared", fn.Name, want))
+			case got.String() != want.String():
+				errs = append(errs, fmt.Errorf("function '%s' overload %s is declared as %s", fn.Name, want, got))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrEnvMismatch, errors.Join(errs...))
+	}
+	return nil
+}
+
+// String formats the signature of the overload, e.g. `is_weekend_timestamp(timestamp) -> bool`
+func (o OverloadDescriptor) String() string {
+	receiver := ""
+	args := o.Args
+	if o.Member && len(args) > 0 {
+		receiver, args = args[0]+".", args[1:]
+	}
+	return fmt.Sprintf("%s%s(%s) -> %s", receiver, o.ID, strings.Join(args, ", "), o.Result)
+}
+
+// describeOverload describes the signature of a function overload
+func describeOverload(overload *decls.OverloadDecl) OverloadDescriptor {
+	args := make([]string, 0, len(overload.ArgTypes()))
+	for _, arg := range overload.ArgTypes() {
+		args = append(args, arg.String())
+	}
+	return OverloadDescriptor{
+		ID:     overload.ID(),
+		Member: overload.IsMemberFunction(),
+		Args:   args,
+		Result: overload.ResultType().String(),
+	}
+}
+
+// standardLibrary returns the variables of the CEL standard library, e.g. the `int` type, and its overloads keyed
+// by function name and overload ID, e.g. `size/size_string`
+var standardLibrary = sync.OnceValues(func() (map[string]bool, map[string]OverloadDescriptor) {
+	variables := make(map[string]bool)
+	overloads := make(map[string]OverloadDescriptor)
+	// The standard env is always valid
+	env, _ := cel.NewEnv()
+	for _, v := range env.Variables() {
+		variables[v.Name()] = true
+	}
+	for name, fn := range env.Functions() {
+		for _, overload := range fn.OverloadDecls() {
+			overloads[name+"/"+overload.ID()] = describeOverload(overload)
+		}
+	}
+	return variables, overloads
+})
@@ -0,0 +1,126 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/go-cmp/cmp"
+)
+
+// descriptorEnv creates a CEL env declaring user and the is_weekend function, with the extra options given
+func descriptorEnv(t *testing.T, opts ...cel.EnvOption) *cel.Env {
+	t.Helper()
+	env, err := cel.NewEnv(append([]cel.EnvOption{
+		cel.Variable("user", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Function("is_weekend",
+			cel.MemberOverload("timestamp_is_weekend", []*cel.Type{cel.TimestampType}, cel.BoolType,
+				cel.UnaryBinding(func(ref.Val) ref.Val { return types.False }),
+			),
+		),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() error = %v", err)
+	}
+	return env
+}
+
+func TestDescribeEnv(t *testing.T) {
+	want := EnvDescriptor{
+		Variables: []VariableDescriptor{{Name: "user", Type: "map(string, dyn)"}},
+		Functions: []FunctionDescriptor{{
+			Name: "is_weekend",
+			Overloads: []OverloadDescriptor{
+				{ID: "timestamp_is_weekend", Member: true, Args: []string{"google.protobuf.Timestamp"}, Result: "bool"},
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, DescribeEnv(descriptorEnv(t))); diff != "" {
+		t.Errorf("DescribeEnv() (-want +got):\n%s", diff)
+	}
+}
+
+func TestEnvDescriptor_Verify(t *testing.T) {
+	descriptor := DescribeEnv(descriptorEnv(t))
+	tests := []struct {
+		name    string
+		env     func(t *testing.T) *cel.Env
+		wantMsg string
+	}{
+		{
+			name: "success - same env",
+			env:  func(t *testing.T) *cel.Env { return descriptorEnv(t) },
+		},
+		{
+			name: "success - more declarations",
+			env: func(t *testing.T) *cel.Env {
+				return descriptorEnv(t, cel.Variable("request", cel.DynType))
+			},
+		},
+		{
+			name: "fail - missing declarations",
+			env: func(t *testing.T) *cel.Env {
+				env, _ := cel.NewEnv()
+				return env
+			},
+			wantMsg: "cel env does not satisfy the descriptor: variable 'user' of type map(string, dyn) is not declared\n" +
+				"function 'is_weekend' overload google.protobuf.Timestamp.timestamp_is_weekend() -> bool is not declared",
+		},
+		{
+			name: "fail - different types",
+			env: func(t *testing.T) *cel.Env {
+				env, _ := cel.NewEnv(
+					cel.Variable("user", cel.DynType),
+					cel.Function("is_weekend",
+						cel.MemberOverload("timestamp_is_weekend", []*cel.Type{cel.TimestampType}, cel.IntType,
+							cel.UnaryBinding(func(ref.Val) ref.Val { return types.Int(0) }),
+						),
+					),
+				)
+				return env
+			},
+			wantMsg: "cel env does not satisfy the descriptor: variable 'user' is declared as dyn, want map(string, dyn)\n" +
+				"function 'is_weekend' overload google.protobuf.Timestamp.timestamp_is_weekend() -> bool is declared as " +
+				"google.protobuf.Timestamp.timestamp_is_weekend() -> int",
+		},
+		{
+			name:    "fail - nil env",
+			env:     func(t *testing.T) *cel.Env { return nil },
+			wantMsg: "cel env does not satisfy the descriptor: cel env is nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := descriptor.Verify(tt.env(t))
+			if tt.wantMsg == "" {
+				if err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrEnvMismatch) || err.Error() != tt.wantMsg {
+				t.Errorf("Verify() error = %v, want %s", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWithEnvDescriptor(t *testing.T) {
+	config := rolloutConfig("user.age >= 18")
+	re, err := NewBuilder().WithConfig(config).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	descriptor := re.EnvDescriptor()
+	if _, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").
+		WithOptions(WithEnvDescriptor(descriptor)).Build(); err != nil {
+		t.Errorf("Build() error = %v", err)
+	}
+	_, err = NewBuilder().WithConfig(rolloutConfig("true")).WithVariables("request").
+		WithOptions(WithEnvDescriptor(descriptor)).Build()
+	if !errors.Is(err, ErrEnvMismatch) {
+		t.Errorf("Build() error = %v, want %v", err, ErrEnvMismatch)
+	}
+}
@@ -0,0 +1,56 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownEnvironment is returned by ValidateEnvironments when the selected environment is not in the config
+var ErrUnknownEnvironment = errors.New("unknown environment")
+
+// ValidateEnvironments checks that the environment exists and that every environment override references rules,
+// rulesets and execution policies of the config
+//
+//	An empty environment selects no overrides and is always valid. Every environment is checked, not only the selected
+//	one, so a typo fails in development before it reaches production. Call it before ApplyEnvironment, which removes
+//	disabled rules. Engines check it when the config sets strict_environments
+func (rc *RulesetConfig) ValidateEnvironments(environment string) error {
+	var errs []error
+	if _, ok := rc.Environments[environment]; environment != "" && !ok {
+		errs = append(errs, fmt.Errorf("%w '%s'", ErrUnknownEnvironment, environment))
+	}
+	for _, name := range sortedNames(rc.Environments) {
+		if err := rc.validateEnvironment(rc.Environments[name]); err != nil {
+			errs = append(errs, fmt.Errorf("environment '%s': %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateEnvironment checks the overrides of a single environment
+func (rc *RulesetConfig) validateEnvironment(env Environment) error {
+	var errs []error
+	if policy := env.ErrorHandling.ExecutionPolicy; policy != "" {
+		if _, ok := rc.ExecutionPolicies[policy]; !ok {
+			errs = append(errs, fmt.Errorf("execution policy '%s' not found", policy))
+		}
+	}
+	for _, name := range sortedNames(env.ErrorHandling.CustomErrorMessages) {
+		_, rule := rc.Rules[name]
+		_, ruleset := rc.Rulesets[name]
+		if !rule && !ruleset {
+			errs = append(errs, fmt.Errorf("custom error message for unknown rule or ruleset '%s'", name))
+		}
+	}
+	for _, name := range env.EnabledRulesets {
+		if _, ok := rc.Rulesets[name]; !ok {
+			errs = append(errs, fmt.Errorf("enabled ruleset '%s' not found", name))
+		}
+	}
+	for _, name := range env.DisabledRules {
+		if _, ok := rc.Rules[name]; !ok {
+			errs = append(errs, fmt.Errorf("disabled rule '%s' not found", name))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,143 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+// strictConfig returns a config with strict_environments set and a development environment with the overrides
+func strictConfig(development Environment) *RulesetConfig {
+	return &RulesetConfig{
+		StrictEnvironments: true,
+		Rules: map[string]Rule{
+			"age_validation": {Expression: "user.age >= 18"},
+			"business_hours": {Expression: "true"},
+		},
+		Rulesets: map[string]Ruleset{
+			"adult": {Selector: selectorAnd, Rules: []string{"age_validation", "business_hours"}},
+		},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}, "fail_fast": {StopOnFailure: true}},
+		ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+		Environments:      map[string]Environment{"development": development},
+	}
+}
+
+func TestRulesetConfig_ValidateEnvironments(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		development Environment
+		wantErr     error
+		wantMsgs    []string
+	}{
+		{
+			name:        "success - valid overrides",
+			environment: "development",
+			development: Environment{
+				ErrorHandling: ErrorHandling{
+					ExecutionPolicy:     "fail_fast",
+					CustomErrorMessages: map[string]string{"age_validation": "too young", "adult": "not an adult"},
+				},
+				EnabledRulesets: []string{"adult"},
+				DisabledRules:   []string{"business_hours"},
+			},
+		},
+		{
+			name:        "success - no environment selected",
+			environment: "",
+		},
+		{
+			name:        "fail - unknown environment",
+			environment: "staging",
+			wantErr:     ErrUnknownEnvironment,
+			wantMsgs:    []string{"unknown environment 'staging'"},
+		},
+		{
+			name:        "fail - overrides reference unknown names",
+			environment: "",
+			development: Environment{
+				ErrorHandling: ErrorHandling{
+					ExecutionPolicy:     "fail_slow",
+					CustomErrorMessages: map[string]string{"age_check": "too young"},
+				},
+				EnabledRulesets: []string{"adults"},
+				DisabledRules:   []string{"business_hour"},
+			},
+			wantMsgs: []string{
+				"environment 'development': execution policy 'fail_slow' not found",
+				"custom error message for unknown rule or ruleset 'age_check'",
+				"enabled ruleset 'adults' not found",
+				"disabled rule 'business_hour' not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := strictConfig(tt.development).ValidateEnvironments(tt.environment)
+			if (err != nil) != (len(tt.wantMsgs) > 0) {
+				t.Fatalf("ValidateEnvironments() error = %v, want %v", err, tt.wantMsgs)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateEnvironments() error = %v, want %v", err, tt.wantErr)
+			}
+			for _, msg := range tt.wantMsgs {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("ValidateEnvironments() error = %v, want it to contain %q", err, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRuleEngine_StrictEnvironments(t *testing.T) {
+	development := Environment{DisabledRules: []string{"business_hours"}}
+	env, err := cel.NewEnv(cel.Variable("user", cel.DynType), cel.Variable("globals", cel.DynType))
+	if err != nil {
+		t.Fatalf("failed to create cel env: %v", err)
+	}
+	tests := []struct {
+		name        string
+		config      *RulesetConfig
+		environment string
+		wantErr     error
+	}{
+		{
+			name:        "success - known environment",
+			config:      strictConfig(development),
+			environment: "development",
+		},
+		{
+			name:        "success - unknown environment without strict_environments",
+			config:      &RulesetConfig{ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}}, ErrorHandling: ErrorHandling{ExecutionPolicy: "collect_all"}},
+			environment: "staging",
+		},
+		{
+			name:        "fail - unknown environment",
+			config:      strictConfig(development),
+			environment: "staging",
+			wantErr:     ErrUnknownEnvironment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := newRuleEngine(tt.config, tt.environment, env)
+			if !errors.Is(err, tt.wantErr) || (err != nil && tt.wantErr == nil) {
+				t.Fatalf("newRuleEngine() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// The bundle holds the applied overrides, loading it must not fail the validation again
+			data, err := re.MarshalBundle()
+			if err != nil {
+				t.Fatalf("MarshalBundle() error = %v", err)
+			}
+			if _, err := NewRuleEngineFromBundle(data, env); err != nil {
+				t.Errorf("NewRuleEngineFromBundle() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompileIssue is a single CEL compilation issue within an expression
+type CompileIssue struct {
+	// Message is the CEL issue message
+	Message string
+	// Line is the 1-based line of the issue within the expression
+	Line int
+	// Column is the 0-based column of the issue within the expression
+	Column int
+}
+
+// String returns the issue in `line:column: message` form
+func (i CompileIssue) String() string {
+	return fmt.Sprintf("%d:%d: %s", i.Line, i.Column, i.Message)
+}
+
+// CompileError describes why a single rule failed to compile
+type CompileError struct {
+	// RuleName is the name of the rule which failed to compile
+	RuleName string
+	// Expression is the rule expression, empty if the failure is not related to the expression
+	Expression string
+	// Issues are the CEL issues with their positions within the expression, if any
+	Issues []CompileIssue
+	// Source is the position of the expression in the YAML config, zero for configs built in code
+	Source SourcePosition
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements error
+func (e *CompileError) Error() string {
+	if e.RuleName == "" {
+		return e.Err.Error()
+	}
+	if e.Source.File != "" {
+		return fmt.Sprintf("%s: rule '%s': %v", e.Source, e.RuleName, e.Err)
+	}
+	return fmt.Sprintf("rule '%s': %v", e.RuleName, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *CompileError) Unwrap() error {
+	return e.Err
+}
+
+// CompileErrors aggregates the compile errors of every broken rule in a config
+type CompileErrors []*CompileError
+
+// Error implements error, listing every compile error on its own line
+func (e CompileErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d rule(s) failed to compile:\n%s", len(e), strings.Join(msgs, "\n"))
+}
+
+// Unwrap returns the individual compile errors
+func (e CompileErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
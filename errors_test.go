@@ -0,0 +1,90 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewRuleEngine_CompileErrors(t *testing.T) {
+	_, err := NewRuleEngine("./testdata/bad_multi_rules.yml", "", setupEnvironment()(t))
+	if err == nil {
+		t.Fatal("NewRuleEngine() expected error")
+	}
+	var compileErrs CompileErrors
+	if !errors.As(err, &compileErrs) {
+		t.Fatalf("NewRuleEngine() error = %T, want CompileErrors", err)
+	}
+
+	type summary struct {
+		RuleName   string
+		Expression string
+		Source     SourcePosition
+		Positions  [][2]int
+	}
+	got := make([]summary, 0, len(compileErrs))
+	for _, compileErr := range compileErrs {
+		s := summary{RuleName: compileErr.RuleName, Expression: compileErr.Expression, Source: compileErr.Source}
+		for _, issue := range compileErr.Issues {
+			if issue.Message == "" {
+				t.Errorf("rule '%s' issue has no message", compileErr.RuleName)
+			}
+			s.Positions = append(s.Positions, [2]int{issue.Line, issue.Column})
+		}
+		got = append(got, s)
+	}
+	want := []summary{
+		{
+			RuleName:   "age_validation",
+			Expression: "user.age >= ",
+			Source:     SourcePosition{File: "./testdata/bad_multi_rules.yml", Line: 15, Column: 17},
+			Positions:  [][2]int{{1, 12}},
+		},
+		{
+			RuleName: "orphan_validation",
+			Source:   SourcePosition{File: "./testdata/bad_multi_rules.yml", Line: 30, Column: 17},
+		},
+		{
+			RuleName:   "status_validation",
+			Expression: "unknown_variable == 'active'",
+			Source:     SourcePosition{File: "./testdata/bad_multi_rules.yml", Line: 25, Column: 17},
+			Positions:  [][2]int{{1, 0}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CompileErrors mismatch (-want +got):\n%s", diff)
+	}
+
+	// Every individual failure is reachable through the error chain
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) || compileErr.RuleName != "age_validation" {
+		t.Errorf("errors.As(*CompileError) = %v, want rule 'age_validation'", compileErr)
+	}
+}
+
+func TestBuilder_Build_CompileErrorSource(t *testing.T) {
+	_, err := NewBuilder().
+		WithConfigFile("./testdata/bad_multi_rules.yml").
+		WithCELEnv(setupEnvironment()(t)).
+		Build()
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("Build() error = %v, want *CompileError", err)
+	}
+	want := SourcePosition{File: "./testdata/bad_multi_rules.yml", Line: 15, Column: 17}
+	if compileErr.Source != want {
+		t.Errorf("Source = %v, want %v", compileErr.Source, want)
+	}
+	if got, prefix := compileErr.Error(), "./testdata/bad_multi_rules.yml:15:17: rule 'age_validation': "; !strings.HasPrefix(got, prefix) {
+		t.Errorf("Error() = %q, want prefix %q", got, prefix)
+	}
+}
+
+func TestCompileIssue_String(t *testing.T) {
+	issue := CompileIssue{Message: "undeclared reference to 'x'", Line: 2, Column: 4}
+	if got, want := issue.String(), "2:4: undeclared reference to 'x'"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
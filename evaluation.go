@@ -0,0 +1,137 @@
+package ruleengine
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/interpreter"
+)
+
+// EvalOption defines a function that configures a single evaluation call
+type EvalOption func(*evaluation)
+
+// evaluation holds the state of a single Evaluate* call
+type evaluation struct {
+	// context is the activation used to evaluate rule programs
+	context map[string]interface{}
+	// globals overlays the configured globals for this evaluation only
+	globals map[string]interface{}
+	// profile is the name of the profile whose globals apply to this evaluation only, see WithEvalProfile
+	profile string
+	// memo shares rule outcomes between rulesets, nil unless evaluating all rulesets
+	memo *ruleMemo
+	// prepared is the context given to WithPreparedContext, if any
+	prepared *PreparedContext
+	// progress receives the progress of ruleset evaluations, nil unless set with WithProgress
+	progress ProgressFunc
+	// into is the pooled result whose RuleResults map and Order slice are reused, see EvaluateRulesetInto
+	into *RulesetResult
+	// activation binds context for the programs, nil unless the prepared context is used as is
+	activation interpreter.Activation
+	// lookups caches the http_get() responses of this evaluation, nil unless enabled with WithHTTPGet
+	lookups *httpCache
+	// bound is the input with the response cache bound, set by the first input call
+	bound interface{}
+	// inherited is the context of a shadow evaluation given none, the SetContext context of the active engine
+	inherited map[string]interface{}
+}
+
+// input returns what programs are evaluated against, the prepared activation or the context, with the http_get()
+// response cache bound
+//
+//	The input is bound once and reused by every rule of the evaluation
+func (e *evaluation) input() interface{} {
+	if e.bound == nil {
+		if e.activation != nil {
+			e.bound = e.lookups.bind(e.activation)
+		} else {
+			e.bound = e.lookups.bind(e.context)
+		}
+	}
+	return e.bound
+}
+
+// WithEvalContext evaluates against ctx instead of the context set by SetContext
+//
+//	The engine state is not modified, so evaluations using WithEvalContext are safe for concurrent use
+func WithEvalContext(ctx map[string]interface{}) EvalOption {
+	return func(e *evaluation) {
+		e.context = ctx
+		e.prepared = nil
+	}
+}
+
+// WithGlobalOverrides overlays the configured globals with overrides for this evaluation only,
+// e.g. a support override raising max_retries for a single request. The overrides are merged as configured by
+// globals_merge, see GlobalsMerge. The engine state is not modified.
+func WithGlobalOverrides(overrides map[string]interface{}) EvalOption {
+	return func(e *evaluation) {
+		e.globals = overrides
+	}
+}
+
+// WithEvalProfile applies the globals of the named profile for this evaluation only, e.g. the jurisdiction of the
+// request, layered on top of the engine's globals and below WithGlobalOverrides. The engine state is not modified.
+func WithEvalProfile(name string) EvalOption {
+	return func(e *evaluation) {
+		e.profile = name
+	}
+}
+
+// newEvaluation creates the evaluation state for a single call, applying the provided options
+//
+//	Errors are returned if the profile selected with WithEvalProfile is not found or the context exceeds the
+//	WithContextLimits limits
+func (re *RuleEngine) newEvaluation(opts []EvalOption) (*evaluation, error) {
+	eval := &evaluation{lookups: re.httpGet.newCache()}
+	for _, opt := range opts {
+		opt(eval)
+	}
+	if p := eval.prepared; p != nil && p.engine == re && eval.globals == nil && eval.profile == "" {
+		eval.context, eval.activation = p.context, p.activation
+		return eval, nil
+	}
+	if err := re.validateContext(eval.context); err != nil {
+		return nil, err
+	}
+	if eval.context == nil && eval.inherited != nil {
+		// Trusted like any SetContext context, the copy below replaces the builtins of the engine it was set on
+		eval.context = eval.inherited
+	}
+	var profile map[string]interface{}
+	if eval.profile != "" {
+		p, ok := re.config.Profiles[eval.profile]
+		if !ok {
+			return nil, fmt.Errorf("profile '%s' not found in config", eval.profile)
+		}
+		profile = p.Globals
+	}
+
+	if eval.context == nil && eval.globals == nil && profile == nil {
+		eval.context = re.context
+		return eval, nil
+	}
+
+	// Copy the context so builtins and overrides never leak into the caller's or the engine's context
+	source := eval.context
+	if source == nil {
+		source = re.context
+	}
+	ctx := make(map[string]interface{}, len(source)+3)
+	for k, v := range source {
+		ctx[k] = v
+	}
+	if eval.context != nil {
+		ctx = re.withBuiltins(ctx)
+	}
+	if eval.globals != nil || profile != nil {
+		globals := make(map[string]interface{}, len(re.config.Globals)+len(profile)+len(eval.globals))
+		for k, v := range re.globals() {
+			globals[k] = v
+		}
+		re.config.GlobalsMerge.mergeInto(globals, profile)
+		re.config.GlobalsMerge.mergeInto(globals, eval.globals)
+		ctx["globals"] = globals
+	}
+	eval.context = re.withComputed(ctx)
+	return eval, nil
+}
@@ -0,0 +1,201 @@
+package ruleengine
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_WithEvalContext(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "production", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	tests := []struct {
+		name    string
+		context map[string]interface{}
+		want    bool
+	}{
+		{
+			name: "success - adult",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{
+					"age":       21,
+					"email":     "test@example.com",
+					"status":    "active",
+					"suspended": false,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "fail - minor",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{
+					"age":       15,
+					"email":     "test@example.com",
+					"status":    "active",
+					"suspended": false,
+				},
+			},
+			want: false,
+		},
+	}
+
+	// Evaluate concurrently to ensure per-call contexts never interfere with each other
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := re.EvaluateRuleset("user_registration", WithEvalContext(tt.context))
+				if err != nil {
+					t.Errorf("%s: EvaluateRuleset() error = %v", tt.name, err)
+					return
+				}
+				if got.Passed != tt.want {
+					t.Errorf("%s: EvaluateRuleset() passed = %v, want %v", tt.name, got.Passed, tt.want)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	for _, tt := range tests {
+		if _, ok := tt.context["globals"]; ok {
+			t.Errorf("%s: WithEvalContext() mutated the caller's context", tt.name)
+		}
+	}
+}
+
+func TestRuleEngine_EvaluateRule_WithGlobalOverrides(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "production", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := map[string]interface{}{
+		"request": map[string]interface{}{"attempt": 7},
+	}
+	tests := []struct {
+		name string
+		opts []EvalOption
+		want bool
+	}{
+		{
+			name: "fail - configured max_retries",
+			opts: []EvalOption{WithEvalContext(ctx)},
+			want: false,
+		},
+		{
+			name: "success - overridden max_retries",
+			opts: []EvalOption{WithEvalContext(ctx), WithGlobalOverrides(map[string]interface{}{"max_retries": 10})},
+			want: true,
+		},
+		{
+			name: "fail - override does not persist",
+			opts: []EvalOption{WithEvalContext(ctx)},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.EvaluateRule("rate_limiting", tt.opts...)
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRule() passed = %v, want %v", got.Passed, tt.want)
+			}
+		})
+	}
+
+	// Overrides also apply on top of the context set by SetContext without modifying it
+	re.SetContext(map[string]interface{}{
+		"request": map[string]interface{}{"attempt": 7},
+	})
+	got, err := re.EvaluateRule("rate_limiting", WithGlobalOverrides(map[string]interface{}{"max_retries": 10}))
+	if err != nil || !got.Passed {
+		t.Errorf("EvaluateRule() = %+v, %v, want passed", got, err)
+	}
+	got, err = re.EvaluateRule("rate_limiting")
+	if err != nil || got.Passed {
+		t.Errorf("EvaluateRule() = %+v, %v, want not passed", got, err)
+	}
+	if re.config.Globals["max_retries"] != 5 {
+		t.Errorf("WithGlobalOverrides() mutated the engine globals: %v", re.config.Globals)
+	}
+}
+
+func TestRuleEngine_EvaluateMany(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "development", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	tests := []struct {
+		name     string
+		contexts map[string]map[string]interface{}
+		opts     []EvalOption
+		want     map[string]bool
+		wantErr  bool
+	}{
+		{
+			name: "success - separate payloads",
+			contexts: map[string]map[string]interface{}{
+				"user_registration": {
+					"user": map[string]interface{}{
+						"age":       21,
+						"email":     "test@example.com",
+						"status":    "active",
+						"suspended": false,
+					},
+				},
+				"request_throttling": {
+					"user":    map[string]interface{}{"tier": "free"},
+					"request": map[string]interface{}{"attempt": 7},
+				},
+			},
+			want: map[string]bool{
+				"user_registration":  true,
+				"request_throttling": false,
+			},
+		},
+		{
+			name: "success - shared options",
+			contexts: map[string]map[string]interface{}{
+				"request_throttling": {
+					"user":    map[string]interface{}{"tier": "free"},
+					"request": map[string]interface{}{"attempt": 7},
+				},
+			},
+			opts: []EvalOption{WithGlobalOverrides(map[string]interface{}{"max_retries": 10})},
+			want: map[string]bool{
+				"request_throttling": true,
+			},
+		},
+		{
+			name: "fail - unknown ruleset",
+			contexts: map[string]map[string]interface{}{
+				"unknown": {},
+			},
+			want:    map[string]bool{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := re.EvaluateMany(tt.contexts, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateMany() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := make(map[string]bool, len(results))
+			for name, result := range results {
+				got[name] = result.Passed
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("EvaluateMany() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
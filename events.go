@@ -0,0 +1,54 @@
+package ruleengine
+
+import (
+	"time"
+)
+
+// Event is emitted by the engine on notable state changes, see WithEventHandler
+type Event interface {
+	// EventName returns the name of the event type
+	EventName() string
+}
+
+// EventHandler receives engine events, it is called synchronously and must not block
+type EventHandler func(Event)
+
+// WithEventHandler registers a handler receiving engine events
+func WithEventHandler(handler EventHandler) Option {
+	return func(re *RuleEngine) {
+		re.eventHandler = handler
+	}
+}
+
+// CircuitOpened is emitted when a rule is disabled by the circuit breaker
+type CircuitOpened struct {
+	// RuleName is the name of the disabled rule
+	RuleName string
+	// ErrorRate is the error rate which tripped the circuit breaker
+	ErrorRate float64
+	// Until is the time the rule is re-enabled
+	Until time.Time
+}
+
+// EventName implements Event
+func (CircuitOpened) EventName() string {
+	return "circuit_opened"
+}
+
+// CircuitClosed is emitted when a rule is re-enabled after its cool-down
+type CircuitClosed struct {
+	// RuleName is the name of the re-enabled rule
+	RuleName string
+}
+
+// EventName implements Event
+func (CircuitClosed) EventName() string {
+	return "circuit_closed"
+}
+
+// emit sends the event to the registered handler, if any
+func (re *RuleEngine) emit(event Event) {
+	if re.eventHandler != nil {
+		re.eventHandler(event)
+	}
+}
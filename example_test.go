@@ -0,0 +1,209 @@
+package ruleengine_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// newEnv creates the CEL environment used by the examples
+func newEnv() *cel.Env {
+	env, err := cel.NewEnv(
+		cel.Variable("user", cel.DynType),
+		cel.Variable("request", cel.DynType),
+		cel.Variable("globals", cel.DynType),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return env
+}
+
+// adult is a context passing the user_registration ruleset
+var adult = map[string]interface{}{
+	"user": map[string]interface{}{
+		"age":       21,
+		"email":     "test@example.com",
+		"status":    "active",
+		"suspended": false,
+		"tier":      "free",
+	},
+	"request": map[string]interface{}{
+		"attempt": 7,
+	},
+}
+
+func ExampleNewRuleEngine() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "production", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	engine.SetContext(map[string]interface{}{
+		"user": map[string]interface{}{"age": 15},
+	})
+	result, err := engine.EvaluateRule("age_validation")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(result.Passed, result.Error)
+	// Output: false user must be at least 18 years old
+}
+
+func ExampleRuleEngine_EvaluateRuleset() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "production", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := engine.EvaluateRuleset("user_registration", ruleengine.WithEvalContext(adult))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(result.Passed)
+	// Output: true
+}
+
+func ExampleRuleEngine_EvaluateAllRulesets() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "development", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := engine.EvaluateAllRulesets(ruleengine.WithEvalContext(adult))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(results["user_registration"].Passed, results["request_throttling"].Passed)
+	// Output: true false
+}
+
+func ExampleRuleEngine_EvaluateMany() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "development", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := engine.EvaluateMany(map[string]map[string]interface{}{
+		"user_registration":  adult,
+		"request_throttling": {"user": map[string]interface{}{"tier": "premium"}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(results["user_registration"].Passed, results["request_throttling"].Passed)
+	// Output: true true
+}
+
+func ExampleWithGlobalOverrides() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "production", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := engine.EvaluateRule("rate_limiting",
+		ruleengine.WithEvalContext(adult),
+		ruleengine.WithGlobalOverrides(map[string]interface{}{"max_retries": 10}),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(result.Passed)
+	// Output: true
+}
+
+func ExampleBuilder() {
+	engine, err := ruleengine.NewBuilder().
+		WithConfigFile("./testdata/rules.yml").
+		WithEnvironment("production").
+		WithVariables("user", "request").
+		WithOptions(ruleengine.WithOptimise()).
+		Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := engine.EvaluateRule("user_status", ruleengine.WithEvalContext(adult))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(result.Passed)
+	// Output: true
+}
+
+func ExampleContextBuilder() {
+	type User struct {
+		Age    int    `json:"age"`
+		Email  string `json:"email"`
+		Status string `json:"status"`
+	}
+	ctx, err := ruleengine.NewContextBuilder().
+		Add("user", User{Age: 21, Email: "test@example.com", Status: "active"}).
+		Set("user.suspended", false).
+		Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "production", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := engine.EvaluateRuleset("user_registration", ruleengine.WithEvalContext(ctx))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(result.Passed)
+	// Output: true
+}
+
+func ExampleEvaluateAs() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/output_rules.yml", "", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	tier, err := ruleengine.EvaluateAs[string](engine, "tier", ruleengine.WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"spend": 1500},
+	}))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(tier)
+	// Output: gold
+}
+
+func ExampleDiffResults() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "development", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	enforced, err := engine.EvaluateRuleset("user_registration", ruleengine.WithEvalContext(adult))
+	if err != nil {
+		log.Fatal(err)
+	}
+	shadow, err := engine.EvaluateRuleset("user_registration",
+		ruleengine.WithEvalContext(adult),
+		ruleengine.WithGlobalOverrides(map[string]interface{}{"min_age": 25}),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, rd := range ruleengine.DiffResults(enforced, shadow).ChangedRules() {
+		fmt.Println(rd.RuleName, rd.PassedA, "->", rd.PassedB)
+	}
+	// Output: age_validation true -> false
+}
+
+func ExampleRuleEngine_Stats() {
+	engine, err := ruleengine.NewRuleEngine("./testdata/rules.yml", "production", newEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, age := range []int{15, 21, 30} {
+		_, err := engine.EvaluateRule("age_validation", ruleengine.WithEvalContext(map[string]interface{}{
+			"user": map[string]interface{}{"age": age},
+		}))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	stats := engine.Stats().Rules["age_validation"]
+	fmt.Println(stats.Passed, stats.Failed, stats.Errored)
+	// Output: 2 1 0
+}
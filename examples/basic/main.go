@@ -0,0 +1,44 @@
+// Command basic demonstrates loading a config and evaluating a ruleset
+//
+//	go run ./examples/basic -config examples/basic/rules.yml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+func main() {
+	configPath := flag.String("config", "examples/basic/rules.yml", "path to the rules config file")
+	flag.Parse()
+
+	engine, err := ruleengine.NewBuilder().
+		WithConfigFile(*configPath).
+		WithVariables("user").
+		Build()
+	if err != nil {
+		log.Fatalf("failed to create rule engine: %v", err)
+	}
+
+	users := []map[string]interface{}{
+		{"age": 21, "email": "adult@example.com"},
+		{"age": 15, "email": "minor@example.com"},
+	}
+	for _, user := range users {
+		result, err := engine.EvaluateRuleset("user_registration",
+			ruleengine.WithEvalContext(map[string]interface{}{"user": user}))
+		if err != nil {
+			log.Fatalf("failed to evaluate ruleset: %v", err)
+		}
+		fmt.Printf("%s: passed=%v", user["email"], result.Passed)
+		for _, ruleResult := range result.Ordered() {
+			if ruleResult.Error != nil {
+				fmt.Printf(" %s=%q", ruleResult.RuleName, ruleResult.Error)
+			}
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,199 @@
+package ruleengine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+)
+
+// RuleExplanation describes the structure of a compiled rule to aid review of complex expressions
+type RuleExplanation struct {
+	// RuleName is the name of the rule
+	RuleName string
+	// Expression is the rule expression
+	Expression string
+	// AST is an indented tree of the checked expression, macros such as all() are shown expanded
+	AST string
+	// Variables are the context paths referenced by the expression, e.g. `user.age`, sorted
+	Variables []string
+	// Parents are the rules this rule extends, from immediate parent to topmost ancestor
+	Parents []string
+	// Cost is the static cost estimate of the expression
+	Cost CostEstimate
+}
+
+// Explain returns the parsed AST, referenced variables, inheritance chain and cost estimate of a rule
+//
+//	Errors are returned if the rule is not found or its expression cannot be compiled
+func (re *RuleEngine) Explain(ruleName string) (RuleExplanation, error) {
+	if active := re.Active(); active != re {
+		return active.Explain(ruleName)
+	}
+	rule, ok := re.config.Rules[ruleName]
+	if !ok {
+		return RuleExplanation{}, fmt.Errorf("rule '%s' not found", ruleName)
+	}
+	checked, issues := re.env.Compile(rule.Expression)
+	if issues != nil && issues.Err() != nil {
+		return RuleExplanation{}, fmt.Errorf("failed to compile expression '%s': %w", rule.Expression, issues.Err())
+	}
+	parents := make([]string, len(re.parents[ruleName]))
+	copy(parents, re.parents[ruleName])
+	expr := checked.NativeRep().Expr()
+	var tree strings.Builder
+	writeExpr(&tree, expr, 0)
+	return RuleExplanation{
+		RuleName:   ruleName,
+		Expression: rule.Expression,
+		AST:        tree.String(),
+		Variables:  re.referencedVariables(expr),
+		Parents:    parents,
+		Cost:       re.costs[ruleName],
+	}, nil
+}
+
+// referencedVariables lists the longest field paths rooted at declared variables within expr
+func (re *RuleEngine) referencedVariables(expr ast.Expr) []string {
+	declared := make(map[string]bool)
+	for _, v := range re.env.Variables() {
+		declared[v.Name()] = true
+	}
+
+	paths := make(map[string]bool)
+	var visit func(e ast.Expr)
+	visit = func(e ast.Expr) {
+		switch e.Kind() {
+		case ast.SelectKind, ast.IdentKind:
+			if path, ok := selectPath(e); ok {
+				if declared[strings.SplitN(path, ".", 2)[0]] {
+					paths[path] = true
+				}
+				return
+			}
+			visit(e.AsSelect().Operand())
+		case ast.CallKind:
+			call := e.AsCall()
+			if call.IsMemberFunction() {
+				visit(call.Target())
+			}
+			for _, arg := range call.Args() {
+				visit(arg)
+			}
+		case ast.ListKind:
+			for _, elem := range e.AsList().Elements() {
+				visit(elem)
+			}
+		case ast.MapKind:
+			for _, entry := range e.AsMap().Entries() {
+				visit(entry.AsMapEntry().Key())
+				visit(entry.AsMapEntry().Value())
+			}
+		case ast.StructKind:
+			for _, field := range e.AsStruct().Fields() {
+				visit(field.AsStructField().Value())
+			}
+		case ast.ComprehensionKind:
+			comp := e.AsComprehension()
+			visit(comp.IterRange())
+			visit(comp.AccuInit())
+			visit(comp.LoopCondition())
+			visit(comp.LoopStep())
+			visit(comp.Result())
+		}
+	}
+	visit(expr)
+
+	variables := make([]string, 0, len(paths))
+	for path := range paths {
+		variables = append(variables, path)
+	}
+	sort.Strings(variables)
+	return variables
+}
+
+// selectPath returns the dotted path of a chain of field selections ending in an identifier, e.g. `user.age`
+func selectPath(e ast.Expr) (string, bool) {
+	switch e.Kind() {
+	case ast.IdentKind:
+		return e.AsIdent(), true
+	case ast.SelectKind:
+		sel := e.AsSelect()
+		if sel.IsTestOnly() {
+			return "", false
+		}
+		operand, ok := selectPath(sel.Operand())
+		if !ok {
+			return "", false
+		}
+		return operand + "." + sel.FieldName(), true
+	default:
+		return "", false
+	}
+}
+
+// writeExpr writes expr to b as an indented tree, one node per line
+func writeExpr(b *strings.Builder, e ast.Expr, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch e.Kind() {
+	case ast.LiteralKind:
+		lit := e.AsLiteral()
+		value := fmt.Sprint(lit.Value())
+		if str, ok := lit.Value().(string); ok {
+			value = strconv.Quote(str)
+		}
+		fmt.Fprintf(b, "%sliteral %s (%s)\n", indent, value, lit.Type().TypeName())
+	case ast.IdentKind:
+		fmt.Fprintf(b, "%sident %s\n", indent, e.AsIdent())
+	case ast.SelectKind:
+		sel := e.AsSelect()
+		if sel.IsTestOnly() {
+			fmt.Fprintf(b, "%shas .%s\n", indent, sel.FieldName())
+		} else {
+			fmt.Fprintf(b, "%sselect .%s\n", indent, sel.FieldName())
+		}
+		writeExpr(b, sel.Operand(), depth+1)
+	case ast.CallKind:
+		call := e.AsCall()
+		fmt.Fprintf(b, "%scall %s\n", indent, call.FunctionName())
+		if call.IsMemberFunction() {
+			fmt.Fprintf(b, "%s  target\n", indent)
+			writeExpr(b, call.Target(), depth+2)
+		}
+		for _, arg := range call.Args() {
+			writeExpr(b, arg, depth+1)
+		}
+	case ast.ListKind:
+		fmt.Fprintf(b, "%slist\n", indent)
+		for _, elem := range e.AsList().Elements() {
+			writeExpr(b, elem, depth+1)
+		}
+	case ast.MapKind:
+		fmt.Fprintf(b, "%smap\n", indent)
+		for _, entry := range e.AsMap().Entries() {
+			fmt.Fprintf(b, "%s  entry\n", indent)
+			writeExpr(b, entry.AsMapEntry().Key(), depth+2)
+			writeExpr(b, entry.AsMapEntry().Value(), depth+2)
+		}
+	case ast.StructKind:
+		fmt.Fprintf(b, "%sstruct %s\n", indent, e.AsStruct().TypeName())
+		for _, field := range e.AsStruct().Fields() {
+			fmt.Fprintf(b, "%s  field %s\n", indent, field.AsStructField().Name())
+			writeExpr(b, field.AsStructField().Value(), depth+2)
+		}
+	case ast.ComprehensionKind:
+		comp := e.AsComprehension()
+		fmt.Fprintf(b, "%scomprehension %s in\n", indent, comp.IterVar())
+		writeExpr(b, comp.IterRange(), depth+1)
+		fmt.Fprintf(b, "%s  condition\n", indent)
+		writeExpr(b, comp.LoopCondition(), depth+2)
+		fmt.Fprintf(b, "%s  step\n", indent)
+		writeExpr(b, comp.LoopStep(), depth+2)
+		fmt.Fprintf(b, "%s  result\n", indent)
+		writeExpr(b, comp.Result(), depth+2)
+	default:
+		fmt.Fprintf(b, "%sunspecified\n", indent)
+	}
+}
@@ -0,0 +1,84 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_Explain(t *testing.T) {
+	tests := []struct {
+		name     string
+		ruleName string
+		want     RuleExplanation
+		wantErr  bool
+	}{
+		{
+			name:     "success - simple rule",
+			ruleName: "age_validation",
+			want: RuleExplanation{
+				RuleName:   "age_validation",
+				Expression: "user.age >= globals.min_age",
+				AST: "call _>=_\n" +
+					"  select .age\n" +
+					"    ident user\n" +
+					"  select .min_age\n" +
+					"    ident globals\n",
+				Variables: []string{"globals.min_age", "user.age"},
+				Parents:   []string{},
+				Cost:      CostEstimate{Min: 3, Max: 1844674407370955266},
+			},
+		},
+		{
+			name:     "success - inherited rule",
+			ruleName: "test_user",
+			want: RuleExplanation{
+				RuleName:   "test_user",
+				Expression: "user.email.startsWith('test')",
+				AST: "call startsWith\n" +
+					"  target\n" +
+					"    select .email\n" +
+					"      ident user\n" +
+					"  literal \"test\" (string)\n",
+				Variables: []string{"user.email"},
+				Parents:   []string{"email_whitelist", "email_format"},
+				Cost:      CostEstimate{Min: 2, Max: 2},
+			},
+		},
+		{
+			name:     "fail - rule not found",
+			ruleName: "missing",
+			wantErr:  true,
+		},
+	}
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.Explain(tt.ruleName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Explain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Explain() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_Explain_ComprehensionVariables(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	got, err := re.Explain("email_whitelist")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	// The iteration variable `domain` is not a context variable
+	if diff := cmp.Diff(got.Variables, []string{"globals.allowed_domains", "user.email"}); diff != "" {
+		t.Errorf("Explain() variables (-got +want):\n%s", diff)
+	}
+}
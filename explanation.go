@@ -0,0 +1,134 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/parser"
+)
+
+// WithExplanations attaches a human-readable explanation to the results of rules which did not pass
+//
+//	The failing expression is rendered with the evaluated values of the variables and calls it references, e.g.
+//	`user.age (15) >= globals.min_age (18) → false`. A failed rule is evaluated again with state tracking to
+//	collect the values, so only failures pay for explanations
+func WithExplanations() Option {
+	return func(re *RuleEngine) {
+		re.explainers = make(map[string]explainer)
+	}
+}
+
+// explainer is a state tracking program used to explain the failure of a rule
+type explainer struct {
+	program cel.Program
+	checked *cel.Ast
+}
+
+// newExplainer creates the state tracking program of a checked rule expression
+func (re *RuleEngine) newExplainer(expression string, checked *cel.Ast) (explainer, error) {
+	program, err := re.env.Program(checked, append(re.programOptions(), cel.EvalOptions(cel.OptTrackState))...)
+	if err != nil {
+		return explainer{}, fmt.Errorf("failed to create explanation program for expression '%s': %w", expression, err)
+	}
+	return explainer{program: re.sandboxed(program), checked: checked}, nil
+}
+
+// explainFailure renders the expression of a failed rule with the values it evaluated against, along with the
+// suggestions of the changes for it to pass
+//
+//	The rule is evaluated again against the input of the evaluation it failed, sharing its http_get responses. An
+//	empty explanation is returned if explanations are disabled or the rule fails to evaluate
+func (re *RuleEngine) explainFailure(ruleName string, input interface{}) (string, []Suggestion) {
+	ex, ok := re.explainers[ruleName]
+	if !ok {
+		return "", nil
+	}
+	out, details, err := re.evalProgram(ex.program, input)
+	if err != nil || details == nil {
+		return "", nil
+	}
+	expr := ex.checked.NativeRep().Expr()
+	r := renderer{state: details.State(), info: ex.checked.NativeRep().SourceInfo()}
+	declared := make(map[string]bool)
+	for _, v := range re.env.Variables() {
+		declared[v.Name()] = true
+	}
+	s := suggester{state: details.State(), declared: declared}
+	return r.render(expr) + " → " + formatValue(out), s.suggestions(expr)
+}
+
+// renderer renders an expression annotated with the values recorded during its evaluation
+type renderer struct {
+	state interpreter.EvalState
+	info  *ast.SourceInfo
+}
+
+// render renders e, annotating variables and calls with their values
+//
+//	Operators are rendered inline so each operand shows its own value, anything else is unparsed as a whole
+func (r renderer) render(e ast.Expr) string {
+	if path, ok := selectPath(e); ok {
+		return r.annotate(path, e)
+	}
+	if e.Kind() == ast.CallKind {
+		call := e.AsCall()
+		fn := call.FunctionName()
+		args := call.Args()
+		if op, ok := operators.FindReverseBinaryOperator(fn); ok && len(args) == 2 && !call.IsMemberFunction() {
+			return r.operand(args[0], fn, false) + " " + op + " " + r.operand(args[1], fn, true)
+		}
+		if fn == operators.LogicalNot && len(args) == 1 {
+			return "!" + r.operand(args[0], fn, false)
+		}
+	}
+	text, err := parser.Unparse(e, r.info)
+	if err != nil {
+		text = "?"
+	}
+	if e.Kind() == ast.LiteralKind {
+		return text
+	}
+	return r.annotate(text, e)
+}
+
+// operand renders an operand of the operator fn, adding parentheses when it binds less tightly than fn
+func (r renderer) operand(e ast.Expr, fn string, right bool) string {
+	text := r.render(e)
+	if e.Kind() != ast.CallKind {
+		return text
+	}
+	// Lower precedence values bind more tightly, operators are left associative
+	childPrecedence := operators.Precedence(e.AsCall().FunctionName())
+	parentPrecedence := operators.Precedence(fn)
+	if childPrecedence > parentPrecedence || (right && childPrecedence == parentPrecedence && childPrecedence > 0) {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// annotate appends the value recorded for e to text, if it was evaluated
+func (r renderer) annotate(text string, e ast.Expr) string {
+	val, ok := r.state.Value(e.ID())
+	if !ok || val == nil {
+		return text
+	}
+	return text + " (" + formatValue(val) + ")"
+}
+
+// formatValue formats a CEL value for an explanation, quoting strings
+func formatValue(val ref.Val) string {
+	switch v := val.(type) {
+	case types.String:
+		return strconv.Quote(string(v))
+	case *types.Err:
+		return "error: " + v.Error()
+	default:
+		return fmt.Sprint(val.Value())
+	}
+}
@@ -0,0 +1,113 @@
+package ruleengine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRuleEngine_EvaluateRule_Explanation(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		user map[string]interface{}
+		opts []Option
+		want string
+	}{
+		{
+			name: "success - variables substituted",
+			rule: "age_validation",
+			user: map[string]interface{}{"age": 15},
+			opts: []Option{WithExplanations()},
+			want: "user.age (15) >= globals.min_age (18) → false",
+		},
+		{
+			name: "success - nested operators",
+			rule: "active_adult",
+			user: map[string]interface{}{"age": 21, "status": "suspended", "verified": false},
+			opts: []Option{WithExplanations()},
+			want: `user.age (21) >= 18 && (user.status ("suspended") == "active" || user.verified (false)) → false`,
+		},
+		{
+			name: "success - function call",
+			rule: "email_domain",
+			user: map[string]interface{}{"email": "jane@example.com"},
+			opts: []Option{WithExplanations()},
+			want: `user.email.endsWith("@company.com") (false) → false`,
+		},
+		{
+			name: "success - extended rule failed",
+			rule: "email_in_allowlist",
+			user: map[string]interface{}{"age": 15, "email": "jane@company.com"},
+			opts: []Option{WithExplanations()},
+			want: "user.age (15) >= globals.min_age (18) → false",
+		},
+		{
+			name: "success - passed rule not explained",
+			rule: "age_validation",
+			user: map[string]interface{}{"age": 21},
+			opts: []Option{WithExplanations()},
+		},
+		{
+			name: "success - explanations disabled",
+			rule: "age_validation",
+			user: map[string]interface{}{"age": 15},
+		},
+		{
+			name: "success - optimised program",
+			rule: "age_validation",
+			user: map[string]interface{}{"age": 15},
+			opts: []Option{WithExplanations(), WithOptimise()},
+			want: "user.age (15) >= globals.min_age (18) → false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/explanation_rules.yml").
+				WithVariables("user").
+				WithOptions(tt.opts...).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+
+			got, err := re.EvaluateRule(tt.rule, WithEvalContext(map[string]interface{}{"user": tt.user}))
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Explanation != tt.want {
+				t.Errorf("EvaluateRule() explanation = %q, want %q", got.Explanation, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvaluateRule_ExplanationReusesLookups(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = fmt.Fprint(w, `{"hit": true}`)
+	}))
+	defer server.Close()
+
+	re, err := NewRuleEngine("./testdata/http_rules.yml", "", setupEnvironment()(t),
+		WithHTTPGet(HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}}), WithExplanations())
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	got, err := re.EvaluateRule("sanctions_check", WithEvalContext(map[string]interface{}{
+		"request": map[string]interface{}{"sanctions_url": server.URL},
+	}))
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	if got.Passed || got.Explanation == "" {
+		t.Errorf("EvaluateRule() passed = %v, explanation = %q, want a failure explained", got.Passed, got.Explanation)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("EvaluateRule() made %d requests, want 1 shared with the explanation", calls.Load())
+	}
+}
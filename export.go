@@ -0,0 +1,66 @@
+package ruleengine
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/parser"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+// ExportedRule is the canonical form of a rule expression, for use with other CEL tooling and expression diffs
+type ExportedRule struct {
+	// RuleName is the name of the rule
+	RuleName string
+	// Expression is the expression formatted by the CEL unparser, e.g. `user.age>=18` becomes `user.age >= 18`
+	Expression string
+	// CheckedExpr is the checked AST as a google.api.expr.v1alpha1.CheckedExpr textproto
+	CheckedExpr string
+}
+
+// ExportRules returns the canonical expression and checked AST of every rule, sorted by rule name
+//
+//	Formatting, comments and redundant parentheses of the configured expression are not preserved, macros such as
+//	all() are kept unexpanded. Errors are returned if an expression cannot be compiled
+func (re *RuleEngine) ExportRules() ([]ExportedRule, error) {
+	if active := re.Active(); active != re {
+		return active.ExportRules()
+	}
+	// The unparser needs the macro calls recorded to render them unexpanded
+	p, err := parser.NewParser(parser.Macros(re.env.Macros()...), parser.PopulateMacroCalls(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser: %w", err)
+	}
+	names := re.ruleNames()
+	exported := make([]ExportedRule, 0, len(names))
+	for _, name := range names {
+		expression := re.config.Rules[name].Expression
+		parsed, errs := p.Parse(common.NewTextSource(expression))
+		if len(errs.GetErrors()) > 0 {
+			return nil, fmt.Errorf("failed to parse rule '%s': %s", name, errs.ToDisplayString())
+		}
+		canonical, err := parser.Unparse(parsed.Expr(), parsed.SourceInfo())
+		if err != nil {
+			return nil, fmt.Errorf("failed to format rule '%s': %w", name, err)
+		}
+		checked, err := re.checkExpression(expression)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile rule '%s': %w", name, err)
+		}
+		pb, err := cel.AstToCheckedExpr(checked)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert rule '%s': %w", name, err)
+		}
+		text, err := prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(pb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal rule '%s': %w", name, err)
+		}
+		exported = append(exported, ExportedRule{
+			RuleName:    name,
+			Expression:  canonical,
+			CheckedExpr: string(text),
+		})
+	}
+	return exported, nil
+}
@@ -0,0 +1,69 @@
+package ruleengine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_ExportRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		rules          map[string]Rule
+		wantExpression map[string]string
+		wantErr        bool
+	}{
+		{
+			name: "success - canonical expressions",
+			rules: map[string]Rule{
+				"adult":  {Expression: "user.age>=18"},
+				"domain": {Expression: "user.email.endsWith( \"@example.com\" )\n  || (user.admin)"},
+				"tags":   {Expression: `user.tags.all(t, t != "blocked")`},
+			},
+			wantExpression: map[string]string{
+				"adult":  "user.age >= 18",
+				"domain": `user.email.endsWith("@example.com") || user.admin`,
+				"tags":   `user.tags.all(t, t != "blocked")`,
+			},
+		},
+		{
+			name:           "success - no rules",
+			rules:          map[string]Rule{},
+			wantExpression: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(&RulesetConfig{
+				Rules:             tt.rules,
+				ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+				ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+			}).WithVariables("user").Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			got, err := re.ExportRules()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExportRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			expressions := make(map[string]string, len(got))
+			for i, rule := range got {
+				expressions[rule.RuleName] = rule.Expression
+				if i > 0 && got[i-1].RuleName > rule.RuleName {
+					t.Errorf("ExportRules() not sorted by rule name: %s before %s", got[i-1].RuleName, rule.RuleName)
+				}
+				if !strings.Contains(rule.CheckedExpr, "reference_map") || !strings.Contains(rule.CheckedExpr, "type_map") {
+					t.Errorf("ExportRules() checked expr of %s = %s, want references and types", rule.RuleName, rule.CheckedExpr)
+				}
+			}
+			if diff := cmp.Diff(expressions, tt.wantExpression); diff != "" {
+				t.Errorf("ExportRules() expressions (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package ruleengine
+
+import (
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// expressionCacheSize is the number of compiled EvaluateExpression programs kept, least recently used first evicted
+const expressionCacheSize = 256
+
+// newExpressionStore creates the store caching the programs of EvaluateExpression
+func newExpressionStore() *programStore {
+	store := newProgramStore()
+	store.max = expressionCacheSize
+	return store
+}
+
+// EvaluateExpression compiles and evaluates a one-off expression against ctx
+//
+//	The expression has access to the engine's env, functions and globals, which makes it useful for tooling and
+//	debugging. Compiled expressions are cached, repeated calls only evaluate. A nil ctx evaluates against the context
+//	set by SetContext.
+//	Errors are returned if the expression does not compile or fails to evaluate.
+func (re *RuleEngine) EvaluateExpression(expression string, ctx map[string]interface{}) (ref.Val, error) {
+	if active := re.Active(); active != re {
+		return active.EvaluateExpression(expression, ctx)
+	}
+	program, err := re.adhoc.get(expression)
+	if err != nil {
+		return nil, err
+	}
+	release, _, err := re.enqueue(false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	eval, err := re.newEvaluation([]EvalOption{WithEvalContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := re.evalProgram(program, eval.input())
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
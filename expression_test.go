@@ -0,0 +1,86 @@
+package ruleengine
+
+import (
+	"testing"
+)
+
+func TestRuleEngine_EvaluateExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		context    map[string]interface{}
+		want       interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "success - context and globals",
+			expression: "user.age >= globals.min_age",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21},
+			},
+			want: true,
+		},
+		{
+			name:       "success - non boolean value",
+			expression: "user.age + 1",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21},
+			},
+			want: int64(22),
+		},
+		{
+			name:       "fail - compile error",
+			expression: "user.age >= ",
+			wantErr:    true,
+		},
+		{
+			name:       "fail - evaluation error",
+			expression: "user.missing > 1",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+	}
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.EvaluateExpression(tt.expression, tt.context)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateExpression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Value() != tt.want {
+				t.Errorf("EvaluateExpression() = %v, want %v", got.Value(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvaluateExpression_Cache(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	for _, age := range []int{21, 12, 30} {
+		ctx := map[string]interface{}{"user": map[string]interface{}{"age": age}}
+		got, err := re.EvaluateExpression("user.age >= 18", ctx)
+		if err != nil {
+			t.Fatalf("EvaluateExpression() error = %v", err)
+		}
+		if want := age >= 18; got.Value() != want {
+			t.Errorf("EvaluateExpression() = %v, want %v for age %d", got.Value(), want, age)
+		}
+	}
+	if _, err := re.EvaluateExpression("user.age >= ", nil); err == nil {
+		t.Error("EvaluateExpression() error = nil, want a compile error")
+	}
+	if got := re.adhoc.recompilations; got != 1 {
+		t.Errorf("compilations = %d, want 1 for a repeated expression", got)
+	}
+}
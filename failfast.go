@@ -0,0 +1,43 @@
+package ruleengine
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrFailFast is returned by EvaluateAllRulesets when WithFailFastAll cancels the remaining rulesets
+var ErrFailFast = errors.New("remaining rulesets cancelled")
+
+// WithFailFastAll stops EvaluateAllRulesets at the first fatal ruleset, returning the results so far and the cause
+//
+//	A ruleset is fatal when it returns an error, when one of its rules fails to evaluate and no fallback decision
+//	covers it, or when it does not pass and the execution policy sets stop_on_failure. The returned error wraps
+//	ErrFailFast and the cause, rulesets are evaluated in name order so the same ruleset triggers it every time
+func WithFailFastAll() Option {
+	return func(re *RuleEngine) {
+		re.failFastAll = true
+	}
+}
+
+// fatalFailure returns the cause of a fatal ruleset result for WithFailFastAll, nil if the result is not fatal
+func (re *RuleEngine) fatalFailure(result RulesetResult) error {
+	if result.Degraded || result.Skipped {
+		// The fallback decision or precondition already decided the ruleset
+		return nil
+	}
+	names := make([]string, 0, len(result.RuleResults))
+	for name := range result.RuleResults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var evalErr *EvaluationError
+		if errors.As(result.RuleResults[name].Error, &evalErr) {
+			return evalErr
+		}
+	}
+	if !result.Passed && re.policy.StopOnFailure {
+		return result.Error
+	}
+	return nil
+}
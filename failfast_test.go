@@ -0,0 +1,107 @@
+package ruleengine
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_WithFailFastAll(t *testing.T) {
+	config := func(stopOnFailure bool) *RulesetConfig {
+		return &RulesetConfig{
+			Rules: map[string]Rule{
+				"adult": {Expression: "user.age >= 18"},
+				"risk":  {Expression: "request.risk_score < 50"},
+			},
+			Rulesets: map[string]Ruleset{
+				"a_adult":    {Rules: []string{"adult"}},
+				"b_fallback": {Rules: []string{"risk"}, Fallback: FallbackAllow},
+				"c_risk":     {Rules: []string{"risk"}},
+				"d_adult":    {Rules: []string{"adult"}},
+			},
+			ExecutionPolicies: map[string]ExecutionPolicy{"policy": {StopOnFailure: stopOnFailure}},
+			ErrorHandling:     ErrorHandling{ExecutionPolicy: "policy"},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		stopOnFailure bool
+		failFast      bool
+		ctx           map[string]interface{}
+		wantRulesets  []string
+		wantErr       bool
+		wantEvalErr   bool
+	}{
+		{
+			name:         "success - all rulesets evaluated without fail fast",
+			ctx:          map[string]interface{}{"user": map[string]interface{}{"age": 20}, "request": map[string]interface{}{}},
+			wantRulesets: []string{"a_adult", "b_fallback", "c_risk", "d_adult"},
+		},
+		{
+			name:         "success - no fatal ruleset",
+			failFast:     true,
+			ctx:          map[string]interface{}{"user": map[string]interface{}{"age": 20}, "request": map[string]interface{}{"risk_score": 10}},
+			wantRulesets: []string{"a_adult", "b_fallback", "c_risk", "d_adult"},
+		},
+		{
+			name:         "fail - evaluation error cancels remaining rulesets",
+			failFast:     true,
+			ctx:          map[string]interface{}{"user": map[string]interface{}{"age": 20}, "request": map[string]interface{}{}},
+			wantRulesets: []string{"a_adult", "b_fallback", "c_risk"},
+			wantErr:      true,
+			wantEvalErr:  true,
+		},
+		{
+			name:         "success - failed ruleset is not fatal without stop on failure",
+			failFast:     true,
+			ctx:          map[string]interface{}{"user": map[string]interface{}{"age": 10}, "request": map[string]interface{}{"risk_score": 10}},
+			wantRulesets: []string{"a_adult", "b_fallback", "c_risk", "d_adult"},
+		},
+		{
+			name:          "fail - failed ruleset is fatal with stop on failure",
+			stopOnFailure: true,
+			failFast:      true,
+			ctx:           map[string]interface{}{"user": map[string]interface{}{"age": 10}, "request": map[string]interface{}{"risk_score": 10}},
+			wantRulesets:  []string{"a_adult"},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.failFast {
+				opts = append(opts, WithFailFastAll())
+			}
+			re, err := NewBuilder().
+				WithConfig(config(tt.stopOnFailure)).
+				WithVariables("user", "request").
+				WithOptions(opts...).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			results, err := re.EvaluateAllRulesets(WithEvalContext(tt.ctx))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateAllRulesets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrFailFast) {
+				t.Errorf("EvaluateAllRulesets() error = %v, want %v", err, ErrFailFast)
+			}
+			var evalErr *EvaluationError
+			if tt.wantEvalErr && !errors.As(err, &evalErr) {
+				t.Errorf("EvaluateAllRulesets() error = %v, want an EvaluationError cause", err)
+			}
+			gotRulesets := make([]string, 0, len(results))
+			for name := range results {
+				gotRulesets = append(gotRulesets, name)
+			}
+			sort.Strings(gotRulesets)
+			if diff := cmp.Diff(tt.wantRulesets, gotRulesets); diff != "" {
+				t.Errorf("EvaluateAllRulesets() rulesets mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package ruleengine
+
+import (
+	"fmt"
+)
+
+const (
+	// FallbackAllow makes a degraded ruleset pass
+	FallbackAllow = "allow"
+	// FallbackDeny makes a degraded ruleset fail
+	FallbackDeny = "deny"
+)
+
+// applyFallback marks the result as degraded and applies the fallback decision
+//
+//	"allow" passes the ruleset, "deny" and any custom value fail it, the custom value is
+//	reported in RulesetResult.Fallback for the caller to act upon
+func applyFallback(result *RulesetResult, fallback string, cause error) {
+	result.Degraded = true
+	result.Fallback = fallback
+	result.Passed = fallback == FallbackAllow
+	result.Error = nil
+	if !result.Passed {
+		result.Error = fmt.Errorf("ruleset '%s' degraded to fallback '%s': %w", result.RulesetName, fallback, cause)
+	}
+}
+
+// fallbackRemaining adds degraded fallback results for every named ruleset with a fallback that has no result yet
+func (re *RuleEngine) fallbackRemaining(results map[string]RulesetResult, names []string, cause error) {
+	for _, name := range names {
+		ruleset, ok := re.config.Rulesets[name]
+		if !ok || ruleset.Fallback == "" {
+			continue
+		}
+		if _, ok := results[name]; ok {
+			continue
+		}
+		result := RulesetResult{
+			RulesetName: name,
+			RuleResults: make(map[string]RuleResult),
+		}
+		applyFallback(&result, ruleset.Fallback, cause)
+		results[name] = re.transformResult(result)
+	}
+}
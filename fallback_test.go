@@ -0,0 +1,132 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_Fallback(t *testing.T) {
+	erroring := map[string]interface{}{
+		"user":    map[string]interface{}{"age": 21},
+		"request": map[string]interface{}{},
+	}
+	healthy := map[string]interface{}{
+		"user":    map[string]interface{}{"age": 21},
+		"request": map[string]interface{}{"risk_score": 10},
+	}
+	type want struct {
+		Passed   bool
+		Degraded bool
+		Fallback string
+		HasError bool
+	}
+	tests := []struct {
+		name        string
+		environment string
+		ruleset     string
+		context     map[string]interface{}
+		want        want
+	}{
+		{
+			name:    "success - no error, fallback unused",
+			ruleset: "fail_closed",
+			context: healthy,
+			want:    want{Passed: true},
+		},
+		{
+			name:    "success - error, fallback allow",
+			ruleset: "fail_open",
+			context: erroring,
+			want:    want{Passed: true, Degraded: true, Fallback: FallbackAllow},
+		},
+		{
+			name:    "fail - error, fallback deny",
+			ruleset: "fail_closed",
+			context: erroring,
+			want:    want{Passed: false, Degraded: true, Fallback: FallbackDeny, HasError: true},
+		},
+		{
+			name:    "fail - error, custom fallback",
+			ruleset: "manual_review",
+			context: erroring,
+			want:    want{Passed: false, Degraded: true, Fallback: "review", HasError: true},
+		},
+		{
+			name:    "fail - error, no fallback",
+			ruleset: "no_fallback",
+			context: erroring,
+			want:    want{Passed: false, HasError: true},
+		},
+		{
+			name:        "success - timeout, fallback allow",
+			environment: "timeout",
+			ruleset:     "fail_open",
+			context:     healthy,
+			want:        want{Passed: true, Degraded: true, Fallback: FallbackAllow},
+		},
+		{
+			name:        "success - timeout, no fallback",
+			environment: "timeout",
+			ruleset:     "no_fallback",
+			context:     healthy,
+			want:        want{Passed: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewRuleEngine("./testdata/fallback_rules.yml", tt.environment, setupEnvironment()(t))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			result, err := re.EvaluateRuleset(tt.ruleset, WithEvalContext(tt.context))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			got := want{
+				Passed:   result.Passed,
+				Degraded: result.Degraded,
+				Fallback: result.Fallback,
+				HasError: result.Error != nil,
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("EvaluateRuleset() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvaluateRule_EvaluationError(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/fallback_rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	got, err := re.EvaluateRule("risk_score", WithEvalContext(map[string]interface{}{
+		"request": map[string]interface{}{},
+	}))
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	var evalErr *EvaluationError
+	if !errors.As(got.Error, &evalErr) || evalErr.RuleName != "risk_score" {
+		t.Errorf("EvaluateRule() error = %v, want EvaluationError", got.Error)
+	}
+}
+
+func TestRuleEngine_EvaluateAllRulesets_Fallback(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/fallback_rules.yml", "timeout", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	// The timeout error depends on ticker scheduling, every ruleset with a fallback must be degraded either way
+	results, _ := re.EvaluateAllRulesets(WithEvalContext(map[string]interface{}{
+		"user":    map[string]interface{}{"age": 21},
+		"request": map[string]interface{}{"risk_score": 10},
+	}))
+	for _, name := range []string{"fail_open", "fail_closed", "manual_review"} {
+		if !results[name].Degraded {
+			t.Errorf("EvaluateAllRulesets() ruleset %s not degraded", name)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package ruleengine
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/parser"
+)
+
+// registerFunctions expands the user-defined `functions` in the config into CEL macros
+//
+//	Each function is a zero-argument snippet, e.g. `is_adult: "user.age >= globals.min_age"`, which rules
+//	can call as `is_adult()`. Functions may call other functions, circular references are rejected.
+func (re *RuleEngine) registerFunctions() error {
+	if len(re.config.Functions) == 0 {
+		return nil
+	}
+
+	// Resolve the dependency order between functions so nested calls expand correctly
+	order, err := re.functionOrder()
+	if err != nil {
+		return err
+	}
+
+	resolved := make(map[string]ast.Expr, len(order))
+	macros := make([]cel.Macro, 0, len(order))
+	for _, name := range order {
+		env, err := re.env.Extend(cel.Macros(macros...))
+		if err != nil {
+			return fmt.Errorf("failed to extend cel env for function '%s': %w", name, err)
+		}
+		parsed, issues := env.Parse(re.config.Functions[name])
+		if issues != nil && issues.Err() != nil {
+			return fmt.Errorf("failed to parse function '%s': %w", name, issues.Err())
+		}
+		resolved[name] = parsed.NativeRep().Expr()
+		macros = append(macros, functionMacro(name, resolved[name]))
+	}
+
+	env, err := re.env.Extend(cel.Macros(macros...))
+	if err != nil {
+		return fmt.Errorf("failed to register functions: %w", err)
+	}
+	re.env = env
+	return nil
+}
+
+// functionOrder returns the function names sorted so that every function appears after the functions it calls
+func (re *RuleEngine) functionOrder() ([]string, error) {
+	deps := make(map[string][]string, len(re.config.Functions))
+	for name, expression := range re.config.Functions {
+		parsed, issues := re.env.Parse(expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("failed to parse function '%s': %w", name, issues.Err())
+		}
+		deps[name] = calledFunctions(parsed.NativeRep().Expr(), re.config.Functions)
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	order := make([]string, 0, len(names))
+	state := make(map[string]int, len(names)) // 0 unvisited, 1 visiting, 2 done
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("circular dependency detected in function '%s'", name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// calledFunctions lists the user-defined functions called with no target and no arguments in expr
+func calledFunctions(expr ast.Expr, functions map[string]string) []string {
+	called := make([]string, 0)
+	ast.PostOrderVisit(expr, ast.NewExprVisitor(func(e ast.Expr) {
+		if e.Kind() != ast.CallKind {
+			return
+		}
+		call := e.AsCall()
+		if call.IsMemberFunction() || len(call.Args()) != 0 {
+			return
+		}
+		if _, ok := functions[call.FunctionName()]; ok {
+			called = append(called, call.FunctionName())
+		}
+	}))
+	return called
+}
+
+// functionMacro builds a zero-argument global macro which expands to a copy of body
+func functionMacro(name string, body ast.Expr) cel.Macro {
+	return cel.GlobalMacro(name, 0, func(eh parser.ExprHelper, _ ast.Expr, _ []ast.Expr) (ast.Expr, *common.Error) {
+		return eh.Copy(body), nil
+	})
+}
@@ -0,0 +1,100 @@
+package ruleengine
+
+import (
+	"testing"
+)
+
+func TestRuleEngine_EvaluateRule_Functions(t *testing.T) {
+	tests := []struct {
+		name     string
+		ruleName string
+		context  map[string]interface{}
+		want     bool
+	}{
+		{
+			name:     "success - single function",
+			ruleName: "age_validation",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21},
+			},
+			want: true,
+		},
+		{
+			name:     "fail - single function",
+			ruleName: "age_validation",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 15},
+			},
+			want: false,
+		},
+		{
+			name:     "success - nested functions",
+			ruleName: "active_adult",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{
+					"age":       21,
+					"email":     "test@example.com",
+					"status":    "active",
+					"suspended": false,
+				},
+			},
+			want: true,
+		},
+		{
+			name:     "fail - nested functions",
+			ruleName: "active_adult",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{
+					"age":       21,
+					"email":     "test@example.com",
+					"status":    "active",
+					"suspended": true,
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewRuleEngine("./testdata/functions_rules.yml", "", setupEnvironment()(t))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			re.SetContext(tt.context)
+			got, err := re.EvaluateRule(tt.ruleName)
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRule() passed = %v, want %v, error = %v", got.Passed, tt.want, got.Error)
+			}
+		})
+	}
+}
+
+func TestNewRuleEngine_Functions(t *testing.T) {
+	tests := []struct {
+		name       string
+		configPath string
+		wantErr    bool
+	}{
+		{
+			name:       "success",
+			configPath: "./testdata/functions_rules.yml",
+			wantErr:    false,
+		},
+		{
+			name:       "fail - circular functions",
+			configPath: "./testdata/bad_functions.yml",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRuleEngine(tt.configPath, "", setupEnvironment()(t))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRuleEngine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
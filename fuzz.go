@@ -0,0 +1,156 @@
+package ruleengine
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// fuzzState caches the context paths used to generate fuzzed contexts for an engine
+type fuzzState struct {
+	once  sync.Once
+	paths []string
+	err   error
+}
+
+// FuzzEvaluate builds a context from data and evaluates every rule and ruleset of the engine against it
+//
+//	Values of varying types are generated for the context paths referenced by the config, so rules see missing
+//	fields, unexpected types and edge case values. Intended to be called from a native fuzz target:
+//
+//	func FuzzRules(f *testing.F) {
+//		engine, _ := ruleengine.NewRuleEngine("rules.yml", "", env)
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			if err := ruleengine.FuzzEvaluate(engine, data); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+//
+//	Errors are returned if an evaluation panics or a rule evaluation returns an error rather than a failed result
+func FuzzEvaluate(re *RuleEngine, data []byte) error {
+	if active := re.Active(); active != re {
+		return FuzzEvaluate(active, data)
+	}
+	re.fuzz.once.Do(func() {
+		usage, err := re.VariableUsage()
+		re.fuzz.paths, re.fuzz.err = usage.Fields, err
+	})
+	if re.fuzz.err != nil {
+		return re.fuzz.err
+	}
+
+	ctx := fuzzContext(re.fuzz.paths, data)
+	for _, name := range re.ruleNames() {
+		if err := fuzzCall("rule", name, func() error {
+			_, err := re.EvaluateRule(name, WithEvalContext(ctx))
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, name := range re.rulesetNames() {
+		if err := fuzzCall("ruleset", name, func() error {
+			// Ruleset errors such as timeouts are expected, only panics are reported
+			_, _ = re.EvaluateRuleset(name, WithEvalContext(ctx))
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fuzzCall runs fn, converting a panic into an error with the stack trace attached
+func fuzzCall(kind, name string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic evaluating %s '%s': %v\n%s", kind, name, r, debug.Stack())
+		}
+	}()
+	if err := fn(); err != nil {
+		return fmt.Errorf("failed to evaluate %s '%s': %w", kind, name, err)
+	}
+	return nil
+}
+
+// fuzzReader consumes fuzz input, returning zero values once the input is exhausted
+type fuzzReader struct {
+	data []byte
+}
+
+// byte returns the next byte of input
+func (r *fuzzReader) byte() byte {
+	if len(r.data) == 0 {
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+// string returns a string of up to 32 bytes of input
+func (r *fuzzReader) string() string {
+	n := int(r.byte() % 33)
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+	return s
+}
+
+// fuzzContext builds a context placing a generated value at each path
+func fuzzContext(paths []string, data []byte) map[string]interface{} {
+	r := &fuzzReader{data: data}
+	b := NewContextBuilder()
+	for _, path := range paths {
+		if value, ok := r.value(0); ok {
+			b.set(path, value)
+		}
+	}
+	return b.ctx
+}
+
+// value generates a value of an input-selected type, ok is false when the field should be missing
+func (r *fuzzReader) value(depth int) (interface{}, bool) {
+	kind := r.byte() % 10
+	if depth > 2 && kind >= 7 {
+		kind %= 7
+	}
+	switch kind {
+	case 0:
+		return nil, false
+	case 1:
+		return nil, true
+	case 2:
+		return r.byte()%2 == 0, true
+	case 3:
+		return int64(int8(r.byte())) << (r.byte() % 57), true
+	case 4:
+		return float64(int8(r.byte())) / float64(r.byte()|1), true
+	case 5:
+		return r.string(), true
+	case 6:
+		return uint64(r.byte()) << (r.byte() % 57), true
+	case 7:
+		list := make([]interface{}, 0)
+		for i := r.byte() % 5; i > 0; i-- {
+			if v, ok := r.value(depth + 1); ok {
+				list = append(list, v)
+			}
+		}
+		return list, true
+	case 8:
+		m := make(map[string]interface{})
+		for i := r.byte() % 5; i > 0; i-- {
+			key := r.string()
+			if v, ok := r.value(depth + 1); ok {
+				m[key] = v
+			}
+		}
+		return m, true
+	default:
+		return []byte(r.string()), true
+	}
+}
@@ -0,0 +1,38 @@
+package ruleengine
+
+import (
+	"testing"
+)
+
+func FuzzRuleEngine_EvaluateRule(f *testing.F) {
+	re, err := NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		f.Fatalf("failed to create rules engine: %v", err)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{1, 1, 1, 1, 1, 1, 1})
+	f.Add([]byte{3, 21, 0, 5, 4, 't', 'e', 's', 't', 5, 6, 'a', 'c', 't', 'i', 'v', 'e', 2, 1})
+	f.Add([]byte{7, 4, 8, 2, 1, 'a', 3, 200, 9, 9, 9, 9, 4, 255, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := FuzzEvaluate(re, data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestFuzzContext(t *testing.T) {
+	paths := []string{"user.age", "user.email"}
+	ctx := fuzzContext(paths, []byte{3, 21, 0, 5, 4, 't', 'e', 's', 't'})
+	user, ok := ctx["user"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("fuzzContext() user = %T, want map", ctx["user"])
+	}
+	if user["age"] != int64(21) || user["email"] != "test" {
+		t.Errorf("fuzzContext() user = %v, want age 21 and email test", user)
+	}
+
+	// Exhausted input still produces a context, with every field missing
+	if ctx := fuzzContext(paths, nil); len(ctx) != 0 {
+		t.Errorf("fuzzContext(nil) = %v, want empty context", ctx)
+	}
+}
@@ -0,0 +1,59 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrChangeRejected is returned when a ChangeGate rejects a Commit or Promote
+var ErrChangeRejected = errors.New("change rejected")
+
+// ChangeRequest describes a config change about to be activated, see ChangeGate
+type ChangeRequest struct {
+	// Action is "commit" for Drafts.Commit, "promote" for RuleEngine.Promote or "reload" for RuleEngine.Reload
+	Action string
+	// Author and Message are given to Drafts.Commit, empty for Promote
+	Author  string
+	Message string
+	// Changes are the draft changes committed, empty for Promote
+	Changes []DraftChange
+	// Config is the config about to become active, it must not be modified
+	Config *RulesetConfig
+}
+
+// ChangeGate approves config changes before they become active, enforcing governance in code, e.g. requiring two
+// approvals or a change ticket referenced in the commit message
+type ChangeGate interface {
+	// Approve returns an error to reject the change, the active config then keeps serving. It must not call the
+	// Drafts being committed
+	Approve(req ChangeRequest) error
+}
+
+// ChangeGateFunc adapts a function to a ChangeGate
+type ChangeGateFunc func(req ChangeRequest) error
+
+// Approve implements ChangeGate
+func (f ChangeGateFunc) Approve(req ChangeRequest) error {
+	return f(req)
+}
+
+// WithChangeGate registers a gate invoked before Drafts.Commit and RuleEngine.Promote activate a config
+//
+//	A rejected change is returned as an error wrapping ErrChangeRejected and the gate's error, a rejected Promote
+//	keeps the candidate staged and rejected drafts stay pending
+func WithChangeGate(gate ChangeGate) Option {
+	return func(re *RuleEngine) {
+		re.changeGate = gate
+	}
+}
+
+// approve asks the change gate, if any, to approve the change
+func (re *RuleEngine) approve(req ChangeRequest) error {
+	if re.changeGate == nil {
+		return nil
+	}
+	if err := re.changeGate.Approve(req); err != nil {
+		return fmt.Errorf("%w: %w", ErrChangeRejected, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithChangeGate(t *testing.T) {
+	requests := make([]ChangeRequest, 0)
+	// The gate requires a change ticket in the commit message and a staged config keeping the adult ruleset
+	gate := ChangeGateFunc(func(req ChangeRequest) error {
+		requests = append(requests, req)
+		switch req.Action {
+		case "commit":
+			if !strings.Contains(req.Message, "CHG-") {
+				return errors.New("change ticket required")
+			}
+		case "promote":
+			if _, ok := req.Config.Rulesets["adult"]; !ok {
+				return errors.New("adult ruleset must be kept")
+			}
+		}
+		return nil
+	})
+	re, err := NewBuilder().
+		WithConfig(rolloutConfig("user.age >= 18")).
+		WithVariables("user").
+		WithOptions(WithChangeGate(gate)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	drafts := NewDrafts(re)
+	if err := drafts.PutRule("alice", "age_validation", Rule{Expression: "user.age >= 21"}); err != nil {
+		t.Fatalf("PutRule() error = %v", err)
+	}
+	if _, err := drafts.Commit("alice", "raise the age"); !errors.Is(err, ErrChangeRejected) {
+		t.Fatalf("Commit() error = %v, want %v", err, ErrChangeRejected)
+	}
+	if len(drafts.Pending()) != 1 {
+		t.Errorf("Pending() = %v, want the rejected draft kept", drafts.Pending())
+	}
+	audit := drafts.Audit()
+	if last := audit[len(audit)-1]; last.Action != "reject" || !strings.Contains(last.Message, "change ticket required") {
+		t.Errorf("Audit() last entry = %+v, want the rejection", last)
+	}
+	if _, err := drafts.Commit("alice", "raise the age, CHG-42"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got := requests[len(requests)-1]; got.Author != "alice" || len(got.Changes) != 1 || got.Config.Rules["age_validation"].Expression != "user.age >= 21" {
+		t.Errorf("Approve() request = %+v, want the committed change", got)
+	}
+
+	staged := rolloutConfig("user.age >= 25")
+	staged.Rulesets = map[string]Ruleset{"other": {Selector: "AND", Rules: []string{"age_validation"}}}
+	if err := re.Stage(staged); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := re.Promote(); !errors.Is(err, ErrChangeRejected) {
+		t.Fatalf("Promote() error = %v, want %v", err, ErrChangeRejected)
+	}
+	if re.Staged() == nil {
+		t.Error("Staged() = nil, want the rejected candidate kept staged")
+	}
+	if err := re.Stage(rolloutConfig("user.age >= 25")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := re.Promote(); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+	if got := requests[len(requests)-1]; got.Action != "promote" {
+		t.Errorf("Approve() action = %s, want promote", got.Action)
+	}
+}
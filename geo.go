@@ -0,0 +1,104 @@
+package ruleengine
+
+import (
+	"math"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// earthRadiusKm is the mean radius of the Earth used by the haversine formula
+const earthRadiusKm = 6371.0088
+
+// geoLib is a CEL library providing geographic proximity functions
+//
+//	distance(lat1, lon1, lat2, lon2) returns the great-circle distance in kilometres
+//	within_radius(lat1, lon1, lat2, lon2, radius) reports whether the points are at most radius kilometres apart
+type geoLib struct{}
+
+// GeoFunctions returns a cel.EnvOption declaring the distance and within_radius functions
+//
+//	The engine registers these automatically, this is exposed for callers building their own cel.Env
+func GeoFunctions() cel.EnvOption {
+	return cel.Lib(geoLib{})
+}
+
+// LibraryName implements cel.SingletonLibrary so the functions are only declared once per env
+func (geoLib) LibraryName() string {
+	return "ruleengine.geo"
+}
+
+// CompileOptions implements cel.Library
+func (geoLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("distance",
+			cel.Overload("distance_dyn_dyn_dyn_dyn",
+				[]*cel.Type{cel.DynType, cel.DynType, cel.DynType, cel.DynType}, cel.DoubleType,
+				cel.FunctionBinding(geoDistance),
+			),
+		),
+		cel.Function("within_radius",
+			cel.Overload("within_radius_dyn_dyn_dyn_dyn_dyn",
+				[]*cel.Type{cel.DynType, cel.DynType, cel.DynType, cel.DynType, cel.DynType}, cel.BoolType,
+				cel.FunctionBinding(geoWithinRadius),
+			),
+		),
+	}
+}
+
+// ProgramOptions implements cel.Library
+func (geoLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// geoDistance is the CEL binding for distance()
+func geoDistance(args ...ref.Val) ref.Val {
+	coords, errVal := toFloats("distance", args)
+	if errVal != nil {
+		return errVal
+	}
+	return types.Double(haversine(coords[0], coords[1], coords[2], coords[3]))
+}
+
+// geoWithinRadius is the CEL binding for within_radius()
+func geoWithinRadius(args ...ref.Val) ref.Val {
+	coords, errVal := toFloats("within_radius", args)
+	if errVal != nil {
+		return errVal
+	}
+	if coords[4] < 0 {
+		return types.NewErr("within_radius() radius must not be negative")
+	}
+	return types.Bool(haversine(coords[0], coords[1], coords[2], coords[3]) <= coords[4])
+}
+
+// toFloats converts numeric CEL values into float64, returning a CEL error for non-numeric input
+func toFloats(function string, args []ref.Val) ([]float64, ref.Val) {
+	out := make([]float64, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case types.Double:
+			out[i] = float64(v)
+		case types.Int:
+			out[i] = float64(v)
+		case types.Uint:
+			out[i] = float64(v)
+		default:
+			return nil, types.NewErr("%s() requires numeric arguments, got %s", function, arg.Type().TypeName())
+		}
+	}
+	return out, nil
+}
+
+// haversine returns the great-circle distance in kilometres between two points given in decimal degrees
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,106 @@
+package ruleengine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	type args struct {
+		lat1, lon1, lat2, lon2 float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want float64
+	}{
+		{
+			name: "same point",
+			args: args{lat1: -33.8688, lon1: 151.2093, lat2: -33.8688, lon2: 151.2093},
+			want: 0,
+		},
+		{
+			name: "sydney to melbourne",
+			args: args{lat1: -33.8688, lon1: 151.2093, lat2: -37.8136, lon2: 144.9631},
+			want: 713.8,
+		},
+		{
+			name: "london to paris",
+			args: args{lat1: 51.5074, lon1: -0.1278, lat2: 48.8566, lon2: 2.3522},
+			want: 343.6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := haversine(tt.args.lat1, tt.args.lon1, tt.args.lat2, tt.args.lon2)
+			if math.Abs(got-tt.want) > 1 {
+				t.Errorf("haversine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvaluateRule_Geo(t *testing.T) {
+	tests := []struct {
+		name     string
+		ruleName string
+		context  map[string]interface{}
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name:     "success - within radius",
+			ruleName: "store_pickup",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"lat": -33.8731, "lon": 151.2065},
+			},
+			want: true,
+		},
+		{
+			name:     "fail - outside radius",
+			ruleName: "store_pickup",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"lat": -37.8136, "lon": 144.9631},
+			},
+			want: false,
+		},
+		{
+			name:     "success - integer coordinates",
+			ruleName: "fraud_geolocation",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"lat": -34, "lon": 151},
+				"request": map[string]interface{}{"lat": -33.8688, "lon": 151.2093},
+			},
+			want: true,
+		},
+		{
+			name:     "fail - non numeric coordinates",
+			ruleName: "fraud_geolocation",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"lat": "north", "lon": 151},
+				"request": map[string]interface{}{"lat": -33.8688, "lon": 151.2093},
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewRuleEngine("./testdata/geo_rules.yml", "", setupEnvironment()(t))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			re.SetContext(tt.context)
+			got, err := re.EvaluateRule(tt.ruleName)
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRule() passed = %v, want %v", got.Passed, tt.want)
+			}
+			if (got.Error != nil) != (tt.wantErr || !tt.want) {
+				t.Errorf("EvaluateRule() result error = %v, wantErr %v", got.Error, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package ruleengine
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/cel-go/interpreter"
+)
+
+// EvaluateRulesetBool evaluates a ruleset against ctx and returns only whether it passed, e.g. for high-QPS allow or
+// deny checks
+//
+//	No RuleResults, error messages or reason codes are built, ctx is bound without being copied and evaluation stops
+//	as soon as the outcome is known, so rule statistics only count the rules evaluated. The outcome is the one of
+//	EvaluateRuleset, which is used instead for engines with result transformers, interceptors, computed fields, a
+//	result cache, a profiler, a mirror or a staged config, and for rulesets with a fallback. A nil ctx evaluates
+//	against the context set by SetContext. Errors are returned if the ruleset is not found or ctx exceeds the
+//	WithContextLimits limits
+func (re *RuleEngine) EvaluateRulesetBool(rulesetName string, ctx map[string]interface{}) (bool, error) {
+	if active := re.Active(); active != re {
+		return active.EvaluateRulesetBool(rulesetName, ctx)
+	}
+	ruleset, ok := re.config.Rulesets[rulesetName]
+	if !ok {
+		return false, fmt.Errorf("ruleset '%s' not found", rulesetName)
+	}
+	if !re.hotPath(ruleset) {
+		result, err := re.EvaluateRuleset(rulesetName, WithEvalContext(ctx))
+		return result.Passed, err
+	}
+	release, shed, err := re.enqueue(true)
+	if shed || err != nil {
+		if shed {
+			result, err := re.shedResult(rulesetName)
+			return result.Passed, err
+		}
+		return false, err
+	}
+	defer release()
+
+	eval := &evaluation{context: re.context, lookups: re.httpGet.newCache()}
+	if ctx != nil {
+		if err := re.validateContext(ctx); err != nil {
+			return false, err
+		}
+		bound, err := interpreter.NewActivation(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to bind context: %w", err)
+		}
+		// The builtins are resolved first, as withBuiltins overwrites them in a copied context
+		eval.context = ctx
+		eval.activation = interpreter.NewHierarchicalActivation(bound, re.builtins)
+	}
+
+	now := time.Now()
+	if !re.rulesetActivations[rulesetName].active(now) {
+		return false, nil
+	}
+	if skip, err := re.evaluatePrecondition(rulesetName, eval); skip || err != nil {
+		return false, nil
+	}
+
+	or := ruleset.Selector == selectorOr
+	rules := ruleset.Rules
+	if or && re.policy.StopOnFirstPass {
+		rules = re.earlyExitOrder(rules)
+	}
+	// passed is whether a rule passed an OR ruleset, only its veto rules are evaluated afterwards
+	passed := false
+	for _, ruleRef := range rules {
+		veto := slices.Contains(ruleset.Vetoes, ruleRef)
+		if passed && !veto {
+			continue
+		}
+		rulePassed, skipped := re.evaluateRuleBool(ruleRef, eval)
+		if rulePassed || skipped {
+			if or && rulePassed && !veto {
+				passed = true
+				if len(ruleset.Vetoes) == 0 {
+					return true, nil
+				}
+			}
+			continue
+		}
+		// A failed veto fails any ruleset, a failed rule an AND ruleset unless it is optional
+		if veto || (!or && !slices.Contains(ruleset.Optional, ruleRef)) {
+			return false, nil
+		}
+	}
+	return passed || !or, nil
+}
+
+// hotPath reports whether EvaluateRulesetBool can evaluate the ruleset without building its result
+func (re *RuleEngine) hotPath(ruleset Ruleset) bool {
+	return ruleset.Fallback == "" &&
+		len(re.transformers) == 0 &&
+		len(re.interceptors) == 0 &&
+		len(re.computed) == 0 &&
+		re.resultCache == nil &&
+		re.profiler == nil &&
+		re.mirror == nil &&
+		re.Staged() == nil
+}
+
+// evaluateRuleBool evaluates a rule and the rules it extends as evaluateRuleChain, without building its result
+func (re *RuleEngine) evaluateRuleBool(ruleName string, eval *evaluation) (passed, skipped bool) {
+	start := time.Now()
+	if _, ok := re.config.Rules[ruleName]; !ok {
+		return false, re.missingRules[ruleName]
+	}
+	if _, ok := re.quarantined[ruleName]; ok {
+		return false, true
+	}
+	if !re.activations[ruleName].active(start) {
+		return false, true
+	}
+	if skip, err := re.evaluateGuard(ruleName, eval); skip || err != nil {
+		if err != nil {
+			re.recordRule(RuleResult{RuleName: ruleName, Duration: time.Since(start)}, true, false, 0)
+		}
+		return false, skip
+	}
+	if !re.breakerAllow(ruleName) {
+		return false, false
+	}
+
+	passed, errored := true, false
+	for _, parent := range re.parents[ruleName] {
+		if passed, errored = re.programPassed(parent, eval); !passed || errored {
+			break
+		}
+	}
+	if passed && !errored {
+		passed, errored = re.programPassed(ruleName, eval)
+	}
+	re.recordRule(RuleResult{RuleName: ruleName, Passed: passed, Duration: time.Since(start)}, errored, false, 0)
+	return passed, false
+}
+
+// programPassed evaluates the program of a single rule, without the rules it extends
+func (re *RuleEngine) programPassed(ruleName string, eval *evaluation) (passed, errored bool) {
+	program, err := re.programs.get(ruleName)
+	if err != nil {
+		return false, true
+	}
+	out, _, err := re.evalRule(ruleName, program, eval.input())
+	if err != nil {
+		return false, true
+	}
+	passed, _ = out.Value().(bool)
+	return passed, false
+}
+
+// builtinActivation binds the globals and builtin helpers withBuiltins adds to every context
+func (re *RuleEngine) builtinActivation() interpreter.Activation {
+	// A map is always a valid activation
+	activation, _ := interpreter.NewActivation(re.withBuiltins(make(map[string]interface{}, 3)))
+	return activation
+}
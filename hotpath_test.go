@@ -0,0 +1,131 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+)
+
+// hotPathConfig covers the selectors, vetoes, optional rules, when clauses, preconditions and inheritance
+func hotPathConfig() *RulesetConfig {
+	return &RulesetConfig{
+		Globals: map[string]interface{}{"min_age": 18},
+		Rules: map[string]Rule{
+			"adult":      {Expression: "user.age >= globals.min_age"},
+			"verified":   {Expression: "user.verified"},
+			"trusted":    {Expression: "user.score > 50", Extends: "verified"},
+			"sanctioned": {Expression: "!user.sanctioned"},
+			"phone":      {Expression: "user.phone != ''"},
+			"premium":    {Expression: "user.tier == 'premium'", When: "has(user.tier)"},
+			"broken":     {Expression: "user.missing.field"},
+		},
+		Rulesets: map[string]Ruleset{
+			"signup": {
+				Selector: selectorAnd,
+				Rules:    []string{"adult", "verified", "phone", "premium"},
+				Optional: []string{"phone"},
+			},
+			"access": {
+				Selector: selectorOr,
+				Rules:    []string{"trusted", "premium", "sanctioned"},
+				Vetoes:   []string{"sanctioned"},
+			},
+			"any": {
+				Selector: selectorOr,
+				Rules:    []string{"broken", "adult"},
+			},
+			"gated": {
+				Selector:     selectorAnd,
+				Precondition: "user.age > 0",
+				Rules:        []string{"adult"},
+			},
+			"empty_or": {Selector: selectorOr},
+		},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+	}
+}
+
+func TestRuleEngine_EvaluateRulesetBool(t *testing.T) {
+	users := map[string]map[string]interface{}{
+		"adult":      {"age": 30, "verified": true, "score": 80, "sanctioned": false, "phone": "", "tier": "premium"},
+		"minor":      {"age": 12, "verified": true, "score": 10, "sanctioned": false, "phone": "123"},
+		"sanctioned": {"age": 30, "verified": true, "score": 80, "sanctioned": true, "phone": "123", "tier": "premium"},
+		"unverified": {"age": 0, "verified": false, "score": 80, "sanctioned": false, "phone": "123", "tier": "free"},
+	}
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "success - hot path"},
+		{name: "success - stop on first pass", opts: []Option{WithPolicy(Policy{StopOnFirstPass: true})}},
+		{name: "success - result transformer", opts: []Option{WithResultTransformer(ResultTransformerFunc(func(r RulesetResult) RulesetResult { return r }))}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(hotPathConfig()).WithVariables("user").WithOptions(tt.opts...).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			for _, rulesetName := range re.RulesetNames() {
+				for userName, user := range users {
+					ctx := map[string]interface{}{"user": user}
+					want, err := re.EvaluateRuleset(rulesetName, WithEvalContext(ctx))
+					if err != nil {
+						t.Fatalf("EvaluateRuleset() error = %v", err)
+					}
+					got, err := re.EvaluateRulesetBool(rulesetName, ctx)
+					if err != nil {
+						t.Fatalf("EvaluateRulesetBool() error = %v", err)
+					}
+					if got != want.Passed {
+						t.Errorf("EvaluateRulesetBool(%s, %s) = %v, EvaluateRuleset() passed = %v", rulesetName, userName, got, want.Passed)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvaluateRulesetBool_Errors(t *testing.T) {
+	re, err := NewBuilder().
+		WithConfig(hotPathConfig()).
+		WithVariables("user").
+		WithOptions(WithContextLimits(ContextLimits{MaxKeys: 2})).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		rulesetName string
+		ctx         map[string]interface{}
+		wantErr     error
+		wantMsg     string
+	}{
+		{
+			name:        "fail - unknown ruleset",
+			rulesetName: "unknown",
+			ctx:         map[string]interface{}{},
+			wantMsg:     "ruleset 'unknown' not found",
+		},
+		{
+			name:        "fail - context exceeds limits",
+			rulesetName: "signup",
+			ctx:         map[string]interface{}{"user": map[string]interface{}{"age": 1, "verified": true}},
+			wantErr:     ErrContextTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := re.EvaluateRulesetBool(tt.rulesetName, tt.ctx)
+			if err == nil {
+				t.Fatal("EvaluateRulesetBool() error = nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("EvaluateRulesetBool() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("EvaluateRulesetBool() error = %v, want %s", err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package ruleengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+const (
+	// defaultHTTPTimeout is the default timeout for a single http_get() call
+	defaultHTTPTimeout = 2 * time.Second
+	// defaultHTTPMaxResponseBytes is the default maximum response body size for http_get()
+	defaultHTTPMaxResponseBytes = 1 << 20
+	// httpCacheVariable is the activation variable binding the http_get() response cache of an evaluation
+	httpCacheVariable = "#http_get"
+)
+
+// HTTPGetConfig configures the sandboxed http_get() function
+type HTTPGetConfig struct {
+	// AllowedHosts is the list of host names http_get() may call, any other host is rejected
+	AllowedHosts []string
+	// Timeout is the maximum duration of a single request, defaults to 2s
+	Timeout time.Duration
+	// MaxResponseBytes is the maximum response body size, defaults to 1MiB
+	MaxResponseBytes int64
+	// Client is the HTTP client used for requests, its timeout and redirect policy are overridden
+	Client *http.Client
+}
+
+// WithHTTPGet enables the http_get(url) function for live lookups from rules
+//
+//	Requests are restricted to the allowlisted hosts and results are cached for the Evaluate* call, so a URL is
+//	fetched at most once per evaluation. Expressions evaluated outside of an Evaluate* call, e.g. computed fields or
+//	explanations, fetch without caching
+func WithHTTPGet(config HTTPGetConfig) Option {
+	return func(re *RuleEngine) {
+		re.httpGet = newHTTPGetLib(config)
+	}
+}
+
+// httpGetLib is a CEL library providing the http_get() function
+//
+//	http_get(url) returns a map with the response `status` and `body`, the body is decoded when it is valid JSON
+type httpGetLib struct {
+	client       *http.Client
+	allowedHosts map[string]bool
+	maxBytes     int64
+}
+
+// httpCache caches the http_get() responses of a single evaluation keyed by url
+type httpCache struct {
+	mu        sync.Mutex
+	responses map[string]*httpResponse
+}
+
+// httpResponse is a cached http_get() response, fetched once however many calls request it concurrently
+type httpResponse struct {
+	once sync.Once
+	// result is set under the cache lock once fetched, nil while the request is in flight
+	result ref.Val
+}
+
+// newHTTPGetLib creates a httpGetLib applying defaults to the config
+func newHTTPGetLib(config HTTPGetConfig) *httpGetLib {
+	lib := &httpGetLib{
+		allowedHosts: make(map[string]bool, len(config.AllowedHosts)),
+		maxBytes:     config.MaxResponseBytes,
+	}
+	for _, host := range config.AllowedHosts {
+		lib.allowedHosts[strings.ToLower(host)] = true
+	}
+	if lib.maxBytes <= 0 {
+		lib.maxBytes = defaultHTTPMaxResponseBytes
+	}
+
+	client := &http.Client{}
+	if config.Client != nil {
+		clone := *config.Client
+		client = &clone
+	}
+	client.Timeout = config.Timeout
+	if client.Timeout <= 0 {
+		client.Timeout = defaultHTTPTimeout
+	}
+	// Never follow redirects outside the allowlist
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		return lib.checkURL(req.URL)
+	}
+	lib.client = client
+	return lib
+}
+
+// LibraryName implements cel.SingletonLibrary
+func (*httpGetLib) LibraryName() string {
+	return "ruleengine.http"
+}
+
+// CompileOptions implements cel.Library
+func (lib *httpGetLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("http_get",
+			cel.Overload("http_get_string", []*cel.Type{cel.StringType}, cel.MapType(cel.StringType, cel.DynType),
+				cel.UnaryBinding(func(val ref.Val) ref.Val {
+					return lib.get(nil, val)
+				}),
+			),
+		),
+	}
+}
+
+// ProgramOptions implements cel.Library, http_get() calls are bound to the response cache of the activation
+func (lib *httpGetLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{cel.CustomDecorator(lib.decorate)}
+}
+
+// newCache creates the response cache of an evaluation, nil if http_get() is not enabled
+func (lib *httpGetLib) newCache() *httpCache {
+	if lib == nil {
+		return nil
+	}
+	return &httpCache{responses: make(map[string]*httpResponse)}
+}
+
+// bind returns an activation of input binding the response cache, input itself if there is no cache
+func (c *httpCache) bind(input interface{}) interface{} {
+	if c == nil {
+		return input
+	}
+	activation, err := interpreter.NewActivation(input)
+	if err != nil {
+		return input
+	}
+	cache, _ := interpreter.NewActivation(map[string]interface{}{httpCacheVariable: c})
+	return interpreter.NewHierarchicalActivation(activation, cache)
+}
+
+// boundHTTPCache returns the response cache bound by the activation, nil if none
+func boundHTTPCache(input interface{}) *httpCache {
+	activation, ok := input.(interpreter.Activation)
+	if !ok {
+		return nil
+	}
+	cache, _ := activation.ResolveName(httpCacheVariable)
+	c, _ := cache.(*httpCache)
+	return c
+}
+
+// dropRetryable removes cached transient failures so a retried rule fetches them again
+func (c *httpCache) dropRetryable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for rawURL, response := range c.responses {
+		if response.result != nil && isRetryable(response.result) {
+			delete(c.responses, rawURL)
+		}
+	}
+}
+
+// decorate replaces http_get() calls with calls using the response cache of the activation
+func (lib *httpGetLib) decorate(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+	if call, ok := i.(interpreter.InterpretableCall); ok && call.Function() == "http_get" && len(call.Args()) == 1 {
+		return &httpGetCall{InterpretableCall: call, lib: lib}, nil
+	}
+	return i, nil
+}
+
+// httpGetCall is a http_get() call caching its responses in the cache bound by the activation
+type httpGetCall struct {
+	interpreter.InterpretableCall
+	lib *httpGetLib
+}
+
+// Eval implements interpreter.Interpretable
+func (c *httpGetCall) Eval(vars interpreter.Activation) ref.Val {
+	val := c.Args()[0].Eval(vars)
+	if types.IsUnknownOrError(val) {
+		return val
+	}
+	return c.lib.get(boundHTTPCache(vars), val)
+}
+
+// get is the CEL binding for http_get(), returning the results cached for the evaluation where available
+func (lib *httpGetLib) get(cache *httpCache, val ref.Val) ref.Val {
+	rawURL, ok := val.Value().(string)
+	if !ok {
+		return types.NewErr("http_get() requires string input")
+	}
+	if cache == nil {
+		return lib.fetch(rawURL)
+	}
+
+	cache.mu.Lock()
+	response, ok := cache.responses[rawURL]
+	if !ok {
+		response = &httpResponse{}
+		cache.responses[rawURL] = response
+	}
+	cache.mu.Unlock()
+
+	// Concurrent calls for the url wait for the request in flight instead of fetching it again
+	response.once.Do(func() {
+		result := lib.fetch(rawURL)
+		cache.mu.Lock()
+		response.result = result
+		cache.mu.Unlock()
+	})
+	return response.result
+}
+
+// fetch performs the sandboxed request and converts the response into a CEL value
+func (lib *httpGetLib) fetch(rawURL string) ref.Val {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return types.NewErr("http_get() invalid url: %v", err)
+	}
+	if err := lib.checkURL(u); err != nil {
+		return types.NewErr("http_get() %v", err)
+	}
+
+	resp, err := lib.client.Get(u.String())
+	if err != nil {
+		return types.WrapErr(retryableErrorf("http_get() request failed: %v", err))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, lib.maxBytes+1))
+	if err != nil {
+		return types.WrapErr(retryableErrorf("http_get() failed to read response: %v", err))
+	}
+	if int64(len(data)) > lib.maxBytes {
+		return types.NewErr("http_get() response exceeds %d bytes", lib.maxBytes)
+	}
+
+	var body interface{} = string(data)
+	var decoded interface{}
+	if json.Unmarshal(data, &decoded) == nil {
+		body = decoded
+	}
+	return types.DefaultTypeAdapter.NativeToValue(map[string]interface{}{
+		"status": resp.StatusCode,
+		"body":   body,
+	})
+}
+
+// checkURL ensures the url uses http(s) and targets an allowlisted host
+func (lib *httpGetLib) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme '%s' is not allowed", u.Scheme)
+	}
+	if !lib.allowedHosts[strings.ToLower(u.Hostname())] {
+		return fmt.Errorf("host '%s' is not allowed", u.Hostname())
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package ruleengine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestRuleEngine_EvaluateRule_HTTPGet(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/clear":
+			_, _ = fmt.Fprint(w, `{"hit": false}`)
+		case "/hit":
+			_, _ = fmt.Fprint(w, `{"hit": true}`)
+		case "/large":
+			_, _ = fmt.Fprint(w, `{"hit": false, "padding": "`+strings.Repeat("x", 256)+`"}`)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			_, _ = fmt.Fprint(w, `{"hit": false}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		config    HTTPGetConfig
+		path      string
+		want      bool
+		wantCalls int32
+	}{
+		{
+			name:      "success - not sanctioned, cached",
+			config:    HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}},
+			path:      "/clear",
+			want:      true,
+			wantCalls: 1,
+		},
+		{
+			name:      "fail - sanctioned",
+			config:    HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}},
+			path:      "/hit",
+			want:      false,
+			wantCalls: 1,
+		},
+		{
+			name:      "fail - host not allowed",
+			config:    HTTPGetConfig{AllowedHosts: []string{"example.com"}},
+			path:      "/clear",
+			want:      false,
+			wantCalls: 0,
+		},
+		{
+			name:      "fail - response too large",
+			config:    HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}, MaxResponseBytes: 64},
+			path:      "/large",
+			want:      false,
+			wantCalls: 1,
+		},
+		{
+			name:      "fail - timeout",
+			config:    HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}, Timeout: 50 * time.Millisecond},
+			path:      "/slow",
+			want:      false,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			re, err := NewRuleEngine("./testdata/http_rules.yml", "", setupEnvironment()(t), WithHTTPGet(tt.config))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			re.SetContext(map[string]interface{}{
+				"request": map[string]interface{}{"sanctions_url": server.URL + tt.path},
+			})
+			got, err := re.EvaluateRule("sanctions_check")
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRule() passed = %v, want %v, error = %v", got.Passed, tt.want, got.Error)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("EvaluateRule() made %d requests, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestNewRuleEngine_HTTPGetDisabled(t *testing.T) {
+	_, err := NewRuleEngine("./testdata/http_rules.yml", "", setupEnvironment()(t))
+	if err == nil {
+		t.Errorf("NewRuleEngine() expected error when http_get is not enabled")
+	}
+}
+
+func TestRuleEngine_EvaluateRule_HTTPGetRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Time out the first request to simulate a transient failure
+		if calls.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = fmt.Fprint(w, `{"hit": false}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		ruleName  string
+		want      bool
+		wantCalls int32
+	}{
+		{
+			name:      "success - failed request retried",
+			ruleName:  "sanctions_check_retried",
+			want:      true,
+			wantCalls: 2,
+		},
+		{
+			name:      "fail - failed request cached for the evaluation",
+			ruleName:  "sanctions_check",
+			want:      false,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			re, err := NewRuleEngine("./testdata/http_rules.yml", "", setupEnvironment()(t),
+				WithHTTPGet(HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}, Timeout: 50 * time.Millisecond}))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			re.SetContext(map[string]interface{}{
+				"request": map[string]interface{}{"sanctions_url": server.URL},
+			})
+			got, err := re.EvaluateRule(tt.ruleName)
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRule() passed = %v, want %v, error = %v", got.Passed, tt.want, got.Error)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("EvaluateRule() made %d requests, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvaluateRule_HTTPGetCachedPerEvaluation(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = fmt.Fprintf(w, `{"hit": %s}`, r.URL.Query().Get("hit"))
+	}))
+	defer server.Close()
+
+	re, err := NewRuleEngine("./testdata/http_rules.yml", "", setupEnvironment()(t),
+		WithHTTPGet(HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}}))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	// The last evaluation requests the url of the first one again, responses are not shared between evaluations
+	for i, hit := range []bool{false, true, false} {
+		got, err := re.EvaluateRule("sanctions_check", WithEvalContext(map[string]interface{}{
+			"request": map[string]interface{}{"sanctions_url": fmt.Sprintf("%s?hit=%t", server.URL, hit)},
+		}))
+		if err != nil {
+			t.Fatalf("EvaluateRule() error = %v", err)
+		}
+		if got.Passed == hit {
+			t.Errorf("EvaluateRule() passed = %v, want %v, error = %v", got.Passed, !hit, got.Error)
+		}
+		if calls.Load() != int32(i+1) {
+			t.Errorf("EvaluateRule() made %d requests, want %d", calls.Load(), i+1)
+		}
+	}
+}
+
+func TestHTTPCache_CoalescesConcurrentFetches(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = fmt.Fprint(w, `{"hit": false}`)
+	}))
+	defer server.Close()
+
+	lib := newHTTPGetLib(HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}})
+	cache := lib.newCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := lib.get(cache, types.String(server.URL)); types.IsError(got) {
+				t.Errorf("get() error = %v", got)
+			}
+		}()
+	}
+	// Give every call the chance to reach the cache before the request in flight completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("get() made %d requests, want 1", calls.Load())
+	}
+}
+
+func TestEvaluation_InputBoundOnce(t *testing.T) {
+	lib := newHTTPGetLib(HTTPGetConfig{AllowedHosts: []string{"127.0.0.1"}})
+	eval := &evaluation{context: map[string]interface{}{"user": "alice"}, lookups: lib.newCache()}
+	first := eval.input()
+	if boundHTTPCache(first) != eval.lookups {
+		t.Fatal("input() does not bind the response cache")
+	}
+	if second := eval.input(); second != first {
+		t.Error("input() bound the context again, want the activation of the first call")
+	}
+}
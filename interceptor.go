@@ -0,0 +1,34 @@
+package ruleengine
+
+// Interceptor wraps every rule evaluation, e.g. for custom logging, caching or feature gating
+//
+//	ctx is the evaluation context of the call and must not be modified, next evaluates the rule. An interceptor may
+//	call next, return its result modified or return a result of its own without calling it
+type Interceptor func(ctx map[string]interface{}, ruleName string, next func() (RuleResult, error)) (RuleResult, error)
+
+// WithInterceptor wraps every rule evaluation with the interceptor, interceptors registered first run outermost
+//
+//	Interceptors run once per rule and evaluation call, rules shared between rulesets by EvaluateAllRulesets are
+//	intercepted once, and the rules a rule extends are evaluated within it
+func WithInterceptor(interceptor Interceptor) Option {
+	return func(re *RuleEngine) {
+		re.interceptors = append(re.interceptors, interceptor)
+	}
+}
+
+// intercept evaluates the rule through the registered interceptors
+func (re *RuleEngine) intercept(ruleName string, eval *evaluation) (RuleResult, error) {
+	if len(re.interceptors) == 0 {
+		return re.evaluateRuleChain(ruleName, eval)
+	}
+	next := func() (RuleResult, error) {
+		return re.evaluateRuleChain(ruleName, eval)
+	}
+	for i := len(re.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := re.interceptors[i], next
+		next = func() (RuleResult, error) {
+			return interceptor(eval.context, ruleName, inner)
+		}
+	}
+	return next()
+}
@@ -0,0 +1,84 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithInterceptor(t *testing.T) {
+	calls := make([]string, 0)
+	logging := func(ctx map[string]interface{}, ruleName string, next func() (RuleResult, error)) (RuleResult, error) {
+		calls = append(calls, "log:"+ruleName)
+		return next()
+	}
+	gating := func(ctx map[string]interface{}, ruleName string, next func() (RuleResult, error)) (RuleResult, error) {
+		calls = append(calls, "gate:"+ruleName)
+		if beta, _ := ctx["beta"].(bool); !beta {
+			return RuleResult{RuleName: ruleName, Skipped: true}, nil
+		}
+		return next()
+	}
+	failing := func(ctx map[string]interface{}, ruleName string, next func() (RuleResult, error)) (RuleResult, error) {
+		return RuleResult{}, errors.New("interceptor failed")
+	}
+	tests := []struct {
+		name         string
+		interceptors []Interceptor
+		context      map[string]interface{}
+		wantPassed   bool
+		wantSkipped  bool
+		wantCalls    []string
+		wantErr      bool
+	}{
+		{
+			name:         "success - interceptors run in order",
+			interceptors: []Interceptor{logging, gating},
+			context:      map[string]interface{}{"beta": true, "user": map[string]interface{}{"age": 21}},
+			wantPassed:   true,
+			wantCalls:    []string{"log:age_validation", "gate:age_validation"},
+		},
+		{
+			name:         "success - interceptor short-circuits",
+			interceptors: []Interceptor{logging, gating},
+			context:      map[string]interface{}{"beta": false, "user": map[string]interface{}{"age": 21}},
+			wantSkipped:  true,
+			wantCalls:    []string{"log:age_validation", "gate:age_validation"},
+		},
+		{
+			name:         "fail - interceptor error",
+			interceptors: []Interceptor{logging, failing},
+			context:      map[string]interface{}{"user": map[string]interface{}{"age": 21}},
+			wantCalls:    []string{"log:age_validation"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = calls[:0]
+			opts := make([]Option, 0, len(tt.interceptors))
+			for _, interceptor := range tt.interceptors {
+				opts = append(opts, WithInterceptor(interceptor))
+			}
+			re, err := NewBuilder().
+				WithConfig(rolloutConfig("user.age >= 18")).
+				WithVariables("user", "beta").
+				WithOptions(opts...).
+				Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			got, err := re.EvaluateRule("age_validation", WithEvalContext(tt.context))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Passed != tt.wantPassed || got.Skipped != tt.wantSkipped {
+				t.Errorf("EvaluateRule() passed = %v, skipped = %v, want %v, %v", got.Passed, got.Skipped, tt.wantPassed, tt.wantSkipped)
+			}
+			if diff := cmp.Diff(tt.wantCalls, calls); diff != "" {
+				t.Errorf("calls mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
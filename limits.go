@@ -0,0 +1,138 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ErrContextTooLarge is wrapped by every ContextLimitError
+var ErrContextTooLarge = errors.New("context exceeds limits")
+
+// ContextLimits bounds the size of evaluation contexts, protecting the CEL runtime from huge or deeply nested input
+//
+//	Zero fields are unlimited. Maps and lists are walked, other values such as structs and protobuf messages are
+//	counted as leaves
+type ContextLimits struct {
+	// MaxDepth is the maximum nesting of a value, e.g. user.address.city has depth 3
+	MaxDepth int
+	// MaxKeys is the maximum number of map keys and list elements in the whole context
+	MaxKeys int
+	// MaxStringLength is the maximum length in bytes of a string or byte value
+	MaxStringLength int
+}
+
+// ContextLimitError describes the first limit an evaluation context exceeds
+type ContextLimitError struct {
+	// Limit is the exceeded limit, "max_depth", "max_keys" or "max_string_length"
+	Limit string
+	// Max is the configured value of the limit
+	Max int
+	// Path is where the limit was exceeded, e.g. "user.tags[3]"
+	Path string
+}
+
+// Error implements error
+func (e *ContextLimitError) Error() string {
+	return fmt.Sprintf("%v: %s %d exceeded at %s", ErrContextTooLarge, e.Limit, e.Max, e.Path)
+}
+
+// Unwrap returns ErrContextTooLarge
+func (e *ContextLimitError) Unwrap() error {
+	return ErrContextTooLarge
+}
+
+// WithContextLimits validates every context given to WithEvalContext against the limits before evaluation
+//
+//	A context exceeding them fails the Evaluate* call with a *ContextLimitError, before any rule is evaluated.
+//	Contexts set with SetContext are trusted and not validated
+func WithContextLimits(limits ContextLimits) Option {
+	return func(re *RuleEngine) {
+		re.contextLimits = &limits
+	}
+}
+
+// contextWalker checks a context against limits, counting the keys seen so far
+type contextWalker struct {
+	limits ContextLimits
+	keys   int
+}
+
+// validateContext checks ctx against the limits of the engine, if any
+func (re *RuleEngine) validateContext(ctx map[string]interface{}) error {
+	if re.contextLimits == nil {
+		return nil
+	}
+	w := &contextWalker{limits: *re.contextLimits}
+	return w.walk(reflect.ValueOf(ctx), "", 0)
+}
+
+// walk checks the value found at path and depth, descending into maps and lists
+func (w *contextWalker) walk(v reflect.Value, path string, depth int) error {
+	if w.limits.MaxDepth > 0 && depth > w.limits.MaxDepth {
+		return &ContextLimitError{Limit: "max_depth", Max: w.limits.MaxDepth, Path: path}
+	}
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return w.checkLength(v.Len(), path)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return w.checkLength(v.Len(), path)
+		}
+		if err := w.count(v.Len(), path); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), path+"["+strconv.Itoa(i)+"]", depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if err := w.count(v.Len(), path); err != nil {
+			return err
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if err := w.checkLength(len(key), path); err != nil {
+				return err
+			}
+			if path != "" {
+				key = path + "." + key
+			}
+			if err := w.walk(iter.Value(), key, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// count adds n keys, failing once MaxKeys is exceeded
+func (w *contextWalker) count(n int, path string) error {
+	w.keys += n
+	if w.limits.MaxKeys > 0 && w.keys > w.limits.MaxKeys {
+		return &ContextLimitError{Limit: "max_keys", Max: w.limits.MaxKeys, Path: contextPath(path)}
+	}
+	return nil
+}
+
+// checkLength fails if n exceeds MaxStringLength
+func (w *contextWalker) checkLength(n int, path string) error {
+	if w.limits.MaxStringLength > 0 && n > w.limits.MaxStringLength {
+		return &ContextLimitError{Limit: "max_string_length", Max: w.limits.MaxStringLength, Path: contextPath(path)}
+	}
+	return nil
+}
+
+// contextPath names the root of the context for error messages
+func contextPath(path string) string {
+	if path == "" {
+		return "context"
+	}
+	return path
+}
@@ -0,0 +1,87 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithContextLimits(t *testing.T) {
+	limits := ContextLimits{MaxDepth: 3, MaxKeys: 6, MaxStringLength: 8}
+	tests := []struct {
+		name    string
+		limits  ContextLimits
+		context map[string]interface{}
+		wantErr *ContextLimitError
+	}{
+		{
+			name:   "success - within limits",
+			limits: limits,
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21, "address": map[string]interface{}{"city": "Sydney"}},
+			},
+		},
+		{
+			name:   "success - unlimited",
+			limits: ContextLimits{},
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21, "name": strings.Repeat("a", 1024)},
+			},
+		},
+		{
+			name:   "fail - too deep",
+			limits: limits,
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21, "address": map[string]interface{}{"geo": map[string]interface{}{"lat": 1.5}}},
+			},
+			wantErr: &ContextLimitError{Limit: "max_depth", Max: 3, Path: "user.address.geo.lat"},
+		},
+		{
+			name:   "fail - too many keys",
+			limits: limits,
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21, "tags": []interface{}{"a", "b", "c", "d", "e"}},
+			},
+			wantErr: &ContextLimitError{Limit: "max_keys", Max: 6, Path: "user.tags"},
+		},
+		{
+			name:   "fail - string too long",
+			limits: limits,
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21, "tags": []string{"admin", "superuser"}},
+			},
+			wantErr: &ContextLimitError{Limit: "max_string_length", Max: 8, Path: "user.tags[1]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfig(rolloutConfig("user.age >= 18")).
+				WithVariables("user").
+				WithOptions(WithContextLimits(tt.limits)).
+				Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			_, err = re.EvaluateRuleset("adult", WithEvalContext(tt.context))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("EvaluateRuleset() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrContextTooLarge) {
+				t.Fatalf("EvaluateRuleset() error = %v, want %v", err, ErrContextTooLarge)
+			}
+			var limitErr *ContextLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("EvaluateRuleset() error = %T, want *ContextLimitError", err)
+			}
+			if diff := cmp.Diff(tt.wantErr, limitErr); diff != "" {
+				t.Errorf("EvaluateRuleset() error mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
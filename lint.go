@@ -0,0 +1,296 @@
+package ruleengine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+)
+
+// LintRules configures the expression complexity checks of Lint, zero fields disable a check
+//
+//	lint:
+//	  max_expression_length: 300
+//	  max_nested_ternaries: 2
+//	  bounded_matches: true
+//	  require_has_guards: true
+type LintRules struct {
+	// MaxExpressionLength is the maximum length of a rule expression, leading and trailing whitespace excluded
+	MaxExpressionLength int `yaml:"max_expression_length"`
+	// MaxNestedTernaries is the maximum nesting of conditional operators, e.g. 1 allows `a ? b : c` but not
+	// `a ? (b ? c : d) : e`
+	MaxNestedTernaries int `yaml:"max_nested_ternaries"`
+	// BoundedMatches requires matches() on a context field to be bounded by a size() check of the same field in the
+	// expression, regular expressions over unbounded input are costly
+	BoundedMatches bool `yaml:"bounded_matches"`
+	// RequireHasGuards requires nested context fields, e.g. user.address.city, to be guarded by has() in the
+	// expression, as a missing field fails the rule with an error instead of not passing it
+	RequireHasGuards bool `yaml:"require_has_guards"`
+}
+
+// DefaultLintRules returns the lint rules used when the config does not configure any, every check enabled
+func DefaultLintRules() LintRules {
+	return LintRules{
+		MaxExpressionLength: 300,
+		MaxNestedTernaries:  2,
+		BoundedMatches:      true,
+		RequireHasGuards:    true,
+	}
+}
+
+// LintRules returns the lint rules of the config, DefaultLintRules if it has no lint section
+func (re *RuleEngine) LintRules() LintRules {
+	if active := re.Active(); active != re {
+		return active.LintRules()
+	}
+	if re.config.Lint == nil {
+		return DefaultLintRules()
+	}
+	return *re.config.Lint
+}
+
+// LintIssue is a rule expression failing a lint check
+type LintIssue struct {
+	// RuleName is the name of the rule
+	RuleName string
+	// Check is the failed check, the yaml name of its LintRules field, e.g. "max_nested_ternaries"
+	Check string
+	// Message describes the issue
+	Message string
+}
+
+// String returns the issue in `rule 'name': check: message` form
+func (i LintIssue) String() string {
+	return fmt.Sprintf("rule '%s': %s: %s", i.RuleName, i.Check, i.Message)
+}
+
+// LintIssues is the error returned by Validate when rule expressions fail the configured lint checks
+type LintIssues []LintIssue
+
+// Error implements error, listing every issue on its own line
+func (e LintIssues) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, issue := range e {
+		msgs = append(msgs, issue.String())
+	}
+	return fmt.Sprintf("%d lint issue(s):\n%s", len(e), strings.Join(msgs, "\n"))
+}
+
+// Validate reports duplicate and conflicting rules and lints the rule expressions with the lint rules of the config,
+// if it has a lint section
+//
+//	Rules requiring semantically identical conditions under different names fail the "duplicate_rules" check and
+//	rules of an AND ruleset requiring the negation of a condition of another, so the ruleset can never pass, fail
+//	the "conflicting_rules" check. Errors are returned as LintIssues if any rule fails a check, or if an expression
+//	cannot be compiled
+func (re *RuleEngine) Validate() error {
+	if active := re.Active(); active != re {
+		return active.Validate()
+	}
+	issues, err := re.conflictIssues()
+	if err != nil {
+		return err
+	}
+	if re.config.Lint != nil {
+		lintIssues, err := re.Lint(*re.config.Lint)
+		if err != nil {
+			return err
+		}
+		issues = append(lintIssues, issues...)
+	}
+	if len(issues) > 0 {
+		return LintIssues(issues)
+	}
+	return nil
+}
+
+// conflictIssues reports duplicate and conflicting rules as lint issues
+func (re *RuleEngine) conflictIssues() ([]LintIssue, error) {
+	conditions, err := re.ruleConditions()
+	if err != nil {
+		return nil, err
+	}
+	duplicates, err := re.duplicateRules(conditions)
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]LintIssue, 0)
+	for _, names := range duplicates {
+		for _, name := range names[1:] {
+			issues = append(issues, LintIssue{
+				RuleName: name,
+				Check:    "duplicate_rules",
+				Message:  fmt.Sprintf("condition is identical to rule '%s'", names[0]),
+			})
+		}
+	}
+	for _, conflict := range re.conflictingRules(conditions) {
+		issues = append(issues, LintIssue{
+			RuleName: conflict.Negation,
+			Check:    "conflicting_rules",
+			Message: fmt.Sprintf("negates rule '%s' in AND ruleset '%s', which can never pass",
+				conflict.Rule, conflict.Ruleset),
+		})
+	}
+	return issues, nil
+}
+
+// Lint checks the rule expressions for complexity issues, sorted by rule name
+//
+//	Errors are returned if an expression cannot be compiled
+func (re *RuleEngine) Lint(rules LintRules) ([]LintIssue, error) {
+	if active := re.Active(); active != re {
+		return active.Lint(rules)
+	}
+	issues := make([]LintIssue, 0)
+	for _, name := range re.ruleNames() {
+		expression := re.config.Rules[name].Expression
+		ruleIssues, err := re.lintExpression(expression, rules)
+		if err != nil {
+			return issues, fmt.Errorf("failed to compile rule '%s': %w", name, err)
+		}
+		for _, issue := range ruleIssues {
+			issue.RuleName = name
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// lintExpression checks a single expression, the issues are returned without a rule name
+func (re *RuleEngine) lintExpression(expression string, rules LintRules) ([]LintIssue, error) {
+	issues := make([]LintIssue, 0)
+	if length := len(strings.TrimSpace(expression)); rules.MaxExpressionLength > 0 && length > rules.MaxExpressionLength {
+		issues = append(issues, LintIssue{
+			Check:   "max_expression_length",
+			Message: fmt.Sprintf("expression is %d characters long, the maximum is %d", length, rules.MaxExpressionLength),
+		})
+	}
+	if rules.MaxNestedTernaries == 0 && !rules.BoundedMatches && !rules.RequireHasGuards {
+		return issues, nil
+	}
+
+	checked, compileIssues := re.env.Compile(expression)
+	if compileIssues != nil && compileIssues.Err() != nil {
+		return nil, compileIssues.Err()
+	}
+	root := ast.NavigateAST(checked.NativeRep())
+
+	if rules.MaxNestedTernaries > 0 {
+		if depth := ternaryDepth(root); depth > rules.MaxNestedTernaries {
+			issues = append(issues, LintIssue{
+				Check:   "max_nested_ternaries",
+				Message: fmt.Sprintf("conditional operators are nested %d deep, the maximum is %d", depth, rules.MaxNestedTernaries),
+			})
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, v := range re.env.Variables() {
+		declared[v.Name()] = v.Name() != "globals"
+	}
+	// contextPath returns the path of a context field read by e, e.g. `user.bio`
+	contextPath := func(e ast.Expr) (string, bool) {
+		if e == nil {
+			return "", false
+		}
+		path, ok := selectPath(e)
+		if !ok || !strings.Contains(path, ".") {
+			return "", false
+		}
+		return path, declared[strings.SplitN(path, ".", 2)[0]]
+	}
+
+	if rules.BoundedMatches {
+		bounded := make(map[string]bool)
+		for _, nav := range ast.MatchDescendants(root, ast.FunctionMatcher(overloads.Size)) {
+			if path, ok := contextPath(callOperand(nav.AsCall())); ok {
+				bounded[path] = true
+			}
+		}
+		for _, nav := range ast.MatchDescendants(root, ast.FunctionMatcher(overloads.Matches)) {
+			path, ok := contextPath(callOperand(nav.AsCall()))
+			if !ok || bounded[path] {
+				continue
+			}
+			issues = append(issues, LintIssue{
+				Check:   "bounded_matches",
+				Message: fmt.Sprintf("matches() on unbounded input %s, check size(%s) first", path, path),
+			})
+		}
+	}
+
+	if rules.RequireHasGuards {
+		guarded := make(map[string]bool)
+		selects := ast.MatchDescendants(root, ast.KindMatcher(ast.SelectKind))
+		for _, nav := range selects {
+			if sel := nav.AsSelect(); sel.IsTestOnly() {
+				if path, ok := contextPath(sel.Operand()); ok {
+					guarded[path+"."+sel.FieldName()] = true
+				}
+			}
+		}
+		reported := make(map[string]bool)
+		for _, nav := range selects {
+			if nav.AsSelect().IsTestOnly() {
+				continue
+			}
+			if parent, ok := nav.Parent(); ok && parent.Kind() == ast.SelectKind {
+				// Only the longest path is read, e.g. `user.address.city` but not `user.address`
+				continue
+			}
+			operand := nav.Children()[0]
+			if operand.Kind() != ast.SelectKind || !dynamicType(operand.Type()) {
+				continue
+			}
+			path, ok := contextPath(nav)
+			if !ok || guarded[path] || reported[path] {
+				continue
+			}
+			reported[path] = true
+			issues = append(issues, LintIssue{
+				Check:   "require_has_guards",
+				Message: fmt.Sprintf("nested field %s is read without a has(%s) guard", path, path),
+			})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Check < issues[j].Check
+	})
+	return issues, nil
+}
+
+// callOperand returns the value a function is applied to, the target of a member call or its first argument
+func callOperand(call ast.CallExpr) ast.Expr {
+	if call.IsMemberFunction() {
+		return call.Target()
+	}
+	if len(call.Args()) > 0 {
+		return call.Args()[0]
+	}
+	return nil
+}
+
+// ternaryDepth returns the deepest nesting of conditional operators in the expression
+func ternaryDepth(root ast.NavigableExpr) int {
+	deepest := 0
+	for _, nav := range ast.MatchDescendants(root, ast.FunctionMatcher(operators.Conditional)) {
+		depth := 1
+		for parent, ok := nav.Parent(); ok; parent, ok = parent.Parent() {
+			if parent.Kind() == ast.CallKind && parent.AsCall().FunctionName() == operators.Conditional {
+				depth++
+			}
+		}
+		deepest = max(deepest, depth)
+	}
+	return deepest
+}
+
+// dynamicType reports whether fields of values of type t may be missing, i.e. it is dyn or a map
+func dynamicType(t *types.Type) bool {
+	return t == nil || t.Kind() == types.DynKind || t.Kind() == types.MapKind
+}
@@ -0,0 +1,94 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_Lint(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/lint_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tests := []struct {
+		name  string
+		rules LintRules
+		want  []LintIssue
+	}{
+		{
+			name:  "success - configured rules",
+			rules: *re.config.Lint,
+			want: []LintIssue{
+				{RuleName: "clean", Check: "max_expression_length", Message: "expression is 73 characters long, the maximum is 60"},
+				{RuleName: "long_expression", Check: "max_expression_length", Message: "expression is 72 characters long, the maximum is 60"},
+				{RuleName: "nested_ternaries", Check: "max_expression_length", Message: "expression is 75 characters long, the maximum is 60"},
+				{RuleName: "nested_ternaries", Check: "max_nested_ternaries", Message: "conditional operators are nested 2 deep, the maximum is 1"},
+				{RuleName: "unbounded_matches", Check: "bounded_matches", Message: "matches() on unbounded input user.bio, check size(user.bio) first"},
+				{RuleName: "unguarded_field", Check: "require_has_guards", Message: "nested field user.address.city is read without a has(user.address.city) guard"},
+			},
+		},
+		{
+			name:  "success - default rules",
+			rules: DefaultLintRules(),
+			want: []LintIssue{
+				{RuleName: "unbounded_matches", Check: "bounded_matches", Message: "matches() on unbounded input user.bio, check size(user.bio) first"},
+				{RuleName: "unguarded_field", Check: "require_has_guards", Message: "nested field user.address.city is read without a has(user.address.city) guard"},
+			},
+		},
+		{
+			name:  "success - no checks",
+			rules: LintRules{},
+			want:  []LintIssue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.Lint(tt.rules)
+			if err != nil {
+				t.Fatalf("Lint() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Lint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		configPath string
+		wantIssues int
+	}{
+		{
+			name:       "success - no lint section",
+			configPath: "./testdata/rules.yml",
+		},
+		{
+			name:       "fail - lint issues",
+			configPath: "./testdata/lint_rules.yml",
+			wantIssues: 6,
+		},
+		{
+			name:       "fail - duplicate and conflicting rules",
+			configPath: "./testdata/conflict_rules.yml",
+			wantIssues: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfigFile(tt.configPath).WithCELEnv(setupEnvironment()(t)).WithVariables("user").Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			err = re.Validate()
+			var issues LintIssues
+			errors.As(err, &issues)
+			if len(issues) != tt.wantIssues || (err != nil) != (tt.wantIssues > 0) {
+				t.Errorf("Validate() error = %v, want %d issues", err, tt.wantIssues)
+			}
+		})
+	}
+}
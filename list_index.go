@@ -0,0 +1,205 @@
+package ruleengine
+
+import (
+	"math"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// defaultListIndexSize is the minimum number of elements of the global lists indexed by default
+const defaultListIndexSize = 64
+
+// WithListIndex sets the minimum number of elements of the global lists indexed when the engine loads
+//
+//	Global lists of strings or integers at least this long are indexed by a hash set, `x in globals.list` then
+//	uses the index and `globals.list.exists(v, v == x)` is rewritten to the same membership test instead of scanning
+//	the list. Lists are indexed from 64 elements by default, a minSize of 0 or less disables indexing
+func WithListIndex(minSize int) Option {
+	return func(re *RuleEngine) {
+		re.listIndexSize = minSize
+	}
+}
+
+// listIndex holds the globals with their large lists replaced by indexed lists
+type listIndex struct {
+	// globals are the config globals with indexed lists
+	globals map[string]interface{}
+	// paths are the `globals.` paths of the indexed lists
+	paths map[string]bool
+}
+
+// buildListIndex indexes the global lists with at least listIndexSize elements, a no-op if there are none
+func (re *RuleEngine) buildListIndex() {
+	if re.listIndexSize <= 0 {
+		return
+	}
+	paths := make(map[string]bool)
+	globals := indexGlobals(re.config.Globals, "globals", re.listIndexSize, paths)
+	if len(paths) > 0 {
+		re.listIndex = &listIndex{globals: globals, paths: paths}
+	}
+}
+
+// globals returns the globals bound to the `globals` variable
+func (re *RuleEngine) globals() map[string]interface{} {
+	if re.listIndex != nil {
+		return re.listIndex.globals
+	}
+	return re.config.Globals
+}
+
+// indexGlobals returns a copy of m with its large lists, including those of nested maps, replaced by indexed lists
+func indexGlobals(m map[string]interface{}, prefix string, minSize int, paths map[string]bool) map[string]interface{} {
+	indexed := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		path := prefix + "." + key
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = indexGlobals(v, path, minSize, paths)
+		case []interface{}:
+			if len(v) >= minSize {
+				if list, ok := newIndexedList(v); ok {
+					value = list
+					paths[path] = true
+				}
+			}
+		}
+		indexed[key] = value
+	}
+	return indexed
+}
+
+// indexedList is a CEL list of strings or integers answering membership tests from a hash set
+type indexedList struct {
+	traits.Lister
+	strings map[string]struct{}
+	ints    map[int64]struct{}
+}
+
+// newIndexedList indexes values, returning false unless they are all strings or all integers
+func newIndexedList(values []interface{}) (*indexedList, bool) {
+	list := &indexedList{Lister: types.DefaultTypeAdapter.NativeToValue(values).(traits.Lister)}
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			if list.strings == nil {
+				list.strings = make(map[string]struct{}, len(values))
+			}
+			list.strings[v] = struct{}{}
+		case int:
+			if list.ints == nil {
+				list.ints = make(map[int64]struct{}, len(values))
+			}
+			list.ints[int64(v)] = struct{}{}
+		case int64:
+			if list.ints == nil {
+				list.ints = make(map[int64]struct{}, len(values))
+			}
+			list.ints[v] = struct{}{}
+		default:
+			return nil, false
+		}
+	}
+	if list.strings != nil && list.ints != nil {
+		return nil, false
+	}
+	return list, true
+}
+
+// Contains implements traits.Container, values other than strings and integers are compared element by element
+func (l *indexedList) Contains(value ref.Val) ref.Val {
+	switch v := value.(type) {
+	case types.String:
+		_, ok := l.strings[string(v)]
+		return types.Bool(ok)
+	case types.Int:
+		_, ok := l.ints[int64(v)]
+		return types.Bool(ok)
+	case types.Uint:
+		if uint64(v) <= math.MaxInt64 {
+			_, ok := l.ints[int64(v)]
+			return types.Bool(ok)
+		}
+	case types.Double:
+		if f := float64(v); f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			_, ok := l.ints[int64(f)]
+			return types.Bool(ok)
+		}
+	}
+	return l.Lister.Contains(value)
+}
+
+// indexLookups rewrites `list.exists(v, v == x)` over indexed lists into `x in list`, nil if there is nothing to rewrite
+func (re *RuleEngine) indexLookups(checked *cel.Ast) (*cel.Ast, error) {
+	if re.listIndex == nil || len(re.listIndex.scans(checked.NativeRep().Expr())) == 0 {
+		return nil, nil
+	}
+	optimized, issues := cel.NewStaticOptimizer(re.listIndex).Optimize(re.env, checked)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return optimized, nil
+}
+
+// Optimize implements cel.ASTOptimizer, replacing scans of indexed lists by membership tests
+func (idx *listIndex) Optimize(ctx *cel.OptimizerContext, a *ast.AST) *ast.AST {
+	for _, scan := range idx.scans(a.Expr()) {
+		ctx.UpdateExpr(scan.comprehension, ctx.NewCall(operators.In, scan.element, scan.list))
+	}
+	return a
+}
+
+// listScan is an `exists(v, v == x)` comprehension over an indexed list
+type listScan struct {
+	comprehension ast.Expr
+	list          ast.Expr
+	element       ast.Expr
+}
+
+// scans finds the `exists(v, v == x)` comprehensions over indexed lists within e, x must not depend on v
+func (idx *listIndex) scans(e ast.Expr) []listScan {
+	scans := make([]listScan, 0)
+	for _, nav := range ast.MatchDescendants(ast.NavigateExpr(nil, e), ast.KindMatcher(ast.ComprehensionKind)) {
+		c := nav.AsComprehension()
+		path, ok := selectPath(c.IterRange())
+		if !ok || !idx.paths[path] || c.HasIterVar2() || c.AccuInit().Kind() != ast.LiteralKind ||
+			c.AccuInit().AsLiteral() != types.False || !isCall(c.LoopStep(), operators.LogicalOr) {
+			continue
+		}
+		step := c.LoopStep().AsCall().Args()
+		if len(step) != 2 || step[0].Kind() != ast.IdentKind || step[0].AsIdent() != c.AccuVar() ||
+			!isCall(step[1], operators.Equals) {
+			continue
+		}
+		args := step[1].AsCall().Args()
+		element := args[1]
+		if !isIdent(args[0], c.IterVar()) {
+			element = args[0]
+			if !isIdent(args[1], c.IterVar()) {
+				continue
+			}
+		}
+		if referencesIdent(element, c.IterVar()) || referencesIdent(element, c.AccuVar()) {
+			continue
+		}
+		scans = append(scans, listScan{comprehension: nav, list: c.IterRange(), element: element})
+	}
+	return scans
+}
+
+// isIdent reports whether e is the identifier name
+func isIdent(e ast.Expr, name string) bool {
+	return e.Kind() == ast.IdentKind && e.AsIdent() == name
+}
+
+// referencesIdent reports whether e refers to the identifier name
+func referencesIdent(e ast.Expr, name string) bool {
+	return len(ast.MatchDescendants(ast.NavigateExpr(nil, e), func(nav ast.NavigableExpr) bool {
+		return isIdent(nav, name)
+	})) > 0
+}
@@ -0,0 +1,175 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/parser"
+)
+
+func TestRuleEngine_ListIndex(t *testing.T) {
+	domains := make([]interface{}, 0, 100)
+	ids := make([]interface{}, 0, 100)
+	for i := 0; i < 100; i++ {
+		domains = append(domains, fmt.Sprintf("domain%d.com", i))
+		ids = append(ids, i*10)
+	}
+	config := func() *RulesetConfig {
+		return &RulesetConfig{
+			Globals: map[string]interface{}{
+				"allowed_domains": domains,
+				"lists":           map[string]interface{}{"blocked_ids": ids},
+				"small":           []interface{}{"a", "b"},
+			},
+			Rules: map[string]Rule{
+				"domain_in":     {Expression: "user.domain in globals.allowed_domains"},
+				"domain_exists": {Expression: "globals.allowed_domains.exists(d, d == user.domain)"},
+				"id_exists":     {Expression: "globals.lists.blocked_ids.exists(i, user.id == i)"},
+				"small_exists":  {Expression: `globals.small.exists(s, s == user.tier)`},
+				"dependent":     {Expression: "globals.allowed_domains.exists(d, d == user.domain + d)"},
+			},
+			ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+			ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		minSize   int
+		user      map[string]interface{}
+		overrides map[string]interface{}
+		want      map[string]bool
+	}{
+		{
+			name:    "success - indexed members",
+			minSize: defaultListIndexSize,
+			user:    map[string]interface{}{"domain": "domain42.com", "id": 420, "tier": "a"},
+			want: map[string]bool{
+				"domain_in": true, "domain_exists": true, "id_exists": true, "small_exists": true, "dependent": false,
+			},
+		},
+		{
+			name:    "success - indexed non members",
+			minSize: defaultListIndexSize,
+			user:    map[string]interface{}{"domain": "other.com", "id": 421, "tier": "c"},
+			want:    map[string]bool{"domain_in": false, "domain_exists": false, "id_exists": false, "small_exists": false},
+		},
+		{
+			name:    "success - indexed numbers of other types",
+			minSize: defaultListIndexSize,
+			user:    map[string]interface{}{"domain": "domain1.com", "id": 420.0, "tier": "b"},
+			want:    map[string]bool{"domain_in": true, "domain_exists": true, "id_exists": true, "small_exists": true},
+		},
+		{
+			name:      "success - overridden globals",
+			minSize:   defaultListIndexSize,
+			user:      map[string]interface{}{"domain": "override.com", "id": 1, "tier": "a"},
+			overrides: map[string]interface{}{"allowed_domains": []interface{}{"override.com"}},
+			want:      map[string]bool{"domain_in": true, "domain_exists": true, "id_exists": false, "small_exists": true},
+		},
+		{
+			name:    "success - indexing disabled",
+			minSize: 0,
+			user:    map[string]interface{}{"domain": "domain42.com", "id": 420, "tier": "a"},
+			want:    map[string]bool{"domain_in": true, "domain_exists": true, "id_exists": true, "small_exists": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfig(config()).
+				WithVariables("user").
+				WithOptions(WithListIndex(tt.minSize)).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			opts := []EvalOption{WithEvalContext(map[string]interface{}{"user": tt.user})}
+			if tt.overrides != nil {
+				opts = append(opts, WithGlobalOverrides(tt.overrides))
+			}
+			for rule, want := range tt.want {
+				got, err := re.EvaluateRule(rule, opts...)
+				if err != nil {
+					t.Fatalf("EvaluateRule(%s) error = %v", rule, err)
+				}
+				if got.Passed != want {
+					t.Errorf("EvaluateRule(%s) passed = %v, want %v, error %v", rule, got.Passed, want, got.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEngine_indexLookups(t *testing.T) {
+	list := make([]interface{}, defaultListIndexSize)
+	for i := range list {
+		list[i] = fmt.Sprint(i)
+	}
+	re, err := NewBuilder().
+		WithConfig(&RulesetConfig{
+			Globals:           map[string]interface{}{"big": list, "small": []interface{}{"a"}},
+			Rules:             map[string]Rule{},
+			ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+			ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+		}).
+		WithVariables("user").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       string
+	}{
+		{
+			name:       "success - exists rewritten",
+			expression: `user.active && globals.big.exists(v, v == user.id)`,
+			want:       "user.active && user.id in globals.big",
+		},
+		{
+			name:       "success - reversed equality rewritten",
+			expression: `globals.big.exists(v, user.id == v)`,
+			want:       "user.id in globals.big",
+		},
+		{
+			name:       "success - small list unchanged",
+			expression: `globals.small.exists(v, v == user.id)`,
+		},
+		{
+			name:       "success - other predicate unchanged",
+			expression: `globals.big.exists(v, v.startsWith(user.id))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checked, err := re.checkExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("checkExpression() error = %v", err)
+			}
+			got, err := re.indexLookups(checked)
+			if err != nil {
+				t.Fatalf("indexLookups() error = %v", err)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("indexLookups() rewrote %s, want it unchanged", tt.expression)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("indexLookups() = nil, want %s", tt.want)
+			}
+			text, err := parser.Unparse(got.NativeRep().Expr(), got.NativeRep().SourceInfo())
+			if err != nil {
+				t.Fatalf("Unparse() error = %v", err)
+			}
+			if strings.TrimSpace(text) != tt.want {
+				t.Errorf("indexLookups() = %s, want %s", text, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package ruleengine
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"google.golang.org/protobuf/proto"
+)
+
+// programBaseBytes and programNodeBytes model the heap held by a compiled program: a fixed overhead plus a cost per
+// AST node, measured with cel-go v0.26 on typical rule expressions
+const (
+	programBaseBytes = 4096
+	programNodeBytes = 128
+)
+
+// MemoryStats estimates the memory held by the compiled rule programs, see RuleEngine.MemoryStats
+type MemoryStats struct {
+	// Rules is the number of compiled rules
+	Rules int
+	// LoadedPrograms is the number of rule programs currently held in memory
+	LoadedPrograms int
+	// MaxPrograms is the cap on loaded programs set with WithMaxPrograms, zero if unlimited
+	MaxPrograms int
+	// ASTNodes is the number of checked AST nodes of the loaded programs
+	ASTNodes int
+	// ASTBytes is the serialized size of the checked ASTs of the loaded programs
+	ASTBytes uint64
+	// ProgramBytes is a rough estimate of the heap held by the loaded programs
+	ProgramBytes uint64
+	// Evictions is the number of programs evicted to stay within MaxPrograms, including while loading
+	Evictions uint64
+	// Recompilations is the number of evicted programs compiled again on demand
+	Recompilations uint64
+}
+
+// WithMaxPrograms caps the number of rule programs held in memory, evicting the least recently used
+//
+//	Every rule is still compiled when loading, so errors are reported upfront. An evaluation of an evicted rule
+//	compiles it again, combine with WithCompileCache to skip parsing and checking. Zero means unlimited
+func WithMaxPrograms(n int) Option {
+	return func(re *RuleEngine) {
+		re.programs.max = n
+	}
+}
+
+// MemoryStats returns an estimate of the memory held by the loaded rule programs
+func (re *RuleEngine) MemoryStats() MemoryStats {
+	if active := re.Active(); active != re {
+		return active.MemoryStats()
+	}
+	loaded := re.programs.names()
+	stats := MemoryStats{
+		Rules:          len(re.footprints),
+		LoadedPrograms: len(loaded),
+		MaxPrograms:    re.programs.max,
+	}
+	for _, name := range loaded {
+		fp := re.footprints[name]
+		stats.ASTNodes += fp.nodes
+		stats.ASTBytes += fp.astBytes
+		stats.ProgramBytes += programBaseBytes + uint64(fp.nodes)*programNodeBytes
+	}
+	stats.Evictions, stats.Recompilations = re.programs.counters()
+	return stats
+}
+
+// footprint is the size of the checked AST of a rule
+type footprint struct {
+	nodes    int
+	astBytes uint64
+}
+
+// newFootprint measures a checked AST
+func newFootprint(checked *cel.Ast) footprint {
+	var fp footprint
+	ast.PostOrderVisit(checked.NativeRep().Expr(), ast.NewExprVisitor(func(ast.Expr) {
+		fp.nodes++
+	}))
+	if pb, err := cel.AstToCheckedExpr(checked); err == nil {
+		fp.astBytes = uint64(proto.Size(pb))
+	}
+	return fp
+}
+
+// recompileRule compiles the program of a rule evicted by WithMaxPrograms
+func (re *RuleEngine) recompileRule(name string) (cel.Program, error) {
+	rule, ok := re.config.Rules[name]
+	if !ok {
+		return nil, fmt.Errorf("program for rule '%s' not found", name)
+	}
+	return re.compileExpression(rule.Expression)
+}
+
+// programStore holds the compiled rule programs, optionally capped with least recently used eviction
+//
+//	Without a cap the programs are only written while loading, so lookups are lock free
+type programStore struct {
+	// max is the maximum number of loaded programs, zero if unlimited
+	max int
+	// recompile compiles an evicted program on demand
+	recompile func(name string) (cel.Program, error)
+
+	mu       sync.Mutex
+	programs map[string]cel.Program
+	// lru orders the loaded program names from most to least recently used, only maintained with a cap
+	lru      *list.List
+	elements map[string]*list.Element
+
+	evictions      uint64
+	recompilations uint64
+}
+
+// newProgramStore creates an empty, uncapped programStore
+func newProgramStore() *programStore {
+	return &programStore{
+		programs: make(map[string]cel.Program),
+		lru:      list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// put stores the program of a rule, evicting the least recently used program when over the cap
+func (s *programStore) put(name string, program cel.Program) {
+	if s.max <= 0 {
+		s.programs[name] = program
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putLocked(name, program)
+}
+
+// putLocked stores a program while holding mu
+func (s *programStore) putLocked(name string, program cel.Program) {
+	if el, ok := s.elements[name]; ok {
+		s.lru.MoveToFront(el)
+	} else {
+		s.elements[name] = s.lru.PushFront(name)
+	}
+	s.programs[name] = program
+	for s.lru.Len() > s.max {
+		oldest := s.lru.Back()
+		evicted := s.lru.Remove(oldest).(string)
+		delete(s.elements, evicted)
+		delete(s.programs, evicted)
+		s.evictions++
+	}
+}
+
+// get returns the program of a rule, compiling it again if it was evicted
+func (s *programStore) get(name string) (cel.Program, error) {
+	if s.max <= 0 {
+		program, ok := s.programs[name]
+		if !ok {
+			return nil, fmt.Errorf("program for rule '%s' not found", name)
+		}
+		return program, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.elements[name]; ok {
+		s.lru.MoveToFront(el)
+		return s.programs[name], nil
+	}
+	if s.recompile == nil {
+		return nil, fmt.Errorf("program for rule '%s' not found", name)
+	}
+	program, err := s.recompile(name)
+	if err != nil {
+		return nil, err
+	}
+	s.recompilations++
+	s.putLocked(name, program)
+	return program, nil
+}
+
+// names returns the names of the loaded programs
+func (s *programStore) names() []string {
+	if s.max > 0 {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	names := make([]string, 0, len(s.programs))
+	for name := range s.programs {
+		names = append(names, name)
+	}
+	return names
+}
+
+// counters returns the number of evictions and recompilations
+func (s *programStore) counters() (uint64, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evictions, s.recompilations
+}
@@ -0,0 +1,94 @@
+package ruleengine
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRuleEngine_MemoryStats(t *testing.T) {
+	ctx := map[string]interface{}{
+		"user": map[string]interface{}{"age": 21, "status": "active", "verified": true, "email": "jane@company.com"},
+	}
+	rules := []string{"active_adult", "age_validation", "email_domain", "email_in_allowlist"}
+
+	tests := []struct {
+		name               string
+		maxPrograms        int
+		wantLoaded         int
+		wantEvictions      uint64
+		wantRecompilations uint64
+	}{
+		{
+			name:       "success - unlimited",
+			wantLoaded: 4,
+		},
+		{
+			name:        "success - capped with recompilation",
+			maxPrograms: 2,
+			wantLoaded:  2,
+			// 2 while loading, then every rule evaluated in order misses the two most recently used
+			wantEvictions:      6,
+			wantRecompilations: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/explanation_rules.yml").
+				WithVariables("user").
+				WithOptions(WithMaxPrograms(tt.maxPrograms)).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+
+			for _, rule := range rules {
+				got, err := re.EvaluateRule(rule, WithEvalContext(ctx))
+				if err != nil || !got.Passed {
+					t.Errorf("EvaluateRule(%s) = %+v, %v, want passed", rule, got, err)
+				}
+			}
+
+			stats := re.MemoryStats()
+			if stats.Rules != 4 || stats.LoadedPrograms != tt.wantLoaded || stats.MaxPrograms != tt.maxPrograms {
+				t.Errorf("MemoryStats() = %+v, want 4 rules, %d loaded", stats, tt.wantLoaded)
+			}
+			if stats.ASTNodes == 0 || stats.ASTBytes == 0 || stats.ProgramBytes < uint64(tt.wantLoaded)*programBaseBytes {
+				t.Errorf("MemoryStats() = %+v, want non-zero estimates", stats)
+			}
+			if stats.Evictions != tt.wantEvictions || stats.Recompilations != tt.wantRecompilations {
+				t.Errorf("MemoryStats() evictions = %d, recompilations = %d, want %d, %d",
+					stats.Evictions, stats.Recompilations, tt.wantEvictions, tt.wantRecompilations)
+			}
+		})
+	}
+}
+
+func TestWithMaxPrograms_Concurrent(t *testing.T) {
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/explanation_rules.yml").
+		WithVariables("user").
+		WithOptions(WithMaxPrograms(1)).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := map[string]interface{}{"user": map[string]interface{}{"age": 21}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, rule := range []string{"age_validation", "email_domain"} {
+				if _, err := re.EvaluateRule(rule, WithEvalContext(ctx)); err != nil {
+					t.Errorf("EvaluateRule(%s) error = %v", rule, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if loaded := re.MemoryStats().LoadedPrograms; loaded != 1 {
+		t.Errorf("MemoryStats() loaded programs = %d, want 1", loaded)
+	}
+}
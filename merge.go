@@ -0,0 +1,103 @@
+package ruleengine
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ListMergeStrategy is how a deep merge combines a list of the overrides with the list it overrides
+type ListMergeStrategy string
+
+const (
+	// ListReplace replaces the base list with the override list, the default
+	ListReplace ListMergeStrategy = "replace"
+	// ListAppend appends the override list to the base list
+	ListAppend ListMergeStrategy = "append"
+	// ListUnique appends the elements of the override list the base list does not already have
+	ListUnique ListMergeStrategy = "unique"
+)
+
+// GlobalsMerge configures how environment and profile globals are merged onto the base globals
+//
+//	globals_merge:
+//	  deep: true
+//	  lists: unique
+//
+//	By default an override replaces the whole global, e.g. an environment setting limits.gold replaces the limits map.
+//	With Deep, nested maps are merged key by key and lists are combined with the Lists strategy, any other value
+//	replaces the base value
+type GlobalsMerge struct {
+	// Deep merges nested maps key by key instead of replacing them
+	Deep bool `yaml:"deep"`
+	// Lists is how lists are combined by a deep merge, ListReplace when empty
+	Lists ListMergeStrategy `yaml:"lists"`
+}
+
+// validate returns an error if the list strategy is unknown
+func (m GlobalsMerge) validate() error {
+	switch m.Lists {
+	case "", ListReplace, ListAppend, ListUnique:
+		return nil
+	}
+	return fmt.Errorf("unknown list merge strategy '%s', want replace, append or unique", m.Lists)
+}
+
+// mergeInto merges overrides onto dst key by key, nested maps and lists of dst are copied, never modified
+func (m GlobalsMerge) mergeInto(dst, overrides map[string]interface{}) {
+	for k, v := range overrides {
+		if base, ok := dst[k]; ok {
+			dst[k] = m.mergeValue(base, v)
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+// mergeValue returns override merged onto base
+func (m GlobalsMerge) mergeValue(base, override interface{}) interface{} {
+	if !m.Deep {
+		return override
+	}
+	switch override := override.(type) {
+	case map[string]interface{}:
+		baseMap, ok := base.(map[string]interface{})
+		if !ok {
+			return override
+		}
+		merged := make(map[string]interface{}, len(baseMap)+len(override))
+		for k, v := range baseMap {
+			merged[k] = v
+		}
+		m.mergeInto(merged, override)
+		return merged
+	case []interface{}:
+		baseList, ok := base.([]interface{})
+		if !ok {
+			return override
+		}
+		switch m.Lists {
+		case ListAppend:
+			merged := make([]interface{}, 0, len(baseList)+len(override))
+			return append(append(merged, baseList...), override...)
+		case ListUnique:
+			merged := append(make([]interface{}, 0, len(baseList)+len(override)), baseList...)
+			for _, v := range override {
+				if !containsValue(merged, v) {
+					merged = append(merged, v)
+				}
+			}
+			return merged
+		}
+	}
+	return override
+}
+
+// containsValue reports whether list holds a value deeply equal to v
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGlobalsMerge_mergeValue(t *testing.T) {
+	base := map[string]interface{}{
+		"limits": map[string]interface{}{
+			"gold":   map[string]interface{}{"daily": 1000, "monthly": 10000},
+			"silver": map[string]interface{}{"daily": 100, "monthly": 1000},
+		},
+		"countries": []interface{}{"AU", "NZ"},
+	}
+	override := map[string]interface{}{
+		"limits": map[string]interface{}{
+			"gold": map[string]interface{}{"daily": 2000},
+		},
+		"countries": []interface{}{"NZ", "GB"},
+	}
+	tests := []struct {
+		name  string
+		merge GlobalsMerge
+		want  interface{}
+	}{
+		{
+			name:  "success - shallow merge replaces nested maps",
+			merge: GlobalsMerge{},
+			want:  override,
+		},
+		{
+			name:  "success - deep merge keeps sibling keys and replaces lists",
+			merge: GlobalsMerge{Deep: true},
+			want: map[string]interface{}{
+				"limits": map[string]interface{}{
+					"gold":   map[string]interface{}{"daily": 2000, "monthly": 10000},
+					"silver": map[string]interface{}{"daily": 100, "monthly": 1000},
+				},
+				"countries": []interface{}{"NZ", "GB"},
+			},
+		},
+		{
+			name:  "success - deep merge appends lists",
+			merge: GlobalsMerge{Deep: true, Lists: ListAppend},
+			want: map[string]interface{}{
+				"limits": map[string]interface{}{
+					"gold":   map[string]interface{}{"daily": 2000, "monthly": 10000},
+					"silver": map[string]interface{}{"daily": 100, "monthly": 1000},
+				},
+				"countries": []interface{}{"AU", "NZ", "NZ", "GB"},
+			},
+		},
+		{
+			name:  "success - deep merge appends unique list elements",
+			merge: GlobalsMerge{Deep: true, Lists: ListUnique},
+			want: map[string]interface{}{
+				"limits": map[string]interface{}{
+					"gold":   map[string]interface{}{"daily": 2000, "monthly": 10000},
+					"silver": map[string]interface{}{"daily": 100, "monthly": 1000},
+				},
+				"countries": []interface{}{"AU", "NZ", "GB"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.merge.mergeValue(base, override)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mergeValue() (-want +got):\n%s", diff)
+			}
+			if gold := base["limits"].(map[string]interface{})["gold"]; !cmp.Equal(gold, map[string]interface{}{"daily": 1000, "monthly": 10000}) {
+				t.Errorf("mergeValue() modified the base: %v", gold)
+			}
+		})
+	}
+}
+
+func TestRulesetConfig_ApplyEnvironment_GlobalsMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		globals map[string]interface{}
+		merge   GlobalsMerge
+		want    map[string]interface{}
+	}{
+		{
+			name:    "success - nil globals",
+			globals: nil,
+			merge:   GlobalsMerge{Deep: true},
+			want:    map[string]interface{}{"limits": map[string]interface{}{"gold": 2000}},
+		},
+		{
+			name:    "success - deep merge",
+			globals: map[string]interface{}{"limits": map[string]interface{}{"gold": 1000, "silver": 100}},
+			merge:   GlobalsMerge{Deep: true},
+			want:    map[string]interface{}{"limits": map[string]interface{}{"gold": 2000, "silver": 100}},
+		},
+		{
+			name:    "success - scalar replaced by a map",
+			globals: map[string]interface{}{"limits": 0},
+			merge:   GlobalsMerge{Deep: true},
+			want:    map[string]interface{}{"limits": map[string]interface{}{"gold": 2000}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &RulesetConfig{
+				Globals:      tt.globals,
+				GlobalsMerge: tt.merge,
+				Environments: map[string]Environment{
+					"production": {
+						Globals: map[string]interface{}{"limits": map[string]interface{}{"gold": 2000}},
+						ErrorHandling: ErrorHandling{
+							CustomErrorMessages: map[string]string{"age_validation": "too young"},
+						},
+					},
+				},
+			}
+			rc.ApplyEnvironment("production")
+			if diff := cmp.Diff(tt.want, rc.Globals); diff != "" {
+				t.Errorf("ApplyEnvironment() globals (-want +got):\n%s", diff)
+			}
+			if rc.ErrorHandling.CustomErrorMessages["age_validation"] != "too young" {
+				t.Errorf("ApplyEnvironment() custom error messages = %v", rc.ErrorHandling.CustomErrorMessages)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_GlobalsMerge(t *testing.T) {
+	config := func(lists ListMergeStrategy) *RulesetConfig {
+		return &RulesetConfig{
+			Globals: map[string]interface{}{
+				"limits":    map[string]interface{}{"gold": 1000, "silver": 100},
+				"countries": []interface{}{"AU"},
+			},
+			GlobalsMerge: GlobalsMerge{Deep: true, Lists: lists},
+			Rules: map[string]Rule{
+				"within_limit": {Expression: "user.spend <= globals.limits[user.tier]"},
+				"allowed":      {Expression: "user.country in globals.countries"},
+			},
+			Rulesets: map[string]Ruleset{
+				"checkout": {Selector: selectorAnd, Rules: []string{"within_limit", "allowed"}},
+			},
+			ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+			ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+			Profiles: map[string]Profile{
+				"gb": {Globals: map[string]interface{}{
+					"limits":    map[string]interface{}{"gold": 500},
+					"countries": []interface{}{"GB"},
+				}},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		lists   ListMergeStrategy
+		user    map[string]interface{}
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:  "success - profile merges nested limits",
+			lists: ListAppend,
+			user:  map[string]interface{}{"spend": 100, "tier": "silver", "country": "GB"},
+			want:  true,
+		},
+		{
+			name:  "success - profile overrides a nested limit",
+			lists: ListAppend,
+			user:  map[string]interface{}{"spend": 800, "tier": "gold", "country": "AU"},
+			want:  false,
+		},
+		{
+			name:  "success - replaced list drops base elements",
+			lists: ListReplace,
+			user:  map[string]interface{}{"spend": 100, "tier": "silver", "country": "AU"},
+			want:  false,
+		},
+		{
+			name:    "fail - unknown list strategy",
+			lists:   "merge",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(config(tt.lists)).WithVariables("user").Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			result, err := re.EvaluateRuleset("checkout",
+				WithEvalContext(map[string]interface{}{"user": tt.user}),
+				WithEvalProfile("gb"))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if result.Passed != tt.want {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", result.Passed, tt.want)
+			}
+		})
+	}
+}
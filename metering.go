@@ -0,0 +1,51 @@
+package ruleengine
+
+// Usage is the usage of one evaluation of a tenant in a Registry, reported to its MeterFunc
+type Usage struct {
+	// Tenant is the name of the tenant
+	Tenant string
+	// Decisions is the number of rulesets evaluated, one for the evaluation of a single rule
+	Decisions int
+	// CostUnits is the number of rule expressions evaluated, a rule extending others costs one unit per rule of its
+	// chain and skipped rules cost nothing
+	CostUnits int
+}
+
+// MeterFunc receives the Usage of every evaluation, e.g. to bill tenants by decision volume
+type MeterFunc func(usage Usage)
+
+// SetMeter reports the Usage of every evaluation the quota of a tenant allowed to meter, nil stops metering
+//
+//	The meter is called synchronously once the evaluation returns, from the goroutine which evaluated. Evaluations
+//	rejected by the quota are not metered, failed evaluations are metered with the rulesets they returned
+func (r *Registry) SetMeter(meter MeterFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.meter = meter
+}
+
+// metered reports the usage of an evaluation of the tenant to the meter, if any
+func (r *Registry) metered(name string, engine *RuleEngine, results ...RulesetResult) {
+	r.mu.RLock()
+	meter := r.meter
+	r.mu.RUnlock()
+	if meter == nil {
+		return
+	}
+	usage := Usage{Tenant: name, Decisions: len(results)}
+	for _, result := range results {
+		usage.CostUnits += engine.costUnits(result.RuleResults)
+	}
+	meter(usage)
+}
+
+// costUnits returns the number of rule expressions evaluated to produce the rule results
+func (re *RuleEngine) costUnits(results map[string]RuleResult) int {
+	units := 0
+	for name, result := range results {
+		if !result.Skipped {
+			units += 1 + len(re.parents[name])
+		}
+	}
+	return units
+}
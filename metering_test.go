@@ -0,0 +1,85 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistry_SetMeter(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := WithEvalContext(map[string]interface{}{
+		"user":    map[string]interface{}{"age": 20, "email": "test@example.com", "status": "active", "suspended": false, "tier": "free"},
+		"request": map[string]interface{}{"attempt": 1},
+	})
+
+	tests := []struct {
+		name      string
+		evaluate  func(r *Registry) error
+		wantUsage []Usage
+	}{
+		{
+			name: "success - rule and the rules it extends",
+			evaluate: func(r *Registry) error {
+				_, err := r.EvaluateRule("acme", "test_user", ctx)
+				return err
+			},
+			wantUsage: []Usage{{Tenant: "acme", Decisions: 1, CostUnits: 3}},
+		},
+		{
+			name: "success - ruleset",
+			evaluate: func(r *Registry) error {
+				_, err := r.EvaluateRuleset("acme", "user_registration", ctx)
+				return err
+			},
+			wantUsage: []Usage{{Tenant: "acme", Decisions: 1, CostUnits: 3}},
+		},
+		{
+			name: "success - all rulesets",
+			evaluate: func(r *Registry) error {
+				_, err := r.EvaluateAllRulesets("acme", ctx)
+				return err
+			},
+			wantUsage: []Usage{{Tenant: "acme", Decisions: 3, CostUnits: 7}},
+		},
+		{
+			name: "success - throttled evaluation not metered",
+			evaluate: func(r *Registry) error {
+				if err := r.Register("acme", re, TenantQuota{QPS: 1}); err != nil {
+					return err
+				}
+				_, _ = r.EvaluateRule("acme", "age_validation", ctx)
+				_, _ = r.EvaluateRule("acme", "age_validation", ctx)
+				return nil
+			},
+			wantUsage: []Usage{{Tenant: "acme", Decisions: 1, CostUnits: 1}},
+		},
+		{
+			name: "success - failed evaluation metered without decisions",
+			evaluate: func(r *Registry) error {
+				_, _ = r.EvaluateRuleset("acme", "user_registration", WithEvalContext(nil), WithEvalProfile("unknown"))
+				return nil
+			},
+			wantUsage: []Usage{{Tenant: "acme"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			if err := registry.Register("acme", re, TenantQuota{}); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			usage := make([]Usage, 0)
+			registry.SetMeter(func(u Usage) { usage = append(usage, u) })
+			if err := tt.evaluate(registry); err != nil {
+				t.Fatalf("evaluate error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantUsage, usage); diff != "" {
+				t.Errorf("metered usage mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package ruleengine
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// MirrorStats reports the evaluations mirrored to a candidate engine, see WithMirror
+type MirrorStats struct {
+	// Mirrored is the number of ruleset evaluations also run against the candidate
+	Mirrored uint64
+	// Mismatches is the number of mirrored evaluations whose outcome differed from the primary result
+	Mismatches uint64
+	// Errors is the number of mirrored evaluations the candidate failed, e.g. for a ruleset it does not have
+	Errors uint64
+}
+
+// MirrorMismatch is emitted when the candidate engine of WithMirror decides a ruleset evaluation differently
+//
+//	It is emitted from the goroutine of the mirrored evaluation, so the event handler may be called concurrently
+type MirrorMismatch struct {
+	// RulesetName is the name of the evaluated ruleset
+	RulesetName string
+	// Diff compares the primary result, A, with the candidate result, B
+	Diff ResultDiff
+}
+
+// EventName implements Event
+func (MirrorMismatch) EventName() string {
+	return "mirror_mismatch"
+}
+
+// WithMirror mirrors a percentage of ruleset evaluations, between 0 and 100, to the candidate engine
+//
+//	Mirrored evaluations run asynchronously and never affect the primary result or its latency, their outcome is
+//	compared with the primary one, counted in MirrorStats and mismatches are emitted as MirrorMismatch events.
+//	Unlike Stage, the candidate may use a different environment, CEL env or options
+func WithMirror(candidate *RuleEngine, percent float64) Option {
+	return func(re *RuleEngine) {
+		if candidate != nil && percent > 0 {
+			re.mirror = &mirror{candidate: candidate, rate: percent / 100}
+		}
+	}
+}
+
+// MirrorStats returns the evaluations mirrored to the candidate engine, empty unless enabled with WithMirror
+func (re *RuleEngine) MirrorStats() MirrorStats {
+	if active := re.Active(); active != re {
+		return active.MirrorStats()
+	}
+	if re.mirror == nil {
+		return MirrorStats{}
+	}
+	return MirrorStats{
+		Mirrored:   re.mirror.mirrored.Load(),
+		Mismatches: re.mirror.mismatches.Load(),
+		Errors:     re.mirror.errors.Load(),
+	}
+}
+
+// mirror is the candidate engine receiving a sample of the evaluations
+type mirror struct {
+	candidate *RuleEngine
+	rate      float64
+
+	mirrored   atomic.Uint64
+	mismatches atomic.Uint64
+	errors     atomic.Uint64
+	// inflight tracks the running mirrored evaluations
+	inflight sync.WaitGroup
+}
+
+// mirrorEvaluation evaluates the ruleset with the candidate in the background, if sampled, and compares it with the
+// primary result
+func (re *RuleEngine) mirrorEvaluation(rulesetName string, opts []EvalOption, primary RulesetResult) {
+	m := re.mirror
+	if m == nil || (m.rate < 1 && rand.Float64() >= m.rate) {
+		return
+	}
+	// The context is copied before returning, the caller may reuse it once the primary result is returned
+	candidate := m.candidate.Active()
+	eval, err := candidate.newEvaluation(append(append([]EvalOption{WithEvalContext(re.context)}, opts...), withoutProgress()))
+	m.mirrored.Add(1)
+	if err != nil {
+		m.errors.Add(1)
+		return
+	}
+	m.inflight.Add(1)
+	go func() {
+		defer m.inflight.Done()
+		result, err := candidate.evaluateRuleset(rulesetName, eval)
+		if err != nil {
+			m.errors.Add(1)
+			return
+		}
+		if primary.Passed != result.Passed {
+			m.mismatches.Add(1)
+			re.emit(MirrorMismatch{RulesetName: rulesetName, Diff: DiffResults(primary, result)})
+		}
+	}()
+}
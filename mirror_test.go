@@ -0,0 +1,110 @@
+package ruleengine
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_Mirror(t *testing.T) {
+	candidate, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 21")).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	other := rolloutConfig("user.age >= 21")
+	other.Rulesets = map[string]Ruleset{"other": {Selector: "AND", Rules: []string{"age_validation"}}}
+	missing, err := NewBuilder().WithConfig(other).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		candidate  *RuleEngine
+		percent    float64
+		ages       []int
+		setContext bool
+		want       MirrorStats
+		wantEvents int
+	}{
+		{
+			name:       "success - mismatches counted",
+			candidate:  candidate,
+			percent:    100,
+			ages:       []int{16, 19, 30},
+			want:       MirrorStats{Mirrored: 3, Mismatches: 1},
+			wantEvents: 1,
+		},
+		{
+			name:       "success - engine context mirrored",
+			candidate:  candidate,
+			percent:    100,
+			ages:       []int{19},
+			setContext: true,
+			want:       MirrorStats{Mirrored: 1, Mismatches: 1},
+			wantEvents: 1,
+		},
+		{
+			name:      "success - disabled",
+			candidate: candidate,
+			ages:      []int{19},
+		},
+		{
+			name:      "fail - candidate without the ruleset",
+			candidate: missing,
+			percent:   100,
+			ages:      []int{19},
+			want:      MirrorStats{Mirrored: 1, Errors: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			events := 0
+			re, err := NewBuilder().
+				WithConfig(rolloutConfig("user.age >= 18")).
+				WithVariables("user").
+				WithOptions(
+					WithMirror(tt.candidate, tt.percent),
+					WithEventHandler(func(e Event) {
+						if _, ok := e.(MirrorMismatch); ok {
+							mu.Lock()
+							events++
+							mu.Unlock()
+						}
+					}),
+				).
+				Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			for _, age := range tt.ages {
+				ctx := map[string]interface{}{"user": map[string]interface{}{"age": age}}
+				var result RulesetResult
+				if tt.setContext {
+					re.SetContext(ctx)
+					result, err = re.EvaluateRuleset("adult")
+				} else {
+					result, err = re.EvaluateRuleset("adult", WithEvalContext(ctx))
+				}
+				if err != nil {
+					t.Fatalf("EvaluateRuleset() error = %v", err)
+				}
+				if result.Passed != (age >= 18) {
+					t.Errorf("EvaluateRuleset() passed = %v, want the primary result", result.Passed)
+				}
+			}
+			if re.mirror != nil {
+				re.mirror.inflight.Wait()
+			}
+
+			if diff := cmp.Diff(tt.want, re.MirrorStats()); diff != "" {
+				t.Errorf("MirrorStats() mismatch (-want +got):\n%s", diff)
+			}
+			if events != tt.wantEvents {
+				t.Errorf("got %d MirrorMismatch events, want %d", events, tt.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnknownRule is the error of rulesets referencing a rule the config does not define
+var ErrUnknownRule = errors.New("unknown rule")
+
+// WithSkipMissingRules skips the rules referenced by rulesets but not defined by the config instead of failing to
+// load the engine, so a config can reference rules a later release defines
+//
+//	Missing rules are skipped during evaluation with an error wrapping ErrUnknownRule and reported in
+//	RulesetResult.Warnings, see MissingRules
+func WithSkipMissingRules() Option {
+	return func(re *RuleEngine) {
+		re.missingRules = make(map[string]bool)
+	}
+}
+
+// MissingRules returns the names of the rules referenced by rulesets but not defined by the config sorted, none
+// unless WithSkipMissingRules
+func (re *RuleEngine) MissingRules() []string {
+	if active := re.Active(); active != re {
+		return active.MissingRules()
+	}
+	names := make([]string, 0, len(re.missingRules))
+	for name := range re.missingRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveRulesetRules checks the rules of every ruleset are defined by the config, recording the missing ones when
+// WithSkipMissingRules
+//
+//	Errors wrap ErrUnknownRule and join one error per missing rule of a ruleset
+func (re *RuleEngine) resolveRulesetRules() error {
+	var errs []error
+	for _, rulesetName := range re.rulesetNames() {
+		for _, name := range re.config.Rulesets[rulesetName].Rules {
+			if _, ok := re.config.Rules[name]; ok {
+				continue
+			}
+			if re.missingRules != nil {
+				re.missingRules[name] = true
+				continue
+			}
+			errs = append(errs, fmt.Errorf("ruleset '%s' references %w '%s'", rulesetName, ErrUnknownRule, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// missingResult returns the skipped result of a missing rule, false if the rule is not missing
+func (re *RuleEngine) missingResult(ruleName string) (RuleResult, bool) {
+	if !re.missingRules[ruleName] {
+		return RuleResult{}, false
+	}
+	return RuleResult{
+		RuleName: ruleName,
+		Skipped:  true,
+		Error:    fmt.Errorf("%w '%s'", ErrUnknownRule, ruleName),
+	}, true
+}
@@ -0,0 +1,98 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithSkipMissingRules(t *testing.T) {
+	tests := []struct {
+		name         string
+		selector     selectorType
+		rules        []string
+		skip         bool
+		wantErr      error
+		wantMissing  []string
+		wantPassed   bool
+		wantWarnings []string
+	}{
+		{
+			name:         "success - missing rule skipped with a warning",
+			selector:     "AND",
+			rules:        []string{"age_validation", "future_rule"},
+			skip:         true,
+			wantMissing:  []string{"future_rule"},
+			wantPassed:   true,
+			wantWarnings: []string{"future_rule"},
+		},
+		{
+			name:         "success - missing rule does not pass an OR ruleset",
+			selector:     "OR",
+			rules:        []string{"future_rule"},
+			skip:         true,
+			wantMissing:  []string{"future_rule"},
+			wantPassed:   false,
+			wantWarnings: []string{"future_rule"},
+		},
+		{
+			name:        "success - nothing missing",
+			selector:    "AND",
+			rules:       []string{"age_validation"},
+			skip:        true,
+			wantMissing: []string{},
+			wantPassed:  true,
+		},
+		{
+			name:     "fail - missing rule without skipping",
+			selector: "AND",
+			rules:    []string{"age_validation", "future_rule"},
+			wantErr:  ErrUnknownRule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := rolloutConfig("user.age >= 18")
+			config.Rulesets["adult"] = Ruleset{Selector: tt.selector, Rules: tt.rules}
+			var opts []Option
+			if tt.skip {
+				opts = append(opts, WithSkipMissingRules())
+			}
+			re, err := NewBuilder().WithConfig(config).WithVariables("user").WithOptions(opts...).Build()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Build() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.wantMissing, re.MissingRules()); diff != "" {
+				t.Errorf("MissingRules() mismatch (-want +got):\n%s", diff)
+			}
+
+			ctx := map[string]interface{}{"user": map[string]interface{}{"age": 19}}
+			result, err := re.EvaluateRuleset("adult", WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+			if diff := cmp.Diff(tt.wantWarnings, result.Warnings); diff != "" {
+				t.Errorf("EvaluateRuleset() warnings mismatch (-want +got):\n%s", diff)
+			}
+			for _, name := range tt.wantMissing {
+				if ruleResult := result.RuleResults[name]; !ruleResult.Skipped || !errors.Is(ruleResult.Error, ErrUnknownRule) {
+					t.Errorf("EvaluateRuleset() rule '%s' = %+v, want skipped with %v", name, ruleResult, ErrUnknownRule)
+				}
+			}
+			passed, err := re.EvaluateRulesetBool("adult", ctx)
+			if err != nil {
+				t.Fatalf("EvaluateRulesetBool() error = %v", err)
+			}
+			if passed != tt.wantPassed {
+				t.Errorf("EvaluateRulesetBool() = %v, want %v", passed, tt.wantPassed)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+)
+
+// MongoOptions configures the translation of rules into MongoDB filter documents
+type MongoOptions struct {
+	// Variable is the context variable documents are bound to, e.g. "user" maps `user.age` to the field `age`
+	//	Paths of other variables are used as-is
+	Variable string
+	// Fields is a map of context paths to the document field to use, e.g. "user.age" to "profile.age"
+	Fields map[string]string
+}
+
+// MongoTranslation is the result of translating rules into MongoDB filter documents
+type MongoTranslation struct {
+	// Filters is a map of rule names to the filter matching the documents the rule passes for
+	Filters map[string]map[string]interface{}
+	// Untranslatable is a map of rule names to the reason they could not be translated
+	Untranslatable map[string]string
+}
+
+// TranslateMongo converts the rules, together with the rules they extend, into MongoDB filter documents where possible
+//
+//	The same expressions as TranslateSQL are supported. Filters are plain maps which encode as the equivalent BSON
+//	documents with the MongoDB driver, e.g. `user.age >= 18` becomes {"age": {"$gte": 18}} with Variable "user"
+func (re *RuleEngine) TranslateMongo(opts MongoOptions) MongoTranslation {
+	if active := re.Active(); active != re {
+		return active.TranslateMongo(opts)
+	}
+	translation := MongoTranslation{
+		Filters:        make(map[string]map[string]interface{}),
+		Untranslatable: make(map[string]string),
+	}
+	t := mongoTranslator{re: re, opts: opts}
+	for _, name := range re.ruleNames() {
+		chain := append([]string{name}, re.parents[name]...)
+		filters := make([]map[string]interface{}, 0, len(chain))
+		var err error
+		for i := len(chain) - 1; i >= 0 && err == nil; i-- {
+			var filter map[string]interface{}
+			filter, err = t.rule(chain[i])
+			filters = append(filters, filter)
+		}
+		if err != nil {
+			translation.Untranslatable[name] = err.Error()
+			continue
+		}
+		translation.Filters[name] = mongoAnd(filters)
+	}
+	return translation
+}
+
+// mongoTranslator translates checked expressions into MongoDB filters
+type mongoTranslator struct {
+	re   *RuleEngine
+	opts MongoOptions
+}
+
+// mongoOperators maps CEL comparison operators to MongoDB query operators
+var mongoOperators = map[string]string{
+	operators.Equals:        "$eq",
+	operators.NotEquals:     "$ne",
+	operators.Less:          "$lt",
+	operators.LessEquals:    "$lte",
+	operators.Greater:       "$gt",
+	operators.GreaterEquals: "$gte",
+}
+
+// mongoFlipped maps comparison operators to their equivalent with the operands swapped
+var mongoFlipped = map[string]string{
+	"$eq":  "$eq",
+	"$ne":  "$ne",
+	"$lt":  "$gt",
+	"$lte": "$gte",
+	"$gt":  "$lt",
+	"$gte": "$lte",
+}
+
+// rule translates the expression of a single rule
+func (t mongoTranslator) rule(name string) (map[string]interface{}, error) {
+	rule := t.re.config.Rules[name]
+	if rule.When != "" {
+		return nil, fmt.Errorf("rule '%s' has a when clause", name)
+	}
+	checked, err := t.re.checkExpression(rule.Expression)
+	if err != nil {
+		return nil, err
+	}
+	return t.predicate(checked.NativeRep().Expr())
+}
+
+// predicate translates a boolean expression
+func (t mongoTranslator) predicate(e ast.Expr) (map[string]interface{}, error) {
+	if value, ok := t.re.constantValue(e); ok {
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("unsupported constant %v", value)
+		}
+		if b {
+			return map[string]interface{}{}, nil
+		}
+		return map[string]interface{}{"$expr": false}, nil
+	}
+	if path, ok := selectPath(e); ok {
+		return map[string]interface{}{t.field(path): true}, nil
+	}
+	switch e.Kind() {
+	case ast.SelectKind:
+		if sel := e.AsSelect(); sel.IsTestOnly() {
+			path, ok := selectPath(sel.Operand())
+			if !ok {
+				return nil, fmt.Errorf("unsupported has() operand")
+			}
+			return map[string]interface{}{t.field(path + "." + sel.FieldName()): map[string]interface{}{"$exists": true}}, nil
+		}
+	case ast.CallKind:
+		call := e.AsCall()
+		fn, args := call.FunctionName(), call.Args()
+		if call.IsMemberFunction() {
+			return nil, fmt.Errorf("unsupported function '%s'", fn)
+		}
+		switch fn {
+		case operators.LogicalAnd, operators.LogicalOr:
+			filters := make([]map[string]interface{}, len(args))
+			for i, arg := range args {
+				filter, err := t.predicate(arg)
+				if err != nil {
+					return nil, err
+				}
+				filters[i] = filter
+			}
+			if fn == operators.LogicalAnd {
+				return mongoAnd(filters), nil
+			}
+			return mongoOr(filters), nil
+		case operators.LogicalNot:
+			filter, err := t.predicate(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"$nor": []interface{}{filter}}, nil
+		case operators.In:
+			return t.in(args[0], args[1])
+		}
+		if op, ok := mongoOperators[fn]; ok {
+			return t.comparison(op, args[0], args[1])
+		}
+		return nil, fmt.Errorf("unsupported function '%s'", fn)
+	}
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+// comparison translates a comparison between a field and a constant
+func (t mongoTranslator) comparison(op string, left, right ast.Expr) (map[string]interface{}, error) {
+	if _, ok := t.re.constantValue(left); ok {
+		left, right, op = right, left, mongoFlipped[op]
+	}
+	path, ok := selectPath(left)
+	if !ok || strings.HasPrefix(path, "globals.") {
+		return nil, fmt.Errorf("unsupported comparison, only fields compared with constants are supported")
+	}
+	value, ok := t.re.constantValue(right)
+	if !ok {
+		return nil, fmt.Errorf("unsupported comparison, only fields compared with constants are supported")
+	}
+	if op == "$eq" {
+		return map[string]interface{}{t.field(path): value}, nil
+	}
+	return map[string]interface{}{t.field(path): map[string]interface{}{op: value}}, nil
+}
+
+// in translates membership of a field in a list of constants
+func (t mongoTranslator) in(elem, list ast.Expr) (map[string]interface{}, error) {
+	path, ok := selectPath(elem)
+	if !ok || strings.HasPrefix(path, "globals.") {
+		return nil, fmt.Errorf("unsupported 'in' operand, only fields are supported")
+	}
+	value, ok := t.re.constantValue(list)
+	if !ok {
+		return nil, fmt.Errorf("unsupported 'in' operand, only lists of constants are supported")
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unsupported 'in' operand, only lists are supported")
+	}
+	return map[string]interface{}{t.field(path): map[string]interface{}{"$in": values}}, nil
+}
+
+// field returns the document field for a context path
+func (t mongoTranslator) field(path string) string {
+	if field, ok := t.opts.Fields[path]; ok {
+		return field
+	}
+	if t.opts.Variable != "" {
+		if field, ok := strings.CutPrefix(path, t.opts.Variable+"."); ok {
+			return field
+		}
+	}
+	return path
+}
+
+// mongoAnd combines filters which must all match, flattening nested $and and dropping empty filters
+func mongoAnd(filters []map[string]interface{}) map[string]interface{} {
+	return mongoCombine("$and", filters)
+}
+
+// mongoOr combines filters of which one must match, flattening nested $or
+func mongoOr(filters []map[string]interface{}) map[string]interface{} {
+	return mongoCombine("$or", filters)
+}
+
+// mongoCombine combines filters with the logical operator op
+func mongoCombine(op string, filters []map[string]interface{}) map[string]interface{} {
+	clauses := make([]interface{}, 0, len(filters))
+	for _, filter := range filters {
+		if nested, ok := filter[op].([]interface{}); ok && len(filter) == 1 {
+			clauses = append(clauses, nested...)
+			continue
+		}
+		if op == "$and" && len(filter) == 0 {
+			// An empty filter matches every document
+			continue
+		}
+		clauses = append(clauses, filter)
+	}
+	switch len(clauses) {
+	case 0:
+		return map[string]interface{}{}
+	case 1:
+		return clauses[0].(map[string]interface{})
+	}
+	return map[string]interface{}{op: clauses}
+}
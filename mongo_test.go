@@ -0,0 +1,103 @@
+package ruleengine
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_TranslateMongo(t *testing.T) {
+	tests := []struct {
+		name               string
+		rules              map[string]Rule
+		opts               MongoOptions
+		wantFilters        map[string]map[string]interface{}
+		wantUntranslatable []string
+	}{
+		{
+			name: "success - comparisons and globals",
+			rules: map[string]Rule{
+				"adult":   {Expression: "globals.min_age <= user.age"},
+				"active":  {Expression: `user.status == "active" && user.verified && has(user.email)`},
+				"country": {Expression: `user.country in globals.countries || user.country == null`},
+				"tier":    {Expression: `!(user.tier in ["free", "trial"])`},
+				"always":  {Expression: "true"},
+			},
+			opts: MongoOptions{Variable: "user"},
+			wantFilters: map[string]map[string]interface{}{
+				"adult": {"age": map[string]interface{}{"$gte": int64(18)}},
+				"active": {"$and": []interface{}{
+					map[string]interface{}{"status": "active"},
+					map[string]interface{}{"verified": true},
+					map[string]interface{}{"email": map[string]interface{}{"$exists": true}},
+				}},
+				"country": {"$or": []interface{}{
+					map[string]interface{}{"country": map[string]interface{}{"$in": []interface{}{"NZ", "AU"}}},
+					map[string]interface{}{"country": nil},
+				}},
+				"tier": {"$nor": []interface{}{
+					map[string]interface{}{"tier": map[string]interface{}{"$in": []interface{}{"free", "trial"}}},
+				}},
+				"always": {},
+			},
+			wantUntranslatable: []string{},
+		},
+		{
+			name: "success - extended rules and fields",
+			rules: map[string]Rule{
+				"adult":    {Expression: "user.age >= 18"},
+				"adult_nz": {Expression: `user.country != "AU"`, Extends: "adult"},
+			},
+			opts: MongoOptions{Fields: map[string]string{"user.age": "profile.age"}},
+			wantFilters: map[string]map[string]interface{}{
+				"adult": {"profile.age": map[string]interface{}{"$gte": int64(18)}},
+				"adult_nz": {"$and": []interface{}{
+					map[string]interface{}{"profile.age": map[string]interface{}{"$gte": int64(18)}},
+					map[string]interface{}{"user.country": map[string]interface{}{"$ne": "AU"}},
+				}},
+			},
+			wantUntranslatable: []string{},
+		},
+		{
+			name: "fail - untranslatable rules",
+			rules: map[string]Rule{
+				"fields":   {Expression: "user.age > user.min_age"},
+				"function": {Expression: `user.email.endsWith("@example.com")`},
+				"macro":    {Expression: `user.tags.exists(t, t == "vip")`},
+				"guarded":  {Expression: "user.age > 18", When: "user.active"},
+			},
+			wantFilters:        map[string]map[string]interface{}{},
+			wantUntranslatable: []string{"fields", "function", "guarded", "macro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(&RulesetConfig{
+				Globals: map[string]interface{}{
+					"min_age":   int64(18),
+					"countries": []interface{}{"NZ", "AU"},
+				},
+				Rules:             tt.rules,
+				ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+				ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+			}).WithVariables("user").Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			got := re.TranslateMongo(tt.opts)
+			if diff := cmp.Diff(got.Filters, tt.wantFilters); diff != "" {
+				t.Errorf("TranslateMongo() filters (-got +want):\n%s", diff)
+			}
+			untranslatable := make([]string, 0, len(got.Untranslatable))
+			for name := range got.Untranslatable {
+				untranslatable = append(untranslatable, name)
+			}
+			sort.Strings(untranslatable)
+			if diff := cmp.Diff(untranslatable, tt.wantUntranslatable); diff != "" {
+				t.Errorf("TranslateMongo() untranslatable (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,686 @@
+package ruleengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ImportOPA converts a simple OPA policy, a Rego module and an optional JSON data document, into a RulesetConfig
+//
+//	Every rule not referenced by another rule becomes a ruleset. Each body of a boolean rule such as `allow` becomes a
+//	rule and the ruleset passes when one of them does. Each body of a partial set rule such as `deny[msg]` becomes a
+//	rule passing when the body does not hold and the ruleset passes when none of them hold, a literal `msg` is used as
+//	the custom error message. Rules referenced by other rules become functions.
+//
+//	`input.<name>` references map to the context variable <name>, `data.<name>` to the global <name> and the values of
+//	the data document become globals. Errors are returned for constructs without a CEL equivalent, e.g. local
+//	variables, iteration other than `x[_] == y`, `else` chains, rules with arguments and defaults other than false
+func ImportOPA(policy []byte, data []byte) (*RulesetConfig, error) {
+	tokens, err := lexRego(string(policy))
+	if err != nil {
+		return nil, err
+	}
+	module, err := parseRego(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &RulesetConfig{
+		APIVersion: "v1",
+		Kind:       "RulesetConfig",
+		Metadata: Metadata{
+			Name:        module.pkg,
+			Description: fmt.Sprintf("Imported from OPA package %s", module.pkg),
+		},
+		Globals:   make(map[string]interface{}),
+		Functions: make(map[string]string),
+		Rules:     make(map[string]Rule),
+		Rulesets:  make(map[string]Ruleset),
+		ExecutionPolicies: map[string]ExecutionPolicy{
+			"collect_all": {
+				Name:        "Collect All Results",
+				Description: "Execute all rules regardless of failures",
+			},
+		},
+		ErrorHandling: ErrorHandling{
+			ExecutionPolicy:     "collect_all",
+			CustomErrorMessages: make(map[string]string),
+		},
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &config.Globals); err != nil {
+			return nil, fmt.Errorf("failed to parse data document: %w", err)
+		}
+	}
+
+	im := regoImporter{rules: module.rules, helpers: module.helpers()}
+	for _, name := range module.names {
+		rule := module.rules[name]
+		if im.helpers[name] {
+			if rule.partial {
+				return nil, fmt.Errorf("partial set rule '%s' cannot be referenced by other rules", name)
+			}
+			bodies := make([]string, 0, len(rule.bodies))
+			for _, body := range rule.bodies {
+				expr, _, err := im.body(body, "")
+				if err != nil {
+					return nil, fmt.Errorf("rule '%s': %w", name, err)
+				}
+				bodies = append(bodies, expr)
+			}
+			config.Functions[name] = joinRego(bodies, " || ")
+			continue
+		}
+
+		ruleset := Ruleset{
+			Name:        name,
+			Description: fmt.Sprintf("Imported from %s.%s", module.pkg, name),
+			Selector:    selectorOr,
+		}
+		if rule.partial {
+			ruleset.Selector = selectorAnd
+		}
+		for i, body := range rule.bodies {
+			ruleName := name
+			if len(rule.bodies) > 1 {
+				ruleName = fmt.Sprintf("%s_%d", name, i+1)
+			}
+			expr, message, err := im.body(body, rule.key)
+			if err != nil {
+				return nil, fmt.Errorf("rule '%s': %w", name, err)
+			}
+			if rule.partial {
+				expr = "!(" + expr + ")"
+			}
+			config.Rules[ruleName] = Rule{
+				Name:        ruleName,
+				Description: fmt.Sprintf("Imported from %s.%s line %d", module.pkg, name, body.line),
+				Expression:  expr,
+			}
+			if message != "" {
+				config.ErrorHandling.CustomErrorMessages[ruleName] = message
+			}
+			ruleset.Rules = append(ruleset.Rules, ruleName)
+		}
+		config.Rulesets[name] = ruleset
+	}
+	return config, nil
+}
+
+// regoTokenKind is the kind of a Rego token
+type regoTokenKind int
+
+const (
+	regoIdent regoTokenKind = iota
+	regoString
+	regoNumber
+	regoOp
+	regoNewline
+)
+
+// regoToken is a lexical token of a Rego module, the text of strings is unquoted
+type regoToken struct {
+	kind regoTokenKind
+	text string
+	line int
+}
+
+// is reports whether the token is the operator or identifier text
+func (t regoToken) is(text string) bool {
+	return (t.kind == regoOp || t.kind == regoIdent) && t.text == text
+}
+
+// regoNumberPattern matches a Rego number literal
+var regoNumberPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?`)
+
+// lexRego splits a Rego module into tokens, dropping comments
+func lexRego(src string) ([]regoToken, error) {
+	tokens := make([]regoToken, 0)
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			tokens = append(tokens, regoToken{kind: regoNewline, line: line})
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			text, err := strconv.Unquote(src[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid string: %w", line, err)
+			}
+			tokens = append(tokens, regoToken{kind: regoString, text: text, line: line})
+			i = end + 1
+		case c == '`':
+			end := strings.IndexByte(src[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated raw string", line)
+			}
+			text := src[i+1 : i+1+end]
+			tokens = append(tokens, regoToken{kind: regoString, text: text, line: line})
+			line += strings.Count(text, "\n")
+			i += end + 2
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i + 1
+			for end < len(src) && (src[end] == '_' || src[end] >= 'a' && src[end] <= 'z' ||
+				src[end] >= 'A' && src[end] <= 'Z' || src[end] >= '0' && src[end] <= '9') {
+				end++
+			}
+			tokens = append(tokens, regoToken{kind: regoIdent, text: src[i:end], line: line})
+			i = end
+		case c >= '0' && c <= '9':
+			number := regoNumberPattern.FindString(src[i:])
+			tokens = append(tokens, regoToken{kind: regoNumber, text: number, line: line})
+			i += len(number)
+		default:
+			if i+1 < len(src) {
+				switch op := src[i : i+2]; op {
+				case ":=", "==", "!=", "<=", ">=":
+					tokens = append(tokens, regoToken{kind: regoOp, text: op, line: line})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("{}[]().,;=<>+-*/%|&:", rune(c)) {
+				return nil, fmt.Errorf("line %d: unexpected character '%c'", line, c)
+			}
+			tokens = append(tokens, regoToken{kind: regoOp, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// regoModule is a parsed Rego module
+type regoModule struct {
+	pkg string
+	// names are the rule names in order of first definition
+	names []string
+	rules map[string]*regoRule
+}
+
+// regoRule is the set of definitions sharing a rule name
+type regoRule struct {
+	// partial reports whether the rule builds a set, e.g. `deny[msg]`
+	partial bool
+	// key is the variable of a partial set rule, e.g. `msg`
+	key    string
+	bodies []regoBody
+}
+
+// regoBody is a rule body split into statements
+type regoBody struct {
+	line       int
+	statements [][]regoToken
+}
+
+// helpers returns the rules referenced by the bodies of other rules
+func (m *regoModule) helpers() map[string]bool {
+	helpers := make(map[string]bool)
+	for _, rule := range m.rules {
+		for _, body := range rule.bodies {
+			for _, statement := range body.statements {
+				for i, tok := range statement {
+					if tok.kind == regoIdent && m.rules[tok.text] != nil && (i == 0 || !statement[i-1].is(".")) {
+						helpers[tok.text] = true
+					}
+				}
+			}
+		}
+	}
+	return helpers
+}
+
+// parseRego parses the package, defaults and rules of a Rego module
+func parseRego(tokens []regoToken) (*regoModule, error) {
+	m := &regoModule{rules: make(map[string]*regoRule)}
+	p := &regoParser{tokens: tokens}
+	for {
+		p.skipNewlines()
+		if p.done() {
+			break
+		}
+		tok := p.next()
+		if tok.kind != regoIdent {
+			return nil, fmt.Errorf("line %d: unexpected '%s'", tok.line, tok.text)
+		}
+		switch tok.text {
+		case "package":
+			m.pkg = tokensText(p.untilNewline())
+		case "import":
+			p.untilNewline()
+		case "default":
+			rest := p.untilNewline()
+			if len(rest) != 3 || !(rest[1].is("=") || rest[1].is(":=")) || !rest[2].is("false") {
+				return nil, fmt.Errorf("line %d: only `default <rule> = false` is supported", tok.line)
+			}
+		default:
+			if err := p.rule(m, tok); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if m.pkg == "" {
+		return nil, fmt.Errorf("missing package declaration")
+	}
+	return m, nil
+}
+
+// regoParser is a cursor over Rego tokens
+type regoParser struct {
+	tokens []regoToken
+	pos    int
+}
+
+// done reports whether every token was consumed
+func (p *regoParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// next consumes the next token
+func (p *regoParser) next() regoToken {
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok
+}
+
+// accept consumes the next token if it is text
+func (p *regoParser) accept(text string) bool {
+	if !p.done() && p.tokens[p.pos].is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipNewlines consumes newline tokens
+func (p *regoParser) skipNewlines() {
+	for !p.done() && p.tokens[p.pos].kind == regoNewline {
+		p.pos++
+	}
+}
+
+// untilNewline consumes the tokens up to the end of the line
+func (p *regoParser) untilNewline() []regoToken {
+	start := p.pos
+	for !p.done() && p.tokens[p.pos].kind != regoNewline {
+		p.pos++
+	}
+	return p.tokens[start:p.pos]
+}
+
+// rule parses the definition of the rule named by head, e.g. `allow if { ... }` or `deny[msg] { ... }`
+func (p *regoParser) rule(m *regoModule, head regoToken) error {
+	name := head.text
+	rule := m.rules[name]
+	if rule == nil {
+		rule = &regoRule{}
+		m.rules[name] = rule
+		m.names = append(m.names, name)
+	}
+
+	partial, key := false, ""
+	switch {
+	case p.accept("["):
+		if p.pos+1 >= len(p.tokens) || p.tokens[p.pos].kind != regoIdent || !p.tokens[p.pos+1].is("]") {
+			return fmt.Errorf("line %d: rule '%s' must build a set of a single variable", head.line, name)
+		}
+		partial, key = true, p.next().text
+		p.next()
+	case p.accept("contains"):
+		if p.done() || p.tokens[p.pos].kind != regoIdent {
+			return fmt.Errorf("line %d: rule '%s' must build a set of a single variable", head.line, name)
+		}
+		partial, key = true, p.next().text
+	case p.accept("("):
+		return fmt.Errorf("line %d: function '%s' is not supported", head.line, name)
+	}
+	if len(rule.bodies) > 0 && rule.partial != partial {
+		return fmt.Errorf("line %d: rule '%s' mixes boolean and partial set definitions", head.line, name)
+	}
+	rule.partial, rule.key = partial, key
+
+	if p.accept("=") || p.accept(":=") {
+		if !p.accept("true") {
+			return fmt.Errorf("line %d: rule '%s' must have a true value", head.line, name)
+		}
+	}
+	p.accept("if")
+
+	body := regoBody{line: head.line}
+	if !p.accept("{") {
+		// A single expression body, e.g. `allow if input.admin`
+		statement := p.untilNewline()
+		if len(statement) == 0 {
+			return fmt.Errorf("line %d: rule '%s' has no body", head.line, name)
+		}
+		body.statements = append(body.statements, statement)
+		rule.bodies = append(rule.bodies, body)
+		return nil
+	}
+
+	depth, statement := 0, make([]regoToken, 0)
+	for {
+		if p.done() {
+			return fmt.Errorf("line %d: unterminated body of rule '%s'", head.line, name)
+		}
+		tok := p.next()
+		switch {
+		case tok.is("{") || tok.is("[") || tok.is("("):
+			depth++
+		case tok.is("}") && depth == 0:
+			if len(statement) > 0 {
+				body.statements = append(body.statements, statement)
+			}
+			rule.bodies = append(rule.bodies, body)
+			if !p.done() && p.tokens[p.pos].is("else") {
+				return fmt.Errorf("line %d: else is not supported", p.tokens[p.pos].line)
+			}
+			return nil
+		case tok.is("}") || tok.is("]") || tok.is(")"):
+			depth--
+		}
+		if depth == 0 && (tok.kind == regoNewline || tok.is(";")) {
+			if len(statement) > 0 {
+				body.statements = append(body.statements, statement)
+				statement = make([]regoToken, 0)
+			}
+			continue
+		}
+		if tok.kind != regoNewline {
+			statement = append(statement, tok)
+		}
+	}
+}
+
+// regoBuiltins maps Rego built-in functions to their CEL equivalent given the translated arguments
+var regoBuiltins = map[string]func(args []string) string{
+	"startswith": func(args []string) string { return regoReceiver(args[0]) + ".startsWith(" + args[1] + ")" },
+	"endswith":   func(args []string) string { return regoReceiver(args[0]) + ".endsWith(" + args[1] + ")" },
+	"contains":   func(args []string) string { return regoReceiver(args[0]) + ".contains(" + args[1] + ")" },
+	"re_match":   func(args []string) string { return regoReceiver(args[1]) + ".matches(" + args[0] + ")" },
+	"count":      func(args []string) string { return "size(" + args[0] + ")" },
+}
+
+// regoBuiltinArity is the number of arguments of each supported built-in function
+var regoBuiltinArity = map[string]int{"startswith": 2, "endswith": 2, "contains": 2, "re_match": 2, "count": 1}
+
+// regoSimpleOperand matches an operand used as a method receiver without parentheses
+var regoSimpleOperand = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$|^"[^"]*"$`)
+
+// regoReceiver parenthesizes expr unless it is a selector or a string literal
+func regoReceiver(expr string) string {
+	if regoSimpleOperand.MatchString(expr) {
+		return expr
+	}
+	return "(" + expr + ")"
+}
+
+// regoImporter translates rule bodies into CEL expressions
+type regoImporter struct {
+	rules   map[string]*regoRule
+	helpers map[string]bool
+}
+
+// body translates the statements of a body into a conjunction, returning the literal assigned to key if any
+func (im regoImporter) body(body regoBody, key string) (string, string, error) {
+	exprs := make([]string, 0, len(body.statements))
+	message := ""
+	for _, statement := range body.statements {
+		if key != "" && len(statement) >= 2 && statement[0].is(key) && (statement[1].is(":=") || statement[1].is("=")) {
+			if len(statement) == 3 && statement[2].kind == regoString {
+				message = statement[2].text
+			}
+			continue
+		}
+		expr, err := im.statement(statement)
+		if err != nil {
+			return "", "", fmt.Errorf("line %d: %w", statement[0].line, err)
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) > 1 {
+		// A negation guarding undefined references is a disjunction, which binds looser than the conjunction
+		for i, expr := range exprs {
+			if strings.Contains(expr, " || ") {
+				exprs[i] = "(" + expr + ")"
+			}
+		}
+	}
+	if len(exprs) == 0 {
+		return "true", message, nil
+	}
+	return strings.Join(exprs, " && "), message, nil
+}
+
+// statement translates a single body statement
+func (im regoImporter) statement(tokens []regoToken) (string, error) {
+	if tokens[0].is("not") {
+		expr, err := im.statement(tokens[1:])
+		if err != nil {
+			return "", err
+		}
+		// Rego's not also holds when a reference is undefined, where CEL fails with no such key
+		switch guards := regoGuards(tokens[1:]); len(guards) {
+		case 0:
+			return "!(" + expr + ")", nil
+		case 1:
+			return "!" + guards[0] + " || !(" + expr + ")", nil
+		default:
+			return "!(" + strings.Join(guards, " && ") + ") || !(" + expr + ")", nil
+		}
+	}
+	if tokens[0].is("some") {
+		return "", fmt.Errorf("some is not supported")
+	}
+	if len(tokens) > 1 && tokens[1].is(":=") {
+		return "", fmt.Errorf("local variables are not supported")
+	}
+
+	// `x[_] == y` holds when y is an element of x
+	for i, tok := range tokens {
+		if !(tok.is("==") || tok.is("=")) {
+			continue
+		}
+		collection, element := tokens[:i], tokens[i+1:]
+		if iterated, ok := cutIterator(collection); ok {
+			collection = iterated
+		} else if iterated, ok := cutIterator(element); ok {
+			collection, element = iterated, collection
+		} else {
+			break
+		}
+		elementExpr, err := im.expr(element)
+		if err != nil {
+			return "", err
+		}
+		collectionExpr, err := im.expr(collection)
+		if err != nil {
+			return "", err
+		}
+		return elementExpr + " in " + collectionExpr, nil
+	}
+	return im.expr(tokens)
+}
+
+// regoGuards returns a has() test for every field selected by the input and data references of tokens, outermost
+// first, e.g. `input.user.profile.banned` is guarded by has(user.profile) and has(user.profile.banned)
+func regoGuards(tokens []regoToken) []string {
+	var guards []string
+	seen := make(map[string]bool)
+	for i, tok := range tokens {
+		if !(tok.is("input") || tok.is("data")) || (i > 0 && tokens[i-1].is(".")) {
+			continue
+		}
+		path := "globals"
+		j := i + 1
+		if tok.is("input") {
+			if j+1 >= len(tokens) || !tokens[j].is(".") || tokens[j+1].kind != regoIdent {
+				continue
+			}
+			path = tokens[j+1].text
+			j += 2
+		}
+		for ; j+1 < len(tokens) && tokens[j].is(".") && tokens[j+1].kind == regoIdent; j += 2 {
+			path += "." + tokens[j+1].text
+			if guard := "has(" + path + ")"; !seen[guard] {
+				seen[guard] = true
+				guards = append(guards, guard)
+			}
+		}
+	}
+	return guards
+}
+
+// cutIterator returns the collection iterated by tokens ending in `[_]`
+func cutIterator(tokens []regoToken) ([]regoToken, bool) {
+	n := len(tokens)
+	if n < 4 || !tokens[n-3].is("[") || !tokens[n-2].is("_") || !tokens[n-1].is("]") {
+		return nil, false
+	}
+	return tokens[:n-3], true
+}
+
+// expr translates an expression
+func (im regoImporter) expr(tokens []regoToken) (string, error) {
+	parts := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		selected := i > 0 && tokens[i-1].is(".")
+		switch {
+		case tok.kind == regoString:
+			parts = append(parts, strconv.Quote(tok.text))
+		case tok.kind == regoNumber:
+			parts = append(parts, tok.text)
+		case tok.kind == regoOp:
+			switch tok.text {
+			case "=":
+				parts = append(parts, "==")
+			case ":=":
+				return "", fmt.Errorf("local variables are not supported")
+			default:
+				parts = append(parts, tok.text)
+			}
+		case selected:
+			parts = append(parts, tok.text)
+		case tok.text == "input" || tok.text == "data":
+			if i+2 >= len(tokens) || !tokens[i+1].is(".") || tokens[i+2].kind != regoIdent {
+				return "", fmt.Errorf("'%s' must be followed by a field selection", tok.text)
+			}
+			if tok.text == "data" {
+				parts = append(parts, "globals", ".")
+			}
+			parts = append(parts, tokens[i+2].text)
+			i += 2
+		case tok.text == "true" || tok.text == "false" || tok.text == "null" || tok.text == "in":
+			parts = append(parts, tok.text)
+		case tok.text == "_":
+			return "", fmt.Errorf("iteration is only supported as `x[_] == y`")
+		case im.rules[tok.text] != nil:
+			parts = append(parts, tok.text+"()")
+		case i+1 < len(tokens) && tokens[i+1].is("("):
+			call, end, err := im.call(tokens, i)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, call)
+			i = end
+		default:
+			return "", fmt.Errorf("unsupported reference '%s'", tok.text)
+		}
+	}
+	return joinRegoParts(parts), nil
+}
+
+// call translates the built-in function call starting at tokens[start], returning the index of its closing parenthesis
+func (im regoImporter) call(tokens []regoToken, start int) (string, int, error) {
+	name := tokens[start].text
+	builtin, ok := regoBuiltins[name]
+	if !ok {
+		return "", 0, fmt.Errorf("unsupported function '%s'", name)
+	}
+	args := make([]string, 0)
+	depth, argStart := 0, start+2
+	for i := start + 1; i < len(tokens); i++ {
+		switch {
+		case tokens[i].is("(") || tokens[i].is("[") || tokens[i].is("{"):
+			depth++
+		case tokens[i].is(")") || tokens[i].is("]") || tokens[i].is("}"):
+			depth--
+		}
+		if (depth == 1 && tokens[i].is(",")) || depth == 0 {
+			if i > argStart {
+				arg, err := im.expr(tokens[argStart:i])
+				if err != nil {
+					return "", 0, err
+				}
+				args = append(args, arg)
+			}
+			argStart = i + 1
+		}
+		if depth == 0 {
+			if len(args) != regoBuiltinArity[name] {
+				return "", 0, fmt.Errorf("function '%s' expects %d arguments", name, regoBuiltinArity[name])
+			}
+			return builtin(args), i, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated call to '%s'", name)
+}
+
+// joinRegoParts joins translated tokens, spacing operators but not selections, indexes or calls
+func joinRegoParts(parts []string) string {
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			prev := parts[i-1]
+			attached := prev == "." || prev == "(" || prev == "[" || prev == "!" ||
+				part == "." || part == ")" || part == "]" || part == "," || part == "["
+			if !attached {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// joinRego joins expressions with op, parenthesizing each when there is more than one
+func joinRego(exprs []string, op string) string {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	wrapped := make([]string, len(exprs))
+	for i, expr := range exprs {
+		wrapped[i] = "(" + expr + ")"
+	}
+	return strings.Join(wrapped, op)
+}
+
+// tokensText joins the text of tokens without separators, e.g. a package path
+func tokensText(tokens []regoToken) string {
+	texts := make([]string, len(tokens))
+	for i, tok := range tokens {
+		texts[i] = tok.text
+	}
+	return strings.Join(texts, "")
+}
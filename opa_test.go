@@ -0,0 +1,258 @@
+package ruleengine
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestImportOPA(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        string
+		data          string
+		wantFunctions map[string]string
+		wantRules     map[string]string
+		wantRulesets  map[string][]string
+		wantMessages  map[string]string
+		wantErr       string
+	}{
+		{
+			name: "success - allow rules",
+			policy: `package authz
+default allow := false
+allow {
+	input.user.age >= 18; input.user.status != "banned"
+}
+allow { input.user.roles[_] = "admin" }`,
+			wantFunctions: map[string]string{},
+			wantRules: map[string]string{
+				"allow_1": `user.age >= 18 && user.status != "banned"`,
+				"allow_2": `"admin" in user.roles`,
+			},
+			wantRulesets: map[string][]string{"allow": {"allow_1", "allow_2"}},
+			wantMessages: map[string]string{},
+		},
+		{
+			name: "success - deny rules, helpers and data",
+			policy: `package authz
+deny[msg] {
+	not verified
+	msg := "not verified"
+}
+verified = true if count(input.user.documents) > 0
+verified if endswith(input.user.email, data.trusted_domain)`,
+			data: `{"trusted_domain": "example.com"}`,
+			wantFunctions: map[string]string{
+				"verified": `(size(user.documents) > 0) || (user.email.endsWith(globals.trusted_domain))`,
+			},
+			wantRules:    map[string]string{"deny": `!(!(verified()))`},
+			wantRulesets: map[string][]string{"deny": {"deny"}},
+			wantMessages: map[string]string{"deny": "not verified"},
+		},
+		{
+			name: "success - not guards undefined references",
+			policy: `package authz
+allow {
+	input.user.age >= 18
+	not input.user.profile.banned
+}
+deny[msg] {
+	not input.user.verified
+	msg := "not verified"
+}`,
+			wantFunctions: map[string]string{},
+			wantRules: map[string]string{
+				"allow": `user.age >= 18 && (!(has(user.profile) && has(user.profile.banned)) || !(user.profile.banned))`,
+				"deny":  `!(!has(user.verified) || !(user.verified))`,
+			},
+			wantRulesets: map[string][]string{"allow": {"allow"}, "deny": {"deny"}},
+			wantMessages: map[string]string{"deny": "not verified"},
+		},
+		{
+			name:    "fail - missing package",
+			policy:  `allow { input.admin }`,
+			wantErr: "missing package declaration",
+		},
+		{
+			name:    "fail - default true",
+			policy:  "package authz\ndefault allow = true",
+			wantErr: "only `default <rule> = false` is supported",
+		},
+		{
+			name:    "fail - local variable",
+			policy:  "package authz\nallow {\n\tx := input.user\n\tx.admin\n}",
+			wantErr: "local variables are not supported",
+		},
+		{
+			name:    "fail - unsupported function",
+			policy:  "package authz\nallow { is_number(input.age) }",
+			wantErr: "unsupported function 'is_number'",
+		},
+		{
+			name:    "fail - rule with arguments",
+			policy:  "package authz\nis_adult(age) { age >= 18 }",
+			wantErr: "function 'is_adult' is not supported",
+		},
+		{
+			name:    "fail - else",
+			policy:  "package authz\nallow { input.admin } else { input.owner }",
+			wantErr: "else is not supported",
+		},
+		{
+			name:    "fail - invalid data document",
+			policy:  "package authz\nallow { input.admin }",
+			data:    "[",
+			wantErr: "failed to parse data document",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ImportOPA([]byte(tt.policy), []byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ImportOPA() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportOPA() error = %v", err)
+			}
+			rules := make(map[string]string, len(got.Rules))
+			for name, rule := range got.Rules {
+				rules[name] = rule.Expression
+			}
+			rulesets := make(map[string][]string, len(got.Rulesets))
+			for name, ruleset := range got.Rulesets {
+				rulesets[name] = ruleset.Rules
+			}
+			if diff := cmp.Diff(got.Functions, tt.wantFunctions); diff != "" {
+				t.Errorf("ImportOPA() functions (-got +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(rules, tt.wantRules); diff != "" {
+				t.Errorf("ImportOPA() rules (-got +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(rulesets, tt.wantRulesets); diff != "" {
+				t.Errorf("ImportOPA() rulesets (-got +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(got.ErrorHandling.CustomErrorMessages, tt.wantMessages); diff != "" {
+				t.Errorf("ImportOPA() error messages (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestImportOPA_Evaluate(t *testing.T) {
+	policy, err := os.ReadFile("testdata/opa_policy.rego")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile("testdata/opa_data.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := ImportOPA(policy, data)
+	if err != nil {
+		t.Fatalf("ImportOPA() error = %v", err)
+	}
+	engine, err := NewBuilder().WithConfig(config).WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ruleset string
+		ctx     map[string]interface{}
+		want    bool
+	}{
+		{
+			name:    "success - admin allowed",
+			ruleset: "allow",
+			ctx: map[string]interface{}{
+				"user":    map[string]interface{}{"roles": []interface{}{"admin"}},
+				"request": map[string]interface{}{"method": "POST", "path": "/admin"},
+			},
+			want: true,
+		},
+		{
+			name:    "success - public read allowed",
+			ruleset: "allow",
+			ctx: map[string]interface{}{
+				"user":    map[string]interface{}{"roles": []interface{}{}},
+				"request": map[string]interface{}{"method": "GET", "path": "/public/docs"},
+			},
+			want: true,
+		},
+		{
+			name:    "fail - private write denied",
+			ruleset: "allow",
+			ctx: map[string]interface{}{
+				"user":    map[string]interface{}{"roles": []interface{}{"viewer"}},
+				"request": map[string]interface{}{"method": "POST", "path": "/public/docs"},
+			},
+			want: false,
+		},
+		{
+			name:    "success - no violations",
+			ruleset: "deny",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"email_verified": true, "age": 21},
+			},
+			want: true,
+		},
+		{
+			name:    "fail - underage",
+			ruleset: "deny",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"email_verified": true, "age": 15},
+			},
+			want: false,
+		},
+		{
+			name:    "success - not holds for undefined field",
+			ruleset: "unrestricted",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21},
+			},
+			want: true,
+		},
+		{
+			name:    "fail - suspended",
+			ruleset: "unrestricted",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"suspended": true},
+			},
+			want: false,
+		},
+		{
+			name:    "fail - email not verified",
+			ruleset: "deny",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21},
+			},
+			want: false,
+		},
+		{
+			name:    "success - blocked country",
+			ruleset: "blocked",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"country": "XX"},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.EvaluateRuleset(tt.ruleset, WithEvalContext(tt.ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v, error %v", got.Passed, tt.want, got.Error)
+			}
+		})
+	}
+}
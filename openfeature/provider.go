@@ -0,0 +1,173 @@
+// Package openfeature exposes a ruleengine.RuleEngine as an OpenFeature provider
+//
+//	Boolean flags map to rulesets and the OpenFeature evaluation context maps to the CEL context, so
+//	services already using OpenFeature can consume rule decisions without new APIs.
+//
+//	engine, _ := ruleengine.NewRuleEngine("rules.yml", "production", env)
+//	_ = openfeature.SetProviderAndWait(ofprovider.NewProvider(engine))
+//	client := openfeature.NewClient("registration")
+//	allowed, _ := client.BooleanValue(ctx, "user_registration", false, openfeature.NewEvaluationContext("user-1", map[string]interface{}{
+//		"user.age": 21,
+//	}))
+package openfeature
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/open-feature/go-sdk/openfeature"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+const (
+	// providerName is the name reported in the provider metadata
+	providerName = "ruleengine"
+	// variantPassed is the variant reported when the ruleset passed
+	variantPassed = "passed"
+	// variantFailed is the variant reported when the ruleset did not pass
+	variantFailed = "failed"
+)
+
+// Provider is an OpenFeature provider backed by a ruleengine.RuleEngine
+type Provider struct {
+	// engine evaluates the rulesets backing boolean flags
+	engine *ruleengine.RuleEngine
+}
+
+// NewProvider creates a new OpenFeature provider evaluating flags against engine
+func NewProvider(engine *ruleengine.RuleEngine) *Provider {
+	return &Provider{engine: engine}
+}
+
+// Metadata returns the provider metadata
+func (p *Provider) Metadata() openfeature.Metadata {
+	return openfeature.Metadata{Name: providerName}
+}
+
+// Hooks returns the provider hooks, the engine does not need any
+func (p *Provider) Hooks() []openfeature.Hook {
+	return []openfeature.Hook{}
+}
+
+// BooleanEvaluation evaluates the ruleset named flag, the flag value is whether the ruleset passed
+//
+//	Failed evaluations resolve to the default value, with a FLAG_NOT_FOUND error for unknown rulesets, an
+//	INVALID_CONTEXT error for contexts exceeding the engine's ruleengine.ContextLimits and a GENERAL error otherwise,
+//	e.g. for evaluations refused by the evaluation queue or timing out
+func (p *Provider) BooleanEvaluation(_ context.Context, flag string, defaultValue bool, evalCtx openfeature.FlattenedContext) openfeature.BoolResolutionDetail {
+	if _, ok := p.engine.GetRuleset(flag); !ok {
+		return openfeature.BoolResolutionDetail{
+			Value:                    defaultValue,
+			ProviderResolutionDetail: resolutionError(openfeature.NewFlagNotFoundResolutionError("ruleset '" + flag + "' not found")),
+		}
+	}
+	result, err := p.engine.EvaluateRuleset(flag, ruleengine.WithEvalContext(ToContext(evalCtx)))
+	if err != nil {
+		resolution := openfeature.NewGeneralResolutionError(err.Error())
+		if errors.Is(err, ruleengine.ErrContextTooLarge) {
+			resolution = openfeature.NewInvalidContextResolutionError(err.Error())
+		}
+		return openfeature.BoolResolutionDetail{
+			Value:                    defaultValue,
+			ProviderResolutionDetail: resolutionError(resolution),
+		}
+	}
+
+	variant := variantFailed
+	if result.Passed {
+		variant = variantPassed
+	}
+	return openfeature.BoolResolutionDetail{
+		Value: result.Passed,
+		ProviderResolutionDetail: openfeature.ProviderResolutionDetail{
+			Reason:  openfeature.TargetingMatchReason,
+			Variant: variant,
+		},
+	}
+}
+
+// StringEvaluation is not supported, rulesets only produce boolean decisions
+func (p *Provider) StringEvaluation(_ context.Context, flag string, defaultValue string, _ openfeature.FlattenedContext) openfeature.StringResolutionDetail {
+	return openfeature.StringResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(flag),
+	}
+}
+
+// FloatEvaluation is not supported, rulesets only produce boolean decisions
+func (p *Provider) FloatEvaluation(_ context.Context, flag string, defaultValue float64, _ openfeature.FlattenedContext) openfeature.FloatResolutionDetail {
+	return openfeature.FloatResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(flag),
+	}
+}
+
+// IntEvaluation is not supported, rulesets only produce boolean decisions
+func (p *Provider) IntEvaluation(_ context.Context, flag string, defaultValue int64, _ openfeature.FlattenedContext) openfeature.IntResolutionDetail {
+	return openfeature.IntResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(flag),
+	}
+}
+
+// ObjectEvaluation is not supported, rulesets only produce boolean decisions
+func (p *Provider) ObjectEvaluation(_ context.Context, flag string, defaultValue interface{}, _ openfeature.FlattenedContext) openfeature.InterfaceResolutionDetail {
+	return openfeature.InterfaceResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(flag),
+	}
+}
+
+// typeMismatch returns the resolution detail for non-boolean flag evaluations
+func typeMismatch(flag string) openfeature.ProviderResolutionDetail {
+	return resolutionError(openfeature.NewTypeMismatchResolutionError("ruleset '" + flag + "' only resolves boolean values"))
+}
+
+// resolutionError returns the resolution detail of a failed flag evaluation
+func resolutionError(err openfeature.ResolutionError) openfeature.ProviderResolutionDetail {
+	return openfeature.ProviderResolutionDetail{
+		ResolutionError: err,
+		Reason:          openfeature.ErrorReason,
+	}
+}
+
+// ToContext converts an OpenFeature evaluation context into a CEL evaluation context
+//
+//	Dotted keys are expanded into nested maps, e.g. `user.age` becomes `user: {age: ...}`, so flat
+//	OpenFeature attributes can be referenced by rule expressions as `user.age`. Keys are expanded in sorted order
+//	and a dotted key wins over its prefix: with `user` and `user.age` both set, age is added to a copy of the
+//	`user` map, or replaces `user` if it is not a map
+func ToContext(evalCtx openfeature.FlattenedContext) map[string]interface{} {
+	keys := make([]string, 0, len(evalCtx))
+	for key := range evalCtx {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	ctx := make(map[string]interface{}, len(evalCtx))
+	// expanded are the prefixes whose maps were created here, maps of the evaluation context are copied first
+	expanded := make(map[string]bool)
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		current := ctx
+		for i, part := range parts[:len(parts)-1] {
+			prefix := strings.Join(parts[:i+1], ".")
+			next, ok := current[part].(map[string]interface{})
+			if !expanded[prefix] {
+				if ok {
+					next = maps.Clone(next)
+				} else {
+					next = make(map[string]interface{})
+				}
+				current[part] = next
+				expanded[prefix] = true
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = evalCtx[key]
+	}
+	return ctx
+}
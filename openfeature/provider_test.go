@@ -0,0 +1,176 @@
+package openfeature
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-feature/go-sdk/openfeature"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+func setupProvider(t *testing.T, opts ...ruleengine.Option) *Provider {
+	env, err := cel.NewEnv(
+		cel.Variable("user", cel.DynType),
+		cel.Variable("request", cel.DynType),
+		cel.Variable("globals", cel.DynType),
+	)
+	if err != nil {
+		t.Fatalf("failed to create CEL environment: %v", err)
+	}
+	engine, err := ruleengine.NewRuleEngine("../testdata/rules.yml", "production", env, opts...)
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	return NewProvider(engine)
+}
+
+func TestProvider_BooleanEvaluation(t *testing.T) {
+	tests := []struct {
+		name         string
+		flag         string
+		defaultValue bool
+		opts         []ruleengine.Option
+		evalCtx      openfeature.FlattenedContext
+		want         bool
+		wantReason   openfeature.Reason
+		wantCode     openfeature.ErrorCode
+	}{
+		{
+			name: "success - ruleset passed",
+			flag: "user_registration",
+			evalCtx: openfeature.FlattenedContext{
+				openfeature.TargetingKey: "user-1",
+				"user.age":               21,
+				"user.email":             "test@example.com",
+				"user.status":            "active",
+				"user.suspended":         false,
+			},
+			want:       true,
+			wantReason: openfeature.TargetingMatchReason,
+		},
+		{
+			name:         "success - ruleset failed",
+			flag:         "user_registration",
+			defaultValue: true,
+			evalCtx: openfeature.FlattenedContext{
+				"user.age":       15,
+				"user.email":     "test@example.com",
+				"user.status":    "active",
+				"user.suspended": false,
+			},
+			want:       false,
+			wantReason: openfeature.TargetingMatchReason,
+		},
+		{
+			name:         "fail - unknown flag",
+			flag:         "unknown",
+			defaultValue: true,
+			evalCtx:      openfeature.FlattenedContext{},
+			want:         true,
+			wantReason:   openfeature.ErrorReason,
+			wantCode:     openfeature.FlagNotFoundCode,
+		},
+		{
+			name:         "fail - context exceeds limits",
+			flag:         "user_registration",
+			defaultValue: true,
+			opts:         []ruleengine.Option{ruleengine.WithContextLimits(ruleengine.ContextLimits{MaxDepth: 1})},
+			evalCtx:      openfeature.FlattenedContext{"user.age": 21},
+			want:         true,
+			wantReason:   openfeature.ErrorReason,
+			wantCode:     openfeature.InvalidContextCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := setupProvider(t, tt.opts...)
+			got := p.BooleanEvaluation(context.Background(), tt.flag, tt.defaultValue, tt.evalCtx)
+			if got.Value != tt.want {
+				t.Errorf("BooleanEvaluation() value = %v, want %v", got.Value, tt.want)
+			}
+			if got.Reason != tt.wantReason {
+				t.Errorf("BooleanEvaluation() reason = %v, want %v", got.Reason, tt.wantReason)
+			}
+			if code := got.ResolutionDetail().ErrorCode; code != tt.wantCode {
+				t.Errorf("BooleanEvaluation() error code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestProvider_BooleanEvaluationQueueFull(t *testing.T) {
+	var p *Provider
+	var got openfeature.BoolResolutionDetail
+	// The first rule evaluation holds the only running slot while the flag is evaluated again
+	nested := false
+	p = setupProvider(t,
+		ruleengine.WithEvaluationQueue(ruleengine.QueueConfig{Concurrency: 1}),
+		ruleengine.WithInterceptor(func(_ map[string]interface{}, _ string, next func() (ruleengine.RuleResult, error)) (ruleengine.RuleResult, error) {
+			if !nested {
+				nested = true
+				got = p.BooleanEvaluation(context.Background(), "user_registration", true, openfeature.FlattenedContext{})
+			}
+			return next()
+		}),
+	)
+	p.BooleanEvaluation(context.Background(), "user_registration", false, openfeature.FlattenedContext{"user.age": 21})
+	if !got.Value || got.Reason != openfeature.ErrorReason {
+		t.Errorf("BooleanEvaluation() = %+v, want the default value with an error", got)
+	}
+	if code := got.ResolutionDetail().ErrorCode; code != openfeature.GeneralCode {
+		t.Errorf("BooleanEvaluation() error code = %v, want %v", code, openfeature.GeneralCode)
+	}
+}
+
+func TestToContext(t *testing.T) {
+	user := map[string]interface{}{"age": 18, "verified": true}
+	tests := []struct {
+		name    string
+		evalCtx openfeature.FlattenedContext
+		want    map[string]interface{}
+	}{
+		{
+			name: "success - dotted keys expanded",
+			evalCtx: openfeature.FlattenedContext{
+				"targetingKey":         "user-1",
+				"user.age":             21,
+				"user.address.country": "AU",
+			},
+			want: map[string]interface{}{
+				"targetingKey": "user-1",
+				"user": map[string]interface{}{
+					"age": 21,
+					"address": map[string]interface{}{
+						"country": "AU",
+					},
+				},
+			},
+		},
+		{
+			name:    "success - dotted key replaces a scalar prefix",
+			evalCtx: openfeature.FlattenedContext{"user": "user-1", "user.age": 21},
+			want:    map[string]interface{}{"user": map[string]interface{}{"age": 21}},
+		},
+		{
+			name:    "success - dotted key added to a copy of a map prefix",
+			evalCtx: openfeature.FlattenedContext{"user": user, "user.age": 21},
+			want:    map[string]interface{}{"user": map[string]interface{}{"age": 21, "verified": true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order varies, conflicting keys must expand the same way every time
+			for i := 0; i < 20; i++ {
+				if diff := cmp.Diff(ToContext(tt.evalCtx), tt.want); diff != "" {
+					t.Fatalf("ToContext() (-got +want):\n%s", diff)
+				}
+			}
+		})
+	}
+	if diff := cmp.Diff(user, map[string]interface{}{"age": 18, "verified": true}); diff != "" {
+		t.Errorf("ToContext() modified the evaluation context (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,69 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_Optional(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/optional_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tests := []struct {
+		name         string
+		user         map[string]interface{}
+		wantPassed   bool
+		wantWarnings []string
+		wantReasons  []string
+		wantOrder    []string
+	}{
+		{
+			name:       "success - every rule passes",
+			user:       map[string]interface{}{"age": 21, "email_verified": true, "phone_verified": true},
+			wantPassed: true,
+			wantOrder:  []string{"age_validation", "phone_verified", "email_verified"},
+		},
+		{
+			name:         "success - optional rule fails",
+			user:         map[string]interface{}{"age": 21, "email_verified": true, "phone_verified": false},
+			wantPassed:   true,
+			wantWarnings: []string{"phone_verified"},
+			wantOrder:    []string{"age_validation", "phone_verified", "email_verified"},
+		},
+		{
+			name:         "fail - mandatory rule fails",
+			user:         map[string]interface{}{"age": 21, "email_verified": false, "phone_verified": false},
+			wantWarnings: []string{"phone_verified"},
+			wantReasons:  []string{"EMAIL"},
+			wantOrder:    []string{"age_validation", "phone_verified", "email_verified"},
+		},
+		{
+			name:        "fail - stops at the first mandatory failure",
+			user:        map[string]interface{}{"age": 16, "email_verified": true, "phone_verified": true},
+			wantReasons: []string{"AGE"},
+			wantOrder:   []string{"age_validation"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.EvaluateRuleset("registration", WithEvalContext(map[string]interface{}{"user": tt.user}))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", got.Passed, tt.wantPassed)
+			}
+			if diff := cmp.Diff(tt.wantWarnings, got.Warnings); diff != "" {
+				t.Errorf("EvaluateRuleset() warnings mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReasons, got.ReasonCodes); diff != "" {
+				t.Errorf("EvaluateRuleset() reason codes mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantOrder, got.Order); diff != "" {
+				t.Errorf("EvaluateRuleset() order mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
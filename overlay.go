@@ -0,0 +1,108 @@
+package ruleengine
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewRulesetConfigWithOverlays reads the base YAML configuration file and patches it with each overlay file in order
+//
+//	Overlays are partial configs merged onto the base like a strategic merge patch: mappings such as rules, rulesets,
+//	globals and environments are merged key by key, so an overlay only lists the fields it changes, while scalars and
+//	lists replace the base value. A `null` value removes the key, e.g. `rules: {business_hours: null}`
+func NewRulesetConfigWithOverlays(configPath string, overlayPaths ...string) (*RulesetConfig, error) {
+	config, _, err := loadOverlaidRulesetConfig(configPath, overlayPaths, nil)
+	return config, err
+}
+
+// loadOverlaidRulesetConfig reads the base config and its overlays, decrypting each with key first when set
+//
+//	The source position of each rule expression points into the file it was last set by
+func loadOverlaidRulesetConfig(configPath string, overlayPaths []string, key []byte) (*RulesetConfig, map[string]SourcePosition, error) {
+	base, err := readConfigDocument(configPath, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	positions := expressionPositions(configPath, base)
+	for _, path := range overlayPaths {
+		overlay, err := readConfigDocument(path, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("overlay '%s': %w", path, err)
+		}
+		if overlay.Kind == 0 {
+			// An empty overlay changes nothing
+			continue
+		}
+		if overlay.Content[0].Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("overlay '%s' must be a YAML mapping", path)
+		}
+		if base.Kind == 0 {
+			base = overlay
+		} else {
+			base.Content[0] = mergeNodes(base.Content[0], overlay.Content[0])
+		}
+		for name, position := range expressionPositions(path, overlay) {
+			positions[name] = position
+		}
+	}
+
+	var config RulesetConfig
+	if err := base.Decode(&config); err != nil {
+		return nil, nil, err
+	}
+	config.resolveTypes(configPath)
+	for name := range positions {
+		if _, ok := config.Rules[name]; !ok {
+			delete(positions, name)
+		}
+	}
+	return &config, positions, nil
+}
+
+// readConfigDocument reads and parses a YAML file, decrypting it with key first when set
+func readConfigDocument(path string, key []byte) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		if data, err = decryptConfig(data, key); err != nil {
+			return nil, err
+		}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// mergeNodes merges overlay onto base, mappings are merged key by key and any other value replaces the base value
+func mergeNodes(base, overlay *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		index := -1
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				index = j
+				break
+			}
+		}
+		switch {
+		case value.Tag == "!!null" && index >= 0:
+			base.Content = append(base.Content[:index], base.Content[index+2:]...)
+		case value.Tag == "!!null":
+			// Removing a key the base does not have is a no-op
+		case index >= 0:
+			base.Content[index+1] = mergeNodes(base.Content[index+1], value)
+		default:
+			base.Content = append(base.Content, key, value)
+		}
+	}
+	return base
+}
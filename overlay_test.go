@@ -0,0 +1,145 @@
+package ruleengine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewRulesetConfigWithOverlays(t *testing.T) {
+	dir := t.TempDir()
+	notMapping := filepath.Join(dir, "list.yml")
+	if err := os.WriteFile(notMapping, []byte("- age_validation\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.yml")
+	if err := os.WriteFile(empty, []byte("# nothing to patch\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		overlays     []string
+		wantMinAge   interface{}
+		wantCountry  interface{}
+		wantAge      Rule
+		wantRate     bool
+		wantThrottle []string
+		wantMessage  string
+		wantErr      bool
+	}{
+		{
+			name:       "success - no overlays",
+			wantMinAge: 13,
+			wantAge: Rule{
+				Name:        "Age Validation",
+				Description: "Validates user age requirements",
+				Expression:  "user.age >= globals.min_age",
+				Owner:       "identity-team",
+			},
+			wantRate:     true,
+			wantThrottle: []string{"rate_limiting", "user_tier"},
+			wantMessage:  "user must be at least 18 years old",
+		},
+		{
+			name:        "success - region overlay",
+			overlays:    []string{"./testdata/overlay_region.yml"},
+			wantMinAge:  21,
+			wantCountry: "AU",
+			wantAge: Rule{
+				Name:        "Age Validation",
+				Description: "Validates user age requirements for the region",
+				Expression:  "user.age >= globals.min_age && user.country == globals.country",
+				Owner:       "identity-team",
+			},
+			wantThrottle: []string{"user_tier"},
+			wantMessage:  "user must be at least 21 years old and in AU",
+		},
+		{
+			name:        "success - later overlays win",
+			overlays:    []string{"./testdata/overlay_region.yml", empty, "./testdata/overlay_dev.yml"},
+			wantMinAge:  16,
+			wantCountry: "AU",
+			wantAge: Rule{
+				Name:        "Age Validation",
+				Description: "Validates user age requirements for the region",
+				Expression:  "user.age >= globals.min_age && user.country == globals.country",
+				Owner:       "identity-team",
+			},
+			wantThrottle: []string{"user_tier"},
+			wantMessage:  "user must be at least 21 years old and in AU",
+		},
+		{
+			name:     "fail - missing overlay",
+			overlays: []string{filepath.Join(dir, "missing.yml")},
+			wantErr:  true,
+		},
+		{
+			name:     "fail - overlay is not a mapping",
+			overlays: []string{notMapping},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRulesetConfigWithOverlays("./testdata/rules.yml", tt.overlays...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRulesetConfigWithOverlays() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Globals["min_age"] != tt.wantMinAge || got.Globals["country"] != tt.wantCountry {
+				t.Errorf("NewRulesetConfigWithOverlays() globals = %v", got.Globals)
+			}
+			if diff := cmp.Diff(tt.wantAge, got.Rules["age_validation"]); diff != "" {
+				t.Errorf("NewRulesetConfigWithOverlays() age_validation mismatch (-want +got):\n%s", diff)
+			}
+			if _, ok := got.Rules["rate_limiting"]; ok != tt.wantRate {
+				t.Errorf("NewRulesetConfigWithOverlays() rate_limiting present = %v, want %v", ok, tt.wantRate)
+			}
+			if diff := cmp.Diff(tt.wantThrottle, got.Rulesets["request_throttling"].Rules); diff != "" {
+				t.Errorf("NewRulesetConfigWithOverlays() request_throttling mismatch (-want +got):\n%s", diff)
+			}
+			if got.Rulesets["request_throttling"].Selector != selectorOr {
+				t.Errorf("NewRulesetConfigWithOverlays() lost the request_throttling selector")
+			}
+			if msg := got.ErrorHandling.CustomErrorMessages["age_validation"]; msg != tt.wantMessage {
+				t.Errorf("NewRulesetConfigWithOverlays() age_validation message = %q, want %q", msg, tt.wantMessage)
+			}
+			if msg := got.ErrorHandling.CustomErrorMessages["email_format"]; msg != "please provide a valid email address" {
+				t.Errorf("NewRulesetConfigWithOverlays() email_format message = %q, want it kept", msg)
+			}
+		})
+	}
+}
+
+func TestBuilder_WithOverlayFiles(t *testing.T) {
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/rules.yml").
+		WithOverlayFiles("./testdata/overlay_region.yml").
+		WithVariables("user", "request").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	got, err := re.EvaluateRule("age_validation", WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"age": 20, "country": "AU"},
+	}))
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	if got.Passed {
+		t.Errorf("EvaluateRule() passed = true, want the overlay's min_age of 21 to fail")
+	}
+	if position := re.sources["age_validation"]; position.File != "./testdata/overlay_region.yml" {
+		t.Errorf("source of age_validation = %v, want the overlay file", position)
+	}
+
+	_, err = NewBuilder().WithConfig(&RulesetConfig{}).WithOverlayFiles("./testdata/overlay_region.yml").Build()
+	if err == nil {
+		t.Errorf("Build() error = nil, want overlays rejected without WithConfigFile")
+	}
+}
@@ -0,0 +1,89 @@
+package ruleengine
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// PanicError is the evaluation error of a rule whose evaluation panicked, see WithPanicRecovery
+type PanicError struct {
+	// Function is the CEL function which panicked, empty if the panic happened outside a function call
+	Function string
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine
+	Stack []byte
+}
+
+// Error implements error
+func (e *PanicError) Error() string {
+	if e.Function != "" {
+		return fmt.Sprintf("panic in function '%s': %v", e.Function, e.Value)
+	}
+	return fmt.Sprintf("panic during evaluation: %v", e.Value)
+}
+
+// WithPanicRecovery recovers panics raised while evaluating rules, including in custom functions, and reports them
+// as a failed RuleResult whose error is a *PanicError with the stack trace attached
+//
+//	CEL already converts panics into opaque `internal error` evaluation errors, this option keeps the function name
+//	and stack trace at the cost of a deferred recover per function call
+func WithPanicRecovery() Option {
+	return func(re *RuleEngine) {
+		re.recoverPanics = true
+	}
+}
+
+// programOptions returns the program options enabled by engine options
+func (re *RuleEngine) programOptions() []cel.ProgramOption {
+	var opts []cel.ProgramOption
+	if re.recoverPanics {
+		opts = append(opts, cel.CustomDecorator(recoverDecorator))
+	}
+	// Comprehensions check for an interruption on every iteration, see sandboxedProgram
+	if re.sandbox != nil && re.sandbox.MaxComprehensionIterations > 0 {
+		opts = append(opts, cel.InterruptCheckFrequency(1))
+	}
+	return opts
+}
+
+// recoverDecorator wraps every function call of a program so panics are returned as CEL errors
+func recoverDecorator(i interpreter.Interpretable) (interpreter.Interpretable, error) {
+	if call, ok := i.(interpreter.InterpretableCall); ok {
+		return &recoverCall{InterpretableCall: call}, nil
+	}
+	return i, nil
+}
+
+// recoverCall is a function call which recovers panics into a CEL error wrapping a *PanicError
+type recoverCall struct {
+	interpreter.InterpretableCall
+}
+
+// Eval implements interpreter.Interpretable
+func (c *recoverCall) Eval(activation interpreter.Activation) (val ref.Val) {
+	defer func() {
+		if r := recover(); r != nil {
+			val = types.WrapErr(&PanicError{Function: c.Function(), Value: r, Stack: debug.Stack()})
+		}
+	}()
+	return c.InterpretableCall.Eval(activation)
+}
+
+// evalProgram evaluates program against input, a context map or activation, recovering panics into a *PanicError when
+// enabled
+func (re *RuleEngine) evalProgram(program cel.Program, input interface{}) (out ref.Val, details *cel.EvalDetails, err error) {
+	if re.recoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				out, details, err = nil, nil, &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return program.Eval(input)
+}
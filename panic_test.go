@@ -0,0 +1,110 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// explodeFunction declares explode(int) which always panics
+func explodeFunction() cel.EnvOption {
+	return cel.Function("explode",
+		cel.Overload("explode_int", []*cel.Type{cel.IntType}, cel.BoolType,
+			cel.UnaryBinding(func(ref.Val) ref.Val {
+				panic("boom")
+			}),
+		),
+	)
+}
+
+func TestRuleEngine_EvaluateRule_PanicRecovery(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantPanic bool
+		wantErr   string
+	}{
+		{
+			name:      "success - recovered with stack trace",
+			opts:      []Option{WithPanicRecovery()},
+			wantPanic: true,
+			wantErr:   "panic in function 'explode': boom",
+		},
+		{
+			name:    "success - recovered by CEL without stack trace",
+			wantErr: "internal error: boom",
+		},
+		{
+			name:      "success - optimised program",
+			opts:      []Option{WithPanicRecovery(), WithOptimise()},
+			wantPanic: true,
+			wantErr:   "panic in function 'explode': boom",
+		},
+		{
+			name:      "success - profiled program",
+			opts:      []Option{WithPanicRecovery(), WithProfiler(1)},
+			wantPanic: true,
+			wantErr:   "panic in function 'explode': boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/panic_rules.yml").
+				WithVariables("user").
+				WithFunctions(explodeFunction()).
+				WithOptions(tt.opts...).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			ctx := map[string]interface{}{"user": map[string]interface{}{"age": 21}}
+
+			got, err := re.EvaluateRule("explosive", WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed || got.Error == nil || got.Error.Error() != tt.wantErr {
+				t.Fatalf("EvaluateRule() = %+v, want failed with error %q", got, tt.wantErr)
+			}
+			var panicErr *PanicError
+			if errors.As(got.Error, &panicErr) != tt.wantPanic {
+				t.Fatalf("EvaluateRule() error = %v, want *PanicError %v", got.Error, tt.wantPanic)
+			}
+			if tt.wantPanic && !strings.Contains(string(panicErr.Stack), "panic_test.go") {
+				t.Errorf("PanicError.Stack does not include the panicking function:\n%s", panicErr.Stack)
+			}
+
+			// The other rules of the ruleset are still evaluated
+			ruleset, err := re.EvaluateRuleset("registration", WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if !ruleset.RuleResults["age_validation"].Passed {
+				t.Errorf("EvaluateRuleset() age_validation = %+v, want passed", ruleset.RuleResults["age_validation"])
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvalProgram_Panic(t *testing.T) {
+	re := &RuleEngine{recoverPanics: true}
+	_, _, err := re.evalProgram(panicProgram{}, nil)
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Error() != "panic during evaluation: program exploded" {
+		t.Errorf("evalProgram() error = %v, want *PanicError", err)
+	}
+}
+
+// panicProgram is a cel.Program which panics outside any function call
+type panicProgram struct {
+	cel.Program
+}
+
+// Eval implements cel.Program
+func (panicProgram) Eval(interface{}) (ref.Val, *cel.EvalDetails, error) {
+	panic("program exploded")
+}
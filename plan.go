@@ -0,0 +1,113 @@
+package ruleengine
+
+import (
+	"sort"
+)
+
+// ExecutionPlan is the DAG of rules EvaluateAllRulesets evaluates across all rulesets
+//
+//	A rule shared by several rulesets, directly or as the parent of a referenced rule, is scheduled once per
+//	EvaluateAllRulesets call and its result is reused by every ruleset and extending rule depending on it
+type ExecutionPlan struct {
+	// Rules lists every rule used by a ruleset once, extended rules before the rules extending them
+	Rules []string
+	// Dependents is a map of rule names to the used rules extending them directly
+	Dependents map[string][]string
+	// Rulesets is a map of rule names to the rulesets depending on them, directly or through an extending rule
+	Rulesets map[string][]string
+}
+
+// Shared returns the rules more than one ruleset or extending rule depends on, in plan order
+func (p ExecutionPlan) Shared() []string {
+	shared := make([]string, 0)
+	for _, name := range p.Rules {
+		if len(p.Rulesets[name])+len(p.Dependents[name]) > 1 {
+			shared = append(shared, name)
+		}
+	}
+	return shared
+}
+
+// Plan returns the execution plan used by EvaluateAllRulesets
+func (re *RuleEngine) Plan() ExecutionPlan {
+	if active := re.Active(); active != re {
+		return active.Plan()
+	}
+	plan := ExecutionPlan{
+		Rules:      make([]string, 0, len(re.config.Rules)),
+		Dependents: make(map[string][]string),
+		Rulesets:   make(map[string][]string),
+	}
+
+	used := make(map[string]bool, len(re.config.Rules))
+	for _, rulesetName := range re.rulesetNames() {
+		// A rule may be listed twice or share a parent with another rule of the same ruleset
+		seen := make(map[string]bool)
+		for _, ruleName := range re.config.Rulesets[rulesetName].Rules {
+			for _, name := range append([]string{ruleName}, re.parents[ruleName]...) {
+				used[name] = true
+				if !seen[name] {
+					seen[name] = true
+					plan.Rulesets[name] = append(plan.Rulesets[name], rulesetName)
+				}
+			}
+		}
+	}
+	for name := range used {
+		plan.Rules = append(plan.Rules, name)
+		if parents := re.parents[name]; len(parents) > 0 {
+			plan.Dependents[parents[0]] = append(plan.Dependents[parents[0]], name)
+		}
+	}
+
+	// A rule is always deeper in the inheritance chain than the rules it extends
+	sort.Slice(plan.Rules, func(i, j int) bool {
+		di, dj := len(re.parents[plan.Rules[i]]), len(re.parents[plan.Rules[j]])
+		if di != dj {
+			return di < dj
+		}
+		return plan.Rules[i] < plan.Rules[j]
+	})
+	for _, dependents := range plan.Dependents {
+		sort.Strings(dependents)
+	}
+	return plan
+}
+
+// ruleMemo holds the rule outcomes shared between the rulesets of a single EvaluateAllRulesets call
+type ruleMemo struct {
+	// results is a map of rule names to their complete results, including extended rules
+	results map[string]RuleResult
+	// programs is a map of rule names to the outcome of their own expression
+	programs map[string]programOutcome
+}
+
+// programOutcome is the outcome of evaluating the program of a single rule
+type programOutcome struct {
+	passed bool
+	err    error
+}
+
+// newRuleMemo creates an empty ruleMemo
+func newRuleMemo() *ruleMemo {
+	return &ruleMemo{
+		results:  make(map[string]RuleResult),
+		programs: make(map[string]programOutcome),
+	}
+}
+
+// program returns the memoized outcome of the program of a rule, a nil memo never holds outcomes
+func (m *ruleMemo) program(ruleName string) (programOutcome, bool) {
+	if m == nil {
+		return programOutcome{}, false
+	}
+	outcome, ok := m.programs[ruleName]
+	return outcome, ok
+}
+
+// setProgram memoizes the outcome of the program of a rule, a no-op on a nil memo
+func (m *ruleMemo) setProgram(ruleName string, outcome programOutcome) {
+	if m != nil {
+		m.programs[ruleName] = outcome
+	}
+}
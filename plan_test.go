@@ -0,0 +1,124 @@
+package ruleengine
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/go-cmp/cmp"
+)
+
+// visitCounter declares visit(string) which counts the evaluations of each rule and returns true
+type visitCounter struct {
+	mu     sync.Mutex
+	visits map[string]int
+}
+
+// function returns the visit declaration
+func (vc *visitCounter) function() cel.EnvOption {
+	return cel.Function("visit",
+		cel.Overload("visit_string", []*cel.Type{cel.StringType}, cel.BoolType,
+			cel.UnaryBinding(func(name ref.Val) ref.Val {
+				vc.mu.Lock()
+				defer vc.mu.Unlock()
+				vc.visits[name.Value().(string)]++
+				return types.True
+			}),
+		),
+	)
+}
+
+// newPlanEngine creates an engine for testdata/plan_rules.yml counting rule visits
+func newPlanEngine(t *testing.T) (*RuleEngine, *visitCounter) {
+	t.Helper()
+	vc := &visitCounter{visits: make(map[string]int)}
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/plan_rules.yml").
+		WithVariables("user").
+		WithFunctions(vc.function()).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	return re, vc
+}
+
+func TestRuleEngine_Plan(t *testing.T) {
+	re, _ := newPlanEngine(t)
+
+	got := re.Plan()
+	want := ExecutionPlan{
+		Rules: []string{"age_validation", "email_format", "email_whitelist"},
+		Dependents: map[string][]string{
+			"email_format": {"email_whitelist"},
+		},
+		Rulesets: map[string][]string{
+			"age_validation":  {"employee", "registration"},
+			"email_format":    {"contact", "employee", "registration"},
+			"email_whitelist": {"contact", "employee"},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Plan() (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(got.Shared(), []string{"age_validation", "email_format", "email_whitelist"}); diff != "" {
+		t.Errorf("Shared() (-got +want):\n%s", diff)
+	}
+}
+
+func TestRuleEngine_EvaluateAllRulesets_Plan(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        map[string]interface{}
+		wantPassed map[string]bool
+		wantVisits map[string]int
+	}{
+		{
+			name: "success - shared rules evaluated once",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"age": 21, "email": "jane@company.com"},
+			},
+			wantPassed: map[string]bool{"contact": true, "employee": true, "registration": true},
+			wantVisits: map[string]int{"age_validation": 1, "email_format": 1, "email_whitelist": 1},
+		},
+		{
+			name: "success - failed parent reused by extending rule",
+			ctx: map[string]interface{}{
+				"user": map[string]interface{}{"age": 16, "email": "jane"},
+			},
+			wantPassed: map[string]bool{"contact": false, "employee": false, "registration": false},
+			wantVisits: map[string]int{"age_validation": 1, "email_format": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, vc := newPlanEngine(t)
+
+			results, err := re.EvaluateAllRulesets(WithEvalContext(tt.ctx))
+			if err != nil {
+				t.Fatalf("EvaluateAllRulesets() error = %v", err)
+			}
+			if diff := cmp.Diff(vc.visits, tt.wantVisits); diff != "" {
+				t.Errorf("EvaluateAllRulesets() visits (-got +want):\n%s", diff)
+			}
+
+			gotPassed := make(map[string]bool, len(results))
+			for name, result := range results {
+				gotPassed[name] = result.Passed
+				// Memoized results must match evaluating the ruleset on its own
+				alone, err := re.EvaluateRuleset(name, WithEvalContext(tt.ctx))
+				if err != nil {
+					t.Fatalf("EvaluateRuleset() error = %v", err)
+				}
+				if alone.Passed != result.Passed || len(alone.RuleResults) != len(result.RuleResults) {
+					t.Errorf("EvaluateAllRulesets() ruleset %s = %+v, want %+v", name, result, alone)
+				}
+			}
+			if diff := cmp.Diff(gotPassed, tt.wantPassed); diff != "" {
+				t.Errorf("EvaluateAllRulesets() passed (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
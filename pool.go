@@ -0,0 +1,125 @@
+package ruleengine
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledRules is the largest RuleResults map kept for reuse, larger maps are left to the garbage collector
+const maxPooledRules = 256
+
+// resultPool holds released results for AcquireResult
+var resultPool = sync.Pool{
+	New: func() interface{} {
+		return &PooledResult{}
+	},
+}
+
+// noCopy makes go vet's copylocks check report copies of the struct embedding it
+type noCopy struct{}
+
+// Lock is a no-op used by go vet
+func (*noCopy) Lock() {}
+
+// Unlock is a no-op used by go vet
+func (*noCopy) Unlock() {}
+
+// PooledResult is a RulesetResult borrowed from the results pool, see AcquireResult
+//
+//	Its RuleResults map and Order slice are reused between evaluations, so high-throughput services evaluating with
+//	EvaluateRulesetInto allocate them once. A PooledResult must not be copied, go vet reports copies, and neither it
+//	nor anything read from its Result may be used after ReleaseResult
+type PooledResult struct {
+	_        noCopy
+	result   RulesetResult
+	released atomic.Bool
+}
+
+// AcquireResult returns an empty result from the pool, release it with ReleaseResult once done with it
+func AcquireResult() *PooledResult {
+	p := resultPool.Get().(*PooledResult)
+	p.released.Store(false)
+	return p
+}
+
+// ReleaseResult returns the result to the pool, a nil result is a no-op
+//
+//	The result is cleared, releasing it twice panics
+func ReleaseResult(p *PooledResult) {
+	if p == nil {
+		return
+	}
+	if !p.released.CompareAndSwap(false, true) {
+		panic("ruleengine: result released twice")
+	}
+	ruleResults, order := p.result.RuleResults, p.result.Order
+	if len(ruleResults) > maxPooledRules {
+		ruleResults, order = nil, nil
+	}
+	clear(ruleResults)
+	p.result = RulesetResult{RuleResults: ruleResults, Order: order[:0]}
+	resultPool.Put(p)
+}
+
+// Result returns the evaluated result, valid until ReleaseResult
+//
+//	Calling it after release panics
+func (p *PooledResult) Result() *RulesetResult {
+	if p.released.Load() {
+		panic("ruleengine: use of released result")
+	}
+	return &p.result
+}
+
+// EvaluateRulesetInto evaluates a ruleset as EvaluateRuleset, storing the result in dst and reusing its RuleResults
+// map and Order slice
+//
+//	dst is overwritten and must have been acquired with AcquireResult and not released. Errors are returned as by
+//	EvaluateRuleset, dst then holds the results so far
+func (re *RuleEngine) EvaluateRulesetInto(rulesetName string, dst *PooledResult, opts ...EvalOption) error {
+	if active := re.Active(); active != re {
+		// Candidates are staged on this engine, never on the promoted one
+		if err := active.EvaluateRulesetInto(rulesetName, dst, opts...); err != nil {
+			return err
+		}
+		re.shadow(rulesetName, active, opts, *dst.Result())
+		return nil
+	}
+	into := dst.Result()
+	release, shed, err := re.enqueue(true)
+	if shed || err != nil {
+		if shed {
+			*into, err = re.shedResult(rulesetName)
+		}
+		return err
+	}
+	defer release()
+	eval, err := re.newEvaluation(opts)
+	if err != nil {
+		return err
+	}
+	eval.into = into
+	result, err := re.evaluateRuleset(rulesetName, eval)
+	*into = result
+	if err != nil {
+		return err
+	}
+	re.shadow(rulesetName, re, opts, result)
+	if re.mirror != nil {
+		// The mirror compares in the background, possibly after dst is released
+		re.mirrorEvaluation(rulesetName, opts, copyResult(result))
+	}
+	return nil
+}
+
+// copyResult returns a copy of result not sharing its RuleResults map or slices
+func copyResult(result RulesetResult) RulesetResult {
+	result.RuleResults = maps.Clone(result.RuleResults)
+	result.Order = slices.Clone(result.Order)
+	result.Warnings = slices.Clone(result.Warnings)
+	result.ReasonCodes = slices.Clone(result.ReasonCodes)
+	result.Metadata = maps.Clone(result.Metadata)
+	return result
+}
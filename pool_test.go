@@ -0,0 +1,107 @@
+package ruleengine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRuleEngine_EvaluateRulesetInto(t *testing.T) {
+	re, err := NewBuilder().WithConfig(hotPathConfig()).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		rulesetName string
+		user        map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "success - passed",
+			rulesetName: "signup",
+			user:        map[string]interface{}{"age": 30, "verified": true, "phone": "123", "tier": "premium"},
+		},
+		{
+			name:        "success - failed",
+			rulesetName: "signup",
+			user:        map[string]interface{}{"age": 12, "verified": false, "phone": ""},
+		},
+		{
+			name:        "fail - unknown ruleset",
+			rulesetName: "unknown",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithEvalContext(map[string]interface{}{"user": tt.user})
+			dst := AcquireResult()
+			defer ReleaseResult(dst)
+			for i := 0; i < 2; i++ {
+				var ruleResults map[string]RuleResult
+				if i > 0 {
+					ruleResults = dst.Result().RuleResults
+				}
+				err := re.EvaluateRulesetInto(tt.rulesetName, dst, ctx)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("EvaluateRulesetInto() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				want, _ := re.EvaluateRuleset(tt.rulesetName, ctx)
+				if diff := cmp.Diff(want, *dst.Result(), cmpopts.IgnoreFields(RulesetResult{}, "Duration"),
+					cmpopts.IgnoreFields(RuleResult{}, "Duration"),
+					cmp.Comparer(func(x, y error) bool {
+						return (x == nil && y == nil) || (x != nil && y != nil && x.Error() == y.Error())
+					})); diff != "" {
+					t.Errorf("EvaluateRulesetInto() (-want +got):\n%s", diff)
+				}
+				if ruleResults != nil && reflect.ValueOf(ruleResults).Pointer() != reflect.ValueOf(dst.Result().RuleResults).Pointer() {
+					t.Error("EvaluateRulesetInto() did not reuse the RuleResults map")
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		use       func(p *PooledResult)
+		wantPanic string
+	}{
+		{
+			name: "success - release once",
+			use:  func(p *PooledResult) {},
+		},
+		{
+			name:      "fail - use after release",
+			use:       func(p *PooledResult) { ReleaseResult(p); _ = p.Result() },
+			wantPanic: "ruleengine: use of released result",
+		},
+		{
+			name:      "fail - released twice",
+			use:       func(p *PooledResult) { ReleaseResult(p); ReleaseResult(p) },
+			wantPanic: "ruleengine: result released twice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != tt.wantPanic && !(r == nil && tt.wantPanic == "") {
+					t.Errorf("panic = %v, want %q", r, tt.wantPanic)
+				}
+			}()
+			p := AcquireResult()
+			p.Result().RuleResults = map[string]RuleResult{"adult": {RuleName: "adult"}}
+			tt.use(p)
+			if tt.wantPanic == "" {
+				ReleaseResult(p)
+				if p.result.RuleResults == nil || len(p.result.RuleResults) != 0 {
+					t.Errorf("ReleaseResult() left %v", p.result.RuleResults)
+				}
+			}
+		})
+	}
+}
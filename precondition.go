@@ -0,0 +1,80 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// compilePreconditions compiles the precondition expression of every ruleset declaring one
+//
+//	Preconditions must evaluate to a bool, every ruleset is compiled even if some fail
+func (re *RuleEngine) compilePreconditions() CompileErrors {
+	var errs CompileErrors
+	for _, name := range re.rulesetNames() {
+		expression := re.config.Rulesets[name].Precondition
+		if expression == "" {
+			continue
+		}
+		program, err := re.compileCondition(expression)
+		if err != nil {
+			var exprErr *CompileError
+			if errors.As(err, &exprErr) {
+				err = exprErr.Err
+			}
+			compileErr := &CompileError{
+				RuleName:   name,
+				Expression: expression,
+				Err:        fmt.Errorf("invalid ruleset precondition: %w", err),
+			}
+			if exprErr != nil {
+				compileErr.Issues = exprErr.Issues
+			}
+			errs = append(errs, compileErr)
+			continue
+		}
+		re.preconditions[name] = program
+	}
+	return errs
+}
+
+// compileCondition checks a precondition or when clause is a bool and compiles it into a `cel.Program`
+func (re *RuleEngine) compileCondition(expression string) (cel.Program, error) {
+	checked, err := re.checkExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if !checked.OutputType().IsExactType(cel.BoolType) && !checked.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression '%s' must return a bool, got %s", expression, checked.OutputType())
+	}
+	return re.newProgram(expression, checked)
+}
+
+// evaluatePrecondition reports whether a ruleset should be skipped because its precondition is not true
+//
+//	Rulesets without a precondition are never skipped, errors are returned if the precondition fails to evaluate
+func (re *RuleEngine) evaluatePrecondition(rulesetName string, eval *evaluation) (bool, error) {
+	program, ok := re.preconditions[rulesetName]
+	if !ok {
+		return false, nil
+	}
+	holds, err := re.evaluateCondition(program, eval)
+	if err != nil {
+		return false, err
+	}
+	return !holds, nil
+}
+
+// evaluateCondition evaluates a compiled precondition or when clause, errors are returned unless it is a bool
+func (re *RuleEngine) evaluateCondition(program cel.Program, eval *evaluation) (bool, error) {
+	out, _, err := re.evalProgram(program, eval.input())
+	if err != nil {
+		return false, err
+	}
+	holds, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition returned %s, want bool", out.Type().TypeName())
+	}
+	return holds, nil
+}
@@ -0,0 +1,155 @@
+package ruleengine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_Precondition(t *testing.T) {
+	type want struct {
+		Passed   bool
+		Skipped  bool
+		Degraded bool
+		Rules    int
+		HasError bool
+	}
+	tests := []struct {
+		name    string
+		ruleset string
+		request map[string]interface{}
+		want    want
+	}{
+		{
+			name:    "success - precondition holds",
+			ruleset: "registration",
+			request: map[string]interface{}{"type": "registration"},
+			want:    want{Passed: true, Rules: 1},
+		},
+		{
+			name:    "success - precondition does not hold, skipped",
+			ruleset: "registration",
+			request: map[string]interface{}{"type": "login"},
+			want:    want{Skipped: true},
+		},
+		{
+			name:    "success - no precondition",
+			ruleset: "unguarded",
+			request: map[string]interface{}{"type": "login"},
+			want:    want{Passed: true, Rules: 1},
+		},
+		{
+			name:    "success - precondition error, fallback allow",
+			ruleset: "broken_precondition_allow",
+			request: map[string]interface{}{},
+			want:    want{Passed: true, Degraded: true},
+		},
+		{
+			name:    "fail - precondition error",
+			ruleset: "broken_precondition",
+			request: map[string]interface{}{},
+			want:    want{HasError: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/precondition_rules.yml").
+				WithVariables("user", "request").
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			ctx := map[string]interface{}{
+				"user":    map[string]interface{}{"age": 21},
+				"request": tt.request,
+			}
+
+			result, err := re.EvaluateRuleset(tt.ruleset, WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			got := want{
+				Passed:   result.Passed,
+				Skipped:  result.Skipped,
+				Degraded: result.Degraded,
+				Rules:    len(result.RuleResults),
+				HasError: result.Error != nil,
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("EvaluateRuleset() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewRuleEngine_Precondition(t *testing.T) {
+	tests := []struct {
+		name         string
+		precondition string
+		wantErr      string
+	}{
+		{
+			name:         "success - bool precondition",
+			precondition: `request.type == "registration"`,
+		},
+		{
+			name:         "fail - precondition does not compile",
+			precondition: `request.type ==`,
+			wantErr:      "rule 'registration': invalid ruleset precondition: failed to compile expression",
+		},
+		{
+			name:         "fail - precondition is not a bool",
+			precondition: `1 + 1`,
+			wantErr:      "rule 'registration': invalid ruleset precondition: expression '1 + 1' must return a bool, got int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &RulesetConfig{
+				Rules: map[string]Rule{
+					"age_validation": {Expression: "user.age >= 18"},
+				},
+				Rulesets: map[string]Ruleset{
+					"registration": {Precondition: tt.precondition, Rules: []string{"age_validation"}},
+				},
+				ExecutionPolicies: map[string]ExecutionPolicy{
+					"collect_all": {StopOnFailure: false},
+				},
+				ErrorHandling: ErrorHandling{ExecutionPolicy: "collect_all"},
+			}
+			_, err := NewBuilder().
+				WithConfig(config).
+				WithVariables("user", "request").
+				Build()
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("Build() error = %v, wantErr %q", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_VariableUsage_Precondition(t *testing.T) {
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/precondition_rules.yml").
+		WithVariables("user", "request").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+
+	usage, err := re.VariableUsage()
+	if err != nil {
+		t.Fatalf("VariableUsage() error = %v", err)
+	}
+	if diff := cmp.Diff(usage.Rulesets["registration"], []string{"request.type", "user.age"}); diff != "" {
+		t.Errorf("VariableUsage() registration (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(usage.Fields, []string{"request.channel", "request.type", "user.age"}); diff != "" {
+		t.Errorf("VariableUsage() fields (-got +want):\n%s", diff)
+	}
+}
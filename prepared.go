@@ -0,0 +1,70 @@
+package ruleengine
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/interpreter"
+)
+
+// PreparedContext is an evaluation context validated and bound to a CEL activation once, see Prepare
+//
+//	Evaluating many rules or rulesets for the same subject with WithPreparedContext skips copying the context, adding
+//	the globals, builtins and computed fields and converting its values for every call. A PreparedContext is immutable
+//	and safe for concurrent use
+type PreparedContext struct {
+	// engine is the engine which prepared the context, its globals and computed fields are bound
+	engine *RuleEngine
+	// source is a copy of the context given to Prepare
+	source map[string]interface{}
+	// context is source with the globals, builtins and computed fields added
+	context    map[string]interface{}
+	activation interpreter.Activation
+}
+
+// Prepare validates ctx against the WithContextLimits limits and binds it, with the globals, builtins and computed
+// fields, to a CEL activation for WithPreparedContext
+//
+//	The top-level map is copied, nested values are shared and must not be modified while the context is in use.
+//	Errors are returned if the context exceeds the limits
+func (re *RuleEngine) Prepare(ctx map[string]interface{}) (*PreparedContext, error) {
+	if active := re.Active(); active != re {
+		return active.Prepare(ctx)
+	}
+	if err := re.validateContext(ctx); err != nil {
+		return nil, err
+	}
+	p := &PreparedContext{engine: re, source: copyMap(ctx)}
+	if p.source == nil {
+		p.source = make(map[string]interface{})
+	}
+	p.context = re.withComputed(re.withBuiltins(copyMap(p.source)))
+
+	adapter := re.env.CELTypeAdapter()
+	bindings := make(map[string]interface{}, len(p.context))
+	for name, value := range p.context {
+		// Values the adapter cannot convert, such as the builtin functions, are resolved as they are
+		if val := adapter.NativeToValue(value); !types.IsError(val) {
+			value = val
+		}
+		bindings[name] = value
+	}
+	activation, err := interpreter.NewActivation(bindings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind context: %w", err)
+	}
+	p.activation = activation
+	return p, nil
+}
+
+// WithPreparedContext evaluates against a context prepared with Prepare instead of the context set by SetContext
+//
+//	It replaces WithEvalContext, the last of the two given wins. Combined with WithGlobalOverrides or WithEvalProfile,
+//	or given to another engine than the one which prepared it, e.g. after Promote, the context is evaluated as with
+//	WithEvalContext
+func WithPreparedContext(p *PreparedContext) EvalOption {
+	return func(e *evaluation) {
+		e.context = p.source
+		e.prepared = p
+	}
+}
@@ -0,0 +1,94 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRuleEngine_Prepare(t *testing.T) {
+	config := rolloutConfig("user.age >= globals.min_age && computed.adult")
+	config.Globals = map[string]interface{}{"min_age": 18}
+	config.Computed = map[string]string{"adult": "user.age >= 18"}
+	re, err := NewBuilder().
+		WithConfig(config).
+		WithVariables("user").
+		WithOptions(WithContextLimits(ContextLimits{MaxStringLength: 8})).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	clone, err := re.Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		engine     *RuleEngine
+		ctx        map[string]interface{}
+		opts       []EvalOption
+		wantPassed bool
+		wantErr    error
+	}{
+		{
+			name:       "success - prepared context",
+			engine:     re,
+			ctx:        map[string]interface{}{"user": map[string]interface{}{"age": 21}},
+			wantPassed: true,
+		},
+		{
+			name:       "success - global overrides",
+			engine:     re,
+			ctx:        map[string]interface{}{"user": map[string]interface{}{"age": 19}},
+			opts:       []EvalOption{WithGlobalOverrides(map[string]interface{}{"min_age": 20})},
+			wantPassed: false,
+		},
+		{
+			name:       "success - prepared by another engine",
+			engine:     clone,
+			ctx:        map[string]interface{}{"user": map[string]interface{}{"age": 21}},
+			wantPassed: true,
+		},
+		{
+			name:       "success - eval context given last wins",
+			engine:     re,
+			ctx:        map[string]interface{}{"user": map[string]interface{}{"age": 21}},
+			opts:       []EvalOption{WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 12}})},
+			wantPassed: false,
+		},
+		{
+			name:    "fail - context exceeds limits",
+			engine:  re,
+			ctx:     map[string]interface{}{"user": map[string]interface{}{"name": "a long name"}},
+			wantErr: ErrContextTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepared, err := re.Prepare(tt.ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Prepare() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			opts := append([]EvalOption{WithPreparedContext(prepared)}, tt.opts...)
+			for i := 0; i < 2; i++ {
+				result, err := tt.engine.EvaluateRuleset("adult", opts...)
+				if err != nil {
+					t.Fatalf("EvaluateRuleset() error = %v", err)
+				}
+				if result.Passed != tt.wantPassed {
+					t.Errorf("EvaluateRuleset() passed = %v, want %v", result.Passed, tt.wantPassed)
+				}
+				rule, err := tt.engine.EvaluateRule("age_validation", opts...)
+				if err != nil {
+					t.Fatalf("EvaluateRule() error = %v", err)
+				}
+				if rule.Passed != tt.wantPassed {
+					t.Errorf("EvaluateRule() passed = %v, want %v", rule.Passed, tt.wantPassed)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package ruleengine
+
+import (
+	"math"
+	"sort"
+)
+
+// earlyExitOrder returns the rules of an OR ruleset ordered so the first pass is likely found cheaply
+//
+//	Rules are ordered by their configured priority, highest first, then by their observed pass rate, then by
+//	their estimated cost including extended rules, and finally by their order in the ruleset
+func (re *RuleEngine) earlyExitOrder(rules []string) []string {
+	type ranked struct {
+		name     string
+		priority int
+		passRate float64
+		cost     uint64
+	}
+	ranks := make([]ranked, 0, len(rules))
+	for _, name := range rules {
+		r := ranked{name: name, priority: re.config.Rules[name].Priority}
+		if counters, ok := re.counters[name]; ok {
+			stats := counters.snapshot()
+			if evaluations := stats.Evaluations(); evaluations > 0 {
+				r.passRate = float64(stats.Passed) / float64(evaluations)
+			}
+		}
+		for _, rule := range append([]string{name}, re.parents[name]...) {
+			r.cost = addCost(r.cost, re.costs[rule].Max)
+		}
+		ranks = append(ranks, r)
+	}
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		switch {
+		case ranks[i].priority != ranks[j].priority:
+			return ranks[i].priority > ranks[j].priority
+		case ranks[i].passRate != ranks[j].passRate:
+			return ranks[i].passRate > ranks[j].passRate
+		default:
+			return ranks[i].cost < ranks[j].cost
+		}
+	})
+
+	ordered := make([]string, 0, len(ranks))
+	for _, r := range ranks {
+		ordered = append(ordered, r.name)
+	}
+	return ordered
+}
+
+// addCost adds two cost estimates, saturating at the maximum
+func addCost(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
@@ -0,0 +1,108 @@
+package ruleengine
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_StopOnFirstPass(t *testing.T) {
+	tests := []struct {
+		name          string
+		rulesetName   string
+		context       map[string]interface{}
+		want          bool
+		wantEvaluated []string
+	}{
+		{
+			name:          "success - cheapest rule first",
+			rulesetName:   "by_cost",
+			want:          true,
+			wantEvaluated: []string{"constant"},
+		},
+		{
+			name:        "success - highest priority rule first",
+			rulesetName: "by_priority",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"vip": true, "tier": "premium"},
+			},
+			want:          true,
+			wantEvaluated: []string{"premium"},
+		},
+		{
+			name:        "success - later rule passes",
+			rulesetName: "by_priority",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"vip": true, "tier": "free"},
+			},
+			want:          true,
+			wantEvaluated: []string{"premium", "vip"},
+		},
+		{
+			name:        "fail - no rule passes",
+			rulesetName: "by_priority",
+			context: map[string]interface{}{
+				"user": map[string]interface{}{"vip": false, "tier": "free"},
+			},
+			want:          false,
+			wantEvaluated: []string{"premium", "vip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewRuleEngine("./testdata/priority_rules.yml", "", setupEnvironment()(t))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			got, err := re.EvaluateRuleset(tt.rulesetName, WithEvalContext(tt.context))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", got.Passed, tt.want)
+			}
+			evaluated := make([]string, 0, len(got.RuleResults))
+			for name := range got.RuleResults {
+				evaluated = append(evaluated, name)
+			}
+			sort.Strings(evaluated)
+			if diff := cmp.Diff(evaluated, tt.wantEvaluated); diff != "" {
+				t.Errorf("EvaluateRuleset() evaluated rules (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_earlyExitOrder(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/priority_rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	rules := []string{"nested_loops", "vip", "constant", "premium"}
+	want := []string{"premium", "constant", "vip", "nested_loops"}
+	if diff := cmp.Diff(re.earlyExitOrder(rules), want); diff != "" {
+		t.Errorf("earlyExitOrder() (-got +want):\n%s", diff)
+	}
+
+	// Observed pass rates take precedence over cost
+	for i := 0; i < 3; i++ {
+		_, _ = re.EvaluateRule("vip", WithEvalContext(map[string]interface{}{
+			"user": map[string]interface{}{"vip": true},
+		}))
+	}
+	want = []string{"premium", "vip", "constant", "nested_loops"}
+	if diff := cmp.Diff(re.earlyExitOrder(rules), want); diff != "" {
+		t.Errorf("earlyExitOrder() after evaluations (-got +want):\n%s", diff)
+	}
+}
+
+func TestAddCost(t *testing.T) {
+	if got := addCost(1, 2); got != 3 {
+		t.Errorf("addCost(1, 2) = %d, want 3", got)
+	}
+	if got := addCost(math.MaxUint64, 1); got != math.MaxUint64 {
+		t.Errorf("addCost(max, 1) = %d, want saturated", got)
+	}
+}
@@ -0,0 +1,151 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ProblemContentType is the media type of Problem bodies, see RFC 9457
+const ProblemContentType = "application/problem+json"
+
+// HTTPProblems maps the results which did not pass to HTTP status codes and problem+json bodies
+//
+//	Reason code mappings take precedence over severity mappings, the first failing rule in evaluation order with a
+//	mapping is used, and Default applies when none of them has one
+type HTTPProblems struct {
+	// ReasonCodes maps rule reason codes, e.g. "KYC_001", to their problem
+	ReasonCodes map[string]ProblemMapping `yaml:"reason_codes"`
+	// Severities maps rule severities, e.g. "critical", to their problem
+	Severities map[string]ProblemMapping `yaml:"severities"`
+	// Default is the problem of results without a mapping, a 422 Unprocessable Entity when the status is unset
+	Default ProblemMapping `yaml:"default"`
+}
+
+// ProblemMapping is the HTTP status and problem details reported for a result which did not pass
+type ProblemMapping struct {
+	// Status is the HTTP status code, e.g. 403
+	Status int `yaml:"status"`
+	// Type is a URI identifying the problem type, "about:blank" when empty
+	Type string `yaml:"type"`
+	// Title is a short human-readable summary of the problem type, the status text when empty
+	Title string `yaml:"title"`
+}
+
+// Problem is an RFC 9457 problem details body describing why a rule or ruleset did not pass
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	// Detail is the error message of the result, see Rule.ErrorMessage
+	Detail string `json:"detail,omitempty"`
+	// Instance is the name of the evaluated rule or ruleset
+	Instance string `json:"instance,omitempty"`
+	// ReasonCodes are the reason codes of the rules which did not pass
+	ReasonCodes []string `json:"reason_codes,omitempty"`
+}
+
+// validate checks the configured status codes are HTTP client or server errors
+func (hp *HTTPProblems) validate() error {
+	if hp == nil {
+		return nil
+	}
+	mappings := map[string]ProblemMapping{"default": hp.Default}
+	for code, m := range hp.ReasonCodes {
+		mappings["reason code "+code] = m
+	}
+	for severity, m := range hp.Severities {
+		mappings["severity "+severity] = m
+	}
+	names := make([]string, 0, len(mappings))
+	for name := range mappings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if status := mappings[name].Status; status != 0 && (status < 400 || status > 599) {
+			errs = append(errs, fmt.Errorf("%s status %d must be between 400 and 599", name, status))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RuleProblem returns the problem describing why the rule did not pass
+//
+//	nil is returned if the rule passed or was skipped, or if error_handling.http_problems is not configured
+func (re *RuleEngine) RuleProblem(result RuleResult) *Problem {
+	if active := re.Active(); active != re {
+		return active.RuleProblem(result)
+	}
+	if result.Passed || result.Skipped || re.config.ErrorHandling.HTTPProblems == nil {
+		return nil
+	}
+	problem := re.problem([]RuleResult{result})
+	problem.Instance = result.RuleName
+	if result.Error != nil {
+		problem.Detail = result.Error.Error()
+	}
+	if result.ReasonCode != "" {
+		problem.ReasonCodes = []string{result.ReasonCode}
+	}
+	return problem
+}
+
+// RulesetProblem returns the problem describing why the ruleset did not pass
+//
+//	nil is returned if the ruleset passed or was skipped, or if error_handling.http_problems is not configured
+func (re *RuleEngine) RulesetProblem(result RulesetResult) *Problem {
+	if active := re.Active(); active != re {
+		return active.RulesetProblem(result)
+	}
+	if result.Passed || result.Skipped || re.config.ErrorHandling.HTTPProblems == nil {
+		return nil
+	}
+	failed := make([]RuleResult, 0, len(result.RuleResults))
+	for _, rule := range result.Ordered() {
+		if !rule.Passed && !rule.Skipped {
+			failed = append(failed, rule)
+		}
+	}
+	problem := re.problem(failed)
+	problem.Instance = result.RulesetName
+	if result.Error != nil {
+		problem.Detail = result.Error.Error()
+	}
+	problem.ReasonCodes = result.ReasonCodes
+	return problem
+}
+
+// problem resolves the mapping of the failed rules, see HTTPProblems
+func (re *RuleEngine) problem(failed []RuleResult) *Problem {
+	mapping := re.problemMapping(re.config.ErrorHandling.HTTPProblems, failed)
+	problem := &Problem{Type: mapping.Type, Title: mapping.Title, Status: mapping.Status}
+	if problem.Status == 0 {
+		problem.Status = http.StatusUnprocessableEntity
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	return problem
+}
+
+// problemMapping returns the mapping of the first failed rule with a reason code mapping, else with a severity one
+func (re *RuleEngine) problemMapping(hp *HTTPProblems, failed []RuleResult) ProblemMapping {
+	for _, rule := range failed {
+		if m, ok := hp.ReasonCodes[rule.ReasonCode]; ok && rule.ReasonCode != "" {
+			return m
+		}
+	}
+	for _, rule := range failed {
+		severity := re.config.Rules[rule.RuleName].Severity
+		if m, ok := hp.Severities[severity]; ok && severity != "" {
+			return m
+		}
+	}
+	return hp.Default
+}
@@ -0,0 +1,119 @@
+package ruleengine
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_RulesetProblem(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/problem_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	tests := []struct {
+		name    string
+		ruleset string
+		user    map[string]interface{}
+		want    *Problem
+	}{
+		{
+			name:    "success - passed",
+			ruleset: "onboarding",
+			user:    map[string]interface{}{"verified": true, "sanctioned": false, "email": "a@example.com"},
+		},
+		{
+			name:    "success - reason code wins over severity",
+			ruleset: "onboarding",
+			user:    map[string]interface{}{"verified": false, "sanctioned": true, "email": "a@example.com"},
+			want: &Problem{
+				Type:        "https://errors.example.com/kyc",
+				Title:       "Identity verification required",
+				Status:      http.StatusForbidden,
+				Detail:      "ruleset 'onboarding' did not pass evaluation",
+				Instance:    "onboarding",
+				ReasonCodes: []string{"KYC_001"},
+			},
+		},
+		{
+			name:    "success - severity",
+			ruleset: "onboarding",
+			user:    map[string]interface{}{"verified": true, "sanctioned": true, "email": "invalid"},
+			want: &Problem{
+				Type:     "about:blank",
+				Title:    "Unavailable For Legal Reasons",
+				Status:   http.StatusUnavailableForLegalReasons,
+				Detail:   "ruleset 'onboarding' did not pass evaluation",
+				Instance: "onboarding",
+			},
+		},
+		{
+			name:    "success - default",
+			ruleset: "contact",
+			user:    map[string]interface{}{"email": "invalid"},
+			want: &Problem{
+				Type:     "about:blank",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "ruleset 'contact' did not pass evaluation",
+				Instance: "contact",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := re.EvaluateRuleset(tt.ruleset, WithEvalContext(map[string]interface{}{"user": tt.user}))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, re.RulesetProblem(result)); diff != "" {
+				t.Errorf("RulesetProblem() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_RuleProblem(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/problem_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	got := re.RuleProblem(RuleResult{RuleName: "identity_verified", ReasonCode: "KYC_001", Error: errors.New("identity is not verified")})
+	want := &Problem{
+		Type:        "https://errors.example.com/kyc",
+		Title:       "Identity verification required",
+		Status:      http.StatusForbidden,
+		Detail:      "identity is not verified",
+		Instance:    "identity_verified",
+		ReasonCodes: []string{"KYC_001"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("RuleProblem() mismatch (-want +got):\n%s", diff)
+	}
+	if got := re.RuleProblem(RuleResult{RuleName: "identity_verified", Skipped: true}); got != nil {
+		t.Errorf("RuleProblem() = %+v, want nil for a skipped rule", got)
+	}
+
+	// Without http_problems results are not mapped
+	plain, err := NewBuilder().WithConfigFile("./testdata/reason_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	if got := plain.RuleProblem(RuleResult{RuleName: "age_validation"}); got != nil {
+		t.Errorf("RuleProblem() = %+v, want nil without http_problems", got)
+	}
+
+	_, err = NewBuilder().WithConfig(&RulesetConfig{
+		Rules:             map[string]Rule{"r": {Expression: "true"}},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling: ErrorHandling{
+			ExecutionPolicy: "collect_all",
+			HTTPProblems:    &HTTPProblems{Severities: map[string]ProblemMapping{"critical": {Status: 200}}},
+		},
+	}).Build()
+	if err == nil {
+		t.Errorf("Build() error = nil, want a non-error status rejected")
+	}
+}
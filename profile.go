@@ -0,0 +1,34 @@
+package ruleengine
+
+// Profile is a named set of overrides, e.g. a country or jurisdiction, layered on top of the environment overrides
+//
+//	Environments and profiles are independent dimensions: the environment picks where the engine runs, the profile
+//	picks whose rules apply, e.g. the min_age of the jurisdiction. A profile is selected when building the engine
+//	with Builder.WithProfile, or per evaluation with WithEvalProfile
+type Profile struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Globals     map[string]interface{} `yaml:"globals"`
+}
+
+// ApplyProfile applies the overrides of the named profile to the configuration, an unknown profile is a no-op
+//
+//	Apply it after ApplyEnvironment so the profile takes precedence over the environment
+func (rc *RulesetConfig) ApplyProfile(profile string) {
+	p, ok := rc.Profiles[profile]
+	if !ok {
+		return
+	}
+	if rc.Globals == nil {
+		rc.Globals = make(map[string]interface{}, len(p.Globals))
+	}
+	rc.GlobalsMerge.mergeInto(rc.Globals, p.Globals)
+}
+
+// withProfile applies the named profile once the environment overrides have been applied
+func withProfile(profile string) Option {
+	return func(re *RuleEngine) {
+		re.profile = profile
+		re.config.ApplyProfile(profile)
+	}
+}
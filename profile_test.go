@@ -0,0 +1,120 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuilder_WithProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		profile     string
+		wantGlobals map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "success - no profile",
+			wantGlobals: map[string]interface{}{"min_age": 18, "drinking_age": 18},
+		},
+		{
+			name:        "success - profile",
+			profile:     "us",
+			wantGlobals: map[string]interface{}{"min_age": 18, "drinking_age": 21},
+		},
+		{
+			name:        "success - profile layered on the environment",
+			environment: "development",
+			profile:     "us",
+			wantGlobals: map[string]interface{}{"min_age": 13, "drinking_age": 21},
+		},
+		{
+			name:    "fail - unknown profile",
+			profile: "fr",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/profile_rules.yml").
+				WithEnvironment(tt.environment).
+				WithProfile(tt.profile).
+				WithVariables("user").
+				Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.wantGlobals, re.config.Globals); diff != "" {
+				t.Errorf("Build() globals mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithEvalProfile(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/profile_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := map[string]interface{}{"user": map[string]interface{}{"age": 19}}
+	tests := []struct {
+		name       string
+		opts       []EvalOption
+		wantPassed map[string]bool
+		wantErr    bool
+	}{
+		{
+			name:       "success - engine globals",
+			opts:       []EvalOption{WithEvalContext(ctx)},
+			wantPassed: map[string]bool{"age_validation": true, "alcohol_purchase": true},
+		},
+		{
+			name:       "success - profile",
+			opts:       []EvalOption{WithEvalContext(ctx), WithEvalProfile("us")},
+			wantPassed: map[string]bool{"age_validation": true, "alcohol_purchase": false},
+		},
+		{
+			name:       "success - profile with the engine context",
+			opts:       []EvalOption{WithEvalProfile("us")},
+			wantPassed: map[string]bool{"age_validation": true, "alcohol_purchase": false},
+		},
+		{
+			name: "success - global overrides win over the profile",
+			opts: []EvalOption{WithEvalContext(ctx), WithEvalProfile("us"),
+				WithGlobalOverrides(map[string]interface{}{"drinking_age": 19})},
+			wantPassed: map[string]bool{"age_validation": true, "alcohol_purchase": true},
+		},
+		{
+			name:    "fail - unknown profile",
+			opts:    []EvalOption{WithEvalContext(ctx), WithEvalProfile("fr")},
+			wantErr: true,
+		},
+	}
+	re.SetContext(ctx)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.EvaluateRuleset("checkout", tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateRuleset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gotPassed := make(map[string]bool, len(got.RuleResults))
+			for name, result := range got.RuleResults {
+				gotPassed[name] = result.Passed
+			}
+			if diff := cmp.Diff(tt.wantPassed, gotPassed); diff != "" {
+				t.Errorf("EvaluateRuleset() passed mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if diff := cmp.Diff(map[string]interface{}{"min_age": 18, "drinking_age": 18}, re.config.Globals); diff != "" {
+		t.Errorf("WithEvalProfile() mutated the engine globals (-want +got):\n%s", diff)
+	}
+}
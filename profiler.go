@@ -0,0 +1,144 @@
+package ruleengine
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// profileWindow is the number of most recent samples kept per rule for percentile calculation
+const profileWindow = 1024
+
+// RuleProfile is the sampled latency and cost profile of a single rule
+type RuleProfile struct {
+	// Samples is the total number of sampled evaluations
+	Samples uint64
+	// P50 is the median latency of the retained samples
+	P50 time.Duration
+	// P90 is the 90th percentile latency of the retained samples
+	P90 time.Duration
+	// P99 is the 99th percentile latency of the retained samples
+	P99 time.Duration
+	// Max is the maximum latency of the retained samples
+	Max time.Duration
+	// MeanCost is the mean CEL runtime cost of the retained samples
+	MeanCost float64
+	// MaxCost is the maximum CEL runtime cost of the retained samples
+	MaxCost uint64
+}
+
+// WithProfiler enables sampling-based profiling of rule evaluations
+//
+//	rate is the fraction of evaluations to sample between 0 and 1, e.g. 0.01 samples 1% of evaluations.
+//	Sampled latencies and CEL runtime costs are reported by Stats().Profiles
+func WithProfiler(rate float64) Option {
+	return func(re *RuleEngine) {
+		re.profiler = &profiler{
+			rate:    min(max(rate, 0), 1),
+			samples: make(map[string]*ruleSamples),
+		}
+	}
+}
+
+// profiler records sampled rule latencies and costs
+type profiler struct {
+	rate float64
+
+	mu      sync.Mutex
+	samples map[string]*ruleSamples
+}
+
+// profiledProgram pairs a rule program with a cost tracking variant used for sampled evaluations
+type profiledProgram struct {
+	cel.Program
+	profiled cel.Program
+}
+
+// ruleSamples is a ring buffer of the most recent samples of a rule
+type ruleSamples struct {
+	latencies []time.Duration
+	costs     []uint64
+	next      int
+	total     uint64
+}
+
+// sample reports whether the current evaluation should be profiled
+func (p *profiler) sample() bool {
+	if p == nil || p.rate == 0 {
+		return false
+	}
+	return p.rate >= 1 || rand.Float64() < p.rate
+}
+
+// record adds a sample for the rule
+func (p *profiler) record(ruleName string, latency time.Duration, cost uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.samples[ruleName]
+	if !ok {
+		s = &ruleSamples{}
+		p.samples[ruleName] = s
+	}
+	if len(s.latencies) < profileWindow {
+		s.latencies = append(s.latencies, latency)
+		s.costs = append(s.costs, cost)
+	} else {
+		s.latencies[s.next] = latency
+		s.costs[s.next] = cost
+		s.next = (s.next + 1) % profileWindow
+	}
+	s.total++
+}
+
+// report computes the profile of every sampled rule
+func (p *profiler) report() map[string]RuleProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	profiles := make(map[string]RuleProfile, len(p.samples))
+	for name, s := range p.samples {
+		latencies := slices.Clone(s.latencies)
+		slices.Sort(latencies)
+
+		profile := RuleProfile{
+			Samples: s.total,
+			P50:     percentile(latencies, 0.50),
+			P90:     percentile(latencies, 0.90),
+			P99:     percentile(latencies, 0.99),
+			Max:     latencies[len(latencies)-1],
+		}
+		var totalCost uint64
+		for _, c := range s.costs {
+			totalCost += c
+			profile.MaxCost = max(profile.MaxCost, c)
+		}
+		profile.MeanCost = float64(totalCost) / float64(len(s.costs))
+		profiles[name] = profile
+	}
+	return profiles
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[min(max(idx, 0), len(sorted)-1)]
+}
+
+// StatsHandler returns an http.Handler serving Stats() as JSON
+func (re *RuleEngine) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(re.Stats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package ruleengine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{name: "empty", sorted: nil, p: 0.5, want: 0},
+		{name: "p50", sorted: sorted, p: 0.5, want: 5},
+		{name: "p90", sorted: sorted, p: 0.9, want: 9},
+		{name: "p99", sorted: sorted, p: 0.99, want: 10},
+		{name: "p0", sorted: sorted, p: 0, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_Profiler(t *testing.T) {
+	tests := []struct {
+		name        string
+		rate        float64
+		wantSamples uint64
+	}{
+		{name: "sample all", rate: 1, wantSamples: 20},
+		{name: "sample none", rate: 0, wantSamples: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewRuleEngine("./testdata/rules.yml", "production", setupEnvironment()(t), WithProfiler(tt.rate))
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			ctx := map[string]interface{}{"user": map[string]interface{}{"age": 21}}
+			for i := 0; i < 20; i++ {
+				if _, err := re.EvaluateRule("age_validation", WithEvalContext(ctx)); err != nil {
+					t.Fatalf("EvaluateRule() error = %v", err)
+				}
+			}
+
+			profile := re.Stats().Profiles["age_validation"]
+			if profile.Samples != tt.wantSamples {
+				t.Errorf("Stats() samples = %d, want %d", profile.Samples, tt.wantSamples)
+			}
+			if tt.wantSamples > 0 && (profile.MaxCost == 0 || profile.P50 > profile.P99 || profile.P99 > profile.Max) {
+				t.Errorf("Stats() inconsistent profile %+v", profile)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_StatsHandler(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "production", setupEnvironment()(t), WithProfiler(1))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	if _, err := re.EvaluateRule("age_validation", WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"age": 21},
+	})); err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	re.StatsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("StatsHandler() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("StatsHandler() returned invalid JSON: %v", err)
+	}
+	if got.Rules["age_validation"].Passed != 1 || got.Profiles["age_validation"].Samples != 1 {
+		t.Errorf("StatsHandler() = %+v", got)
+	}
+}
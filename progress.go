@@ -0,0 +1,30 @@
+package ruleengine
+
+// ProgressFunc receives the progress of a ruleset evaluation, done of its total rules are evaluated
+type ProgressFunc func(rulesetName string, done, total int)
+
+// WithProgress reports the progress of every ruleset evaluated by this call to progress, e.g. for UIs and job
+// monitors following long-running batch evaluations
+//
+//	progress is called synchronously after each rule of a ruleset, done counts up to total. Rules left unevaluated
+//	because the outcome is known, e.g. with stop_on_failure, and rulesets skipped by their precondition or time window
+//	are reported done at once. Shadow and mirrored evaluations do not report progress
+func WithProgress(progress ProgressFunc) EvalOption {
+	return func(e *evaluation) {
+		e.progress = progress
+	}
+}
+
+// withoutProgress drops the WithProgress callback, for evaluations the caller did not ask for
+func withoutProgress() EvalOption {
+	return func(e *evaluation) {
+		e.progress = nil
+	}
+}
+
+// reportProgress calls the WithProgress callback, if any
+func (e *evaluation) reportProgress(rulesetName string, done, total int) {
+	if e.progress != nil {
+		e.progress(rulesetName, done, total)
+	}
+}
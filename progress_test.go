@@ -0,0 +1,86 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithProgress(t *testing.T) {
+	type progress struct {
+		Ruleset     string
+		Done, Total int
+	}
+	tests := []struct {
+		name        string
+		opts        []Option
+		rulesetName string
+		user        map[string]interface{}
+		want        []progress
+	}{
+		{
+			name:        "success - every rule",
+			rulesetName: "signup",
+			user:        map[string]interface{}{"age": 30, "verified": true, "phone": "123", "tier": "premium"},
+			want:        []progress{{"signup", 1, 4}, {"signup", 2, 4}, {"signup", 3, 4}, {"signup", 4, 4}},
+		},
+		{
+			name:        "success - stop on failure",
+			opts:        []Option{WithPolicy(Policy{StopOnFailure: true})},
+			rulesetName: "signup",
+			user:        map[string]interface{}{"age": 12, "verified": true, "phone": "123"},
+			want:        []progress{{"signup", 1, 4}, {"signup", 4, 4}},
+		},
+		{
+			name:        "success - precondition skipped",
+			rulesetName: "gated",
+			user:        map[string]interface{}{"age": 0},
+			want:        []progress{{"gated", 1, 1}},
+		},
+		{
+			name:        "success - empty ruleset",
+			rulesetName: "empty_or",
+			user:        map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(hotPathConfig()).WithVariables("user").WithOptions(tt.opts...).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			var got []progress
+			_, err = re.EvaluateRuleset(tt.rulesetName,
+				WithEvalContext(map[string]interface{}{"user": tt.user}),
+				WithProgress(func(rulesetName string, done, total int) {
+					got = append(got, progress{rulesetName, done, total})
+				}))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("WithProgress() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithProgress_AllRulesets(t *testing.T) {
+	re, err := NewBuilder().WithConfig(hotPathConfig()).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	last := map[string][2]int{}
+	_, err = re.EvaluateAllRulesets(
+		WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 30, "verified": true}}),
+		WithProgress(func(rulesetName string, done, total int) {
+			last[rulesetName] = [2]int{done, total}
+		}))
+	if err != nil {
+		t.Fatalf("EvaluateAllRulesets() error = %v", err)
+	}
+	want := map[string][2]int{"signup": {4, 4}, "access": {3, 3}, "any": {2, 2}, "gated": {1, 1}}
+	if diff := cmp.Diff(want, last); diff != "" {
+		t.Errorf("WithProgress() (-want +got):\n%s", diff)
+	}
+}
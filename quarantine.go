@@ -0,0 +1,114 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrQuarantined is the error of rules skipped because they were quarantined at load time, see WithQuarantine
+var ErrQuarantined = errors.New("rule is quarantined")
+
+// WithQuarantine quarantines rules referencing functions or variables the cel.Env does not declare instead of
+// failing to load the engine, so the rest of the config keeps working when an optional function library is missing
+//
+//	Quarantined rules, and the rules extending them, are skipped during evaluation with an error wrapping
+//	ErrQuarantined and their CompileError, see Quarantined. Any other compile error still fails loading
+func WithQuarantine() Option {
+	return func(re *RuleEngine) {
+		re.quarantined = make(map[string]*CompileError)
+	}
+}
+
+// RuleQuarantined is emitted when a rule is quarantined while loading, see WithQuarantine
+type RuleQuarantined struct {
+	// RuleName is the name of the quarantined rule
+	RuleName string
+	// Err is the compile error which caused the quarantine
+	Err *CompileError
+}
+
+// EventName implements Event
+func (RuleQuarantined) EventName() string {
+	return "rule_quarantined"
+}
+
+// Quarantined returns the compile errors of the quarantined rules sorted by rule name, none unless WithQuarantine
+func (re *RuleEngine) Quarantined() CompileErrors {
+	if active := re.Active(); active != re {
+		return active.Quarantined()
+	}
+	names := make([]string, 0, len(re.quarantined))
+	for name := range re.quarantined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := make(CompileErrors, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, re.quarantined[name])
+	}
+	return errs
+}
+
+// quarantine moves the compile errors caused by undeclared references out of errs into the quarantined rules,
+// along with the rules extending them, and returns the remaining errors
+func (re *RuleEngine) quarantine(errs CompileErrors) CompileErrors {
+	if re.quarantined == nil {
+		return errs
+	}
+	remaining := make(CompileErrors, 0, len(errs))
+	for _, err := range errs {
+		if !undeclaredReference(err) {
+			remaining = append(remaining, err)
+			continue
+		}
+		if _, ok := re.quarantined[err.RuleName]; !ok {
+			re.quarantined[err.RuleName] = err
+		}
+	}
+
+	// Rules extending a quarantined rule cannot be evaluated either
+	for _, name := range re.ruleNames() {
+		for _, parent := range re.parents[name] {
+			if _, ok := re.quarantined[parent]; ok {
+				re.quarantined[name] = &CompileError{
+					RuleName: name,
+					Source:   re.sources[name],
+					Err:      fmt.Errorf("extends quarantined rule '%s'", parent),
+				}
+				break
+			}
+		}
+	}
+	for _, err := range re.Quarantined() {
+		re.emit(RuleQuarantined{RuleName: err.RuleName, Err: err})
+	}
+	return remaining
+}
+
+// undeclaredReference reports whether every issue of a compile error is a reference the env does not declare
+func undeclaredReference(err *CompileError) bool {
+	if err.RuleName == "" || len(err.Issues) == 0 {
+		return false
+	}
+	for _, issue := range err.Issues {
+		if !strings.HasPrefix(issue.Message, "undeclared reference to") {
+			return false
+		}
+	}
+	return true
+}
+
+// quarantinedResult returns the skipped result of a quarantined rule, false if the rule is not quarantined
+func (re *RuleEngine) quarantinedResult(ruleName string) (RuleResult, bool) {
+	err, ok := re.quarantined[ruleName]
+	if !ok {
+		return RuleResult{}, false
+	}
+	return RuleResult{
+		RuleName: ruleName,
+		Skipped:  true,
+		Error:    fmt.Errorf("%w: %w", ErrQuarantined, err),
+	}, true
+}
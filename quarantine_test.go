@@ -0,0 +1,133 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithQuarantine(t *testing.T) {
+	tests := []struct {
+		name            string
+		rules           map[string]Rule
+		quarantine      bool
+		wantErr         bool
+		wantQuarantined []string
+		wantPassed      bool
+	}{
+		{
+			name: "success - missing function quarantined",
+			rules: map[string]Rule{
+				"adult":     {Expression: "user.age >= 18"},
+				"corporate": {Expression: "user.email.isCorporate()"},
+			},
+			quarantine:      true,
+			wantQuarantined: []string{"corporate"},
+			wantPassed:      true,
+		},
+		{
+			name: "success - rules extending a quarantined rule quarantined",
+			rules: map[string]Rule{
+				"adult":     {Expression: "user.age >= 18"},
+				"corporate": {Expression: "is_corporate(user.email)"},
+				"staff":     {Expression: "user.staff", Extends: "corporate"},
+			},
+			quarantine:      true,
+			wantQuarantined: []string{"corporate", "staff"},
+			wantPassed:      true,
+		},
+		{
+			name: "success - missing function in when clause quarantined",
+			rules: map[string]Rule{
+				"adult":     {Expression: "user.age >= 18"},
+				"corporate": {Expression: "true", When: "user.email.isCorporate()"},
+			},
+			quarantine:      true,
+			wantQuarantined: []string{"corporate"},
+			wantPassed:      true,
+		},
+		{
+			name: "success - nothing quarantined",
+			rules: map[string]Rule{
+				"adult":     {Expression: "user.age >= 18"},
+				"corporate": {Expression: "user.email.endsWith('@acme.com')"},
+			},
+			quarantine:      true,
+			wantQuarantined: []string{},
+			wantPassed:      false,
+		},
+		{
+			name: "fail - missing function without quarantine",
+			rules: map[string]Rule{
+				"adult":     {Expression: "user.age >= 18"},
+				"corporate": {Expression: "user.email.isCorporate()"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail - syntax error not quarantined",
+			rules: map[string]Rule{
+				"adult":     {Expression: "user.age >= 18"},
+				"corporate": {Expression: "user.email.isCorporate("},
+			},
+			quarantine: true,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make([]string, 0)
+			opts := []Option{WithEventHandler(func(e Event) {
+				if q, ok := e.(RuleQuarantined); ok {
+					events = append(events, q.RuleName)
+				}
+			})}
+			if tt.quarantine {
+				opts = append(opts, WithQuarantine())
+			}
+			rules := make([]string, 0, len(tt.rules))
+			for name := range tt.rules {
+				rules = append(rules, name)
+			}
+			re, err := NewBuilder().WithConfig(&RulesetConfig{
+				Rules:             tt.rules,
+				Rulesets:          map[string]Ruleset{"all": {Selector: "AND", Rules: rules}},
+				ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+				ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+			}).WithVariables("user").WithOptions(opts...).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			quarantined := make([]string, 0)
+			for _, err := range re.Quarantined() {
+				quarantined = append(quarantined, err.RuleName)
+			}
+			if diff := cmp.Diff(quarantined, tt.wantQuarantined); diff != "" {
+				t.Errorf("Quarantined() (-got +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(events, tt.wantQuarantined); diff != "" {
+				t.Errorf("RuleQuarantined events (-got +want):\n%s", diff)
+			}
+
+			ctx := map[string]interface{}{"user": map[string]interface{}{"age": 21, "email": "a@b.com", "staff": true}}
+			result, err := re.EvaluateRuleset("all", WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+			for _, name := range tt.wantQuarantined {
+				got := result.RuleResults[name]
+				if !got.Skipped || !errors.Is(got.Error, ErrQuarantined) {
+					t.Errorf("rule %s result = %+v, want skipped with ErrQuarantined", name, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQueueFull is returned, or is the cause of the degraded results, of evaluations refused by a full evaluation
+// queue, see WithEvaluationQueue
+var ErrQueueFull = errors.New("evaluation queue full")
+
+const (
+	// QueueFullReject fails evaluations refused by a full queue with ErrQueueFull
+	QueueFullReject = "reject"
+	// QueueFullShed returns degraded results for evaluations refused by a full queue, see QueueConfig.OnFull
+	QueueFullShed = "shed"
+)
+
+// QueueConfig configures the evaluation queue of WithEvaluationQueue
+type QueueConfig struct {
+	// Concurrency is the maximum number of evaluations running at once
+	Concurrency int
+	// Size is the maximum number of evaluations waiting for one of the running evaluations to finish, zero refuses
+	// evaluations as soon as Concurrency are running
+	Size int
+	// MaxWait is how long an evaluation waits in the queue before it is refused, zero waits until it runs
+	MaxWait time.Duration
+	// OnFull is what happens to refused evaluations, QueueFullReject, the default, or QueueFullShed. Shed
+	// evaluations of rulesets return a degraded result applying the ruleset fallback, "deny" when it has none
+	OnFull string
+}
+
+// QueueStats reports the state of the evaluation queue, see RuleEngine.QueueStats
+type QueueStats struct {
+	// Running and Waiting are the number of evaluations running and waiting to run
+	Running int
+	Waiting int
+	// Rejected and Shed count the evaluations refused by the queue
+	Rejected uint64
+	Shed     uint64
+}
+
+// WithEvaluationQueue bounds the evaluations running at once, protecting downstream lookup providers, e.g.
+// WithHTTPGet endpoints, during traffic spikes
+//
+//	Evaluations beyond Concurrency wait in a queue of Size, evaluations arriving at a full queue or waiting longer
+//	than MaxWait are refused as configured by OnFull. Single rule and expression evaluations are always rejected with
+//	ErrQueueFull, they have no degraded result. The queue applies to every evaluation, EvaluateRule, EvaluateAs,
+//	EvaluateExpression and the ruleset evaluations, WhatIf, EvaluateDecision and Decide queue each of their
+//	ruleset evaluations
+func WithEvaluationQueue(config QueueConfig) Option {
+	return func(re *RuleEngine) {
+		re.queue = &evalQueue{config: config}
+	}
+}
+
+// evalQueue is a semaphore of the running evaluations with a bounded number of waiters
+type evalQueue struct {
+	config  QueueConfig
+	slots   chan struct{}
+	waiting atomic.Int64
+
+	rejected atomic.Uint64
+	shed     atomic.Uint64
+}
+
+// init validates the queue config and allocates the slots of the running evaluations
+func (q *evalQueue) init() error {
+	switch {
+	case q.config.Concurrency < 1:
+		return fmt.Errorf("concurrency must be at least 1, got %d", q.config.Concurrency)
+	case q.config.Size < 0:
+		return fmt.Errorf("size must not be negative, got %d", q.config.Size)
+	case q.config.MaxWait < 0:
+		return fmt.Errorf("max wait must not be negative, got %s", q.config.MaxWait)
+	}
+	switch q.config.OnFull {
+	case "":
+		q.config.OnFull = QueueFullReject
+	case QueueFullReject, QueueFullShed:
+	default:
+		return fmt.Errorf("unknown queue full behaviour '%s', want '%s' or '%s'", q.config.OnFull, QueueFullReject, QueueFullShed)
+	}
+	q.slots = make(chan struct{}, q.config.Concurrency)
+	return nil
+}
+
+// acquire waits for a running slot, false if the queue is full or the wait exceeds MaxWait
+func (q *evalQueue) acquire() bool {
+	select {
+	case q.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if q.waiting.Add(1) > int64(q.config.Size) {
+		q.waiting.Add(-1)
+		return false
+	}
+	defer q.waiting.Add(-1)
+	if q.config.MaxWait == 0 {
+		q.slots <- struct{}{}
+		return true
+	}
+	timer := time.NewTimer(q.config.MaxWait)
+	defer timer.Stop()
+	select {
+	case q.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// release frees the running slot of a finished evaluation
+func (q *evalQueue) release() {
+	<-q.slots
+}
+
+// enqueue waits for the evaluation to run, shed reports it was refused and should return degraded results, errors
+// wrapping ErrQueueFull are returned if it was refused and is rejected
+//
+//	release must be called once the evaluation finishes unless it was refused
+func (re *RuleEngine) enqueue(sheddable bool) (release func(), shed bool, err error) {
+	q := re.queue
+	if q == nil {
+		return func() {}, false, nil
+	}
+	if q.acquire() {
+		return q.release, false, nil
+	}
+	if sheddable && q.config.OnFull == QueueFullShed {
+		q.shed.Add(1)
+		return nil, true, nil
+	}
+	q.rejected.Add(1)
+	return nil, false, fmt.Errorf("%w: %d running, %d waiting", ErrQueueFull, q.config.Concurrency, q.config.Size)
+}
+
+// shedResult returns the degraded result of a ruleset evaluation refused by the queue
+func (re *RuleEngine) shedResult(rulesetName string) (RulesetResult, error) {
+	ruleset, ok := re.config.Rulesets[rulesetName]
+	if !ok {
+		return RulesetResult{}, fmt.Errorf("ruleset '%s' not found", rulesetName)
+	}
+	fallback := ruleset.Fallback
+	if fallback == "" {
+		fallback = FallbackDeny
+	}
+	result := RulesetResult{RulesetName: rulesetName, RuleResults: make(map[string]RuleResult)}
+	applyFallback(&result, fallback, ErrQueueFull)
+	return re.transformResult(result), nil
+}
+
+// shedResults returns the degraded results of the named rulesets for an evaluation refused by the queue
+func (re *RuleEngine) shedResults(names []string) (map[string]RulesetResult, error) {
+	results := make(map[string]RulesetResult, len(names))
+	for _, name := range names {
+		result, err := re.shedResult(name)
+		if err != nil {
+			return results, err
+		}
+		results[name] = result
+	}
+	return results, nil
+}
+
+// QueueStats returns the state of the evaluation queue, zero unless WithEvaluationQueue
+func (re *RuleEngine) QueueStats() QueueStats {
+	q := re.Active().queue
+	if q == nil {
+		return QueueStats{}
+	}
+	return QueueStats{
+		Running:  len(q.slots),
+		Waiting:  int(q.waiting.Load()),
+		Rejected: q.rejected.Load(),
+		Shed:     q.shed.Load(),
+	}
+}
@@ -0,0 +1,186 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithEvaluationQueue(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       QueueConfig
+		fallback     string
+		wantBuildErr bool
+		wantErr      error
+		wantPassed   bool
+		wantDegraded bool
+		wantStats    QueueStats
+	}{
+		{
+			name:      "fail - queue full rejected",
+			config:    QueueConfig{Concurrency: 1},
+			wantErr:   ErrQueueFull,
+			wantStats: QueueStats{Running: 1, Rejected: 1},
+		},
+		{
+			name:      "fail - wait exceeds max wait",
+			config:    QueueConfig{Concurrency: 1, Size: 1, MaxWait: time.Millisecond},
+			wantErr:   ErrQueueFull,
+			wantStats: QueueStats{Running: 1, Rejected: 1},
+		},
+		{
+			name:         "success - shed to the ruleset fallback",
+			config:       QueueConfig{Concurrency: 1, OnFull: QueueFullShed},
+			fallback:     FallbackAllow,
+			wantPassed:   true,
+			wantDegraded: true,
+			wantStats:    QueueStats{Running: 1, Shed: 1},
+		},
+		{
+			name:         "success - shed without fallback denied",
+			config:       QueueConfig{Concurrency: 1, OnFull: QueueFullShed},
+			wantPassed:   false,
+			wantDegraded: true,
+			wantStats:    QueueStats{Running: 1, Shed: 1},
+		},
+		{
+			name:         "fail - zero concurrency",
+			config:       QueueConfig{},
+			wantBuildErr: true,
+		},
+		{
+			name:         "fail - unknown full behaviour",
+			config:       QueueConfig{Concurrency: 1, OnFull: "drop"},
+			wantBuildErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := rolloutConfig("user.age >= 18")
+			config.Rulesets["adult"] = Ruleset{Selector: "AND", Rules: []string{"age_validation"}, Fallback: tt.fallback}
+			re, err := NewBuilder().
+				WithConfig(config).
+				WithVariables("user").
+				WithOptions(WithEvaluationQueue(tt.config)).
+				Build()
+			if (err != nil) != tt.wantBuildErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantBuildErr)
+			}
+			if err != nil {
+				return
+			}
+			// Hold the only running slot
+			if !re.queue.acquire() {
+				t.Fatal("acquire() = false for an empty queue")
+			}
+			ctx := WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}})
+			result, err := re.EvaluateRuleset("adult", ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EvaluateRuleset() error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Passed != tt.wantPassed || result.Degraded != tt.wantDegraded {
+				t.Errorf("EvaluateRuleset() passed = %v, degraded = %v, want %v and %v",
+					result.Passed, result.Degraded, tt.wantPassed, tt.wantDegraded)
+			}
+			if tt.wantDegraded && !tt.wantPassed && !errors.Is(result.Error, ErrQueueFull) {
+				t.Errorf("EvaluateRuleset() result error = %v, want %v", result.Error, ErrQueueFull)
+			}
+			if diff := cmp.Diff(tt.wantStats, re.QueueStats()); diff != "" {
+				t.Errorf("QueueStats() mismatch (-want +got):\n%s", diff)
+			}
+			re.queue.release()
+			if _, err := re.EvaluateRule("age_validation", ctx); err != nil {
+				t.Errorf("EvaluateRule() error = %v after the slot was released", err)
+			}
+		})
+	}
+}
+
+func TestWithEvaluationQueue_EntryPoints(t *testing.T) {
+	config := rolloutConfig("user.age >= 18")
+	config.Rulesets["adult"] = Ruleset{Selector: "AND", Rules: []string{"age_validation"}}
+	re, err := NewBuilder().
+		WithConfig(config).
+		WithVariables("user").
+		WithOptions(WithEvaluationQueue(QueueConfig{Concurrency: 1})).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	ctx := map[string]interface{}{"user": map[string]interface{}{"age": 19}}
+
+	tests := []struct {
+		name     string
+		evaluate func() error
+	}{
+		{
+			name: "fail - EvaluateAs",
+			evaluate: func() error {
+				_, err := EvaluateAs[bool](re, "age_validation", WithEvalContext(ctx))
+				return err
+			},
+		},
+		{
+			name: "fail - EvaluateExpression",
+			evaluate: func() error {
+				_, err := re.EvaluateExpression("user.age >= 21", ctx)
+				return err
+			},
+		},
+		{
+			name: "fail - WhatIf",
+			evaluate: func() error {
+				_, err := re.WhatIf("adult", ctx, map[string]interface{}{"user.age": 17})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Hold the only running slot
+			if !re.queue.acquire() {
+				t.Fatal("acquire() = false for an empty queue")
+			}
+			defer re.queue.release()
+			if err := tt.evaluate(); !errors.Is(err, ErrQueueFull) {
+				t.Errorf("error = %v, want %v", err, ErrQueueFull)
+			}
+		})
+	}
+	if got := re.QueueStats().Rejected; got != uint64(len(tests)) {
+		t.Errorf("QueueStats().Rejected = %d, want %d", got, len(tests))
+	}
+}
+
+func TestWithEvaluationQueue_Wait(t *testing.T) {
+	re, err := NewBuilder().
+		WithConfig(rolloutConfig("user.age >= 18")).
+		WithVariables("user").
+		WithOptions(WithEvaluationQueue(QueueConfig{Concurrency: 1, Size: 1})).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	re.queue.acquire()
+	done := make(chan error)
+	go func() {
+		_, err := re.EvaluateRule("age_validation", WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}}))
+		done <- err
+	}()
+	for re.QueueStats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := re.EvaluateRuleset("adult"); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("EvaluateRuleset() error = %v with a full queue, want %v", err, ErrQueueFull)
+	}
+	re.queue.release()
+	if err := <-done; err != nil {
+		t.Errorf("EvaluateRule() error = %v for a queued evaluation", err)
+	}
+	if diff := cmp.Diff(QueueStats{Rejected: 1}, re.QueueStats()); diff != "" {
+		t.Errorf("QueueStats() mismatch (-want +got):\n%s", diff)
+	}
+}
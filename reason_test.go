@@ -0,0 +1,69 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_ReasonCodes(t *testing.T) {
+	tests := []struct {
+		name            string
+		ruleset         string
+		user            map[string]interface{}
+		wantRuleCodes   map[string]string
+		wantReasonCodes []string
+	}{
+		{
+			name:    "success - passed, no reason codes",
+			ruleset: "kyc",
+			user:    map[string]interface{}{"age": 21, "verified": true, "sanctioned": false, "status": "active"},
+			wantRuleCodes: map[string]string{
+				"age_validation": "", "identity_verified": "", "sanctions_check": "", "user_status": "",
+			},
+		},
+		{
+			name:    "fail - codes aggregated, sorted and de-duplicated",
+			ruleset: "kyc",
+			user:    map[string]interface{}{"age": 16, "verified": false, "sanctioned": true, "status": "banned"},
+			wantRuleCodes: map[string]string{
+				"age_validation": "KYC_001", "identity_verified": "KYC_002", "sanctions_check": "KYC_002", "user_status": "",
+			},
+			wantReasonCodes: []string{"KYC_001", "KYC_002"},
+		},
+		{
+			name:    "success - OR passed, failed rule keeps its code",
+			ruleset: "any_kyc",
+			user:    map[string]interface{}{"age": 16, "verified": true},
+			wantRuleCodes: map[string]string{
+				"age_validation": "KYC_001", "identity_verified": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/reason_rules.yml").
+				WithVariables("user").
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+
+			got, err := re.EvaluateRuleset(tt.ruleset, WithEvalContext(map[string]interface{}{"user": tt.user}))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			gotRuleCodes := make(map[string]string, len(got.RuleResults))
+			for name, result := range got.RuleResults {
+				gotRuleCodes[name] = result.ReasonCode
+			}
+			if diff := cmp.Diff(gotRuleCodes, tt.wantRuleCodes); diff != "" {
+				t.Errorf("EvaluateRuleset() rule reason codes (-got +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(got.ReasonCodes, tt.wantReasonCodes); diff != "" {
+				t.Errorf("EvaluateRuleset() reason codes (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ValidateReferences checks that every name the config references resolves: custom_error_messages keys to a rule
+// or ruleset, the execution policy to one of execution_policies, ruleset rules, vetoes and optional rules and rule
+// parents to rules, http_problems reason codes and severities to those of a rule, and the overrides of every
+// environment as ValidateEnvironments
+//
+//	Unresolved names are otherwise silently ignored, e.g. a misspelt custom error message is never reported. Call it
+//	before ApplyEnvironment, which removes disabled rules. Errors join one error per unresolved name
+func (rc *RulesetConfig) ValidateReferences() error {
+	var errs []error
+	if policy := rc.ErrorHandling.ExecutionPolicy; policy != "" {
+		if _, ok := rc.ExecutionPolicies[policy]; !ok {
+			errs = append(errs, fmt.Errorf("error_handling: execution policy '%s' not found", policy))
+		}
+	}
+	for _, name := range sortedNames(rc.ErrorHandling.CustomErrorMessages) {
+		_, rule := rc.Rules[name]
+		_, ruleset := rc.Rulesets[name]
+		if !rule && !ruleset {
+			errs = append(errs, fmt.Errorf("error_handling: custom error message for unknown rule or ruleset '%s'", name))
+		}
+	}
+	if err := rc.validateProblemReferences(); err != nil {
+		errs = append(errs, fmt.Errorf("error_handling: %w", err))
+	}
+	for _, name := range sortedNames(rc.Rules) {
+		if parent := rc.Rules[name].Extends; parent != "" {
+			if _, ok := rc.Rules[parent]; !ok {
+				errs = append(errs, fmt.Errorf("rule '%s': extended rule '%s' not found", name, parent))
+			}
+		}
+	}
+	for _, name := range sortedNames(rc.Rulesets) {
+		if err := rc.validateRulesetReferences(rc.Rulesets[name]); err != nil {
+			errs = append(errs, fmt.Errorf("ruleset '%s': %w", name, err))
+		}
+	}
+	for _, name := range sortedNames(rc.Environments) {
+		if err := rc.validateEnvironment(rc.Environments[name]); err != nil {
+			errs = append(errs, fmt.Errorf("environment '%s': %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateRulesetReferences checks the rules, vetoes and optional rules of a ruleset are rules of the config
+func (rc *RulesetConfig) validateRulesetReferences(ruleset Ruleset) error {
+	var errs []error
+	for _, name := range ruleset.Rules {
+		if _, ok := rc.Rules[name]; !ok {
+			errs = append(errs, fmt.Errorf("rule '%s' not found", name))
+		}
+	}
+	for _, name := range ruleset.Vetoes {
+		if !slices.Contains(ruleset.Rules, name) {
+			errs = append(errs, fmt.Errorf("veto rule '%s' is not a rule of the ruleset", name))
+		}
+	}
+	for _, name := range ruleset.Optional {
+		if !slices.Contains(ruleset.Rules, name) {
+			errs = append(errs, fmt.Errorf("optional rule '%s' is not a rule of the ruleset", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateProblemReferences checks the http_problems reason codes and severities are those of a rule
+func (rc *RulesetConfig) validateProblemReferences() error {
+	problems := rc.ErrorHandling.HTTPProblems
+	if problems == nil {
+		return nil
+	}
+	reasonCodes := make(map[string]bool)
+	severities := make(map[string]bool)
+	for _, rule := range rc.Rules {
+		reasonCodes[rule.ReasonCode] = true
+		severities[rule.Severity] = true
+	}
+	var errs []error
+	for _, code := range sortedNames(problems.ReasonCodes) {
+		if !reasonCodes[code] {
+			errs = append(errs, fmt.Errorf("http problem for unknown reason code '%s'", code))
+		}
+	}
+	for _, severity := range sortedNames(problems.Severities) {
+		if !severities[severity] {
+			errs = append(errs, fmt.Errorf("http problem for unknown severity '%s'", severity))
+		}
+	}
+	return errors.Join(errs...)
+}
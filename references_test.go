@@ -0,0 +1,105 @@
+package ruleengine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestRulesetConfig_ValidateReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(c *RulesetConfig)
+		wantMsgs []string
+	}{
+		{
+			name: "success - every reference resolves",
+			modify: func(c *RulesetConfig) {
+				c.ErrorHandling.CustomErrorMessages = map[string]string{"age_validation": "too young", "adult": "not an adult"}
+				c.Rules["age_validation"] = Rule{Expression: "user.age >= 18", ReasonCode: "AGE_001", Severity: "critical"}
+				c.ErrorHandling.HTTPProblems = &HTTPProblems{
+					ReasonCodes: map[string]ProblemMapping{"AGE_001": {Status: 403}},
+					Severities:  map[string]ProblemMapping{"critical": {Status: 403}},
+				}
+				c.Rulesets["adult"] = Ruleset{
+					Selector: selectorAnd,
+					Rules:    []string{"age_validation", "business_hours"},
+					Vetoes:   []string{"age_validation"},
+					Optional: []string{"business_hours"},
+				}
+			},
+		},
+		{
+			name: "fail - unresolved names",
+			modify: func(c *RulesetConfig) {
+				c.ErrorHandling.ExecutionPolicy = "collect_al"
+				c.ErrorHandling.CustomErrorMessages = map[string]string{"age_validaton": "too young"}
+				c.ErrorHandling.HTTPProblems = &HTTPProblems{
+					ReasonCodes: map[string]ProblemMapping{"AGE_01": {Status: 403}},
+					Severities:  map[string]ProblemMapping{"critcal": {Status: 403}},
+				}
+				c.Rules["business_hours"] = Rule{Expression: "true", Extends: "age_check"}
+				c.Rulesets["adult"] = Ruleset{
+					Selector: selectorAnd,
+					Rules:    []string{"age_validation", "email"},
+					Vetoes:   []string{"business_hours"},
+					Optional: []string{"phone"},
+				}
+				c.Environments["development"] = Environment{EnabledRulesets: []string{"adults"}}
+			},
+			wantMsgs: []string{
+				"error_handling: execution policy 'collect_al' not found",
+				"error_handling: custom error message for unknown rule or ruleset 'age_validaton'",
+				"error_handling: http problem for unknown reason code 'AGE_01'",
+				"http problem for unknown severity 'critcal'",
+				"rule 'business_hours': extended rule 'age_check' not found",
+				"ruleset 'adult': rule 'email' not found",
+				"veto rule 'business_hours' is not a rule of the ruleset",
+				"optional rule 'phone' is not a rule of the ruleset",
+				"environment 'development': enabled ruleset 'adults' not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := strictConfig(Environment{})
+			tt.modify(config)
+			err := config.ValidateReferences()
+			if (err != nil) != (len(tt.wantMsgs) > 0) {
+				t.Fatalf("ValidateReferences() error = %v, want %v", err, tt.wantMsgs)
+			}
+			for _, msg := range tt.wantMsgs {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("ValidateReferences() error = %v, want it to contain %q", err, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRuleEngine_StrictReferences(t *testing.T) {
+	env, err := cel.NewEnv(cel.Variable("user", cel.DynType), cel.Variable("globals", cel.DynType))
+	if err != nil {
+		t.Fatalf("failed to create cel env: %v", err)
+	}
+	tests := []struct {
+		name    string
+		strict  bool
+		wantErr bool
+	}{
+		{name: "success - misspelt message without strict_references"},
+		{name: "fail - misspelt message", strict: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := strictConfig(Environment{})
+			config.StrictReferences = tt.strict
+			config.ErrorHandling.CustomErrorMessages = map[string]string{"age_validaton": "too young"}
+			_, err := newRuleEngine(config, "", env)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newRuleEngine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
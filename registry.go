@@ -0,0 +1,259 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// ErrTenantNotFound is returned when evaluating for a tenant not registered in the Registry
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// ErrQuotaExceeded is returned when a tenant evaluates faster than its TenantQuota allows
+var ErrQuotaExceeded = errors.New("tenant evaluation quota exceeded")
+
+// ErrRuleLimitExceeded is returned when a single evaluation would run more rules than the TenantQuota allows
+var ErrRuleLimitExceeded = errors.New("tenant rule limit exceeded")
+
+// TenantQuota limits the evaluations of a tenant in a Registry, zero values are unlimited
+type TenantQuota struct {
+	// QPS is the sustained number of evaluations per second allowed
+	QPS float64
+	// Burst is the number of evaluations allowed at once before QPS applies, defaults to QPS rounded up
+	Burst int
+	// MaxRules is the maximum number of rules, including the rules they extend, a single evaluation may run
+	MaxRules int
+}
+
+// TenantStats reports the evaluations of a tenant in a Registry
+type TenantStats struct {
+	// Evaluations is the number of evaluations allowed
+	Evaluations uint64
+	// Throttled is the number of evaluations rejected with ErrQuotaExceeded
+	Throttled uint64
+	// RuleLimited is the number of evaluations rejected with ErrRuleLimitExceeded
+	RuleLimited uint64
+}
+
+// Registry serves the engines of several tenants, enforcing a quota per tenant
+//
+//	Quotas keep one noisy tenant from starving the others sharing a process: evaluations above the tenant's QPS fail
+//	fast with ErrQuotaExceeded instead of queueing, and evaluations running more rules than MaxRules fail with
+//	ErrRuleLimitExceeded before any rule is evaluated. A Registry is safe for concurrent use
+type Registry struct {
+	mu      sync.RWMutex
+	tenants map[string]*tenant
+	// functions are the function declarations tenant bundles may allow keyed by name, see DeclareFunction
+	functions map[string]cel.EnvOption
+	// meter receives the usage of every evaluation, nil if not metered, see SetMeter
+	meter MeterFunc
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// tenant is the engine and quota state of a registered tenant
+type tenant struct {
+	engine *RuleEngine
+	quota  TenantQuota
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	stats  TenantStats
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		tenants:   make(map[string]*tenant),
+		functions: make(map[string]cel.EnvOption),
+		now:       time.Now,
+	}
+}
+
+// Register adds or replaces the engine and quota of a tenant, replacing a tenant resets its quota and stats
+func (r *Registry) Register(name string, engine *RuleEngine, quota TenantQuota) error {
+	if name == "" {
+		return errors.New("tenant name must not be empty")
+	}
+	if engine == nil {
+		return fmt.Errorf("tenant '%s' has no engine", name)
+	}
+	if quota.QPS < 0 || quota.Burst < 0 || quota.MaxRules < 0 {
+		return fmt.Errorf("tenant '%s' quota must not be negative", name)
+	}
+	if quota.QPS > 0 && quota.Burst == 0 {
+		quota.Burst = int(quota.QPS)
+		if float64(quota.Burst) < quota.QPS {
+			quota.Burst++
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants[name] = &tenant{
+		engine: engine,
+		quota:  quota,
+		tokens: float64(quota.Burst),
+		last:   r.now(),
+	}
+	return nil
+}
+
+// Remove removes a tenant, a no-op if it is not registered
+func (r *Registry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tenants, name)
+}
+
+// Tenants returns the names of the registered tenants in sorted order
+func (r *Registry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.tenants))
+	for name := range r.tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Engine returns the engine of a tenant, false if it is not registered
+func (r *Registry) Engine(name string) (*RuleEngine, bool) {
+	t, err := r.tenant(name)
+	if err != nil {
+		return nil, false
+	}
+	return t.engine, true
+}
+
+// Stats returns the evaluation counts of a tenant, false if it is not registered
+func (r *Registry) Stats(name string) (TenantStats, bool) {
+	t, err := r.tenant(name)
+	if err != nil {
+		return TenantStats{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats, true
+}
+
+// EvaluateRule evaluates a rule of the tenant's engine within its quota, see RuleEngine.EvaluateRule
+func (r *Registry) EvaluateRule(name, ruleName string, opts ...EvalOption) (RuleResult, error) {
+	t, err := r.tenant(name)
+	if err != nil {
+		return RuleResult{}, err
+	}
+	engine := t.engine.Active()
+	if _, ok := engine.config.Rules[ruleName]; !ok {
+		return RuleResult{}, fmt.Errorf("rule '%s' not found", ruleName)
+	}
+	if err := r.admit(name, t, engine.ruleCount([]string{ruleName})); err != nil {
+		return RuleResult{}, err
+	}
+	result, err := t.engine.EvaluateRule(ruleName, opts...)
+	if err != nil {
+		r.metered(name, engine)
+		return result, err
+	}
+	r.metered(name, engine, RulesetResult{RuleResults: map[string]RuleResult{ruleName: result}})
+	return result, nil
+}
+
+// EvaluateRuleset evaluates a ruleset of the tenant's engine within its quota, see RuleEngine.EvaluateRuleset
+func (r *Registry) EvaluateRuleset(name, rulesetName string, opts ...EvalOption) (RulesetResult, error) {
+	t, err := r.tenant(name)
+	if err != nil {
+		return RulesetResult{}, err
+	}
+	engine := t.engine.Active()
+	ruleset, ok := engine.config.Rulesets[rulesetName]
+	if !ok {
+		return RulesetResult{}, fmt.Errorf("ruleset '%s' not found", rulesetName)
+	}
+	if err := r.admit(name, t, engine.ruleCount(ruleset.Rules)); err != nil {
+		return RulesetResult{}, err
+	}
+	result, err := t.engine.EvaluateRuleset(rulesetName, opts...)
+	if err != nil {
+		r.metered(name, engine)
+		return result, err
+	}
+	r.metered(name, engine, result)
+	return result, nil
+}
+
+// EvaluateAllRulesets evaluates every ruleset of the tenant's engine within its quota, counting as one evaluation
+//
+//	See RuleEngine.EvaluateAllRulesets
+func (r *Registry) EvaluateAllRulesets(name string, opts ...EvalOption) (map[string]RulesetResult, error) {
+	t, err := r.tenant(name)
+	if err != nil {
+		return nil, err
+	}
+	engine := t.engine.Active()
+	rules := make([]string, 0)
+	for _, ruleset := range engine.config.Rulesets {
+		rules = append(rules, ruleset.Rules...)
+	}
+	if err := r.admit(name, t, engine.ruleCount(rules)); err != nil {
+		return nil, err
+	}
+	results, err := t.engine.EvaluateAllRulesets(opts...)
+	r.metered(name, engine, slices.Collect(maps.Values(results))...)
+	return results, err
+}
+
+// tenant returns the registered tenant name
+func (r *Registry) tenant(name string) (*tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tenants[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrTenantNotFound, name)
+	}
+	return t, nil
+}
+
+// admit checks an evaluation running rules rules against the tenant's quota, taking a token when allowed
+func (r *Registry) admit(name string, t *tenant, rules int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.quota.MaxRules > 0 && rules > t.quota.MaxRules {
+		t.stats.RuleLimited++
+		return fmt.Errorf("%w: tenant '%s' evaluation runs %d rules, limit %d", ErrRuleLimitExceeded, name, rules, t.quota.MaxRules)
+	}
+	if t.quota.QPS > 0 {
+		now := r.now()
+		t.tokens += now.Sub(t.last).Seconds() * t.quota.QPS
+		if burst := float64(t.quota.Burst); t.tokens > burst {
+			t.tokens = burst
+		}
+		t.last = now
+		if t.tokens < 1 {
+			t.stats.Throttled++
+			return fmt.Errorf("%w: tenant '%s' is limited to %g evaluations per second", ErrQuotaExceeded, name, t.quota.QPS)
+		}
+		t.tokens--
+	}
+	t.stats.Evaluations++
+	return nil
+}
+
+// ruleCount returns the number of distinct rules, including the rules they extend, evaluating rules runs at most
+func (re *RuleEngine) ruleCount(rules []string) int {
+	seen := make(map[string]bool, len(rules))
+	for _, name := range rules {
+		seen[name] = true
+		for _, parent := range re.parents[name] {
+			seen[parent] = true
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,198 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistry(t *testing.T) {
+	newEngine := func(t *testing.T) *RuleEngine {
+		re, err := NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request").Build()
+		if err != nil {
+			t.Fatalf("failed to create rules engine: %v", err)
+		}
+		return re
+	}
+	ctx := WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"age": 20, "email": "a@example.com"},
+	})
+
+	type step struct {
+		tenant  string
+		ruleset string
+		rule    string
+		advance time.Duration
+		wantErr error
+	}
+	tests := []struct {
+		name      string
+		quotas    map[string]TenantQuota
+		steps     []step
+		wantStats map[string]TenantStats
+	}{
+		{
+			name:   "success - unlimited",
+			quotas: map[string]TenantQuota{"acme": {}},
+			steps: []step{
+				{tenant: "acme", ruleset: "user_registration"},
+				{tenant: "acme", ruleset: "user_registration"},
+				{tenant: "acme", rule: "age_validation"},
+				{tenant: "acme"},
+			},
+			wantStats: map[string]TenantStats{"acme": {Evaluations: 4}},
+		},
+		{
+			name:   "fail - qps throttles one tenant only",
+			quotas: map[string]TenantQuota{"noisy": {QPS: 2}, "quiet": {QPS: 2}},
+			steps: []step{
+				{tenant: "noisy", ruleset: "user_registration"},
+				{tenant: "noisy", ruleset: "user_registration"},
+				{tenant: "noisy", ruleset: "user_registration", wantErr: ErrQuotaExceeded},
+				{tenant: "quiet", ruleset: "user_registration"},
+				{tenant: "noisy", rule: "age_validation", advance: 500 * time.Millisecond},
+				{tenant: "noisy", rule: "age_validation", wantErr: ErrQuotaExceeded},
+			},
+			wantStats: map[string]TenantStats{
+				"noisy": {Evaluations: 3, Throttled: 2},
+				"quiet": {Evaluations: 1},
+			},
+		},
+		{
+			name:   "success - burst refills up to its size",
+			quotas: map[string]TenantQuota{"acme": {QPS: 1, Burst: 2}},
+			steps: []step{
+				{tenant: "acme", rule: "age_validation"},
+				{tenant: "acme", rule: "age_validation"},
+				{tenant: "acme", rule: "age_validation", wantErr: ErrQuotaExceeded},
+				{tenant: "acme", rule: "age_validation", advance: time.Hour},
+				{tenant: "acme", rule: "age_validation"},
+				{tenant: "acme", rule: "age_validation", wantErr: ErrQuotaExceeded},
+			},
+			wantStats: map[string]TenantStats{"acme": {Evaluations: 4, Throttled: 2}},
+		},
+		{
+			name:   "fail - rule limit",
+			quotas: map[string]TenantQuota{"acme": {MaxRules: 1}},
+			steps: []step{
+				{tenant: "acme", rule: "age_validation"},
+				{tenant: "acme", rule: "test_user", wantErr: ErrRuleLimitExceeded},
+				{tenant: "acme", ruleset: "user_registration", wantErr: ErrRuleLimitExceeded},
+				{tenant: "acme", wantErr: ErrRuleLimitExceeded},
+			},
+			wantStats: map[string]TenantStats{"acme": {Evaluations: 1, RuleLimited: 3}},
+		},
+		{
+			name:   "fail - unknown tenant",
+			quotas: map[string]TenantQuota{"acme": {}},
+			steps: []step{
+				{tenant: "other", ruleset: "user_registration", wantErr: ErrTenantNotFound},
+				{tenant: "other", rule: "age_validation", wantErr: ErrTenantNotFound},
+			},
+			wantStats: map[string]TenantStats{"acme": {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			registry := NewRegistry()
+			registry.now = func() time.Time { return now }
+			for name, quota := range tt.quotas {
+				if err := registry.Register(name, newEngine(t), quota); err != nil {
+					t.Fatalf("Register(%s) error = %v", name, err)
+				}
+			}
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				var err error
+				switch {
+				case s.rule != "":
+					_, err = registry.EvaluateRule(s.tenant, s.rule, ctx)
+				case s.ruleset != "":
+					_, err = registry.EvaluateRuleset(s.tenant, s.ruleset, ctx)
+				default:
+					_, err = registry.EvaluateAllRulesets(s.tenant, ctx)
+				}
+				if !errors.Is(err, s.wantErr) {
+					t.Errorf("step %d: error = %v, want %v", i, err, s.wantErr)
+				}
+			}
+			gotStats := make(map[string]TenantStats)
+			for _, name := range registry.Tenants() {
+				gotStats[name], _ = registry.Stats(name)
+			}
+			if diff := cmp.Diff(tt.wantStats, gotStats); diff != "" {
+				t.Errorf("Stats() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	tests := []struct {
+		name    string
+		tenant  string
+		engine  *RuleEngine
+		quota   TenantQuota
+		wantErr bool
+	}{
+		{name: "success - quota", tenant: "acme", engine: re, quota: TenantQuota{QPS: 10, MaxRules: 5}},
+		{name: "fail - empty name", tenant: "", engine: re, wantErr: true},
+		{name: "fail - no engine", tenant: "acme", wantErr: true},
+		{name: "fail - negative quota", tenant: "acme", engine: re, quota: TenantQuota{QPS: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			err := registry.Register(tt.tenant, tt.engine, tt.quota)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, ok := registry.Engine(tt.tenant); ok == tt.wantErr {
+				t.Errorf("Engine() registered = %v, want %v", ok, !tt.wantErr)
+			}
+			registry.Remove(tt.tenant)
+			if len(registry.Tenants()) != 0 {
+				t.Errorf("Tenants() after Remove = %v, want none", registry.Tenants())
+			}
+		})
+	}
+}
+
+func TestRegistry_Reload(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	registry := NewRegistry()
+	if err := registry.Register("acme", re, TenantQuota{MaxRules: 3}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	usage := make([]Usage, 0)
+	registry.SetMeter(func(u Usage) { usage = append(usage, u) })
+
+	config, err := NewRulesetConfig("./testdata/rules.yml")
+	if err != nil {
+		t.Fatalf("NewRulesetConfig() error = %v", err)
+	}
+	config.Rulesets["adult2"] = Ruleset{Name: "adult2", Selector: selectorAnd, Rules: []string{"test_user"}}
+	if err := re.Reload(config); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	ctx := WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"age": 20, "email": "test@example.com"},
+	})
+	if _, err := registry.EvaluateRuleset("acme", "adult2", ctx); err != nil {
+		t.Fatalf("EvaluateRuleset() after Reload error = %v", err)
+	}
+	if diff := cmp.Diff([]Usage{{Tenant: "acme", Decisions: 1, CostUnits: 3}}, usage); diff != "" {
+		t.Errorf("metered usage mismatch (-want +got):\n%s", diff)
+	}
+}
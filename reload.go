@@ -0,0 +1,113 @@
+package ruleengine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigReloaded is emitted when a new config becomes active with Reload, Promote or Drafts.Commit, so behaviour
+// shifts can be correlated with reloads
+type ConfigReloaded struct {
+	// Action is how the config was activated, "reload", "promote" or "commit"
+	Action string
+	// PreviousVersion and Version are the ConfigVersion of the replaced and the new config
+	PreviousVersion string
+	Version         string
+	// RulesAdded, RulesRemoved and RulesChanged count the rules only in the new config, only in the replaced config
+	// and in both with a different definition
+	RulesAdded   int
+	RulesRemoved int
+	RulesChanged int
+	// CompileDuration is the time taken to build the new engine, compiling its expressions
+	CompileDuration time.Duration
+}
+
+// EventName implements Event
+func (ConfigReloaded) EventName() string {
+	return "config_reloaded"
+}
+
+// ConfigVersion returns a hash identifying the effective config of the engine, environment overrides applied
+//
+//	Engines built from identical configs have the same version, whatever their options
+func (re *RuleEngine) ConfigVersion() string {
+	if active := re.Active(); active != re {
+		return active.ConfigVersion()
+	}
+	return re.version
+}
+
+// configVersion hashes the YAML form of the config, its maps are written with sorted keys
+func configVersion(config *RulesetConfig) (string, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Reload builds an engine from the config, with the environment, CEL env and options of this engine, and atomically
+// makes it the active config, evaluations started afterwards use it
+//
+//	It is Stage and Promote without the shadow evaluations, any staged candidate stays staged. The context set with
+//	SetContext carries over and a ConfigReloaded event is emitted. The config is modified in place. Errors are
+//	returned if the engine fails to build or the WithChangeGate gate rejects it, the active config then keeps serving
+func (re *RuleEngine) Reload(config *RulesetConfig) error {
+	engine, err := re.candidate(config)
+	if err != nil {
+		return fmt.Errorf("failed to reload config: %w", err)
+	}
+	if err := re.approve(ChangeRequest{Action: "reload", Config: engine.config}); err != nil {
+		return err
+	}
+	re.reloaded("reload", re.swap(engine), engine)
+	return nil
+}
+
+// swap atomically makes the engine the active one, with the SetContext context of the engine it replaces, leaving
+// any staged candidate in shadow, and returns the engine it replaces
+func (re *RuleEngine) swap(engine *RuleEngine) *RuleEngine {
+	re.rollout.mu.Lock()
+	defer re.rollout.mu.Unlock()
+	previous := re
+	if re.rollout.promoted != nil {
+		previous = re.rollout.promoted
+	}
+	engine.inheritContext(previous)
+	re.rollout.promoted = engine
+	return previous
+}
+
+// reloaded emits the ConfigReloaded event of the engine replacing the previous one
+func (re *RuleEngine) reloaded(action string, previous, engine *RuleEngine) {
+	if re.eventHandler == nil {
+		return
+	}
+	event := ConfigReloaded{
+		Action:          action,
+		PreviousVersion: previous.version,
+		Version:         engine.version,
+		CompileDuration: engine.compileDuration,
+	}
+	for name, rule := range engine.config.Rules {
+		old, ok := previous.config.Rules[name]
+		switch {
+		case !ok:
+			event.RulesAdded++
+		case !reflect.DeepEqual(old, rule):
+			event.RulesChanged++
+		}
+	}
+	for name := range previous.config.Rules {
+		if _, ok := engine.config.Rules[name]; !ok {
+			event.RulesRemoved++
+		}
+	}
+	re.emit(event)
+}
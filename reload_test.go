@@ -0,0 +1,158 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRuleEngine_Reload(t *testing.T) {
+	errRejected := errors.New("frozen")
+	changed := rolloutConfig("user.age >= 21")
+	changed.Rules["verified"] = Rule{Expression: "user.verified"}
+	tests := []struct {
+		name       string
+		config     *RulesetConfig
+		gate       ChangeGateFunc
+		wantPassed bool
+		wantEvent  *ConfigReloaded
+		wantErr    bool
+	}{
+		{
+			name:      "success - changed and added rules",
+			config:    changed,
+			wantEvent: &ConfigReloaded{Action: "reload", RulesAdded: 1, RulesChanged: 1},
+		},
+		{
+			name:       "success - same config",
+			config:     rolloutConfig("user.age >= 18"),
+			wantPassed: true,
+			wantEvent:  &ConfigReloaded{Action: "reload"},
+		},
+		{
+			name:       "fail - invalid config",
+			config:     rolloutConfig("user.age >="),
+			wantPassed: true,
+			wantErr:    true,
+		},
+		{
+			name:       "fail - rejected by the change gate",
+			config:     rolloutConfig("user.age >= 21"),
+			gate:       func(ChangeRequest) error { return errRejected },
+			wantPassed: true,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []ConfigReloaded
+			opts := []Option{WithEventHandler(func(e Event) {
+				if reloaded, ok := e.(ConfigReloaded); ok {
+					events = append(events, reloaded)
+				}
+			})}
+			if tt.gate != nil {
+				opts = append(opts, WithChangeGate(tt.gate))
+			}
+			re, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").WithOptions(opts...).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			previous := re.ConfigVersion()
+			if err := re.Reload(tt.config); (err != nil) != tt.wantErr {
+				t.Fatalf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			result, err := re.EvaluateRuleset("adult", WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}}))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+
+			var want []ConfigReloaded
+			if tt.wantEvent != nil {
+				event := *tt.wantEvent
+				event.PreviousVersion, event.Version = previous, re.Active().ConfigVersion()
+				want = append(want, event)
+			}
+			if diff := cmp.Diff(want, events, cmpopts.IgnoreFields(ConfigReloaded{}, "CompileDuration")); diff != "" {
+				t.Errorf("Reload() events (-want +got):\n%s", diff)
+			}
+			if tt.wantEvent != nil && (events[0].CompileDuration <= 0 || (events[0].RulesChanged == 0) != (events[0].Version == previous)) {
+				t.Errorf("Reload() event = %+v", events[0])
+			}
+		})
+	}
+}
+
+func TestRuleEngine_ReloadEvents(t *testing.T) {
+	var actions []string
+	re, err := NewBuilder().
+		WithConfig(rolloutConfig("user.age >= 18")).
+		WithVariables("user").
+		WithOptions(WithEventHandler(func(e Event) {
+			if reloaded, ok := e.(ConfigReloaded); ok {
+				actions = append(actions, reloaded.Action)
+			}
+		})).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if err := re.Stage(rolloutConfig("user.age >= 21")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := re.Promote(); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+	drafts := NewDrafts(re)
+	if err := drafts.PutRule("ada", "verified", Rule{Expression: "user.verified"}); err != nil {
+		t.Fatalf("PutRule() error = %v", err)
+	}
+	if _, err := drafts.Commit("ada", "add verified"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"promote", "commit"}, actions); diff != "" {
+		t.Errorf("ConfigReloaded actions (-want +got):\n%s", diff)
+	}
+}
+
+func TestRuleEngine_ReloadContext(t *testing.T) {
+	re, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	re.SetContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}})
+	if err := re.Reload(rolloutConfig("user.age >= 21")); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	result, err := re.EvaluateRuleset("adult")
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if result.Passed {
+		t.Error("EvaluateRuleset() passed = true, want the reloaded result against the carried over context")
+	}
+
+	re.SetContext(map[string]interface{}{"user": map[string]interface{}{"age": 26}})
+	if err := re.Reload(rolloutConfig("user.age >= 25")); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	result, err = re.EvaluateRuleset("adult")
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if !result.Passed {
+		t.Error("EvaluateRuleset() passed = false, want the second reload against the latest context")
+	}
+	rule, err := re.EvaluateRule("age_validation")
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	if !rule.Passed {
+		t.Error("EvaluateRule() passed = false, want the second reload against the latest context")
+	}
+}
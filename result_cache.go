@@ -0,0 +1,275 @@
+package ruleengine
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+)
+
+// nonDeterministicFunctions are the built-in functions whose result varies between calls with the same arguments
+var nonDeterministicFunctions = map[string]bool{
+	"now":      true,
+	"http_get": true,
+	"counter":  true,
+}
+
+// ResultCacheStats reports the activity of the rule result cache, see WithResultCache
+type ResultCacheStats struct {
+	// Entries is the number of cached rule outcomes
+	Entries int
+	// Hits is the number of rule evaluations answered from the cache
+	Hits uint64
+	// Misses is the number of cacheable rule evaluations which had to be evaluated
+	Misses uint64
+}
+
+// WithResultCache caches up to size rule outcomes, evicting the least recently used
+//
+//	Outcomes are keyed by the values of the context paths and globals the rule expression reads, so contexts
+//	differing only in fields the rule ignores share the cached outcome. Evaluation errors are never cached. Rules
+//	calling now(), http_get() or counter(), or reading values other than maps, lists and scalars, are always evaluated. Custom
+//	functions are assumed to be deterministic
+func WithResultCache(size int) Option {
+	return func(re *RuleEngine) {
+		if size > 0 {
+			re.resultCache = newResultCache(size)
+		}
+	}
+}
+
+// ResultCacheStats returns the activity of the result cache, empty unless enabled with WithResultCache
+func (re *RuleEngine) ResultCacheStats() ResultCacheStats {
+	if active := re.Active(); active != re {
+		return active.ResultCacheStats()
+	}
+	if re.resultCache == nil {
+		return ResultCacheStats{}
+	}
+	return re.resultCache.stats()
+}
+
+// resultCacheKey identifies the outcome of a rule program for the values of the paths it reads
+type resultCacheKey struct {
+	rule string
+	sum  [sha256.Size]byte
+}
+
+// resultCacheEntry is a cached program outcome
+type resultCacheEntry struct {
+	key     resultCacheKey
+	outcome programOutcome
+}
+
+// resultCache is a least recently used cache of rule program outcomes
+type resultCache struct {
+	// paths is a map of cacheable rule names to the sorted paths their expression reads, written while loading
+	paths map[string][]string
+
+	mu       sync.Mutex
+	size     int
+	lru      *list.List
+	elements map[resultCacheKey]*list.Element
+	hits     uint64
+	misses   uint64
+}
+
+// newResultCache creates an empty resultCache holding up to size outcomes
+func newResultCache(size int) *resultCache {
+	return &resultCache{
+		paths:    make(map[string][]string),
+		size:     size,
+		lru:      list.New(),
+		elements: make(map[resultCacheKey]*list.Element),
+	}
+}
+
+// prepare records the paths a rule reads, rules calling non-deterministic functions are not cached
+func (c *resultCache) prepare(re *RuleEngine, name string, checked *cel.Ast) {
+	expr := checked.NativeRep().Expr()
+	calls := ast.MatchDescendants(ast.NavigateExpr(nil, expr), func(nav ast.NavigableExpr) bool {
+		return nav.Kind() == ast.CallKind && nonDeterministicFunctions[nav.AsCall().FunctionName()]
+	})
+	if len(calls) > 0 {
+		return
+	}
+	paths := re.referencedVariables(expr)
+	sort.Strings(paths)
+	c.paths[name] = paths
+}
+
+// key returns the cache key of a rule for the values ctx holds at the paths it reads, false if it is not cacheable
+// or the cache is nil
+func (c *resultCache) key(rule string, ctx map[string]interface{}) (resultCacheKey, bool) {
+	if c == nil {
+		return resultCacheKey{}, false
+	}
+	paths, ok := c.paths[rule]
+	if !ok {
+		return resultCacheKey{}, false
+	}
+	h := sha256.New()
+	for _, path := range paths {
+		h.Write([]byte(path))
+		value, found := lookupPath(ctx, path)
+		if !found {
+			h.Write([]byte{0})
+			continue
+		}
+		h.Write([]byte{1})
+		if !hashValue(h, value) {
+			return resultCacheKey{}, false
+		}
+	}
+	key := resultCacheKey{rule: rule}
+	h.Sum(key.sum[:0])
+	return key, true
+}
+
+// get returns the cached outcome for key
+func (c *resultCache) get(key resultCacheKey) (programOutcome, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.elements[key]
+	if !ok {
+		c.misses++
+		return programOutcome{}, false
+	}
+	c.hits++
+	c.lru.MoveToFront(el)
+	return el.Value.(*resultCacheEntry).outcome, true
+}
+
+// put caches a successful outcome for key, evicting the least recently used outcome when full
+func (c *resultCache) put(key resultCacheKey, outcome programOutcome) {
+	if outcome.err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.elements[key]; ok {
+		el.Value.(*resultCacheEntry).outcome = outcome
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.elements[key] = c.lru.PushFront(&resultCacheEntry{key: key, outcome: outcome})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.elements, oldest.Value.(*resultCacheEntry).key)
+	}
+}
+
+// stats returns the activity of the cache
+func (c *resultCache) stats() ResultCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResultCacheStats{Entries: c.lru.Len(), Hits: c.hits, Misses: c.misses}
+}
+
+// lookupPath returns the value at a dotted path of nested maps, false if a segment is missing
+func lookupPath(ctx map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = ctx
+	start := 0
+	for i := 0; i <= len(path); i++ {
+		if i < len(path) && path[i] != '.' {
+			continue
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			// A selection into anything but a map is hashed as the value selected from
+			return value, true
+		}
+		if value, ok = m[path[start:i]]; !ok {
+			return nil, false
+		}
+		start = i + 1
+	}
+	return value, true
+}
+
+// hashValue writes an unambiguous encoding of v to h, false if v has a type whose contents cannot be hashed
+func hashValue(h hash.Hash, v interface{}) bool {
+	var buf [9]byte
+	writeTagged := func(tag byte, bits uint64) {
+		buf[0] = tag
+		binary.BigEndian.PutUint64(buf[1:], bits)
+		h.Write(buf[:])
+	}
+	writeString := func(tag byte, s string) {
+		writeTagged(tag, uint64(len(s)))
+		h.Write([]byte(s))
+	}
+	switch v := v.(type) {
+	case nil:
+		h.Write([]byte{'n'})
+	case bool:
+		if v {
+			writeTagged('b', 1)
+		} else {
+			writeTagged('b', 0)
+		}
+	case string:
+		writeString('s', v)
+	case []byte:
+		writeString('y', string(v))
+	case int:
+		writeTagged('i', uint64(v))
+	case int32:
+		writeTagged('i', uint64(v))
+	case int64:
+		writeTagged('i', uint64(v))
+	case uint:
+		writeTagged('u', uint64(v))
+	case uint32:
+		writeTagged('u', uint64(v))
+	case uint64:
+		writeTagged('u', v)
+	case float32:
+		writeTagged('f', math.Float64bits(float64(v)))
+	case float64:
+		writeTagged('f', math.Float64bits(v))
+	case time.Time:
+		writeTagged('t', uint64(v.UnixNano()))
+	case time.Duration:
+		writeTagged('d', uint64(v))
+	case *indexedList:
+		// Indexed lists are only built from the config globals, which never change
+		h.Write([]byte{'x'})
+	case []interface{}:
+		writeTagged('l', uint64(len(v)))
+		for _, elem := range v {
+			if !hashValue(h, elem) {
+				return false
+			}
+		}
+	case []string:
+		writeTagged('l', uint64(len(v)))
+		for _, elem := range v {
+			writeString('s', elem)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		writeTagged('m', uint64(len(v)))
+		for _, key := range keys {
+			writeString('s', key)
+			if !hashValue(h, v[key]) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,197 @@
+package ruleengine
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_ResultCache(t *testing.T) {
+	type step struct {
+		rule      string
+		user      map[string]interface{}
+		overrides map[string]interface{}
+		want      bool
+		wantErr   bool
+	}
+	tests := []struct {
+		name  string
+		size  int
+		steps []step
+		want  ResultCacheStats
+	}{
+		{
+			name: "success - unread fields share the cached outcome",
+			size: 10,
+			steps: []step{
+				{rule: "adult", user: map[string]interface{}{"age": 20, "name": "a"}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 20, "name": "b"}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 20, "name": "c", "extra": []interface{}{1}}, want: true},
+			},
+			want: ResultCacheStats{Entries: 1, Hits: 2, Misses: 1},
+		},
+		{
+			name: "success - read fields change the key",
+			size: 10,
+			steps: []step{
+				{rule: "adult", user: map[string]interface{}{"age": 20}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 10}, want: false},
+				{rule: "adult", user: map[string]interface{}{}, wantErr: true},
+				{rule: "adult", user: map[string]interface{}{"age": 10}, want: false},
+			},
+			want: ResultCacheStats{Entries: 2, Hits: 1, Misses: 3},
+		},
+		{
+			name: "success - global overrides change the key",
+			size: 10,
+			steps: []step{
+				{rule: "adult", user: map[string]interface{}{"age": 20}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 20}, overrides: map[string]interface{}{"min_age": 21}, want: false},
+				{rule: "adult", user: map[string]interface{}{"age": 20}, overrides: map[string]interface{}{"min_age": 21}, want: false},
+			},
+			want: ResultCacheStats{Entries: 2, Hits: 1, Misses: 2},
+		},
+		{
+			name: "success - parent rules are cached separately",
+			size: 10,
+			steps: []step{
+				{rule: "adult_admin", user: map[string]interface{}{"age": 20, "role": "admin"}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 20, "role": "user"}, want: true},
+				{rule: "adult_admin", user: map[string]interface{}{"age": 20, "role": "user"}, want: false},
+			},
+			want: ResultCacheStats{Entries: 3, Hits: 2, Misses: 3},
+		},
+		{
+			name: "success - non-deterministic rules are not cached",
+			size: 10,
+			steps: []step{
+				{rule: "recent", user: map[string]interface{}{"age": 20}, want: true},
+				{rule: "recent", user: map[string]interface{}{"age": 20}, want: true},
+			},
+			want: ResultCacheStats{},
+		},
+		{
+			name: "success - least recently used evicted",
+			size: 2,
+			steps: []step{
+				{rule: "adult", user: map[string]interface{}{"age": 20}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 30}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 20}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 40}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 20}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 30}, want: true},
+			},
+			want: ResultCacheStats{Entries: 2, Hits: 2, Misses: 4},
+		},
+		{
+			name: "success - unhashable values are not cached",
+			size: 10,
+			steps: []step{
+				{rule: "adult", user: map[string]interface{}{"age": 20, "name": struct{}{}}, want: true},
+				{rule: "named", user: map[string]interface{}{"age": 20, "name": struct{ Name string }{"a"}}, wantErr: true},
+			},
+			want: ResultCacheStats{Entries: 1, Misses: 1},
+		},
+		{
+			name: "success - disabled",
+			size: 0,
+			steps: []step{
+				{rule: "adult", user: map[string]interface{}{"age": 20}, want: true},
+				{rule: "adult", user: map[string]interface{}{"age": 20}, want: true},
+			},
+			want: ResultCacheStats{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := newResultCacheEngine(t, tt.size)
+			for i, s := range tt.steps {
+				opts := []EvalOption{WithEvalContext(map[string]interface{}{"user": s.user})}
+				if s.overrides != nil {
+					opts = append(opts, WithGlobalOverrides(s.overrides))
+				}
+				got, err := re.EvaluateRule(s.rule, opts...)
+				if err != nil {
+					t.Fatalf("step %d: EvaluateRule(%s) error = %v", i, s.rule, err)
+				}
+				var evalErr *EvaluationError
+				if gotErr := errors.As(got.Error, &evalErr); gotErr != s.wantErr {
+					t.Fatalf("step %d: EvaluateRule(%s) error = %v, wantErr %v", i, s.rule, got.Error, s.wantErr)
+				}
+				if got.Passed != s.want {
+					t.Errorf("step %d: EvaluateRule(%s) passed = %v, want %v", i, s.rule, got.Passed, s.want)
+				}
+			}
+			if diff := cmp.Diff(tt.want, re.ResultCacheStats()); diff != "" {
+				t.Errorf("ResultCacheStats() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_ResultCacheConcurrent(t *testing.T) {
+	re := newResultCacheEngine(t, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				age := (i + j) % 32
+				got, err := re.EvaluateRule("adult", WithEvalContext(map[string]interface{}{
+					"user": map[string]interface{}{"age": age, "name": j},
+				}))
+				if err != nil {
+					t.Errorf("EvaluateRule() error = %v", err)
+					return
+				}
+				if got.Passed != (age >= 18) {
+					t.Errorf("EvaluateRule() age %d passed = %v", age, got.Passed)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	stats := re.ResultCacheStats()
+	if stats.Hits+stats.Misses != 800 || stats.Entries > 8 {
+		t.Errorf("ResultCacheStats() = %+v", stats)
+	}
+}
+
+// newResultCacheEngine creates an engine with a result cache holding up to size outcomes
+func newResultCacheEngine(t *testing.T, size int) *RuleEngine {
+	t.Helper()
+	re, err := NewBuilder().
+		WithConfig(&RulesetConfig{
+			Globals: map[string]interface{}{"min_age": 18},
+			Rules: map[string]Rule{
+				"adult":       {Expression: "user.age >= globals.min_age"},
+				"adult_admin": {Expression: `user.role == "admin"`, Extends: "adult"},
+				"named":       {Expression: `user.name == "a"`},
+				"recent":      {Expression: `user.age > 0 && now() > timestamp("2000-01-01T00:00:00Z")`},
+			},
+			ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+			ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+		}).
+		WithVariables("user").
+		WithFunctions(cel.Function("now",
+			cel.Overload("now", []*cel.Type{}, cel.TimestampType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return types.Timestamp{Time: time.Now()}
+				}),
+			),
+		)).
+		WithOptions(WithResultCache(size)).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	return re
+}
@@ -0,0 +1,97 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// ErrRetryable marks transient evaluation errors, e.g. a failed http_get() request, which a rule's retry policy
+// retries. Custom functions opt in by returning `types.WrapErr(fmt.Errorf("...: %w", ruleengine.ErrRetryable))`.
+var ErrRetryable = errors.New("retryable error")
+
+// retryableError is an error message matching ErrRetryable
+type retryableError struct {
+	msg string
+}
+
+// retryableErrorf formats an error matching ErrRetryable, keeping the message free of the sentinel's text
+func retryableErrorf(format string, args ...interface{}) error {
+	return &retryableError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Error implements error
+func (e *retryableError) Error() string {
+	return e.msg
+}
+
+// Is reports whether target is ErrRetryable
+func (e *retryableError) Is(target error) bool {
+	return target == ErrRetryable
+}
+
+// isRetryable reports whether val is a CEL error wrapping an ErrRetryable error
+func isRetryable(val ref.Val) bool {
+	err, ok := val.(*types.Err)
+	return ok && errors.Is(err, ErrRetryable)
+}
+
+// RetryPolicy configures how a rule is retried when its evaluation returns an ErrRetryable error
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of evaluations including the first, must be at least 1
+	MaxAttempts int `yaml:"max_attempts"`
+	// Backoff is the delay before the first retry, doubled for each further retry, e.g. `50ms`
+	Backoff string `yaml:"backoff"`
+}
+
+// retryPolicy is the parsed RetryPolicy of a rule
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// parseRetryPolicy validates and parses a rule's retry policy, a nil policy evaluates once
+func parseRetryPolicy(policy *RetryPolicy) (retryPolicy, error) {
+	if policy == nil {
+		return retryPolicy{maxAttempts: 1}, nil
+	}
+	if policy.MaxAttempts < 1 {
+		return retryPolicy{}, fmt.Errorf("retry max_attempts must be at least 1, got %d", policy.MaxAttempts)
+	}
+	parsed := retryPolicy{maxAttempts: policy.MaxAttempts}
+	if policy.Backoff != "" {
+		backoff, err := time.ParseDuration(policy.Backoff)
+		if err != nil {
+			return retryPolicy{}, fmt.Errorf("invalid retry backoff: %w", err)
+		}
+		if backoff < 0 {
+			return retryPolicy{}, fmt.Errorf("retry backoff must not be negative, got %s", policy.Backoff)
+		}
+		parsed.backoff = backoff
+	}
+	return parsed, nil
+}
+
+// evalRule evaluates the program of a rule, retrying ErrRetryable errors according to the rule's retry policy
+func (re *RuleEngine) evalRule(ruleName string, program cel.Program, input interface{}) (ref.Val, *cel.EvalDetails, error) {
+	policy, ok := re.retries[ruleName]
+	if !ok {
+		policy = retryPolicy{maxAttempts: 1}
+	}
+	backoff := policy.backoff
+	for attempt := 1; ; attempt++ {
+		out, details, err := re.evalProgram(program, input)
+		if err == nil || attempt >= policy.maxAttempts || !errors.Is(err, ErrRetryable) {
+			return out, details, err
+		}
+		if cache := boundHTTPCache(input); cache != nil {
+			cache.dropRetryable()
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,144 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRule_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		ruleName  string
+		failures  int
+		retryable bool
+		want      bool
+		wantCalls int
+	}{
+		{
+			name:      "success - recovers within attempts",
+			ruleName:  "retried_lookup",
+			failures:  2,
+			retryable: true,
+			want:      true,
+			wantCalls: 3,
+		},
+		{
+			name:      "fail - attempts exhausted",
+			ruleName:  "retried_lookup",
+			failures:  3,
+			retryable: true,
+			want:      false,
+			wantCalls: 3,
+		},
+		{
+			name:      "fail - error is not retryable",
+			ruleName:  "retried_lookup",
+			failures:  1,
+			retryable: false,
+			want:      false,
+			wantCalls: 1,
+		},
+		{
+			name:      "fail - rule without retry policy",
+			ruleName:  "single_lookup",
+			failures:  1,
+			retryable: true,
+			want:      false,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			lookup := cel.Function("lookup",
+				cel.Overload("lookup_dyn", []*cel.Type{cel.DynType}, cel.BoolType,
+					cel.UnaryBinding(func(ref.Val) ref.Val {
+						calls++
+						if calls <= tt.failures {
+							if tt.retryable {
+								return types.WrapErr(fmt.Errorf("lookup unavailable: %w", ErrRetryable))
+							}
+							return types.NewErr("lookup rejected")
+						}
+						return types.True
+					}),
+				),
+			)
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/retry_rules.yml").
+				WithVariables("user").
+				WithFunctions(lookup).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+
+			got, err := re.EvaluateRule(tt.ruleName, WithEvalContext(map[string]interface{}{
+				"user": map[string]interface{}{"id": 1},
+			}))
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRule() passed = %v, want %v, error = %v", got.Passed, tt.want, got.Error)
+			}
+			if !tt.want && errors.Is(got.Error, ErrRetryable) != tt.retryable {
+				t.Errorf("EvaluateRule() error = %v, want retryable %v", got.Error, tt.retryable)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("EvaluateRule() made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestParseRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		want    retryPolicy
+		wantErr bool
+	}{
+		{
+			name: "success - no policy",
+			want: retryPolicy{maxAttempts: 1},
+		},
+		{
+			name:   "success - with backoff",
+			policy: &RetryPolicy{MaxAttempts: 3, Backoff: "50ms"},
+			want:   retryPolicy{maxAttempts: 3, backoff: 50_000_000},
+		},
+		{
+			name:    "fail - no attempts",
+			policy:  &RetryPolicy{MaxAttempts: 0},
+			wantErr: true,
+		},
+		{
+			name:    "fail - invalid backoff",
+			policy:  &RetryPolicy{MaxAttempts: 2, Backoff: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "fail - negative backoff",
+			policy:  &RetryPolicy{MaxAttempts: 2, Backoff: "-1s"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRetryPolicy(tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRetryPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want, cmp.AllowUnexported(retryPolicy{})); diff != "" {
+				t.Errorf("parseRetryPolicy() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNothingStaged is returned by Promote when no candidate config is staged
+var ErrNothingStaged = errors.New("no config staged")
+
+// ShadowStats reports the shadow comparisons of a staged config against the active one, see RuleEngine.Stage
+type ShadowStats struct {
+	// Evaluations is the number of ruleset evaluations compared
+	Evaluations uint64
+	// Mismatches is the number of compared evaluations whose outcome differed
+	Mismatches uint64
+	// Errors is the number of evaluations the staged config failed, e.g. for a ruleset it does not have
+	Errors uint64
+}
+
+// ShadowMismatch is emitted when the staged config decides a ruleset evaluation differently than the active one
+type ShadowMismatch struct {
+	// RulesetName is the name of the evaluated ruleset
+	RulesetName string
+	// Diff compares the active result, A, with the staged result, B
+	Diff ResultDiff
+}
+
+// EventName implements Event
+func (ShadowMismatch) EventName() string {
+	return "shadow_mismatch"
+}
+
+// rollout holds the candidate engine staged next to the active one and the engine promoted over it
+type rollout struct {
+	mu       sync.RWMutex
+	staged   *RuleEngine
+	promoted *RuleEngine
+	stats    ShadowStats
+}
+
+// Stage builds a candidate engine from the config, with the environment, CEL env and options of this engine, and
+// runs it in shadow of the active config until promoted with Promote or dropped with Unstage
+//
+//	While staged, every EvaluateRuleset is also evaluated with the candidate, against the context set with SetContext
+//	unless one is given, and compared, mismatches are counted in ShadowStats and emitted as ShadowMismatch events.
+//	The caller is only ever served the active result, shadow evaluations add their latency to EvaluateRuleset.
+//	Staging again replaces the candidate and resets the stats. The config is modified in place. Errors are returned
+//	if the candidate fails to build, the active config keeps serving
+func (re *RuleEngine) Stage(config *RulesetConfig) error {
+	staged, err := re.candidate(config)
+	if err != nil {
+		return fmt.Errorf("failed to stage config: %w", err)
+	}
+	re.rollout.mu.Lock()
+	defer re.rollout.mu.Unlock()
+	re.rollout.staged = staged
+	re.rollout.stats = ShadowStats{}
+	return nil
+}
+
+// candidate builds an engine from the config with the environment, CEL env and options of this engine
+func (re *RuleEngine) candidate(config *RulesetConfig) (*RuleEngine, error) {
+	if config == nil {
+		return nil, errors.New("config is nil")
+	}
+	// Source positions belong to the config file of this engine
+	opts := append(re.options[:len(re.options):len(re.options)], withSources(nil))
+	return newRuleEngine(config, re.environment, re.baseEnv, opts...)
+}
+
+// Unstage drops the staged candidate, a no-op if none is staged
+func (re *RuleEngine) Unstage() {
+	re.rollout.mu.Lock()
+	defer re.rollout.mu.Unlock()
+	re.rollout.staged = nil
+	re.rollout.stats = ShadowStats{}
+}
+
+// Promote atomically makes the staged candidate the active config, evaluations started afterwards use it
+//
+//	Evaluations, SetContext and the methods describing the config are served by the promoted engine, the context
+//	set with SetContext carries over to it. Statistics restart with the promoted engine and a ConfigReloaded event
+//	is emitted. ErrNothingStaged is returned if no candidate is staged, an error wrapping ErrChangeRejected if the
+//	WithChangeGate gate rejects it
+func (re *RuleEngine) Promote() error {
+	staged := re.Staged()
+	if staged == nil {
+		return ErrNothingStaged
+	}
+	// The gate is asked without holding the lock, it may inspect the engine
+	if err := re.approve(ChangeRequest{Action: "promote", Config: staged.config}); err != nil {
+		return err
+	}
+	re.rollout.mu.Lock()
+	if re.rollout.staged != staged {
+		re.rollout.mu.Unlock()
+		return errors.New("staged config changed while awaiting approval")
+	}
+	previous := re
+	if re.rollout.promoted != nil {
+		previous = re.rollout.promoted
+	}
+	staged.inheritContext(previous)
+	re.rollout.promoted, re.rollout.staged = re.rollout.staged, nil
+	re.rollout.stats = ShadowStats{}
+	re.rollout.mu.Unlock()
+	// The event is emitted without holding the lock, the handler may use the engine
+	re.reloaded("promote", previous, staged)
+	return nil
+}
+
+// inheritContext sets the context of the engine to the SetContext context of the engine it replaces, with the
+// globals, builtins and computed fields of its own config
+func (re *RuleEngine) inheritContext(previous *RuleEngine) {
+	if previous.context == nil {
+		return
+	}
+	ctx := make(map[string]interface{}, len(previous.context))
+	for k, v := range previous.context {
+		ctx[k] = v
+	}
+	if len(previous.computed) > 0 {
+		delete(ctx, computedVariable)
+	}
+	re.context = re.withComputed(re.withBuiltins(ctx))
+}
+
+// withInheritedContext evaluates against the SetContext context of engine unless a context is given, with the
+// globals, builtins and computed fields of the evaluating engine
+func withInheritedContext(engine *RuleEngine) EvalOption {
+	return func(e *evaluation) {
+		e.inherited = engine.context
+	}
+}
+
+// Active returns the engine serving evaluations, the last promoted engine or this engine if none was promoted
+func (re *RuleEngine) Active() *RuleEngine {
+	re.rollout.mu.RLock()
+	defer re.rollout.mu.RUnlock()
+	if re.rollout.promoted != nil {
+		return re.rollout.promoted
+	}
+	return re
+}
+
+// Staged returns the staged candidate engine, nil if none is staged
+func (re *RuleEngine) Staged() *RuleEngine {
+	re.rollout.mu.RLock()
+	defer re.rollout.mu.RUnlock()
+	return re.rollout.staged
+}
+
+// ShadowStats returns the shadow comparisons of the staged config, empty if none is staged
+func (re *RuleEngine) ShadowStats() ShadowStats {
+	re.rollout.mu.RLock()
+	defer re.rollout.mu.RUnlock()
+	return re.rollout.stats
+}
+
+// shadow evaluates the ruleset with the staged candidate, if any, and compares it with the result of the active engine
+func (re *RuleEngine) shadow(rulesetName string, engine *RuleEngine, opts []EvalOption, active RulesetResult) {
+	staged := re.Staged()
+	if staged == nil {
+		return
+	}
+	opts = append(opts[:len(opts):len(opts)], withInheritedContext(engine), withoutProgress())
+	result, err := staged.EvaluateRuleset(rulesetName, opts...)
+
+	re.rollout.mu.Lock()
+	if re.rollout.staged != staged {
+		// Staged again or promoted meanwhile, the comparison no longer applies
+		re.rollout.mu.Unlock()
+		return
+	}
+	re.rollout.stats.Evaluations++
+	if err != nil {
+		re.rollout.stats.Errors++
+		re.rollout.mu.Unlock()
+		return
+	}
+	mismatch := active.Passed != result.Passed
+	if mismatch {
+		re.rollout.stats.Mismatches++
+	}
+	re.rollout.mu.Unlock()
+
+	if mismatch {
+		re.emit(ShadowMismatch{RulesetName: rulesetName, Diff: DiffResults(active, result)})
+	}
+}
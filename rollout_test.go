@@ -0,0 +1,206 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// rolloutConfig returns a config of the adult ruleset with the given age requirement
+func rolloutConfig(expression string) *RulesetConfig {
+	return &RulesetConfig{
+		Rules:             map[string]Rule{"age_validation": {Expression: expression}},
+		Rulesets:          map[string]Ruleset{"adult": {Selector: "AND", Rules: []string{"age_validation"}}},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+	}
+}
+
+func TestRuleEngine_Stage(t *testing.T) {
+	events := make([]Event, 0)
+	re, err := NewBuilder().
+		WithConfig(rolloutConfig("user.age >= 18")).
+		WithVariables("user").
+		WithOptions(WithEventHandler(func(e Event) { events = append(events, e) })).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	ctx := WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}})
+
+	if err := re.Promote(); !errors.Is(err, ErrNothingStaged) {
+		t.Errorf("Promote() error = %v, want %v", err, ErrNothingStaged)
+	}
+	if err := re.Stage(rolloutConfig("user.age >=")); err == nil {
+		t.Error("Stage() error = nil, want an error for an invalid config")
+	}
+	if re.Staged() != nil {
+		t.Error("Staged() != nil after a failed Stage()")
+	}
+
+	if err := re.Stage(rolloutConfig("user.age >= 21")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		result, err := re.EvaluateRuleset("adult", ctx)
+		if err != nil {
+			t.Fatalf("EvaluateRuleset() error = %v", err)
+		}
+		if !result.Passed {
+			t.Error("EvaluateRuleset() passed = false, want the active result while staged")
+		}
+	}
+	if diff := cmp.Diff(ShadowStats{Evaluations: 2, Mismatches: 2}, re.ShadowStats()); diff != "" {
+		t.Errorf("ShadowStats() mismatch (-want +got):\n%s", diff)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	mismatch, ok := events[0].(ShadowMismatch)
+	if !ok || mismatch.RulesetName != "adult" || len(mismatch.Diff.ChangedRules()) != 1 {
+		t.Errorf("event = %+v, want a ShadowMismatch of age_validation", events[0])
+	}
+
+	if err := re.Promote(); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+	if re.Staged() != nil || re.Active() == re {
+		t.Error("Promote() did not replace the active engine")
+	}
+	result, err := re.EvaluateRuleset("adult", ctx)
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if result.Passed {
+		t.Error("EvaluateRuleset() passed = true, want the promoted result")
+	}
+	rule, err := re.EvaluateRule("age_validation", ctx)
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	if rule.Passed {
+		t.Error("EvaluateRule() passed = true, want the promoted result")
+	}
+	if diff := cmp.Diff(ShadowStats{}, re.ShadowStats()); diff != "" {
+		t.Errorf("ShadowStats() after Promote() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRuleEngine_Unstage(t *testing.T) {
+	re, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if err := re.Stage(rolloutConfig("user.age >= 21")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	// The staged config errors for rulesets it does not have
+	staged := rolloutConfig("user.age >= 21")
+	staged.Rulesets = map[string]Ruleset{"other": {Selector: "AND", Rules: []string{"age_validation"}}}
+	if err := re.Stage(staged); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if _, err := re.EvaluateRuleset("adult", WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 30}})); err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if diff := cmp.Diff(ShadowStats{Evaluations: 1, Errors: 1}, re.ShadowStats()); diff != "" {
+		t.Errorf("ShadowStats() mismatch (-want +got):\n%s", diff)
+	}
+
+	re.Unstage()
+	if re.Staged() != nil {
+		t.Error("Staged() != nil after Unstage()")
+	}
+	if err := re.Promote(); !errors.Is(err, ErrNothingStaged) {
+		t.Errorf("Promote() error = %v, want %v", err, ErrNothingStaged)
+	}
+}
+
+func TestRuleEngine_StageAfterPromote(t *testing.T) {
+	re, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if err := re.Stage(rolloutConfig("user.age >= 21")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := re.Promote(); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+	if err := re.Stage(rolloutConfig("user.age >= 18")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if _, err := re.EvaluateRuleset("adult", WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}})); err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if diff := cmp.Diff(ShadowStats{Evaluations: 1, Mismatches: 1}, re.ShadowStats()); diff != "" {
+		t.Errorf("ShadowStats() after a second Stage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRuleEngine_ShadowSetContext(t *testing.T) {
+	re, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	re.SetContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}})
+	if err := re.Stage(rolloutConfig("user.age >= 18")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	result, err := re.EvaluateRuleset("adult")
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if !result.Passed {
+		t.Error("EvaluateRuleset() passed = false, want true for the SetContext context")
+	}
+	if diff := cmp.Diff(ShadowStats{Evaluations: 1}, re.ShadowStats()); diff != "" {
+		t.Errorf("ShadowStats() of an identical config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRuleEngine_PromoteForwards(t *testing.T) {
+	re, err := NewBuilder().WithConfig(rolloutConfig("user.age >= 18")).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	re.SetContext(map[string]interface{}{"user": map[string]interface{}{"age": 19}})
+	if err := re.Stage(rolloutConfig("user.age >= 21")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := re.Promote(); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+
+	// The context set before the promotion carries over
+	result, err := re.EvaluateRuleset("adult")
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if result.Passed {
+		t.Error("EvaluateRuleset() passed = true, want the promoted result against the carried over context")
+	}
+	re.SetContext(map[string]interface{}{"user": map[string]interface{}{"age": 22}})
+	passed, err := EvaluateAs[bool](re, "age_validation")
+	if err != nil {
+		t.Fatalf("EvaluateAs() error = %v", err)
+	}
+	if !passed {
+		t.Error("EvaluateAs() = false, want the promoted result against the new context")
+	}
+
+	whatIf, err := re.WhatIf("adult", map[string]interface{}{"user": map[string]interface{}{"age": 19}}, map[string]interface{}{"user.age": 21})
+	if err != nil {
+		t.Fatalf("WhatIf() error = %v", err)
+	}
+	if !whatIf.Flipped() {
+		t.Error("WhatIf() did not flip, want the promoted age requirement")
+	}
+	if rule, _ := re.GetRule("age_validation"); rule.Expression != "user.age >= 21" {
+		t.Errorf("GetRule() expression = %q, want the promoted expression", rule.Expression)
+	}
+	if re.ConfigVersion() != re.Active().ConfigVersion() {
+		t.Error("ConfigVersion() is not the version of the promoted config")
+	}
+}
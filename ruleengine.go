@@ -3,11 +3,13 @@ package ruleengine
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
 )
 
 const (
@@ -21,10 +23,14 @@ const (
 type RuleEngine struct {
 	// config is the loaded ruleset configuration
 	config *RulesetConfig
+	// base is a copy of the config as given, before the environment, profile and error message overrides are applied
+	base *RulesetConfig
 	// env is the CEL environment used for compiling and evaluating expressions
 	env *cel.Env
-	// programs is a map of rule names to their compiled CEL programs
-	programs map[string]cel.Program
+	// programs holds the compiled CEL programs of the rules, see WithMaxPrograms
+	programs *programStore
+	// adhoc caches the compiled programs of EvaluateExpression keyed by expression
+	adhoc *programStore
 	// parents is a map of rule names to their parent rules for inheritance
 	parents map[string][]string
 	// policy is the execution policy applied during rule evaluation
@@ -33,10 +39,104 @@ type RuleEngine struct {
 	context map[string]interface{}
 	// optimise indicates whether to optimise rule evaluation
 	optimise bool
+	// counters is a map of rule names to their hit-rate counters
+	counters map[string]*ruleCounters
+	// profiler records sampled latency and cost, nil unless enabled with WithProfiler
+	profiler *profiler
+	// breakerConfig configures the per-rule circuit breakers, nil unless enabled with WithCircuitBreaker
+	breakerConfig *CircuitBreakerConfig
+	// breakers is a map of rule names to their circuit breakers
+	breakers map[string]*circuitBreaker
+	// eventHandler receives engine events, nil unless set with WithEventHandler
+	eventHandler EventHandler
+	// httpGet is the sandboxed http_get() library, nil unless enabled with WithHTTPGet
+	httpGet *httpGetLib
+	// state is the counter() library backed by a StateStore, nil unless enabled with WithStateStore
+	state *stateLib
+	// costs is a map of rule names to their static cost estimates
+	costs map[string]CostEstimate
+	// costBudget is the maximum estimated cost of a rule, zero unless enabled with WithCostBudget
+	costBudget uint64
+	// retries is a map of rule names to their retry policies
+	retries map[string]retryPolicy
+	// recoverPanics indicates whether panics during evaluation are recovered with stack traces, see WithPanicRecovery
+	recoverPanics bool
+	// fuzz caches the state used by FuzzEvaluate
+	fuzz *fuzzState
+	// sources is a map of rule names to the YAML position of their expression, empty for configs built in code
+	sources map[string]SourcePosition
+	// preconditions is a map of ruleset names to their compiled precondition programs
+	preconditions map[string]cel.Program
+	// guards is a map of rule names to their compiled when clause programs
+	guards map[string]cel.Program
+	// activations and rulesetActivations are maps of rule and ruleset names to their time windows, if any
+	activations        map[string]*activation
+	rulesetActivations map[string]*activation
+	// explainers is a map of rule names to their explanation programs, nil unless enabled with WithExplanations
+	explainers map[string]explainer
+	// transformers post-process every RulesetResult, see WithResultTransformer
+	transformers []ResultTransformer
+	// compileCache stores checked ASTs on disk, nil unless enabled with WithCompileCache
+	compileCache *compileCache
+	// precompiled holds the checked ASTs of a bundle, nil unless created with NewRuleEngineFromBundle
+	precompiled *precompiled
+	// bundleKey signs and verifies bundles, nil unless set with WithBundleKey
+	bundleKey []byte
+	// environment is the name of the applied environment overrides
+	environment string
+	// profile is the name of the applied profile overrides, see withProfile
+	profile string
+	// footprints is a map of rule names to the size of their checked ASTs
+	footprints map[string]footprint
+	// listIndexSize is the minimum number of elements of the indexed global lists, see WithListIndex
+	listIndexSize int
+	// listIndex holds the indexed global lists, nil when no list is indexed
+	listIndex *listIndex
+	// failFastAll stops EvaluateAllRulesets at the first fatal ruleset, see WithFailFastAll
+	failFastAll bool
+	// resultCache caches rule outcomes keyed by the values they read, nil unless enabled with WithResultCache
+	resultCache *resultCache
+	// quarantined is a map of rule names to the compile errors they were quarantined for, nil unless WithQuarantine
+	quarantined map[string]*CompileError
+	// missingRules is the set of rules referenced by rulesets but not defined, nil unless WithSkipMissingRules
+	missingRules map[string]bool
+	// sandbox is the sandbox profile restricting expressions, nil if unrestricted, see WithSandbox
+	sandbox *SandboxProfile
+	// queue bounds the evaluations running at once, nil if unbounded, see WithEvaluationQueue
+	queue *evalQueue
+	// options and baseEnv are the options and CEL env the engine was created with, used to build staged configs
+	options []Option
+	baseEnv *cel.Env
+	// version is the ConfigVersion of the config and compileDuration the time taken to build the engine
+	version         string
+	compileDuration time.Duration
+	// envDescriptor is the host env required by WithEnvDescriptor, nil if not verified
+	envDescriptor *EnvDescriptor
+	// rollout holds the staged and promoted engines, see Stage
+	rollout *rollout
+	// changeGate approves Commit and Promote, nil unless set with WithChangeGate
+	changeGate ChangeGate
+	// computed is a map of computed field names to their compiled programs, see RulesetConfig.Computed
+	computed map[string]cel.Program
+	// interceptors wrap every rule evaluation, see WithInterceptor
+	interceptors []Interceptor
+	// decision is the compiled decision expression of the config, nil if it has none
+	decision cel.Program
+	// decisions is a map of decision names to their compiled conditions
+	decisions map[string]*compiledDecision
+	// contextLimits bounds the evaluation contexts, nil unless enabled with WithContextLimits
+	contextLimits *ContextLimits
+	// defaultConfigErr is why the primary config was rejected, nil unless running on the WithDefaultConfig config
+	defaultConfigErr error
+	// builtins binds the globals and builtin helpers for EvaluateRulesetBool
+	builtins interpreter.Activation
+	// mirror receives a sample of the ruleset evaluations, nil unless enabled with WithMirror
+	mirror *mirror
 }
 
 type Policy struct {
 	StopOnFailure    bool
+	StopOnFirstPass  bool
 	MaxExecutionTime time.Duration
 }
 
@@ -52,106 +152,373 @@ func WithOptimise() Option {
 
 // NewRuleEngine creates a new ruleengine instance
 func NewRuleEngine(configPath string, environment string, env *cel.Env, opts ...Option) (*RuleEngine, error) {
-	config, err := NewRulesetConfig(configPath)
+	config, sources, err := loadRulesetConfig(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	return newRuleEngine(config, environment, env, append(opts[:len(opts):len(opts)], withSources(sources))...)
+}
+
+// withSources records the YAML positions of rule expressions for error reporting
+func withSources(sources map[string]SourcePosition) Option {
+	return func(re *RuleEngine) {
+		re.sources = sources
+	}
+}
 
+// newRuleEngine creates a new ruleengine instance from a loaded config, the config is modified in place
+func newRuleEngine(config *RulesetConfig, environment string, env *cel.Env, opts ...Option) (*RuleEngine, error) {
+	start := time.Now()
+	baseEnv := env
+	if err := config.GlobalsMerge.validate(); err != nil {
+		return nil, fmt.Errorf("invalid globals merge: %w", err)
+	}
+	if config.StrictEnvironments {
+		if err := config.ValidateEnvironments(environment); err != nil {
+			return nil, fmt.Errorf("invalid environments: %w", err)
+		}
+	}
+	if config.StrictReferences {
+		if err := config.ValidateReferences(); err != nil {
+			return nil, fmt.Errorf("invalid references: %w", err)
+		}
+	}
+	base := config.clone()
+	config.mergeErrorMessages()
 	config.ApplyEnvironment(environment)
 
 	policy, err := config.ToExecutionPolicy()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get execution policy: %w", err)
 	}
+	if err := config.ErrorHandling.HTTPProblems.validate(); err != nil {
+		return nil, fmt.Errorf("invalid http problems: %w", err)
+	}
 
 	if env == nil {
 		return nil, fmt.Errorf("cel env is nil")
 	}
 
+	// Register the engine's built-in function libraries
+	env, err = env.Extend(GeoFunctions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to extend cel env: %w", err)
+	}
+
+	// Register the protobuf types and typed variables of the config
+	typeOpts, err := config.Types.envOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load types: %w", err)
+	}
+	if len(typeOpts) > 0 {
+		if env, err = env.Extend(typeOpts...); err != nil {
+			return nil, fmt.Errorf("failed to extend cel env: %w", err)
+		}
+	}
+
 	engine := &RuleEngine{
-		config:   config,
-		env:      env,
-		policy:   policy,
-		programs: make(map[string]cel.Program),
-		context:  make(map[string]interface{}),
-		parents:  make(map[string][]string),
-		optimise: false,
+		config:      config,
+		base:        base,
+		environment: environment,
+		env:         env,
+		policy:      policy,
+		programs:    newProgramStore(),
+		adhoc:       newExpressionStore(),
+		context:     make(map[string]interface{}),
+		parents:     make(map[string][]string),
+		counters:    make(map[string]*ruleCounters),
+		breakers:    make(map[string]*circuitBreaker),
+		costs:       make(map[string]CostEstimate),
+		retries:     make(map[string]retryPolicy),
+		optimise:    false,
+		fuzz:        &fuzzState{},
+
+		preconditions: make(map[string]cel.Program),
+		guards:        make(map[string]cel.Program),
+		activations:   make(map[string]*activation),
+
+		rulesetActivations: make(map[string]*activation),
+		footprints:         make(map[string]footprint),
+		listIndexSize:      defaultListIndexSize,
+
+		options: opts,
+		baseEnv: baseEnv,
+		rollout: &rollout{},
 	}
 
 	// Apply all provided options
 	for _, opt := range opts {
 		opt(engine)
 	}
+	if engine.sandbox == nil {
+		if engine.sandbox, err = config.resolveSandbox(); err != nil {
+			return nil, err
+		}
+	}
+	if engine.queue != nil {
+		if err := engine.queue.init(); err != nil {
+			return nil, fmt.Errorf("invalid evaluation queue: %w", err)
+		}
+	}
+	if engine.envDescriptor != nil {
+		if err := engine.envDescriptor.Verify(baseEnv); err != nil {
+			return nil, err
+		}
+	}
+	if err := engine.resolveRulesetRules(); err != nil {
+		return nil, fmt.Errorf("invalid rulesets: %w", err)
+	}
+	engine.programs.recompile = engine.recompileRule
+	engine.adhoc.recompile = engine.compileExpression
+
+	// Register optional function libraries enabled by options
+	if engine.httpGet != nil {
+		engine.env, err = engine.env.Extend(cel.Lib(engine.httpGet))
+		if err != nil {
+			return nil, fmt.Errorf("failed to extend cel env: %w", err)
+		}
+	}
+	if engine.state != nil {
+		engine.env, err = engine.env.Extend(cel.Lib(engine.state))
+		if err != nil {
+			return nil, fmt.Errorf("failed to extend cel env: %w", err)
+		}
+	}
+
+	if engine.sandbox != nil {
+		if err := engine.sandbox.validate(); err != nil {
+			return nil, fmt.Errorf("invalid sandbox: %w", err)
+		}
+		if sandboxOpts := engine.sandbox.envOptions(); len(sandboxOpts) > 0 {
+			engine.env, err = engine.env.Extend(sandboxOpts...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extend cel env: %w", err)
+			}
+		}
+	}
+
+	// Expand user-defined functions into CEL macros available to all rules
+	err = engine.registerFunctions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to register functions: %w", err)
+	}
+	if err := engine.declareComputed(); err != nil {
+		return nil, err
+	}
+
+	// Cache keys depend on the complete env, including functions registered above
+	if engine.compileCache != nil {
+		engine.compileCache.envHash = engine.envHash()
+	}
+	if engine.precompiled != nil && engine.precompiled.envHash != engine.envHash() {
+		return nil, errors.New("bundle was compiled against a different cel env")
+	}
+
+	// Programs are created with lookups rewritten to use the indexed lists
+	engine.buildListIndex()
+	engine.builtins = engine.builtinActivation()
 
 	// Pre-compile all rule expressions into `cel.Program`
 	err = engine.compileRules()
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile rules: %w", err)
 	}
+	if err := engine.compileComputed(); err != nil {
+		return nil, err
+	}
+	if err := engine.compileDecision(); err != nil {
+		return nil, err
+	}
+	if err := engine.compileDecisions(); err != nil {
+		return nil, err
+	}
+	if engine.version, err = configVersion(config); err != nil {
+		return nil, err
+	}
 
+	engine.compileDuration = time.Since(start)
 	return engine, nil
 }
 
 // SetContext sets the evaluation context for the rule engine
+//
+//	The context is set on the active engine, see Promote, and carries over to engines promoted afterwards
 func (re *RuleEngine) SetContext(ctx map[string]interface{}) {
-	re.context = ctx
+	if active := re.Active(); active != re {
+		active.SetContext(ctx)
+		return
+	}
+	re.context = re.withComputed(re.withBuiltins(ctx))
+}
+
+// withBuiltins adds the globals and built-in helpers to ctx
+func (re *RuleEngine) withBuiltins(ctx map[string]interface{}) map[string]interface{} {
 	// Always include globals in context
-	re.context["globals"] = re.config.Globals
+	ctx["globals"] = re.globals()
 	// Add current timestamp
-	re.context["now"] = func() ref.Val {
+	ctx["now"] = func() ref.Val {
 		return types.Timestamp{Time: time.Now()}
 	}
-	re.context["timestamp"] = func(s string) ref.Val {
+	ctx["timestamp"] = func(s string) ref.Val {
 		t, err := time.Parse(time.RFC3339, s)
 		if err != nil {
 			return types.NewErr("invalid timestamp format")
 		}
 		return types.Timestamp{Time: t}
 	}
+	return ctx
 }
 
 // EvaluateRule evaluates a single rule `cel.Program` by name
 //
 //	Errors are returned if the rule is not found or if there is an issue during evaluation
 //	If the rule evaluates to false, a RuleResult with Passed=false is returned and nil error
-func (re *RuleEngine) EvaluateRule(ruleName string) (RuleResult, error) {
+func (re *RuleEngine) EvaluateRule(ruleName string, opts ...EvalOption) (RuleResult, error) {
+	if active := re.Active(); active != re {
+		return active.EvaluateRule(ruleName, opts...)
+	}
+	release, _, err := re.enqueue(false)
+	if err != nil {
+		return RuleResult{}, err
+	}
+	defer release()
+	eval, err := re.newEvaluation(opts)
+	if err != nil {
+		return RuleResult{}, err
+	}
+	return re.evaluateRule(ruleName, eval)
+}
+
+// evaluateRule evaluates a single rule against the evaluation state, reusing its memoized result if any
+func (re *RuleEngine) evaluateRule(ruleName string, eval *evaluation) (RuleResult, error) {
+	if eval.memo != nil {
+		if result, ok := eval.memo.results[ruleName]; ok {
+			return result, nil
+		}
+	}
+	result, err := re.intercept(ruleName, eval)
+	if err != nil {
+		return result, err
+	}
+	if !result.Passed && !result.Skipped {
+		result.ReasonCode = re.config.Rules[ruleName].ReasonCode
+	}
+	if eval.memo != nil {
+		eval.memo.results[ruleName] = result
+	}
+	return result, nil
+}
+
+// evaluateRuleChain evaluates a rule and the rules it extends
+func (re *RuleEngine) evaluateRuleChain(ruleName string, eval *evaluation) (RuleResult, error) {
 	start := time.Now()
 
-	rule, rExists := re.config.Rules[ruleName]
+	_, rExists := re.config.Rules[ruleName]
 	if !rExists {
+		// Rules missing from a WithSkipMissingRules config are skipped
+		if result, ok := re.missingResult(ruleName); ok {
+			return result, nil
+		}
 		return RuleResult{}, fmt.Errorf("rule '%s' not found", ruleName)
 	}
 
-	allRules := append(re.parents[ruleName], ruleName)
+	// Quarantined rules cannot be evaluated and are skipped
+	if result, ok := re.quarantinedResult(ruleName); ok {
+		return result, nil
+	}
+
+	// Rules outside their time window are skipped
+	if !re.activations[ruleName].active(start) {
+		return RuleResult{RuleName: ruleName, Skipped: true, Duration: time.Since(start)}, nil
+	}
+
+	// Rules whose when clause does not hold are skipped
+	if skip, err := re.evaluateGuard(ruleName, eval); skip || err != nil {
+		result := RuleResult{
+			RuleName: ruleName,
+			Skipped:  skip,
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			result.Error = &EvaluationError{RuleName: ruleName, Err: fmt.Errorf("when clause failed to evaluate: %w", err)}
+			re.recordRule(result, true, false, 0)
+		}
+		return result, nil
+	}
+
+	// Rules disabled by the circuit breaker are not evaluated
+	if !re.breakerAllow(ruleName) {
+		return RuleResult{
+			RuleName: ruleName,
+			Passed:   false,
+			Error:    &EvaluationError{RuleName: ruleName, Err: ErrCircuitOpen},
+			Duration: time.Since(start),
+		}, nil
+	}
+
+	// Copy the parents so concurrent evaluations never share the appended slice
+	allRules := make([]string, 0, len(re.parents[ruleName])+1)
+	allRules = append(allRules, re.parents[ruleName]...)
+	allRules = append(allRules, ruleName)
+
+	// Only sampled evaluations pay for profiling
+	sampled := re.profiler.sample()
+	var cost uint64
 
 	passed := false
+	// failed is the rule of the chain which did not pass
+	var failed string
 	for _, r := range allRules {
-		program, pExists := re.programs[r]
-		if !pExists {
-			return RuleResult{}, fmt.Errorf("program for rule '%s' not found", rule)
-		}
-		out, _, err := program.Eval(re.context)
+		program, err := re.programs.get(r)
 		if err != nil {
+			return RuleResult{}, err
+		}
+		if sampled {
+			program = program.(*profiledProgram).profiled
+		}
+		outcome, ok := eval.memo.program(r)
+		var cacheKey resultCacheKey
+		var cacheable bool
+		if !ok {
+			if cacheKey, cacheable = re.resultCache.key(r, eval.context); cacheable {
+				outcome, ok = re.resultCache.get(cacheKey)
+			}
+		}
+		if !ok {
+			out, details, err := re.evalRule(r, program, eval.input())
+			if sampled && details != nil && details.ActualCost() != nil {
+				cost += *details.ActualCost()
+			}
+			outcome.err = err
+			if err == nil {
+				// Convert CEL value to Go value
+				outcome.passed, _ = out.Value().(bool)
+			}
+			eval.memo.setProgram(r, outcome)
+			if cacheable {
+				re.resultCache.put(cacheKey, outcome)
+			}
+		}
+		passed = outcome.passed
+		if err := outcome.err; err != nil {
 			// An unsuccessful evaluation is typically the result of a series of incompatible `EnvOption`
 			// or `ProgramOption` values used in the creation of the evaluation environment or executable
 			// program.
 			// We don't want to overwrite CEL evaluation errors with custom error messages
 			// Instead, we return a failed RuleResult with the error.
 			// The caller can decide how to handle it based on the policy.
-			return RuleResult{
+			result := RuleResult{
 				RuleName: ruleName,
 				Passed:   false,
-				Error:    err,
+				Error:    &EvaluationError{RuleName: ruleName, Err: err},
 				Duration: time.Since(start),
-			}, nil
-		}
-		// Convert CEL value to Go value
-		value := out.Value()
-		if boolVal, ok := value.(bool); ok {
-			passed = boolVal
+			}
+			re.recordRule(result, true, sampled, cost)
+			return result, nil
 		}
 		// If any rule in the chain fails, the overall result is false
 		if !passed {
+			failed = r
 			break
 		}
 	}
@@ -164,12 +531,17 @@ func (re *RuleEngine) EvaluateRule(ruleName string) (RuleResult, error) {
 			errorMessage = errors.New(msg)
 		}
 	}
-	return RuleResult{
+	result := RuleResult{
 		RuleName: ruleName,
 		Passed:   passed,
 		Error:    errorMessage,
 		Duration: time.Since(start),
-	}, nil
+	}
+	if failed != "" && re.explainers != nil {
+		result.Explanation, result.Suggestions = re.explainFailure(failed, eval.input())
+	}
+	re.recordRule(result, false, sampled, cost)
+	return result, nil
 }
 
 // EvaluateRuleset evaluates a ruleset by name, handling rule inheritance and selector logic
@@ -177,7 +549,46 @@ func (re *RuleEngine) EvaluateRule(ruleName string) (RuleResult, error) {
 //		Errors are returned if the ruleset is not found
 //		If the rule evaluates to false, a RuleResult with Passed=false is returned and nil error
 //	    If the rule evaluates to true, a RuleResult with Passed=true is returned and nil error
-func (re *RuleEngine) EvaluateRuleset(rulesetName string) (RulesetResult, error) {
+func (re *RuleEngine) EvaluateRuleset(rulesetName string, opts ...EvalOption) (RulesetResult, error) {
+	if active := re.Active(); active != re {
+		// Candidates are staged on this engine, never on the promoted one
+		result, err := active.EvaluateRuleset(rulesetName, opts...)
+		if err == nil {
+			re.shadow(rulesetName, active, opts, result)
+		}
+		return result, err
+	}
+	release, shed, err := re.enqueue(true)
+	if shed || err != nil {
+		if shed {
+			return re.shedResult(rulesetName)
+		}
+		return RulesetResult{}, err
+	}
+	defer release()
+	eval, err := re.newEvaluation(opts)
+	if err != nil {
+		return RulesetResult{}, err
+	}
+	result, err := re.evaluateRuleset(rulesetName, eval)
+	if err == nil {
+		re.shadow(rulesetName, re, opts, result)
+		re.mirrorEvaluation(rulesetName, opts, result)
+	}
+	return result, err
+}
+
+// evaluateRuleset evaluates a ruleset against the evaluation state, applying the registered result transformers
+func (re *RuleEngine) evaluateRuleset(rulesetName string, eval *evaluation) (RulesetResult, error) {
+	result, err := re.evaluateRulesetRules(rulesetName, eval)
+	if err != nil {
+		return result, err
+	}
+	return re.transformResult(result), nil
+}
+
+// evaluateRulesetRules evaluates the rules of a ruleset and combines their results, applying its fallback
+func (re *RuleEngine) evaluateRulesetRules(rulesetName string, eval *evaluation) (RulesetResult, error) {
 	start := time.Now()
 
 	ruleset, rOk := re.config.Rulesets[rulesetName]
@@ -185,36 +596,118 @@ func (re *RuleEngine) EvaluateRuleset(rulesetName string) (RulesetResult, error)
 		return RulesetResult{}, fmt.Errorf("ruleset '%s' not found", rulesetName)
 	}
 
-	result := RulesetResult{
-		RulesetName: rulesetName,
-		RuleResults: make(map[string]RuleResult, len(ruleset.Rules)),
+	done, total := 0, len(ruleset.Rules)
+	// Rules left unevaluated once the outcome is known are reported done at once
+	defer func() {
+		if done < total {
+			eval.reportProgress(rulesetName, total, total)
+		}
+	}()
+
+	result := RulesetResult{RulesetName: rulesetName}
+	if eval.into != nil && eval.into.RuleResults != nil {
+		result.RuleResults, result.Order = eval.into.RuleResults, eval.into.Order[:0]
+		clear(result.RuleResults)
+	} else {
+		result.RuleResults = make(map[string]RuleResult, len(ruleset.Rules))
+		result.Order = make([]string, 0, len(ruleset.Rules))
+	}
+
+	// Rulesets outside their time window are skipped without evaluating their rules
+	if !re.rulesetActivations[rulesetName].active(start) {
+		result.Duration = time.Since(start)
+		result.Skipped = true
+		return result, nil
+	}
+
+	// Rulesets whose precondition does not hold are skipped without evaluating their rules
+	if skip, err := re.evaluatePrecondition(rulesetName, eval); skip || err != nil {
+		result.Duration = time.Since(start)
+		if err != nil {
+			result.Error = fmt.Errorf("ruleset '%s' precondition failed to evaluate: %w", rulesetName, err)
+			if ruleset.Fallback != "" {
+				applyFallback(&result, ruleset.Fallback, err)
+			}
+			return result, nil
+		}
+		result.Skipped = true
+		return result, nil
+	}
+
+	// degradedErr is the cause for applying the ruleset fallback, only tracked when one is configured
+	var degradedErr error
+	deadline := start.Add(re.policy.MaxExecutionTime)
+
+	// OR rulesets stopping at the first pass evaluate the rules most likely to pass cheaply first
+	earlyExit := ruleset.Selector == selectorOr && re.policy.StopOnFirstPass
+	rules := ruleset.Rules
+	if earlyExit {
+		rules = re.earlyExitOrder(rules)
+	}
+
+	// Veto rules are evaluated even after an early exit, they can still fail the ruleset
+	vetoes := make(map[string]bool, len(ruleset.Vetoes))
+	for _, name := range ruleset.Vetoes {
+		vetoes[name] = true
+	}
+	exited := false
+	// Failures of optional rules are tolerated by AND rulesets and reported as warnings
+	optional := make(map[string]bool, len(ruleset.Optional))
+	if ruleset.Selector != selectorOr {
+		for _, name := range ruleset.Optional {
+			optional[name] = true
+		}
 	}
 
 	// Evaluate individual rules
-	for _, ruleRef := range ruleset.Rules {
-		ruleResult, err := re.EvaluateRule(ruleRef)
+	for _, ruleRef := range rules {
+		if exited && !vetoes[ruleRef] {
+			continue
+		}
+		if ruleset.Fallback != "" && time.Now().After(deadline) {
+			degradedErr = fmt.Errorf("exceeded max execution time of %s", re.policy.MaxExecutionTime)
+			break
+		}
+		ruleResult, err := re.evaluateRule(ruleRef, eval)
+		done++
+		eval.reportProgress(rulesetName, done, total)
+		if _, ok := result.RuleResults[ruleRef]; !ok {
+			result.Order = append(result.Order, ruleRef)
+		}
 		result.RuleResults[ruleRef] = ruleResult
+		var evalErr *EvaluationError
+		if ruleset.Fallback != "" && degradedErr == nil && errors.As(ruleResult.Error, &evalErr) {
+			degradedErr = evalErr
+		}
 		// fail-fast policy
-		if ruleset.Selector != selectorOr && (!ruleResult.Passed || err != nil) && re.policy.StopOnFailure {
+		if ruleset.Selector != selectorOr && !optional[ruleRef] && ((!ruleResult.Passed && !ruleResult.Skipped) || err != nil) && re.policy.StopOnFailure {
 			break
 		}
+		// early exit policy
+		if earlyExit && ruleResult.Passed && !vetoes[ruleRef] {
+			if len(vetoes) == 0 {
+				break
+			}
+			exited = true
+		}
 	}
 
 	// Evaluate based on selector type
 	switch ruleset.Selector {
 	case selectorAnd:
 		result.Passed = true
-		for _, ruleResult := range result.RuleResults {
-			if !ruleResult.Passed {
+		for name, ruleResult := range result.RuleResults {
+			if !ruleResult.Passed && !ruleResult.Skipped && !optional[name] {
 				result.Passed = false
 				break
 			}
 		}
 
 	case selectorOr:
+		// Veto rules can only fail the ruleset, passing one is not a path to pass it
 		result.Passed = false
-		for _, ruleResult := range result.RuleResults {
-			if ruleResult.Passed {
+		for name, ruleResult := range result.RuleResults {
+			if ruleResult.Passed && !vetoes[name] {
 				result.Passed = true
 				break
 			}
@@ -223,15 +716,31 @@ func (re *RuleEngine) EvaluateRuleset(rulesetName string) (RulesetResult, error)
 	default:
 		// Default to AND logic
 		result.Passed = true
-		for _, ruleResult := range result.RuleResults {
-			if !ruleResult.Passed {
+		for name, ruleResult := range result.RuleResults {
+			if !ruleResult.Passed && !ruleResult.Skipped && !optional[name] {
 				result.Passed = false
 			}
 		}
 	}
+	for _, name := range result.Order {
+		ruleResult := result.RuleResults[name]
+		if (optional[name] && !ruleResult.Passed && !ruleResult.Skipped) || re.missingRules[name] {
+			result.Warnings = append(result.Warnings, name)
+		}
+	}
+
+	// A veto rule which did not pass fails the ruleset whatever its selector
+	for _, name := range ruleset.Vetoes {
+		if ruleResult, ok := result.RuleResults[name]; ok && !ruleResult.Passed && !ruleResult.Skipped {
+			result.Passed = false
+			result.VetoedBy = name
+			break
+		}
+	}
 
 	var errorMessage error
 	if !result.Passed {
+		result.ReasonCodes = reasonCodes(result.RuleResults, optional)
 		errorMessage = fmt.Errorf("ruleset '%s' did not pass evaluation", rulesetName)
 		if msg, ok := re.config.ErrorHandling.CustomErrorMessages[rulesetName]; ok {
 			errorMessage = errors.New(msg)
@@ -240,6 +749,9 @@ func (re *RuleEngine) EvaluateRuleset(rulesetName string) (RulesetResult, error)
 
 	result.Duration = time.Since(start)
 	result.Error = errorMessage
+	if degradedErr != nil {
+		applyFallback(&result, ruleset.Fallback, degradedErr)
+	}
 	return result, nil
 }
 
@@ -250,61 +762,285 @@ func (re *RuleEngine) EvaluateRuleset(rulesetName string) (RulesetResult, error)
 //		execution will be halted in these cases
 //		If the rule evaluates to false, a RuleResult with Passed=false is returned and nil error
 //	    If the rule evaluates to true, a RuleResult with Passed=true is returned and nil error
-func (re *RuleEngine) EvaluateAllRulesets() (map[string]RulesetResult, error) {
+//		Rules shared between rulesets are evaluated once per call, see Plan
+func (re *RuleEngine) EvaluateAllRulesets(opts ...EvalOption) (map[string]RulesetResult, error) {
+	if active := re.Active(); active != re {
+		return active.EvaluateAllRulesets(opts...)
+	}
+	release, shed, err := re.enqueue(true)
+	if shed || err != nil {
+		if shed {
+			return re.shedResults(re.rulesetNames())
+		}
+		return nil, err
+	}
+	defer release()
+	eval, err := re.newEvaluation(opts)
+	if err != nil {
+		return nil, err
+	}
+	// Rules shared between rulesets are evaluated once, see Plan
+	eval.memo = newRuleMemo()
 	results := make(map[string]RulesetResult)
 	ticker := time.NewTicker(re.policy.MaxExecutionTime)
 	defer ticker.Stop()
-	for rulesetName := range re.config.Rulesets {
+	for _, rulesetName := range re.rulesetNames() {
 		select {
 		case <-ticker.C:
-			return results, fmt.Errorf("timed out waiting for ruleset %s", rulesetName)
+			err := fmt.Errorf("timed out waiting for ruleset %s", rulesetName)
+			re.fallbackRemaining(results, re.rulesetNames(), err)
+			return results, err
 		default:
 		}
 
-		result, err := re.EvaluateRuleset(rulesetName)
+		result, err := re.evaluateRuleset(rulesetName, eval)
 		results[rulesetName] = result
 		// This is only expected to happen if the ruleset name is missing
 		if err != nil {
 			return results, err
 		}
+		if re.failFastAll {
+			if cause := re.fatalFailure(result); cause != nil {
+				return results, fmt.Errorf("%w: ruleset '%s': %w", ErrFailFast, rulesetName, cause)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// EvaluateMany evaluates each named ruleset against its own context in one pass
+// Returns a map of ruleset names to their evaluation results
+//
+//	All rulesets share the max execution time budget of the execution policy,
+//	errors are returned if a ruleset is not found or if there is a timeout,
+//	execution will be halted in these cases and the results so far are returned
+//	The provided options apply to every evaluation, e.g. WithGlobalOverrides
+func (re *RuleEngine) EvaluateMany(contexts map[string]map[string]interface{}, opts ...EvalOption) (map[string]RulesetResult, error) {
+	if active := re.Active(); active != re {
+		return active.EvaluateMany(contexts, opts...)
+	}
+	names := make([]string, 0, len(contexts))
+	for name := range contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	release, shed, err := re.enqueue(true)
+	if shed || err != nil {
+		if shed {
+			return re.shedResults(names)
+		}
+		return nil, err
+	}
+	defer release()
+
+	results := make(map[string]RulesetResult, len(contexts))
+	ticker := time.NewTicker(re.policy.MaxExecutionTime)
+	defer ticker.Stop()
+	for _, rulesetName := range names {
+		select {
+		case <-ticker.C:
+			err := fmt.Errorf("timed out waiting for ruleset %s", rulesetName)
+			re.fallbackRemaining(results, names, err)
+			return results, err
+		default:
+		}
+
+		evalOpts := make([]EvalOption, 0, len(opts)+1)
+		evalOpts = append(evalOpts, opts...)
+		evalOpts = append(evalOpts, WithEvalContext(contexts[rulesetName]))
+		eval, err := re.newEvaluation(evalOpts)
+		if err != nil {
+			return results, err
+		}
+		result, err := re.evaluateRuleset(rulesetName, eval)
+		if err != nil {
+			return results, err
+		}
+		results[rulesetName] = result
 	}
 
 	return results, nil
 }
 
+// Environments returns the names of the environments the config defines overrides for, in sorted order
+func (re *RuleEngine) Environments() []string {
+	if active := re.Active(); active != re {
+		return active.Environments()
+	}
+	names := make([]string, 0, len(re.config.Environments))
+	for name := range re.config.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rulesetNames returns the names of all configured rulesets in sorted order
+func (re *RuleEngine) rulesetNames() []string {
+	names := make([]string, 0, len(re.config.Rulesets))
+	for name := range re.config.Rulesets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // compileRules parses, checks and compiles all rule expressions into `cel.Program`
+//
+//	Every rule is compiled even if some fail, all failures are returned together as CompileErrors
 func (re *RuleEngine) compileRules() error {
+	var errs CompileErrors
 	// Compile individual rules
-	for name, rule := range re.config.Rules {
-		program, err := re.compileExpression(rule.Expression)
+	for _, name := range re.ruleNames() {
+		rule := re.config.Rules[name]
+		program, err := re.compileRule(name, rule)
 		if err != nil {
-			return fmt.Errorf("failed to compile program for rule '%s': %w", name, err)
+			compileErr := &CompileError{
+				RuleName:   name,
+				Expression: rule.Expression,
+				Source:     re.sources[name],
+				Err:        err,
+			}
+			var exprErr *CompileError
+			if errors.As(err, &exprErr) {
+				compileErr.Issues = exprErr.Issues
+				compileErr.Err = exprErr.Err
+			}
+			errs = append(errs, compileErr)
+			continue
 		}
-		re.programs[name] = program
+		re.programs.put(name, program)
 		parents, err := re.getRuleParents(rule)
 		if err != nil {
-			return fmt.Errorf("failed to find parent rules for rule '%s': %w", name, err)
+			errs = append(errs, &CompileError{
+				RuleName: name,
+				Source:   re.sources[name],
+				Err:      fmt.Errorf("failed to find parent rules: %w", err),
+			})
+			continue
 		}
 		re.parents[name] = parents
+		re.counters[name] = &ruleCounters{}
+		if re.breakerConfig != nil {
+			re.breakers[name] = &circuitBreaker{}
+		}
 	}
 
+	errs = append(errs, re.compileGuards()...)
+	errs = re.quarantine(errs)
+	errs = append(errs, re.compileActivations()...)
+	errs = append(errs, re.compilePreconditions()...)
+
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
+// compileRule compiles the expression of a rule, recording its estimated cost and enforcing the cost budget
+func (re *RuleEngine) compileRule(name string, rule Rule) (cel.Program, error) {
+	checked, err := re.checkExpression(rule.Expression)
+	if err != nil {
+		return nil, err
+	}
+	cost, err := re.estimateCost(checked)
+	if err != nil {
+		return nil, err
+	}
+	if re.costBudget > 0 && cost.Max > re.costBudget {
+		return nil, fmt.Errorf("%w: estimated cost %d exceeds budget %d", ErrCostBudgetExceeded, cost.Max, re.costBudget)
+	}
+	retry, err := parseRetryPolicy(rule.Retry)
+	if err != nil {
+		return nil, err
+	}
+	if re.explainers != nil {
+		ex, err := re.newExplainer(rule.Expression, checked)
+		if err != nil {
+			return nil, err
+		}
+		re.explainers[name] = ex
+	}
+	re.costs[name] = cost
+	re.retries[name] = retry
+	re.footprints[name] = newFootprint(checked)
+	if re.resultCache != nil {
+		re.resultCache.prepare(re, name, checked)
+	}
+	return re.newProgram(rule.Expression, checked)
+}
+
 // func compileExpression parses, checks and compiles a single CEL expression into `cel.Program`
 func (re *RuleEngine) compileExpression(expression string) (cel.Program, error) {
-	ast, issues := re.env.Compile(expression)
+	checked, err := re.checkExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return re.newProgram(expression, checked)
+}
+
+// checkExpression parses and checks a single CEL expression, issues are returned as a *CompileError
+func (re *RuleEngine) checkExpression(expression string) (*cel.Ast, error) {
+	if re.precompiled != nil {
+		if checked, ok := re.precompiled.asts[expression]; ok {
+			return checked, nil
+		}
+	}
+	if re.compileCache != nil {
+		if checked, ok := re.compileCache.load(expression); ok {
+			return checked, nil
+		}
+	}
+	checked, issues := re.env.Compile(expression)
 	if issues != nil && issues.Err() != nil {
-		return nil, fmt.Errorf("failed to compile expression '%s': %w", expression, issues.Err())
+		compileErr := &CompileError{
+			Expression: expression,
+			Err:        fmt.Errorf("failed to compile expression '%s': %w", expression, issues.Err()),
+		}
+		for _, issue := range issues.Errors() {
+			compileErr.Issues = append(compileErr.Issues, CompileIssue{
+				Message: issue.Message,
+				Line:    issue.Location.Line(),
+				Column:  issue.Location.Column(),
+			})
+		}
+		return nil, compileErr
+	}
+	if re.compileCache != nil {
+		// The cache is best effort, a failed write only costs compiling again on the next start
+		_ = re.compileCache.store(expression, checked)
+	}
+	return checked, nil
+}
+
+// newProgram creates the `cel.Program` of a checked expression
+func (re *RuleEngine) newProgram(expression string, checked *cel.Ast) (cel.Program, error) {
+	indexed, err := re.indexLookups(checked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index lookups of expression '%s': %w", expression, err)
+	}
+	if indexed != nil {
+		checked = indexed
 	}
 	evalOpts := cel.OptExhaustiveEval
 	if re.optimise {
 		evalOpts = cel.OptOptimize
 	}
-	program, err := re.env.Program(ast, cel.EvalOptions(evalOpts))
+	program, err := re.env.Program(checked, append(re.programOptions(), cel.EvalOptions(evalOpts))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create program for expression '%s': %w", expression, err)
 	}
+	program = re.sandboxed(program)
+	// Sampled evaluations use a separate cost tracking program, exhaustive evaluation does not report cost
+	if re.profiler != nil {
+		profiled, err := re.env.Program(checked,
+			append(re.programOptions(), cel.EvalOptions(cel.OptOptimize, cel.OptTrackCost))...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create profiled program for expression '%s': %w", expression, err)
+		}
+		program = &profiledProgram{Program: program, profiled: re.sandboxed(profiled)}
+	}
 	return program, nil
 }
 
@@ -1,6 +1,7 @@
 package ruleengine
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -346,3 +347,79 @@ func BenchmarkNewRuleEngineOptimise(t *testing.B) {
 		})
 	}
 }
+
+func BenchmarkRuleEngine_ListIndex(b *testing.B) {
+	domains := make([]interface{}, 100000)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("domain%d.com", i)
+	}
+	ctx := map[string]interface{}{"user": map[string]interface{}{"domain": "missing.com"}}
+	tests := []struct {
+		name    string
+		minSize int
+	}{
+		{name: "indexed", minSize: defaultListIndexSize},
+		{name: "scan", minSize: 0},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			re, err := NewBuilder().
+				WithConfig(&RulesetConfig{
+					Globals: map[string]interface{}{"allowed_domains": domains},
+					Rules: map[string]Rule{
+						"domain": {Expression: "globals.allowed_domains.exists(d, d == user.domain)"},
+					},
+					ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+					ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+				}).
+				WithVariables("user").
+				WithOptions(WithListIndex(tt.minSize)).
+				Build()
+			if err != nil {
+				b.Fatalf("failed to create rules engine: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = re.EvaluateRule("domain", WithEvalContext(ctx))
+			}
+		})
+	}
+}
+
+func BenchmarkRuleEngine_EvaluateRulesetBool(b *testing.B) {
+	re, err := NewBuilder().WithConfig(hotPathConfig()).WithVariables("user").Build()
+	if err != nil {
+		b.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := map[string]interface{}{
+		"user": map[string]interface{}{"age": 30, "verified": true, "phone": "123", "tier": "premium"},
+	}
+	b.Run("EvaluateRuleset", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = re.EvaluateRuleset("signup", WithEvalContext(ctx))
+		}
+	})
+	b.Run("EvaluateRulesetBool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = re.EvaluateRulesetBool("signup", ctx)
+		}
+	})
+}
+
+func BenchmarkRuleEngine_EvaluateRulesetInto(b *testing.B) {
+	re, err := NewBuilder().WithConfig(hotPathConfig()).WithVariables("user").Build()
+	if err != nil {
+		b.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"age": 30, "verified": true, "phone": "123", "tier": "premium"},
+	})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst := AcquireResult()
+		_ = re.EvaluateRulesetInto("signup", dst, ctx)
+		ReleaseResult(dst)
+	}
+}
@@ -965,7 +965,7 @@ func TestRuleEngine_EvaluateRuleset(t *testing.T) {
 			}
 			diff := cmp.Diff(got, tt.want,
 				cmpopts.IgnoreFields(RuleResult{}, "Duration"),
-				cmpopts.IgnoreFields(RulesetResult{}, "Duration"),
+				cmpopts.IgnoreFields(RulesetResult{}, "Duration", "Order"),
 				cmp.Comparer(func(x, y error) bool {
 					return (x == nil && y == nil) || (x != nil && y != nil && x.Error() == y.Error())
 				}),
@@ -1294,7 +1294,7 @@ func TestRuleEngine_EvaluateAllRulesets(t *testing.T) {
 			}
 			diff := cmp.Diff(got, tt.want,
 				cmpopts.IgnoreFields(RuleResult{}, "Duration"),
-				cmpopts.IgnoreFields(RulesetResult{}, "Duration"),
+				cmpopts.IgnoreFields(RulesetResult{}, "Duration", "Order"),
 				cmp.Comparer(func(x, y error) bool {
 					return (x == nil && y == nil) || (x != nil && y != nil && x.Error() == y.Error())
 				}),
@@ -1,6 +1,7 @@
 package ruleengine
 
 import (
+	"sort"
 	"time"
 )
 
@@ -14,6 +15,15 @@ type RuleResult struct {
 	Error error
 	// Duration is the time taken to evaluate the rule
 	Duration time.Duration
+	// Skipped indicates the rule's when clause did not hold, skipped rules are excluded from ruleset aggregation
+	Skipped bool
+	// Explanation renders the failing expression with its evaluated values, empty unless WithExplanations is used
+	Explanation string
+	// Suggestions are the minimal changes to the context for the failing rule to pass, e.g. "user.age must increase
+	// by 3", derived from its simple comparisons when WithExplanations is used
+	Suggestions []Suggestion
+	// ReasonCode is the configured reason code of a rule which did not pass, e.g. "KYC_001"
+	ReasonCode string
 }
 
 // RulesetResult represents the outcome of a ruleset evaluation
@@ -28,4 +38,94 @@ type RulesetResult struct {
 	Error error
 	// Duration is the time taken to evaluate the ruleset
 	Duration time.Duration
+	// Degraded indicates the ruleset errored or exceeded its time budget and its fallback decision was applied
+	Degraded bool
+	// Fallback is the fallback decision applied when Degraded, e.g. "allow", "deny" or a custom value
+	Fallback string
+	// Skipped indicates the ruleset precondition did not hold, so none of its rules were evaluated and it did not pass
+	Skipped bool
+	// ReasonCodes are the sorted, unique reason codes of the rules which did not pass, set when the ruleset did not pass
+	ReasonCodes []string
+	// Metadata holds values added by result transformers, e.g. tenant information, nil unless set
+	Metadata map[string]interface{}
+	// Order is the names of the RuleResults in the order they were evaluated
+	Order []string
+	// VetoedBy is the name of the veto rule which failed the ruleset, see Ruleset.Vetoes, empty if none did
+	VetoedBy string
+	// Warnings are the names of the optional rules which did not pass and of the skipped missing rules, in evaluation
+	// order, see Ruleset.Optional and WithSkipMissingRules
+	Warnings []string
+}
+
+// Ordered returns the rule results in evaluation order, results missing from Order follow sorted by rule name
+//
+//	Unlike ranging over RuleResults, the order is the same on every call, keeping logs, golden files and diffs stable
+func (r RulesetResult) Ordered() []RuleResult {
+	ordered := make([]RuleResult, 0, len(r.RuleResults))
+	seen := make(map[string]bool, len(r.RuleResults))
+	for _, name := range r.Order {
+		if result, ok := r.RuleResults[name]; ok && !seen[name] {
+			seen[name] = true
+			ordered = append(ordered, result)
+		}
+	}
+	rest := make([]string, 0, len(r.RuleResults)-len(ordered))
+	for name := range r.RuleResults {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		ordered = append(ordered, r.RuleResults[name])
+	}
+	return ordered
+}
+
+// OrderedResults returns the results of EvaluateAllRulesets or EvaluateMany sorted by ruleset name,
+// the order they are evaluated in
+func OrderedResults(results map[string]RulesetResult) []RulesetResult {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ordered := make([]RulesetResult, 0, len(names))
+	for _, name := range names {
+		ordered = append(ordered, results[name])
+	}
+	return ordered
+}
+
+// reasonCodes returns the sorted, unique reason codes of the rule results not ignored, nil if there are none
+func reasonCodes(results map[string]RuleResult, ignored map[string]bool) []string {
+	seen := make(map[string]bool)
+	var codes []string
+	for name, result := range results {
+		if result.ReasonCode != "" && !seen[result.ReasonCode] && !ignored[name] {
+			seen[result.ReasonCode] = true
+			codes = append(codes, result.ReasonCode)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// EvaluationError is the RuleResult error of a rule whose expression failed to evaluate,
+// as opposed to a rule that evaluated and did not pass
+type EvaluationError struct {
+	// RuleName is the name of the rule which failed to evaluate
+	RuleName string
+	// Err is the underlying CEL evaluation error
+	Err error
+}
+
+// Error implements error, returning the underlying CEL error message
+func (e *EvaluationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying CEL evaluation error
+func (e *EvaluationError) Unwrap() error {
+	return e.Err
 }
@@ -0,0 +1,103 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRulesetResult_Ordered(t *testing.T) {
+	tests := []struct {
+		name   string
+		result RulesetResult
+		want   []string
+	}{
+		{
+			name: "success - evaluation order",
+			result: RulesetResult{
+				RuleResults: map[string]RuleResult{"c": {RuleName: "c"}, "a": {RuleName: "a"}, "b": {RuleName: "b"}},
+				Order:       []string{"c", "a", "b"},
+			},
+			want: []string{"c", "a", "b"},
+		},
+		{
+			name: "success - results missing from the order sorted last",
+			result: RulesetResult{
+				RuleResults: map[string]RuleResult{"c": {RuleName: "c"}, "a": {RuleName: "a"}, "b": {RuleName: "b"}},
+				Order:       []string{"b", "missing", "b"},
+			},
+			want: []string{"b", "a", "c"},
+		},
+		{
+			name:   "success - empty",
+			result: RulesetResult{},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, result := range tt.result.Ordered() {
+				got = append(got, result.RuleName)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Ordered() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOrderedResults(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"age": 20, "email": "test@example.com", "status": "active"},
+	})
+	names := func(results map[string]RulesetResult) []string {
+		got := make([]string, 0, len(results))
+		for _, result := range OrderedResults(results) {
+			got = append(got, result.RulesetName)
+			for _, rule := range result.Ordered() {
+				got = append(got, result.RulesetName+"/"+rule.RuleName)
+			}
+		}
+		return got
+	}
+
+	first, err := re.EvaluateAllRulesets(ctx)
+	if err != nil {
+		t.Fatalf("EvaluateAllRulesets() error = %v", err)
+	}
+	want := names(first)
+	for i := 0; i < 10; i++ {
+		results, err := re.EvaluateAllRulesets(ctx)
+		if err != nil {
+			t.Fatalf("EvaluateAllRulesets() error = %v", err)
+		}
+		if diff := cmp.Diff(want, names(results)); diff != "" {
+			t.Fatalf("OrderedResults() changed between evaluations (-want +got):\n%s", diff)
+		}
+	}
+	for _, result := range OrderedResults(first) {
+		if diff := cmp.Diff(re.config.Rulesets[result.RulesetName].Rules, result.Order); diff != "" {
+			t.Errorf("Order of %s mismatch (-want +got):\n%s", result.RulesetName, diff)
+		}
+	}
+
+	// OR rulesets stopping at the first pass evaluate the highest priority rule first
+	re, err = NewBuilder().WithConfigFile("./testdata/priority_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	result, err := re.EvaluateRuleset("by_priority", WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"vip": true, "tier": "basic"},
+	}))
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"premium", "vip"}, result.Order); diff != "" {
+		t.Errorf("Order mismatch (-want +got):\n%s", diff)
+	}
+}
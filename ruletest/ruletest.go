@@ -0,0 +1,200 @@
+// Package ruletest provides golden file helpers for regression testing rule configs
+//
+//	func TestRules(t *testing.T) {
+//		engine, err := ruleengine.NewBuilder().WithConfigFile("rules.yml").WithVariables("user").Build()
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		ruletest.Golden(t, engine, "testdata/contexts")
+//	}
+//
+// Every `<name>.json` context fixture in the directory is evaluated and compared to `<name>.golden`, run
+// `go test -update` to write the golden files after an intended change and review their diff.
+package ruletest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mobanhawi/ruleengine"
+)
+
+// GoldenExt is the extension of golden files, which sit next to their context fixture
+const GoldenExt = ".golden"
+
+// update rewrites the golden files with the current results instead of comparing them
+var update = flag.Bool("update", false, "update ruletest golden files")
+
+// Option configures Golden
+type Option func(*config)
+
+// config holds the settings of a Golden run
+type config struct {
+	rulesets    []string
+	evalOptions []ruleengine.EvalOption
+	update      bool
+}
+
+// WithRulesets evaluates only the named rulesets, every ruleset is evaluated by default
+func WithRulesets(names ...string) Option {
+	return func(c *config) {
+		c.rulesets = append(c.rulesets, names...)
+	}
+}
+
+// WithEvalOptions applies evaluation options to every fixture, e.g. ruleengine.WithEvalProfile
+//
+//	WithEvalContext is applied after these options with the fixture context
+func WithEvalOptions(opts ...ruleengine.EvalOption) Option {
+	return func(c *config) {
+		c.evalOptions = append(c.evalOptions, opts...)
+	}
+}
+
+// WithUpdate writes the golden files instead of comparing them, regardless of the -update flag
+func WithUpdate() Option {
+	return func(c *config) {
+		c.update = true
+	}
+}
+
+// Golden evaluates the engine against every `*.json` context fixture in dir, each in a subtest named after the
+// fixture, and compares the results to the golden files
+//
+//	Golden files hold the indented JSON results of the rulesets, without durations so they are stable across runs.
+//	A missing golden file fails the test unless golden files are being updated
+func Golden(t *testing.T, engine *ruleengine.RuleEngine, dir string, opts ...Option) {
+	t.Helper()
+	c := config{update: *update}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	fixtures, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list context fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("no context fixtures found in %s", dir)
+	}
+	for _, fixture := range fixtures {
+		t.Run(strings.TrimSuffix(filepath.Base(fixture), ".json"), func(t *testing.T) {
+			checkGolden(t, engine, fixture, c)
+		})
+	}
+}
+
+// checkGolden evaluates the fixture and compares its results to, or writes them to, its golden file
+func checkGolden(t testing.TB, engine *ruleengine.RuleEngine, fixture string, c config) {
+	t.Helper()
+	got, err := Snapshot(engine, fixture, c.rulesets, c.evalOptions...)
+	if err != nil {
+		t.Fatalf("failed to evaluate %s: %v", fixture, err)
+	}
+	golden := strings.TrimSuffix(fixture, ".json") + GoldenExt
+	if c.update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("golden file %s not found, run go test -update to create it", golden)
+	}
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(got), string(want)); diff != "" {
+		t.Errorf("results of %s differ from %s (-got +want):\n%s", fixture, golden, diff)
+	}
+}
+
+// result is the JSON representation of a rule or ruleset result in golden files
+type result struct {
+	Passed      bool              `json:"passed"`
+	Error       string            `json:"error,omitempty"`
+	Degraded    bool              `json:"degraded,omitempty"`
+	Fallback    string            `json:"fallback,omitempty"`
+	Skipped     bool              `json:"skipped,omitempty"`
+	ReasonCode  string            `json:"reason_code,omitempty"`
+	ReasonCodes []string          `json:"reason_codes,omitempty"`
+	Rules       map[string]result `json:"rules,omitempty"`
+}
+
+// Snapshot evaluates the rulesets, every ruleset when none are named, against the JSON context in the fixture file
+// and returns the results as the indented JSON written to golden files
+func Snapshot(engine *ruleengine.RuleEngine, fixture string, rulesets []string, opts ...ruleengine.EvalOption) ([]byte, error) {
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context fixture: %w", err)
+	}
+	ctx := make(map[string]interface{})
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		return nil, fmt.Errorf("failed to parse context fixture %s: %w", fixture, err)
+	}
+	opts = append(opts[:len(opts):len(opts)], ruleengine.WithEvalContext(ctx))
+
+	var results map[string]ruleengine.RulesetResult
+	if len(rulesets) == 0 {
+		if results, err = engine.EvaluateAllRulesets(opts...); err != nil {
+			return nil, err
+		}
+	} else {
+		results = make(map[string]ruleengine.RulesetResult, len(rulesets))
+		for _, name := range rulesets {
+			if results[name], err = engine.EvaluateRuleset(name, opts...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out := make(map[string]result, len(results))
+	for name, r := range results {
+		out[name] = rulesetResult(r)
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return nil, fmt.Errorf("failed to encode results: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// rulesetResult converts a RulesetResult into its golden representation
+func rulesetResult(r ruleengine.RulesetResult) result {
+	out := result{
+		Passed:      r.Passed,
+		Error:       errorString(r.Error),
+		Degraded:    r.Degraded,
+		Fallback:    r.Fallback,
+		Skipped:     r.Skipped,
+		ReasonCodes: r.ReasonCodes,
+		Rules:       make(map[string]result, len(r.RuleResults)),
+	}
+	for name, rule := range r.RuleResults {
+		out.Rules[name] = result{
+			Passed:     rule.Passed,
+			Error:      errorString(rule.Error),
+			Skipped:    rule.Skipped,
+			ReasonCode: rule.ReasonCode,
+		}
+	}
+	return out
+}
+
+// errorString returns the message of err, empty if nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,132 @@
+package ruletest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mobanhawi/ruleengine"
+)
+
+// recorder is a testing.TB recording failures, Fatalf stops the calling goroutine like testing.T
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	runtime.Goexit()
+}
+
+// run calls checkGolden on a new goroutine so Fatalf can stop it
+func (r *recorder) run(engine *ruleengine.RuleEngine, fixture string, c config) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		checkGolden(r, engine, fixture, c)
+	}()
+	<-done
+}
+
+func newEngine(t *testing.T) *ruleengine.RuleEngine {
+	engine, err := ruleengine.NewBuilder().
+		WithConfigFile("../testdata/rules.yml").
+		WithVariables("user", "request").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	return engine
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, newEngine(t), "testdata/contexts")
+}
+
+func TestCheckGolden(t *testing.T) {
+	engine := newEngine(t)
+	tests := []struct {
+		name      string
+		golden    string
+		rulesets  []string
+		update    bool
+		wantError string
+		wantFatal bool
+	}{
+		{
+			name:     "success - matching golden file",
+			golden:   "{\n  \"request_throttling\": {\n    \"passed\": true,\n    \"rules\": {\n      \"rate_limiting\": {\n        \"passed\": true\n      },\n      \"user_tier\": {\n        \"passed\": false,\n        \"error\": \"rule 'user_tier' did not pass evaluation\"\n      }\n    }\n  }\n}\n",
+			rulesets: []string{"request_throttling"},
+		},
+		{
+			name:     "success - update writes golden file",
+			rulesets: []string{"request_throttling"},
+			update:   true,
+		},
+		{
+			name:      "fail - results differ",
+			golden:    "{}\n",
+			rulesets:  []string{"request_throttling"},
+			wantError: "differ from",
+		},
+		{
+			name:      "fail - missing golden file",
+			wantError: "run go test -update",
+			wantFatal: true,
+		},
+		{
+			name:      "fail - unknown ruleset",
+			golden:    "{}\n",
+			rulesets:  []string{"unknown"},
+			wantError: "ruleset 'unknown' not found",
+			wantFatal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fixture := filepath.Join(dir, "context.json")
+			if err := os.WriteFile(fixture, []byte(`{"request": {"attempt": 1}, "user": {"tier": "free"}}`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join(dir, "context"+GoldenExt)
+			if tt.golden != "" {
+				if err := os.WriteFile(golden, []byte(tt.golden), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := &recorder{}
+			r.run(engine, fixture, config{rulesets: tt.rulesets, update: tt.update})
+			if r.fatal != tt.wantFatal {
+				t.Errorf("fatal = %v, want %v, errors %v", r.fatal, tt.wantFatal, r.errors)
+			}
+			if tt.wantError == "" && len(r.errors) > 0 {
+				t.Errorf("errors = %v, want none", r.errors)
+			}
+			if tt.wantError != "" && (len(r.errors) == 0 || !strings.Contains(r.errors[0], tt.wantError)) {
+				t.Errorf("errors = %v, want %q", r.errors, tt.wantError)
+			}
+			if tt.update {
+				// The written golden file must match on the next run
+				r = &recorder{}
+				r.run(engine, fixture, config{rulesets: tt.rulesets})
+				if len(r.errors) > 0 {
+					t.Errorf("errors after update = %v, want none", r.errors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// ErrIterationLimit is the evaluation error of expressions iterating more than the sandbox allows, see
+// SandboxProfile.MaxComprehensionIterations
+var ErrIterationLimit = errors.New("comprehension iteration limit exceeded")
+
+// SandboxProfile restricts the CEL features expressions may use, e.g. for rules written by semi-trusted tenants
+//
+//	Expressions using a disabled feature fail to compile, iteration limits are enforced while evaluating. Zero
+//	values leave a feature unrestricted
+type SandboxProfile struct {
+	// DisableRegex rejects expressions calling `matches`
+	DisableRegex bool `yaml:"disable_regex"`
+	// DisableStringConcatInLoops rejects string concatenation inside the loops of comprehensions, e.g.
+	// `list.map(x, x + "!")`, which builds a new string on every iteration
+	DisableStringConcatInLoops bool `yaml:"disable_string_concat_in_loops"`
+	// MaxComprehensionNesting is the maximum depth of nested comprehensions, e.g. 1 rejects `a.all(x, b.exists(y, ...))`
+	MaxComprehensionNesting int `yaml:"max_comprehension_nesting"`
+	// MaxComprehensionIterations is the maximum number of comprehension iterations of one evaluation of an
+	// expression, across all its comprehensions
+	MaxComprehensionIterations int `yaml:"max_comprehension_iterations"`
+}
+
+// WithSandbox enforces the sandbox profile on every expression, whatever the sandbox the config selects
+//
+//	Hosts loading configs from tenants use it so a config cannot lift its own restrictions
+func WithSandbox(profile SandboxProfile) Option {
+	return func(re *RuleEngine) {
+		re.sandbox = &profile
+	}
+}
+
+// resolveSandbox returns the sandbox profile the config selects, nil if none
+func (rc *RulesetConfig) resolveSandbox() (*SandboxProfile, error) {
+	if rc.Sandbox == "" {
+		return nil, nil
+	}
+	profile, ok := rc.SandboxProfiles[rc.Sandbox]
+	if !ok {
+		return nil, fmt.Errorf("sandbox profile '%s' not found", rc.Sandbox)
+	}
+	return &profile, nil
+}
+
+// validate checks the limits of the profile are not negative
+func (p *SandboxProfile) validate() error {
+	if p.MaxComprehensionNesting < 0 {
+		return fmt.Errorf("max_comprehension_nesting must not be negative, got %d", p.MaxComprehensionNesting)
+	}
+	if p.MaxComprehensionIterations < 0 {
+		return fmt.Errorf("max_comprehension_iterations must not be negative, got %d", p.MaxComprehensionIterations)
+	}
+	return nil
+}
+
+// envOptions returns the AST validators rejecting the features the profile disables
+func (p *SandboxProfile) envOptions() []cel.EnvOption {
+	var validators []cel.ASTValidator
+	if p.DisableRegex {
+		validators = append(validators, disabledFunctionValidator{function: overloads.Matches})
+	}
+	if p.DisableStringConcatInLoops {
+		validators = append(validators, loopConcatValidator{})
+	}
+	if p.MaxComprehensionNesting > 0 {
+		validators = append(validators, cel.ValidateComprehensionNestingLimit(p.MaxComprehensionNesting))
+	}
+	if len(validators) == 0 {
+		return nil
+	}
+	return []cel.EnvOption{cel.ASTValidators(validators...)}
+}
+
+// disabledFunctionValidator rejects calls to a function
+type disabledFunctionValidator struct {
+	function string
+}
+
+// Name implements cel.ASTValidator
+func (v disabledFunctionValidator) Name() string {
+	return "sandbox.disabled." + v.function
+}
+
+// Validate implements cel.ASTValidator
+func (v disabledFunctionValidator) Validate(_ *cel.Env, _ cel.ValidatorConfig, a *ast.AST, iss *cel.Issues) {
+	for _, call := range ast.MatchDescendants(ast.NavigateAST(a), ast.FunctionMatcher(v.function)) {
+		iss.ReportErrorAtID(call.ID(), "function '%s' is disabled by the sandbox", v.function)
+	}
+}
+
+// loopConcatValidator rejects string concatenation in the loop condition and step of comprehensions
+type loopConcatValidator struct{}
+
+// Name implements cel.ASTValidator
+func (loopConcatValidator) Name() string {
+	return "sandbox.loop_concat"
+}
+
+// Validate implements cel.ASTValidator
+func (loopConcatValidator) Validate(_ *cel.Env, _ cel.ValidatorConfig, a *ast.AST, iss *cel.Issues) {
+	reported := make(map[int64]bool)
+	for _, comp := range ast.MatchDescendants(ast.NavigateAST(a), ast.KindMatcher(ast.ComprehensionKind)) {
+		loop := comp.AsComprehension()
+		for _, e := range []ast.Expr{loop.LoopCondition(), loop.LoopStep()} {
+			for _, add := range ast.MatchDescendants(ast.NavigateExpr(a, e), ast.FunctionMatcher(operators.Add)) {
+				if reported[add.ID()] || !add.Type().IsExactType(types.StringType) {
+					continue
+				}
+				reported[add.ID()] = true
+				iss.ReportErrorAtID(add.ID(), "string concatenation in a comprehension is disabled by the sandbox")
+			}
+		}
+	}
+}
+
+// sandboxedProgram is a program whose evaluations are limited to a number of comprehension iterations
+type sandboxedProgram struct {
+	cel.Program
+	maxIterations int
+}
+
+// sandboxed limits the comprehension iterations of program as the sandbox requires, program itself if unlimited
+func (re *RuleEngine) sandboxed(program cel.Program) cel.Program {
+	if re.sandbox == nil || re.sandbox.MaxComprehensionIterations == 0 {
+		return program
+	}
+	return &sandboxedProgram{Program: program, maxIterations: re.sandbox.MaxComprehensionIterations}
+}
+
+// Eval implements cel.Program, interrupting comprehensions once the iterations are exhausted
+func (p *sandboxedProgram) Eval(input any) (ref.Val, *cel.EvalDetails, error) {
+	var activation interpreter.Activation
+	switch in := input.(type) {
+	case interpreter.Activation:
+		activation = in
+	default:
+		var err error
+		if activation, err = interpreter.NewActivation(input); err != nil {
+			return nil, nil, err
+		}
+	}
+	budget := &iterationBudget{Activation: activation, remaining: p.maxIterations}
+	out, details, err := p.Program.Eval(budget)
+	if budget.exceeded {
+		return nil, details, fmt.Errorf("%w: more than %d iterations", ErrIterationLimit, p.maxIterations)
+	}
+	return out, details, err
+}
+
+// iterationBudget is an activation counting the comprehension iterations, comprehensions check the `#interrupted`
+// variable once per iteration when the program is created with cel.InterruptCheckFrequency
+type iterationBudget struct {
+	interpreter.Activation
+	remaining int
+	exceeded  bool
+}
+
+// ResolveName implements interpreter.Activation
+func (b *iterationBudget) ResolveName(name string) (any, bool) {
+	if name != "#interrupted" {
+		return b.Activation.ResolveName(name)
+	}
+	if b.remaining--; b.remaining < 0 {
+		b.exceeded = true
+		return true, true
+	}
+	return nil, false
+}
+
+// Parent implements interpreter.Activation
+func (b *iterationBudget) Parent() interpreter.Activation {
+	return b.Activation
+}
@@ -0,0 +1,116 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSandboxProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		sandbox    string
+		profiles   map[string]SandboxProfile
+		option     *SandboxProfile
+		wantErr    bool
+		wantEval   error
+		wantPassed bool
+	}{
+		{
+			name:       "success - unrestricted",
+			expression: "user.name.matches('^a') && user.tags.map(t, t + '!').size() == 3",
+			wantPassed: true,
+		},
+		{
+			name:       "success - allowed features",
+			expression: "user.tags.all(t, t != '')",
+			sandbox:    "tenant",
+			profiles: map[string]SandboxProfile{"tenant": {
+				DisableRegex: true, DisableStringConcatInLoops: true, MaxComprehensionNesting: 1, MaxComprehensionIterations: 3,
+			}},
+			wantPassed: true,
+		},
+		{
+			name:       "success - string concatenation outside loops",
+			expression: "user.name + '!' == 'alice!'",
+			option:     &SandboxProfile{DisableStringConcatInLoops: true},
+			wantPassed: true,
+		},
+		{
+			name:       "fail - regex disabled",
+			expression: "user.name.matches('^a')",
+			sandbox:    "tenant",
+			profiles:   map[string]SandboxProfile{"tenant": {DisableRegex: true}},
+			wantErr:    true,
+		},
+		{
+			name:       "fail - string concatenation in loop disabled",
+			expression: "user.tags.map(t, t + '!').size() == 3",
+			option:     &SandboxProfile{DisableStringConcatInLoops: true},
+			wantErr:    true,
+		},
+		{
+			name:       "fail - comprehension nesting limit",
+			expression: "user.tags.all(t, user.tags.exists(u, u == t))",
+			option:     &SandboxProfile{MaxComprehensionNesting: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "fail - comprehension iteration limit",
+			expression: "user.tags.all(t, user.tags.exists(u, u == t))",
+			option:     &SandboxProfile{MaxComprehensionIterations: 5},
+			wantEval:   ErrIterationLimit,
+		},
+		{
+			name:       "fail - option overrides config",
+			expression: "user.name.matches('^a')",
+			sandbox:    "tenant",
+			profiles:   map[string]SandboxProfile{"tenant": {}},
+			option:     &SandboxProfile{DisableRegex: true},
+			wantErr:    true,
+		},
+		{
+			name:       "fail - unknown sandbox profile",
+			expression: "true",
+			sandbox:    "tenant",
+			wantErr:    true,
+		},
+		{
+			name:       "fail - negative limit",
+			expression: "true",
+			option:     &SandboxProfile{MaxComprehensionIterations: -1},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := rolloutConfig(tt.expression)
+			config.Sandbox, config.SandboxProfiles = tt.sandbox, tt.profiles
+			var opts []Option
+			if tt.option != nil {
+				opts = append(opts, WithSandbox(*tt.option))
+			}
+			re, err := NewBuilder().WithConfig(config).WithVariables("user").WithOptions(opts...).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			ctx := map[string]interface{}{"user": map[string]interface{}{"name": "alice", "tags": []string{"a", "b", "c"}}}
+			result, err := re.EvaluateRule("age_validation", WithEvalContext(ctx))
+			if tt.wantEval != nil {
+				if !errors.Is(err, tt.wantEval) && !errors.Is(result.Error, tt.wantEval) {
+					t.Fatalf("EvaluateRule() error = %v, result error = %v, want %v", err, result.Error, tt.wantEval)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("EvaluateRule() passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+		})
+	}
+}
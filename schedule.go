@@ -0,0 +1,197 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// activation is the parsed time window of a rule or ruleset, see Rule.ActiveFrom, Rule.ActiveUntil and Rule.Schedule
+type activation struct {
+	from     time.Time
+	until    time.Time
+	schedule *cronSchedule
+}
+
+// parseActivation parses the active_from, active_until and schedule settings, nil if none is set
+func parseActivation(from, until, schedule string) (*activation, error) {
+	if from == "" && until == "" && schedule == "" {
+		return nil, nil
+	}
+	a := &activation{}
+	var err error
+	if from != "" {
+		if a.from, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, fmt.Errorf("invalid active_from '%s', want RFC3339: %w", from, err)
+		}
+	}
+	if until != "" {
+		if a.until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, fmt.Errorf("invalid active_until '%s', want RFC3339: %w", until, err)
+		}
+	}
+	if !a.from.IsZero() && !a.until.IsZero() && !a.until.After(a.from) {
+		return nil, fmt.Errorf("active_until '%s' must be after active_from '%s'", until, from)
+	}
+	if schedule != "" {
+		if a.schedule, err = parseCronSchedule(schedule); err != nil {
+			return nil, fmt.Errorf("invalid schedule '%s': %w", schedule, err)
+		}
+	}
+	return a, nil
+}
+
+// active reports whether t is within the window, active_from is inclusive and active_until exclusive
+func (a *activation) active(t time.Time) bool {
+	if a == nil {
+		return true
+	}
+	if !a.from.IsZero() && t.Before(a.from) {
+		return false
+	}
+	if !a.until.IsZero() && !t.Before(a.until) {
+		return false
+	}
+	return a.schedule == nil || a.schedule.matches(t)
+}
+
+// compileActivations parses the time windows of every rule and ruleset declaring one
+//
+//	Every rule and ruleset is parsed even if some fail
+func (re *RuleEngine) compileActivations() CompileErrors {
+	var errs CompileErrors
+	for _, name := range re.ruleNames() {
+		rule := re.config.Rules[name]
+		a, err := parseActivation(rule.ActiveFrom, rule.ActiveUntil, rule.Schedule)
+		if err != nil {
+			errs = append(errs, &CompileError{RuleName: name, Source: re.sources[name], Err: err})
+			continue
+		}
+		if a != nil {
+			re.activations[name] = a
+		}
+	}
+	for _, name := range re.rulesetNames() {
+		ruleset := re.config.Rulesets[name]
+		a, err := parseActivation(ruleset.ActiveFrom, ruleset.ActiveUntil, ruleset.Schedule)
+		if err != nil {
+			errs = append(errs, &CompileError{RuleName: name, Err: fmt.Errorf("invalid ruleset window: %w", err)})
+			continue
+		}
+		if a != nil {
+			re.rulesetActivations[name] = a
+		}
+	}
+	return errs
+}
+
+// cronSchedule is a cron-style window, matching every minute of the five fields: minute, hour, day of month, month
+// and day of week
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a `*` day field, a day matches either day field only when both are restricted
+	domAny, dowAny bool
+	location       *time.Location
+}
+
+// cronField is the range of a cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// parseCronSchedule parses a five field cron expression, optionally prefixed by `CRON_TZ=<zone> `, UTC by default
+//
+//	Fields are `*`, a value, a range `a-b`, a step `*/n` or `a-b/n`, or a comma separated list of these. Day of
+//	week 0 and 7 are Sunday
+func parseCronSchedule(expression string) (*cronSchedule, error) {
+	s := &cronSchedule{location: time.UTC}
+	fields := strings.Fields(expression)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "CRON_TZ=") {
+		location, err := time.LoadLocation(strings.TrimPrefix(fields[0], "CRON_TZ="))
+		if err != nil {
+			return nil, err
+		}
+		s.location = location
+		fields = fields[1:]
+	}
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("want %d fields, got %d", len(cronFields), len(fields))
+	}
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i]); err != nil {
+			return nil, err
+		}
+	}
+	s.minute, s.hour, s.dom, s.month, s.dow = bits[0], bits[1], bits[2], bits[3], bits[4]
+	// Sunday is both 0 and 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return s, nil
+}
+
+// parseCronField parses a cron field into a bit set of the values it matches
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step '%s'", f.name, stepPart)
+			}
+		}
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(lowPart); err != nil {
+				return 0, fmt.Errorf("invalid %s '%s'", f.name, part)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highPart); err != nil {
+					return 0, fmt.Errorf("invalid %s '%s'", f.name, part)
+				}
+			} else if hasStep {
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%s '%s' out of range %d-%d", f.name, part, f.min, f.max)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// matches reports whether the minute of t matches the schedule
+func (s *cronSchedule) matches(t time.Time) bool {
+	t = t.In(s.location)
+	if s.minute&(1<<t.Minute()) == 0 || s.hour&(1<<t.Hour()) == 0 || s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,213 @@
+package ruleengine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	// 2024-01-01 is a Monday
+	monday9am := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		times      map[time.Time]bool
+		wantErr    bool
+	}{
+		{
+			name:       "success - every minute",
+			expression: "* * * * *",
+			times:      map[time.Time]bool{monday9am: true},
+		},
+		{
+			name:       "success - business hours",
+			expression: "* 9-16 * * 1-5",
+			times: map[time.Time]bool{
+				monday9am:                         true,
+				monday9am.Add(8 * time.Hour):      false,
+				monday9am.Add(-time.Minute):       false,
+				monday9am.Add(5 * 24 * time.Hour): false,
+			},
+		},
+		{
+			name:       "success - steps and lists",
+			expression: "*/15 9,17 * * *",
+			times: map[time.Time]bool{
+				monday9am:                       true,
+				monday9am.Add(15 * time.Minute): true,
+				monday9am.Add(20 * time.Minute): false,
+				monday9am.Add(8 * time.Hour):    true,
+				monday9am.Add(9 * time.Hour):    false,
+			},
+		},
+		{
+			name:       "success - sunday as 7",
+			expression: "* * * * 7",
+			times: map[time.Time]bool{
+				monday9am:                         false,
+				monday9am.Add(6 * 24 * time.Hour): true,
+			},
+		},
+		{
+			name:       "success - restricted days match either day field",
+			expression: "* * 15 * 1",
+			times: map[time.Time]bool{
+				monday9am:                          true,
+				monday9am.Add(24 * time.Hour):      false,
+				monday9am.Add(14 * 24 * time.Hour): true,
+			},
+		},
+		{
+			name:       "success - time zone",
+			expression: "CRON_TZ=UTC * 9 * * *",
+			times:      map[time.Time]bool{monday9am: true},
+		},
+		{
+			name:       "fail - field count",
+			expression: "* * * *",
+			wantErr:    true,
+		},
+		{
+			name:       "fail - out of range",
+			expression: "60 * * * *",
+			wantErr:    true,
+		},
+		{
+			name:       "fail - invalid step",
+			expression: "*/0 * * * *",
+			wantErr:    true,
+		},
+		{
+			name:       "fail - reversed range",
+			expression: "* 17-9 * * *",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseCronSchedule(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCronSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for at, want := range tt.times {
+				if got := s.matches(at); got != want {
+					t.Errorf("matches(%s) = %v, want %v", at.Format(time.RFC3339), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseActivation(t *testing.T) {
+	from := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from     string
+		until    string
+		schedule string
+		times    map[time.Time]bool
+		wantErr  bool
+	}{
+		{
+			name:  "success - no window",
+			times: map[time.Time]bool{from: true},
+		},
+		{
+			name:  "success - from inclusive until exclusive",
+			from:  "2024-12-01T00:00:00Z",
+			until: "2024-12-26T00:00:00Z",
+			times: map[time.Time]bool{
+				from.Add(-time.Second):        false,
+				from:                          true,
+				from.Add(25 * 24 * time.Hour): false,
+			},
+		},
+		{
+			name:     "success - window and schedule",
+			from:     "2024-12-01T00:00:00Z",
+			schedule: "* 9-16 * * *",
+			times: map[time.Time]bool{
+				from:                    false,
+				from.Add(9 * time.Hour): true,
+			},
+		},
+		{
+			name:    "fail - invalid time",
+			from:    "2024-12-01",
+			wantErr: true,
+		},
+		{
+			name:    "fail - until before from",
+			from:    "2024-12-26T00:00:00Z",
+			until:   "2024-12-01T00:00:00Z",
+			wantErr: true,
+		},
+		{
+			name:     "fail - invalid schedule",
+			schedule: "every day",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := parseActivation(tt.from, tt.until, tt.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseActivation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for at, want := range tt.times {
+				if got := a.active(at); got != want {
+					t.Errorf("active(%s) = %v, want %v", at.Format(time.RFC3339), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEngine_Activation(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/schedule_rules.yml").WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	ctx := WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"age": 20}})
+
+	got, err := re.EvaluateRuleset("checkout", ctx)
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if !got.Passed {
+		t.Errorf("EvaluateRuleset() passed = false, want inactive rules skipped")
+	}
+	gotSkipped := make(map[string]bool, len(got.RuleResults))
+	for name, result := range got.RuleResults {
+		gotSkipped[name] = result.Skipped
+	}
+	wantSkipped := map[string]bool{
+		"age_validation":     false,
+		"expired_promotion":  true,
+		"future_restriction": true,
+		"always_on":          false,
+		"never_on":           true,
+	}
+	if diff := cmp.Diff(wantSkipped, gotSkipped); diff != "" {
+		t.Errorf("EvaluateRuleset() skipped mismatch (-want +got):\n%s", diff)
+	}
+
+	sale, err := re.EvaluateRuleset("holiday_sale", ctx)
+	if err != nil {
+		t.Fatalf("EvaluateRuleset() error = %v", err)
+	}
+	if !sale.Skipped || sale.Passed || len(sale.RuleResults) != 0 {
+		t.Errorf("EvaluateRuleset() = %+v, want the expired ruleset skipped", sale)
+	}
+
+	_, err = NewBuilder().WithConfig(&RulesetConfig{
+		Rules:             map[string]Rule{"r": {Expression: "true", Schedule: "* * *"}},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+	}).Build()
+	if err == nil {
+		t.Errorf("Build() error = nil, want the invalid schedule rejected")
+	}
+}
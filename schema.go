@@ -0,0 +1,235 @@
+package ruleengine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+)
+
+// JSONSchemaDialect is the JSON Schema draft ContextSchema documents conform to
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document, or subschema, describing the context rules read
+type JSONSchema struct {
+	Schema     string                 `json:"$schema,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Format     string                 `json:"format,omitempty"`
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+}
+
+// ContextSchema returns the JSON Schema of the context the named rulesets read, every ruleset when none are named
+//
+//	The schema lists the context paths of VariableUsage as required properties. Field types come from the checked
+//	expressions, e.g. protobuf typed variables, or are inferred from how a dynamic field is used, e.g. compared to
+//	an int literal or calling startsWith, and are left open when unknown. Globals are supplied by the engine and
+//	are not included. Errors are returned if a ruleset is not found or an expression cannot be compiled
+func (re *RuleEngine) ContextSchema(rulesetNames ...string) (*JSONSchema, error) {
+	if active := re.Active(); active != re {
+		return active.ContextSchema(rulesetNames...)
+	}
+	if len(rulesetNames) == 0 {
+		rulesetNames = re.rulesetNames()
+	}
+	expressions := make([]string, 0)
+	seen := make(map[string]bool)
+	addRule := func(name string) {
+		if _, ok := re.quarantined[name]; ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		rule := re.config.Rules[name]
+		expressions = append(expressions, rule.Expression)
+		if rule.When != "" {
+			expressions = append(expressions, rule.When)
+		}
+	}
+	for _, name := range rulesetNames {
+		ruleset, ok := re.config.Rulesets[name]
+		if !ok {
+			return nil, fmt.Errorf("ruleset '%s' not found", name)
+		}
+		if ruleset.Precondition != "" {
+			expressions = append(expressions, ruleset.Precondition)
+		}
+		for _, ruleName := range ruleset.Rules {
+			for _, parent := range re.parents[ruleName] {
+				addRule(parent)
+			}
+			addRule(ruleName)
+		}
+	}
+
+	fields := make(map[string]*schemaField)
+	for _, expression := range expressions {
+		if err := re.schemaFields(expression, fields); err != nil {
+			return nil, err
+		}
+	}
+	schema := &JSONSchema{
+		Schema: JSONSchemaDialect,
+		Title:  strings.Join(rulesetNames, ", "),
+		Type:   "object",
+	}
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		schema.add(strings.Split(path, "."), fields[path].t)
+	}
+	return schema, nil
+}
+
+// schemaFields records the context paths read by expression with their checked or inferred type, nil if unknown
+func (re *RuleEngine) schemaFields(expression string, fields map[string]*schemaField) error {
+	checked, issues := re.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return fmt.Errorf("failed to compile expression '%s': %w", expression, issues.Err())
+	}
+	declared := make(map[string]bool)
+	for _, v := range re.env.Variables() {
+		declared[v.Name()] = true
+	}
+	root := ast.NavigateAST(checked.NativeRep())
+	matcher := func(e ast.NavigableExpr) bool {
+		return e.Kind() == ast.SelectKind || e.Kind() == ast.IdentKind
+	}
+	for _, nav := range ast.MatchDescendants(root, matcher) {
+		if parent, ok := nav.Parent(); ok && parent.Kind() == ast.SelectKind && !parent.AsSelect().IsTestOnly() {
+			// Only the longest path is a field, e.g. `user.age` but not `user`
+			continue
+		}
+		path, ok := selectPath(nav)
+		if !ok {
+			continue
+		}
+		variable := strings.SplitN(path, ".", 2)[0]
+		if !declared[variable] || variable == "globals" {
+			continue
+		}
+		field, ok := fields[path]
+		if !ok {
+			field = &schemaField{}
+			fields[path] = field
+		}
+		field.use(inferredType(nav))
+	}
+	return nil
+}
+
+// schemaField is the type of a context field across its uses
+type schemaField struct {
+	t *types.Type
+	// conflict is set when uses disagree on the type, leaving the field open
+	conflict bool
+}
+
+// use records a use of the field with type t, nil if the use does not tell the type
+func (f *schemaField) use(t *types.Type) {
+	switch {
+	case t == nil || f.conflict:
+	case f.t == nil:
+		f.t = t
+	case !f.t.IsExactType(t):
+		f.t, f.conflict = nil, true
+	}
+}
+
+// inferredType returns the checked type of a field, or the type implied by its use when it is dynamic
+func inferredType(nav ast.NavigableExpr) *types.Type {
+	if t := nav.Type(); t != nil && t.Kind() != types.DynKind && t.Kind() != types.AnyKind && t.Kind() != types.ErrorKind {
+		return t
+	}
+	parent, ok := nav.Parent()
+	if !ok {
+		// Rule expressions, when clauses and preconditions evaluate to a bool
+		return types.BoolType
+	}
+	if parent.Kind() != ast.CallKind {
+		return nil
+	}
+	call := parent.AsCall()
+	switch call.FunctionName() {
+	case operators.Equals, operators.NotEquals, operators.Less, operators.LessEquals, operators.Greater,
+		operators.GreaterEquals, operators.Add, operators.Subtract, operators.Multiply, operators.Divide,
+		operators.Modulo:
+		// The field has the type of the value it is compared or combined with, e.g. `user.age >= 18`
+		for _, other := range parent.Children() {
+			if t := other.Type(); other.ID() != nav.ID() && t != nil && t.Kind() != types.DynKind &&
+				t.Kind() != types.AnyKind && t.Kind() != types.ErrorKind {
+				return t
+			}
+		}
+	case operators.LogicalAnd, operators.LogicalOr, operators.LogicalNot:
+		return types.BoolType
+	case operators.Conditional:
+		if call.Args()[0].ID() == nav.ID() {
+			return types.BoolType
+		}
+	case operators.In:
+		if call.Args()[1].ID() == nav.ID() {
+			return types.NewListType(types.DynType)
+		}
+	case "matches", "startsWith", "endsWith", "contains":
+		return types.StringType
+	}
+	return nil
+}
+
+// add places the field at path in the schema, creating the objects along it, every field on the path is required
+func (s *JSONSchema) add(path []string, t *types.Type) {
+	name := path[0]
+	if s.Properties == nil {
+		s.Properties = make(map[string]*JSONSchema)
+	}
+	child, ok := s.Properties[name]
+	if !ok {
+		child = &JSONSchema{}
+		s.Properties[name] = child
+		s.Required = append(s.Required, name)
+		sort.Strings(s.Required)
+	}
+	if len(path) > 1 {
+		child.Type = "object"
+		child.Format = ""
+		child.add(path[1:], t)
+		return
+	}
+	if child.Properties == nil {
+		child.Type, child.Format = schemaType(t)
+	}
+}
+
+// schemaType returns the JSON Schema type and format of a CEL type, empty if any value is accepted
+func schemaType(t *types.Type) (string, string) {
+	if t == nil {
+		return "", ""
+	}
+	switch t.Kind() {
+	case types.BoolKind:
+		return "boolean", ""
+	case types.IntKind, types.UintKind:
+		return "integer", ""
+	case types.DoubleKind:
+		return "number", ""
+	case types.StringKind, types.BytesKind:
+		return "string", ""
+	case types.TimestampKind:
+		return "string", "date-time"
+	case types.DurationKind:
+		return "string", "duration"
+	case types.ListKind:
+		return "array", ""
+	case types.MapKind, types.StructKind:
+		return "object", ""
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,133 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_ContextSchema(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	tests := []struct {
+		name     string
+		rulesets []string
+		want     *JSONSchema
+		wantErr  bool
+	}{
+		{
+			name:     "success - ruleset fields with inferred types",
+			rulesets: []string{"user_registration"},
+			want: &JSONSchema{
+				Schema: JSONSchemaDialect,
+				Title:  "user_registration",
+				Type:   "object",
+				Properties: map[string]*JSONSchema{
+					"user": {
+						Type: "object",
+						Properties: map[string]*JSONSchema{
+							"age":       {},
+							"email":     {Type: "string"},
+							"status":    {Type: "string"},
+							"suspended": {Type: "boolean"},
+						},
+						Required: []string{"age", "email", "status", "suspended"},
+					},
+				},
+				Required: []string{"user"},
+			},
+		},
+		{
+			name:     "success - multiple rulesets",
+			rulesets: []string{"request_throttling", "domain_whitelist"},
+			want: &JSONSchema{
+				Schema: JSONSchemaDialect,
+				Title:  "request_throttling, domain_whitelist",
+				Type:   "object",
+				Properties: map[string]*JSONSchema{
+					"request": {
+						Type:       "object",
+						Properties: map[string]*JSONSchema{"attempt": {}},
+						Required:   []string{"attempt"},
+					},
+					"user": {
+						Type:       "object",
+						Properties: map[string]*JSONSchema{"email": {Type: "string"}, "tier": {Type: "string"}},
+						Required:   []string{"email", "tier"},
+					},
+				},
+				Required: []string{"request", "user"},
+			},
+		},
+		{
+			name:     "fail - unknown ruleset",
+			rulesets: []string{"unknown"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.ContextSchema(tt.rulesets...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ContextSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("ContextSchema() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_ContextSchema_Inference(t *testing.T) {
+	re, err := NewBuilder().WithConfig(&RulesetConfig{
+		Rules: map[string]Rule{
+			"adult":   {Expression: "user.age >= 18 && user.score > 0.5", When: "user.verified"},
+			"roles":   {Expression: "'admin' in user.roles ? user.created < timestamp('2024-01-01T00:00:00Z') : false"},
+			"code":    {Expression: "user.code == 1 || user.code == 'A'"},
+			"nested":  {Expression: "user.address.country == 'AU' && has(user.address.city)"},
+			"globals": {Expression: "user.age <= globals.max_age"},
+		},
+		Rulesets: map[string]Ruleset{
+			"all": {Selector: "AND", Rules: []string{"adult", "roles", "code", "nested", "globals"}},
+		},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+	}).WithVariables("user").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	got, err := re.ContextSchema("all")
+	if err != nil {
+		t.Fatalf("ContextSchema() error = %v", err)
+	}
+	want := &JSONSchema{
+		Schema: JSONSchemaDialect,
+		Title:  "all",
+		Type:   "object",
+		Properties: map[string]*JSONSchema{
+			"user": {
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"address": {
+						Type:       "object",
+						Properties: map[string]*JSONSchema{"country": {Type: "string"}},
+						Required:   []string{"country"},
+					},
+					"age":      {Type: "integer"},
+					"code":     {},
+					"created":  {Type: "string", Format: "date-time"},
+					"roles":    {Type: "array"},
+					"score":    {Type: "number"},
+					"verified": {Type: "boolean"},
+				},
+				Required: []string{"address", "age", "code", "created", "roles", "score", "verified"},
+			},
+		},
+		Required: []string{"user"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("ContextSchema() (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,213 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// maxFlipExamples bounds the number of record numbers kept per ruleset as examples of decision flips
+const maxFlipExamples = 10
+
+// Simulator replays a dataset of historical contexts through a baseline config and, optionally, a candidate config,
+// sizing the blast radius of a proposed change before it ships
+//
+//	sim := ruleengine.Simulator{Baseline: current, Candidate: proposed}
+//	report, err := sim.Run(ruleengine.NewJSONLReader(file))
+type Simulator struct {
+	// Baseline is the engine of the current config
+	Baseline *RuleEngine
+	// Candidate is the engine of the proposed config, nil to only report the baseline
+	Candidate *RuleEngine
+	// Rulesets are the rulesets to evaluate, every ruleset of either engine when empty
+	Rulesets []string
+	// EvalOptions are applied to every evaluation, e.g. WithEvalProfile, the record is applied with WithEvalContext
+	EvalOptions []EvalOption
+}
+
+// SimulationReport aggregates the results of a simulation
+type SimulationReport struct {
+	// Records is the number of contexts replayed
+	Records int
+	// Rulesets is a map of ruleset names to their aggregated results
+	Rulesets map[string]RulesetSimulation
+}
+
+// RulesetSimulation aggregates the results of a ruleset over every record
+type RulesetSimulation struct {
+	// Baseline holds the results of the baseline config, nil if the ruleset is not in it
+	Baseline *SimulationStats
+	// Candidate holds the results of the candidate config, nil without a candidate or if the ruleset is not in it
+	Candidate *SimulationStats
+	// PassToFail is the number of records passing with the baseline and not passing with the candidate
+	PassToFail int
+	// FailToPass is the number of records not passing with the baseline and passing with the candidate
+	FailToPass int
+	// FlipExamples are the 1-based numbers of the first records whose decision flipped
+	FlipExamples []int
+}
+
+// Flips returns the number of records whose decision differs between the baseline and the candidate
+func (s RulesetSimulation) Flips() int {
+	return s.PassToFail + s.FailToPass
+}
+
+// SimulationStats counts the outcomes of a ruleset over the records evaluated by one config
+type SimulationStats struct {
+	// Passed, Failed and Skipped count the records by ruleset outcome, skipped rulesets are not counted as failed
+	Passed  int
+	Failed  int
+	Skipped int
+	// Degraded counts the records where the ruleset fallback decision was applied
+	Degraded int
+	// Rules is a map of rule names to their contribution to the ruleset outcome
+	Rules map[string]RuleContribution
+}
+
+// PassRate returns the share of the evaluated records, excluding skipped ones, for which the ruleset passed
+func (s SimulationStats) PassRate() float64 {
+	if s.Passed+s.Failed == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Passed+s.Failed)
+}
+
+// RuleContribution counts how often a rule caused its ruleset not to pass
+type RuleContribution struct {
+	// Failed is the number of records the rule did not pass for, including evaluation errors
+	Failed int
+	// Errors is the number of records the rule failed to evaluate for
+	Errors int
+	// Decisive is the number of records the ruleset did not pass for with this rule as the only failing rule
+	Decisive int
+}
+
+// Run replays every context of the dataset and aggregates the results
+//
+//	Errors are returned if the dataset cannot be read or a named ruleset is in neither config
+func (s Simulator) Run(dataset DatasetReader) (SimulationReport, error) {
+	if s.Baseline == nil {
+		return SimulationReport{}, errors.New("simulator requires a baseline engine")
+	}
+	names := s.rulesetNames()
+	report := SimulationReport{Rulesets: make(map[string]RulesetSimulation, len(names))}
+	for {
+		ctx, err := dataset.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to read dataset: %w", err)
+		}
+		report.Records++
+
+		for _, name := range names {
+			sim := report.Rulesets[name]
+			baseline, inBaseline, err := s.evaluate(s.Baseline, name, ctx)
+			if err != nil {
+				return report, err
+			}
+			candidate, inCandidate, err := s.evaluate(s.Candidate, name, ctx)
+			if err != nil {
+				return report, err
+			}
+			if !inBaseline && !inCandidate {
+				return report, fmt.Errorf("ruleset '%s' not found", name)
+			}
+			if inBaseline {
+				sim.Baseline = sim.Baseline.record(baseline)
+			}
+			if inCandidate {
+				sim.Candidate = sim.Candidate.record(candidate)
+			}
+			if inBaseline && inCandidate && baseline.Passed != candidate.Passed {
+				if baseline.Passed {
+					sim.PassToFail++
+				} else {
+					sim.FailToPass++
+				}
+				if len(sim.FlipExamples) < maxFlipExamples {
+					sim.FlipExamples = append(sim.FlipExamples, report.Records)
+				}
+			}
+			report.Rulesets[name] = sim
+		}
+	}
+	return report, nil
+}
+
+// rulesetNames returns the rulesets to evaluate, every ruleset of either engine in sorted order by default
+func (s Simulator) rulesetNames() []string {
+	if len(s.Rulesets) > 0 {
+		return s.Rulesets
+	}
+	seen := make(map[string]bool)
+	for _, re := range []*RuleEngine{s.Baseline, s.Candidate} {
+		if re == nil {
+			continue
+		}
+		for _, name := range re.rulesetNames() {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// evaluate evaluates the ruleset with the engine, false is returned if the engine is nil or has no such ruleset
+func (s Simulator) evaluate(re *RuleEngine, name string, ctx map[string]interface{}) (RulesetResult, bool, error) {
+	if re == nil {
+		return RulesetResult{}, false, nil
+	}
+	if _, ok := re.config.Rulesets[name]; !ok {
+		return RulesetResult{}, false, nil
+	}
+	opts := append(s.EvalOptions[:len(s.EvalOptions):len(s.EvalOptions)], WithEvalContext(ctx))
+	result, err := re.EvaluateRuleset(name, opts...)
+	return result, true, err
+}
+
+// record adds the result to the stats, creating them if nil
+func (s *SimulationStats) record(result RulesetResult) *SimulationStats {
+	if s == nil {
+		s = &SimulationStats{Rules: make(map[string]RuleContribution)}
+	}
+	switch {
+	case result.Skipped:
+		s.Skipped++
+		return s
+	case result.Passed:
+		s.Passed++
+	default:
+		s.Failed++
+	}
+	if result.Degraded {
+		s.Degraded++
+	}
+
+	failing := make([]string, 0)
+	for name, rule := range result.RuleResults {
+		if rule.Passed || rule.Skipped {
+			continue
+		}
+		failing = append(failing, name)
+		c := s.Rules[name]
+		c.Failed++
+		var evalErr *EvaluationError
+		if errors.As(rule.Error, &evalErr) {
+			c.Errors++
+		}
+		s.Rules[name] = c
+	}
+	if !result.Passed && len(failing) == 1 {
+		c := s.Rules[failing[0]]
+		c.Decisive++
+		s.Rules[failing[0]] = c
+	}
+	return s
+}
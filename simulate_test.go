@@ -0,0 +1,130 @@
+package ruleengine
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSimulator_Run(t *testing.T) {
+	baseline, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	config, err := NewRulesetConfig("./testdata/rules.yml")
+	if err != nil {
+		t.Fatalf("NewRulesetConfig() error = %v", err)
+	}
+	config.Globals["min_age"] = 18
+	candidate, err := newRuleEngine(config, "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create candidate rules engine: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		sim     Simulator
+		want    SimulationReport
+		wantErr bool
+	}{
+		{
+			name: "success - baseline only",
+			sim:  Simulator{Baseline: baseline, Rulesets: []string{"user_registration"}},
+			want: SimulationReport{
+				Records: 4,
+				Rulesets: map[string]RulesetSimulation{
+					"user_registration": {
+						Baseline: &SimulationStats{
+							Passed: 2,
+							Failed: 2,
+							Rules: map[string]RuleContribution{
+								"age_validation": {Failed: 1, Decisive: 1},
+								"user_status":    {Failed: 1, Decisive: 1},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "success - candidate flips decisions",
+			sim:  Simulator{Baseline: baseline, Candidate: candidate, Rulesets: []string{"user_registration"}},
+			want: SimulationReport{
+				Records: 4,
+				Rulesets: map[string]RulesetSimulation{
+					"user_registration": {
+						Baseline: &SimulationStats{
+							Passed: 2,
+							Failed: 2,
+							Rules: map[string]RuleContribution{
+								"age_validation": {Failed: 1, Decisive: 1},
+								"user_status":    {Failed: 1, Decisive: 1},
+							},
+						},
+						Candidate: &SimulationStats{
+							Passed: 1,
+							Failed: 3,
+							Rules: map[string]RuleContribution{
+								"age_validation": {Failed: 2, Decisive: 2},
+								"user_status":    {Failed: 1, Decisive: 1},
+							},
+						},
+						PassToFail:   1,
+						FlipExamples: []int{2},
+					},
+				},
+			},
+		},
+		{
+			name:    "fail - unknown ruleset",
+			sim:     Simulator{Baseline: baseline, Candidate: candidate, Rulesets: []string{"unknown"}},
+			wantErr: true,
+		},
+		{
+			name:    "fail - no baseline",
+			sim:     Simulator{Candidate: candidate},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile("./testdata/simulation.jsonl")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.sim.Run(NewJSONLReader(strings.NewReader(string(data))))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Run() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSimulator_AllRulesets(t *testing.T) {
+	baseline, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	data := `{"user": {"age": 21, "email": "ada@example.com", "status": "active", "suspended": false, "tier": "premium"}, "request": {"attempt": 1}}`
+	got, err := Simulator{Baseline: baseline}.Run(NewJSONLReader(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, name := range []string{"domain_whitelist", "request_throttling", "user_registration"} {
+		sim, ok := got.Rulesets[name]
+		if !ok || sim.Baseline == nil || sim.Candidate != nil || sim.Flips() != 0 {
+			t.Errorf("Run() ruleset %s = %+v, want baseline results only", name, sim)
+		}
+	}
+	if rate := got.Rulesets["user_registration"].Baseline.PassRate(); rate != 1 {
+		t.Errorf("PassRate() = %v, want 1", rate)
+	}
+}
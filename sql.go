@@ -0,0 +1,303 @@
+package ruleengine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+)
+
+// SQLOptions configures the translation of rules into SQL WHERE clauses
+type SQLOptions struct {
+	// Columns is a map of context paths to the SQL column to use, e.g. "user.age" to "users.age_years"
+	//	Paths not listed are used as-is
+	Columns map[string]string
+}
+
+// SQLTranslation is the result of translating rules into SQL WHERE clauses
+type SQLTranslation struct {
+	// Clauses is a map of rule names to the WHERE clause selecting the rows the rule passes for
+	Clauses map[string]string
+	// Untranslatable is a map of rule names to the reason they could not be translated
+	Untranslatable map[string]string
+}
+
+// TranslateSQL converts the rules, together with the rules they extend, into SQL WHERE clauses where possible
+//
+//	Comparisons, `&&`, `||`, `!`, `in` over a list and has() are translated, globals are inlined as literals.
+//	Anything else, e.g. arithmetic, string functions, macros or when clauses, makes the rule untranslatable.
+//	The translation is best effort: SQL three-valued logic differs from CEL when a column is NULL
+func (re *RuleEngine) TranslateSQL(opts SQLOptions) SQLTranslation {
+	if active := re.Active(); active != re {
+		return active.TranslateSQL(opts)
+	}
+	translation := SQLTranslation{
+		Clauses:        make(map[string]string),
+		Untranslatable: make(map[string]string),
+	}
+	t := sqlTranslator{re: re, columns: opts.Columns}
+	for _, name := range re.ruleNames() {
+		chain := append([]string{name}, re.parents[name]...)
+		clauses := make([]string, 0, len(chain))
+		var err error
+		for i := len(chain) - 1; i >= 0 && err == nil; i-- {
+			var clause string
+			clause, err = t.rule(chain[i])
+			clauses = append(clauses, clause)
+		}
+		if err != nil {
+			translation.Untranslatable[name] = err.Error()
+			continue
+		}
+		translation.Clauses[name] = strings.Join(clauses, " AND ")
+	}
+	return translation
+}
+
+// sqlTranslator translates checked expressions into SQL
+type sqlTranslator struct {
+	re      *RuleEngine
+	columns map[string]string
+}
+
+// sqlOperators maps CEL comparison operators to SQL
+var sqlOperators = map[string]string{
+	operators.Equals:        "=",
+	operators.NotEquals:     "<>",
+	operators.Less:          "<",
+	operators.LessEquals:    "<=",
+	operators.Greater:       ">",
+	operators.GreaterEquals: ">=",
+}
+
+// rule translates the expression of a single rule, parenthesized when it is a disjunction
+func (t sqlTranslator) rule(name string) (string, error) {
+	rule := t.re.config.Rules[name]
+	if rule.When != "" {
+		return "", fmt.Errorf("rule '%s' has a when clause", name)
+	}
+	checked, err := t.re.checkExpression(rule.Expression)
+	if err != nil {
+		return "", err
+	}
+	expr := checked.NativeRep().Expr()
+	clause, err := t.predicate(expr)
+	if err != nil {
+		return "", err
+	}
+	if isCall(expr, operators.LogicalOr) {
+		return "(" + clause + ")", nil
+	}
+	return clause, nil
+}
+
+// predicate translates a boolean expression
+func (t sqlTranslator) predicate(e ast.Expr) (string, error) {
+	if value, ok := t.re.constantValue(e); ok {
+		if b, ok := value.(bool); ok {
+			return sqlLiteral(b)
+		}
+		return "", fmt.Errorf("unsupported constant %v", value)
+	}
+	if path, ok := selectPath(e); ok {
+		return t.column(path) + " = TRUE", nil
+	}
+	switch e.Kind() {
+	case ast.SelectKind:
+		if sel := e.AsSelect(); sel.IsTestOnly() {
+			path, ok := selectPath(sel.Operand())
+			if !ok {
+				return "", fmt.Errorf("unsupported has() operand")
+			}
+			return t.column(path+"."+sel.FieldName()) + " IS NOT NULL", nil
+		}
+	case ast.CallKind:
+		call := e.AsCall()
+		fn, args := call.FunctionName(), call.Args()
+		if call.IsMemberFunction() {
+			return "", fmt.Errorf("unsupported function '%s'", fn)
+		}
+		switch fn {
+		case operators.LogicalAnd, operators.LogicalOr:
+			keyword := map[string]string{operators.LogicalAnd: " AND ", operators.LogicalOr: " OR "}[fn]
+			parts := make([]string, len(args))
+			for i, arg := range args {
+				part, err := t.predicate(arg)
+				if err != nil {
+					return "", err
+				}
+				if arg.Kind() == ast.CallKind && (isCall(arg, operators.LogicalAnd) || isCall(arg, operators.LogicalOr)) &&
+					arg.AsCall().FunctionName() != fn {
+					part = "(" + part + ")"
+				}
+				parts[i] = part
+			}
+			return strings.Join(parts, keyword), nil
+		case operators.LogicalNot:
+			part, err := t.predicate(args[0])
+			if err != nil {
+				return "", err
+			}
+			return "NOT (" + part + ")", nil
+		case operators.In:
+			return t.in(args[0], args[1])
+		}
+		if op, ok := sqlOperators[fn]; ok {
+			return t.comparison(op, args[0], args[1])
+		}
+		return "", fmt.Errorf("unsupported function '%s'", fn)
+	}
+	return "", fmt.Errorf("unsupported expression")
+}
+
+// comparison translates a comparison, comparing with null translates to IS NULL or IS NOT NULL
+func (t sqlTranslator) comparison(op string, left, right ast.Expr) (string, error) {
+	if isNull(left) {
+		left, right = right, left
+	}
+	lhs, err := t.operand(left)
+	if err != nil {
+		return "", err
+	}
+	if isNull(right) {
+		switch op {
+		case "=":
+			return lhs + " IS NULL", nil
+		case "<>":
+			return lhs + " IS NOT NULL", nil
+		}
+		return "", fmt.Errorf("unsupported comparison with null")
+	}
+	rhs, err := t.operand(right)
+	if err != nil {
+		return "", err
+	}
+	return lhs + " " + op + " " + rhs, nil
+}
+
+// in translates membership in a list of constants
+func (t sqlTranslator) in(elem, list ast.Expr) (string, error) {
+	lhs, err := t.operand(elem)
+	if err != nil {
+		return "", err
+	}
+	value, ok := t.re.constantValue(list)
+	if !ok {
+		return "", fmt.Errorf("unsupported 'in' operand, only lists of constants are supported")
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("unsupported 'in' operand, only lists are supported")
+	}
+	if len(values) == 0 {
+		return "FALSE", nil
+	}
+	literals := make([]string, len(values))
+	for i, v := range values {
+		if literals[i], err = sqlLiteral(v); err != nil {
+			return "", err
+		}
+	}
+	return lhs + " IN (" + strings.Join(literals, ", ") + ")", nil
+}
+
+// operand translates a column or a constant
+func (t sqlTranslator) operand(e ast.Expr) (string, error) {
+	if value, ok := t.re.constantValue(e); ok {
+		return sqlLiteral(value)
+	}
+	if path, ok := selectPath(e); ok {
+		return t.column(path), nil
+	}
+	return "", fmt.Errorf("unsupported operand, only fields and constants are supported")
+}
+
+// column returns the column for a context path
+func (t sqlTranslator) column(path string) string {
+	if column, ok := t.columns[path]; ok {
+		return column
+	}
+	return path
+}
+
+// sqlLiteral formats a constant as a SQL literal, strings are single quoted
+func sqlLiteral(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported constant of type %T", v)
+}
+
+// constantValue resolves a literal, a list of constants or a globals path to its Go value
+func (re *RuleEngine) constantValue(e ast.Expr) (interface{}, bool) {
+	switch e.Kind() {
+	case ast.LiteralKind:
+		lit := e.AsLiteral()
+		if lit == types.NullValue {
+			return nil, true
+		}
+		switch lit.(type) {
+		case types.String, types.Int, types.Uint, types.Double, types.Bool:
+			return lit.Value(), true
+		}
+		return nil, false
+	case ast.ListKind:
+		values := make([]interface{}, 0, len(e.AsList().Elements()))
+		for _, elem := range e.AsList().Elements() {
+			value, ok := re.constantValue(elem)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, value)
+		}
+		return values, true
+	}
+	path, ok := selectPath(e)
+	if !ok {
+		return nil, false
+	}
+	global, ok := strings.CutPrefix(path, "globals.")
+	if !ok {
+		return nil, false
+	}
+	var value interface{} = re.config.Globals
+	for _, field := range strings.Split(global, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[field]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// isCall reports whether e is a call of the function fn
+func isCall(e ast.Expr, fn string) bool {
+	return e.Kind() == ast.CallKind && e.AsCall().FunctionName() == fn
+}
+
+// isNull reports whether e is the null literal
+func isNull(e ast.Expr) bool {
+	return e.Kind() == ast.LiteralKind && e.AsLiteral() == types.NullValue
+}
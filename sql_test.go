@@ -0,0 +1,91 @@
+package ruleengine
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_TranslateSQL(t *testing.T) {
+	tests := []struct {
+		name               string
+		rules              map[string]Rule
+		opts               SQLOptions
+		wantClauses        map[string]string
+		wantUntranslatable []string
+	}{
+		{
+			name: "success - comparisons and globals",
+			rules: map[string]Rule{
+				"adult":   {Expression: "user.age >= globals.min_age"},
+				"name":    {Expression: `user.name != "O'Brien" && user.active`},
+				"country": {Expression: `user.country in globals.countries || user.country == null`},
+				"tier":    {Expression: `!(user.tier in ["free", "trial"]) && has(user.email)`},
+			},
+			wantClauses: map[string]string{
+				"adult":   "user.age >= 18",
+				"name":    "user.name <> 'O''Brien' AND user.active = TRUE",
+				"country": "(user.country IN ('NZ', 'AU') OR user.country IS NULL)",
+				"tier":    "NOT (user.tier IN ('free', 'trial')) AND user.email IS NOT NULL",
+			},
+			wantUntranslatable: []string{},
+		},
+		{
+			name: "success - extended rules and columns",
+			rules: map[string]Rule{
+				"adult":        {Expression: "user.age >= 18 || user.emancipated"},
+				"adult_nz":     {Expression: `user.country == "NZ"`, Extends: "adult"},
+				"adult_nz_vip": {Expression: `user.vip`, Extends: "adult_nz"},
+			},
+			opts: SQLOptions{Columns: map[string]string{"user.age": "users.age_years"}},
+			wantClauses: map[string]string{
+				"adult":        "(users.age_years >= 18 OR user.emancipated = TRUE)",
+				"adult_nz":     "(users.age_years >= 18 OR user.emancipated = TRUE) AND user.country = 'NZ'",
+				"adult_nz_vip": "(users.age_years >= 18 OR user.emancipated = TRUE) AND user.country = 'NZ' AND user.vip = TRUE",
+			},
+			wantUntranslatable: []string{},
+		},
+		{
+			name: "fail - untranslatable rules",
+			rules: map[string]Rule{
+				"arithmetic": {Expression: "user.age + 1 > 18"},
+				"function":   {Expression: `user.email.endsWith("@example.com")`},
+				"macro":      {Expression: `user.tags.exists(t, t == "vip")`},
+				"guarded":    {Expression: "user.age > 18", When: "user.active"},
+				"parent":     {Expression: "user.active", Extends: "function"},
+			},
+			wantClauses:        map[string]string{},
+			wantUntranslatable: []string{"arithmetic", "function", "guarded", "macro", "parent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(&RulesetConfig{
+				Globals: map[string]interface{}{
+					"min_age":   18,
+					"countries": []interface{}{"NZ", "AU"},
+				},
+				Rules:             tt.rules,
+				ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+				ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+			}).WithVariables("user").Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			got := re.TranslateSQL(tt.opts)
+			if diff := cmp.Diff(got.Clauses, tt.wantClauses); diff != "" {
+				t.Errorf("TranslateSQL() clauses (-got +want):\n%s", diff)
+			}
+			untranslatable := make([]string, 0, len(got.Untranslatable))
+			for name := range got.Untranslatable {
+				untranslatable = append(untranslatable, name)
+			}
+			sort.Strings(untranslatable)
+			if diff := cmp.Diff(untranslatable, tt.wantUntranslatable); diff != "" {
+				t.Errorf("TranslateSQL() untranslatable (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
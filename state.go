@@ -0,0 +1,428 @@
+package ruleengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// defaultStateRetention is how long counter events are kept by default, the longest window of the counter functions
+const defaultStateRetention = 24 * time.Hour
+
+// StateStore persists the events counted by velocity rules, e.g. "no more than 5 attempts per hour"
+//
+//	Implementations must be safe for concurrent use. NewMemoryStateStore keeps the events of a single process,
+//	NewRedisStateStore shares them between processes
+type StateStore interface {
+	// Append records an event of the counter key at the given time, carrying an optional value to aggregate
+	Append(ctx context.Context, key string, at time.Time, value interface{}) error
+	// Count returns the number of events of the counter key recorded since the given time
+	Count(ctx context.Context, key string, since time.Time) (int64, error)
+	// Values returns the values of the events of the counter key recorded since the given time, nil for events
+	// recorded without one
+	Values(ctx context.Context, key string, since time.Time) ([]interface{}, error)
+}
+
+// WithStateStore enables the counter(name, id) function backed by the store, for velocity and frequency rules
+//
+//	counter(name, id) selects the events of name recorded for id with RuleEngine.IncrementCounter or
+//	RuleEngine.RecordEvent, and lastMinute(), lastHour(), lastDay() or last(duration) count them within the window
+//	up to now, e.g. `counter("login_attempts", user.id).lastHour() < 5`. sum(duration) and distinct(duration)
+//	aggregate the values of the events within the window, e.g. `counter("payments", user.id).sum(duration("1h"))`.
+//	Store failures are evaluation errors retried by retry policies
+func WithStateStore(store StateStore) Option {
+	return func(re *RuleEngine) {
+		re.state = &stateLib{store: store, now: time.Now}
+	}
+}
+
+// IncrementCounter records an event of the counter name for id at the current time, e.g. a failed login of a user
+//
+//	Errors are returned if no state store is configured with WithStateStore or the store fails
+func (re *RuleEngine) IncrementCounter(name string, id interface{}) error {
+	return re.RecordEvent(name, id, nil)
+}
+
+// RecordEvent records an event of the counter name for id at the current time, carrying a value aggregated by the
+// sum() and distinct() windows, e.g. the amount of a payment or the card it was made with
+//
+//	Values are numbers for sum(), any value is compared by its string form for distinct(). Errors are returned if
+//	no state store is configured with WithStateStore or the store fails
+func (re *RuleEngine) RecordEvent(name string, id interface{}, value interface{}) error {
+	if re.state == nil {
+		return errors.New("no state store configured, use WithStateStore")
+	}
+	if err := re.state.store.Append(context.Background(), counterKey(name, id), re.state.now(), value); err != nil {
+		return fmt.Errorf("failed to record event of counter '%s': %w", name, err)
+	}
+	return nil
+}
+
+// counterKey returns the store key of the counter name for id
+func counterKey(name string, id interface{}) string {
+	return name + ":" + fmt.Sprint(id)
+}
+
+// counterType is the CEL type of the values returned by counter()
+var counterType = cel.OpaqueType("ruleengine.Counter")
+
+// counter is the CEL value selecting the events of a counter for an id
+type counter struct {
+	key string
+}
+
+// ConvertToNative implements ref.Val
+func (c counter) ConvertToNative(typeDesc reflect.Type) (interface{}, error) {
+	return nil, fmt.Errorf("counter cannot be converted to %v", typeDesc)
+}
+
+// ConvertToType implements ref.Val
+func (c counter) ConvertToType(typeValue ref.Type) ref.Val {
+	if typeValue == types.TypeType {
+		return counterType
+	}
+	return types.NewErr("counter cannot be converted to %s", typeValue.TypeName())
+}
+
+// Equal implements ref.Val
+func (c counter) Equal(other ref.Val) ref.Val {
+	o, ok := other.(counter)
+	return types.Bool(ok && o.key == c.key)
+}
+
+// Type implements ref.Val
+func (c counter) Type() ref.Type {
+	return counterType
+}
+
+// Value implements ref.Val
+func (c counter) Value() interface{} {
+	return c.key
+}
+
+// stateLib is a CEL library providing the counter() function and its windows
+type stateLib struct {
+	store StateStore
+	now   func() time.Time
+}
+
+// LibraryName implements cel.SingletonLibrary
+func (*stateLib) LibraryName() string {
+	return "ruleengine.state"
+}
+
+// CompileOptions implements cel.Library
+func (lib *stateLib) CompileOptions() []cel.EnvOption {
+	window := func(name string, d time.Duration) cel.EnvOption {
+		return cel.Function(name,
+			cel.MemberOverload("counter_"+name, []*cel.Type{counterType}, cel.IntType,
+				cel.UnaryBinding(func(val ref.Val) ref.Val {
+					return lib.count(val, d)
+				}),
+			),
+		)
+	}
+	return []cel.EnvOption{
+		cel.Function("counter",
+			cel.Overload("counter_string_dyn", []*cel.Type{cel.StringType, cel.DynType}, counterType,
+				cel.BinaryBinding(func(name, id ref.Val) ref.Val {
+					return counter{key: counterKey(fmt.Sprint(name.Value()), id.Value())}
+				}),
+			),
+		),
+		window("lastMinute", time.Minute),
+		window("lastHour", time.Hour),
+		window("lastDay", 24*time.Hour),
+		aggregation("last", cel.IntType, lib.count),
+		aggregation("sum", cel.DoubleType, lib.sum),
+		aggregation("distinct", cel.IntType, lib.distinct),
+	}
+}
+
+// aggregation declares a counter member function aggregating the events within a duration argument
+func aggregation(name string, result *cel.Type, aggregate func(ref.Val, time.Duration) ref.Val) cel.EnvOption {
+	return cel.Function(name,
+		cel.MemberOverload("counter_"+name+"_duration", []*cel.Type{counterType, cel.DurationType}, result,
+			cel.BinaryBinding(func(val, d ref.Val) ref.Val {
+				duration, ok := d.(types.Duration)
+				if !ok {
+					return types.NewErr("%s() requires a duration", name)
+				}
+				return aggregate(val, duration.Duration)
+			}),
+		),
+	)
+}
+
+// ProgramOptions implements cel.Library
+func (*stateLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// count returns the number of events of the counter within the window d up to now
+func (lib *stateLib) count(val ref.Val, d time.Duration) ref.Val {
+	c, ok := val.(counter)
+	if !ok {
+		return types.NewErr("counter window requires a counter")
+	}
+	n, err := lib.store.Count(context.Background(), c.key, lib.now().Add(-d))
+	if err != nil {
+		return types.WrapErr(retryableErrorf("counter '%s' failed: %v", c.key, err))
+	}
+	return types.Int(n)
+}
+
+// sum returns the sum of the numeric values of the events of the counter within the window d up to now
+//
+//	Events without a value are skipped, any other non-numeric value is an error
+func (lib *stateLib) sum(val ref.Val, d time.Duration) ref.Val {
+	values, errVal := lib.values(val, d)
+	if errVal != nil {
+		return errVal
+	}
+	var total float64
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		f, ok := numericValue(v)
+		if !ok {
+			return types.NewErr("sum() of non-numeric value %v", v)
+		}
+		total += f
+	}
+	return types.Double(total)
+}
+
+// distinct returns the number of distinct values of the events of the counter within the window d up to now
+//
+//	Values are compared by their string form, events without a value are skipped
+func (lib *stateLib) distinct(val ref.Val, d time.Duration) ref.Val {
+	values, errVal := lib.values(val, d)
+	if errVal != nil {
+		return errVal
+	}
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v != nil {
+			seen[fmt.Sprint(v)] = true
+		}
+	}
+	return types.Int(len(seen))
+}
+
+// values returns the values of the events of the counter within the window d up to now
+func (lib *stateLib) values(val ref.Val, d time.Duration) ([]interface{}, ref.Val) {
+	c, ok := val.(counter)
+	if !ok {
+		return nil, types.NewErr("counter window requires a counter")
+	}
+	values, err := lib.store.Values(context.Background(), c.key, lib.now().Add(-d))
+	if err != nil {
+		return nil, types.WrapErr(retryableErrorf("counter '%s' failed: %v", c.key, err))
+	}
+	return values, nil
+}
+
+// numericValue converts a Go number, or a JSON number decoded by a store, to a float64
+func numericValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// memoryStateStore is a StateStore keeping the events of each key in memory
+type memoryStateStore struct {
+	retention time.Duration
+
+	mu     sync.Mutex
+	events map[string][]stateEvent
+}
+
+// stateEvent is an event kept by the memory state store
+type stateEvent struct {
+	at    time.Time
+	value interface{}
+}
+
+// NewMemoryStateStore creates a StateStore keeping events in memory for the retention, 24h if not positive
+//
+//	Events are pruned as keys are appended to, counts over windows longer than the retention are truncated
+func NewMemoryStateStore(retention time.Duration) StateStore {
+	if retention <= 0 {
+		retention = defaultStateRetention
+	}
+	return &memoryStateStore{retention: retention, events: make(map[string][]stateEvent)}
+}
+
+// Append implements StateStore
+func (s *memoryStateStore) Append(_ context.Context, key string, at time.Time, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := s.events[key]
+	// Events are mostly recorded in order, insert out of order ones in place so windows can binary search
+	i := sort.Search(len(events), func(i int) bool { return events[i].at.After(at) })
+	events = append(events, stateEvent{})
+	copy(events[i+1:], events[i:])
+	events[i] = stateEvent{at: at, value: value}
+	s.events[key] = events[s.since(events, at.Add(-s.retention)):]
+	return nil
+}
+
+// Count implements StateStore
+func (s *memoryStateStore) Count(_ context.Context, key string, since time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := s.events[key]
+	return int64(len(events) - s.since(events, since)), nil
+}
+
+// Values implements StateStore
+func (s *memoryStateStore) Values(_ context.Context, key string, since time.Time) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := s.events[key]
+	values := make([]interface{}, 0, len(events))
+	for _, e := range events[s.since(events, since):] {
+		values = append(values, e.value)
+	}
+	return values, nil
+}
+
+// since returns the index of the first of the sorted events recorded at or after t
+func (s *memoryStateStore) since(events []stateEvent, t time.Time) int {
+	return sort.Search(len(events), func(i int) bool { return !events[i].at.Before(t) })
+}
+
+// RedisClient sends a command to Redis and returns its reply, e.g. an adapter of a go-redis client
+//
+//	client := ruleengine.RedisFunc(func(ctx context.Context, args ...interface{}) (interface{}, error) {
+//		return rdb.Do(ctx, args...).Result()
+//	})
+type RedisClient interface {
+	Do(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+// RedisFunc adapts a function to a RedisClient
+type RedisFunc func(ctx context.Context, args ...interface{}) (interface{}, error)
+
+// Do implements RedisClient
+func (f RedisFunc) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	return f(ctx, args...)
+}
+
+// redisStateStore is a StateStore keeping the events of each key in a Redis sorted set scored by time
+type redisStateStore struct {
+	client    RedisClient
+	prefix    string
+	retention time.Duration
+}
+
+// NewRedisStateStore creates a StateStore sharing events between processes in Redis sorted sets
+//
+//	Keys are prefixed with prefix, e.g. "ruleengine:", and expire after the retention, 24h if not positive. Events
+//	older than the retention are removed as keys are incremented
+func NewRedisStateStore(client RedisClient, prefix string, retention time.Duration) StateStore {
+	if retention <= 0 {
+		retention = defaultStateRetention
+	}
+	return &redisStateStore{client: client, prefix: prefix, retention: retention}
+}
+
+// Append implements StateStore
+//
+//	The member of an event is its time, a random suffix and its JSON encoded value, e.g. `1767355200000000000-k3x:12.5`
+func (s *redisStateStore) Append(ctx context.Context, key string, at time.Time, value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+	key = s.prefix + key
+	score := at.UnixNano()
+	// Members must be unique for events recorded at the same time, by any process, to be counted separately
+	member := strconv.FormatInt(score, 10) + "-" + strconv.FormatUint(rand.Uint64(), 36) + ":" + string(encoded)
+	commands := [][]interface{}{
+		{"ZADD", key, score, member},
+		{"ZREMRANGEBYSCORE", key, "-inf", "(" + strconv.FormatInt(at.Add(-s.retention).UnixNano(), 10)},
+		{"PEXPIRE", key, s.retention.Milliseconds()},
+	}
+	for _, args := range commands {
+		if _, err := s.client.Do(ctx, args...); err != nil {
+			return fmt.Errorf("redis %s: %w", args[0], err)
+		}
+	}
+	return nil
+}
+
+// Count implements StateStore
+func (s *redisStateStore) Count(ctx context.Context, key string, since time.Time) (int64, error) {
+	reply, err := s.client.Do(ctx, "ZCOUNT", s.prefix+key, since.UnixNano(), "+inf")
+	if err != nil {
+		return 0, fmt.Errorf("redis ZCOUNT: %w", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis ZCOUNT: unexpected reply %T", reply)
+	}
+	return n, nil
+}
+
+// Values implements StateStore
+func (s *redisStateStore) Values(ctx context.Context, key string, since time.Time) ([]interface{}, error) {
+	reply, err := s.client.Do(ctx, "ZRANGEBYSCORE", s.prefix+key, since.UnixNano(), "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("redis ZRANGEBYSCORE: %w", err)
+	}
+	members, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis ZRANGEBYSCORE: unexpected reply %T", reply)
+	}
+	values := make([]interface{}, 0, len(members))
+	for _, m := range members {
+		member, ok := m.(string)
+		if !ok {
+			return nil, fmt.Errorf("redis ZRANGEBYSCORE: unexpected member %T", m)
+		}
+		_, encoded, _ := strings.Cut(member, ":")
+		dec := json.NewDecoder(strings.NewReader(encoded))
+		dec.UseNumber()
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to decode value of member '%s': %w", member, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,313 @@
+package ruleengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRule_Counter(t *testing.T) {
+	start := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		events     []time.Duration
+		values     []interface{}
+		store      StateStore
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name:       "success - under the hourly limit",
+			expression: `counter("login_attempts", user.id).lastHour() < 3`,
+			events:     []time.Duration{-2 * time.Hour, -90 * time.Minute, -30 * time.Minute, -time.Minute},
+			want:       true,
+		},
+		{
+			name:       "success - hourly limit reached",
+			expression: `counter("login_attempts", user.id).lastHour() < 3`,
+			events:     []time.Duration{-50 * time.Minute, -30 * time.Minute, -time.Minute},
+		},
+		{
+			name:       "success - custom window",
+			expression: `counter("login_attempts", user.id).last(duration("10m")) == 1`,
+			events:     []time.Duration{-30 * time.Minute, -time.Minute},
+			want:       true,
+		},
+		{
+			name:       "success - daily window",
+			expression: `counter("login_attempts", user.id).lastDay() == 2 && counter("login_attempts", user.id).lastMinute() == 0`,
+			events:     []time.Duration{-25 * time.Hour, -23 * time.Hour, -2 * time.Minute},
+			want:       true,
+		},
+		{
+			name:       "success - other id not counted",
+			expression: `counter("login_attempts", "u2").lastHour() == 0`,
+			events:     []time.Duration{-time.Minute},
+			want:       true,
+		},
+		{
+			name:       "success - sum of values",
+			expression: `counter("login_attempts", user.id).sum(duration("1h")) == 42.5`,
+			events:     []time.Duration{-2 * time.Hour, -30 * time.Minute, -10 * time.Minute, -time.Minute},
+			values:     []interface{}{100, 30, 12.5, nil},
+			want:       true,
+		},
+		{
+			name:       "success - distinct values",
+			expression: `counter("login_attempts", user.id).distinct(duration("24h")) == 2`,
+			events:     []time.Duration{-2 * time.Hour, -30 * time.Minute, -10 * time.Minute, -time.Minute},
+			values:     []interface{}{"card-1", "card-2", "card-1", nil},
+			want:       true,
+		},
+		{
+			name:       "fail - sum of non-numeric values",
+			expression: `counter("login_attempts", user.id).sum(duration("1h")) > 0.0`,
+			events:     []time.Duration{-time.Minute},
+			values:     []interface{}{"card-1"},
+			wantErr:    true,
+		},
+		{
+			name:       "fail - store error",
+			expression: `counter("login_attempts", user.id).lastHour() < 3`,
+			store:      failingStateStore{},
+			wantErr:    true,
+		},
+		{
+			name:       "fail - store error aggregating",
+			expression: `counter("login_attempts", user.id).distinct(duration("1h")) < 3`,
+			store:      failingStateStore{},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			if store == nil {
+				store = NewMemoryStateStore(48 * time.Hour)
+			}
+			re, err := NewBuilder().WithConfig(&RulesetConfig{
+				Rules:             map[string]Rule{"velocity": {Expression: tt.expression}},
+				ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+				ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+			}).WithVariables("user").WithOptions(WithStateStore(store)).Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			for i, offset := range tt.events {
+				re.state.now = func() time.Time { return start.Add(offset) }
+				if tt.values != nil {
+					err = re.RecordEvent("login_attempts", "u1", tt.values[i])
+				} else {
+					err = re.IncrementCounter("login_attempts", "u1")
+				}
+				if err != nil {
+					t.Fatalf("RecordEvent() error = %v", err)
+				}
+			}
+			re.state.now = func() time.Time { return start }
+
+			got, err := re.EvaluateRule("velocity", WithEvalContext(map[string]interface{}{"user": map[string]interface{}{"id": "u1"}}))
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			var evalErr *EvaluationError
+			if gotErr := errors.As(got.Error, &evalErr); gotErr != tt.wantErr {
+				t.Fatalf("EvaluateRule() error = %v, wantErr %v", got.Error, tt.wantErr)
+			}
+			if got.Passed != tt.want {
+				t.Errorf("EvaluateRule() passed = %v, want %v", got.Passed, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_IncrementCounter(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	if err := re.IncrementCounter("login_attempts", "u1"); err == nil {
+		t.Error("IncrementCounter() error = nil, want an error without a state store")
+	}
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStateStore(time.Hour)
+	for _, offset := range []time.Duration{10 * time.Minute, 0, 5 * time.Minute, 90 * time.Minute} {
+		if err := store.Append(ctx, "k", start.Add(offset), nil); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	tests := []struct {
+		name  string
+		key   string
+		since time.Time
+		want  int64
+	}{
+		{
+			name:  "success - events older than the retention pruned",
+			key:   "k",
+			since: start,
+			want:  1,
+		},
+		{
+			name:  "success - window",
+			key:   "k",
+			since: start.Add(time.Hour),
+			want:  1,
+		},
+		{
+			name:  "success - unknown key",
+			key:   "other",
+			since: start,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Count(ctx, tt.key, tt.since)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	ordered := NewMemoryStateStore(time.Hour)
+	for _, offset := range []time.Duration{10 * time.Minute, 0, 5 * time.Minute} {
+		_ = ordered.Append(ctx, "k", start.Add(offset), nil)
+	}
+	if got, _ := ordered.Count(ctx, "k", start.Add(time.Minute)); got != 2 {
+		t.Errorf("Count() of out of order events = %d, want 2", got)
+	}
+}
+
+func TestRedisStateStore(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	redis := &fakeRedis{sets: make(map[string]map[string]int64)}
+	store := NewRedisStateStore(redis, "re:", time.Hour)
+	for i, offset := range []time.Duration{0, 0, 30 * time.Minute, 90 * time.Minute} {
+		if err := store.Append(ctx, "k", start.Add(offset), i); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	got, err := store.Count(ctx, "k", start.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if got != 2 {
+		t.Errorf("Count() = %d, want 2", got)
+	}
+	values, err := store.Values(ctx, "k", start.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Values() error = %v", err)
+	}
+	if diff := cmp.Diff([]interface{}{json.Number("2"), json.Number("3")}, values); diff != "" {
+		t.Errorf("Values() mismatch (-want +got):\n%s", diff)
+	}
+	wantCommands := []string{"ZADD re:k", "ZREMRANGEBYSCORE re:k", "PEXPIRE re:k 3600000"}
+	if diff := cmp.Diff(wantCommands, redis.commands[:3]); diff != "" {
+		t.Errorf("commands mismatch (-want +got):\n%s", diff)
+	}
+
+	failing := NewRedisStateStore(RedisFunc(func(context.Context, ...interface{}) (interface{}, error) {
+		return nil, errors.New("connection refused")
+	}), "", 0)
+	if err := failing.Append(ctx, "k", start, nil); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Append() error = %v, want connection refused", err)
+	}
+	if _, err := failing.Count(ctx, "k", start); err == nil {
+		t.Error("Count() error = nil, want an error")
+	}
+	if _, err := failing.Values(ctx, "k", start); err == nil {
+		t.Error("Values() error = nil, want an error")
+	}
+}
+
+// failingStateStore is a StateStore whose every call fails
+type failingStateStore struct{}
+
+func (failingStateStore) Append(context.Context, string, time.Time, interface{}) error {
+	return errors.New("store unavailable")
+}
+
+func (failingStateStore) Values(context.Context, string, time.Time) ([]interface{}, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingStateStore) Count(context.Context, string, time.Time) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+// fakeRedis implements the sorted set commands used by the Redis state store
+type fakeRedis struct {
+	sets     map[string]map[string]int64
+	commands []string
+}
+
+func (r *fakeRedis) Do(_ context.Context, args ...interface{}) (interface{}, error) {
+	cmd, key := args[0].(string), args[1].(string)
+	switch cmd {
+	case "ZADD":
+		r.commands = append(r.commands, cmd+" "+key)
+		if r.sets[key] == nil {
+			r.sets[key] = make(map[string]int64)
+		}
+		r.sets[key][args[3].(string)] = args[2].(int64)
+		return int64(1), nil
+	case "ZREMRANGEBYSCORE":
+		r.commands = append(r.commands, cmd+" "+key)
+		limit, err := strconv.ParseInt(strings.TrimPrefix(args[3].(string), "("), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		for member, score := range r.sets[key] {
+			if score < limit {
+				delete(r.sets[key], member)
+			}
+		}
+		return int64(0), nil
+	case "PEXPIRE":
+		r.commands = append(r.commands, fmt.Sprintf("%s %s %d", cmd, key, args[2]))
+		return int64(1), nil
+	case "ZRANGEBYSCORE":
+		type scored struct {
+			member string
+			score  int64
+		}
+		members := make([]scored, 0)
+		for member, score := range r.sets[key] {
+			if score >= args[2].(int64) {
+				members = append(members, scored{member, score})
+			}
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].score < members[j].score })
+		reply := make([]interface{}, 0, len(members))
+		for _, m := range members {
+			reply = append(reply, m.member)
+		}
+		return reply, nil
+	case "ZCOUNT":
+		var n int64
+		for _, score := range r.sets[key] {
+			if score >= args[2].(int64) {
+				n++
+			}
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("unknown command %s", cmd)
+}
@@ -0,0 +1,95 @@
+package ruleengine
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the engine's evaluation statistics
+type Stats struct {
+	// Rules contains the hit-rate statistics of every rule keyed by rule name
+	Rules map[string]RuleStats
+	// Profiles contains the sampled latency and cost of every profiled rule, nil unless WithProfiler is used
+	Profiles map[string]RuleProfile
+}
+
+// RuleStats contains the hit-rate statistics of a single rule
+type RuleStats struct {
+	// Passed is the number of evaluations where the rule passed
+	Passed uint64
+	// Failed is the number of evaluations where the rule did not pass
+	Failed uint64
+	// Errored is the number of evaluations which returned an evaluation error
+	Errored uint64
+	// LastEvaluated is the time of the most recent evaluation, zero if the rule was never evaluated
+	LastEvaluated time.Time
+}
+
+// Evaluations returns the total number of evaluations of the rule
+func (rs RuleStats) Evaluations() uint64 {
+	return rs.Passed + rs.Failed + rs.Errored
+}
+
+// ruleCounters holds the atomic counters backing RuleStats
+type ruleCounters struct {
+	passed        atomic.Uint64
+	failed        atomic.Uint64
+	errored       atomic.Uint64
+	lastEvaluated atomic.Int64
+}
+
+// record updates the counters of the rule with the evaluation result
+//
+//	evalErr indicates the result is an evaluation error rather than a rule failure
+func (rc *ruleCounters) record(result RuleResult, evalErr bool) {
+	switch {
+	case evalErr:
+		rc.errored.Add(1)
+	case result.Passed:
+		rc.passed.Add(1)
+	default:
+		rc.failed.Add(1)
+	}
+	rc.lastEvaluated.Store(time.Now().UnixNano())
+}
+
+// snapshot returns the current values of the counters
+func (rc *ruleCounters) snapshot() RuleStats {
+	stats := RuleStats{
+		Passed:  rc.passed.Load(),
+		Failed:  rc.failed.Load(),
+		Errored: rc.errored.Load(),
+	}
+	if last := rc.lastEvaluated.Load(); last != 0 {
+		stats.LastEvaluated = time.Unix(0, last)
+	}
+	return stats
+}
+
+// Stats returns a snapshot of the per-rule pass/fail counters, useful to find rules that never fire or always fail
+func (re *RuleEngine) Stats() Stats {
+	if active := re.Active(); active != re {
+		return active.Stats()
+	}
+	stats := Stats{
+		Rules: make(map[string]RuleStats, len(re.counters)),
+	}
+	for name, counters := range re.counters {
+		stats.Rules[name] = counters.snapshot()
+	}
+	if re.profiler != nil {
+		stats.Profiles = re.profiler.report()
+	}
+	return stats
+}
+
+// recordRule updates the statistics of the evaluated rule
+func (re *RuleEngine) recordRule(result RuleResult, evalErr bool, sampled bool, cost uint64) {
+	if counters, ok := re.counters[result.RuleName]; ok {
+		counters.record(result, evalErr)
+	}
+	if sampled {
+		re.profiler.record(result.RuleName, result.Duration, cost)
+	}
+	re.breakerRecord(result.RuleName, evalErr)
+}
@@ -0,0 +1,51 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRuleEngine_Stats(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "production", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+
+	contexts := []map[string]interface{}{
+		{"user": map[string]interface{}{"age": 21}},
+		{"user": map[string]interface{}{"age": 15}},
+		{"user": map[string]interface{}{"age": 30}},
+		{"user": map[string]interface{}{}},
+	}
+	for _, ctx := range contexts {
+		if _, err := re.EvaluateRule("age_validation", WithEvalContext(ctx)); err != nil {
+			t.Fatalf("EvaluateRule() error = %v", err)
+		}
+	}
+
+	got := re.Stats()
+	if got.Rules["age_validation"].LastEvaluated.IsZero() {
+		t.Errorf("Stats() LastEvaluated not set for evaluated rule")
+	}
+	if !got.Rules["user_tier"].LastEvaluated.IsZero() {
+		t.Errorf("Stats() LastEvaluated set for rule never evaluated")
+	}
+
+	want := map[string]RuleStats{
+		"age_validation": {Passed: 2, Failed: 1, Errored: 1},
+		"user_tier":      {},
+	}
+	for name, w := range want {
+		if diff := cmp.Diff(got.Rules[name], w, cmpopts.IgnoreFields(RuleStats{}, "LastEvaluated")); diff != "" {
+			t.Errorf("Stats() %s (-got +want):\n%s", name, diff)
+		}
+	}
+	if got.Rules["age_validation"].Evaluations() != 4 {
+		t.Errorf("Evaluations() = %d, want 4", got.Rules["age_validation"].Evaluations())
+	}
+	if len(got.Rules) != 8 {
+		t.Errorf("Stats() returned %d rules, want 8", len(got.Rules))
+	}
+}
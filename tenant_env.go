@@ -0,0 +1,78 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// ErrFunctionNotAllowed is returned when a tenant bundle allows a function the Registry does not declare
+var ErrFunctionNotAllowed = errors.New("function not declared by the registry")
+
+// TenantBundle is the config of a tenant and the variables and functions its rules may use, see
+// Registry.RegisterBundle
+type TenantBundle struct {
+	Config *RulesetConfig
+	// Environment is the name of the environment overrides applied to Config, none when empty
+	Environment string
+	// Variables are the context variables the rules may read, declared as dynamic types unless typed by the config
+	Variables []string
+	// Functions are the names of the functions declared with Registry.DeclareFunction the rules may call
+	Functions []string
+}
+
+// DeclareFunction adds a function tenant bundles may allow by name, e.g. a cel.Function declaration and its
+// bindings, replacing any function declared with the same name
+//
+//	Declared functions are available to no tenant until its bundle lists them in TenantBundle.Functions, so
+//	privileged functions can be offered to some tenants only
+func (r *Registry) DeclareFunction(name string, declaration cel.EnvOption) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.functions[name] = declaration
+}
+
+// RegisterBundle builds the engine of a tenant from its bundle and registers it with quota, see Register
+//
+//	The rules are compiled in a CEL env of their own declaring only `globals`, the bundle's variables and the
+//	functions it allows, so they fail to compile if they reference the context shapes or functions of another tenant.
+//	Errors wrapping ErrFunctionNotAllowed are returned if an allowed function was not declared with DeclareFunction,
+//	errors are also returned if the engine fails to build
+func (r *Registry) RegisterBundle(name string, bundle TenantBundle, quota TenantQuota, opts ...Option) error {
+	if bundle.Config == nil {
+		return fmt.Errorf("tenant '%s' bundle has no config", name)
+	}
+	functions, err := r.allowedFunctions(bundle.Functions)
+	if err != nil {
+		return fmt.Errorf("tenant '%s': %w", name, err)
+	}
+	engine, err := NewBuilder().
+		WithConfig(bundle.Config).
+		WithEnvironment(bundle.Environment).
+		WithVariables(bundle.Variables...).
+		WithFunctions(functions...).
+		WithOptions(opts...).
+		Build()
+	if err != nil {
+		return fmt.Errorf("tenant '%s': failed to build engine: %w", name, err)
+	}
+	return r.Register(name, engine, quota)
+}
+
+// allowedFunctions returns the declarations of the named functions
+func (r *Registry) allowedFunctions(names []string) ([]cel.EnvOption, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	functions := make([]cel.EnvOption, 0, len(names))
+	var errs []error
+	for _, name := range names {
+		declaration, ok := r.functions[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: '%s'", ErrFunctionNotAllowed, name))
+			continue
+		}
+		functions = append(functions, declaration)
+	}
+	return functions, errors.Join(errs...)
+}
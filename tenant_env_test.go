@@ -0,0 +1,116 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistry_RegisterBundle(t *testing.T) {
+	isCorporate := cel.Function("is_corporate",
+		cel.Overload("is_corporate_string", []*cel.Type{cel.StringType}, cel.BoolType,
+			cel.UnaryBinding(func(email ref.Val) ref.Val {
+				return types.Bool(strings.HasSuffix(string(email.(types.String)), "@acme.com"))
+			})))
+
+	tests := []struct {
+		name       string
+		expression string
+		variables  []string
+		functions  []string
+		wantErr    error
+		wantBuild  bool
+	}{
+		{
+			name:       "success - allowed variable and function",
+			expression: "is_corporate(user.email)",
+			variables:  []string{"user"},
+			functions:  []string{"is_corporate"},
+		},
+		{
+			name:       "fail - variable of another tenant",
+			expression: "order.total > 0",
+			variables:  []string{"user"},
+			wantBuild:  true,
+		},
+		{
+			name:       "fail - function not allowed",
+			expression: "is_corporate(user.email)",
+			variables:  []string{"user"},
+			wantBuild:  true,
+		},
+		{
+			name:       "fail - function not declared",
+			expression: "true",
+			functions:  []string{"is_admin"},
+			wantErr:    ErrFunctionNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.DeclareFunction("is_corporate", isCorporate)
+			bundle := TenantBundle{
+				Config:    rolloutConfig(tt.expression),
+				Variables: tt.variables,
+				Functions: tt.functions,
+			}
+			err := r.RegisterBundle("acme", bundle, TenantQuota{})
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("RegisterBundle() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			case tt.wantBuild:
+				if err == nil {
+					t.Fatal("RegisterBundle() error = nil, want a compile error")
+				}
+				return
+			case err != nil:
+				t.Fatalf("RegisterBundle() error = %v", err)
+			}
+			result, err := r.EvaluateRule("acme", "age_validation", WithEvalContext(map[string]interface{}{
+				"user": map[string]interface{}{"email": "jo@acme.com"},
+			}))
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if !result.Passed {
+				t.Error("EvaluateRule() passed = false, want true")
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterBundle_Isolation(t *testing.T) {
+	r := NewRegistry()
+	r.DeclareFunction("is_admin", cel.Function("is_admin",
+		cel.Overload("is_admin_string", []*cel.Type{cel.StringType}, cel.BoolType,
+			cel.UnaryBinding(func(ref.Val) ref.Val { return types.True }))))
+	if err := r.RegisterBundle("acme", TenantBundle{
+		Config:    rolloutConfig("is_admin(user.email)"),
+		Variables: []string{"user"},
+		Functions: []string{"is_admin"},
+	}, TenantQuota{}); err != nil {
+		t.Fatalf("RegisterBundle() error = %v", err)
+	}
+	// The functions and variables of acme are not declared in the env of globex
+	for _, expression := range []string{"is_admin(order.id)", "user.email != ''"} {
+		err := r.RegisterBundle("globex", TenantBundle{
+			Config:    rolloutConfig(expression),
+			Variables: []string{"order"},
+		}, TenantQuota{})
+		if err == nil {
+			t.Errorf("RegisterBundle() error = nil for '%s', want a compile error", expression)
+		}
+	}
+	if diff := cmp.Diff([]string{"acme"}, r.Tenants()); diff != "" {
+		t.Errorf("Tenants() mismatch (-want +got):\n%s", diff)
+	}
+}
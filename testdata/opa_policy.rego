@@ -0,0 +1,34 @@
+# Simple allow/deny policy used to test the OPA importer
+package authz.http
+
+import future.keywords.if
+import future.keywords.in
+
+default allow = false
+
+allow if {
+	is_admin
+}
+
+allow if {
+	input.request.method == "GET"
+	startswith(input.request.path, "/public/")
+}
+
+is_admin if {
+	input.user.roles[_] == "admin"
+}
+
+deny[msg] {
+	not input.user.email_verified
+	msg := "Email address must be verified"
+}
+
+deny contains msg if {
+	input.user.age < data.min_age
+	msg := sprintf("User must be at least %d", [data.min_age])
+}
+
+blocked if input.user.country in data.blocked_countries
+
+unrestricted if not input.user.suspended
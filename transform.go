@@ -0,0 +1,36 @@
+package ruleengine
+
+// ResultTransformer post-processes every RulesetResult before it is returned, see WithResultTransformer
+//
+//	Transformers can map results to a company-standard decision envelope via Metadata, strip fields or add tenant
+//	information. They are called synchronously for every ruleset evaluation and must be safe for concurrent use
+type ResultTransformer interface {
+	// Transform returns the result to return in place of result
+	Transform(result RulesetResult) RulesetResult
+}
+
+// ResultTransformerFunc adapts a function to a ResultTransformer
+type ResultTransformerFunc func(RulesetResult) RulesetResult
+
+// Transform implements ResultTransformer
+func (f ResultTransformerFunc) Transform(result RulesetResult) RulesetResult {
+	return f(result)
+}
+
+// WithResultTransformer registers a transformer applied to every RulesetResult
+//
+//	Transformers are applied in registration order, after fallbacks, to the results of EvaluateRuleset,
+//	EvaluateAllRulesets and EvaluateMany
+func WithResultTransformer(transformer ResultTransformer) Option {
+	return func(re *RuleEngine) {
+		re.transformers = append(re.transformers, transformer)
+	}
+}
+
+// transformResult applies the registered transformers to result
+func (re *RuleEngine) transformResult(result RulesetResult) RulesetResult {
+	for _, transformer := range re.transformers {
+		result = transformer.Transform(result)
+	}
+	return result
+}
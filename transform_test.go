@@ -0,0 +1,89 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_ResultTransformer(t *testing.T) {
+	tenant := ResultTransformerFunc(func(result RulesetResult) RulesetResult {
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]interface{})
+		}
+		result.Metadata["tenant"] = "acme"
+		return result
+	})
+	decision := ResultTransformerFunc(func(result RulesetResult) RulesetResult {
+		decision := "deny"
+		if result.Passed {
+			decision = "allow"
+		}
+		result.Metadata["decision"] = decision
+		result.RuleResults = nil
+		return result
+	})
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		age          int
+		wantMetadata map[string]interface{}
+		wantRules    int
+	}{
+		{
+			name:      "success - no transformers",
+			age:       21,
+			wantRules: 2,
+		},
+		{
+			name:         "success - transformers applied in order",
+			opts:         []Option{WithResultTransformer(tenant), WithResultTransformer(decision)},
+			age:          21,
+			wantMetadata: map[string]interface{}{"tenant": "acme", "decision": "allow"},
+		},
+		{
+			name:         "fail - transformers applied to failed results",
+			opts:         []Option{WithResultTransformer(tenant), WithResultTransformer(decision)},
+			age:          16,
+			wantMetadata: map[string]interface{}{"tenant": "acme", "decision": "deny"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().
+				WithConfigFile("./testdata/when_rules.yml").
+				WithVariables("user", "request").
+				WithOptions(tt.opts...).
+				Build()
+			if err != nil {
+				t.Fatalf("failed to create rules engine: %v", err)
+			}
+			ctx := map[string]interface{}{
+				"user":    map[string]interface{}{"tier": "premium", "age": tt.age},
+				"request": map[string]interface{}{"hour": 10},
+			}
+
+			got, err := re.EvaluateRuleset("access", WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if diff := cmp.Diff(got.Metadata, tt.wantMetadata); diff != "" {
+				t.Errorf("EvaluateRuleset() metadata (-got +want):\n%s", diff)
+			}
+			if len(got.RuleResults) != tt.wantRules {
+				t.Errorf("EvaluateRuleset() rule results = %d, want %d", len(got.RuleResults), tt.wantRules)
+			}
+
+			all, err := re.EvaluateAllRulesets(WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateAllRulesets() error = %v", err)
+			}
+			for name, result := range all {
+				if (result.Metadata != nil) != (tt.wantMetadata != nil) {
+					t.Errorf("EvaluateAllRulesets() ruleset %s metadata = %v, want transformed %v", name, result.Metadata, tt.wantMetadata != nil)
+				}
+			}
+		})
+	}
+}
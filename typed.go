@@ -0,0 +1,92 @@
+package ruleengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/common/types/ref"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// EvaluateAs evaluates an output-producing rule and coerces its value to T
+//
+//	Values are converted natively where CEL supports it, e.g. bool, string, int64 or float64, otherwise via JSON,
+//	which also allows decoding map outputs into structs. Parent rules act as guards and must pass.
+//	Errors are returned if the rule is not found, fails to evaluate, a parent does not pass or the value cannot
+//	be converted to T
+func EvaluateAs[T any](re *RuleEngine, ruleName string, opts ...EvalOption) (T, error) {
+	if active := re.Active(); active != re {
+		return EvaluateAs[T](active, ruleName, opts...)
+	}
+	var zero T
+	release, _, err := re.enqueue(false)
+	if err != nil {
+		return zero, err
+	}
+	defer release()
+	eval, err := re.newEvaluation(opts)
+	if err != nil {
+		return zero, err
+	}
+	out, err := re.evaluateValue(ruleName, eval)
+	if err != nil {
+		return zero, err
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if native, err := out.ConvertToNative(typ); err == nil {
+		if v, ok := native.(T); ok {
+			return v, nil
+		}
+	}
+
+	// Fall back to JSON for numeric coercion, structs and other composite values
+	pb, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return zero, fmt.Errorf("failed to convert rule '%s' output to %s: %w", ruleName, typ, err)
+	}
+	data, err := protojson.Marshal(pb.(*structpb.Value))
+	if err != nil {
+		return zero, fmt.Errorf("failed to convert rule '%s' output to %s: %w", ruleName, typ, err)
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return zero, fmt.Errorf("failed to convert rule '%s' output to %s: %w", ruleName, typ, err)
+	}
+	return v, nil
+}
+
+// evaluateValue evaluates a rule and returns its raw CEL output value, parent rules must pass
+func (re *RuleEngine) evaluateValue(ruleName string, eval *evaluation) (ref.Val, error) {
+	if _, ok := re.config.Rules[ruleName]; !ok {
+		return nil, fmt.Errorf("rule '%s' not found", ruleName)
+	}
+	if result, ok := re.quarantinedResult(ruleName); ok {
+		return nil, result.Error
+	}
+	for _, parent := range re.parents[ruleName] {
+		program, err := re.programs.get(parent)
+		if err != nil {
+			return nil, err
+		}
+		out, _, err := re.evalRule(parent, program, eval.input())
+		if err != nil {
+			return nil, &EvaluationError{RuleName: parent, Err: err}
+		}
+		if passed, ok := out.Value().(bool); !ok || !passed {
+			return nil, fmt.Errorf("parent rule '%s' of rule '%s' did not pass", parent, ruleName)
+		}
+	}
+
+	program, err := re.programs.get(ruleName)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := re.evalRule(ruleName, program, eval.input())
+	if err != nil {
+		return nil, &EvaluationError{RuleName: ruleName, Err: err}
+	}
+	return out, nil
+}
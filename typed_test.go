@@ -0,0 +1,76 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testOffer struct {
+	Code     string   `json:"code"`
+	Percent  int      `json:"percent"`
+	Channels []string `json:"channels"`
+}
+
+func TestEvaluateAs(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/output_rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	member := WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"member": true, "spend": 1500},
+	})
+	nonMember := WithEvalContext(map[string]interface{}{
+		"user": map[string]interface{}{"member": false, "spend": 1500},
+	})
+
+	t.Run("bool", func(t *testing.T) {
+		got, err := EvaluateAs[bool](re, "is_member", member)
+		if err != nil || !got {
+			t.Errorf("EvaluateAs[bool]() = %v, %v, want true", got, err)
+		}
+	})
+	t.Run("string", func(t *testing.T) {
+		got, err := EvaluateAs[string](re, "tier", member)
+		if err != nil || got != "gold" {
+			t.Errorf("EvaluateAs[string]() = %v, %v, want gold", got, err)
+		}
+	})
+	t.Run("float64", func(t *testing.T) {
+		got, err := EvaluateAs[float64](re, "discount", member)
+		if err != nil || got != 0.15 {
+			t.Errorf("EvaluateAs[float64]() = %v, %v, want 0.15", got, err)
+		}
+	})
+	t.Run("float64 from int", func(t *testing.T) {
+		got, err := EvaluateAs[float64](re, "points", member)
+		if err != nil || got != 150 {
+			t.Errorf("EvaluateAs[float64]() = %v, %v, want 150", got, err)
+		}
+	})
+	t.Run("struct", func(t *testing.T) {
+		got, err := EvaluateAs[testOffer](re, "offer", member)
+		if err != nil {
+			t.Fatalf("EvaluateAs[testOffer]() error = %v", err)
+		}
+		want := testOffer{Code: "WELCOME", Percent: 10, Channels: []string{"email", "sms"}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("EvaluateAs[testOffer]() (-got +want):\n%s", diff)
+		}
+	})
+	t.Run("fail - parent did not pass", func(t *testing.T) {
+		if _, err := EvaluateAs[float64](re, "discount", nonMember); err == nil {
+			t.Errorf("EvaluateAs[float64]() expected error")
+		}
+	})
+	t.Run("fail - type mismatch", func(t *testing.T) {
+		if _, err := EvaluateAs[int](re, "tier", member); err == nil {
+			t.Errorf("EvaluateAs[int]() expected error")
+		}
+	})
+	t.Run("fail - rule not found", func(t *testing.T) {
+		if _, err := EvaluateAs[bool](re, "unknown", member); err == nil {
+			t.Errorf("EvaluateAs[bool]() expected error")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package ruleengine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VariableUsage reports which context paths the config references, used to build minimal context payloads
+//
+//	Paths are the longest field selections rooted at a declared variable, e.g. `user.age`. Globals are supplied
+//	by the engine and are not included.
+type VariableUsage struct {
+	// Rules is a map of rule names to the paths referenced by their expression and when clause
+	Rules map[string][]string
+	// Rulesets is a map of ruleset names to the paths needed to evaluate them, including extended rules and preconditions
+	Rulesets map[string][]string
+	// Fields are the paths needed to evaluate every rule and precondition, paths covered by a shorter path are omitted
+	Fields []string
+}
+
+// VariableUsage lists, per rule and ruleset, the context paths referenced by the checked expressions
+//
+//	Errors are returned if an expression cannot be compiled
+func (re *RuleEngine) VariableUsage() (VariableUsage, error) {
+	if active := re.Active(); active != re {
+		return active.VariableUsage()
+	}
+	usage := VariableUsage{
+		Rules:    make(map[string][]string, len(re.config.Rules)),
+		Rulesets: make(map[string][]string, len(re.config.Rulesets)),
+	}
+
+	all := make([]string, 0)
+	for _, name := range re.ruleNames() {
+		rule := re.config.Rules[name]
+		paths, err := re.contextPaths(rule.Expression)
+		if err != nil {
+			return VariableUsage{}, fmt.Errorf("failed to compile rule '%s': %w", name, err)
+		}
+		if rule.When != "" {
+			guardPaths, err := re.contextPaths(rule.When)
+			if err != nil {
+				return VariableUsage{}, fmt.Errorf("failed to compile when clause of rule '%s': %w", name, err)
+			}
+			paths = append(paths, guardPaths...)
+		}
+		usage.Rules[name] = paths
+		all = append(all, paths...)
+	}
+
+	for _, name := range re.rulesetNames() {
+		paths := make([]string, 0)
+		if precondition := re.config.Rulesets[name].Precondition; precondition != "" {
+			preconditionPaths, err := re.contextPaths(precondition)
+			if err != nil {
+				return VariableUsage{}, fmt.Errorf("failed to compile precondition of ruleset '%s': %w", name, err)
+			}
+			paths = append(paths, preconditionPaths...)
+			all = append(all, preconditionPaths...)
+		}
+		for _, ruleName := range re.config.Rulesets[name].Rules {
+			paths = append(paths, usage.Rules[ruleName]...)
+			for _, parent := range re.parents[ruleName] {
+				paths = append(paths, usage.Rules[parent]...)
+			}
+		}
+		usage.Rulesets[name] = minimalPaths(paths)
+	}
+	usage.Fields = minimalPaths(all)
+	return usage, nil
+}
+
+// contextPaths returns the context paths referenced by an expression, excluding globals
+func (re *RuleEngine) contextPaths(expression string) ([]string, error) {
+	checked, issues := re.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	paths := make([]string, 0)
+	for _, path := range re.referencedVariables(checked.NativeRep().Expr()) {
+		if path != "globals" && !strings.HasPrefix(path, "globals.") {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// minimalPaths sorts and de-duplicates paths, dropping paths covered by a shorter prefix, e.g. `user.age` by `user`
+func minimalPaths(paths []string) []string {
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+	minimal := make([]string, 0, len(sorted))
+	for _, path := range sorted {
+		covered := false
+		for _, kept := range minimal {
+			if path == kept || strings.HasPrefix(path, kept+".") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			minimal = append(minimal, path)
+		}
+	}
+	return minimal
+}
@@ -0,0 +1,78 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_VariableUsage(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	got, err := re.VariableUsage()
+	if err != nil {
+		t.Fatalf("VariableUsage() error = %v", err)
+	}
+	want := VariableUsage{
+		Rules: map[string][]string{
+			"age_validation":  {"user.age"},
+			"business_hours":  {"request.time"},
+			"email_format":    {"user.email"},
+			"email_whitelist": {"user.email"},
+			"rate_limiting":   {"request.attempt"},
+			"test_user":       {"user.email"},
+			"user_status":     {"user.status", "user.suspended"},
+			"user_tier":       {"user.tier"},
+		},
+		Rulesets: map[string][]string{
+			"domain_whitelist":   {"user.email"},
+			"request_throttling": {"request.attempt", "user.tier"},
+			"user_registration":  {"user.age", "user.email", "user.status", "user.suspended"},
+		},
+		Fields: []string{
+			"request.attempt",
+			"request.time",
+			"user.age",
+			"user.email",
+			"user.status",
+			"user.suspended",
+			"user.tier",
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("VariableUsage() (-got +want):\n%s", diff)
+	}
+}
+
+func TestMinimalPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "success - duplicates",
+			paths: []string{"user.age", "user.age"},
+			want:  []string{"user.age"},
+		},
+		{
+			name:  "success - covered by prefix",
+			paths: []string{"user.age", "user", "request.attempt"},
+			want:  []string{"request.attempt", "user"},
+		},
+		{
+			name:  "success - shared name prefix is not covered",
+			paths: []string{"user.age", "user.agent"},
+			want:  []string{"user.age", "user.agent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(minimalPaths(tt.paths), tt.want); diff != "" {
+				t.Errorf("minimalPaths() (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
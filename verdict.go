@@ -0,0 +1,248 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// ErrNoDecision is returned by EvaluateDecision when the config has no decision expression
+var ErrNoDecision = errors.New("config has no decision expression")
+
+// Verdict is the final verdict of the decision expression of the config, see EvaluateDecision
+type Verdict struct {
+	// Passed is the value of the decision expression
+	Passed bool
+	// Rulesets are the results of every ruleset the decision combines, keyed by ruleset name
+	Rulesets map[string]RulesetResult
+	// Duration is the time taken to evaluate the rulesets and the decision
+	Duration time.Duration
+}
+
+// EvaluateDecision evaluates every ruleset and combines their outcomes with the decision expression of the config
+// into a single verdict
+//
+//	decision: "rulesets.user_registration && !rulesets.request_throttling"
+//
+//	The expression reads the outcome of each ruleset as a bool from the `rulesets` map, see EvaluateAllRulesets for how
+//	the rulesets are evaluated. ErrNoDecision is returned if the config has no decision, other errors are returned if
+//	a ruleset or the decision fails to evaluate, with the ruleset results so far
+func (re *RuleEngine) EvaluateDecision(opts ...EvalOption) (Verdict, error) {
+	if active := re.Active(); active != re {
+		return active.EvaluateDecision(opts...)
+	}
+	if re.decision == nil {
+		return Verdict{}, ErrNoDecision
+	}
+	start := time.Now()
+	results, err := re.EvaluateAllRulesets(opts...)
+	verdict := Verdict{Rulesets: results}
+	if err != nil {
+		verdict.Duration = time.Since(start)
+		return verdict, err
+	}
+	verdict.Passed, err = evalDecisionProgram(re.decision, results)
+	verdict.Duration = time.Since(start)
+	if err != nil {
+		return verdict, fmt.Errorf("failed to evaluate decision: %w", err)
+	}
+	return verdict, nil
+}
+
+// compileDecision compiles the decision expression of the config, if any
+func (re *RuleEngine) compileDecision() error {
+	if re.config.Decision == "" {
+		return nil
+	}
+	program, err := re.compileOutcomeExpression(re.config.Decision)
+	if err != nil {
+		return fmt.Errorf("invalid decision: %w", err)
+	}
+	re.decision = program
+	return nil
+}
+
+// outcomeEnv creates the CEL env of the boolean expressions over the `rulesets` outcomes map
+func outcomeEnv() (*cel.Env, error) {
+	env, err := cel.NewEnv(cel.Variable("rulesets", cel.MapType(cel.StringType, cel.BoolType)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel env: %w", err)
+	}
+	return env, nil
+}
+
+// checkOutcomeExpression returns the checked AST of an expression in the outcomeEnv env, loaded from the bundle if
+// precompiled
+func (re *RuleEngine) checkOutcomeExpression(env *cel.Env, expression string) (*cel.Ast, error) {
+	if re.precompiled != nil {
+		if checked, ok := re.precompiled.outcomes[expression]; ok {
+			return checked, nil
+		}
+	}
+	checked, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return checked, nil
+}
+
+// compileOutcomeExpression compiles a boolean expression over the `rulesets` outcomes map
+//
+//	Errors are returned if the expression does not compile, is not a bool or selects a ruleset not in the config
+func (re *RuleEngine) compileOutcomeExpression(expression string) (cel.Program, error) {
+	env, err := outcomeEnv()
+	if err != nil {
+		return nil, err
+	}
+	checked, err := re.checkOutcomeExpression(env, expression)
+	if err != nil {
+		return nil, err
+	}
+	if checked.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression '%s' must be a bool, got %s", expression, checked.OutputType())
+	}
+	root := ast.NavigateAST(checked.NativeRep())
+	for _, nav := range ast.MatchDescendants(root, ast.KindMatcher(ast.SelectKind)) {
+		sel := nav.AsSelect()
+		if sel.Operand().Kind() != ast.IdentKind || sel.Operand().AsIdent() != "rulesets" {
+			continue
+		}
+		if _, ok := re.config.Rulesets[sel.FieldName()]; !ok {
+			return nil, fmt.Errorf("expression '%s' references unknown ruleset '%s'", expression, sel.FieldName())
+		}
+	}
+	return env.Program(checked)
+}
+
+// evalDecisionProgram evaluates a program compiled by compileOutcomeExpression against the ruleset results
+func evalDecisionProgram(program cel.Program, results map[string]RulesetResult) (bool, error) {
+	outcomes := make(map[string]bool, len(results))
+	for name, result := range results {
+		outcomes[name] = result.Passed
+	}
+	out, _, err := program.Eval(map[string]interface{}{"rulesets": outcomes})
+	if err != nil {
+		return false, err
+	}
+	return out == types.True, nil
+}
+
+// DecisionConfig chooses a business outcome, e.g. APPROVE, REVIEW or DECLINE, from the ruleset outcomes
+//
+//	decisions:
+//	  payment:
+//	    outcomes:
+//	      - outcome: DECLINE
+//	        when: "!rulesets.sanctions"
+//	      - outcome: REVIEW
+//	        when: "!rulesets.velocity"
+//	    default: APPROVE
+type DecisionConfig struct {
+	Description string `yaml:"description"`
+	// Outcomes are checked in order, the first whose condition holds is chosen
+	Outcomes []DecisionOutcome `yaml:"outcomes"`
+	// Default is the outcome chosen when no condition holds
+	Default string `yaml:"default"`
+}
+
+// DecisionOutcome is an outcome of a decision and the condition choosing it
+type DecisionOutcome struct {
+	// Outcome is the business outcome, e.g. "REVIEW"
+	Outcome string `yaml:"outcome"`
+	// When is a bool expression over the `rulesets` outcomes, as the top-level decision expression
+	When string `yaml:"when"`
+}
+
+// DecisionResult is the outcome chosen by a named decision, see Decide
+type DecisionResult struct {
+	// DecisionName is the name of the decision
+	DecisionName string
+	// Outcome is the chosen outcome
+	Outcome string
+	// When is the condition which chose the outcome, empty if the default was chosen
+	When string
+	// Rulesets are the results of every ruleset the outcome was chosen from, keyed by ruleset name
+	Rulesets map[string]RulesetResult
+	// Duration is the time taken to evaluate the rulesets and the conditions
+	Duration time.Duration
+}
+
+// compiledDecision holds the compiled conditions of a named decision, in order
+type compiledDecision struct {
+	config     DecisionConfig
+	conditions []cel.Program
+}
+
+// Decide evaluates every ruleset and returns the outcome of the named decision, chosen by the first outcome whose
+// condition holds, or its default
+//
+//	See EvaluateAllRulesets for how the rulesets are evaluated. Errors are returned if the decision is not found or a
+//	ruleset or condition fails to evaluate, with the ruleset results so far
+func (re *RuleEngine) Decide(decisionName string, opts ...EvalOption) (DecisionResult, error) {
+	if active := re.Active(); active != re {
+		return active.Decide(decisionName, opts...)
+	}
+	decision, ok := re.decisions[decisionName]
+	if !ok {
+		return DecisionResult{}, fmt.Errorf("decision '%s' not found", decisionName)
+	}
+	start := time.Now()
+	results, err := re.EvaluateAllRulesets(opts...)
+	result := DecisionResult{DecisionName: decisionName, Rulesets: results}
+	if err != nil {
+		result.Duration = time.Since(start)
+		return result, err
+	}
+	result.Outcome = decision.config.Default
+	for i, condition := range decision.conditions {
+		holds, err := evalDecisionProgram(condition, results)
+		if err != nil {
+			result.Duration = time.Since(start)
+			return result, fmt.Errorf("failed to evaluate decision '%s' outcome '%s': %w", decisionName, decision.config.Outcomes[i].Outcome, err)
+		}
+		if holds {
+			result.Outcome, result.When = decision.config.Outcomes[i].Outcome, decision.config.Outcomes[i].When
+			break
+		}
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// DecisionNames returns the names of the configured decisions in sorted order
+func (re *RuleEngine) DecisionNames() []string {
+	if active := re.Active(); active != re {
+		return active.DecisionNames()
+	}
+	return sortedNames(re.decisions)
+}
+
+// compileDecisions compiles the conditions of the named decisions of the config
+//
+//	Errors are returned if a decision has no default, an outcome has no name or condition, or a condition is invalid
+func (re *RuleEngine) compileDecisions() error {
+	re.decisions = make(map[string]*compiledDecision, len(re.config.Decisions))
+	for _, name := range sortedNames(re.config.Decisions) {
+		config := re.config.Decisions[name]
+		if config.Default == "" {
+			return fmt.Errorf("decision '%s' has no default outcome", name)
+		}
+		decision := &compiledDecision{config: config, conditions: make([]cel.Program, 0, len(config.Outcomes))}
+		for i, outcome := range config.Outcomes {
+			if outcome.Outcome == "" || outcome.When == "" {
+				return fmt.Errorf("decision '%s' outcome %d needs an outcome and a when condition", name, i+1)
+			}
+			program, err := re.compileOutcomeExpression(outcome.When)
+			if err != nil {
+				return fmt.Errorf("invalid decision '%s' outcome '%s': %w", name, outcome.Outcome, err)
+			}
+			decision.conditions = append(decision.conditions, program)
+		}
+		re.decisions[name] = decision
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package ruleengine
+
+import (
+	"errors"
+	"testing"
+)
+
+// decisionConfig returns a config with an adult and a blocked ruleset combined by the decision expression
+func decisionConfig(decision string) *RulesetConfig {
+	return &RulesetConfig{
+		Rules: map[string]Rule{
+			"age_validation": {Expression: "user.age >= 18"},
+			"blocklisted":    {Expression: "user.blocked"},
+		},
+		Rulesets: map[string]Ruleset{
+			"adult":   {Selector: "AND", Rules: []string{"age_validation"}},
+			"blocked": {Selector: "AND", Rules: []string{"blocklisted"}},
+		},
+		ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+		ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+		Decision:          decision,
+	}
+}
+
+func TestRuleEngine_EvaluateDecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		decision     string
+		user         map[string]interface{}
+		wantPassed   bool
+		wantBuildErr bool
+		wantErr      error
+	}{
+		{
+			name:       "success - decision passes",
+			decision:   "rulesets.adult && !rulesets.blocked",
+			user:       map[string]interface{}{"age": 21, "blocked": false},
+			wantPassed: true,
+		},
+		{
+			name:     "success - decision fails",
+			decision: "rulesets.adult && !rulesets.blocked",
+			user:     map[string]interface{}{"age": 21, "blocked": true},
+		},
+		{
+			name:     "fail - no decision",
+			decision: "",
+			user:     map[string]interface{}{"age": 21, "blocked": false},
+			wantErr:  ErrNoDecision,
+		},
+		{
+			name:         "fail - unknown ruleset",
+			decision:     "rulesets.adult && !rulesets.sanctioned",
+			wantBuildErr: true,
+		},
+		{
+			name:         "fail - not a bool",
+			decision:     "rulesets.adult ? 1 : 0",
+			wantBuildErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := NewBuilder().WithConfig(decisionConfig(tt.decision)).WithVariables("user").Build()
+			if (err != nil) != tt.wantBuildErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantBuildErr)
+			}
+			if tt.wantBuildErr {
+				return
+			}
+			got, err := re.EvaluateDecision(WithEvalContext(map[string]interface{}{"user": tt.user}))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EvaluateDecision() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.Passed != tt.wantPassed {
+				t.Errorf("EvaluateDecision() passed = %v, want %v", got.Passed, tt.wantPassed)
+			}
+			if len(got.Rulesets) != 2 {
+				t.Errorf("EvaluateDecision() rulesets = %v, want adult and blocked", got.Rulesets)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_Decide(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/decision_rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		decision    string
+		context     map[string]interface{}
+		wantOutcome string
+		wantWhen    string
+		wantErr     bool
+	}{
+		{
+			name:     "success - first condition holds",
+			decision: "payment",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"sanctioned": true},
+				"request": map[string]interface{}{"attempts": 10},
+			},
+			wantOutcome: "DECLINE",
+			wantWhen:    "!rulesets.sanctions",
+		},
+		{
+			name:     "success - second condition holds",
+			decision: "payment",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"sanctioned": false},
+				"request": map[string]interface{}{"attempts": 10},
+			},
+			wantOutcome: "REVIEW",
+			wantWhen:    "!rulesets.velocity",
+		},
+		{
+			name:     "success - default",
+			decision: "payment",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"sanctioned": false},
+				"request": map[string]interface{}{"attempts": 1},
+			},
+			wantOutcome: "APPROVE",
+		},
+		{
+			name:     "fail - decision not found",
+			decision: "refund",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.Decide(tt.decision, WithEvalContext(tt.context))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decide() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Outcome != tt.wantOutcome || got.When != tt.wantWhen {
+				t.Errorf("Decide() = %s when %q, want %s when %q", got.Outcome, got.When, tt.wantOutcome, tt.wantWhen)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_compileDecisions(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision DecisionConfig
+		wantErr  bool
+	}{
+		{
+			name:     "success - default only",
+			decision: DecisionConfig{Default: "APPROVE"},
+		},
+		{
+			name:     "fail - no default",
+			decision: DecisionConfig{Outcomes: []DecisionOutcome{{Outcome: "DECLINE", When: "!rulesets.adult"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "fail - outcome without condition",
+			decision: DecisionConfig{Outcomes: []DecisionOutcome{{Outcome: "DECLINE"}}, Default: "APPROVE"},
+			wantErr:  true,
+		},
+		{
+			name:     "fail - unknown ruleset",
+			decision: DecisionConfig{Outcomes: []DecisionOutcome{{Outcome: "DECLINE", When: "!rulesets.kyc"}}, Default: "APPROVE"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := decisionConfig("")
+			config.Decisions = map[string]DecisionConfig{"signup": tt.decision}
+			_, err := NewBuilder().WithConfig(config).WithVariables("user").Build()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
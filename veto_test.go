@@ -0,0 +1,120 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_EvaluateRuleset_Veto(t *testing.T) {
+	re, err := NewBuilder().WithConfigFile("./testdata/veto_rules.yml").WithVariables("user", "request").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"sanctions_check"}, re.config.Rulesets["payment"].Vetoes); diff != "" {
+		t.Fatalf("Vetoes mismatch (-want +got):\n%s", diff)
+	}
+	tests := []struct {
+		name         string
+		context      map[string]interface{}
+		wantPassed   bool
+		wantVetoedBy string
+		wantReasons  []string
+	}{
+		{
+			name: "success - allow path passes",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"allowlisted": true, "sanctioned": false},
+				"request": map[string]interface{}{"amount": 500},
+			},
+			wantPassed: true,
+		},
+		{
+			name: "fail - sanctions hit vetoes the allow path",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"allowlisted": true, "sanctioned": true},
+				"request": map[string]interface{}{"amount": 50},
+			},
+			wantVetoedBy: "sanctions_check",
+			wantReasons:  []string{"SANCTIONS_HIT"},
+		},
+		{
+			name: "fail - no allow path",
+			context: map[string]interface{}{
+				"user":    map[string]interface{}{"allowlisted": false, "sanctioned": false},
+				"request": map[string]interface{}{"amount": 500},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := re.EvaluateRuleset("payment", WithEvalContext(tt.context))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if got.Passed != tt.wantPassed || got.VetoedBy != tt.wantVetoedBy {
+				t.Errorf("EvaluateRuleset() passed = %v, vetoed by %q, want %v, %q", got.Passed, got.VetoedBy, tt.wantPassed, tt.wantVetoedBy)
+			}
+			if _, ok := got.RuleResults["sanctions_check"]; !ok {
+				t.Error("EvaluateRuleset() did not evaluate the veto rule after the early exit")
+			}
+			if diff := cmp.Diff(tt.wantReasons, got.ReasonCodes); diff != "" {
+				t.Errorf("EvaluateRuleset() reason codes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_Analyze_Veto(t *testing.T) {
+	tests := []struct {
+		name            string
+		allow           string
+		veto            string
+		wantUnreachable []UnreachableRuleset
+	}{
+		{
+			name:            "success - reachable",
+			allow:           "user.allowlisted",
+			veto:            "!user.sanctioned",
+			wantUnreachable: []UnreachableRuleset{},
+		},
+		{
+			name:            "success - passing veto is not an allow path",
+			allow:           "1 > 2",
+			veto:            "true",
+			wantUnreachable: []UnreachableRuleset{{Name: "payment", Rules: []string{"allowlisted"}}},
+		},
+		{
+			name:            "success - veto never passes",
+			allow:           "user.allowlisted",
+			veto:            "1 > 2",
+			wantUnreachable: []UnreachableRuleset{{Name: "payment", Rules: []string{"sanctions_check"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &RulesetConfig{
+				Rules: map[string]Rule{
+					"allowlisted":     {Expression: tt.allow},
+					"sanctions_check": {Expression: tt.veto},
+				},
+				Rulesets: map[string]Ruleset{
+					"payment": {Selector: "OR", Rules: []string{"allowlisted", "sanctions_check"}, Vetoes: []string{"sanctions_check"}},
+				},
+				ExecutionPolicies: map[string]ExecutionPolicy{"collect_all": {}},
+				ErrorHandling:     ErrorHandling{ExecutionPolicy: "collect_all"},
+			}
+			re, err := NewBuilder().WithConfig(config).WithVariables("user").Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			got, err := re.Analyze()
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantUnreachable, got.UnreachableRulesets); diff != "" {
+				t.Errorf("Analyze() unreachable rulesets mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package ruleengine
+
+import (
+	"sort"
+	"strings"
+)
+
+// WhatIfResult compares a ruleset evaluated against a context with and without overrides, see RuleEngine.WhatIf
+type WhatIfResult struct {
+	// Before is the result against the context as given
+	Before RulesetResult
+	// After is the result against the context with the overrides applied
+	After RulesetResult
+	// Changed are the rules whose outcome changed with the overrides, or which were only evaluated in one of the
+	// results, sorted by rule name
+	Changed []RuleDiff
+}
+
+// Flipped reports whether the overrides change the outcome of the ruleset
+func (w WhatIfResult) Flipped() bool {
+	return w.Before.Passed != w.After.Passed
+}
+
+// WhatIf evaluates the ruleset once against ctx and once with the overrides applied, reporting which rules changed
+// outcome, e.g. to answer "what would make this pass?"
+//
+//	Overrides are keyed by dotted context path, e.g. `user.age`, or by `globals.<name>` to override a global for
+//	this evaluation only. The intermediate maps of a path are created if missing, ctx is not modified.
+//	Errors are returned if the ruleset is not found
+func (re *RuleEngine) WhatIf(rulesetName string, ctx map[string]interface{}, overrides map[string]interface{}) (WhatIfResult, error) {
+	if active := re.Active(); active != re {
+		return active.WhatIf(rulesetName, ctx, overrides)
+	}
+	before, err := re.EvaluateRuleset(rulesetName, WithEvalContext(ctx))
+	if err != nil {
+		return WhatIfResult{}, err
+	}
+
+	paths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		paths = append(paths, path)
+	}
+	// Shorter paths first, so `user` then `user.age` overrides the age within the replaced user
+	sort.Strings(paths)
+	overridden := ctx
+	var globals map[string]interface{}
+	for _, path := range paths {
+		if name, ok := strings.CutPrefix(path, "globals."); ok {
+			if globals == nil {
+				globals = make(map[string]interface{})
+			}
+			globals[name] = overrides[path]
+			continue
+		}
+		overridden = withPath(overridden, strings.Split(path, "."), overrides[path])
+	}
+	opts := []EvalOption{WithEvalContext(overridden)}
+	if globals != nil {
+		opts = append(opts, WithGlobalOverrides(globals))
+	}
+	after, err := re.EvaluateRuleset(rulesetName, opts...)
+	if err != nil {
+		return WhatIfResult{}, err
+	}
+
+	result := WhatIfResult{Before: before, After: after, Changed: make([]RuleDiff, 0)}
+	for _, rd := range DiffResults(before, after).Rules {
+		if rd.InA != rd.InB || rd.OutcomeChanged() {
+			result.Changed = append(result.Changed, rd)
+		}
+	}
+	return result, nil
+}
+
+// withPath returns a copy of m with value at path, copying the maps along the path so m is not modified
+func withPath(m map[string]interface{}, path []string, value interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	if len(path) == 1 {
+		out[path[0]] = value
+		return out
+	}
+	next, _ := out[path[0]].(map[string]interface{})
+	out[path[0]] = withPath(next, path[1:], value)
+	return out
+}
@@ -0,0 +1,81 @@
+package ruleengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleEngine_WhatIf(t *testing.T) {
+	re, err := NewRuleEngine("./testdata/rules.yml", "", setupEnvironment()(t))
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	tests := []struct {
+		name        string
+		ruleset     string
+		overrides   map[string]interface{}
+		wantChanged []string
+		wantFlipped bool
+		wantErr     bool
+	}{
+		{
+			name:        "success - context override makes the ruleset pass",
+			ruleset:     "user_registration",
+			overrides:   map[string]interface{}{"user.age": 21},
+			wantChanged: []string{"age_validation"},
+			wantFlipped: true,
+		},
+		{
+			name:        "success - global override",
+			ruleset:     "user_registration",
+			overrides:   map[string]interface{}{"globals.min_age": 10},
+			wantChanged: []string{"age_validation"},
+			wantFlipped: true,
+		},
+		{
+			name:        "success - override replacing a parent and a nested field",
+			ruleset:     "user_registration",
+			overrides:   map[string]interface{}{"user": map[string]interface{}{"age": 30, "suspended": true}, "user.status": "active", "user.email": "a@b.com"},
+			wantChanged: []string{"age_validation", "user_status"},
+			wantFlipped: false,
+		},
+		{
+			name:        "success - nothing changes",
+			ruleset:     "user_registration",
+			overrides:   map[string]interface{}{"request.attempt": 1},
+			wantChanged: []string{},
+		},
+		{
+			name:    "fail - unknown ruleset",
+			ruleset: "unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := map[string]interface{}{"age": 11, "email": "kid@example.com", "status": "active", "suspended": false}
+			ctx := map[string]interface{}{"user": user}
+			got, err := re.WhatIf(tt.ruleset, ctx, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WhatIf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			changed := make([]string, 0, len(got.Changed))
+			for _, rd := range got.Changed {
+				changed = append(changed, rd.RuleName)
+			}
+			if diff := cmp.Diff(changed, tt.wantChanged); diff != "" {
+				t.Errorf("WhatIf() changed rules (-got +want):\n%s", diff)
+			}
+			if got.Flipped() != tt.wantFlipped {
+				t.Errorf("Flipped() = %v, want %v", got.Flipped(), tt.wantFlipped)
+			}
+			if user["age"] != 11 || len(ctx) != 1 {
+				t.Errorf("WhatIf() modified the context: %v", ctx)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package ruleengine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// compileGuards compiles the when clause of every rule declaring one
+//
+//	When clauses must evaluate to a bool, every rule is compiled even if some fail
+func (re *RuleEngine) compileGuards() CompileErrors {
+	var errs CompileErrors
+	for _, name := range re.ruleNames() {
+		expression := re.config.Rules[name].When
+		if expression == "" {
+			continue
+		}
+		program, err := re.compileCondition(expression)
+		if err != nil {
+			var exprErr *CompileError
+			if errors.As(err, &exprErr) {
+				err = exprErr.Err
+			}
+			compileErr := &CompileError{
+				RuleName:   name,
+				Expression: expression,
+				Err:        fmt.Errorf("invalid when clause: %w", err),
+			}
+			if exprErr != nil {
+				compileErr.Issues = exprErr.Issues
+			}
+			errs = append(errs, compileErr)
+			continue
+		}
+		re.guards[name] = program
+	}
+	return errs
+}
+
+// evaluateGuard reports whether a rule should be skipped because its when clause is not true
+//
+//	Only the rule's own when clause applies, the when clauses of extended rules are ignored.
+//	Rules without a when clause are never skipped, errors are returned if the clause fails to evaluate
+func (re *RuleEngine) evaluateGuard(ruleName string, eval *evaluation) (bool, error) {
+	program, ok := re.guards[ruleName]
+	if !ok {
+		return false, nil
+	}
+	holds, err := re.evaluateCondition(program, eval)
+	if err != nil {
+		return false, err
+	}
+	return !holds, nil
+}
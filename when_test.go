@@ -0,0 +1,148 @@
+package ruleengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newWhenEngine creates an engine for testdata/when_rules.yml
+func newWhenEngine(t *testing.T) *RuleEngine {
+	t.Helper()
+	re, err := NewBuilder().
+		WithConfigFile("./testdata/when_rules.yml").
+		WithVariables("user", "request").
+		Build()
+	if err != nil {
+		t.Fatalf("failed to create rules engine: %v", err)
+	}
+	return re
+}
+
+func TestRuleEngine_EvaluateRule_When(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        map[string]interface{}
+		wantPassed  bool
+		wantSkipped bool
+		wantEvalErr bool
+	}{
+		{
+			name:       "success - when clause holds, rule passes",
+			user:       map[string]interface{}{"tier": "free", "age": 21},
+			wantPassed: true,
+		},
+		{
+			name:        "success - when clause does not hold, skipped",
+			user:        map[string]interface{}{"tier": "premium", "age": 21},
+			wantSkipped: true,
+		},
+		{
+			name:        "fail - when clause fails to evaluate",
+			user:        map[string]interface{}{"age": 21},
+			wantEvalErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := newWhenEngine(t)
+			ctx := map[string]interface{}{
+				"user":    tt.user,
+				"request": map[string]interface{}{"hour": 10},
+			}
+
+			got, err := re.EvaluateRule("business_hours", WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRule() error = %v", err)
+			}
+			if got.Passed != tt.wantPassed || got.Skipped != tt.wantSkipped {
+				t.Errorf("EvaluateRule() = %+v, want passed %v skipped %v", got, tt.wantPassed, tt.wantSkipped)
+			}
+			var evalErr *EvaluationError
+			if errors.As(got.Error, &evalErr) != tt.wantEvalErr {
+				t.Errorf("EvaluateRule() error = %v, want evaluation error %v", got.Error, tt.wantEvalErr)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_EvaluateRuleset_When(t *testing.T) {
+	tests := []struct {
+		name        string
+		ruleset     string
+		user        map[string]interface{}
+		wantPassed  bool
+		wantSkipped map[string]bool
+	}{
+		{
+			name:        "success - skipped rule excluded from AND",
+			ruleset:     "access",
+			user:        map[string]interface{}{"tier": "premium", "age": 21},
+			wantPassed:  true,
+			wantSkipped: map[string]bool{"business_hours": true, "age_validation": false},
+		},
+		{
+			name:        "fail - guarded rule fails AND, fail fast",
+			ruleset:     "access",
+			user:        map[string]interface{}{"tier": "free", "age": 21},
+			wantPassed:  false,
+			wantSkipped: map[string]bool{"business_hours": false},
+		},
+		{
+			name:        "fail - skipped rule excluded from OR",
+			ruleset:     "either",
+			user:        map[string]interface{}{"tier": "premium", "age": 16},
+			wantPassed:  false,
+			wantSkipped: map[string]bool{"business_hours": true, "age_validation": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := newWhenEngine(t)
+			ctx := map[string]interface{}{
+				"user":    tt.user,
+				"request": map[string]interface{}{"hour": 20},
+			}
+
+			got, err := re.EvaluateRuleset(tt.ruleset, WithEvalContext(ctx))
+			if err != nil {
+				t.Fatalf("EvaluateRuleset() error = %v", err)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Errorf("EvaluateRuleset() passed = %v, want %v", got.Passed, tt.wantPassed)
+			}
+			gotSkipped := make(map[string]bool, len(got.RuleResults))
+			for name, result := range got.RuleResults {
+				gotSkipped[name] = result.Skipped
+			}
+			if diff := cmp.Diff(gotSkipped, tt.wantSkipped); diff != "" {
+				t.Errorf("EvaluateRuleset() skipped (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewRuleEngine_When(t *testing.T) {
+	config := &RulesetConfig{
+		Rules: map[string]Rule{
+			"age_validation": {Expression: "user.age >= 18", When: "1 + 1"},
+		},
+		ExecutionPolicies: map[string]ExecutionPolicy{
+			"collect_all": {StopOnFailure: false},
+		},
+		ErrorHandling: ErrorHandling{ExecutionPolicy: "collect_all"},
+	}
+	_, err := NewBuilder().
+		WithConfig(config).
+		WithVariables("user").
+		Build()
+	if err == nil {
+		t.Fatalf("Build() error = nil, want error")
+	}
+	want := "rule 'age_validation': invalid when clause"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Build() error = %v, want %q", err, want)
+	}
+}